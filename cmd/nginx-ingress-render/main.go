@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/nginxinc/kubernetes-ingress/internal/configs"
+	"github.com/nginxinc/kubernetes-ingress/internal/configs/version1"
+	"github.com/nginxinc/kubernetes-ingress/internal/configs/version2"
+	"github.com/nginxinc/kubernetes-ingress/internal/k8s"
+	"github.com/nginxinc/kubernetes-ingress/internal/nginx"
+	cr_validation "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/validation"
+	conf_scheme "github.com/nginxinc/kubernetes-ingress/pkg/client/clientset/versioned/scheme"
+	api_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var (
+	manifestsPath = flag.String("manifests", "",
+		`Path to a directory with the YAML manifests of the resources: Ingresses, VirtualServers, VirtualServerRoutes,
+	Policies, TransportServers, GlobalConfiguration, ConfigMaps, Secrets, Services, Endpoints, EndpointSlices and Pods. Required`)
+
+	outputPath = flag.String("output", "",
+		`Path to a directory where the generated NGINX configuration files are written. Required`)
+
+	templatesPath = flag.String("templates-path", "internal/configs",
+		`Path to the directory with the version1 and version2 directories of the NGINX templates`)
+
+	nginxPlus = flag.Bool("nginx-plus", false, "Generate the configuration for NGINX Plus")
+
+	ingressClass = flag.String("ingress-class", "nginx",
+		`The class of the Ingress Controller. Only the resources of this class are processed`)
+
+	nginxConfigMaps = flag.String("nginx-configmaps", "",
+		`A ConfigMap resource among the manifests for customizing NGINX configuration. Format: <namespace>/<name>`)
+
+	enableTLSPassthrough = flag.Bool("enable-tls-passthrough", false,
+		"Enable TLS Passthrough on port 443")
+
+	enableSnippets = flag.Bool("enable-snippets", false,
+		"Enable custom NGINX configuration snippets in Ingress, VirtualServer, VirtualServerRoute and TransportServer resources")
+
+	enableOIDC = flag.Bool("enable-oidc", false,
+		"Enable OIDC Policies")
+
+	enableGeoIP2 = flag.Bool("enable-geoip2", false,
+		"Enable geoAccess Policies")
+
+	enableBrotli = flag.Bool("enable-brotli", false,
+		"Enable the brotli algorithm in compression Policies")
+
+	enableAPIKeyAuth = flag.Bool("enable-apikey-auth", false,
+		"Enable apiKey Policies")
+)
+
+func main() {
+	flag.Parse()
+
+	err := flag.Lookup("logtostderr").Value.Set("true")
+	if err != nil {
+		glog.Fatalf("Error setting logtostderr to true: %v", err)
+	}
+
+	if *manifestsPath == "" || *outputPath == "" {
+		glog.Fatal("The -manifests and -output flags are required")
+	}
+
+	objects, err := readManifests(*manifestsPath)
+	if err != nil {
+		glog.Fatalf("Error reading the manifests: %v", err)
+	}
+
+	nginxManager, err := nginx.NewFileManager(*outputPath)
+	if err != nil {
+		glog.Fatalf("Error creating the output directory: %v", err)
+	}
+
+	templateExecutor, templateExecutorV2 := createTemplateExecutors()
+
+	cfgParams := configs.NewDefaultConfigParams(*nginxPlus)
+	cfgParams = processConfigMaps(objects, cfgParams, nginxManager, templateExecutor)
+
+	staticCfgParams := &configs.StaticConfigParams{
+		HealthStatusURI:    "/nginx-health",
+		NginxStatusPort:    8080,
+		TLSPassthrough:     *enableTLSPassthrough,
+		EnableSnippets:     *enableSnippets,
+		EnableOIDC:         *enableOIDC,
+		EnableGeoIP2:       *enableGeoIP2,
+		EnableBrotli:       *enableBrotli,
+		EnableAPIKeyAuth:   *enableAPIKeyAuth,
+		SSLRejectHandshake: true,
+	}
+
+	ngxConfig := configs.GenerateNginxMainConfig(staticCfgParams, cfgParams)
+	content, err := templateExecutor.ExecuteMainConfigTemplate(ngxConfig)
+	if err != nil {
+		glog.Fatalf("Error generating NGINX main config: %v", err)
+	}
+	nginxManager.CreateMainConfig(content)
+
+	if *enableTLSPassthrough {
+		var emptyFile []byte
+		nginxManager.CreateTLSPassthroughHostsConfig(emptyFile)
+	}
+
+	cnf := configs.NewConfigurator(nginxManager, staticCfgParams, cfgParams, templateExecutor,
+		templateExecutorV2, *nginxPlus, false, nil, false, nil, false)
+
+	result, err := k8s.Render(k8s.RenderInput{
+		NginxConfigurator:            cnf,
+		Objects:                      objects,
+		IngressClass:                 *ingressClass,
+		IsNginxPlus:                  *nginxPlus,
+		IsTLSPassthroughEnabled:      *enableTLSPassthrough,
+		SnippetsEnabled:              *enableSnippets,
+		EnableOIDC:                   *enableOIDC,
+		EnableGeoIP2:                 *enableGeoIP2,
+		EnableBrotli:                 *enableBrotli,
+		EnableAPIKeyAuth:             *enableAPIKeyAuth,
+		VirtualServerValidator:       cr_validation.NewVirtualServerValidator(cr_validation.IsPlus(*nginxPlus)),
+		GlobalConfigurationValidator: cr_validation.NewGlobalConfigurationValidator(map[int]bool{80: true, 443: true}),
+		TransportServerValidator:     cr_validation.NewTransportServerValidator(*enableTLSPassthrough, *enableSnippets, *nginxPlus),
+	})
+	if err != nil {
+		glog.Fatalf("Error generating the NGINX configuration: %v", err)
+	}
+
+	printResult(os.Stdout, result)
+
+	if len(result.Problems) > 0 {
+		os.Exit(1)
+	}
+}
+
+func createTemplateExecutors() (*version1.TemplateExecutor, *version2.TemplateExecutor) {
+	nginxConfTemplatePath := "nginx.tmpl"
+	nginxIngressTemplatePath := "nginx.ingress.tmpl"
+	nginxVirtualServerTemplatePath := "nginx.virtualserver.tmpl"
+	nginxTransportServerTemplatePath := "nginx.transportserver.tmpl"
+	if *nginxPlus {
+		nginxConfTemplatePath = "nginx-plus.tmpl"
+		nginxIngressTemplatePath = "nginx-plus.ingress.tmpl"
+		nginxVirtualServerTemplatePath = "nginx-plus.virtualserver.tmpl"
+		nginxTransportServerTemplatePath = "nginx-plus.transportserver.tmpl"
+	}
+
+	version1Path := filepath.Join(*templatesPath, "version1")
+	version2Path := filepath.Join(*templatesPath, "version2")
+
+	templateExecutor, err := version1.NewTemplateExecutor(filepath.Join(version1Path, nginxConfTemplatePath), filepath.Join(version1Path, nginxIngressTemplatePath))
+	if err != nil {
+		glog.Fatalf("Error creating TemplateExecutor: %v", err)
+	}
+
+	templateExecutorV2, err := version2.NewTemplateExecutor(filepath.Join(version2Path, nginxVirtualServerTemplatePath), filepath.Join(version2Path, nginxTransportServerTemplatePath))
+	if err != nil {
+		glog.Fatalf("Error creating TemplateExecutorV2: %v", err)
+	}
+
+	return templateExecutor, templateExecutorV2
+}
+
+func processConfigMaps(objects []runtime.Object, cfgParams *configs.ConfigParams, nginxManager nginx.Manager, templateExecutor *version1.TemplateExecutor) *configs.ConfigParams {
+	if *nginxConfigMaps == "" {
+		return cfgParams
+	}
+
+	ns, name, err := k8s.ParseNamespaceName(*nginxConfigMaps)
+	if err != nil {
+		glog.Fatalf("Error parsing the nginx-configmaps argument: %v", err)
+	}
+
+	var cfm *api_v1.ConfigMap
+	for _, obj := range objects {
+		if cm, ok := obj.(*api_v1.ConfigMap); ok && cm.Namespace == ns && cm.Name == name {
+			cfm = cm
+			break
+		}
+	}
+	if cfm == nil {
+		glog.Fatalf("ConfigMap %v not found among the manifests", *nginxConfigMaps)
+	}
+
+	cfgParams = configs.ParseConfigMap(cfm, *nginxPlus, false, false)
+	if cfgParams.MainServerSSLDHParamFileContent != nil {
+		fileName, err := nginxManager.CreateDHParam(*cfgParams.MainServerSSLDHParamFileContent)
+		if err != nil {
+			glog.Fatalf("Configmap %s/%s: Could not update dhparams: %v", ns, name, err)
+		}
+		cfgParams.MainServerSSLDHParam = fileName
+	}
+	if cfgParams.MainTemplate != nil {
+		err = templateExecutor.UpdateMainTemplate(cfgParams.MainTemplate)
+		if err != nil {
+			glog.Fatalf("Error updating NGINX main template: %v", err)
+		}
+	}
+	if cfgParams.IngressTemplate != nil {
+		err = templateExecutor.UpdateIngressTemplate(cfgParams.IngressTemplate)
+		if err != nil {
+			glog.Fatalf("Error updating ingress template: %v", err)
+		}
+	}
+
+	return cfgParams
+}
+
+// readManifests reads the objects from all YAML files in the directory.
+// A file can include multiple objects separated by "---".
+func readManifests(dir string) ([]runtime.Object, error) {
+	s := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{scheme.AddToScheme, conf_scheme.AddToScheme} {
+		if err := addToScheme(s); err != nil {
+			return nil, err
+		}
+	}
+	decoder := serializer.NewCodecFactory(s).UniversalDeserializer()
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []runtime.Object
+
+	for _, f := range files {
+		ext := filepath.Ext(f.Name())
+		if f.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		filename := filepath.Join(dir, f.Name())
+
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		reader := yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
+		for {
+			doc, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error reading %s: %w", filename, err)
+			}
+
+			if len(bytes.TrimSpace(doc)) == 0 {
+				continue
+			}
+
+			obj, _, err := decoder.Decode(doc, nil, nil)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding an object in %s: %w", filename, err)
+			}
+
+			objects = append(objects, obj)
+		}
+	}
+
+	return objects, nil
+}
+
+func printResult(w io.Writer, result *k8s.RenderResult) {
+	var keys []string
+	for key := range result.Warnings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(w, "Warning: %s: %s\n", key, strings.Join(result.Warnings[key], "; "))
+	}
+
+	for _, p := range result.Problems {
+		severity := "Warning"
+		if p.IsError {
+			severity = "Error"
+		}
+		fmt.Fprintf(w, "%s: %s: %s: %s\n", severity, getObjectName(p.Object), p.Reason, p.Message)
+	}
+}
+
+func getObjectName(obj runtime.Object) string {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return kind
+	}
+
+	return fmt.Sprintf("%s %s/%s", kind, accessor.GetNamespace(), accessor.GetName())
+}
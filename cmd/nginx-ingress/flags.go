@@ -122,6 +122,16 @@ var (
 	nginxReloadTimeout = flag.Int("nginx-reload-timeout", 60000,
 		`The timeout in milliseconds which the Ingress Controller will wait for a successful NGINX reload after a change or at the initial start. (default 60000)`)
 
+	nginxReloadBatchWindow = flag.Int("nginx-reload-batch-window", 0,
+		`The time in milliseconds during which the Ingress Controller batches the configuration changes into a single NGINX reload.
+	The reload happens when there were no configuration changes during the window since the last change. If 0, NGINX is reloaded after every change. (default 0)`)
+
+	nginxReloadBatchMaxDelay = flag.Int("nginx-reload-batch-max-delay", 5000,
+		`The maximum time in milliseconds by which the Ingress Controller delays an NGINX reload when batching the configuration changes. Requires -nginx-reload-batch-window. (default 5000)`)
+
+	syncWorkers = flag.Int("sync-workers", 1,
+		`The number of workers that sync the changes of the resources. The changes of a resource are always synced in order by a single worker. The changes of the NGINX configuration are applied one at a time and in order. (default 1)`)
+
 	wildcardTLSSecret = flag.String("wildcard-tls-secret", "",
 		`A Secret with a TLS certificate and key for TLS termination of every Ingress/VirtualServer host for which TLS termination is enabled but the Secret is not specified.
 		Format: <namespace>/<name>. If the argument is not set, for such Ingress/VirtualServer hosts NGINX will break any attempt to establish a TLS connection.
@@ -145,6 +155,15 @@ var (
 	enableOIDC = flag.Bool("enable-oidc", false,
 		"Enable OIDC Policies.")
 
+	enableGeoIP2 = flag.Bool("enable-geoip2", false,
+		"Enable geoAccess Policies. Loads the GeoIP2 dynamic module, which must be installed in the image of the Ingress Controller.")
+
+	enableBrotli = flag.Bool("enable-brotli", false,
+		"Enable the brotli algorithm in compression Policies. Loads the brotli dynamic module, which must be installed in the image of the Ingress Controller.")
+
+	enableAPIKeyAuth = flag.Bool("enable-apikey-auth", false,
+		"Enable apiKey Policies. Loads the njs dynamic module for the authentication of the API keys.")
+
 	enableSnippets = flag.Bool("enable-snippets", false,
 		"Enable custom NGINX configuration snippets in Ingress, VirtualServer, VirtualServerRoute and TransportServer resources.")
 
@@ -174,6 +193,15 @@ var (
 	enableExternalDNS = flag.Bool("enable-external-dns", false,
 		"Enable external-dns controller for VirtualServer resources. Requires -enable-custom-resources")
 
+	enableEndpointSlices = flag.Bool("enable-endpoint-slices", true,
+		"Use EndpointSlices (discovery.k8s.io/v1) instead of Endpoints to discover the endpoints of services. Set to false for Kubernetes clusters older than 1.21")
+
+	enableGatewayAPI = flag.Bool("enable-gateway-api", false,
+		"Enable support for the Gateway API (GatewayClass, Gateway and HTTPRoute resources). Requires -enable-custom-resources")
+
+	enableTrafficShifting = flag.Bool("enable-traffic-shifting", false,
+		"Enable support for the TrafficShift resources. Requires -enable-custom-resources and either -nginx-plus or -enable-latency-metrics")
+
 	startupCheckFn func() error
 )
 
@@ -250,6 +278,30 @@ func parseFlags(versionInfo string, binaryInfo string) {
 		glog.Fatal("enable-external-dns flag requires -enable-custom-resources")
 	}
 
+	if *enableGatewayAPI && !*enableCustomResources {
+		glog.Fatal("enable-gateway-api flag requires -enable-custom-resources")
+	}
+
+	if *enableTrafficShifting && !*enableCustomResources {
+		glog.Fatal("enable-traffic-shifting flag requires -enable-custom-resources")
+	}
+
+	if *enableTrafficShifting && !*nginxPlus && !*enableLatencyMetrics {
+		glog.Fatal("enable-traffic-shifting flag requires -nginx-plus or -enable-latency-metrics")
+	}
+
+	if *enableGeoIP2 && !*enableCustomResources {
+		glog.Fatal("enable-geoip2 flag requires -enable-custom-resources")
+	}
+
+	if *enableBrotli && !*enableCustomResources {
+		glog.Fatal("enable-brotli flag requires -enable-custom-resources")
+	}
+
+	if *enableAPIKeyAuth && !*enableCustomResources {
+		glog.Fatal("enable-apikey-auth flag requires -enable-custom-resources")
+	}
+
 	if *ingressLink != "" && *externalService != "" {
 		glog.Fatal("ingresslink and external-service cannot both be set")
 	}
@@ -308,6 +360,18 @@ func validationChecks() {
 		glog.Fatalf("Invalid value for ready-status-port: %v", readyStatusPortValidationError)
 	}
 
+	if *nginxReloadBatchWindow < 0 {
+		glog.Fatalf("Invalid value for nginx-reload-batch-window: %v. The value must not be negative", *nginxReloadBatchWindow)
+	}
+
+	if *nginxReloadBatchMaxDelay < *nginxReloadBatchWindow {
+		glog.Fatalf("Invalid value for nginx-reload-batch-max-delay: %v. The value must not be less than nginx-reload-batch-window", *nginxReloadBatchMaxDelay)
+	}
+
+	if *syncWorkers < 1 {
+		glog.Fatalf("Invalid value for sync-workers: %v. The value must be greater than 0", *syncWorkers)
+	}
+
 	var err error
 	allowedCIDRs, err = parseNginxStatusAllowCIDRs(*nginxStatusAllowCIDRs)
 	if err != nil {
@@ -35,6 +35,8 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	gateway_versioned "sigs.k8s.io/gateway-api/pkg/client/clientset/gateway/versioned"
+	gateway_scheme "sigs.k8s.io/gateway-api/pkg/client/clientset/gateway/versioned/scheme"
 )
 
 func main() {
@@ -49,6 +51,8 @@ func main() {
 
 	dynClient, confClient := createCustomClients(config)
 
+	gatewayClient := createGatewayClient(config)
+
 	constLabels := map[string]string{"class": *ingressClass}
 
 	managerCollector, controllerCollector, registry := createManagerAndControllerCollectors(constLabels)
@@ -86,6 +90,9 @@ func main() {
 		MainAppProtectDosLoadModule:    *appProtectDos,
 		EnableLatencyMetrics:           *enableLatencyMetrics,
 		EnableOIDC:                     *enableOIDC,
+		EnableGeoIP2:                   *enableGeoIP2,
+		EnableBrotli:                   *enableBrotli,
+		EnableAPIKeyAuth:               *enableAPIKeyAuth,
 		SSLRejectHandshake:             sslRejectHandshake,
 		EnableCertManager:              *enableCertManager,
 	}
@@ -106,6 +113,9 @@ func main() {
 
 	cnf := configs.NewConfigurator(nginxManager, staticCfgParams, cfgParams, templateExecutor,
 		templateExecutorV2, *nginxPlus, isWildcardEnabled, plusCollector, *enablePrometheusMetrics, latencyCollector, *enableLatencyMetrics)
+	if *nginxReloadBatchWindow > 0 {
+		cnf.EnableReloadBatching(time.Duration(*nginxReloadBatchWindow)*time.Millisecond, time.Duration(*nginxReloadBatchMaxDelay)*time.Millisecond)
+	}
 	controllerNamespace := os.Getenv("POD_NAMESPACE")
 
 	transportServerValidator := cr_validation.NewTransportServerValidator(*enableTLSPassthrough, *enableSnippets, *nginxPlus)
@@ -114,6 +124,7 @@ func main() {
 	lbcInput := k8s.NewLoadBalancerControllerInput{
 		KubeClient:                   kubeClient,
 		ConfClient:                   confClient,
+		GatewayClient:                gatewayClient,
 		DynClient:                    dynClient,
 		RestConfig:                   config,
 		ResyncPeriod:                 30 * time.Second,
@@ -134,7 +145,11 @@ func main() {
 		ConfigMaps:                   *nginxConfigMaps,
 		GlobalConfiguration:          *globalConfiguration,
 		AreCustomResourcesEnabled:    *enableCustomResources,
+		IsGatewayAPIEnabled:          *enableGatewayAPI,
 		EnableOIDC:                   *enableOIDC,
+		EnableGeoIP2:                 *enableGeoIP2,
+		EnableBrotli:                 *enableBrotli,
+		EnableAPIKeyAuth:             *enableAPIKeyAuth,
 		MetricsCollector:             controllerCollector,
 		GlobalConfigurationValidator: globalConfigurationValidator,
 		TransportServerValidator:     transportServerValidator,
@@ -143,10 +158,20 @@ func main() {
 		InternalRoutesEnabled:        *enableInternalRoutes,
 		IsPrometheusEnabled:          *enablePrometheusMetrics,
 		IsLatencyMetricsEnabled:      *enableLatencyMetrics,
+		IsEndpointSlicesEnabled:      *enableEndpointSlices,
 		IsTLSPassthroughEnabled:      *enableTLSPassthrough,
 		SnippetsEnabled:              *enableSnippets,
 		CertManagerEnabled:           *enableCertManager,
 		ExternalDNSEnabled:           *enableExternalDNS,
+		SyncWorkers:                  *syncWorkers,
+	}
+
+	if *enableTrafficShifting {
+		if plusClient != nil {
+			lbcInput.UpstreamResponsesSource = k8s.NewPlusUpstreamResponsesSource(plusClient)
+		} else {
+			lbcInput.UpstreamResponsesSource = k8s.NewLatencyUpstreamResponsesSource(latencyCollector)
+		}
 	}
 
 	lbc := k8s.NewLoadBalancerController(lbcInput)
@@ -217,6 +242,16 @@ func kubernetesVersionInfo(kubeClient kubernetes.Interface) {
 	if !k8sVersion.AtLeast(minK8sVersion) {
 		glog.Fatalf("Versions of Kubernetes < %v are not supported, please refer to the documentation for details on supported versions and legacy controller support.", minK8sVersion)
 	}
+
+	endpointSlicesK8sVersion, err := util_version.ParseGeneric("1.21.0")
+	if err != nil {
+		glog.Fatalf("unexpected error parsing minimum version for EndpointSlices: %v", err)
+	}
+
+	if *enableEndpointSlices && !k8sVersion.AtLeast(endpointSlicesK8sVersion) {
+		glog.Warningf("EndpointSlices (discovery.k8s.io/v1) are not available in Kubernetes < %v, falling back to Endpoints", endpointSlicesK8sVersion)
+		*enableEndpointSlices = false
+	}
 }
 
 func validateIngressClass(kubeClient kubernetes.Interface) {
@@ -255,6 +290,25 @@ func createCustomClients(config *rest.Config) (dynamic.Interface, k8s_nginx.Inte
 	return dynClient, confClient
 }
 
+func createGatewayClient(config *rest.Config) gateway_versioned.Interface {
+	if !*enableGatewayAPI {
+		return nil
+	}
+
+	gatewayClient, err := gateway_versioned.NewForConfig(config)
+	if err != nil {
+		glog.Fatalf("Failed to create a gateway client: %v", err)
+	}
+
+	// required for emitting Events for Gateway
+	err = gateway_scheme.AddToScheme(scheme.Scheme)
+	if err != nil {
+		glog.Fatalf("Failed to add gateway types to the scheme: %v", err)
+	}
+
+	return gatewayClient
+}
+
 func createPlusClient(nginxPlus bool, useFakeNginxManager bool, nginxManager nginx.Manager) *client.NginxClient {
 	var plusClient *client.NginxClient
 	var err error
@@ -602,7 +656,7 @@ func createPlusAndLatencyCollectors(
 			upstreamServerPeerVariableLabelNames = append(upstreamServerPeerVariableLabelNames, "pod_owner")
 		}
 		if *nginxPlus {
-			streamUpstreamServerVariableLabels := []string{"service", "resource_type", "resource_name", "resource_namespace"}
+			streamUpstreamServerVariableLabels := []string{"service", "resource_type", "resource_name", "resource_namespace", "load_balancing_method"}
 			streamUpstreamServerPeerVariableLabelNames := []string{"pod_name"}
 
 			serverZoneVariableLabels := []string{"resource_type", "resource_name", "resource_namespace"}
@@ -125,6 +125,9 @@ type StaticConfigParams struct {
 	InternalRouteServerName        string
 	EnableLatencyMetrics           bool
 	EnableOIDC                     bool
+	EnableGeoIP2                   bool
+	EnableBrotli                   bool
+	EnableAPIKeyAuth               bool
 	SSLRejectHandshake             bool
 	EnableCertManager              bool
 }
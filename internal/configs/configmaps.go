@@ -594,6 +594,9 @@ func GenerateNginxMainConfig(staticCfgParams *StaticConfigParams, config *Config
 		InternalRouteServerName:            staticCfgParams.InternalRouteServerName,
 		LatencyMetrics:                     staticCfgParams.EnableLatencyMetrics,
 		OIDC:                               staticCfgParams.EnableOIDC,
+		GeoIP2:                             staticCfgParams.EnableGeoIP2,
+		Brotli:                             staticCfgParams.EnableBrotli,
+		APIKeyAuth:                         staticCfgParams.EnableAPIKeyAuth,
 	}
 	return nginxCfg
 }
@@ -2,10 +2,15 @@ package configs
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/nginxinc/kubernetes-ingress/pkg/apis/dos/v1beta1"
 
@@ -38,6 +43,7 @@ const (
 	appProtectUserSigIndex          = "/etc/nginx/waf/nac-usersigs/index.conf"
 	appProtectDosPolicyFolder       = "/etc/nginx/dos/policies/"
 	appProtectDosLogConfFolder      = "/etc/nginx/dos/logconfs/"
+	sniListenersConfigName          = "sni-listeners"
 )
 
 // DefaultServerSecretPath is the full path to the Secret with a TLS cert and a key for the default server. #nosec G101
@@ -83,6 +89,14 @@ type tlsPassthroughPair struct {
 	UnixSocket string
 }
 
+// sniHost is a host of a TransportServer routed by SNI on a listener shared with other TransportServers.
+type sniHost struct {
+	Listener   string
+	Port       int
+	Host       string
+	UnixSocket string
+}
+
 // metricLabelsIndex keeps the relations between Ingress Controller resources and NGINX configuration.
 // Used to be able to add Prometheus Metrics variable labels grouped by resource key.
 type metricLabelsIndex struct {
@@ -111,7 +125,9 @@ type Configurator struct {
 	ingresses               map[string]*IngressEx
 	minions                 map[string]map[string]bool
 	virtualServers          map[string]*VirtualServerEx
+	vsCircuitBreakers       map[string]map[string]*conf_v1.CircuitBreaker
 	tlsPassthroughPairs     map[string]tlsPassthroughPair
+	sniHosts                map[string]sniHost
 	isWildcardEnabled       bool
 	isPlus                  bool
 	labelUpdater            collector.LabelUpdater
@@ -120,6 +136,7 @@ type Configurator struct {
 	latencyCollector        latCollector.LatencyCollector
 	isLatencyMetricsEnabled bool
 	isReloadsEnabled        bool
+	reloadCoalescer         *reloadCoalescer
 }
 
 // NewConfigurator creates a new Configurator.
@@ -145,10 +162,12 @@ func NewConfigurator(nginxManager nginx.Manager, staticCfgParams *StaticConfigPa
 		cfgParams:               config,
 		ingresses:               make(map[string]*IngressEx),
 		virtualServers:          make(map[string]*VirtualServerEx),
+		vsCircuitBreakers:       make(map[string]map[string]*conf_v1.CircuitBreaker),
 		templateExecutor:        templateExecutor,
 		templateExecutorV2:      templateExecutorV2,
 		minions:                 make(map[string]map[string]bool),
 		tlsPassthroughPairs:     make(map[string]tlsPassthroughPair),
+		sniHosts:                make(map[string]sniHost),
 		isPlus:                  isPlus,
 		isWildcardEnabled:       isWildcardEnabled,
 		labelUpdater:            labelUpdater,
@@ -478,6 +497,7 @@ func (cnf *Configurator) addOrUpdateVirtualServer(virtualServerEx *VirtualServer
 	cnf.nginxManager.CreateConfig(name, content)
 
 	cnf.virtualServers[name] = virtualServerEx
+	cnf.vsCircuitBreakers[name] = vsc.circuitBreakers
 
 	if (cnf.isPlus && cnf.isPrometheusEnabled) || cnf.isLatencyMetricsEnabled {
 		cnf.updateVirtualServerMetricsLabels(virtualServerEx, vsCfg.Upstreams)
@@ -504,6 +524,15 @@ func (cnf *Configurator) AddOrUpdateVirtualServers(virtualServerExes []*VirtualS
 	return allWarnings, nil
 }
 
+// getLoadBalancingMethodLabel returns the name of the load balancing method of the directive of a stream upstream.
+// No directive means the round_robin method.
+func getLoadBalancingMethodLabel(method string) string {
+	if method == "" {
+		return "round_robin"
+	}
+	return strings.Fields(method)[0]
+}
+
 func (cnf *Configurator) updateTransportServerMetricsLabels(transportServerEx *TransportServerEx, upstreams []version2.StreamUpstream) {
 	labels := make(map[string][]string)
 	newUpstreams := make(map[string]bool)
@@ -514,7 +543,8 @@ func (cnf *Configurator) updateTransportServerMetricsLabels(transportServerEx *T
 	var newPeersIPs []string
 
 	for _, u := range upstreams {
-		labels[u.Name] = []string{u.UpstreamLabels.Service, u.UpstreamLabels.ResourceType, u.UpstreamLabels.ResourceName, u.UpstreamLabels.ResourceNamespace}
+		labels[u.Name] = []string{u.UpstreamLabels.Service, u.UpstreamLabels.ResourceType, u.UpstreamLabels.ResourceName, u.UpstreamLabels.ResourceNamespace,
+			getLoadBalancingMethodLabel(u.LoadBalancingMethod)}
 		newUpstreams[u.Name] = true
 		newUpstreamsNames = append(newUpstreamsNames, u.Name)
 
@@ -573,27 +603,27 @@ func (cnf *Configurator) deleteTransportServerMetricsLabels(key string) {
 
 // AddOrUpdateTransportServer adds or updates NGINX configuration for the TransportServer resource.
 // It is a responsibility of the caller to check that the TransportServer references an existing listener.
-func (cnf *Configurator) AddOrUpdateTransportServer(transportServerEx *TransportServerEx) error {
-	err := cnf.addOrUpdateTransportServer(transportServerEx)
+func (cnf *Configurator) AddOrUpdateTransportServer(transportServerEx *TransportServerEx) (Warnings, error) {
+	warnings, err := cnf.addOrUpdateTransportServer(transportServerEx)
 	if err != nil {
-		return fmt.Errorf("Error adding or updating TransportServer %v/%v: %w", transportServerEx.TransportServer.Namespace, transportServerEx.TransportServer.Name, err)
+		return warnings, fmt.Errorf("Error adding or updating TransportServer %v/%v: %w", transportServerEx.TransportServer.Namespace, transportServerEx.TransportServer.Name, err)
 	}
 
 	if err := cnf.reload(nginx.ReloadForOtherUpdate); err != nil {
-		return fmt.Errorf("Error reloading NGINX for TransportServer %v/%v: %w", transportServerEx.TransportServer.Namespace, transportServerEx.TransportServer.Name, err)
+		return warnings, fmt.Errorf("Error reloading NGINX for TransportServer %v/%v: %w", transportServerEx.TransportServer.Namespace, transportServerEx.TransportServer.Name, err)
 	}
 
-	return nil
+	return warnings, nil
 }
 
-func (cnf *Configurator) addOrUpdateTransportServer(transportServerEx *TransportServerEx) error {
+func (cnf *Configurator) addOrUpdateTransportServer(transportServerEx *TransportServerEx) (Warnings, error) {
 	name := getFileNameForTransportServer(transportServerEx.TransportServer)
 
-	tsCfg := generateTransportServerConfig(transportServerEx, transportServerEx.ListenerPort, cnf.isPlus)
+	tsCfg, warnings := generateTransportServerConfig(transportServerEx, transportServerEx.ListenerPort, cnf.isPlus)
 
 	content, err := cnf.templateExecutorV2.ExecuteTransportServerTemplate(tsCfg)
 	if err != nil {
-		return fmt.Errorf("Error generating TransportServer config %v: %w", name, err)
+		return warnings, fmt.Errorf("Error generating TransportServer config %v: %w", name, err)
 	}
 
 	if cnf.isPlus && cnf.isPrometheusEnabled {
@@ -602,19 +632,39 @@ func (cnf *Configurator) addOrUpdateTransportServer(transportServerEx *Transport
 
 	cnf.nginxManager.CreateStreamConfig(name, content)
 
+	key := generateNamespaceNameKey(&transportServerEx.TransportServer.ObjectMeta)
+
+	// update SNI listeners config in case the TransportServer is or was routed by SNI
+	_, wasRoutedBySNI := cnf.sniHosts[key]
+	if isRoutedBySNI(transportServerEx.TransportServer) {
+		cnf.sniHosts[key] = sniHost{
+			Listener:   transportServerEx.TransportServer.Spec.Listener.Name,
+			Port:       transportServerEx.ListenerPort,
+			Host:       transportServerEx.TransportServer.Spec.Host,
+			UnixSocket: generateUnixSocket(transportServerEx),
+		}
+
+		return warnings, cnf.updateSNIListenersConfig()
+	}
+	if wasRoutedBySNI {
+		delete(cnf.sniHosts, key)
+
+		if err := cnf.updateSNIListenersConfig(); err != nil {
+			return warnings, err
+		}
+	}
+
 	// update TLS Passthrough Hosts config in case we have a TLS Passthrough TransportServer
-	// only TLS Passthrough TransportServers have non-empty hosts
-	if transportServerEx.TransportServer.Spec.Host != "" {
-		key := generateNamespaceNameKey(&transportServerEx.TransportServer.ObjectMeta)
+	if transportServerEx.TransportServer.Spec.Listener.Name == conf_v1alpha1.TLSPassthroughListenerName {
 		cnf.tlsPassthroughPairs[key] = tlsPassthroughPair{
 			Host:       transportServerEx.TransportServer.Spec.Host,
 			UnixSocket: generateUnixSocket(transportServerEx),
 		}
 
-		return cnf.updateTLSPassthroughHostsConfig()
+		return warnings, cnf.updateTLSPassthroughHostsConfig()
 	}
 
-	return nil
+	return warnings, nil
 }
 
 // GetVirtualServerRoutesForVirtualServer returns the virtualServerRoutes that a virtualServer
@@ -650,6 +700,52 @@ func generateTLSPassthroughHostsConfig(tlsPassthroughPairs map[string]tlsPassthr
 	return &cfg
 }
 
+func (cnf *Configurator) updateSNIListenersConfig() error {
+	cfg := generateSNIListenersConfig(cnf.sniHosts)
+
+	content, err := cnf.templateExecutorV2.ExecuteSNIListenersTemplate(cfg)
+	if err != nil {
+		return fmt.Errorf("Error generating config for SNI listeners: %w", err)
+	}
+
+	cnf.nginxManager.CreateStreamConfig(sniListenersConfigName, content)
+
+	return nil
+}
+
+func generateSNIListenersConfig(sniHosts map[string]sniHost) *version2.SNIListenersConfig {
+	listeners := make(map[string]*version2.SNIListener)
+
+	for _, h := range sniHosts {
+		l, exists := listeners[h.Listener]
+		if !exists {
+			l = &version2.SNIListener{
+				Name:              h.Listener,
+				Port:              h.Port,
+				Variable:          fmt.Sprintf("$dest_sni_%s", strings.ReplaceAll(h.Listener, "-", "_")),
+				Hosts:             make(map[string]string),
+				DefaultUnixSocket: nginxNonExistingUnixSocket,
+			}
+			listeners[h.Listener] = l
+		}
+
+		l.Hosts[h.Host] = h.UnixSocket
+	}
+
+	names := make([]string, 0, len(listeners))
+	for name := range listeners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cfg := version2.SNIListenersConfig{}
+	for _, name := range names {
+		cfg = append(cfg, *listeners[name])
+	}
+
+	return &cfg
+}
+
 func (cnf *Configurator) addOrUpdateCASecret(secret *api_v1.Secret) string {
 	name := objectMetaToFileName(&secret.ObjectMeta)
 	data := GenerateCAFileContent(secret)
@@ -668,6 +764,12 @@ func (cnf *Configurator) addOrUpdateHtpasswdSecret(secret *api_v1.Secret) string
 	return cnf.nginxManager.CreateSecret(name, data, nginx.HtpasswdSecretFileMode)
 }
 
+func (cnf *Configurator) addOrUpdateAPIKeySecret(secret *api_v1.Secret) string {
+	name := objectMetaToFileName(&secret.ObjectMeta)
+	data := GenerateAPIKeyFileContent(secret)
+	return cnf.nginxManager.CreateSecret(name, data, nginx.APIKeySecretFileMode)
+}
+
 // AddOrUpdateResources adds or updates configuration for resources.
 func (cnf *Configurator) AddOrUpdateResources(resources ExtendedResources) (Warnings, error) {
 	allWarnings := newWarnings()
@@ -697,10 +799,11 @@ func (cnf *Configurator) AddOrUpdateResources(resources ExtendedResources) (Warn
 	}
 
 	for _, tsEx := range resources.TransportServerExes {
-		err := cnf.addOrUpdateTransportServer(tsEx)
+		warnings, err := cnf.addOrUpdateTransportServer(tsEx)
 		if err != nil {
 			return allWarnings, fmt.Errorf("Error adding or updating TransportServer %v/%v: %w", tsEx.TransportServer.Namespace, tsEx.TransportServer.Name, err)
 		}
+		allWarnings.Add(warnings)
 	}
 
 	if err := cnf.reload(nginx.ReloadForOtherUpdate); err != nil {
@@ -751,6 +854,27 @@ func GenerateCAFileContent(secret *api_v1.Secret) []byte {
 	return res.Bytes()
 }
 
+// GenerateAPIKeyFileContent generates the content of the file with the API keys of a Secret.
+// The file holds the entries of an NGINX map that maps the SHA-256 hash of an API key to the client ID,
+// so that the API keys themselves are not written to the file system. The hashes are base64url-encoded
+// rather than hex-encoded to fit into the default map_hash_bucket_size.
+func GenerateAPIKeyFileContent(secret *api_v1.Secret) []byte {
+	var res bytes.Buffer
+
+	clientIDs := make([]string, 0, len(secret.Data))
+	for clientID := range secret.Data {
+		clientIDs = append(clientIDs, clientID)
+	}
+	sort.Strings(clientIDs)
+
+	for _, clientID := range clientIDs {
+		hash := sha256.Sum256(secret.Data[clientID])
+		res.WriteString(fmt.Sprintf("\"%s\" \"%s\";\n", base64.RawURLEncoding.EncodeToString(hash[:]), clientID))
+	}
+
+	return res.Bytes()
+}
+
 // DeleteIngress deletes NGINX configuration for the Ingress resource.
 func (cnf *Configurator) DeleteIngress(key string) error {
 	name := keyToFileName(key)
@@ -776,6 +900,7 @@ func (cnf *Configurator) DeleteVirtualServer(key string) error {
 	cnf.nginxManager.DeleteConfig(name)
 
 	delete(cnf.virtualServers, name)
+	delete(cnf.vsCircuitBreakers, name)
 	if (cnf.isPlus && cnf.isPrometheusEnabled) || cnf.isLatencyMetricsEnabled {
 		cnf.deleteVirtualServerMetricsLabels(key)
 	}
@@ -810,6 +935,13 @@ func (cnf *Configurator) deleteTransportServer(key string) error {
 	name := getFileNameForTransportServerFromKey(key)
 	cnf.nginxManager.DeleteStreamConfig(name)
 
+	// update SNI listeners config in case we have a TransportServer routed by SNI
+	if _, exists := cnf.sniHosts[key]; exists {
+		delete(cnf.sniHosts, key)
+
+		return cnf.updateSNIListenersConfig()
+	}
+
 	// update TLS Passthrough Hosts config in case we have a TLS Passthrough TransportServer
 	if _, exists := cnf.tlsPassthroughPairs[key]; exists {
 		delete(cnf.tlsPassthroughPairs, key)
@@ -919,7 +1051,8 @@ func (cnf *Configurator) UpdateEndpointsForVirtualServers(virtualServerExes []*V
 }
 
 func (cnf *Configurator) updatePlusEndpointsForVirtualServer(virtualServerEx *VirtualServerEx) error {
-	upstreams := createUpstreamsForPlus(virtualServerEx, cnf.cfgParams, cnf.staticCfgParams)
+	circuitBreakers := cnf.vsCircuitBreakers[getFileNameForVirtualServer(virtualServerEx.VirtualServer)]
+	upstreams := createUpstreamsForPlus(virtualServerEx, circuitBreakers, cnf.cfgParams, cnf.staticCfgParams)
 	for _, upstream := range upstreams {
 		serverCfg := createUpstreamServersConfigForPlus(upstream)
 
@@ -939,7 +1072,8 @@ func (cnf *Configurator) UpdateEndpointsForTransportServers(transportServerExes
 	reloadPlus := false
 
 	for _, tsEx := range transportServerExes {
-		err := cnf.addOrUpdateTransportServer(tsEx)
+		// It is safe to ignore warnings here as no new warnings should appear when updating Endpoints for TransportServers
+		_, err := cnf.addOrUpdateTransportServer(tsEx)
 		if err != nil {
 			return fmt.Errorf("Error adding or updating TransportServer %v/%v: %w", tsEx.TransportServer.Namespace, tsEx.TransportServer.Name, err)
 		}
@@ -1039,11 +1173,68 @@ func (cnf *Configurator) EnableReloads() {
 	cnf.isReloadsEnabled = true
 }
 
+// EnableReloadBatching makes the Configurator batch the reloads for the configuration changes.
+// Instead of reloading NGINX after every change, the Configurator reloads NGINX once via ReloadBatch
+// when the channel returned by ReloadBatchDue receives a value: either when there were no changes during the window
+// since the last change or when maxDelay since the first change of the batch has passed.
+func (cnf *Configurator) EnableReloadBatching(window time.Duration, maxDelay time.Duration) {
+	cnf.reloadCoalescer = newReloadCoalescer(window, maxDelay)
+}
+
+// ReloadBatchDue returns a channel that receives a value when the batched reload is due.
+// If reload batching is not enabled, the channel never receives a value.
+func (cnf *Configurator) ReloadBatchDue() <-chan struct{} {
+	if cnf.reloadCoalescer == nil {
+		return nil
+	}
+	return cnf.reloadCoalescer.due
+}
+
+// AfterReload calls the callback with the outcome of the reload that applies the configuration changes made so far.
+// If the reload is batched, the callback is called by ReloadBatch. Otherwise, NGINX was already reloaded,
+// so the callback is called immediately with a nil error.
+func (cnf *Configurator) AfterReload(callback func(reloadErr error)) {
+	if cnf.reloadCoalescer != nil && cnf.reloadCoalescer.addCallback(callback) {
+		return
+	}
+	callback(nil)
+}
+
+// ReloadBatch reloads NGINX for the batched configuration changes, if there are any,
+// and calls the callbacks added via AfterReload with the outcome of the reload.
+func (cnf *Configurator) ReloadBatch() {
+	if cnf.reloadCoalescer == nil {
+		return
+	}
+
+	batch := cnf.reloadCoalescer.take()
+	if !batch.pending {
+		return
+	}
+
+	glog.V(3).Infof("Reloading NGINX for the batched changes of %d resource(s)", len(batch.callbacks))
+
+	err := cnf.nginxManager.Reload(batch.isEndpointsUpdate)
+	if err != nil {
+		err = fmt.Errorf("Error reloading NGINX for the batched changes: %w", err)
+		glog.Error(err)
+	}
+
+	for _, callback := range batch.callbacks {
+		callback(err)
+	}
+}
+
 func (cnf *Configurator) reload(isEndpointsUpdate bool) error {
 	if !cnf.isReloadsEnabled {
 		return nil
 	}
 
+	if cnf.reloadCoalescer != nil {
+		cnf.reloadCoalescer.request(isEndpointsUpdate, time.Now())
+		return nil
+	}
+
 	return cnf.nginxManager.Reload(isEndpointsUpdate)
 }
 
@@ -1129,7 +1320,7 @@ func (cnf *Configurator) UpdateConfig(cfgParams *ConfigParams, resources Extende
 
 	// we don't need to regenerate config for TransportServers, because:
 	// (1) Changes to the ConfigMap don't affect TransportServer configs directly
-	// (2) addOrUpdateTransportServer doesn't return any warnings that we need to propagate to the caller.
+	// (2) The warnings of TransportServers don't depend on the ConfigMap, so there are no new warnings to propagate to the caller.
 	// if (1) and (2) is no longer the case, we need to generate the config for TransportServers
 
 	if mainCfg.OpenTracingLoadModule {
@@ -1147,26 +1338,29 @@ func (cnf *Configurator) UpdateConfig(cfgParams *ConfigParams, resources Extende
 }
 
 // UpdateTransportServers updates TransportServers.
-func (cnf *Configurator) UpdateTransportServers(updatedTSExes []*TransportServerEx, deletedKeys []string) error {
+func (cnf *Configurator) UpdateTransportServers(updatedTSExes []*TransportServerEx, deletedKeys []string) (Warnings, error) {
+	allWarnings := newWarnings()
+
 	for _, tsEx := range updatedTSExes {
-		err := cnf.addOrUpdateTransportServer(tsEx)
+		warnings, err := cnf.addOrUpdateTransportServer(tsEx)
 		if err != nil {
-			return fmt.Errorf("Error adding or updating TransportServer %v/%v: %w", tsEx.TransportServer.Namespace, tsEx.TransportServer.Name, err)
+			return allWarnings, fmt.Errorf("Error adding or updating TransportServer %v/%v: %w", tsEx.TransportServer.Namespace, tsEx.TransportServer.Name, err)
 		}
+		allWarnings.Add(warnings)
 	}
 
 	for _, key := range deletedKeys {
 		err := cnf.deleteTransportServer(key)
 		if err != nil {
-			return fmt.Errorf("Error when removing TransportServer %v: %w", key, err)
+			return allWarnings, fmt.Errorf("Error when removing TransportServer %v: %w", key, err)
 		}
 	}
 
 	if err := cnf.reload(nginx.ReloadForOtherUpdate); err != nil {
-		return fmt.Errorf("Error when updating TransportServers: %w", err)
+		return allWarnings, fmt.Errorf("Error when updating TransportServers: %w", err)
 	}
 
-	return nil
+	return allWarnings, nil
 }
 
 func keyToFileName(key string) string {
@@ -1199,6 +1393,65 @@ func getFileNameForTransportServerFromKey(key string) string {
 	return fmt.Sprintf("ts_%s", replaced)
 }
 
+// GetIngressError returns the error of the operation for the Ingress. If NGINX rejected the config of the Ingress,
+// the error includes the rejected directives and their lines.
+func (cnf *Configurator) GetIngressError(err error, ing *networking.Ingress) error {
+	return getConfigFileError(err, cnf.nginxManager.GetFilenameForConfig(objectMetaToFileName(&ing.ObjectMeta)))
+}
+
+// GetVirtualServerError returns the error of the operation for the VirtualServer. If NGINX rejected the config of
+// the VirtualServer, the error includes the rejected directives and their lines.
+func (cnf *Configurator) GetVirtualServerError(err error, vs *conf_v1.VirtualServer) error {
+	return getConfigFileError(err, cnf.nginxManager.GetFilenameForConfig(getFileNameForVirtualServer(vs)))
+}
+
+// GetTransportServerError returns the error of the operation for the TransportServer. If NGINX rejected the config of
+// the TransportServer, the error includes the rejected directives and their lines.
+func (cnf *Configurator) GetTransportServerError(err error, ts *conf_v1alpha1.TransportServer) error {
+	return getConfigFileError(err, cnf.nginxManager.GetFilenameForStreamConfig(getFileNameForTransportServer(ts)))
+}
+
+// ReloadBlockedError is the error of the operation for a resource whose config file NGINX accepted, when the
+// config was not applied because NGINX rejected the config files of other resources.
+type ReloadBlockedError struct {
+	// Filenames are the names of the rejected config files.
+	Filenames []string
+}
+
+func (e *ReloadBlockedError) Error() string {
+	return fmt.Sprintf("the config was not applied because NGINX rejected the config in %s", strings.Join(e.Filenames, ", "))
+}
+
+// getConfigFileError narrows down the error to the errors in the config file, if NGINX rejected the config.
+// If NGINX rejected only other config files, nil is returned if NGINX was reloaded without the rejected files,
+// and a *ReloadBlockedError otherwise.
+// If the errors of NGINX can't be attributed to config files, the error is returned unchanged.
+func getConfigFileError(err error, filename string) error {
+	var validationErr *nginx.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+
+	if len(validationErr.Errors) == 0 {
+		return err
+	}
+
+	configErrs := validationErr.ErrorsForFile(filename)
+	if len(configErrs) == 0 {
+		if validationErr.Applied {
+			return nil
+		}
+		return &ReloadBlockedError{Filenames: validationErr.Filenames()}
+	}
+
+	var msgs []string
+	for _, configErr := range configErrs {
+		msgs = append(msgs, configErr.Error())
+	}
+
+	return fmt.Errorf("NGINX rejected the config: %s", strings.Join(msgs, "; "))
+}
+
 // HasIngress checks if the Ingress resource is present in NGINX configuration.
 func (cnf *Configurator) HasIngress(ing *networking.Ingress) bool {
 	name := objectMetaToFileName(&ing.ObjectMeta)
@@ -1516,6 +1769,8 @@ func (cnf *Configurator) AddOrUpdateSecret(secret *api_v1.Secret) string {
 		return cnf.addOrUpdateJWKSecret(secret)
 	case secrets.SecretTypeHtpasswd:
 		return cnf.addOrUpdateHtpasswdSecret(secret)
+	case secrets.SecretTypeAPIKey:
+		return cnf.addOrUpdateAPIKeySecret(secret)
 	case secrets.SecretTypeOIDC:
 		// OIDC ClientSecret is not required on the filesystem, it is written directly to the config file.
 		return ""
@@ -1,18 +1,23 @@
 package configs
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/prometheus/client_golang/prometheus"
+	api_v1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/nginxinc/kubernetes-ingress/internal/configs/version1"
 	"github.com/nginxinc/kubernetes-ingress/internal/configs/version2"
+	"github.com/nginxinc/kubernetes-ingress/internal/k8s/secrets"
 	"github.com/nginxinc/kubernetes-ingress/internal/nginx"
 	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
 	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
@@ -328,6 +333,81 @@ func TestGenerateTLSPassthroughHostsConfig(t *testing.T) {
 	}
 }
 
+func TestGenerateSNIListenersConfig(t *testing.T) {
+	t.Parallel()
+	sniHosts := map[string]sniHost{
+		"default/ts-1": {
+			Listener:   "tcp-443",
+			Port:       443,
+			Host:       "one.example.com",
+			UnixSocket: "socket1.sock",
+		},
+		"default/ts-2": {
+			Listener:   "tcp-443",
+			Port:       443,
+			Host:       "two.example.com",
+			UnixSocket: "socket2.sock",
+		},
+		"default/ts-3": {
+			Listener:   "db",
+			Port:       5432,
+			Host:       "db.example.com",
+			UnixSocket: "socket3.sock",
+		},
+	}
+
+	expectedCfg := &version2.SNIListenersConfig{
+		{
+			Name:     "db",
+			Port:     5432,
+			Variable: "$dest_sni_db",
+			Hosts: map[string]string{
+				"db.example.com": "socket3.sock",
+			},
+			DefaultUnixSocket: nginxNonExistingUnixSocket,
+		},
+		{
+			Name:     "tcp-443",
+			Port:     443,
+			Variable: "$dest_sni_tcp_443",
+			Hosts: map[string]string{
+				"one.example.com": "socket1.sock",
+				"two.example.com": "socket2.sock",
+			},
+			DefaultUnixSocket: nginxNonExistingUnixSocket,
+		},
+	}
+
+	resultCfg := generateSNIListenersConfig(sniHosts)
+	if !reflect.DeepEqual(resultCfg, expectedCfg) {
+		t.Errorf("generateSNIListenersConfig() returned %v but expected %v", resultCfg, expectedCfg)
+	}
+}
+
+func TestGenerateAPIKeyFileContent(t *testing.T) {
+	t.Parallel()
+	secret := &api_v1.Secret{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "api-key-secret",
+			Namespace: "default",
+		},
+		Type: secrets.SecretTypeAPIKey,
+		Data: map[string][]byte{
+			"client2": []byte("another-password"),
+			"client1": []byte("password"),
+		},
+	}
+
+	expected := `"XohImNooBHFR0OVvjcYpJ3NgPQ1qq73WKhHvch0VQtg" "client1";
+"W2y4Zrec-v_kFicY-X6sr6ZzLj00BiL8vahFgoQOuew" "client2";
+`
+
+	result := GenerateAPIKeyFileContent(secret)
+	if diff := cmp.Diff(expected, string(result)); diff != "" {
+		t.Errorf("GenerateAPIKeyFileContent() returned unexpected result (-want +got):\n%s", diff)
+	}
+}
+
 func TestAddInternalRouteConfig(t *testing.T) {
 	t.Parallel()
 	cnf, err := createTestConfigurator()
@@ -546,6 +626,9 @@ func (u *mockLatencyCollector) DeleteUpstreamServerPeerLabels(peers []string) {
 // RecordLatency implements a fake RecordLatency method
 func (u *mockLatencyCollector) RecordLatency(string) {}
 
+// GetUpstreamResponses implements a fake GetUpstreamResponses method
+func (u *mockLatencyCollector) GetUpstreamResponses(string) (uint64, uint64) { return 0, 0 }
+
 // Register implements a fake Register method
 func (u *mockLatencyCollector) Register(*prometheus.Registry) error { return nil }
 
@@ -920,7 +1003,8 @@ func TestUpdateTransportServerMetricsLabels(t *testing.T) {
 
 	streamUpstreams := []version2.StreamUpstream{
 		{
-			Name: "upstream-1",
+			Name:                "upstream-1",
+			LoadBalancingMethod: "hash ${remote_addr} consistent",
 			Servers: []version2.StreamUpstreamServer{
 				{
 					Address: "10.0.0.1:80",
@@ -950,8 +1034,8 @@ func TestUpdateTransportServerMetricsLabels(t *testing.T) {
 	}
 
 	streamUpstreamServerLabels := map[string][]string{
-		"upstream-1": {"service-1", "transportserver", "test-transportserver", "default"},
-		"upstream-2": {"service-2", "transportserver", "test-transportserver", "default"},
+		"upstream-1": {"service-1", "transportserver", "test-transportserver", "default", "hash"},
+		"upstream-2": {"service-2", "transportserver", "test-transportserver", "default", "round_robin"},
 	}
 
 	streamUpstreamServerPeerLabels := map[string][]string{
@@ -977,7 +1061,8 @@ func TestUpdateTransportServerMetricsLabels(t *testing.T) {
 
 	updatedStreamUpstreams := []version2.StreamUpstream{
 		{
-			Name: "upstream-1",
+			Name:                "upstream-1",
+			LoadBalancingMethod: "random two least_conn",
 			Servers: []version2.StreamUpstreamServer{
 				{
 					Address: "10.0.0.1:80",
@@ -993,7 +1078,7 @@ func TestUpdateTransportServerMetricsLabels(t *testing.T) {
 	}
 
 	streamUpstreamServerLabels = map[string][]string{
-		"upstream-1": {"service-1", "transportserver", "test-transportserver", "default"},
+		"upstream-1": {"service-1", "transportserver", "test-transportserver", "default", "random"},
 	}
 
 	streamUpstreamServerPeerLabels = map[string][]string{
@@ -1067,7 +1152,7 @@ func TestUpdateTransportServerMetricsLabels(t *testing.T) {
 	}
 
 	streamUpstreamServerLabels = map[string][]string{
-		"upstream-3": {"service-3", "transportserver", "test-transportserver-tls", "default"},
+		"upstream-3": {"service-3", "transportserver", "test-transportserver-tls", "default", "round_robin"},
 	}
 
 	streamUpstreamServerPeerLabels = map[string][]string{
@@ -1324,3 +1409,148 @@ func TestUpdateApResourcesForVs(t *testing.T) {
 		}
 	}
 }
+
+type reloadCountingManager struct {
+	*nginx.FakeManager
+	reloads []bool
+	err     error
+}
+
+func (m *reloadCountingManager) Reload(isEndpointsUpdate bool) error {
+	m.reloads = append(m.reloads, isEndpointsUpdate)
+	return m.err
+}
+
+func TestReloadBatch(t *testing.T) {
+	reloadErr := errors.New("reload failed")
+
+	tests := []struct {
+		managerErr      error
+		expectedErr     error
+		expectedReloads []bool
+		msg             string
+	}{
+		{
+			managerErr:      nil,
+			expectedErr:     nil,
+			expectedReloads: []bool{nginx.ReloadForOtherUpdate},
+			msg:             "successful reload",
+		},
+		{
+			managerErr:      reloadErr,
+			expectedErr:     reloadErr,
+			expectedReloads: []bool{nginx.ReloadForOtherUpdate},
+			msg:             "failed reload",
+		},
+	}
+
+	for _, test := range tests {
+		manager := &reloadCountingManager{
+			FakeManager: nginx.NewFakeManager("/etc/nginx"),
+			err:         test.managerErr,
+		}
+
+		cnf := NewConfigurator(manager, createTestStaticConfigParams(), NewDefaultConfigParams(false), &version1.TemplateExecutor{},
+			&version2.TemplateExecutor{}, false, false, nil, false, nil, false)
+		cnf.EnableReloads()
+		cnf.EnableReloadBatching(time.Hour, time.Hour)
+
+		var reportedErrs []error
+
+		for _, key := range []string{"default/cafe", "default/tea"} {
+			err := cnf.DeleteVirtualServer(key)
+			if err != nil {
+				t.Errorf("DeleteVirtualServer() returned unexpected error for the case of %s: %v", test.msg, err)
+			}
+
+			cnf.AfterReload(func(err error) {
+				reportedErrs = append(reportedErrs, err)
+			})
+		}
+
+		if len(manager.reloads) != 0 || len(reportedErrs) != 0 {
+			t.Errorf("Configurator reloaded NGINX before the batch was due for the case of %s", test.msg)
+		}
+
+		cnf.ReloadBatch()
+
+		if diff := cmp.Diff(test.expectedReloads, manager.reloads); diff != "" {
+			t.Errorf("ReloadBatch() reloads mismatch for the case of %s (-want +got):\n%s", test.msg, diff)
+		}
+
+		if len(reportedErrs) != 2 {
+			t.Fatalf("ReloadBatch() called %d callbacks, expected 2 for the case of %s", len(reportedErrs), test.msg)
+		}
+		for _, err := range reportedErrs {
+			if !errors.Is(err, test.expectedErr) || (err == nil) != (test.expectedErr == nil) {
+				t.Errorf("ReloadBatch() reported error %v, expected %v for the case of %s", err, test.expectedErr, test.msg)
+			}
+		}
+
+		// no changes since the last reload
+		cnf.ReloadBatch()
+		if len(manager.reloads) != len(test.expectedReloads) {
+			t.Errorf("ReloadBatch() reloaded NGINX without changes for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestAfterReloadWithoutBatching(t *testing.T) {
+	cnf, err := createTestConfigurator()
+	if err != nil {
+		t.Fatalf("Failed to create a test configurator: %v", err)
+	}
+
+	called := false
+	cnf.AfterReload(func(err error) {
+		called = true
+		if err != nil {
+			t.Errorf("AfterReload() called the callback with unexpected error: %v", err)
+		}
+	})
+
+	if !called {
+		t.Errorf("AfterReload() didn't call the callback immediately when reload batching is disabled")
+	}
+}
+
+func TestGetConfigFileError(t *testing.T) {
+	validationErr := &nginx.ValidationError{
+		Errors: []nginx.ConfigError{
+			{
+				Filename:  "/etc/nginx/conf.d/vs_default_cafe.conf",
+				Line:      25,
+				Message:   `unknown directive "proxy_passs"`,
+				Directive: "proxy_passs http://coffee;",
+			},
+		},
+	}
+	err := fmt.Errorf("Error reloading NGINX for the batched changes: %w", validationErr)
+
+	expected := `NGINX rejected the config: unknown directive "proxy_passs" in /etc/nginx/conf.d/vs_default_cafe.conf:25 ("proxy_passs http://coffee;")`
+	if result := getConfigFileError(err, "/etc/nginx/conf.d/vs_default_cafe.conf"); result.Error() != expected {
+		t.Errorf("getConfigFileError() returned %q but expected %q", result, expected)
+	}
+
+	var blockedErr *ReloadBlockedError
+	if result := getConfigFileError(err, "/etc/nginx/conf.d/vs_default_tea.conf"); !errors.As(result, &blockedErr) {
+		t.Errorf("getConfigFileError() returned %q but expected a *ReloadBlockedError", result)
+	} else if diff := cmp.Diff([]string{"/etc/nginx/conf.d/vs_default_cafe.conf"}, blockedErr.Filenames); diff != "" {
+		t.Errorf("getConfigFileError() returned unexpected rejected files (-want +got):\n%s", diff)
+	}
+
+	validationErr.Applied = true
+	if result := getConfigFileError(err, "/etc/nginx/conf.d/vs_default_tea.conf"); result != nil {
+		t.Errorf("getConfigFileError() returned %q for an applied config file but expected nil", result)
+	}
+
+	unlocatedErr := fmt.Errorf("Error reloading NGINX: %w", &nginx.ValidationError{Output: "nginx: [emerg] no memory"})
+	if result := getConfigFileError(unlocatedErr, "/etc/nginx/conf.d/vs_default_cafe.conf"); !errors.Is(result, unlocatedErr) {
+		t.Errorf("getConfigFileError() returned %q but expected the unchanged error %q", result, unlocatedErr)
+	}
+
+	otherErr := errors.New("reload failed")
+	if result := getConfigFileError(otherErr, "/etc/nginx/conf.d/vs_default_cafe.conf"); !errors.Is(result, otherErr) {
+		t.Errorf("getConfigFileError() returned %q but expected the unchanged error %q", result, otherErr)
+	}
+}
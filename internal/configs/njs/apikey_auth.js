@@ -0,0 +1,47 @@
+/*
+ * JavaScript functions for providing API key authentication with NGINX
+ *
+ * Copyright (C) 2022 Nginx, Inc.
+ */
+import crypto from 'crypto';
+
+export default { hash };
+
+// Returns the base64url-encoded SHA-256 hash of the API key of the request or an empty string if the request has no key.
+// The hash is matched against the hashes of the keys of the clients in the map of the API key policy.
+function hash(r) {
+    var key = getKey(r);
+    if (!key) {
+        return '';
+    }
+
+    return crypto.createHash('sha256').update(key).digest('base64url');
+}
+
+// Returns the value of the first of the headers or the query parameters set by the API key policy
+// in the $apikey_auth_headers and $apikey_auth_query variables that is present in the request.
+function getKey(r) {
+    var headers = splitList(r.variables.apikey_auth_headers);
+    for (var i = 0; i < headers.length; i++) {
+        if (r.headersIn[headers[i]]) {
+            return r.headersIn[headers[i]];
+        }
+    }
+
+    var query = splitList(r.variables.apikey_auth_query);
+    for (var j = 0; j < query.length; j++) {
+        var value = r.args[query[j]];
+        if (Array.isArray(value)) {
+            value = value[0];
+        }
+        if (value) {
+            return value;
+        }
+    }
+
+    return '';
+}
+
+function splitList(list) {
+    return list ? list.split(',') : [];
+}
@@ -0,0 +1,17 @@
+/*
+ * JavaScript functions for providing the dry run of the geoAccess policy with NGINX
+ *
+ * Copyright (C) 2022 Nginx, Inc.
+ */
+
+export default { dryRun };
+
+// Logs the requests that the geoAccess policy in the dry-run mode would reject and returns an empty string.
+// The location of the policy sets $geo_access_denied to the variable of the decision of the policy.
+function dryRun(r) {
+    if (r.variables.geo_access_denied === '1') {
+        r.warn('geoAccess dry run: request from ' + r.remoteAddress + ' to ' + r.uri + ' would be rejected');
+    }
+
+    return '';
+}
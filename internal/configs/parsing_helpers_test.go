@@ -459,6 +459,34 @@ func TestParseSize(t *testing.T) {
 	}
 }
 
+func TestParseSizeInBytes(t *testing.T) {
+	t.Parallel()
+	testsWithValidInput := map[string]int64{
+		"1":   1,
+		"2k":  2048,
+		"2K":  2048,
+		"3m":  3145728,
+		"3M":  3145728,
+		" 5 ": 5,
+	}
+	invalidInput := []string{"-1", "", "blah", "4g", "4G", "99999999999999999999"}
+	for test, expected := range testsWithValidInput {
+		result, err := ParseSizeInBytes(test)
+		if err != nil {
+			t.Errorf("ParseSizeInBytes(%q) returned an error for valid input", test)
+		}
+		if result != expected {
+			t.Errorf("ParseSizeInBytes(%q) returned %d expected %d", test, result, expected)
+		}
+	}
+	for _, test := range invalidInput {
+		result, err := ParseSizeInBytes(test)
+		if err == nil {
+			t.Errorf("ParseSizeInBytes(%q) didn't return error. Returned: %d", test, result)
+		}
+	}
+}
+
 func TestParseProxyBuffersSpec(t *testing.T) {
 	t.Parallel()
 	testsWithValidInput := []string{"1 1k", "10 24k", "2 2K", "6 3m", "128 3M"}
@@ -0,0 +1,117 @@
+package configs
+
+import (
+	"sync"
+	"time"
+)
+
+// reloadCoalescer batches the NGINX reloads requested for the configuration changes.
+// A batch is due when no reload was requested during the window since the last request
+// or when the max delay since the first request of the batch has passed, whichever comes first.
+type reloadCoalescer struct {
+	window   time.Duration
+	maxDelay time.Duration
+	// due receives a value when the batch is due
+	due chan struct{}
+
+	// lock protects the fields below
+	lock              sync.Mutex
+	timer             *time.Timer
+	pending           bool
+	isEndpointsUpdate bool
+	firstRequest      time.Time
+	callbacks         []func(error)
+}
+
+// reloadBatch is a batch of the reload requests.
+type reloadBatch struct {
+	// pending is true if at least one reload was requested
+	pending bool
+	// isEndpointsUpdate is true if all reloads were requested for the endpoints updates
+	isEndpointsUpdate bool
+	callbacks         []func(error)
+}
+
+func newReloadCoalescer(window time.Duration, maxDelay time.Duration) *reloadCoalescer {
+	return &reloadCoalescer{
+		window:   window,
+		maxDelay: maxDelay,
+		due:      make(chan struct{}, 1),
+	}
+}
+
+// request adds a reload request to the batch and postpones the batch by the window, but not beyond the max delay.
+func (rc *reloadCoalescer) request(isEndpointsUpdate bool, now time.Time) {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	if !rc.pending {
+		rc.pending = true
+		rc.isEndpointsUpdate = isEndpointsUpdate
+		rc.firstRequest = now
+	} else {
+		rc.isEndpointsUpdate = rc.isEndpointsUpdate && isEndpointsUpdate
+	}
+
+	delay := rc.window
+	if remaining := rc.firstRequest.Add(rc.maxDelay).Sub(now); remaining < delay {
+		delay = remaining
+	}
+
+	if rc.timer == nil {
+		rc.timer = time.AfterFunc(delay, rc.signalDue)
+	} else {
+		rc.timer.Reset(delay)
+	}
+}
+
+func (rc *reloadCoalescer) signalDue() {
+	select {
+	case rc.due <- struct{}{}:
+	default:
+	}
+}
+
+// isPending returns true if the batch has at least one reload request.
+func (rc *reloadCoalescer) isPending() bool {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	return rc.pending
+}
+
+// addCallback adds a callback that is called with the outcome of the reload of the current batch.
+// It returns false if there are no reload requests in the batch.
+func (rc *reloadCoalescer) addCallback(callback func(error)) bool {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	if !rc.pending {
+		return false
+	}
+
+	rc.callbacks = append(rc.callbacks, callback)
+	return true
+}
+
+// take returns the current batch and starts a new one.
+func (rc *reloadCoalescer) take() reloadBatch {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	if rc.timer != nil {
+		rc.timer.Stop()
+	}
+
+	batch := reloadBatch{
+		pending:           rc.pending,
+		isEndpointsUpdate: rc.isEndpointsUpdate,
+		callbacks:         rc.callbacks,
+	}
+
+	rc.pending = false
+	rc.isEndpointsUpdate = false
+	rc.callbacks = nil
+
+	return batch
+}
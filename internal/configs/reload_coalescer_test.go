@@ -0,0 +1,60 @@
+package configs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReloadCoalescerMaxDelay(t *testing.T) {
+	rc := newReloadCoalescer(time.Hour, 10*time.Millisecond)
+
+	rc.request(true, time.Now())
+	rc.request(false, time.Now())
+
+	select {
+	case <-rc.due:
+	case <-time.After(time.Second):
+		t.Fatal("reloadCoalescer didn't signal the due batch after the max delay")
+	}
+
+	batch := rc.take()
+	if !batch.pending {
+		t.Error("reloadCoalescer returned a batch without pending reloads")
+	}
+	if batch.isEndpointsUpdate {
+		t.Error("reloadCoalescer returned an endpoints update batch for a batch with an other update")
+	}
+
+	if rc.isPending() {
+		t.Error("reloadCoalescer has pending reloads after the batch was taken")
+	}
+}
+
+func TestReloadCoalescerWindow(t *testing.T) {
+	rc := newReloadCoalescer(10*time.Millisecond, time.Hour)
+
+	if rc.addCallback(func(error) {}) {
+		t.Error("reloadCoalescer added a callback without pending reloads")
+	}
+
+	rc.request(true, time.Now())
+	rc.request(true, time.Now())
+
+	if !rc.addCallback(func(error) {}) {
+		t.Error("reloadCoalescer didn't add a callback with pending reloads")
+	}
+
+	select {
+	case <-rc.due:
+	case <-time.After(time.Second):
+		t.Fatal("reloadCoalescer didn't signal the due batch after the window")
+	}
+
+	batch := rc.take()
+	if !batch.isEndpointsUpdate {
+		t.Error("reloadCoalescer returned an other update batch for a batch with only endpoints updates")
+	}
+	if len(batch.callbacks) != 1 {
+		t.Errorf("reloadCoalescer returned %d callbacks, expected 1", len(batch.callbacks))
+	}
+}
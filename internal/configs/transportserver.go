@@ -5,7 +5,10 @@ import (
 	"strings"
 
 	"github.com/nginxinc/kubernetes-ingress/internal/configs/version2"
+	"github.com/nginxinc/kubernetes-ingress/internal/k8s/secrets"
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
 	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+	api_v1 "k8s.io/api/core/v1"
 )
 
 const nginxNonExistingUnixSocket = "unix:/var/lib/nginx/non-existing-unix-socket.sock"
@@ -16,6 +19,8 @@ type TransportServerEx struct {
 	TransportServer *conf_v1alpha1.TransportServer
 	Endpoints       map[string][]string
 	PodsByIP        map[string]string
+	SecretRefs      map[string]*secrets.SecretReference
+	Policies        map[string]*conf_v1.Policy
 }
 
 func (tsEx *TransportServerEx) String() string {
@@ -31,7 +36,9 @@ func (tsEx *TransportServerEx) String() string {
 }
 
 // generateTransportServerConfig generates a full configuration for a TransportServer.
-func generateTransportServerConfig(transportServerEx *TransportServerEx, listenerPort int, isPlus bool) *version2.TransportServerConfig {
+func generateTransportServerConfig(transportServerEx *TransportServerEx, listenerPort int, isPlus bool) (*version2.TransportServerConfig, Warnings) {
+	warnings := newWarnings()
+
 	upstreamNamer := newUpstreamNamerForTransportServer(transportServerEx.TransportServer)
 
 	upstreams := generateStreamUpstreams(transportServerEx, upstreamNamer, isPlus)
@@ -67,20 +74,42 @@ func generateTransportServerConfig(transportServerEx *TransportServerEx, listene
 	streamSnippets := generateSnippets(true, transportServerEx.TransportServer.Spec.StreamSnippets, []string{})
 
 	statusZone := transportServerEx.TransportServer.Spec.Listener.Name
-	if transportServerEx.TransportServer.Spec.Listener.Name == conf_v1alpha1.TLSPassthroughListenerName {
+	if transportServerEx.TransportServer.Spec.Host != "" {
 		statusZone = transportServerEx.TransportServer.Spec.Host
 	}
 
+	policies := generateStreamPolicies(transportServerEx, warnings)
+
+	proxyPass := upstreamNamer.GetNameForUpstream(transportServerEx.TransportServer.Spec.Action.Pass)
+
+	sslConfig, ingressMTLS, sslOK := generateStreamSSLConfig(transportServerEx, warnings)
+	proxySSL, proxySSLOK := generateStreamProxySSLConfig(transportServerEx, warnings)
+	if !sslOK || !proxySSLOK {
+		// the connections can't be secured, so we pass them to a non-existing unix socket
+		proxyPass = nginxNonExistingUnixSocket
+		healthCheck = nil
+	}
+
 	tsConfig := &version2.TransportServerConfig{
 		Server: version2.StreamServer{
 			TLSPassthrough:           transportServerEx.TransportServer.Spec.Listener.Name == conf_v1alpha1.TLSPassthroughListenerName,
 			UnixSocket:               generateUnixSocket(transportServerEx),
 			Port:                     listenerPort,
 			UDP:                      transportServerEx.TransportServer.Spec.Listener.Protocol == "UDP",
+			SSL:                      sslConfig,
+			IngressMTLS:              ingressMTLS,
+			ProxySSL:                 proxySSL,
+			DenyAll:                  policies.DenyAll,
+			Allow:                    policies.Allow,
+			Deny:                     policies.Deny,
+			LimitConns:               policies.LimitConns,
+			LimitConnOptions:         policies.LimitConnOptions,
+			ProxyUploadRate:          policies.ProxyUploadRate,
+			ProxyDownloadRate:        policies.ProxyDownloadRate,
 			StatusZone:               statusZone,
 			ProxyRequests:            proxyRequests,
 			ProxyResponses:           proxyResponses,
-			ProxyPass:                upstreamNamer.GetNameForUpstream(transportServerEx.TransportServer.Spec.Action.Pass),
+			ProxyPass:                proxyPass,
 			Name:                     transportServerEx.TransportServer.Name,
 			Namespace:                transportServerEx.TransportServer.Namespace,
 			ProxyConnectTimeout:      generateTimeWithDefault(connectTimeout, "60s"),
@@ -94,9 +123,229 @@ func generateTransportServerConfig(transportServerEx *TransportServerEx, listene
 		Match:          match,
 		Upstreams:      upstreams,
 		StreamSnippets: streamSnippets,
+		LimitConnZones: policies.LimitConnZones,
 	}
 
-	return tsConfig
+	return tsConfig, warnings
+}
+
+// streamPoliciesCfg holds the configuration generated from the policies of a TransportServer.
+type streamPoliciesCfg struct {
+	DenyAll           bool
+	Allow             []string
+	Deny              []string
+	LimitConnZones    []version2.StreamLimitConnZone
+	LimitConns        []version2.StreamLimitConn
+	LimitConnOptions  version2.StreamLimitConnOptions
+	ProxyUploadRate   string
+	ProxyDownloadRate string
+}
+
+// generateStreamPolicies generates the configuration of the policies of a TransportServer.
+// If a policy is missing, invalid or of a type not supported in TransportServers, all connections are denied.
+func generateStreamPolicies(transportServerEx *TransportServerEx, warnings Warnings) streamPoliciesCfg {
+	ts := transportServerEx.TransportServer
+	cfg := streamPoliciesCfg{}
+	denyAll := streamPoliciesCfg{
+		DenyAll: true,
+	}
+
+	hasBandwidth := false
+
+	for _, p := range ts.Spec.Policies {
+		polNamespace := p.Namespace
+		if polNamespace == "" {
+			polNamespace = ts.Namespace
+		}
+
+		key := fmt.Sprintf("%s/%s", polNamespace, p.Name)
+
+		pol, exists := transportServerEx.Policies[key]
+		if !exists {
+			warnings.AddWarningf(ts, "Policy %s is missing or invalid", key)
+			return denyAll
+		}
+
+		switch {
+		case pol.Spec.AccessControl != nil:
+			cfg.Allow = append(cfg.Allow, pol.Spec.AccessControl.Allow...)
+			cfg.Deny = append(cfg.Deny, pol.Spec.AccessControl.Deny...)
+		case pol.Spec.ConnectionLimit != nil:
+			connectionLimit := pol.Spec.ConnectionLimit
+			zoneName := fmt.Sprintf("pol_lc_%s_%s_%s_%s", polNamespace, p.Name, ts.Namespace, ts.Name)
+
+			if len(cfg.LimitConns) == 0 {
+				cfg.LimitConnOptions = version2.StreamLimitConnOptions{
+					DryRun:   generateBool(connectionLimit.DryRun, false),
+					LogLevel: generateString(connectionLimit.LogLevel, "error"),
+				}
+			}
+
+			cfg.LimitConnZones = append(cfg.LimitConnZones, version2.StreamLimitConnZone{
+				Key:      connectionLimit.Key,
+				ZoneName: zoneName,
+				ZoneSize: connectionLimit.ZoneSize,
+			})
+			cfg.LimitConns = append(cfg.LimitConns, version2.StreamLimitConn{
+				ZoneName:       zoneName,
+				MaxConnections: connectionLimit.MaxConnections,
+			})
+		case pol.Spec.Bandwidth != nil:
+			if hasBandwidth {
+				warnings.AddWarningf(ts, "Multiple bandwidth policies are not allowed. Bandwidth policy %s will be ignored", key)
+				continue
+			}
+			hasBandwidth = true
+
+			cfg.ProxyUploadRate = pol.Spec.Bandwidth.UploadRate
+			cfg.ProxyDownloadRate = pol.Spec.Bandwidth.DownloadRate
+		default:
+			warnings.AddWarningf(ts, "Policy %s is not supported in TransportServers", key)
+			return denyAll
+		}
+	}
+
+	if len(cfg.Allow) > 0 && len(cfg.Deny) > 0 {
+		warnings.AddWarningf(ts, "AccessControl policy (or policies) with deny rules is overridden by policy (or policies) with allow rules")
+	}
+
+	return cfg
+}
+
+// generateStreamSSLConfig generates the TLS termination and the client certificate verification for a TransportServer.
+// It returns false if the TLS secret or the client certificate secret is missing or invalid.
+// The stream module of NGINX doesn't support ssl_reject_handshake, so the TLS is not terminated in that case.
+func generateStreamSSLConfig(transportServerEx *TransportServerEx, warnings Warnings) (*version2.StreamSSL, *version2.IngressMTLS, bool) {
+	ts := transportServerEx.TransportServer
+	tls := ts.Spec.TLS
+	if tls == nil {
+		return nil, nil, true
+	}
+
+	secretRef := getSecretRef(transportServerEx.SecretRefs, ts.Namespace, tls.Secret)
+	var secretType api_v1.SecretType
+	if secretRef.Secret != nil {
+		secretType = secretRef.Secret.Type
+	}
+	if secretType != "" && secretType != api_v1.SecretTypeTLS {
+		warnings.AddWarningf(ts, "TLS secret %s is of a wrong type '%s', must be '%s'", tls.Secret, secretType, api_v1.SecretTypeTLS)
+		return nil, nil, false
+	} else if secretRef.Error != nil {
+		warnings.AddWarningf(ts, "TLS secret %s is invalid: %v", tls.Secret, secretRef.Error)
+		return nil, nil, false
+	}
+
+	ssl := &version2.StreamSSL{
+		Certificate:    secretRef.Path,
+		CertificateKey: secretRef.Path,
+	}
+
+	if tls.ClientCertSecret == "" {
+		return ssl, nil, true
+	}
+
+	caSecretRef := getSecretRef(transportServerEx.SecretRefs, ts.Namespace, tls.ClientCertSecret)
+	var caSecretType api_v1.SecretType
+	if caSecretRef.Secret != nil {
+		caSecretType = caSecretRef.Secret.Type
+	}
+	if caSecretType != "" && caSecretType != secrets.SecretTypeCA {
+		warnings.AddWarningf(ts, "Client certificate secret %s is of a wrong type '%s', must be '%s'", tls.ClientCertSecret, caSecretType, secrets.SecretTypeCA)
+		return nil, nil, false
+	} else if caSecretRef.Error != nil {
+		warnings.AddWarningf(ts, "Client certificate secret %s is invalid: %v", tls.ClientCertSecret, caSecretRef.Error)
+		return nil, nil, false
+	}
+
+	ingressMTLS := &version2.IngressMTLS{
+		ClientCert:   caSecretRef.Path,
+		VerifyClient: generateString(tls.VerifyClient, "on"),
+		VerifyDepth:  generateIntFromPointer(tls.VerifyDepth, 1),
+	}
+
+	return ssl, ingressMTLS, true
+}
+
+// generateStreamProxySSLConfig generates the TLS configuration of the connections to the upstream of the action of a TransportServer.
+// It returns false if the TLS secret or the trusted certificate secret of the upstream is missing or invalid.
+func generateStreamProxySSLConfig(transportServerEx *TransportServerEx, warnings Warnings) (*version2.StreamProxySSL, bool) {
+	ts := transportServerEx.TransportServer
+	if ts.Spec.Action == nil {
+		return nil, true
+	}
+
+	var tls *conf_v1alpha1.UpstreamTLS
+	var service string
+	for _, u := range ts.Spec.Upstreams {
+		if u.Name == ts.Spec.Action.Pass {
+			tls = u.TLS
+			service = u.Service
+			break
+		}
+	}
+
+	if tls == nil || !tls.Enable {
+		return nil, true
+	}
+
+	var tlsSecretPath string
+
+	if tls.TLSSecret != "" {
+		secretRef := getSecretRef(transportServerEx.SecretRefs, ts.Namespace, tls.TLSSecret)
+		var secretType api_v1.SecretType
+		if secretRef.Secret != nil {
+			secretType = secretRef.Secret.Type
+		}
+		if secretType != "" && secretType != api_v1.SecretTypeTLS {
+			warnings.AddWarningf(ts, "Upstream TLS secret %s is of a wrong type '%s', must be '%s'", tls.TLSSecret, secretType, api_v1.SecretTypeTLS)
+			return nil, false
+		} else if secretRef.Error != nil {
+			warnings.AddWarningf(ts, "Upstream TLS secret %s is invalid: %v", tls.TLSSecret, secretRef.Error)
+			return nil, false
+		}
+
+		tlsSecretPath = secretRef.Path
+	}
+
+	var trustedSecretPath string
+
+	if tls.TrustedCertSecret != "" {
+		secretRef := getSecretRef(transportServerEx.SecretRefs, ts.Namespace, tls.TrustedCertSecret)
+		var secretType api_v1.SecretType
+		if secretRef.Secret != nil {
+			secretType = secretRef.Secret.Type
+		}
+		if secretType != "" && secretType != secrets.SecretTypeCA {
+			warnings.AddWarningf(ts, "Upstream trusted certificate secret %s is of a wrong type '%s', must be '%s'", tls.TrustedCertSecret, secretType, secrets.SecretTypeCA)
+			return nil, false
+		} else if secretRef.Error != nil {
+			warnings.AddWarningf(ts, "Upstream trusted certificate secret %s is invalid: %v", tls.TrustedCertSecret, secretRef.Error)
+			return nil, false
+		}
+
+		trustedSecretPath = secretRef.Path
+	}
+
+	return &version2.StreamProxySSL{
+		Certificate:    tlsSecretPath,
+		CertificateKey: tlsSecretPath,
+		TrustedCert:    trustedSecretPath,
+		Verify:         tls.VerifyServer,
+		VerifyDepth:    generateIntFromPointer(tls.VerifyDepth, 1),
+		ServerName:     tls.ServerName,
+		Name:           generateString(tls.SSLName, fmt.Sprintf("%s.%s.svc", service, ts.Namespace)),
+	}, true
+}
+
+func getSecretRef(secretRefs map[string]*secrets.SecretReference, namespace string, name string) *secrets.SecretReference {
+	secretRef, exists := secretRefs[fmt.Sprintf("%s/%s", namespace, name)]
+	if !exists {
+		return &secrets.SecretReference{
+			Error: fmt.Errorf("secret doesn't exist or of an unsupported type"),
+		}
+	}
+
+	return secretRef
 }
 
 func generateUnixSocket(transportServerEx *TransportServerEx) string {
@@ -104,9 +353,19 @@ func generateUnixSocket(transportServerEx *TransportServerEx) string {
 		return fmt.Sprintf("unix:/var/lib/nginx/passthrough-%s_%s.sock", transportServerEx.TransportServer.Namespace, transportServerEx.TransportServer.Name)
 	}
 
+	if isRoutedBySNI(transportServerEx.TransportServer) {
+		return fmt.Sprintf("unix:/var/lib/nginx/sni-%s_%s.sock", transportServerEx.TransportServer.Namespace, transportServerEx.TransportServer.Name)
+	}
+
 	return ""
 }
 
+// isRoutedBySNI tells if the connections are routed to the TransportServer by SNI on a listener that it shares with
+// other TransportServers. Such TransportServers have a host and a listener other than the TLS Passthrough listener.
+func isRoutedBySNI(ts *conf_v1alpha1.TransportServer) bool {
+	return ts.Spec.Host != "" && ts.Spec.Listener.Name != conf_v1alpha1.TLSPassthroughListenerName
+}
+
 func generateStreamUpstreams(transportServerEx *TransportServerEx, upstreamNamer *upstreamNamer, isPlus bool) []version2.StreamUpstream {
 	var upstreams []version2.StreamUpstream
 
@@ -231,7 +490,7 @@ func generateStreamUpstream(upstream conf_v1alpha1.Upstream, upstreamNamer *upst
 	return version2.StreamUpstream{
 		Name:                name,
 		Servers:             upsServers,
-		LoadBalancingMethod: generateLoadBalancingMethod(upstream.LoadBalancingMethod),
+		LoadBalancingMethod: generateStreamLoadBalancingMethod(upstream),
 	}
 }
 
@@ -247,3 +506,52 @@ func generateLoadBalancingMethod(method string) string {
 	}
 	return method
 }
+
+// generateStreamLoadBalancingMethod generates the load balancing directive of the upstream from the loadBalancing field
+// or, if the field is not set, from the loadBalancingMethod field.
+func generateStreamLoadBalancingMethod(upstream conf_v1alpha1.Upstream) string {
+	lb := upstream.LoadBalancing
+	if lb == nil {
+		return generateLoadBalancingMethod(upstream.LoadBalancingMethod)
+	}
+
+	if lb.Persistence == "clientIP" {
+		return "hash $remote_addr consistent"
+	}
+
+	switch lb.Method {
+	case "round_robin":
+		return ""
+	case "hash":
+		method := "hash " + lb.Hash.Key
+		if lb.Hash.Consistent {
+			method += " consistent"
+		}
+		return method
+	case "random":
+		method := "random"
+		if lb.Random != nil && lb.Random.Two {
+			method += " two"
+			if lb.Random.Method != "" {
+				method += " " + lb.Random.Method
+			}
+		}
+		return method
+	case "least_time":
+		measure := "connect"
+		inflight := false
+		if lb.LeastTime != nil {
+			if lb.LeastTime.Measure != "" {
+				measure = lb.LeastTime.Measure
+			}
+			inflight = lb.LeastTime.Inflight
+		}
+		method := "least_time " + measure
+		if inflight {
+			method += " inflight"
+		}
+		return method
+	}
+
+	return lb.Method
+}
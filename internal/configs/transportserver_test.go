@@ -1,11 +1,15 @@
 package configs
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/nginxinc/kubernetes-ingress/internal/configs/version2"
+	"github.com/nginxinc/kubernetes-ingress/internal/k8s/secrets"
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
 	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+	api_v1 "k8s.io/api/core/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -137,10 +141,13 @@ func TestGenerateTransportServerConfigForTCPSnippets(t *testing.T) {
 		StreamSnippets: []string{"limit_conn_zone $binary_remote_addr zone=addr:10m;"},
 	}
 
-	result := generateTransportServerConfig(&transportServerEx, listenerPort, true)
+	result, warnings := generateTransportServerConfig(&transportServerEx, listenerPort, true)
 	if diff := cmp.Diff(expected, result); diff != "" {
 		t.Errorf("generateTransportServerConfig() mismatch (-want +got):\n%s", diff)
 	}
+	if len(warnings) != 0 {
+		t.Errorf("generateTransportServerConfig() returned unexpected warnings: %v", warnings)
+	}
 }
 
 func TestGenerateTransportServerConfigForTCP(t *testing.T) {
@@ -224,10 +231,68 @@ func TestGenerateTransportServerConfigForTCP(t *testing.T) {
 		StreamSnippets: []string{},
 	}
 
-	result := generateTransportServerConfig(&transportServerEx, listenerPort, true)
+	result, warnings := generateTransportServerConfig(&transportServerEx, listenerPort, true)
 	if diff := cmp.Diff(expected, result); diff != "" {
 		t.Errorf("generateTransportServerConfig() mismatch (-want +got):\n%s", diff)
 	}
+	if len(warnings) != 0 {
+		t.Errorf("generateTransportServerConfig() returned unexpected warnings: %v", warnings)
+	}
+}
+
+func TestGenerateTransportServerConfigForTCPWithInvalidTLSSecret(t *testing.T) {
+	t.Parallel()
+	transportServerEx := TransportServerEx{
+		TransportServer: &conf_v1alpha1.TransportServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "tcp-server",
+				Namespace: "default",
+			},
+			Spec: conf_v1alpha1.TransportServerSpec{
+				Listener: conf_v1alpha1.TransportServerListener{
+					Name:     "tcp-listener",
+					Protocol: "TCP",
+				},
+				TLS: &conf_v1alpha1.TransportServerTLS{
+					Secret: "tls-secret",
+				},
+				Upstreams: []conf_v1alpha1.Upstream{
+					{
+						Name:    "tcp-app",
+						Service: "tcp-app-svc",
+						Port:    5001,
+					},
+				},
+				Action: &conf_v1alpha1.Action{
+					Pass: "tcp-app",
+				},
+			},
+		},
+		Endpoints: map[string][]string{
+			"default/tcp-app-svc:5001": {
+				"10.0.0.20:5001",
+			},
+		},
+		SecretRefs: map[string]*secrets.SecretReference{
+			"default/tls-secret": {
+				Error: errors.New("secret doesn't exist or of an unsupported type"),
+			},
+		},
+	}
+
+	result, warnings := generateTransportServerConfig(&transportServerEx, 2020, false)
+
+	if result.Server.SSL != nil {
+		t.Errorf("generateTransportServerConfig() returned SSL %v for an invalid TLS secret", result.Server.SSL)
+	}
+	if result.Server.ProxyPass != nginxNonExistingUnixSocket {
+		t.Errorf("generateTransportServerConfig() returned proxy pass %q, expected %q", result.Server.ProxyPass, nginxNonExistingUnixSocket)
+	}
+
+	expectedWarnings := []string{"TLS secret tls-secret is invalid: secret doesn't exist or of an unsupported type"}
+	if diff := cmp.Diff(expectedWarnings, warnings[transportServerEx.TransportServer]); diff != "" {
+		t.Errorf("generateTransportServerConfig() returned unexpected warnings (-want +got):\n%s", diff)
+	}
 }
 
 func TestGenerateTransportServerConfigForTCPMaxConnections(t *testing.T) {
@@ -313,10 +378,13 @@ func TestGenerateTransportServerConfigForTCPMaxConnections(t *testing.T) {
 		StreamSnippets: []string{},
 	}
 
-	result := generateTransportServerConfig(&transportServerEx, listenerPort, true)
+	result, warnings := generateTransportServerConfig(&transportServerEx, listenerPort, true)
 	if diff := cmp.Diff(expected, result); diff != "" {
 		t.Errorf("generateTransportServerConfig() mismatch (-want +got):\n%s", diff)
 	}
+	if len(warnings) != 0 {
+		t.Errorf("generateTransportServerConfig() returned unexpected warnings: %v", warnings)
+	}
 }
 
 func TestGenerateTransportServerConfigForTLSPassthrough(t *testing.T) {
@@ -400,10 +468,13 @@ func TestGenerateTransportServerConfigForTLSPassthrough(t *testing.T) {
 		StreamSnippets: []string{},
 	}
 
-	result := generateTransportServerConfig(&transportServerEx, listenerPort, true)
+	result, warnings := generateTransportServerConfig(&transportServerEx, listenerPort, true)
 	if diff := cmp.Diff(expected, result); diff != "" {
 		t.Errorf("generateTransportServerConfig() mismatch (-want +got):\n%s", diff)
 	}
+	if len(warnings) != 0 {
+		t.Errorf("generateTransportServerConfig() returned unexpected warnings: %v", warnings)
+	}
 }
 
 func TestGenerateTransportServerConfigForUDP(t *testing.T) {
@@ -492,10 +563,13 @@ func TestGenerateTransportServerConfigForUDP(t *testing.T) {
 		StreamSnippets: []string{},
 	}
 
-	result := generateTransportServerConfig(&transportServerEx, listenerPort, true)
+	result, warnings := generateTransportServerConfig(&transportServerEx, listenerPort, true)
 	if diff := cmp.Diff(expected, result); diff != "" {
 		t.Errorf("generateTransportServerConfig() mismatch (-want +got):\n%s", diff)
 	}
+	if len(warnings) != 0 {
+		t.Errorf("generateTransportServerConfig() returned unexpected warnings: %v", warnings)
+	}
 }
 
 func TestGenerateUnixSocket(t *testing.T) {
@@ -528,6 +602,14 @@ func TestGenerateUnixSocket(t *testing.T) {
 	if result != expected {
 		t.Errorf("generateUnixSocket() returned %q but expected %q", result, expected)
 	}
+
+	transportServerEx.TransportServer.Spec.Host = "example.com"
+	expected = "unix:/var/lib/nginx/sni-default_tcp-server.sock"
+
+	result = generateUnixSocket(transportServerEx)
+	if result != expected {
+		t.Errorf("generateUnixSocket() returned %q but expected %q", result, expected)
+	}
 }
 
 func TestGenerateTransportServerHealthChecks(t *testing.T) {
@@ -772,6 +854,627 @@ func TestGenerateHealthCheckMatch(t *testing.T) {
 	}
 }
 
+func TestGenerateStreamLoadBalancingMethod(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		upstream conf_v1alpha1.Upstream
+		expected string
+		msg      string
+	}{
+		{
+			upstream: conf_v1alpha1.Upstream{},
+			expected: "random two least_conn",
+			msg:      "default method",
+		},
+		{
+			upstream: conf_v1alpha1.Upstream{
+				LoadBalancingMethod: "least_conn",
+			},
+			expected: "least_conn",
+			msg:      "loadBalancingMethod",
+		},
+		{
+			upstream: conf_v1alpha1.Upstream{
+				LoadBalancing: &conf_v1alpha1.LoadBalancing{Method: "round_robin"},
+			},
+			expected: "",
+			msg:      "round robin",
+		},
+		{
+			upstream: conf_v1alpha1.Upstream{
+				LoadBalancing: &conf_v1alpha1.LoadBalancing{Method: "least_conn"},
+			},
+			expected: "least_conn",
+			msg:      "least conn",
+		},
+		{
+			upstream: conf_v1alpha1.Upstream{
+				LoadBalancing: &conf_v1alpha1.LoadBalancing{
+					Method: "hash",
+					Hash: &conf_v1alpha1.HashLoadBalancing{
+						Key:        "${remote_addr}",
+						Consistent: true,
+					},
+				},
+			},
+			expected: "hash ${remote_addr} consistent",
+			msg:      "consistent hash",
+		},
+		{
+			upstream: conf_v1alpha1.Upstream{
+				LoadBalancing: &conf_v1alpha1.LoadBalancing{
+					Method: "hash",
+					Hash: &conf_v1alpha1.HashLoadBalancing{
+						Key: "${remote_addr}",
+					},
+				},
+			},
+			expected: "hash ${remote_addr}",
+			msg:      "hash",
+		},
+		{
+			upstream: conf_v1alpha1.Upstream{
+				LoadBalancing: &conf_v1alpha1.LoadBalancing{Method: "random"},
+			},
+			expected: "random",
+			msg:      "random",
+		},
+		{
+			upstream: conf_v1alpha1.Upstream{
+				LoadBalancing: &conf_v1alpha1.LoadBalancing{
+					Method: "random",
+					Random: &conf_v1alpha1.RandomLoadBalancing{
+						Two:    true,
+						Method: "least_time=first_byte",
+					},
+				},
+			},
+			expected: "random two least_time=first_byte",
+			msg:      "random two with method",
+		},
+		{
+			upstream: conf_v1alpha1.Upstream{
+				LoadBalancing: &conf_v1alpha1.LoadBalancing{Method: "least_time"},
+			},
+			expected: "least_time connect",
+			msg:      "least time with default measure",
+		},
+		{
+			upstream: conf_v1alpha1.Upstream{
+				LoadBalancing: &conf_v1alpha1.LoadBalancing{
+					Method: "least_time",
+					LeastTime: &conf_v1alpha1.LeastTimeLoadBalancing{
+						Measure:  "last_byte",
+						Inflight: true,
+					},
+				},
+			},
+			expected: "least_time last_byte inflight",
+			msg:      "least time last byte inflight",
+		},
+		{
+			upstream: conf_v1alpha1.Upstream{
+				LoadBalancing: &conf_v1alpha1.LoadBalancing{Persistence: "clientIP"},
+			},
+			expected: "hash $remote_addr consistent",
+			msg:      "client IP persistence",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateStreamLoadBalancingMethod(test.upstream)
+		if result != test.expected {
+			t.Errorf("generateStreamLoadBalancingMethod() returned %q but expected %q for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
 func intPointer(value int) *int {
 	return &value
 }
+
+func TestGenerateStreamSSLConfig(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		tls                 *conf_v1alpha1.TransportServerTLS
+		secretRefs          map[string]*secrets.SecretReference
+		expectedSSL         *version2.StreamSSL
+		expectedIngressMTLS *version2.IngressMTLS
+		expectedWarnings    []string
+		expectedFailure     bool
+		msg                 string
+	}{
+		{
+			tls:                 nil,
+			expectedSSL:         nil,
+			expectedIngressMTLS: nil,
+			msg:                 "no tls",
+		},
+		{
+			tls: &conf_v1alpha1.TransportServerTLS{
+				Secret: "tls-secret",
+			},
+			secretRefs: map[string]*secrets.SecretReference{
+				"default/tls-secret": {
+					Secret: &api_v1.Secret{
+						Type: api_v1.SecretTypeTLS,
+					},
+					Path: "/etc/nginx/secrets/default-tls-secret",
+				},
+			},
+			expectedSSL: &version2.StreamSSL{
+				Certificate:    "/etc/nginx/secrets/default-tls-secret",
+				CertificateKey: "/etc/nginx/secrets/default-tls-secret",
+			},
+			expectedIngressMTLS: nil,
+			msg:                 "tls secret",
+		},
+		{
+			tls: &conf_v1alpha1.TransportServerTLS{
+				Secret:           "tls-secret",
+				ClientCertSecret: "ca-secret",
+				VerifyClient:     "optional",
+				VerifyDepth:      intPointer(2),
+			},
+			secretRefs: map[string]*secrets.SecretReference{
+				"default/tls-secret": {
+					Secret: &api_v1.Secret{
+						Type: api_v1.SecretTypeTLS,
+					},
+					Path: "/etc/nginx/secrets/default-tls-secret",
+				},
+				"default/ca-secret": {
+					Secret: &api_v1.Secret{
+						Type: secrets.SecretTypeCA,
+					},
+					Path: "/etc/nginx/secrets/default-ca-secret",
+				},
+			},
+			expectedSSL: &version2.StreamSSL{
+				Certificate:    "/etc/nginx/secrets/default-tls-secret",
+				CertificateKey: "/etc/nginx/secrets/default-tls-secret",
+			},
+			expectedIngressMTLS: &version2.IngressMTLS{
+				ClientCert:   "/etc/nginx/secrets/default-ca-secret",
+				VerifyClient: "optional",
+				VerifyDepth:  2,
+			},
+			msg: "tls secret and client cert secret",
+		},
+		{
+			tls: &conf_v1alpha1.TransportServerTLS{
+				Secret: "tls-secret",
+			},
+			secretRefs:          map[string]*secrets.SecretReference{},
+			expectedSSL:         nil,
+			expectedIngressMTLS: nil,
+			expectedFailure:     true,
+			expectedWarnings: []string{
+				"TLS secret tls-secret is invalid: secret doesn't exist or of an unsupported type",
+			},
+			msg: "missing tls secret",
+		},
+		{
+			tls: &conf_v1alpha1.TransportServerTLS{
+				Secret: "tls-secret",
+			},
+			secretRefs: map[string]*secrets.SecretReference{
+				"default/tls-secret": {
+					Secret: &api_v1.Secret{
+						Type: secrets.SecretTypeCA,
+					},
+				},
+			},
+			expectedSSL:         nil,
+			expectedIngressMTLS: nil,
+			expectedFailure:     true,
+			expectedWarnings: []string{
+				"TLS secret tls-secret is of a wrong type 'nginx.org/ca', must be 'kubernetes.io/tls'",
+			},
+			msg: "tls secret of a wrong type",
+		},
+		{
+			tls: &conf_v1alpha1.TransportServerTLS{
+				Secret:           "tls-secret",
+				ClientCertSecret: "ca-secret",
+			},
+			secretRefs: map[string]*secrets.SecretReference{
+				"default/tls-secret": {
+					Secret: &api_v1.Secret{
+						Type: api_v1.SecretTypeTLS,
+					},
+					Path: "/etc/nginx/secrets/default-tls-secret",
+				},
+				"default/ca-secret": {
+					Secret: &api_v1.Secret{
+						Type: secrets.SecretTypeCA,
+					},
+					Error: errors.New("invalid CA"),
+				},
+			},
+			expectedSSL:         nil,
+			expectedIngressMTLS: nil,
+			expectedFailure:     true,
+			expectedWarnings: []string{
+				"Client certificate secret ca-secret is invalid: invalid CA",
+			},
+			msg: "invalid client cert secret",
+		},
+	}
+
+	for _, test := range tests {
+		ts := &conf_v1alpha1.TransportServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "tcp-server",
+				Namespace: "default",
+			},
+			Spec: conf_v1alpha1.TransportServerSpec{
+				TLS: test.tls,
+			},
+		}
+		transportServerEx := &TransportServerEx{
+			TransportServer: ts,
+			SecretRefs:      test.secretRefs,
+		}
+		warnings := newWarnings()
+
+		ssl, ingressMTLS, ok := generateStreamSSLConfig(transportServerEx, warnings)
+		if ok == test.expectedFailure {
+			t.Errorf("generateStreamSSLConfig() returned %v for the case of %s", ok, test.msg)
+		}
+		if diff := cmp.Diff(test.expectedSSL, ssl); diff != "" {
+			t.Errorf("generateStreamSSLConfig() returned unexpected SSL for the case of %s (-want +got):\n%s", test.msg, diff)
+		}
+		if diff := cmp.Diff(test.expectedIngressMTLS, ingressMTLS); diff != "" {
+			t.Errorf("generateStreamSSLConfig() returned unexpected IngressMTLS for the case of %s (-want +got):\n%s", test.msg, diff)
+		}
+		if diff := cmp.Diff(test.expectedWarnings, warnings[ts]); diff != "" {
+			t.Errorf("generateStreamSSLConfig() returned unexpected warnings for the case of %s (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestGenerateStreamProxySSLConfig(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		tls              *conf_v1alpha1.UpstreamTLS
+		secretRefs       map[string]*secrets.SecretReference
+		expected         *version2.StreamProxySSL
+		expectedOK       bool
+		expectedWarnings []string
+		msg              string
+	}{
+		{
+			tls:        nil,
+			expected:   nil,
+			expectedOK: true,
+			msg:        "no tls",
+		},
+		{
+			tls: &conf_v1alpha1.UpstreamTLS{
+				Enable: true,
+			},
+			expected: &version2.StreamProxySSL{
+				VerifyDepth: 1,
+				Name:        "db-svc.default.svc",
+			},
+			expectedOK: true,
+			msg:        "tls enabled",
+		},
+		{
+			tls: &conf_v1alpha1.UpstreamTLS{
+				Enable:            true,
+				TLSSecret:         "client-secret",
+				TrustedCertSecret: "ca-secret",
+				VerifyServer:      true,
+				VerifyDepth:       intPointer(2),
+				ServerName:        true,
+				SSLName:           "db.example.com",
+			},
+			secretRefs: map[string]*secrets.SecretReference{
+				"default/client-secret": {
+					Secret: &api_v1.Secret{
+						Type: api_v1.SecretTypeTLS,
+					},
+					Path: "/etc/nginx/secrets/default-client-secret",
+				},
+				"default/ca-secret": {
+					Secret: &api_v1.Secret{
+						Type: secrets.SecretTypeCA,
+					},
+					Path: "/etc/nginx/secrets/default-ca-secret",
+				},
+			},
+			expected: &version2.StreamProxySSL{
+				Certificate:    "/etc/nginx/secrets/default-client-secret",
+				CertificateKey: "/etc/nginx/secrets/default-client-secret",
+				TrustedCert:    "/etc/nginx/secrets/default-ca-secret",
+				Verify:         true,
+				VerifyDepth:    2,
+				ServerName:     true,
+				Name:           "db.example.com",
+			},
+			expectedOK: true,
+			msg:        "all fields",
+		},
+		{
+			tls: &conf_v1alpha1.UpstreamTLS{
+				Enable:    true,
+				TLSSecret: "client-secret",
+			},
+			secretRefs: map[string]*secrets.SecretReference{},
+			expected:   nil,
+			expectedOK: false,
+			expectedWarnings: []string{
+				"Upstream TLS secret client-secret is invalid: secret doesn't exist or of an unsupported type",
+			},
+			msg: "missing tls secret",
+		},
+		{
+			tls: &conf_v1alpha1.UpstreamTLS{
+				Enable:            true,
+				TrustedCertSecret: "ca-secret",
+			},
+			secretRefs: map[string]*secrets.SecretReference{
+				"default/ca-secret": {
+					Secret: &api_v1.Secret{
+						Type: api_v1.SecretTypeTLS,
+					},
+				},
+			},
+			expected:   nil,
+			expectedOK: false,
+			expectedWarnings: []string{
+				"Upstream trusted certificate secret ca-secret is of a wrong type 'kubernetes.io/tls', must be 'nginx.org/ca'",
+			},
+			msg: "trusted cert secret of a wrong type",
+		},
+	}
+
+	for _, test := range tests {
+		ts := &conf_v1alpha1.TransportServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "tcp-server",
+				Namespace: "default",
+			},
+			Spec: conf_v1alpha1.TransportServerSpec{
+				Upstreams: []conf_v1alpha1.Upstream{
+					{
+						Name:    "db",
+						Service: "db-svc",
+						Port:    5432,
+						TLS:     test.tls,
+					},
+				},
+				Action: &conf_v1alpha1.Action{
+					Pass: "db",
+				},
+			},
+		}
+		transportServerEx := &TransportServerEx{
+			TransportServer: ts,
+			SecretRefs:      test.secretRefs,
+		}
+		warnings := newWarnings()
+
+		result, ok := generateStreamProxySSLConfig(transportServerEx, warnings)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("generateStreamProxySSLConfig() mismatch for the case of %s (-want +got):\n%s", test.msg, diff)
+		}
+		if ok != test.expectedOK {
+			t.Errorf("generateStreamProxySSLConfig() returned %v but expected %v for the case of %s", ok, test.expectedOK, test.msg)
+		}
+		if diff := cmp.Diff(test.expectedWarnings, warnings[ts]); diff != "" {
+			t.Errorf("generateStreamProxySSLConfig() returned unexpected warnings for the case of %s (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestGenerateStreamPolicies(t *testing.T) {
+	t.Parallel()
+	dryRun := true
+	tests := []struct {
+		policyRefs       []conf_v1alpha1.PolicyReference
+		policies         map[string]*conf_v1.Policy
+		expected         streamPoliciesCfg
+		expectedWarnings []string
+		msg              string
+	}{
+		{
+			policyRefs: nil,
+			expected:   streamPoliciesCfg{},
+			msg:        "no policies",
+		},
+		{
+			policyRefs: []conf_v1alpha1.PolicyReference{
+				{
+					Name: "allow-policy",
+				},
+				{
+					Name:      "connection-limit-policy",
+					Namespace: "policies",
+				},
+				{
+					Name: "connection-limit-policy2",
+				},
+				{
+					Name: "bandwidth-policy",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/allow-policy": {
+					Spec: conf_v1.PolicySpec{
+						AccessControl: &conf_v1.AccessControl{
+							Allow: []string{"10.0.0.0/8"},
+						},
+					},
+				},
+				"policies/connection-limit-policy": {
+					Spec: conf_v1.PolicySpec{
+						ConnectionLimit: &conf_v1.ConnectionLimit{
+							Key:            "${binary_remote_addr}",
+							MaxConnections: 10,
+							ZoneSize:       "10M",
+							DryRun:         &dryRun,
+							LogLevel:       "warn",
+						},
+					},
+				},
+				"default/connection-limit-policy2": {
+					Spec: conf_v1.PolicySpec{
+						ConnectionLimit: &conf_v1.ConnectionLimit{
+							Key:            "${server_port}",
+							MaxConnections: 1000,
+							ZoneSize:       "1M",
+						},
+					},
+				},
+				"default/bandwidth-policy": {
+					Spec: conf_v1.PolicySpec{
+						Bandwidth: &conf_v1.Bandwidth{
+							UploadRate:   "512k",
+							DownloadRate: "1m",
+						},
+					},
+				},
+			},
+			expected: streamPoliciesCfg{
+				Allow: []string{"10.0.0.0/8"},
+				LimitConnZones: []version2.StreamLimitConnZone{
+					{
+						Key:      "${binary_remote_addr}",
+						ZoneName: "pol_lc_policies_connection-limit-policy_default_tcp-server",
+						ZoneSize: "10M",
+					},
+					{
+						Key:      "${server_port}",
+						ZoneName: "pol_lc_default_connection-limit-policy2_default_tcp-server",
+						ZoneSize: "1M",
+					},
+				},
+				LimitConns: []version2.StreamLimitConn{
+					{
+						ZoneName:       "pol_lc_policies_connection-limit-policy_default_tcp-server",
+						MaxConnections: 10,
+					},
+					{
+						ZoneName:       "pol_lc_default_connection-limit-policy2_default_tcp-server",
+						MaxConnections: 1000,
+					},
+				},
+				LimitConnOptions: version2.StreamLimitConnOptions{
+					DryRun:   true,
+					LogLevel: "warn",
+				},
+				ProxyUploadRate:   "512k",
+				ProxyDownloadRate: "1m",
+			},
+			msg: "access control, connection limits and bandwidth",
+		},
+		{
+			policyRefs: []conf_v1alpha1.PolicyReference{
+				{
+					Name: "bandwidth-policy",
+				},
+				{
+					Name: "bandwidth-policy2",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/bandwidth-policy": {
+					Spec: conf_v1.PolicySpec{
+						Bandwidth: &conf_v1.Bandwidth{
+							UploadRate: "512k",
+						},
+					},
+				},
+				"default/bandwidth-policy2": {
+					Spec: conf_v1.PolicySpec{
+						Bandwidth: &conf_v1.Bandwidth{
+							UploadRate: "1m",
+						},
+					},
+				},
+			},
+			expected: streamPoliciesCfg{
+				ProxyUploadRate: "512k",
+			},
+			expectedWarnings: []string{
+				"Multiple bandwidth policies are not allowed. Bandwidth policy default/bandwidth-policy2 will be ignored",
+			},
+			msg: "multiple bandwidth policies",
+		},
+		{
+			policyRefs: []conf_v1alpha1.PolicyReference{
+				{
+					Name: "allow-policy",
+				},
+				{
+					Name: "missing-policy",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/allow-policy": {
+					Spec: conf_v1.PolicySpec{
+						AccessControl: &conf_v1.AccessControl{
+							Allow: []string{"10.0.0.0/8"},
+						},
+					},
+				},
+			},
+			expected: streamPoliciesCfg{
+				DenyAll: true,
+			},
+			expectedWarnings: []string{
+				"Policy default/missing-policy is missing or invalid",
+			},
+			msg: "missing policy",
+		},
+		{
+			policyRefs: []conf_v1alpha1.PolicyReference{
+				{
+					Name: "headers-policy",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/headers-policy": {
+					Spec: conf_v1.PolicySpec{
+						Headers: &conf_v1.Headers{},
+					},
+				},
+			},
+			expected: streamPoliciesCfg{
+				DenyAll: true,
+			},
+			expectedWarnings: []string{
+				"Policy default/headers-policy is not supported in TransportServers",
+			},
+			msg: "unsupported policy",
+		},
+	}
+
+	for _, test := range tests {
+		ts := &conf_v1alpha1.TransportServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "tcp-server",
+				Namespace: "default",
+			},
+			Spec: conf_v1alpha1.TransportServerSpec{
+				Policies: test.policyRefs,
+			},
+		}
+		transportServerEx := &TransportServerEx{
+			TransportServer: ts,
+			Policies:        test.policies,
+		}
+		warnings := newWarnings()
+
+		result := generateStreamPolicies(transportServerEx, warnings)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("generateStreamPolicies() mismatch for the case of %s (-want +got):\n%s", test.msg, diff)
+		}
+		if diff := cmp.Diff(test.expectedWarnings, warnings[ts]); diff != "" {
+			t.Errorf("generateStreamPolicies() returned unexpected warnings for the case of %s (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
@@ -221,6 +221,9 @@ type MainConfig struct {
 	InternalRouteServerName            string
 	LatencyMetrics                     bool
 	OIDC                               bool
+	GeoIP2                             bool
+	Brotli                             bool
+	APIKeyAuth                         bool
 }
 
 // NewUpstreamWithDefaultServer creates an upstream with the default server.
@@ -2,6 +2,7 @@ package version1
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 	"text/template"
 )
@@ -179,6 +180,68 @@ func TestMainForNGINX(t *testing.T) {
 	}
 }
 
+func TestMainGeoAccessDryRunRequiresGeoIP2(t *testing.T) {
+	t.Parallel()
+	for _, tmplName := range []string{nginxMainTmpl, nginxPlusMainTmpl} {
+		tmpl, err := template.New(tmplName).ParseFiles(tmplName)
+		if err != nil {
+			t.Fatalf("Failed to parse template file: %v", err)
+		}
+
+		for _, geoIP2 := range []bool{false, true} {
+			cfg := mainCfg
+			cfg.GeoIP2 = geoIP2
+
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, cfg); err != nil {
+				t.Fatalf("Failed to write template %v", err)
+			}
+
+			if got := strings.Contains(buf.String(), "js_import /etc/nginx/njs/geo_access.js;"); got != geoIP2 {
+				t.Errorf("%s with GeoIP2 %v: geo_access.js imported %v, expected %v", tmplName, geoIP2, got, geoIP2)
+			}
+		}
+	}
+}
+
+func TestMainNJSRequiresAPIKeyAuthOrGeoIP2(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		apiKeyAuth bool
+		geoIP2     bool
+		expected   bool
+	}{
+		{apiKeyAuth: false, geoIP2: false, expected: false},
+		{apiKeyAuth: true, geoIP2: false, expected: true},
+		{apiKeyAuth: false, geoIP2: true, expected: true},
+	}
+
+	for _, tmplName := range []string{nginxMainTmpl, nginxPlusMainTmpl} {
+		tmpl, err := template.New(tmplName).ParseFiles(tmplName)
+		if err != nil {
+			t.Fatalf("Failed to parse template file: %v", err)
+		}
+
+		for _, test := range tests {
+			cfg := mainCfg
+			cfg.APIKeyAuth = test.apiKeyAuth
+			cfg.GeoIP2 = test.geoIP2
+
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, cfg); err != nil {
+				t.Fatalf("Failed to write template %v", err)
+			}
+
+			if got := strings.Contains(buf.String(), "load_module modules/ngx_http_js_module.so;"); got != test.expected {
+				t.Errorf("%s with APIKeyAuth %v and GeoIP2 %v: njs module loaded %v, expected %v", tmplName, test.apiKeyAuth, test.geoIP2, got, test.expected)
+			}
+			if got := strings.Contains(buf.String(), "js_import /etc/nginx/njs/apikey_auth.js;"); got != test.apiKeyAuth {
+				t.Errorf("%s with APIKeyAuth %v: apikey_auth.js imported %v, expected %v", tmplName, test.apiKeyAuth, got, test.apiKeyAuth)
+			}
+		}
+	}
+}
+
 func TestSplitHelperFunction(t *testing.T) {
 	t.Parallel()
 	const tpl = `{{range $n := split . ","}}{{$n}} {{end}}`
@@ -12,14 +12,17 @@ type UpstreamLabels struct {
 
 // VirtualServerConfig holds NGINX configuration for a VirtualServer.
 type VirtualServerConfig struct {
-	HTTPSnippets  []string
-	LimitReqZones []LimitReqZone
-	Maps          []Map
-	Server        Server
-	SpiffeCerts   bool
-	SplitClients  []SplitClient
-	StatusMatches []StatusMatch
-	Upstreams     []Upstream
+	CacheZones         []CacheZone
+	ExternalAuthCaches []ExternalAuthCache
+	GeoIP2             []GeoIP2
+	HTTPSnippets       []string
+	LimitReqZones      []LimitReqZone
+	Maps               []Map
+	Server             Server
+	SpiffeCerts        bool
+	SplitClients       []SplitClient
+	StatusMatches      []StatusMatch
+	Upstreams          []Upstream
 }
 
 // Upstream defines an upstream.
@@ -60,6 +63,9 @@ type Server struct {
 	Locations                 []Location
 	ErrorPageLocations        []ErrorPageLocation
 	ReturnLocations           []ReturnLocation
+	MirrorLocations           []Mirror
+	ExternalAuthLocations     []ExternalAuth
+	JwksURILocations          []JwksURI
 	HealthChecks              []HealthCheck
 	TLSRedirect               *TLSRedirect
 	TLSPassthrough            bool
@@ -74,6 +80,7 @@ type Server struct {
 	OIDC                      *OIDC
 	WAF                       *WAF
 	Dos                       *Dos
+	Compression               *Compression
 	PoliciesErrorReturn       *Return
 	VSNamespace               string
 	VSName                    string
@@ -164,6 +171,8 @@ type Location struct {
 	ProxyHideHeaders         []string
 	ProxyPassHeaders         []string
 	ProxyIgnoreHeaders       string
+	ProxyRedirects           []HeaderRewrite
+	ProxyCookieDomains       []HeaderRewrite
 	ProxyPassRewrite         string
 	AddHeaders               []AddHeader
 	Rewrites                 []string
@@ -181,12 +190,33 @@ type Location struct {
 	OIDC                     bool
 	WAF                      *WAF
 	Dos                      *Dos
+	CORS                     *CORS
+	ExternalAuth             *ExternalAuth
+	APIKey                   *APIKey
+	Cache                    *Cache
+	GeoAccess                *GeoAccess
+	Compression              *Compression
 	PoliciesErrorReturn      *Return
 	ServiceName              string
 	IsVSR                    bool
 	VSRName                  string
 	VSRNamespace             string
 	GRPCPass                 string
+	Mirror                   *Mirror
+}
+
+// Mirror defines the mirroring of the requests of a location to an upstream through an internal location.
+type Mirror struct {
+	// Path is the path of the internal location.
+	Path        string
+	ProxyPass   string
+	RequestBody bool
+	Percentage  int
+	// SampleVariable is empty for the requests that must not be mirrored. It is only set when Percentage is less than 100.
+	SampleVariable string
+	// ProxySSLName and EgressMTLS configure the TLS connections to the mirror upstream the same way as for the location.
+	ProxySSLName string
+	EgressMTLS   *EgressMTLS
 }
 
 // ReturnLocation defines a location for returning a fixed response.
@@ -230,6 +260,12 @@ type Header struct {
 	Value string
 }
 
+// HeaderRewrite defines the replacement of the text From with the text To in the value of a header.
+type HeaderRewrite struct {
+	From string
+	To   string
+}
+
 // AddHeader defines a header to use with add_header directive with an optional Always field.
 type AddHeader struct {
 	Header
@@ -349,9 +385,113 @@ func (rl LimitReqOptions) String() string {
 
 // JWTAuth holds JWT authentication configuration.
 type JWTAuth struct {
-	Secret string
-	Realm  string
-	Token  string
+	Secret  string
+	Realm   string
+	Token   string
+	JwksURI *JwksURI
+}
+
+// JwksURI defines an internal location that fetches the JWKS of a JWT policy from a remote URI
+// and caches it for KeyCache in the cache zone ZoneName.
+type JwksURI struct {
+	Path     string
+	URI      string
+	KeyCache string
+	ZoneName string
+}
+
+// APIKey defines the authentication of the requests by API keys.
+// Headers and Query are comma-separated lists of the headers and the query parameters that can hold the key.
+// ClientVariable is the variable of the map that maps the hash of the key of a request to the client ID.
+type APIKey struct {
+	Headers        string
+	Query          string
+	ClientVariable string
+}
+
+// Cache defines the caching of the responses of a location.
+// Valid holds the parameters of the proxy_cache_valid directives, like "200 301 10m".
+type Cache struct {
+	ZoneName      string
+	Key           string
+	Valid         []string
+	Bypass        []string
+	NoCache       []string
+	Methods       []string
+	PurgeVariable string
+}
+
+// CacheZone defines the zone of the cache of a cache policy.
+type CacheZone struct {
+	ZoneName string
+	ZoneSize string
+	MaxSize  string
+	Purge    *CachePurge
+}
+
+// CachePurge defines the purging of a cache by the PURGE requests from the allowed addresses.
+// AllowedVariable is 1 for the requests from the allowed addresses.
+// Variable is 1 for the PURGE requests from the allowed addresses.
+type CachePurge struct {
+	Allow           []string
+	AllowedVariable string
+	Variable        string
+}
+
+// GeoIP2 defines a variable with the data of a GeoIP2 database for the address of the client.
+// Path is the path of the data in the database, like "country iso_code".
+type GeoIP2 struct {
+	Database string
+	Variable string
+	Path     string
+}
+
+// GeoAccess defines the rejection of the requests based on the GeoIP2 data of the address of the client.
+// DeniedVariable is 1 for the requests that are denied.
+type GeoAccess struct {
+	DeniedVariable string
+	RejectCode     int
+	DryRun         bool
+}
+
+// Compression defines the compression of the responses with gzip and brotli.
+// Types is a space-separated list of the MIME types of the compressed responses.
+type Compression struct {
+	Gzip        bool
+	GzipLevel   int
+	Brotli      bool
+	BrotliLevel int
+	MinLength   int
+	Types       string
+	Proxied     bool
+}
+
+// ExternalAuth defines the authorization of the requests by an external service through an internal location.
+type ExternalAuth struct {
+	// Path is the path of the internal location.
+	Path            string
+	ProxyPass       string
+	ForwardHeaders  []Header
+	ResponseHeaders []ExternalAuthResponseHeader
+	Cache           *ExternalAuthCache
+}
+
+// ExternalAuthResponseHeader defines a header of the response of an external authorization service
+// that is passed to the upstream.
+type ExternalAuthResponseHeader struct {
+	Name string
+	// Variable keeps the value of the header of the response for the request.
+	Variable string
+	// UpstreamVariable is the $upstream_http_ variable of the header.
+	UpstreamVariable string
+}
+
+// ExternalAuthCache defines the cache of the decisions of an external authorization service.
+type ExternalAuthCache struct {
+	ZoneName string
+	ZoneSize string
+	Key      string
+	Valid    string
 }
 
 // BasicAuth refers to basic HTTP authentication mechanism options
@@ -359,3 +499,18 @@ type BasicAuth struct {
 	Secret string
 	Realm  string
 }
+
+// CORS defines the headers of the responses to the cross-origin requests.
+type CORS struct {
+	// AllowOrigin is either "*" or a variable with the origin of the request if the origin is allowed.
+	AllowOrigin      string
+	AllowMethods     string
+	AllowHeaders     string
+	ExposeHeaders    string
+	AllowCredentials bool
+	MaxAge           string
+	// VaryOrigin is true when the allowed origin depends on the origin of the request.
+	VaryOrigin bool
+	// PreflightVariable is not empty for the preflight requests.
+	PreflightVariable string
+}
@@ -6,6 +6,7 @@ type TransportServerConfig struct {
 	Upstreams      []StreamUpstream
 	StreamSnippets []string
 	Match          *Match
+	LimitConnZones []StreamLimitConnZone
 }
 
 // StreamUpstream defines a stream upstream.
@@ -30,6 +31,16 @@ type StreamServer struct {
 	UnixSocket               string
 	Port                     int
 	UDP                      bool
+	SSL                      *StreamSSL
+	IngressMTLS              *IngressMTLS
+	ProxySSL                 *StreamProxySSL
+	DenyAll                  bool
+	Allow                    []string
+	Deny                     []string
+	LimitConns               []StreamLimitConn
+	LimitConnOptions         StreamLimitConnOptions
+	ProxyUploadRate          string
+	ProxyDownloadRate        string
 	StatusZone               string
 	ProxyRequests            *int
 	ProxyResponses           *int
@@ -45,6 +56,42 @@ type StreamServer struct {
 	ServerSnippets           []string
 }
 
+// StreamSSL defines the TLS termination of the connections of a StreamServer.
+type StreamSSL struct {
+	Certificate    string
+	CertificateKey string
+}
+
+// StreamProxySSL defines the TLS configuration of the connections of a StreamServer to its StreamUpstream.
+type StreamProxySSL struct {
+	Certificate    string
+	CertificateKey string
+	TrustedCert    string
+	Verify         bool
+	VerifyDepth    int
+	ServerName     bool
+	Name           string
+}
+
+// StreamLimitConnZone defines a shared memory zone for the connections of a connection limit policy.
+type StreamLimitConnZone struct {
+	Key      string
+	ZoneName string
+	ZoneSize string
+}
+
+// StreamLimitConn defines the maximum number of the connections of a StreamServer for a zone.
+type StreamLimitConn struct {
+	ZoneName       string
+	MaxConnections int
+}
+
+// StreamLimitConnOptions defines the options of the connection limits of a StreamServer.
+type StreamLimitConnOptions struct {
+	DryRun   bool
+	LogLevel string
+}
+
 // StreamHealthCheck defines a health check for a StreamUpstream in a StreamServer.
 type StreamHealthCheck struct {
 	Enabled  bool
@@ -67,3 +114,15 @@ type Match struct {
 
 // TLSPassthroughHostsConfig defines a mapping between TLS Passthrough hosts and the corresponding unix sockets.
 type TLSPassthroughHostsConfig map[string]string
+
+// SNIListenersConfig defines the listeners that route TLS connections to TransportServers by SNI.
+type SNIListenersConfig []SNIListener
+
+// SNIListener defines a listener that routes TLS connections to the unix sockets of TransportServers by SNI.
+type SNIListener struct {
+	Name              string
+	Port              int
+	Variable          string
+	Hosts             map[string]string
+	DefaultUnixSocket string
+}
@@ -13,11 +13,35 @@ const tlsPassthroughHostsTemplateString = `# mapping between TLS Passthrough hos
 {{ end }}
 `
 
+// #nosec G101
+const sniListenersTemplateString = `# listeners that route TLS connections to TransportServers by SNI
+{{ range $l := . }}
+# listener {{ $l.Name }}
+map $ssl_preread_server_name {{ $l.Variable }} {
+    default {{ $l.DefaultUnixSocket }};
+    {{ range $h, $u := $l.Hosts }}
+    {{ $h }} {{ $u }};
+    {{ end }}
+}
+
+server {
+    listen {{ $l.Port }};
+    listen [::]:{{ $l.Port }};
+
+    ssl_preread on;
+
+    proxy_protocol on;
+    proxy_pass {{ $l.Variable }};
+}
+{{ end }}
+`
+
 // TemplateExecutor executes NGINX configuration templates.
 type TemplateExecutor struct {
 	virtualServerTemplate       *template.Template
 	transportServerTemplate     *template.Template
 	tlsPassthroughHostsTemplate *template.Template
+	sniListenersTemplate        *template.Template
 }
 
 // NewTemplateExecutor creates a TemplateExecutor.
@@ -39,10 +63,16 @@ func NewTemplateExecutor(virtualServerTemplatePath string, transportServerTempla
 		return nil, err
 	}
 
+	sniListenersTemplate, err := template.New("sniListeners").Parse(sniListenersTemplateString)
+	if err != nil {
+		return nil, err
+	}
+
 	return &TemplateExecutor{
 		virtualServerTemplate:       vsTemplate,
 		transportServerTemplate:     tsTemplate,
 		tlsPassthroughHostsTemplate: tlsPassthroughHostsTemplate,
+		sniListenersTemplate:        sniListenersTemplate,
 	}, nil
 }
 
@@ -81,3 +111,12 @@ func (te *TemplateExecutor) ExecuteTLSPassthroughHostsTemplate(cfg *TLSPassthrou
 
 	return configBuffer.Bytes(), err
 }
+
+// ExecuteSNIListenersTemplate generates the content of an NGINX configuration file for the listeners that route
+// TLS connections to TransportServers by SNI.
+func (te *TemplateExecutor) ExecuteSNIListenersTemplate(cfg *SNIListenersConfig) ([]byte, error) {
+	var configBuffer bytes.Buffer
+	err := te.sniListenersTemplate.Execute(&configBuffer, cfg)
+
+	return configBuffer.Bytes(), err
+}
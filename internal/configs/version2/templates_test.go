@@ -1,6 +1,7 @@
 package version2
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -12,6 +13,33 @@ const (
 )
 
 var virtualServerCfg = VirtualServerConfig{
+	CacheZones: []CacheZone{
+		{
+			ZoneName: "pol_cache_default_cache_default_cafe",
+			ZoneSize: "10m",
+			MaxSize:  "1g",
+			Purge: &CachePurge{
+				Allow:           []string{"10.0.0.0/8"},
+				AllowedVariable: "$pol_cache_default_cache_default_cafe_purge_allowed",
+				Variable:        "$pol_cache_default_cache_default_cafe_purge",
+			},
+		},
+	},
+	ExternalAuthCaches: []ExternalAuthCache{
+		{
+			ZoneName: "pol_ext_auth_default_ext-auth_default_cafe",
+			ZoneSize: "1m",
+			Key:      "${http_authorization}${http_cookie}${request_method}${request_uri}",
+			Valid:    "1m",
+		},
+	},
+	GeoIP2: []GeoIP2{
+		{
+			Database: "/etc/nginx/geoip/GeoLite2-Country.mmdb",
+			Variable: "$pol_geo_country_default_geo_default_cafe",
+			Path:     "country iso_code",
+		},
+	},
 	LimitReqZones: []LimitReqZone{
 		{
 			ZoneName: "pol_rl_test_test_test", Rate: "10r/s", ZoneSize: "10m", Key: "$url",
@@ -78,6 +106,20 @@ var virtualServerCfg = VirtualServerConfig{
 		},
 	},
 	Maps: []Map{
+		{
+			Source:   "$apikey_auth_hash",
+			Variable: "$pol_apikey_client_default_api_key_default_cafe",
+			Parameters: []Parameter{
+				{
+					Value:  "default",
+					Result: `""`,
+				},
+				{
+					Value:  "include",
+					Result: "/etc/nginx/secrets/default-api-key-secret",
+				},
+			},
+		},
 		{
 			Source:   "$match_0_0",
 			Variable: "$match",
@@ -152,6 +194,14 @@ var virtualServerCfg = VirtualServerConfig{
 			ApSecurityLogEnable: true,
 			ApLogConf:           []string{"/etc/nginx/waf/nac-logconfs/default-logconf"},
 		},
+		Compression: &Compression{
+			Gzip:        true,
+			GzipLevel:   5,
+			Brotli:      true,
+			BrotliLevel: 6,
+			MinLength:   256,
+			Types:       "text/css application/json",
+		},
 		Snippets: []string{"# server snippet"},
 		InternalRedirectLocations: []InternalRedirectLocation{
 			{
@@ -271,6 +321,11 @@ var virtualServerCfg = VirtualServerConfig{
 				ProxyPass:                "http://coffee-v2",
 				ProxyNextUpstream:        "error timeout",
 				ProxyNextUpstreamTimeout: "5s",
+				Mirror: &Mirror{
+					Path:           "/internal_location_mirror_0",
+					RequestBody:    true,
+					SampleVariable: "$vs_default_cafe_mirror_0",
+				},
 			},
 			{
 				Path:                "@loc2",
@@ -281,6 +336,154 @@ var virtualServerCfg = VirtualServerConfig{
 				ProxyPass:           "http://coffee-v2",
 				GRPCPass:            "grpc://coffee-v3",
 			},
+			{
+				Path:                "/cors",
+				ProxyConnectTimeout: "30s",
+				ProxyReadTimeout:    "31s",
+				ProxySendTimeout:    "32s",
+				ClientMaxBodySize:   "1m",
+				ProxyPass:           "http://coffee-v2",
+				CORS: &CORS{
+					AllowOrigin:       "$pol_cors_origin_default_cors_default_cafe",
+					AllowMethods:      "GET, POST",
+					AllowHeaders:      "Content-Type",
+					ExposeHeaders:     "X-Request-Id",
+					AllowCredentials:  true,
+					MaxAge:            "3600",
+					VaryOrigin:        true,
+					PreflightVariable: "$pol_cors_preflight_default_cors_default_cafe",
+				},
+			},
+			{
+				Path:                "/ext-auth",
+				ProxyConnectTimeout: "30s",
+				ProxyReadTimeout:    "31s",
+				ProxySendTimeout:    "32s",
+				ClientMaxBodySize:   "1m",
+				ProxyPass:           "http://coffee-v2",
+				ExternalAuth: &ExternalAuth{
+					Path: "/internal_location_ext_auth_default_ext-auth",
+					ResponseHeaders: []ExternalAuthResponseHeader{
+						{
+							Name:             "X-Auth-Request-User",
+							Variable:         "$ext_auth_x_auth_request_user",
+							UpstreamVariable: "$upstream_http_x_auth_request_user",
+						},
+					},
+				},
+			},
+			{
+				Path:                "/api-key",
+				ProxyConnectTimeout: "30s",
+				ProxyReadTimeout:    "31s",
+				ProxySendTimeout:    "32s",
+				ClientMaxBodySize:   "1m",
+				ProxyPass:           "http://coffee-v2",
+				APIKey: &APIKey{
+					Headers:        "X-API-Key",
+					Query:          "apikey",
+					ClientVariable: "$pol_apikey_client_default_api_key_default_cafe",
+				},
+			},
+			{
+				Path:                "/cache",
+				ProxyConnectTimeout: "30s",
+				ProxyReadTimeout:    "31s",
+				ProxySendTimeout:    "32s",
+				ClientMaxBodySize:   "1m",
+				ProxyPass:           "http://coffee-v2",
+				Cache: &Cache{
+					ZoneName:      "pol_cache_default_cache_default_cafe",
+					Key:           "${scheme}${host}${request_uri}",
+					Valid:         []string{"200 301 10m", "1m"},
+					Bypass:        []string{"${cookie_nocache}", "${arg_nocache}"},
+					NoCache:       []string{"${http_pragma}"},
+					Methods:       []string{"GET", "HEAD"},
+					PurgeVariable: "$pol_cache_default_cache_default_cafe_purge",
+				},
+			},
+			{
+				Path:                "/geo",
+				ProxyConnectTimeout: "30s",
+				ProxyReadTimeout:    "31s",
+				ProxySendTimeout:    "32s",
+				ClientMaxBodySize:   "1m",
+				ProxyPass:           "http://coffee-v2",
+				GeoAccess: &GeoAccess{
+					DeniedVariable: "$pol_geo_denied_default_geo_default_cafe",
+					RejectCode:     403,
+				},
+			},
+			{
+				Path:                "/geo-dry-run",
+				ProxyConnectTimeout: "30s",
+				ProxyReadTimeout:    "31s",
+				ProxySendTimeout:    "32s",
+				ClientMaxBodySize:   "1m",
+				ProxyPass:           "http://coffee-v2",
+				GeoAccess: &GeoAccess{
+					DeniedVariable: "$pol_geo_denied_default_geo_default_cafe",
+					RejectCode:     403,
+					DryRun:         true,
+				},
+			},
+			{
+				Path:                "/headers",
+				ProxyConnectTimeout: "30s",
+				ProxyReadTimeout:    "31s",
+				ProxySendTimeout:    "32s",
+				ClientMaxBodySize:   "1m",
+				ProxyPass:           "http://coffee-v2",
+				ProxySetHeaders: []Header{
+					{Name: "Host", Value: "$host"},
+					{Name: "X-User-ID", Value: "$http_x_user"},
+					{Name: "X-User", Value: ""},
+				},
+				ProxyHideHeaders: []string{"Server", "X-Internal"},
+				AddHeaders: []AddHeader{
+					{Header: Header{Name: "X-Backend", Value: "$upstream_http_x_internal"}, Always: true},
+					{Header: Header{Name: "X-Frame-Options", Value: "DENY"}, Always: true},
+				},
+				ProxyRedirects: []HeaderRewrite{
+					{From: "http://coffee.internal/", To: "https://cafe.example.com/"},
+				},
+				ProxyCookieDomains: []HeaderRewrite{
+					{From: "coffee.internal", To: "cafe.example.com"},
+				},
+			},
+			{
+				Path:                "/compression",
+				ProxyConnectTimeout: "30s",
+				ProxyReadTimeout:    "31s",
+				ProxySendTimeout:    "32s",
+				ClientMaxBodySize:   "1m",
+				ProxyPass:           "http://coffee-v2",
+				Compression: &Compression{
+					Gzip:        true,
+					GzipLevel:   1,
+					BrotliLevel: 6,
+					MinLength:   20,
+					Types:       "*",
+					Proxied:     true,
+				},
+			},
+			{
+				Path:                "/jwks",
+				ProxyConnectTimeout: "30s",
+				ProxyReadTimeout:    "31s",
+				ProxySendTimeout:    "32s",
+				ClientMaxBodySize:   "1m",
+				ProxyPass:           "http://coffee-v2",
+				JWTAuth: &JWTAuth{
+					Realm: "My Api",
+					JwksURI: &JwksURI{
+						Path:     "/_jwks_uri_default_jwt",
+						URI:      "https://idp.example.com/.well-known/jwks.json",
+						KeyCache: "12h",
+						ZoneName: "jwks_uri_default_jwt_default_cafe",
+					},
+				},
+			},
 			{
 				Path:                     "@match_loc_0",
 				ProxyConnectTimeout:      "30s",
@@ -343,6 +546,51 @@ var virtualServerCfg = VirtualServerConfig{
 				},
 			},
 		},
+		ExternalAuthLocations: []ExternalAuth{
+			{
+				Path:      "/internal_location_ext_auth_default_ext-auth",
+				ProxyPass: "http://vs_default_cafe_ext_auth_default_ext-auth/oauth2/auth",
+				ForwardHeaders: []Header{
+					{
+						Name:  "Authorization",
+						Value: "$http_authorization",
+					},
+				},
+				Cache: &ExternalAuthCache{
+					ZoneName: "pol_ext_auth_default_ext-auth_default_cafe",
+					ZoneSize: "1m",
+					Key:      "${http_authorization}${http_cookie}${request_method}${request_uri}",
+					Valid:    "1m",
+				},
+			},
+		},
+		JwksURILocations: []JwksURI{
+			{
+				Path:     "/_jwks_uri_default_jwt",
+				URI:      "https://idp.example.com/.well-known/jwks.json",
+				KeyCache: "12h",
+				ZoneName: "jwks_uri_default_jwt_default_cafe",
+			},
+		},
+		MirrorLocations: []Mirror{
+			{
+				Path:           "/internal_location_mirror_0",
+				ProxyPass:      "https://coffee-v3",
+				RequestBody:    true,
+				Percentage:     10,
+				SampleVariable: "$vs_default_cafe_mirror_0",
+				ProxySSLName:   "coffee-v3.default.svc",
+				EgressMTLS: &EgressMTLS{
+					Certificate:    "/etc/nginx/secrets/default-egress-mtls-secret",
+					CertificateKey: "/etc/nginx/secrets/default-egress-mtls-secret",
+					VerifyDepth:    1,
+					Protocols:      "TLSv1 TLSv1.1 TLSv1.2",
+					Ciphers:        "DEFAULT",
+					ServerName:     true,
+					SSLName:        "coffee-v3.default.svc",
+				},
+			},
+		},
 		ReturnLocations: []ReturnLocation{
 			{
 				Name:        "@return_0",
@@ -398,6 +646,62 @@ var transportServerCfg = TransportServerConfig{
 	},
 }
 
+var transportServerCfgWithTLS = TransportServerConfig{
+	Upstreams: []StreamUpstream{
+		{
+			Name: "tcp-upstream",
+			Servers: []StreamUpstreamServer{
+				{
+					Address: "10.0.0.20:5001",
+				},
+			},
+		},
+	},
+	Server: StreamServer{
+		Port:       1234,
+		StatusZone: "tcp-app",
+		SSL: &StreamSSL{
+			Certificate:    "/etc/nginx/secrets/default-tcp-secret",
+			CertificateKey: "/etc/nginx/secrets/default-tcp-secret",
+		},
+		IngressMTLS: &IngressMTLS{
+			ClientCert:   "/etc/nginx/secrets/default-ca-secret",
+			VerifyClient: "on",
+			VerifyDepth:  1,
+		},
+		ProxySSL: &StreamProxySSL{
+			Certificate:    "/etc/nginx/secrets/default-tcp-client-secret",
+			CertificateKey: "/etc/nginx/secrets/default-tcp-client-secret",
+			TrustedCert:    "/etc/nginx/secrets/default-tcp-ca-secret",
+			Verify:         true,
+			VerifyDepth:    1,
+			ServerName:     true,
+			Name:           "tcp-app-svc.default.svc",
+		},
+		Allow: []string{"10.0.0.0/8"},
+		LimitConns: []StreamLimitConn{
+			{
+				ZoneName:       "pol_lc_default_conn-limit_default_tcp-server",
+				MaxConnections: 10,
+			},
+		},
+		LimitConnOptions: StreamLimitConnOptions{
+			LogLevel: "error",
+		},
+		ProxyDownloadRate:   "1m",
+		ProxyPass:           "tcp-upstream",
+		ProxyTimeout:        "10s",
+		ProxyConnectTimeout: "10s",
+	},
+	LimitConnZones: []StreamLimitConnZone{
+		{
+			Key:      "${binary_remote_addr}",
+			ZoneName: "pol_lc_default_conn-limit_default_tcp-server",
+			ZoneSize: "10M",
+		},
+	},
+}
+
 func createPointerFromInt(n int) *int {
 	return &n
 }
@@ -432,6 +736,39 @@ func TestVirtualServerForNginx(t *testing.T) {
 	t.Log(string(data))
 }
 
+func TestVirtualServerMirrorLocationWithTLS(t *testing.T) {
+	t.Parallel()
+	for _, tmpl := range []string{nginxPlusVirtualServerTmpl, nginxVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&virtualServerCfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template %s: %v", tmpl, err)
+		}
+
+		config := string(data)
+		start := strings.Index(config, "location /internal_location_mirror_0 {")
+		end := strings.Index(config, "proxy_pass https://coffee-v3$request_uri;")
+		if start == -1 || end < start {
+			t.Fatalf("Template %s generated config without the mirror location", tmpl)
+		}
+
+		mirrorLocation := config[start:end]
+		for _, directive := range []string{
+			"proxy_ssl_certificate /etc/nginx/secrets/default-egress-mtls-secret;",
+			"proxy_ssl_server_name on;",
+			"proxy_ssl_name coffee-v3.default.svc;",
+		} {
+			if !strings.Contains(mirrorLocation, directive) {
+				t.Errorf("Template %s generated the mirror location without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
 func TestTransportServerForNginxPlus(t *testing.T) {
 	t.Parallel()
 	executor, err := NewTemplateExecutor(nginxPlusVirtualServerTmpl, nginxPlusTransportServerTmpl)
@@ -462,6 +799,72 @@ func TestTransportServerForNginx(t *testing.T) {
 	t.Log(string(data))
 }
 
+func TestTransportServerWithTLS(t *testing.T) {
+	t.Parallel()
+	for _, tmpl := range []string{nginxPlusTransportServerTmpl, nginxTransportServerTmpl} {
+		executor, err := NewTemplateExecutor(nginxVirtualServerTmpl, tmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteTransportServerTemplate(&transportServerCfgWithTLS)
+		if err != nil {
+			t.Fatalf("Failed to execute template %s: %v", tmpl, err)
+		}
+
+		for _, directive := range []string{
+			"listen 1234 ssl;",
+			"ssl_certificate /etc/nginx/secrets/default-tcp-secret;",
+			"ssl_client_certificate /etc/nginx/secrets/default-ca-secret;",
+			"ssl_verify_client on;",
+			"proxy_ssl on;",
+			"proxy_ssl_trusted_certificate /etc/nginx/secrets/default-tcp-ca-secret;",
+			"proxy_ssl_server_name on;",
+			"allow 10.0.0.0/8;",
+			"limit_conn_zone ${binary_remote_addr} zone=pol_lc_default_conn-limit_default_tcp-server:10M;",
+			"limit_conn pol_lc_default_conn-limit_default_tcp-server 10;",
+			"proxy_download_rate 1m;",
+		} {
+			if !strings.Contains(string(data), directive) {
+				t.Errorf("Template %s generated config without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestTransportServerWithInvalidTLSSecret(t *testing.T) {
+	t.Parallel()
+	for _, tmpl := range []string{nginxPlusTransportServerTmpl, nginxTransportServerTmpl} {
+		executor, err := NewTemplateExecutor(nginxVirtualServerTmpl, tmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		// the config of a TransportServer with a missing or invalid TLS secret
+		cfg := transportServerCfgWithTLS
+		cfg.Server.SSL = nil
+		cfg.Server.IngressMTLS = nil
+		cfg.Server.ProxyPass = "unix:/var/lib/nginx/non-existing-unix-socket.sock"
+
+		data, err := executor.ExecuteTransportServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template %s: %v", tmpl, err)
+		}
+
+		if !strings.Contains(string(data), "listen 1234;") {
+			t.Errorf("Template %s generated config without the listen without ssl", tmpl)
+		}
+		if !strings.Contains(string(data), "proxy_pass unix:/var/lib/nginx/non-existing-unix-socket.sock;") {
+			t.Errorf("Template %s generated config without the proxy pass to the non-existing unix socket", tmpl)
+		}
+		for _, directive := range []string{"ssl_reject_handshake", "ssl_certificate /etc/nginx/secrets/default-tcp-secret;"} {
+			if strings.Contains(string(data), directive) {
+				t.Errorf("Template %s generated config with %q", tmpl, directive)
+			}
+		}
+	}
+}
+
 func TestTLSPassthroughHosts(t *testing.T) {
 	t.Parallel()
 	executor, err := NewTemplateExecutor(nginxVirtualServerTmpl, nginxTransportServerTmpl)
@@ -480,3 +883,65 @@ func TestTLSPassthroughHosts(t *testing.T) {
 
 	t.Log(string(data))
 }
+
+func TestTransportServerWithSNI(t *testing.T) {
+	t.Parallel()
+	for _, tmpl := range []string{nginxPlusTransportServerTmpl, nginxTransportServerTmpl} {
+		executor, err := NewTemplateExecutor(nginxVirtualServerTmpl, tmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		cfg := transportServerCfgWithTLS
+		cfg.Server.UnixSocket = "unix:/var/lib/nginx/sni-default_tcp-server.sock"
+
+		data, err := executor.ExecuteTransportServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template %s: %v", tmpl, err)
+		}
+
+		if !strings.Contains(string(data), "listen unix:/var/lib/nginx/sni-default_tcp-server.sock proxy_protocol ssl;") {
+			t.Errorf("Template %s generated config without the listen on the unix socket", tmpl)
+		}
+		if strings.Contains(string(data), "listen 1234") {
+			t.Errorf("Template %s generated config with the listen on the port of the listener", tmpl)
+		}
+	}
+}
+
+func TestSNIListeners(t *testing.T) {
+	t.Parallel()
+	executor, err := NewTemplateExecutor(nginxVirtualServerTmpl, nginxTransportServerTmpl)
+	if err != nil {
+		t.Fatalf("Failed to create template executor: %v", err)
+	}
+
+	sniListenersCfg := SNIListenersConfig{
+		{
+			Name:     "tcp-443",
+			Port:     443,
+			Variable: "$dest_sni_tcp_443",
+			Hosts: map[string]string{
+				"app.example.com": "unix:/var/lib/nginx/sni-default_secure-app.sock",
+			},
+			DefaultUnixSocket: "unix:/var/lib/nginx/non-existing-unix-socket.sock",
+		},
+	}
+
+	data, err := executor.ExecuteSNIListenersTemplate(&sniListenersCfg)
+	if err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+
+	for _, directive := range []string{
+		"map $ssl_preread_server_name $dest_sni_tcp_443 {",
+		"app.example.com unix:/var/lib/nginx/sni-default_secure-app.sock;",
+		"listen 443;",
+		"ssl_preread on;",
+		"proxy_pass $dest_sni_tcp_443;",
+	} {
+		if !strings.Contains(string(data), directive) {
+			t.Errorf("Template generated config without %q", directive)
+		}
+	}
+}
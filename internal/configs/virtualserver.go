@@ -2,6 +2,9 @@ package configs
 
 import (
 	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -87,6 +90,14 @@ type VirtualServerEx struct {
 	LogConfRefs         map[string]*unstructured.Unstructured
 	DosProtectedRefs    map[string]*unstructured.Unstructured
 	DosProtectedEx      map[string]*DosEx
+	// SplitWeights are the weights of the upstreams of the splits set by TrafficShifts, keyed by the path of the route.
+	SplitWeights map[string]SplitWeight
+}
+
+// SplitWeight is the weight of the upstream of one of the two splits of a route.
+type SplitWeight struct {
+	Upstream string
+	Weight   int
 }
 
 func (vsx *VirtualServerEx) String() string {
@@ -139,6 +150,11 @@ func newUpstreamNamerForVirtualServer(virtualServer *conf_v1.VirtualServer) *ups
 	}
 }
 
+// GetUpstreamNameForVirtualServer returns the name of the NGINX upstream of the upstream of the VirtualServer.
+func GetUpstreamNameForVirtualServer(virtualServer *conf_v1.VirtualServer, upstream string) string {
+	return newUpstreamNamerForVirtualServer(virtualServer).GetNameForUpstream(upstream)
+}
+
 func newUpstreamNamerForVirtualServerRoute(
 	virtualServer *conf_v1.VirtualServer,
 	virtualServerRoute *conf_v1.VirtualServerRoute,
@@ -191,6 +207,10 @@ func (namer *variableNamer) GetNameForSplitClientVariable(index int) string {
 	return fmt.Sprintf("$vs_%s_splits_%d", namer.safeNsName, index)
 }
 
+func (namer *variableNamer) GetNameForMirrorVariable(index int) string {
+	return fmt.Sprintf("$vs_%s_mirror_%d", namer.safeNsName, index)
+}
+
 func (namer *variableNamer) GetNameForVariableForMatchesRouteMap(
 	matchesIndex int,
 	matchIndex int,
@@ -236,6 +256,9 @@ type virtualServerConfigurator struct {
 	warnings             Warnings
 	spiffeCerts          bool
 	oidcPolCfg           *oidcPolicyCfg
+	// circuitBreakers maps the names of the upstreams to the circuit breakers of the resilience policies of their routes.
+	// GenerateVirtualServerConfig sets it, so that the updates of the upstreams through the NGINX Plus API can reuse it.
+	circuitBreakers map[string]*conf_v1.CircuitBreaker
 }
 
 type oidcPolicyCfg struct {
@@ -308,6 +331,7 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 	dosResources map[string]*appProtectDosResource,
 ) (version2.VirtualServerConfig, Warnings) {
 	vsc.clearWarnings()
+	vsc.circuitBreakers = make(map[string]*conf_v1.CircuitBreaker)
 
 	sslConfig := vsc.generateSSLConfig(vsEx.VirtualServer, vsEx.VirtualServer.Spec.TLS, vsEx.VirtualServer.Namespace, vsEx.SecretRefs, vsc.cfgParams)
 	tlsRedirectConfig := generateTLSRedirectConfig(vsEx.VirtualServer.Spec.TLS)
@@ -337,8 +361,12 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 	var statusMatches []version2.StatusMatch
 	var healthChecks []version2.HealthCheck
 	var limitReqZones []version2.LimitReqZone
+	var cacheZones []version2.CacheZone
+	var geoIP2 []version2.GeoIP2
 
 	limitReqZones = append(limitReqZones, policiesCfg.LimitReqZones...)
+	cacheZones = append(cacheZones, policiesCfg.CacheZones...)
+	geoIP2 = append(geoIP2, policiesCfg.GeoIP2...)
 
 	// generate upstreams for VirtualServer
 	for _, u := range vsEx.VirtualServer.Spec.Upstreams {
@@ -400,11 +428,14 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 		}
 	}
 
+	upstreams = append(upstreams, vsc.generateExternalAuthUpstreams(vsEx)...)
+
 	var locations []version2.Location
 	var internalRedirectLocations []version2.InternalRedirectLocation
 	var returnLocations []version2.ReturnLocation
 	var splitClients []version2.SplitClient
 	var maps []version2.Map
+	maps = append(maps, policiesCfg.Maps...)
 	var errorPageLocations []version2.ErrorPageLocation
 	vsrErrorPagesFromVs := make(map[string][]conf_v1.ErrorPage)
 	vsrErrorPagesRouteIndex := make(map[string]int)
@@ -461,10 +492,49 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 		if policiesCfg.OIDC {
 			routePoliciesCfg.OIDC = policiesCfg.OIDC
 		}
+		if routePoliciesCfg.CORS == nil {
+			routePoliciesCfg.CORS = policiesCfg.CORS
+		}
+		if routePoliciesCfg.ExternalAuth == nil {
+			routePoliciesCfg.ExternalAuth = policiesCfg.ExternalAuth
+		}
+		if routePoliciesCfg.APIKey == nil {
+			routePoliciesCfg.APIKey = policiesCfg.APIKey
+		}
+		if routePoliciesCfg.Cache == nil {
+			routePoliciesCfg.Cache = policiesCfg.Cache
+		}
+		if routePoliciesCfg.Resilience == nil {
+			routePoliciesCfg.Resilience = policiesCfg.Resilience
+		}
+		if routePoliciesCfg.GeoAccess == nil {
+			routePoliciesCfg.GeoAccess = policiesCfg.GeoAccess
+		}
+		if routePoliciesCfg.Headers == nil {
+			routePoliciesCfg.Headers = policiesCfg.Headers
+		}
 		limitReqZones = append(limitReqZones, routePoliciesCfg.LimitReqZones...)
+		cacheZones = append(cacheZones, routePoliciesCfg.CacheZones...)
+		geoIP2 = append(geoIP2, routePoliciesCfg.GeoIP2...)
+		maps = append(maps, routePoliciesCfg.Maps...)
 
 		dosRouteCfg := generateDosCfg(dosResources[r.Path])
 
+		if r.Canary != nil {
+			r = generateCanaryRoute(r)
+		}
+
+		if splitWeight, exists := vsEx.SplitWeights[r.Path]; exists {
+			r = applySplitWeight(r, splitWeight)
+		}
+
+		vsc.checkHeadersPolicy(vsEx.VirtualServer, routePoliciesCfg.Headers, r)
+
+		// the requests of a route with invalid policies are not passed to the upstreams
+		if routePoliciesCfg.ErrorReturn == nil {
+			vsc.addCircuitBreaker(vsEx.VirtualServer, vsc.circuitBreakers, routePoliciesCfg.Resilience, r, virtualServerUpstreamNamer)
+		}
+
 		if len(r.Matches) > 0 {
 			cfg := generateMatchesConfig(
 				r,
@@ -508,6 +578,7 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 
 			loc, returnLoc := generateLocation(r.Path, upstreamName, upstream, r.Action, vsc.cfgParams, errorPages, false,
 				proxySSLName, r.Path, vsLocSnippets, vsc.enableSnippets, len(returnLocations), isVSR, "", "", vsc.warnings)
+			loc.Mirror = generateMirror(r.Action, virtualServerUpstreamNamer, crUpstreams)
 			addPoliciesCfgToLocation(routePoliciesCfg, &loc)
 			loc.Dos = dosRouteCfg
 
@@ -571,10 +642,44 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 			if policiesCfg.OIDC {
 				routePoliciesCfg.OIDC = policiesCfg.OIDC
 			}
+			if routePoliciesCfg.CORS == nil {
+				routePoliciesCfg.CORS = policiesCfg.CORS
+			}
+			if routePoliciesCfg.ExternalAuth == nil {
+				routePoliciesCfg.ExternalAuth = policiesCfg.ExternalAuth
+			}
+			if routePoliciesCfg.APIKey == nil {
+				routePoliciesCfg.APIKey = policiesCfg.APIKey
+			}
+			if routePoliciesCfg.Cache == nil {
+				routePoliciesCfg.Cache = policiesCfg.Cache
+			}
+			if routePoliciesCfg.Resilience == nil {
+				routePoliciesCfg.Resilience = policiesCfg.Resilience
+			}
+			if routePoliciesCfg.GeoAccess == nil {
+				routePoliciesCfg.GeoAccess = policiesCfg.GeoAccess
+			}
+			if routePoliciesCfg.Headers == nil {
+				routePoliciesCfg.Headers = policiesCfg.Headers
+			}
 			limitReqZones = append(limitReqZones, routePoliciesCfg.LimitReqZones...)
+			cacheZones = append(cacheZones, routePoliciesCfg.CacheZones...)
+			geoIP2 = append(geoIP2, routePoliciesCfg.GeoIP2...)
+			maps = append(maps, routePoliciesCfg.Maps...)
 
 			dosRouteCfg := generateDosCfg(dosResources[r.Path])
 
+			if r.Canary != nil {
+				r = generateCanaryRoute(r)
+			}
+
+			vsc.checkHeadersPolicy(ownerDetails.owner, routePoliciesCfg.Headers, r)
+
+			if routePoliciesCfg.ErrorReturn == nil {
+				vsc.addCircuitBreaker(ownerDetails.owner, vsc.circuitBreakers, routePoliciesCfg.Resilience, r, upstreamNamer)
+			}
+
 			if len(r.Matches) > 0 {
 				cfg := generateMatchesConfig(
 					r,
@@ -619,6 +724,7 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 
 				loc, returnLoc := generateLocation(r.Path, upstreamName, upstream, r.Action, vsc.cfgParams, errorPages, false,
 					proxySSLName, r.Path, locSnippets, vsc.enableSnippets, len(returnLocations), isVSR, vsr.Name, vsr.Namespace, vsc.warnings)
+				loc.Mirror = generateMirror(r.Action, upstreamNamer, crUpstreams)
 				addPoliciesCfgToLocation(routePoliciesCfg, &loc)
 				loc.Dos = dosRouteCfg
 
@@ -630,6 +736,8 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 		}
 	}
 
+	vsc.applyCircuitBreakers(vsEx.VirtualServer, upstreams, vsc.circuitBreakers)
+
 	httpSnippets := generateSnippets(vsc.enableSnippets, vsEx.VirtualServer.Spec.HTTPSnippets, []string{})
 	serverSnippets := generateSnippets(
 		vsc.enableSnippets,
@@ -637,13 +745,22 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 		vsc.cfgParams.ServerSnippets,
 	)
 
+	mirrorLocations, mirrorSplitClients := generateMirrorLocations(locations, variableNamer)
+	splitClients = append(splitClients, mirrorSplitClients...)
+
+	externalAuthLocations, externalAuthCaches := generateExternalAuthLocations(locations)
+	jwksURILocations := generateJwksURILocations(policiesCfg.JWTAuth, locations)
+
 	vsCfg := version2.VirtualServerConfig{
-		Upstreams:     upstreams,
-		SplitClients:  splitClients,
-		Maps:          maps,
-		StatusMatches: statusMatches,
-		LimitReqZones: removeDuplicateLimitReqZones(limitReqZones),
-		HTTPSnippets:  httpSnippets,
+		Upstreams:          upstreams,
+		SplitClients:       splitClients,
+		Maps:               removeDuplicateMaps(maps),
+		StatusMatches:      statusMatches,
+		LimitReqZones:      removeDuplicateLimitReqZones(limitReqZones),
+		CacheZones:         removeDuplicateCacheZones(cacheZones),
+		ExternalAuthCaches: externalAuthCaches,
+		GeoIP2:             removeDuplicateGeoIP2(geoIP2),
+		HTTPSnippets:       httpSnippets,
 		Server: version2.Server{
 			ServerName:                vsEx.VirtualServer.Spec.Host,
 			StatusZone:                vsEx.VirtualServer.Spec.Host,
@@ -657,6 +774,9 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 			InternalRedirectLocations: internalRedirectLocations,
 			Locations:                 locations,
 			ReturnLocations:           returnLocations,
+			MirrorLocations:           mirrorLocations,
+			ExternalAuthLocations:     externalAuthLocations,
+			JwksURILocations:          jwksURILocations,
 			HealthChecks:              healthChecks,
 			TLSRedirect:               tlsRedirectConfig,
 			ErrorPageLocations:        errorPageLocations,
@@ -672,6 +792,7 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 			OIDC:                      vsc.oidcPolCfg.oidc,
 			WAF:                       policiesCfg.WAF,
 			Dos:                       dosCfg,
+			Compression:               policiesCfg.Compression,
 			PoliciesErrorReturn:       policiesCfg.ErrorReturn,
 			VSNamespace:               vsEx.VirtualServer.Namespace,
 			VSName:                    vsEx.VirtualServer.Name,
@@ -694,6 +815,17 @@ type policiesCfg struct {
 	EgressMTLS      *version2.EgressMTLS
 	OIDC            bool
 	WAF             *version2.WAF
+	CORS            *version2.CORS
+	Maps            []version2.Map
+	ExternalAuth    *version2.ExternalAuth
+	APIKey          *version2.APIKey
+	Cache           *version2.Cache
+	CacheZones      []version2.CacheZone
+	Resilience      *conf_v1.Resilience
+	GeoAccess       *version2.GeoAccess
+	GeoIP2          []version2.GeoIP2
+	Headers         *conf_v1.Headers
+	Compression     *version2.Compression
 	ErrorReturn     *version2.Return
 }
 
@@ -749,9 +881,14 @@ func (p *policiesCfg) addRateLimitConfig(
 ) *validationResults {
 	res := newValidationResults()
 	rlZoneName := fmt.Sprintf("pol_rl_%v_%v_%v_%v", polNamespace, polName, vsNamespace, vsName)
-	p.LimitReqs = append(p.LimitReqs, generateLimitReq(rlZoneName, rateLimit))
-	p.LimitReqZones = append(p.LimitReqZones, generateLimitReqZone(rlZoneName, rateLimit))
-	if len(p.LimitReqs) == 1 {
+	isFirst := len(p.LimitReqs) == 0
+	if len(rateLimit.Tiers) > 0 {
+		p.addRateLimitTiers(rlZoneName, rateLimit)
+	} else {
+		p.LimitReqs = append(p.LimitReqs, generateLimitReq(rlZoneName, rateLimit))
+		p.LimitReqZones = append(p.LimitReqZones, generateLimitReqZone(rlZoneName, rateLimit))
+	}
+	if isFirst {
 		p.LimitReqOptions = generateLimitReqOptions(rateLimit)
 	} else {
 		curOptions := generateLimitReqOptions(rateLimit)
@@ -768,6 +905,91 @@ func (p *policiesCfg) addRateLimitConfig(
 	return res
 }
 
+// addRateLimitTiers adds a zone for every tier of a rate limit policy. For the zone of a tier with a condition,
+// a map evaluates to the key of the policy for the requests that match the condition and to an empty string,
+// which NGINX doesn't limit, for the other requests. The zone of the tier without a condition limits
+// the requests that match no other tier.
+func (p *policiesCfg) addRateLimitTiers(zoneName string, rateLimit *conf_v1.RateLimit) {
+	keyVariables := make(map[string]string)
+	var defaultSource string
+
+	for _, t := range rateLimit.Tiers {
+		if t.Condition == nil {
+			continue
+		}
+		keyVariables[t.Name] = generateRateLimitTierKeyVariable(zoneName, t.Name)
+		defaultSource += keyVariables[t.Name]
+	}
+
+	for _, t := range rateLimit.Tiers {
+		tierZoneName := zoneName + "_" + t.Name
+		key := rateLimit.Key
+
+		if t.Condition != nil {
+			key = keyVariables[t.Name]
+			p.Maps = append(p.Maps, version2.Map{
+				Source:   generateRateLimitConditionSource(t.Condition),
+				Variable: key,
+				Parameters: []version2.Parameter{
+					{
+						Value:  fmt.Sprintf(`"%s"`, t.Condition.Value),
+						Result: fmt.Sprintf(`"%s"`, rateLimit.Key),
+					},
+					{
+						Value:  "default",
+						Result: `""`,
+					},
+				},
+			})
+		} else if defaultSource != "" {
+			key = generateRateLimitTierKeyVariable(zoneName, t.Name)
+			p.Maps = append(p.Maps, version2.Map{
+				Source:   fmt.Sprintf(`"%s"`, defaultSource),
+				Variable: key,
+				Parameters: []version2.Parameter{
+					{
+						Value:  `""`,
+						Result: fmt.Sprintf(`"%s"`, rateLimit.Key),
+					},
+					{
+						Value:  "default",
+						Result: `""`,
+					},
+				},
+			})
+		}
+
+		limitReq := generateLimitReq(tierZoneName, rateLimit)
+		if t.Burst != nil {
+			limitReq.Burst = *t.Burst
+		}
+		p.LimitReqs = append(p.LimitReqs, limitReq)
+		p.LimitReqZones = append(p.LimitReqZones, version2.LimitReqZone{
+			ZoneName: tierZoneName,
+			Key:      key,
+			ZoneSize: generateString(t.ZoneSize, rateLimit.ZoneSize),
+			Rate:     t.Rate,
+		})
+	}
+}
+
+func generateRateLimitTierKeyVariable(zoneName string, tierName string) string {
+	variable := fmt.Sprintf("$%v_%v_key", zoneName, tierName)
+	return strings.NewReplacer("-", "_", ".", "_").Replace(variable)
+}
+
+// generateRateLimitConditionSource generates the source of the map of the condition of a rate limit tier.
+func generateRateLimitConditionSource(condition *conf_v1.RateLimitCondition) string {
+	switch {
+	case condition.JWTClaim != "":
+		return "$jwt_claim_" + condition.JWTClaim
+	case condition.Header != "":
+		return "$http_" + strings.ReplaceAll(strings.ToLower(condition.Header), "-", "_")
+	default:
+		return fmt.Sprintf(`"%s"`, condition.Variable)
+	}
+}
+
 func (p *policiesCfg) addBasicAuthConfig(
 	basicAuth *conf_v1.BasicAuth,
 	polKey string,
@@ -803,10 +1025,72 @@ func (p *policiesCfg) addBasicAuthConfig(
 	return res
 }
 
+func (p *policiesCfg) addAPIKeyConfig(
+	apiKey *conf_v1.APIKey,
+	polKey string,
+	polNamespace string,
+	polName string,
+	vsNamespace string,
+	vsName string,
+	secretRefs map[string]*secrets.SecretReference,
+) *validationResults {
+	res := newValidationResults()
+	if p.APIKey != nil {
+		res.addWarningf("Multiple API Key policies in the same context is not valid. API Key policy %s will be ignored", polKey)
+		return res
+	}
+
+	secretKey := fmt.Sprintf("%v/%v", polNamespace, apiKey.ClientSecret)
+	secretRef := secretRefs[secretKey]
+	var secretType api_v1.SecretType
+	if secretRef.Secret != nil {
+		secretType = secretRef.Secret.Type
+	}
+	if secretType != "" && secretType != secrets.SecretTypeAPIKey {
+		res.addWarningf("API Key policy %s references a secret %s of a wrong type '%s', must be '%s'", polKey, secretKey, secretType, secrets.SecretTypeAPIKey)
+		res.isError = true
+		return res
+	} else if secretRef.Error != nil {
+		res.addWarningf("API Key policy %s references an invalid secret %s: %v", polKey, secretKey, secretRef.Error)
+		res.isError = true
+		return res
+	}
+
+	clientVariable := fmt.Sprintf("$pol_apikey_client_%v_%v_%v_%v", polNamespace, polName, vsNamespace, vsName)
+	clientVariable = strings.NewReplacer("-", "_", ".", "_").Replace(clientVariable)
+
+	p.APIKey = &version2.APIKey{
+		Headers:        strings.Join(apiKey.SuppliedIn.Header, ","),
+		Query:          strings.Join(apiKey.SuppliedIn.Query, ","),
+		ClientVariable: clientVariable,
+	}
+
+	// the map includes the file with the hashes of the API keys of the secret, see GenerateAPIKeyFileContent
+	p.Maps = append(p.Maps, version2.Map{
+		Source:   "$apikey_auth_hash",
+		Variable: clientVariable,
+		Parameters: []version2.Parameter{
+			{
+				Value:  "default",
+				Result: `""`,
+			},
+			{
+				Value:  "include",
+				Result: secretRef.Path,
+			},
+		},
+	})
+
+	return res
+}
+
 func (p *policiesCfg) addJWTAuthConfig(
 	jwtAuth *conf_v1.JWTAuth,
 	polKey string,
 	polNamespace string,
+	polName string,
+	vsNamespace string,
+	vsName string,
 	secretRefs map[string]*secrets.SecretReference,
 ) *validationResults {
 	res := newValidationResults()
@@ -815,6 +1099,20 @@ func (p *policiesCfg) addJWTAuthConfig(
 		return res
 	}
 
+	if jwtAuth.JwksURI != "" {
+		p.JWTAuth = &version2.JWTAuth{
+			Realm: jwtAuth.Realm,
+			Token: jwtAuth.Token,
+			JwksURI: &version2.JwksURI{
+				Path:     fmt.Sprintf("/_jwks_uri_%v_%v", polNamespace, polName),
+				URI:      jwtAuth.JwksURI,
+				KeyCache: generateString(jwtAuth.KeyCache, "12h"),
+				ZoneName: fmt.Sprintf("jwks_uri_%v_%v_%v_%v", polNamespace, polName, vsNamespace, vsName),
+			},
+		}
+		return res
+	}
+
 	jwtSecretKey := fmt.Sprintf("%v/%v", polNamespace, jwtAuth.Secret)
 	secretRef := secretRefs[jwtSecretKey]
 	var secretType api_v1.SecretType
@@ -1006,117 +1304,820 @@ func (p *policiesCfg) addOIDCConfig(
 		if secretRef.Secret != nil {
 			secretType = secretRef.Secret.Type
 		}
-		if secretType != "" && secretType != secrets.SecretTypeOIDC {
-			res.addWarningf("OIDC policy %s references a secret %s of a wrong type '%s', must be '%s'", polKey, secretKey, secretType, secrets.SecretTypeOIDC)
-			res.isError = true
-			return res
-		} else if secretRef.Error != nil {
-			res.addWarningf("OIDC policy %s references an invalid secret %s: %v", polKey, secretKey, secretRef.Error)
-			res.isError = true
-			return res
+		if secretType != "" && secretType != secrets.SecretTypeOIDC {
+			res.addWarningf("OIDC policy %s references a secret %s of a wrong type '%s', must be '%s'", polKey, secretKey, secretType, secrets.SecretTypeOIDC)
+			res.isError = true
+			return res
+		} else if secretRef.Error != nil {
+			res.addWarningf("OIDC policy %s references an invalid secret %s: %v", polKey, secretKey, secretRef.Error)
+			res.isError = true
+			return res
+		}
+
+		clientSecret := secretRef.Secret.Data[ClientSecretKey]
+
+		redirectURI := oidc.RedirectURI
+		if redirectURI == "" {
+			redirectURI = "/_codexch"
+		}
+		scope := oidc.Scope
+		if scope == "" {
+			scope = "openid"
+		}
+
+		oidcPolCfg.oidc = &version2.OIDC{
+			AuthEndpoint:   oidc.AuthEndpoint,
+			TokenEndpoint:  oidc.TokenEndpoint,
+			JwksURI:        oidc.JWKSURI,
+			ClientID:       oidc.ClientID,
+			ClientSecret:   string(clientSecret),
+			Scope:          scope,
+			RedirectURI:    redirectURI,
+			ZoneSyncLeeway: generateIntFromPointer(oidc.ZoneSyncLeeway, 200),
+		}
+		oidcPolCfg.key = polKey
+	}
+
+	p.OIDC = true
+
+	return res
+}
+
+func (p *policiesCfg) addWAFConfig(
+	waf *conf_v1.WAF,
+	polKey string,
+	polNamespace string,
+	apResources *appProtectResourcesForVS,
+) *validationResults {
+	res := newValidationResults()
+	if p.WAF != nil {
+		res.addWarningf("Multiple WAF policies in the same context is not valid. WAF policy %s will be ignored", polKey)
+		return res
+	}
+
+	if waf.Enable {
+		p.WAF = &version2.WAF{Enable: "on"}
+	} else {
+		p.WAF = &version2.WAF{Enable: "off"}
+	}
+
+	if waf.ApPolicy != "" {
+		apPolKey := waf.ApPolicy
+		hasNamespace := strings.Contains(apPolKey, "/")
+		if !hasNamespace {
+			apPolKey = fmt.Sprintf("%v/%v", polNamespace, apPolKey)
+		}
+
+		if apPolPath, exists := apResources.Policies[apPolKey]; exists {
+			p.WAF.ApPolicy = apPolPath
+		} else {
+			res.addWarningf("WAF policy %s references an invalid or non-existing App Protect policy %s", polKey, apPolKey)
+			res.isError = true
+			return res
+		}
+	}
+
+	if waf.SecurityLog != nil && waf.SecurityLogs == nil {
+		glog.V(2).Info("the field securityLog is deprecated nad will be removed in future releases. Use field securityLogs instead")
+		p.WAF.ApSecurityLogEnable = true
+
+		logConfKey := waf.SecurityLog.ApLogConf
+		hasNamespace := strings.Contains(logConfKey, "/")
+		if !hasNamespace {
+			logConfKey = fmt.Sprintf("%v/%v", polNamespace, logConfKey)
+		}
+
+		if logConfPath, ok := apResources.LogConfs[logConfKey]; ok {
+			logDest := generateString(waf.SecurityLog.LogDest, "syslog:server=localhost:514")
+			p.WAF.ApLogConf = []string{fmt.Sprintf("%s %s", logConfPath, logDest)}
+		} else {
+			res.addWarningf("WAF policy %s references an invalid or non-existing log config %s", polKey, logConfKey)
+			res.isError = true
+		}
+	}
+
+	if waf.SecurityLogs != nil {
+		p.WAF.ApSecurityLogEnable = true
+		p.WAF.ApLogConf = []string{}
+		for _, loco := range waf.SecurityLogs {
+			logConfKey := loco.ApLogConf
+			hasNamepace := strings.Contains(logConfKey, "/")
+			if !hasNamepace {
+				logConfKey = fmt.Sprintf("%v/%v", polNamespace, logConfKey)
+			}
+			if logConfPath, ok := apResources.LogConfs[logConfKey]; ok {
+				logDest := generateString(loco.LogDest, "syslog:server=localhost:514")
+				p.WAF.ApLogConf = append(p.WAF.ApLogConf, fmt.Sprintf("%s %s", logConfPath, logDest))
+			} else {
+				res.addWarningf("WAF policy %s references an invalid or non-existing log config %s", polKey, logConfKey)
+				res.isError = true
+			}
+		}
+	}
+	return res
+}
+
+func (p *policiesCfg) addCORSConfig(
+	cors *conf_v1.CORS,
+	polKey string,
+	polNamespace string,
+	polName string,
+	vsNamespace string,
+	vsName string,
+) *validationResults {
+	res := newValidationResults()
+	if p.CORS != nil {
+		res.addWarningf("Multiple CORS policies in the same context is not valid. CORS policy %s will be ignored", polKey)
+		return res
+	}
+
+	p.CORS = &version2.CORS{
+		AllowOrigin:       "*",
+		AllowMethods:      strings.Join(cors.AllowMethods, ", "),
+		AllowHeaders:      strings.Join(cors.AllowHeaders, ", "),
+		ExposeHeaders:     strings.Join(cors.ExposeHeaders, ", "),
+		AllowCredentials:  generateBool(cors.AllowCredentials, false),
+		PreflightVariable: generateCORSVariableName("preflight", polNamespace, polName, vsNamespace, vsName),
+	}
+	if cors.MaxAge != nil {
+		p.CORS.MaxAge = strconv.Itoa(*cors.MaxAge)
+	}
+
+	p.Maps = append(p.Maps, version2.Map{
+		Source:   "$request_method:$http_access_control_request_method",
+		Variable: p.CORS.PreflightVariable,
+		Parameters: []version2.Parameter{
+			{
+				Value:  `"~^OPTIONS:."`,
+				Result: "1",
+			},
+			{
+				Value:  "default",
+				Result: `""`,
+			},
+		},
+	})
+
+	for _, origin := range cors.AllowOrigin {
+		if origin == "*" {
+			return res
+		}
+	}
+
+	p.CORS.AllowOrigin = generateCORSVariableName("origin", polNamespace, polName, vsNamespace, vsName)
+	p.CORS.VaryOrigin = true
+	p.Maps = append(p.Maps, version2.Map{
+		Source:     "$http_origin",
+		Variable:   p.CORS.AllowOrigin,
+		Parameters: generateParametersForCORSOriginMap(cors.AllowOrigin),
+	})
+
+	return res
+}
+
+func generateCORSVariableName(name string, polNamespace string, polName string, vsNamespace string, vsName string) string {
+	variable := fmt.Sprintf("$pol_cors_%v_%v_%v_%v_%v", name, polNamespace, polName, vsNamespace, vsName)
+	return strings.NewReplacer("-", "_", ".", "_").Replace(variable)
+}
+
+// generateParametersForCORSOriginMap generates the parameters of the map that evaluates to the origin of the request
+// if the origin is allowed and to an empty string otherwise.
+// An origin with a wildcard like https://*.example.com is converted to a regular expression.
+func generateParametersForCORSOriginMap(origins []string) []version2.Parameter {
+	var params []version2.Parameter
+
+	for _, origin := range origins {
+		value := origin
+		if i := strings.Index(origin, "://*."); i != -1 && !strings.HasPrefix(origin, "~") {
+			value = fmt.Sprintf(`~^%v://[a-z0-9.-]+\.%v$`, origin[:i], regexp.QuoteMeta(origin[i+len("://*."):]))
+		}
+
+		params = append(params, version2.Parameter{
+			Value:  fmt.Sprintf(`"%v"`, value),
+			Result: "$http_origin",
+		})
+	}
+
+	params = append(params, version2.Parameter{
+		Value:  "default",
+		Result: `""`,
+	})
+
+	return params
+}
+
+func (p *policiesCfg) addExternalAuthConfig(
+	extAuth *conf_v1.ExternalAuth,
+	polKey string,
+	polNamespace string,
+	polName string,
+	vsNamespace string,
+	vsName string,
+) *validationResults {
+	res := newValidationResults()
+	if p.ExternalAuth != nil {
+		res.addWarningf("Multiple externalAuth policies in the same context is not valid. ExternalAuth policy %s will be ignored", polKey)
+		return res
+	}
+
+	upstreamName := generateExternalAuthUpstreamName(vsNamespace, vsName, polNamespace, polName)
+
+	p.ExternalAuth = &version2.ExternalAuth{
+		Path:      fmt.Sprintf("/%vext_auth_%v_%v", internalLocationPrefix, polNamespace, polName),
+		ProxyPass: fmt.Sprintf("http://%v%v", upstreamName, generateString(extAuth.AuthPath, "/")),
+	}
+
+	for _, h := range extAuth.ForwardHeaders {
+		p.ExternalAuth.ForwardHeaders = append(p.ExternalAuth.ForwardHeaders, version2.Header{
+			Name:  h,
+			Value: "$http_" + generateHeaderVariableSuffix(h),
+		})
+	}
+
+	for _, h := range extAuth.ResponseHeaders {
+		p.ExternalAuth.ResponseHeaders = append(p.ExternalAuth.ResponseHeaders, version2.ExternalAuthResponseHeader{
+			Name:             h,
+			Variable:         "$ext_auth_" + generateHeaderVariableSuffix(h),
+			UpstreamVariable: "$upstream_http_" + generateHeaderVariableSuffix(h),
+		})
+	}
+
+	if extAuth.Cache != nil {
+		// the authorization service decides per method and URI, so the decisions are cached per method and URI too
+		p.ExternalAuth.Cache = &version2.ExternalAuthCache{
+			ZoneName: fmt.Sprintf("pol_ext_auth_%v_%v_%v_%v", polNamespace, polName, vsNamespace, vsName),
+			ZoneSize: generateString(extAuth.Cache.ZoneSize, "1m"),
+			Key:      generateString(extAuth.Cache.Key, "${http_authorization}${http_cookie}${request_method}${request_uri}"),
+			Valid:    extAuth.Cache.Valid,
+		}
+	}
+
+	return res
+}
+
+func (p *policiesCfg) addCacheConfig(
+	cache *conf_v1.Cache,
+	polKey string,
+	polNamespace string,
+	polName string,
+	vsNamespace string,
+	vsName string,
+) *validationResults {
+	res := newValidationResults()
+	if p.Cache != nil {
+		res.addWarningf("Multiple cache policies in the same context is not valid. Cache policy %s will be ignored", polKey)
+		return res
+	}
+
+	zoneName := fmt.Sprintf("pol_cache_%v_%v_%v_%v", polNamespace, polName, vsNamespace, vsName)
+
+	p.Cache = &version2.Cache{
+		ZoneName: zoneName,
+		Key:      cache.Key,
+		Bypass:   cache.Bypass,
+		NoCache:  cache.NoCache,
+		Methods:  cache.Methods,
+	}
+
+	for _, v := range cache.Valid {
+		var params []string
+		for _, code := range v.Codes {
+			params = append(params, strconv.Itoa(code))
+		}
+		params = append(params, v.Time)
+		p.Cache.Valid = append(p.Cache.Valid, strings.Join(params, " "))
+	}
+
+	zone := version2.CacheZone{
+		ZoneName: zoneName,
+		ZoneSize: cache.ZoneSize,
+		MaxSize:  cache.MaxSize,
+	}
+
+	if cache.Purge != nil {
+		purgeVariable := strings.NewReplacer("-", "_", ".", "_").Replace("$" + zoneName + "_purge")
+		zone.Purge = &version2.CachePurge{
+			Allow:           cache.Purge.Allow,
+			AllowedVariable: purgeVariable + "_allowed",
+			Variable:        purgeVariable,
+		}
+		p.Cache.PurgeVariable = purgeVariable
+	}
+
+	p.CacheZones = append(p.CacheZones, zone)
+
+	return res
+}
+
+func (p *policiesCfg) addResilienceConfig(resilience *conf_v1.Resilience, polKey string) *validationResults {
+	res := newValidationResults()
+	if p.Resilience != nil {
+		res.addWarningf("Multiple resilience policies in the same context is not valid. Resilience policy %s will be ignored", polKey)
+		return res
+	}
+
+	p.Resilience = resilience
+	return res
+}
+
+func (p *policiesCfg) addHeadersConfig(headers *conf_v1.Headers, polKey string) *validationResults {
+	res := newValidationResults()
+	if p.Headers != nil {
+		res.addWarningf("Multiple headers policies in the same context is not valid. Headers policy %s will be ignored", polKey)
+		return res
+	}
+
+	p.Headers = headers
+	return res
+}
+
+// addHeadersToLocation adds the headers of a headers policy to the headers of the location, which come from
+// the action of the location. The headers set or added by the action take precedence over the headers of the policy.
+func addHeadersToLocation(headers *conf_v1.Headers, location *version2.Location) {
+	setHeaders := make(map[string]bool)
+	for _, h := range location.ProxySetHeaders {
+		setHeaders[strings.ToLower(h.Name)] = true
+	}
+	for _, h := range generateHeadersPolicySetHeaders(headers) {
+		if !setHeaders[strings.ToLower(h.Name)] {
+			location.ProxySetHeaders = append(location.ProxySetHeaders, h)
+		}
+	}
+
+	addHeaders := make(map[string]bool)
+	for _, h := range location.AddHeaders {
+		addHeaders[strings.ToLower(h.Name)] = true
+	}
+	for _, h := range generateHeadersPolicyAddHeaders(headers) {
+		if !addHeaders[strings.ToLower(h.Name)] {
+			location.AddHeaders = append(location.AddHeaders, h)
+		}
+	}
+
+	if headers.Response == nil {
+		return
+	}
+
+	// the hidden headers of the location can come from the action, so they are copied before appending
+	hideHeaders := append([]string{}, location.ProxyHideHeaders...)
+	hideHeaders = append(hideHeaders, headers.Response.Remove...)
+	for _, r := range headers.Response.Rename {
+		hideHeaders = append(hideHeaders, r.From)
+	}
+	location.ProxyHideHeaders = hideHeaders
+
+	for _, r := range headers.Response.RewriteLocation {
+		location.ProxyRedirects = append(location.ProxyRedirects, version2.HeaderRewrite{From: r.From, To: r.To})
+	}
+	for _, r := range headers.Response.RewriteCookieDomain {
+		location.ProxyCookieDomains = append(location.ProxyCookieDomains, version2.HeaderRewrite{From: r.From, To: r.To})
+	}
+}
+
+// generateHeadersPolicySetHeaders generates the headers of the requests set by a headers policy.
+// A header is removed by setting it to an empty value.
+func generateHeadersPolicySetHeaders(headers *conf_v1.Headers) []version2.Header {
+	if headers.Request == nil {
+		return nil
+	}
+
+	var result []version2.Header
+	for _, h := range headers.Request.Set {
+		result = append(result, version2.Header{Name: h.Name, Value: h.Value})
+	}
+	for _, h := range headers.Request.Remove {
+		result = append(result, version2.Header{Name: h, Value: ""})
+	}
+	for _, r := range headers.Request.Rename {
+		result = append(result,
+			version2.Header{Name: r.To, Value: generateHeaderVariable("$http_", r.From)},
+			version2.Header{Name: r.From, Value: ""},
+		)
+	}
+
+	return result
+}
+
+// generateHeadersPolicyAddHeaders generates the headers added to the responses by a headers policy.
+func generateHeadersPolicyAddHeaders(headers *conf_v1.Headers) []version2.AddHeader {
+	var result []version2.AddHeader
+
+	if headers.Response != nil {
+		for _, h := range headers.Response.Add {
+			result = append(result, version2.AddHeader{
+				Header: version2.Header{Name: h.Name, Value: h.Value},
+				Always: h.Always,
+			})
+		}
+		for _, r := range headers.Response.Rename {
+			result = append(result, version2.AddHeader{
+				Header: version2.Header{Name: r.To, Value: generateHeaderVariable("$upstream_http_", r.From)},
+				Always: true,
+			})
+		}
+	}
+
+	if sh := headers.SecurityHeaders; sh != nil {
+		securityHeaders := []version2.Header{
+			{Name: "Content-Security-Policy", Value: sh.ContentSecurityPolicy},
+			{Name: "X-Frame-Options", Value: sh.FrameOptions},
+			{Name: "Referrer-Policy", Value: sh.ReferrerPolicy},
+		}
+		for _, h := range securityHeaders {
+			if h.Value != "" {
+				result = append(result, version2.AddHeader{Header: h, Always: true})
+			}
+		}
+	}
+
+	return result
+}
+
+// generateHeaderVariable generates the name of the NGINX variable of a header, like $http_x_user_id for X-User-ID.
+func generateHeaderVariable(prefix string, header string) string {
+	return prefix + strings.ReplaceAll(strings.ToLower(header), "-", "_")
+}
+
+// defaultCompressionTypes are the MIME types of the responses compressed by a compression policy without types.
+// The responses of the type text/html are always compressed.
+const defaultCompressionTypes = "text/plain text/css text/xml application/javascript application/json application/xml"
+
+func (p *policiesCfg) addCompressionConfig(compression *conf_v1.Compression, polKey string) *validationResults {
+	res := newValidationResults()
+	if p.Compression != nil {
+		res.addWarningf("Multiple compression policies in the same context is not valid. Compression policy %s will be ignored", polKey)
+		return res
+	}
+
+	cfg := &version2.Compression{
+		GzipLevel:   generateIntFromPointer(compression.GzipLevel, 1),
+		BrotliLevel: generateIntFromPointer(compression.BrotliLevel, 6),
+		MinLength:   generateIntFromPointer(compression.MinLength, 20),
+		Types:       defaultCompressionTypes,
+		Proxied:     compression.Proxied,
+	}
+	for _, a := range compression.Algorithms {
+		switch a {
+		case "gzip":
+			cfg.Gzip = true
+		case "brotli":
+			cfg.Brotli = true
+		}
+	}
+	if len(compression.Types) > 0 {
+		cfg.Types = strings.Join(compression.Types, " ")
+	}
+
+	p.Compression = cfg
+	return res
+}
+
+func (p *policiesCfg) addGeoAccessConfig(
+	geoAccess *conf_v1.GeoAccess,
+	polKey string,
+	polNamespace string,
+	polName string,
+	vsNamespace string,
+	vsName string,
+) *validationResults {
+	res := newValidationResults()
+	if p.GeoAccess != nil {
+		res.addWarningf("Multiple geoAccess policies in the same context is not valid. GeoAccess policy %s will be ignored", polKey)
+		return res
+	}
+
+	rules := geoAccess.Allow
+	if rules == nil {
+		rules = geoAccess.Deny
+	}
+
+	var source string
+
+	if len(rules.Countries) > 0 {
+		countryVariable := generateGeoAccessVariableName("country", polNamespace, polName, vsNamespace, vsName)
+		p.GeoIP2 = append(p.GeoIP2, version2.GeoIP2{
+			Database: geoAccess.CountryDatabase,
+			Variable: countryVariable,
+			Path:     "country iso_code",
+		})
+		p.Maps = append(p.Maps, generateGeoAccessMatchMap(countryVariable, rules.Countries))
+		source += countryVariable + "_match"
+	}
+
+	if len(rules.ASNs) > 0 {
+		asnVariable := generateGeoAccessVariableName("asn", polNamespace, polName, vsNamespace, vsName)
+		p.GeoIP2 = append(p.GeoIP2, version2.GeoIP2{
+			Database: geoAccess.ASNDatabase,
+			Variable: asnVariable,
+			Path:     "autonomous_system_number",
+		})
+		var asns []string
+		for _, asn := range rules.ASNs {
+			asns = append(asns, strconv.Itoa(asn))
+		}
+		p.Maps = append(p.Maps, generateGeoAccessMatchMap(asnVariable, asns))
+		source += asnVariable + "_match"
+	}
+
+	// The source of the map is empty when the address of the client matches none of the rules.
+	noMatchResult, defaultResult := "0", "1"
+	if geoAccess.Allow != nil {
+		noMatchResult, defaultResult = "1", "0"
+	}
+
+	deniedVariable := generateGeoAccessVariableName("denied", polNamespace, polName, vsNamespace, vsName)
+	p.Maps = append(p.Maps, version2.Map{
+		Source:   fmt.Sprintf(`"%s"`, source),
+		Variable: deniedVariable,
+		Parameters: []version2.Parameter{
+			{
+				Value:  `""`,
+				Result: noMatchResult,
+			},
+			{
+				Value:  "default",
+				Result: defaultResult,
+			},
+		},
+	})
+
+	p.GeoAccess = &version2.GeoAccess{
+		DeniedVariable: deniedVariable,
+		RejectCode:     generateIntFromPointer(geoAccess.RejectCode, 403),
+		DryRun:         generateBool(geoAccess.DryRun, false),
+	}
+
+	return res
+}
+
+func generateGeoAccessVariableName(name string, polNamespace string, polName string, vsNamespace string, vsName string) string {
+	variable := fmt.Sprintf("$pol_geo_%v_%v_%v_%v_%v", name, polNamespace, polName, vsNamespace, vsName)
+	return strings.NewReplacer("-", "_", ".", "_").Replace(variable)
+}
+
+// generateGeoAccessMatchMap generates the map that evaluates to 1 if the value of the GeoIP2 variable
+// is one of the values and to an empty string otherwise.
+func generateGeoAccessMatchMap(variable string, values []string) version2.Map {
+	var params []version2.Parameter
+	for _, v := range values {
+		params = append(params, version2.Parameter{
+			Value:  v,
+			Result: "1",
+		})
+	}
+	params = append(params, version2.Parameter{
+		Value:  "default",
+		Result: `""`,
+	})
+
+	return version2.Map{
+		Source:     variable,
+		Variable:   variable + "_match",
+		Parameters: params,
+	}
+}
+
+// addRetryToLocation overrides the retries of the location, which come from the upstream of the location,
+// with the retry of a resilience policy.
+func addRetryToLocation(retry *conf_v1.Retry, location *version2.Location) {
+	conditions := retry.Conditions
+	if len(conditions) == 0 {
+		conditions = []string{"error", "timeout"}
+	}
+	location.ProxyNextUpstream = strings.Join(conditions, " ")
+	if retry.NonIdempotent {
+		location.ProxyNextUpstream += " non_idempotent"
+	}
+
+	if retry.MaxAttempts != nil {
+		location.ProxyNextUpstreamTries = *retry.MaxAttempts
+	}
+	if retry.Timeout != "" {
+		location.ProxyNextUpstreamTimeout = generateTime(retry.Timeout)
+	}
+	if retry.PerTryTimeout != "" {
+		location.ProxyReadTimeout = generateTime(retry.PerTryTimeout)
+		location.ProxySendTimeout = generateTime(retry.PerTryTimeout)
+	}
+}
+
+// addCircuitBreaker stores the circuit breaker of the resilience policy of the route for the upstreams of the route.
+// An upstream can have only one circuit breaker, so the circuit breaker of the first route of the upstream is used.
+func (vsc *virtualServerConfigurator) addCircuitBreaker(
+	owner runtime.Object,
+	circuitBreakers map[string]*conf_v1.CircuitBreaker,
+	resilience *conf_v1.Resilience,
+	route conf_v1.Route,
+	namer *upstreamNamer,
+) {
+	if resilience == nil || resilience.CircuitBreaker == nil {
+		return
+	}
+
+	for _, name := range getUpstreamNamesForRoute(route, namer) {
+		existing, exists := circuitBreakers[name]
+		if !exists {
+			circuitBreakers[name] = resilience.CircuitBreaker
+			continue
+		}
+		if !reflect.DeepEqual(existing, resilience.CircuitBreaker) {
+			vsc.addWarningf(owner, "The circuit breaker of the resilience policy of route %s is ignored for upstream %s, "+
+				"because the upstream already uses the circuit breaker of another route", route.Path, name)
+		}
+	}
+}
+
+// getActionsForRoute returns the actions of the route, its splits and its matches.
+func getActionsForRoute(route conf_v1.Route) []*conf_v1.Action {
+	var actions []*conf_v1.Action
+
+	actions = append(actions, route.Action)
+	for _, s := range route.Splits {
+		actions = append(actions, s.Action)
+	}
+	for _, m := range route.Matches {
+		actions = append(actions, m.Action)
+		for _, s := range m.Splits {
+			actions = append(actions, s.Action)
+		}
+	}
+
+	return actions
+}
+
+// getUpstreamNamesForRoute returns the names of the upstreams the route passes the requests to.
+func getUpstreamNamesForRoute(route conf_v1.Route, namer *upstreamNamer) []string {
+	var names []string
+	for _, a := range getActionsForRoute(route) {
+		if a == nil || (a.Pass == "" && a.Proxy == nil) {
+			continue
+		}
+		names = append(names, namer.GetNameForUpstreamFromAction(a))
+	}
+
+	return names
+}
+
+// checkHeadersPolicy reports the headers of the headers policy of the route that are ignored,
+// because the actions of the route set the same headers.
+func (vsc *virtualServerConfigurator) checkHeadersPolicy(owner runtime.Object, headers *conf_v1.Headers, route conf_v1.Route) {
+	if headers == nil {
+		return
+	}
+
+	requestHeaders := make(map[string]bool)
+	responseHeaders := make(map[string]bool)
+	for _, a := range getActionsForRoute(route) {
+		if a == nil || a.Proxy == nil {
+			continue
+		}
+		for _, h := range generateProxySetHeaders(a.Proxy) {
+			requestHeaders[strings.ToLower(h.Name)] = true
+		}
+		for _, h := range generateProxyAddHeaders(a.Proxy) {
+			responseHeaders[strings.ToLower(h.Name)] = true
 		}
+	}
 
-		clientSecret := secretRef.Secret.Data[ClientSecretKey]
+	for _, h := range generateHeadersPolicySetHeaders(headers) {
+		if requestHeaders[strings.ToLower(h.Name)] {
+			vsc.addWarningf(owner, "The request header %s of the headers policy of route %s is ignored, "+
+				"because the action of the route sets the header", h.Name, route.Path)
+		}
+	}
 
-		redirectURI := oidc.RedirectURI
-		if redirectURI == "" {
-			redirectURI = "/_codexch"
+	for _, h := range generateHeadersPolicyAddHeaders(headers) {
+		if responseHeaders[strings.ToLower(h.Name)] {
+			vsc.addWarningf(owner, "The response header %s of the headers policy of route %s is ignored, "+
+				"because the action of the route adds the header", h.Name, route.Path)
 		}
-		scope := oidc.Scope
-		if scope == "" {
-			scope = "openid"
+	}
+}
+
+// applyCircuitBreakers overrides the parameters of the servers of the upstreams with the circuit breakers.
+func (vsc *virtualServerConfigurator) applyCircuitBreakers(
+	owner runtime.Object,
+	upstreams []version2.Upstream,
+	circuitBreakers map[string]*conf_v1.CircuitBreaker,
+) {
+	for i := range upstreams {
+		cb, exists := circuitBreakers[upstreams[i].Name]
+		if !exists {
+			continue
 		}
 
-		oidcPolCfg.oidc = &version2.OIDC{
-			AuthEndpoint:   oidc.AuthEndpoint,
-			TokenEndpoint:  oidc.TokenEndpoint,
-			JwksURI:        oidc.JWKSURI,
-			ClientID:       oidc.ClientID,
-			ClientSecret:   string(clientSecret),
-			Scope:          scope,
-			RedirectURI:    redirectURI,
-			ZoneSyncLeeway: generateIntFromPointer(oidc.ZoneSyncLeeway, 200),
+		if cb.MaxFails != nil {
+			upstreams[i].MaxFails = *cb.MaxFails
+		}
+		if cb.FailTimeout != "" {
+			upstreams[i].FailTimeout = generateTime(cb.FailTimeout)
+		}
+		if vsc.isPlus && cb.SlowStart != "" {
+			upstreams[i].SlowStart = vsc.generateSlowStartForPlus(owner, conf_v1.Upstream{
+				Name:      upstreams[i].Name,
+				SlowStart: cb.SlowStart,
+			}, upstreams[i].LBMethod)
 		}
-		oidcPolCfg.key = polKey
 	}
+}
 
-	p.OIDC = true
+// generateHeaderVariableSuffix converts the name of a header into the suffix of the NGINX variables of the header
+// like $http_ or $upstream_http_.
+func generateHeaderVariableSuffix(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "-", "_"))
+}
 
-	return res
+func generateExternalAuthUpstreamName(vsNamespace string, vsName string, polNamespace string, polName string) string {
+	return fmt.Sprintf("vs_%v_%v_ext_auth_%v_%v", vsNamespace, vsName, polNamespace, polName)
 }
 
-func (p *policiesCfg) addWAFConfig(
-	waf *conf_v1.WAF,
-	polKey string,
-	polNamespace string,
-	apResources *appProtectResourcesForVS,
-) *validationResults {
-	res := newValidationResults()
-	if p.WAF != nil {
-		res.addWarningf("Multiple WAF policies in the same context is not valid. WAF policy %s will be ignored", polKey)
-		return res
+// generateExternalAuthUpstream generates the upstream for the external authorization service of the policy.
+func generateExternalAuthUpstream(pol *conf_v1.Policy) conf_v1.Upstream {
+	return conf_v1.Upstream{
+		Name:    fmt.Sprintf("ext-auth-%v", pol.Name),
+		Service: pol.Spec.ExternalAuth.AuthService,
+		Port:    uint16(pol.Spec.ExternalAuth.AuthServicePort),
 	}
+}
 
-	if waf.Enable {
-		p.WAF = &version2.WAF{Enable: "on"}
-	} else {
-		p.WAF = &version2.WAF{Enable: "off"}
-	}
+// generateExternalAuthUpstreams generates the upstreams for the external authorization services of the externalAuth
+// policies referenced by the VirtualServer and its VirtualServerRoutes.
+func (vsc *virtualServerConfigurator) generateExternalAuthUpstreams(vsEx *VirtualServerEx) []version2.Upstream {
+	var upstreams []version2.Upstream
 
-	if waf.ApPolicy != "" {
-		apPolKey := waf.ApPolicy
-		hasNamespace := strings.Contains(apPolKey, "/")
-		if !hasNamespace {
-			apPolKey = fmt.Sprintf("%v/%v", polNamespace, apPolKey)
+	for _, key := range getSortedPolicyKeys(vsEx.Policies) {
+		pol := vsEx.Policies[key]
+		if pol.Spec.ExternalAuth == nil {
+			continue
 		}
 
-		if apPolPath, exists := apResources.Policies[apPolKey]; exists {
-			p.WAF.ApPolicy = apPolPath
-		} else {
-			res.addWarningf("WAF policy %s references an invalid or non-existing App Protect policy %s", polKey, apPolKey)
-			res.isError = true
-			return res
-		}
+		u := generateExternalAuthUpstream(pol)
+		upstreamName := generateExternalAuthUpstreamName(vsEx.VirtualServer.Namespace, vsEx.VirtualServer.Name, pol.Namespace, pol.Name)
+		endpoints := vsc.generateEndpointsForUpstream(vsEx.VirtualServer, pol.Namespace, u, vsEx)
+
+		// isExternalNameSvc is always false for OSS
+		_, isExternalNameSvc := vsEx.ExternalNameSvcs[GenerateExternalNameSvcKey(pol.Namespace, u.Service)]
+		upstreams = append(upstreams, vsc.generateUpstream(vsEx.VirtualServer, upstreamName, u, isExternalNameSvc, endpoints))
 	}
 
-	if waf.SecurityLog != nil && waf.SecurityLogs == nil {
-		glog.V(2).Info("the field securityLog is deprecated nad will be removed in future releases. Use field securityLogs instead")
-		p.WAF.ApSecurityLogEnable = true
+	return upstreams
+}
 
-		logConfKey := waf.SecurityLog.ApLogConf
-		hasNamespace := strings.Contains(logConfKey, "/")
-		if !hasNamespace {
-			logConfKey = fmt.Sprintf("%v/%v", polNamespace, logConfKey)
-		}
+func getSortedPolicyKeys(policies map[string]*conf_v1.Policy) []string {
+	var keys []string
 
-		if logConfPath, ok := apResources.LogConfs[logConfKey]; ok {
-			logDest := generateString(waf.SecurityLog.LogDest, "syslog:server=localhost:514")
-			p.WAF.ApLogConf = []string{fmt.Sprintf("%s %s", logConfPath, logDest)}
-		} else {
-			res.addWarningf("WAF policy %s references an invalid or non-existing log config %s", polKey, logConfKey)
-			res.isError = true
+	for key := range policies {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// generateJwksURILocations generates an internal location for every JWT policy with a JWKS URI of the server
+// and the locations. The JWT policies fetch their keys from the remote URI through these locations.
+func generateJwksURILocations(serverJWTAuth *version2.JWTAuth, locations []version2.Location) []version2.JwksURI {
+	var jwksURILocations []version2.JwksURI
+	encountered := make(map[string]bool)
+
+	jwtAuths := []*version2.JWTAuth{serverJWTAuth}
+	for _, l := range locations {
+		jwtAuths = append(jwtAuths, l.JWTAuth)
+	}
+
+	for _, jwtAuth := range jwtAuths {
+		if jwtAuth == nil || jwtAuth.JwksURI == nil || encountered[jwtAuth.JwksURI.Path] {
+			continue
 		}
+		encountered[jwtAuth.JwksURI.Path] = true
+
+		jwksURILocations = append(jwksURILocations, *jwtAuth.JwksURI)
 	}
 
-	if waf.SecurityLogs != nil {
-		p.WAF.ApSecurityLogEnable = true
-		p.WAF.ApLogConf = []string{}
-		for _, loco := range waf.SecurityLogs {
-			logConfKey := loco.ApLogConf
-			hasNamepace := strings.Contains(logConfKey, "/")
-			if !hasNamepace {
-				logConfKey = fmt.Sprintf("%v/%v", polNamespace, logConfKey)
-			}
-			if logConfPath, ok := apResources.LogConfs[logConfKey]; ok {
-				logDest := generateString(loco.LogDest, "syslog:server=localhost:514")
-				p.WAF.ApLogConf = append(p.WAF.ApLogConf, fmt.Sprintf("%s %s", logConfPath, logDest))
-			} else {
-				res.addWarningf("WAF policy %s references an invalid or non-existing log config %s", polKey, logConfKey)
-				res.isError = true
-			}
+	return jwksURILocations
+}
+
+// generateExternalAuthLocations generates an internal location for every externalAuth policy of the locations
+// and the caches of the policies.
+func generateExternalAuthLocations(locations []version2.Location) ([]version2.ExternalAuth, []version2.ExternalAuthCache) {
+	var externalAuthLocations []version2.ExternalAuth
+	var caches []version2.ExternalAuthCache
+	encountered := make(map[string]bool)
+
+	for _, l := range locations {
+		if l.ExternalAuth == nil || encountered[l.ExternalAuth.Path] {
+			continue
+		}
+		encountered[l.ExternalAuth.Path] = true
+
+		externalAuthLocations = append(externalAuthLocations, *l.ExternalAuth)
+		if l.ExternalAuth.Cache != nil {
+			caches = append(caches, *l.ExternalAuth.Cache)
 		}
 	}
-	return res
+
+	return externalAuthLocations, caches
 }
 
 func (vsc *virtualServerConfigurator) generatePolicies(
@@ -1151,7 +2152,15 @@ func (vsc *virtualServerConfigurator) generatePolicies(
 					ownerDetails.vsName,
 				)
 			case pol.Spec.JWTAuth != nil:
-				res = config.addJWTAuthConfig(pol.Spec.JWTAuth, key, polNamespace, policyOpts.secretRefs)
+				res = config.addJWTAuthConfig(
+					pol.Spec.JWTAuth,
+					key,
+					polNamespace,
+					p.Name,
+					ownerDetails.vsNamespace,
+					ownerDetails.vsName,
+					policyOpts.secretRefs,
+				)
 			case pol.Spec.BasicAuth != nil:
 				res = config.addBasicAuthConfig(pol.Spec.BasicAuth, key, polNamespace, policyOpts.secretRefs)
 			case pol.Spec.IngressMTLS != nil:
@@ -1169,6 +2178,61 @@ func (vsc *virtualServerConfigurator) generatePolicies(
 				res = config.addOIDCConfig(pol.Spec.OIDC, key, polNamespace, policyOpts.secretRefs, vsc.oidcPolCfg)
 			case pol.Spec.WAF != nil:
 				res = config.addWAFConfig(pol.Spec.WAF, key, polNamespace, policyOpts.apResources)
+			case pol.Spec.CORS != nil:
+				res = config.addCORSConfig(
+					pol.Spec.CORS,
+					key,
+					polNamespace,
+					p.Name,
+					ownerDetails.vsNamespace,
+					ownerDetails.vsName,
+				)
+			case pol.Spec.ExternalAuth != nil:
+				res = config.addExternalAuthConfig(
+					pol.Spec.ExternalAuth,
+					key,
+					polNamespace,
+					p.Name,
+					ownerDetails.vsNamespace,
+					ownerDetails.vsName,
+				)
+			case pol.Spec.APIKey != nil:
+				res = config.addAPIKeyConfig(
+					pol.Spec.APIKey,
+					key,
+					polNamespace,
+					p.Name,
+					ownerDetails.vsNamespace,
+					ownerDetails.vsName,
+					policyOpts.secretRefs,
+				)
+			case pol.Spec.Cache != nil:
+				res = config.addCacheConfig(
+					pol.Spec.Cache,
+					key,
+					polNamespace,
+					p.Name,
+					ownerDetails.vsNamespace,
+					ownerDetails.vsName,
+				)
+			case pol.Spec.Resilience != nil:
+				res = config.addResilienceConfig(pol.Spec.Resilience, key)
+			case pol.Spec.Headers != nil:
+				res = config.addHeadersConfig(pol.Spec.Headers, key)
+			case pol.Spec.Compression != nil:
+				res = config.addCompressionConfig(pol.Spec.Compression, key)
+			case pol.Spec.GeoAccess != nil:
+				res = config.addGeoAccessConfig(
+					pol.Spec.GeoAccess,
+					key,
+					polNamespace,
+					p.Name,
+					ownerDetails.vsNamespace,
+					ownerDetails.vsName,
+				)
+			case pol.Spec.ConnectionLimit != nil, pol.Spec.Bandwidth != nil:
+				res = newValidationResults()
+				res.addWarningf("Policy %s is supported only in TransportServers and will be ignored", key)
 			default:
 				res = newValidationResults()
 			}
@@ -1240,6 +2304,48 @@ func removeDuplicateLimitReqZones(rlz []version2.LimitReqZone) []version2.LimitR
 	return result
 }
 
+func removeDuplicateCacheZones(zones []version2.CacheZone) []version2.CacheZone {
+	encountered := make(map[string]bool)
+	var result []version2.CacheZone
+
+	for _, z := range zones {
+		if !encountered[z.ZoneName] {
+			encountered[z.ZoneName] = true
+			result = append(result, z)
+		}
+	}
+
+	return result
+}
+
+func removeDuplicateGeoIP2(geoIP2 []version2.GeoIP2) []version2.GeoIP2 {
+	encountered := make(map[string]bool)
+	var result []version2.GeoIP2
+
+	for _, g := range geoIP2 {
+		if !encountered[g.Variable] {
+			encountered[g.Variable] = true
+			result = append(result, g)
+		}
+	}
+
+	return result
+}
+
+func removeDuplicateMaps(maps []version2.Map) []version2.Map {
+	encountered := make(map[string]bool)
+	var result []version2.Map
+
+	for _, m := range maps {
+		if !encountered[m.Variable] {
+			encountered[m.Variable] = true
+			result = append(result, m)
+		}
+	}
+
+	return result
+}
+
 func addPoliciesCfgToLocation(cfg policiesCfg, location *version2.Location) {
 	location.Allow = cfg.Allow
 	location.Deny = cfg.Deny
@@ -1250,6 +2356,18 @@ func addPoliciesCfgToLocation(cfg policiesCfg, location *version2.Location) {
 	location.EgressMTLS = cfg.EgressMTLS
 	location.OIDC = cfg.OIDC
 	location.WAF = cfg.WAF
+	location.CORS = cfg.CORS
+	location.ExternalAuth = cfg.ExternalAuth
+	location.APIKey = cfg.APIKey
+	location.Cache = cfg.Cache
+	location.GeoAccess = cfg.GeoAccess
+	location.Compression = cfg.Compression
+	if cfg.Headers != nil {
+		addHeadersToLocation(cfg.Headers, location)
+	}
+	if cfg.Resilience != nil && cfg.Resilience.Retry != nil {
+		addRetryToLocation(cfg.Resilience.Retry, location)
+	}
 	location.PoliciesErrorReturn = cfg.ErrorReturn
 }
 
@@ -1773,6 +2891,71 @@ func generateLocationForProxying(path string, upstreamName string, upstream conf
 	}
 }
 
+// generateMirror generates the mirroring of the requests of the action. The path and the sample variable of the mirror
+// are set by generateMirrorLocations once all the locations are generated.
+func generateMirror(action *conf_v1.Action, upstreamNamer *upstreamNamer, crUpstreams map[string]conf_v1.Upstream) *version2.Mirror {
+	if action == nil || action.Proxy == nil || action.Proxy.Mirror == nil {
+		return nil
+	}
+
+	mirror := action.Proxy.Mirror
+	upstreamName := upstreamNamer.GetNameForUpstream(mirror.Upstream)
+	upstream := crUpstreams[upstreamName]
+
+	percentage := 100
+	if mirror.Percentage != nil {
+		percentage = *mirror.Percentage
+	}
+
+	return &version2.Mirror{
+		ProxyPass:    fmt.Sprintf("%v://%v", generateProxyPassProtocol(upstream.TLS.Enable), upstreamName),
+		RequestBody:  generateBool(mirror.RequestBody, true),
+		Percentage:   percentage,
+		ProxySSLName: generateProxySSLName(upstream.Service, upstreamNamer.namespace),
+	}
+}
+
+// generateMirrorLocations generates an internal location for every location that mirrors its requests.
+// When only a part of the requests is mirrored, the split clients choose the mirrored requests.
+// The mirror locations inherit the egress TLS settings of their locations.
+func generateMirrorLocations(locations []version2.Location, variableNamer *variableNamer) ([]version2.Mirror, []version2.SplitClient) {
+	var mirrorLocations []version2.Mirror
+	var splitClients []version2.SplitClient
+
+	for i := range locations {
+		mirror := locations[i].Mirror
+		if mirror == nil {
+			continue
+		}
+
+		index := len(mirrorLocations)
+		mirror.Path = fmt.Sprintf("/%vmirror_%d", internalLocationPrefix, index)
+		mirror.EgressMTLS = locations[i].EgressMTLS
+
+		if mirror.Percentage < 100 {
+			mirror.SampleVariable = variableNamer.GetNameForMirrorVariable(index)
+			splitClients = append(splitClients, version2.SplitClient{
+				Source:   "$request_id",
+				Variable: mirror.SampleVariable,
+				Distributions: []version2.Distribution{
+					{
+						Weight: fmt.Sprintf("%d%%", mirror.Percentage),
+						Value:  "1",
+					},
+					{
+						Weight: "*",
+						Value:  `""`,
+					},
+				},
+			})
+		}
+
+		mirrorLocations = append(mirrorLocations, *mirror)
+	}
+
+	return mirrorLocations, splitClients
+}
+
 func generateProxyInterceptErrors(errorPages []conf_v1.ErrorPage) bool {
 	return len(errorPages) > 0
 }
@@ -1890,6 +3073,7 @@ func generateSplits(
 		newRetLocIndex := retLocIndex + len(returnLocations)
 		loc, returnLoc := generateLocation(path, upstreamName, upstream, s.Action, cfgParams, errorPages, true,
 			proxySSLName, originalPath, locSnippets, enableSnippets, newRetLocIndex, isVSR, vsrName, vsrNamespace, vscWarnings)
+		loc.Mirror = generateMirror(s.Action, upstreamNamer, crUpstreams)
 		locations = append(locations, loc)
 		if returnLoc != nil {
 			returnLocations = append(returnLocations, *returnLoc)
@@ -1934,6 +3118,111 @@ func generateDefaultSplitsConfig(
 	}
 }
 
+const (
+	canaryAlways = "always"
+	canaryNever  = "never"
+)
+
+// generateCanaryRoute converts the canary of the route into matches and splits, so that the canary is generated
+// with the maps and the split clients of the matches and the splits.
+// The header takes precedence over the cookie, which takes precedence over the weight.
+func generateCanaryRoute(route conf_v1.Route) conf_v1.Route {
+	canary := route.Canary
+	stableAction := route.Action
+
+	canaryRoute := route
+	canaryRoute.Canary = nil
+	canaryRoute.Matches = nil
+
+	var conditions []conf_v1.Condition
+	if canary.Header != "" {
+		conditions = append(conditions,
+			conf_v1.Condition{Header: canary.Header, Value: canaryAlways},
+			conf_v1.Condition{Header: canary.Header, Value: canaryNever})
+	}
+	if canary.Cookie != "" {
+		conditions = append(conditions,
+			conf_v1.Condition{Cookie: canary.Cookie, Value: canaryAlways},
+			conf_v1.Condition{Cookie: canary.Cookie, Value: canaryNever})
+	}
+
+	for _, c := range conditions {
+		action := canary.Action
+		if c.Value == canaryNever {
+			action = stableAction
+		}
+		canaryRoute.Matches = append(canaryRoute.Matches, conf_v1.Match{
+			Conditions: []conf_v1.Condition{c},
+			Action:     action,
+		})
+	}
+
+	switch canary.Weight {
+	case 0:
+		canaryRoute.Action = stableAction
+	case 100:
+		canaryRoute.Action = canary.Action
+	default:
+		canaryRoute.Action = nil
+		canaryRoute.Splits = []conf_v1.Split{
+			{
+				Weight: canary.Weight,
+				Action: canary.Action,
+			},
+			{
+				Weight: 100 - canary.Weight,
+				Action: stableAction,
+			},
+		}
+	}
+
+	return canaryRoute
+}
+
+// applySplitWeight sets the weights of the two splits of the route according to the weight of the upstream.
+// The weights of 0 and 100 turn the route into an action, because the splits only support the weights from 1 to 99.
+func applySplitWeight(route conf_v1.Route, splitWeight SplitWeight) conf_v1.Route {
+	if len(route.Splits) != 2 {
+		return route
+	}
+
+	shifted := -1
+	for i, s := range route.Splits {
+		if s.Action != nil && getUpstreamFromAction(s.Action) == splitWeight.Upstream {
+			shifted = i
+			break
+		}
+	}
+	if shifted == -1 {
+		return route
+	}
+	other := 1 - shifted
+
+	switch splitWeight.Weight {
+	case 0:
+		route.Action = route.Splits[other].Action
+		route.Splits = nil
+	case 100:
+		route.Action = route.Splits[shifted].Action
+		route.Splits = nil
+	default:
+		splits := make([]conf_v1.Split, len(route.Splits))
+		copy(splits, route.Splits)
+		splits[shifted].Weight = splitWeight.Weight
+		splits[other].Weight = 100 - splitWeight.Weight
+		route.Splits = splits
+	}
+
+	return route
+}
+
+func getUpstreamFromAction(action *conf_v1.Action) string {
+	if action.Proxy != nil && action.Proxy.Upstream != "" {
+		return action.Proxy.Upstream
+	}
+	return action.Pass
+}
+
 func generateMatchesConfig(route conf_v1.Route, upstreamNamer *upstreamNamer, crUpstreams map[string]conf_v1.Upstream,
 	variableNamer *variableNamer, index int, scIndex int, cfgParams *ConfigParams, errorPages errorPageDetails,
 	locSnippets string, enableSnippets bool, retLocIndex int, isVSR bool, vsrName string, vsrNamespace string, vscWarnings Warnings,
@@ -2041,6 +3330,7 @@ func generateMatchesConfig(route conf_v1.Route, upstreamNamer *upstreamNamer, cr
 			newRetLocIndex := retLocIndex + len(returnLocations)
 			loc, returnLoc := generateLocation(path, upstreamName, upstream, m.Action, cfgParams, errorPages, true,
 				proxySSLName, route.Path, locSnippets, enableSnippets, newRetLocIndex, isVSR, vsrName, vsrNamespace, vscWarnings)
+			loc.Mirror = generateMirror(m.Action, upstreamNamer, crUpstreams)
 			locations = append(locations, loc)
 			if returnLoc != nil {
 				returnLocations = append(returnLocations, *returnLoc)
@@ -2079,6 +3369,7 @@ func generateMatchesConfig(route conf_v1.Route, upstreamNamer *upstreamNamer, cr
 		newRetLocIndex := retLocIndex + len(returnLocations)
 		loc, returnLoc := generateLocation(path, upstreamName, upstream, route.Action, cfgParams, errorPages, true,
 			proxySSLName, route.Path, locSnippets, enableSnippets, newRetLocIndex, isVSR, vsrName, vsrNamespace, vscWarnings)
+		loc.Mirror = generateMirror(route.Action, upstreamNamer, crUpstreams)
 		locations = append(locations, loc)
 		if returnLoc != nil {
 			returnLocations = append(returnLocations, *returnLoc)
@@ -2241,8 +3532,11 @@ func createEndpointsFromUpstream(upstream version2.Upstream) []string {
 	return endpoints
 }
 
+// createUpstreamsForPlus creates the upstreams of the VirtualServer for the update through the NGINX Plus API.
+// The circuit breakers are the ones recorded by the generation of the config of the VirtualServer.
 func createUpstreamsForPlus(
 	virtualServerEx *VirtualServerEx,
+	circuitBreakers map[string]*conf_v1.CircuitBreaker,
 	baseCfgParams *ConfigParams,
 	staticParams *StaticConfigParams,
 ) []version2.Upstream {
@@ -2294,6 +3588,30 @@ func createUpstreamsForPlus(
 		}
 	}
 
+	for _, key := range getSortedPolicyKeys(virtualServerEx.Policies) {
+		pol := virtualServerEx.Policies[key]
+		if pol.Spec.ExternalAuth == nil {
+			continue
+		}
+
+		u := generateExternalAuthUpstream(pol)
+		isExternalNameSvc := virtualServerEx.ExternalNameSvcs[GenerateExternalNameSvcKey(pol.Namespace, u.Service)]
+		if isExternalNameSvc {
+			glog.V(3).Infof("Service %s is Type ExternalName, skipping NGINX Plus endpoints update via API", u.Service)
+			continue
+		}
+
+		upstreamName := generateExternalAuthUpstreamName(virtualServerEx.VirtualServer.Namespace, virtualServerEx.VirtualServer.Name, pol.Namespace, pol.Name)
+
+		endpointsKey := GenerateEndpointsKey(pol.Namespace, u.Service, u.Subselector, u.Port)
+		endpoints := virtualServerEx.Endpoints[endpointsKey]
+
+		ups := vsc.generateUpstream(virtualServerEx.VirtualServer, upstreamName, u, isExternalNameSvc, endpoints)
+		upstreams = append(upstreams, ups)
+	}
+
+	vsc.applyCircuitBreakers(virtualServerEx.VirtualServer, upstreams, circuitBreakers)
+
 	return upstreams
 }
 
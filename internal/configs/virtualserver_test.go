@@ -2598,6 +2598,12 @@ func TestGeneratePolicies(t *testing.T) {
 					},
 				},
 			},
+			"default/api-key-secret": {
+				Secret: &api_v1.Secret{
+					Type: secrets.SecretTypeAPIKey,
+				},
+				Path: "/etc/nginx/secrets/default-api-key-secret",
+			},
 		},
 		apResources: &appProtectResourcesForVS{
 			Policies: map[string]string{
@@ -2789,6 +2795,116 @@ func TestGeneratePolicies(t *testing.T) {
 			},
 			msg: "multi rate limit reference",
 		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "rate-limit-tiers",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/rate-limit-tiers": {
+					Spec: conf_v1.PolicySpec{
+						RateLimit: &conf_v1.RateLimit{
+							Key:      "${jwt_claim_sub}",
+							ZoneSize: "10M",
+							Burst:    createPointerFromInt(5),
+							Tiers: []conf_v1.RateLimitTier{
+								{
+									Name: "gold",
+									Condition: &conf_v1.RateLimitCondition{
+										JWTClaim: "plan",
+										Value:    "gold",
+									},
+									Rate:     "100r/s",
+									Burst:    createPointerFromInt(50),
+									ZoneSize: "20M",
+								},
+								{
+									Name: "silver",
+									Condition: &conf_v1.RateLimitCondition{
+										Header: "X-Plan",
+										Value:  "silver",
+									},
+									Rate: "10r/s",
+								},
+								{
+									Name: "free",
+									Rate: "1r/s",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: policiesCfg{
+				LimitReqZones: []version2.LimitReqZone{
+					{
+						Key:      "$pol_rl_default_rate_limit_tiers_default_test_gold_key",
+						ZoneSize: "20M",
+						Rate:     "100r/s",
+						ZoneName: "pol_rl_default_rate-limit-tiers_default_test_gold",
+					},
+					{
+						Key:      "$pol_rl_default_rate_limit_tiers_default_test_silver_key",
+						ZoneSize: "10M",
+						Rate:     "10r/s",
+						ZoneName: "pol_rl_default_rate-limit-tiers_default_test_silver",
+					},
+					{
+						Key:      "$pol_rl_default_rate_limit_tiers_default_test_free_key",
+						ZoneSize: "10M",
+						Rate:     "1r/s",
+						ZoneName: "pol_rl_default_rate-limit-tiers_default_test_free",
+					},
+				},
+				LimitReqOptions: version2.LimitReqOptions{
+					LogLevel:   "error",
+					RejectCode: 503,
+				},
+				LimitReqs: []version2.LimitReq{
+					{
+						ZoneName: "pol_rl_default_rate-limit-tiers_default_test_gold",
+						Burst:    50,
+					},
+					{
+						ZoneName: "pol_rl_default_rate-limit-tiers_default_test_silver",
+						Burst:    5,
+					},
+					{
+						ZoneName: "pol_rl_default_rate-limit-tiers_default_test_free",
+						Burst:    5,
+					},
+				},
+				Maps: []version2.Map{
+					{
+						Source:   "$jwt_claim_plan",
+						Variable: "$pol_rl_default_rate_limit_tiers_default_test_gold_key",
+						Parameters: []version2.Parameter{
+							{Value: `"gold"`, Result: `"${jwt_claim_sub}"`},
+							{Value: "default", Result: `""`},
+						},
+					},
+					{
+						Source:   "$http_x_plan",
+						Variable: "$pol_rl_default_rate_limit_tiers_default_test_silver_key",
+						Parameters: []version2.Parameter{
+							{Value: `"silver"`, Result: `"${jwt_claim_sub}"`},
+							{Value: "default", Result: `""`},
+						},
+					},
+					{
+						Source:   `"$pol_rl_default_rate_limit_tiers_default_test_gold_key$pol_rl_default_rate_limit_tiers_default_test_silver_key"`,
+						Variable: "$pol_rl_default_rate_limit_tiers_default_test_free_key",
+						Parameters: []version2.Parameter{
+							{Value: `""`, Result: `"${jwt_claim_sub}"`},
+							{Value: "default", Result: `""`},
+						},
+					},
+				},
+			},
+			msg: "rate limit tiers reference",
+		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
@@ -2818,6 +2934,41 @@ func TestGeneratePolicies(t *testing.T) {
 			},
 			msg: "jwt reference",
 		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "jwt-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/jwt-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "jwt-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						JWTAuth: &conf_v1.JWTAuth{
+							Realm:    "My Test API",
+							JwksURI:  "https://idp.example.com/.well-known/jwks.json",
+							KeyCache: "1h",
+						},
+					},
+				},
+			},
+			expected: policiesCfg{
+				JWTAuth: &version2.JWTAuth{
+					Realm: "My Test API",
+					JwksURI: &version2.JwksURI{
+						Path:     "/_jwks_uri_default_jwt-policy",
+						URI:      "https://idp.example.com/.well-known/jwks.json",
+						KeyCache: "1h",
+						ZoneName: "jwks_uri_default_jwt-policy_default_test",
+					},
+				},
+			},
+			msg: "jwt reference with jwksURI",
+		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
@@ -2979,515 +3130,573 @@ func TestGeneratePolicies(t *testing.T) {
 			},
 			msg: "WAF reference",
 		},
-	}
-
-	vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{}, false)
-
-	for _, test := range tests {
-		result := vsc.generatePolicies(ownerDetails, test.policyRefs, test.policies, test.context, policyOpts)
-		if diff := cmp.Diff(test.expected, result); diff != "" {
-			t.Errorf("generatePolicies() '%v' mismatch (-want +got):\n%s", test.msg, diff)
-		}
-		if len(vsc.warnings) > 0 {
-			t.Errorf("generatePolicies() returned unexpected warnings %v for the case of %s", vsc.warnings, test.msg)
-		}
-	}
-}
-
-func TestGeneratePoliciesFails(t *testing.T) {
-	t.Parallel()
-	ownerDetails := policyOwnerDetails{
-		owner:          nil, // nil is OK for the unit test
-		ownerNamespace: "default",
-		vsNamespace:    "default",
-		vsName:         "test",
-	}
-
-	dryRunOverride := true
-	rejectCodeOverride := 505
-
-	tests := []struct {
-		policyRefs        []conf_v1.PolicyReference
-		policies          map[string]*conf_v1.Policy
-		policyOpts        policyOptions
-		trustedCAFileName string
-		context           string
-		oidcPolCfg        *oidcPolicyCfg
-		expected          policiesCfg
-		expectedWarnings  Warnings
-		expectedOidc      *oidcPolicyCfg
-		msg               string
-	}{
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "allow-policy",
+					Name:      "cors-policy",
 					Namespace: "default",
 				},
 			},
-			policies:   map[string]*conf_v1.Policy{},
-			policyOpts: policyOptions{},
-			expected: policiesCfg{
-				ErrorReturn: &version2.Return{
-					Code: 500,
+			policies: map[string]*conf_v1.Policy{
+				"default/cors-policy": {
+					Spec: conf_v1.PolicySpec{
+						CORS: &conf_v1.CORS{
+							AllowOrigin:      []string{"https://example.com", "https://*.example.com", `~^https://app-[0-9]+\.example\.com$`},
+							AllowMethods:     []string{"GET", "POST"},
+							AllowHeaders:     []string{"Content-Type", "Authorization"},
+							ExposeHeaders:    []string{"X-Request-Id"},
+							AllowCredentials: createPointerFromBool(true),
+							MaxAge:           createPointerFromInt(3600),
+						},
+					},
 				},
 			},
-			expectedWarnings: Warnings{
-				nil: {
-					"Policy default/allow-policy is missing or invalid",
+			context: "route",
+			expected: policiesCfg{
+				CORS: &version2.CORS{
+					AllowOrigin:       "$pol_cors_origin_default_cors_policy_default_test",
+					AllowMethods:      "GET, POST",
+					AllowHeaders:      "Content-Type, Authorization",
+					ExposeHeaders:     "X-Request-Id",
+					AllowCredentials:  true,
+					MaxAge:            "3600",
+					VaryOrigin:        true,
+					PreflightVariable: "$pol_cors_preflight_default_cors_policy_default_test",
+				},
+				Maps: []version2.Map{
+					{
+						Source:   "$request_method:$http_access_control_request_method",
+						Variable: "$pol_cors_preflight_default_cors_policy_default_test",
+						Parameters: []version2.Parameter{
+							{
+								Value:  `"~^OPTIONS:."`,
+								Result: "1",
+							},
+							{
+								Value:  "default",
+								Result: `""`,
+							},
+						},
+					},
+					{
+						Source:   "$http_origin",
+						Variable: "$pol_cors_origin_default_cors_policy_default_test",
+						Parameters: []version2.Parameter{
+							{
+								Value:  `"https://example.com"`,
+								Result: "$http_origin",
+							},
+							{
+								Value:  `"~^https://[a-z0-9.-]+\.example\.com$"`,
+								Result: "$http_origin",
+							},
+							{
+								Value:  `"~^https://app-[0-9]+\.example\.com$"`,
+								Result: "$http_origin",
+							},
+							{
+								Value:  "default",
+								Result: `""`,
+							},
+						},
+					},
 				},
 			},
-			expectedOidc: &oidcPolicyCfg{},
-			msg:          "missing policy",
+			msg: "CORS reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name: "allow-policy",
-				},
-				{
-					Name: "deny-policy",
+					Name:      "cors-any-origin",
+					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/allow-policy": {
-					Spec: conf_v1.PolicySpec{
-						AccessControl: &conf_v1.AccessControl{
-							Allow: []string{"127.0.0.1"},
-						},
-					},
-				},
-				"default/deny-policy": {
+				"default/cors-any-origin": {
 					Spec: conf_v1.PolicySpec{
-						AccessControl: &conf_v1.AccessControl{
-							Deny: []string{"127.0.0.2"},
+						CORS: &conf_v1.CORS{
+							AllowOrigin: []string{"*"},
 						},
 					},
 				},
 			},
-			policyOpts: policyOptions{},
+			context: "route",
 			expected: policiesCfg{
-				Allow: []string{"127.0.0.1"},
-				Deny:  []string{"127.0.0.2"},
-			},
-			expectedWarnings: Warnings{
-				nil: {
-					"AccessControl policy (or policies) with deny rules is overridden by policy (or policies) with allow rules",
+				CORS: &version2.CORS{
+					AllowOrigin:       "*",
+					PreflightVariable: "$pol_cors_preflight_default_cors_any_origin_default_test",
+				},
+				Maps: []version2.Map{
+					{
+						Source:   "$request_method:$http_access_control_request_method",
+						Variable: "$pol_cors_preflight_default_cors_any_origin_default_test",
+						Parameters: []version2.Parameter{
+							{
+								Value:  `"~^OPTIONS:."`,
+								Result: "1",
+							},
+							{
+								Value:  "default",
+								Result: `""`,
+							},
+						},
+					},
 				},
 			},
-			expectedOidc: &oidcPolicyCfg{},
-			msg:          "conflicting policies",
+			msg: "CORS reference with any origin",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "rateLimit-policy",
-					Namespace: "default",
-				},
-				{
-					Name:      "rateLimit-policy2",
+					Name:      "ext-auth-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/rateLimit-policy": {
-					Spec: conf_v1.PolicySpec{
-						RateLimit: &conf_v1.RateLimit{
-							Key:      "test",
-							ZoneSize: "10M",
-							Rate:     "10r/s",
-						},
-					},
-				},
-				"default/rateLimit-policy2": {
+				"default/ext-auth-policy": {
 					Spec: conf_v1.PolicySpec{
-						RateLimit: &conf_v1.RateLimit{
-							Key:        "test2",
-							ZoneSize:   "20M",
-							Rate:       "20r/s",
-							DryRun:     &dryRunOverride,
-							LogLevel:   "info",
-							RejectCode: &rejectCodeOverride,
+						ExternalAuth: &conf_v1.ExternalAuth{
+							AuthService:     "oauth2-proxy",
+							AuthServicePort: 4180,
+							AuthPath:        "/oauth2/auth",
+							ForwardHeaders:  []string{"Authorization", "Cookie"},
+							ResponseHeaders: []string{"X-Auth-Request-User"},
+							Cache: &conf_v1.ExternalAuthCache{
+								Valid: "1m",
+							},
 						},
 					},
 				},
 			},
-			policyOpts: policyOptions{},
+			context: "route",
 			expected: policiesCfg{
-				LimitReqZones: []version2.LimitReqZone{
-					{
-						Key:      "test",
-						ZoneSize: "10M",
-						Rate:     "10r/s",
-						ZoneName: "pol_rl_default_rateLimit-policy_default_test",
-					},
-					{
-						Key:      "test2",
-						ZoneSize: "20M",
-						Rate:     "20r/s",
-						ZoneName: "pol_rl_default_rateLimit-policy2_default_test",
+				ExternalAuth: &version2.ExternalAuth{
+					Path:      "/internal_location_ext_auth_default_ext-auth-policy",
+					ProxyPass: "http://vs_default_test_ext_auth_default_ext-auth-policy/oauth2/auth",
+					ForwardHeaders: []version2.Header{
+						{
+							Name:  "Authorization",
+							Value: "$http_authorization",
+						},
+						{
+							Name:  "Cookie",
+							Value: "$http_cookie",
+						},
 					},
-				},
-				LimitReqOptions: version2.LimitReqOptions{
-					LogLevel:   "error",
-					RejectCode: 503,
-				},
-				LimitReqs: []version2.LimitReq{
-					{
-						ZoneName: "pol_rl_default_rateLimit-policy_default_test",
+					ResponseHeaders: []version2.ExternalAuthResponseHeader{
+						{
+							Name:             "X-Auth-Request-User",
+							Variable:         "$ext_auth_x_auth_request_user",
+							UpstreamVariable: "$upstream_http_x_auth_request_user",
+						},
 					},
-					{
-						ZoneName: "pol_rl_default_rateLimit-policy2_default_test",
+					Cache: &version2.ExternalAuthCache{
+						ZoneName: "pol_ext_auth_default_ext-auth-policy_default_test",
+						ZoneSize: "1m",
+						Key:      "${http_authorization}${http_cookie}${request_method}${request_uri}",
+						Valid:    "1m",
 					},
 				},
 			},
-			expectedWarnings: Warnings{
-				nil: {
-					`RateLimit policy default/rateLimit-policy2 with limit request option dryRun='true' is overridden to dryRun='false' by the first policy reference in this context`,
-					`RateLimit policy default/rateLimit-policy2 with limit request option logLevel='info' is overridden to logLevel='error' by the first policy reference in this context`,
-					`RateLimit policy default/rateLimit-policy2 with limit request option rejectCode='505' is overridden to rejectCode='503' by the first policy reference in this context`,
-				},
-			},
-			expectedOidc: &oidcPolicyCfg{},
-			msg:          "rate limit policy limit request option override",
+			msg: "externalAuth reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "jwt-policy",
+					Name:      "api-key-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/jwt-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "jwt-policy",
-						Namespace: "default",
-					},
+				"default/api-key-policy": {
 					Spec: conf_v1.PolicySpec{
-						JWTAuth: &conf_v1.JWTAuth{
-							Realm:  "test",
-							Secret: "jwt-secret",
-						},
-					},
-				},
-			},
-			policyOpts: policyOptions{
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/jwt-secret": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeJWK,
+						APIKey: &conf_v1.APIKey{
+							SuppliedIn: &conf_v1.SuppliedIn{
+								Header: []string{"X-API-Key", "API-Key"},
+								Query:  []string{"apikey"},
+							},
+							ClientSecret: "api-key-secret",
 						},
-						Error: errors.New("secret is invalid"),
 					},
 				},
 			},
+			context: "route",
 			expected: policiesCfg{
-				ErrorReturn: &version2.Return{
-					Code: 500,
+				APIKey: &version2.APIKey{
+					Headers:        "X-API-Key,API-Key",
+					Query:          "apikey",
+					ClientVariable: "$pol_apikey_client_default_api_key_policy_default_test",
 				},
-			},
-			expectedWarnings: Warnings{
-				nil: {
-					`JWT policy default/jwt-policy references an invalid secret default/jwt-secret: secret is invalid`,
+				Maps: []version2.Map{
+					{
+						Source:   "$apikey_auth_hash",
+						Variable: "$pol_apikey_client_default_api_key_policy_default_test",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: `""`,
+							},
+							{
+								Value:  "include",
+								Result: "/etc/nginx/secrets/default-api-key-secret",
+							},
+						},
+					},
 				},
 			},
-			expectedOidc: &oidcPolicyCfg{},
-			msg:          "jwt reference missing secret",
+			msg: "apiKey reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "jwt-policy",
+					Name:      "cache-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/jwt-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "jwt-policy",
-						Namespace: "default",
-					},
+				"default/cache-policy": {
 					Spec: conf_v1.PolicySpec{
-						JWTAuth: &conf_v1.JWTAuth{
-							Realm:  "test",
-							Secret: "jwt-secret",
+						Cache: &conf_v1.Cache{
+							ZoneSize: "10m",
+							MaxSize:  "1g",
+							Valid: []conf_v1.CacheValid{
+								{
+									Codes: []int{200, 301},
+									Time:  "10m",
+								},
+								{
+									Time: "1m",
+								},
+							},
+							Key:     "${scheme}${host}${request_uri}",
+							Bypass:  []string{"${cookie_nocache}"},
+							NoCache: []string{"${http_pragma}"},
+							Methods: []string{"GET", "HEAD"},
+							Purge: &conf_v1.CachePurge{
+								Allow: []string{"10.0.0.0/8"},
+							},
 						},
 					},
 				},
 			},
-			policyOpts: policyOptions{
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/jwt-secret": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeCA,
+			context: "route",
+			expected: policiesCfg{
+				Cache: &version2.Cache{
+					ZoneName:      "pol_cache_default_cache-policy_default_test",
+					Key:           "${scheme}${host}${request_uri}",
+					Valid:         []string{"200 301 10m", "1m"},
+					Bypass:        []string{"${cookie_nocache}"},
+					NoCache:       []string{"${http_pragma}"},
+					Methods:       []string{"GET", "HEAD"},
+					PurgeVariable: "$pol_cache_default_cache_policy_default_test_purge",
+				},
+				CacheZones: []version2.CacheZone{
+					{
+						ZoneName: "pol_cache_default_cache-policy_default_test",
+						ZoneSize: "10m",
+						MaxSize:  "1g",
+						Purge: &version2.CachePurge{
+							Allow:           []string{"10.0.0.0/8"},
+							AllowedVariable: "$pol_cache_default_cache_policy_default_test_purge_allowed",
+							Variable:        "$pol_cache_default_cache_policy_default_test_purge",
 						},
 					},
 				},
 			},
-			expected: policiesCfg{
-				ErrorReturn: &version2.Return{
-					Code: 500,
+			msg: "cache reference",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "resilience-policy",
+					Namespace: "default",
 				},
 			},
-			expectedWarnings: Warnings{
-				nil: {
-					`JWT policy default/jwt-policy references a secret default/jwt-secret of a wrong type 'nginx.org/ca', must be 'nginx.org/jwk'`,
+			policies: map[string]*conf_v1.Policy{
+				"default/resilience-policy": {
+					Spec: conf_v1.PolicySpec{
+						Resilience: &conf_v1.Resilience{
+							Retry: &conf_v1.Retry{
+								Conditions: []string{"error"},
+							},
+						},
+					},
 				},
 			},
-			expectedOidc: &oidcPolicyCfg{},
-			msg:          "jwt references wrong secret type",
+			context: "route",
+			expected: policiesCfg{
+				Resilience: &conf_v1.Resilience{
+					Retry: &conf_v1.Retry{
+						Conditions: []string{"error"},
+					},
+				},
+			},
+			msg: "resilience reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "jwt-policy",
-					Namespace: "default",
-				},
-				{
-					Name:      "jwt-policy2",
+					Name:      "geo-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/jwt-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "jwt-policy",
-						Namespace: "default",
-					},
+				"default/geo-policy": {
 					Spec: conf_v1.PolicySpec{
-						JWTAuth: &conf_v1.JWTAuth{
-							Realm:  "test",
-							Secret: "jwt-secret",
+						GeoAccess: &conf_v1.GeoAccess{
+							CountryDatabase: "/etc/nginx/geoip/GeoLite2-Country.mmdb",
+							ASNDatabase:     "/etc/nginx/geoip/GeoLite2-ASN.mmdb",
+							Allow: &conf_v1.GeoRules{
+								Countries: []string{"US", "CA"},
+								ASNs:      []int{64496},
+							},
 						},
 					},
 				},
-				"default/jwt-policy2": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "jwt-policy2",
-						Namespace: "default",
+			},
+			context: "route",
+			expected: policiesCfg{
+				GeoAccess: &version2.GeoAccess{
+					DeniedVariable: "$pol_geo_denied_default_geo_policy_default_test",
+					RejectCode:     403,
+				},
+				GeoIP2: []version2.GeoIP2{
+					{
+						Database: "/etc/nginx/geoip/GeoLite2-Country.mmdb",
+						Variable: "$pol_geo_country_default_geo_policy_default_test",
+						Path:     "country iso_code",
 					},
-					Spec: conf_v1.PolicySpec{
-						JWTAuth: &conf_v1.JWTAuth{
-							Realm:  "test",
-							Secret: "jwt-secret2",
-						},
+					{
+						Database: "/etc/nginx/geoip/GeoLite2-ASN.mmdb",
+						Variable: "$pol_geo_asn_default_geo_policy_default_test",
+						Path:     "autonomous_system_number",
 					},
 				},
-			},
-			policyOpts: policyOptions{
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/jwt-secret": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeJWK,
+				Maps: []version2.Map{
+					{
+						Source:   "$pol_geo_country_default_geo_policy_default_test",
+						Variable: "$pol_geo_country_default_geo_policy_default_test_match",
+						Parameters: []version2.Parameter{
+							{Value: "US", Result: "1"},
+							{Value: "CA", Result: "1"},
+							{Value: "default", Result: `""`},
 						},
-						Path: "/etc/nginx/secrets/default-jwt-secret",
 					},
-					"default/jwt-secret2": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeJWK,
+					{
+						Source:   "$pol_geo_asn_default_geo_policy_default_test",
+						Variable: "$pol_geo_asn_default_geo_policy_default_test_match",
+						Parameters: []version2.Parameter{
+							{Value: "64496", Result: "1"},
+							{Value: "default", Result: `""`},
+						},
+					},
+					{
+						Source:   `"$pol_geo_country_default_geo_policy_default_test_match$pol_geo_asn_default_geo_policy_default_test_match"`,
+						Variable: "$pol_geo_denied_default_geo_policy_default_test",
+						Parameters: []version2.Parameter{
+							{Value: `""`, Result: "1"},
+							{Value: "default", Result: "0"},
 						},
-						Path: "/etc/nginx/secrets/default-jwt-secret2",
 					},
 				},
 			},
-			expected: policiesCfg{
-				JWTAuth: &version2.JWTAuth{
-					Secret: "/etc/nginx/secrets/default-jwt-secret",
-					Realm:  "test",
-				},
-			},
-			expectedWarnings: Warnings{
-				nil: {
-					`Multiple jwt policies in the same context is not valid. JWT policy default/jwt-policy2 will be ignored`,
-				},
-			},
-			expectedOidc: &oidcPolicyCfg{},
-			msg:          "multi jwt reference",
+			msg: "geoAccess allow reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "basic-auth-policy",
+					Name:      "geo-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/basic-auth-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "basic-auth-policy",
-						Namespace: "default",
-					},
+				"default/geo-policy": {
 					Spec: conf_v1.PolicySpec{
-						BasicAuth: &conf_v1.BasicAuth{
-							Realm:  "test",
-							Secret: "htpasswd-secret",
-						},
-					},
-				},
-			},
-			policyOpts: policyOptions{
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/htpasswd-secret": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeHtpasswd,
+						GeoAccess: &conf_v1.GeoAccess{
+							CountryDatabase: "/etc/nginx/geoip/GeoLite2-Country.mmdb",
+							Deny: &conf_v1.GeoRules{
+								Countries: []string{"RU"},
+							},
+							DryRun:     createPointerFromBool(true),
+							RejectCode: createPointerFromInt(451),
 						},
-						Error: errors.New("secret is invalid"),
 					},
 				},
 			},
+			context: "route",
 			expected: policiesCfg{
-				ErrorReturn: &version2.Return{
-					Code: 500,
+				GeoAccess: &version2.GeoAccess{
+					DeniedVariable: "$pol_geo_denied_default_geo_policy_default_test",
+					RejectCode:     451,
+					DryRun:         true,
 				},
-			},
-			expectedWarnings: Warnings{
-				nil: {
-					`Basic Auth policy default/basic-auth-policy references an invalid secret default/htpasswd-secret: secret is invalid`,
+				GeoIP2: []version2.GeoIP2{
+					{
+						Database: "/etc/nginx/geoip/GeoLite2-Country.mmdb",
+						Variable: "$pol_geo_country_default_geo_policy_default_test",
+						Path:     "country iso_code",
+					},
+				},
+				Maps: []version2.Map{
+					{
+						Source:   "$pol_geo_country_default_geo_policy_default_test",
+						Variable: "$pol_geo_country_default_geo_policy_default_test_match",
+						Parameters: []version2.Parameter{
+							{Value: "RU", Result: "1"},
+							{Value: "default", Result: `""`},
+						},
+					},
+					{
+						Source:   `"$pol_geo_country_default_geo_policy_default_test_match"`,
+						Variable: "$pol_geo_denied_default_geo_policy_default_test",
+						Parameters: []version2.Parameter{
+							{Value: `""`, Result: "0"},
+							{Value: "default", Result: "1"},
+						},
+					},
 				},
 			},
-			expectedOidc: &oidcPolicyCfg{},
-			msg:          "basic auth reference missing secret",
+			msg: "geoAccess deny reference in dry run",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "basic-auth-policy",
+					Name:      "headers-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/basic-auth-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "basic-auth-policy",
-						Namespace: "default",
-					},
+				"default/headers-policy": {
 					Spec: conf_v1.PolicySpec{
-						BasicAuth: &conf_v1.BasicAuth{
-							Realm:  "test",
-							Secret: "htpasswd-secret",
+						Headers: &conf_v1.Headers{
+							SecurityHeaders: &conf_v1.SecurityHeaders{
+								FrameOptions: "DENY",
+							},
 						},
 					},
 				},
 			},
-			policyOpts: policyOptions{
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/htpasswd-secret": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeCA,
-						},
+			context: "route",
+			expected: policiesCfg{
+				Headers: &conf_v1.Headers{
+					SecurityHeaders: &conf_v1.SecurityHeaders{
+						FrameOptions: "DENY",
 					},
 				},
 			},
-			expected: policiesCfg{
-				ErrorReturn: &version2.Return{
-					Code: 500,
+			msg: "headers reference",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "compression-policy",
+					Namespace: "default",
 				},
 			},
-			expectedWarnings: Warnings{
-				nil: {
-					`Basic Auth policy default/basic-auth-policy references a secret default/htpasswd-secret of a wrong type 'nginx.org/ca', must be 'nginx.org/htpasswd'`,
+			policies: map[string]*conf_v1.Policy{
+				"default/compression-policy": {
+					Spec: conf_v1.PolicySpec{
+						Compression: &conf_v1.Compression{
+							Algorithms: []string{"gzip"},
+						},
+					},
 				},
 			},
-			expectedOidc: &oidcPolicyCfg{},
-			msg:          "basic auth references wrong secret type",
+			context: "spec",
+			expected: policiesCfg{
+				Compression: &version2.Compression{
+					Gzip:        true,
+					GzipLevel:   1,
+					BrotliLevel: 6,
+					MinLength:   20,
+					Types:       "text/plain text/css text/xml application/javascript application/json application/xml",
+				},
+			},
+			msg: "compression reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "basic-auth-policy",
-					Namespace: "default",
-				},
-				{
-					Name:      "basic-auth-policy2",
+					Name:      "compression-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/basic-auth-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "basic-auth-policy",
-						Namespace: "default",
-					},
+				"default/compression-policy": {
 					Spec: conf_v1.PolicySpec{
-						BasicAuth: &conf_v1.BasicAuth{
-							Realm:  "test",
-							Secret: "htpasswd-secret",
+						Compression: &conf_v1.Compression{
+							Algorithms:  []string{"brotli", "gzip"},
+							GzipLevel:   createPointerFromInt(5),
+							BrotliLevel: createPointerFromInt(4),
+							MinLength:   createPointerFromInt(1024),
+							Types:       []string{"application/json", "image/svg+xml"},
+							Proxied:     true,
 						},
 					},
 				},
-				"default/basic-auth-policy2": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "basic-auth-policy2",
-						Namespace: "default",
-					},
-					Spec: conf_v1.PolicySpec{
-						BasicAuth: &conf_v1.BasicAuth{
-							Realm:  "test",
-							Secret: "htpasswd-secret2",
-						},
-					},
+			},
+			context: "route",
+			expected: policiesCfg{
+				Compression: &version2.Compression{
+					Gzip:        true,
+					GzipLevel:   5,
+					Brotli:      true,
+					BrotliLevel: 4,
+					MinLength:   1024,
+					Types:       "application/json image/svg+xml",
+					Proxied:     true,
 				},
 			},
-			policyOpts: policyOptions{
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/htpasswd-secret": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeHtpasswd,
-						},
-						Path: "/etc/nginx/secrets/default-htpasswd-secret",
-					},
-					"default/htpasswd-secret2": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeHtpasswd,
-						},
-						Path: "/etc/nginx/secrets/default-htpasswd-secret2",
-					},
-				},
-			},
-			expected: policiesCfg{
-				BasicAuth: &version2.BasicAuth{
-					Secret: "/etc/nginx/secrets/default-htpasswd-secret",
-					Realm:  "test",
-				},
-			},
-			expectedWarnings: Warnings{
-				nil: {
-					`Multiple basic auth policies in the same context is not valid. Basic auth policy default/basic-auth-policy2 will be ignored`,
-				},
-			},
-			expectedOidc: &oidcPolicyCfg{},
-			msg:          "multi basic auth reference",
+			msg: "compression reference with all fields",
 		},
+	}
+
+	vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{}, false)
+
+	for _, test := range tests {
+		result := vsc.generatePolicies(ownerDetails, test.policyRefs, test.policies, test.context, policyOpts)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("generatePolicies() '%v' mismatch (-want +got):\n%s", test.msg, diff)
+		}
+		if len(vsc.warnings) > 0 {
+			t.Errorf("generatePolicies() returned unexpected warnings %v for the case of %s", vsc.warnings, test.msg)
+		}
+	}
+}
+
+func TestGeneratePoliciesFails(t *testing.T) {
+	t.Parallel()
+	ownerDetails := policyOwnerDetails{
+		owner:          nil, // nil is OK for the unit test
+		ownerNamespace: "default",
+		vsNamespace:    "default",
+		vsName:         "test",
+	}
+
+	dryRunOverride := true
+	rejectCodeOverride := 505
+
+	tests := []struct {
+		policyRefs        []conf_v1.PolicyReference
+		policies          map[string]*conf_v1.Policy
+		policyOpts        policyOptions
+		trustedCAFileName string
+		context           string
+		oidcPolCfg        *oidcPolicyCfg
+		expected          policiesCfg
+		expectedWarnings  Warnings
+		expectedOidc      *oidcPolicyCfg
+		msg               string
+	}{
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "ingress-mtls-policy",
+					Name:      "allow-policy",
 					Namespace: "default",
 				},
 			},
-			policies: map[string]*conf_v1.Policy{
-				"default/ingress-mtls-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "ingress-mtls-policy",
-						Namespace: "default",
-					},
-					Spec: conf_v1.PolicySpec{
-						IngressMTLS: &conf_v1.IngressMTLS{
-							ClientCertSecret: "ingress-mtls-secret",
-						},
-					},
-				},
-			},
-			policyOpts: policyOptions{
-				tls: true,
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/ingress-mtls-secret": {
-						Error: errors.New("secret is invalid"),
-					},
-				},
-			},
-			context: "spec",
+			policies:   map[string]*conf_v1.Policy{},
+			policyOpts: policyOptions{},
 			expected: policiesCfg{
 				ErrorReturn: &version2.Return{
 					Code: 500,
@@ -3495,146 +3704,154 @@ func TestGeneratePoliciesFails(t *testing.T) {
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`IngressMTLS policy "default/ingress-mtls-policy" references an invalid secret default/ingress-mtls-secret: secret is invalid`,
+					"Policy default/allow-policy is missing or invalid",
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "ingress mtls reference an invalid secret",
+			msg:          "missing policy",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "ingress-mtls-policy",
-					Namespace: "default",
+					Name: "allow-policy",
+				},
+				{
+					Name: "deny-policy",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/ingress-mtls-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "ingress-mtls-policy",
-						Namespace: "default",
-					},
+				"default/allow-policy": {
 					Spec: conf_v1.PolicySpec{
-						IngressMTLS: &conf_v1.IngressMTLS{
-							ClientCertSecret: "ingress-mtls-secret",
+						AccessControl: &conf_v1.AccessControl{
+							Allow: []string{"127.0.0.1"},
 						},
 					},
 				},
-			},
-			policyOpts: policyOptions{
-				tls: true,
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/ingress-mtls-secret": {
-						Secret: &api_v1.Secret{
-							Type: api_v1.SecretTypeTLS,
+				"default/deny-policy": {
+					Spec: conf_v1.PolicySpec{
+						AccessControl: &conf_v1.AccessControl{
+							Deny: []string{"127.0.0.2"},
 						},
 					},
 				},
 			},
-			context: "spec",
+			policyOpts: policyOptions{},
 			expected: policiesCfg{
-				ErrorReturn: &version2.Return{
-					Code: 500,
-				},
+				Allow: []string{"127.0.0.1"},
+				Deny:  []string{"127.0.0.2"},
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`IngressMTLS policy default/ingress-mtls-policy references a secret default/ingress-mtls-secret of a wrong type 'kubernetes.io/tls', must be 'nginx.org/ca'`,
+					"AccessControl policy (or policies) with deny rules is overridden by policy (or policies) with allow rules",
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "ingress mtls references wrong secret type",
+			msg:          "conflicting policies",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "ingress-mtls-policy",
+					Name:      "rateLimit-policy",
 					Namespace: "default",
 				},
 				{
-					Name:      "ingress-mtls-policy2",
+					Name:      "rateLimit-policy2",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/ingress-mtls-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "ingress-mtls-policy",
-						Namespace: "default",
-					},
+				"default/rateLimit-policy": {
 					Spec: conf_v1.PolicySpec{
-						IngressMTLS: &conf_v1.IngressMTLS{
-							ClientCertSecret: "ingress-mtls-secret",
+						RateLimit: &conf_v1.RateLimit{
+							Key:      "test",
+							ZoneSize: "10M",
+							Rate:     "10r/s",
 						},
 					},
 				},
-				"default/ingress-mtls-policy2": {
+				"default/rateLimit-policy2": {
 					Spec: conf_v1.PolicySpec{
-						IngressMTLS: &conf_v1.IngressMTLS{
-							ClientCertSecret: "ingress-mtls-secret2",
+						RateLimit: &conf_v1.RateLimit{
+							Key:        "test2",
+							ZoneSize:   "20M",
+							Rate:       "20r/s",
+							DryRun:     &dryRunOverride,
+							LogLevel:   "info",
+							RejectCode: &rejectCodeOverride,
 						},
 					},
 				},
 			},
-			policyOpts: policyOptions{
-				tls: true,
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/ingress-mtls-secret": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeCA,
-						},
-						Path: "/etc/nginx/secrets/default-ingress-mtls-secret",
+			policyOpts: policyOptions{},
+			expected: policiesCfg{
+				LimitReqZones: []version2.LimitReqZone{
+					{
+						Key:      "test",
+						ZoneSize: "10M",
+						Rate:     "10r/s",
+						ZoneName: "pol_rl_default_rateLimit-policy_default_test",
+					},
+					{
+						Key:      "test2",
+						ZoneSize: "20M",
+						Rate:     "20r/s",
+						ZoneName: "pol_rl_default_rateLimit-policy2_default_test",
 					},
 				},
-			},
-			context: "spec",
-			expected: policiesCfg{
-				IngressMTLS: &version2.IngressMTLS{
-					ClientCert:   "/etc/nginx/secrets/default-ingress-mtls-secret",
-					VerifyClient: "on",
-					VerifyDepth:  1,
+				LimitReqOptions: version2.LimitReqOptions{
+					LogLevel:   "error",
+					RejectCode: 503,
+				},
+				LimitReqs: []version2.LimitReq{
+					{
+						ZoneName: "pol_rl_default_rateLimit-policy_default_test",
+					},
+					{
+						ZoneName: "pol_rl_default_rateLimit-policy2_default_test",
+					},
 				},
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`Multiple ingressMTLS policies are not allowed. IngressMTLS policy default/ingress-mtls-policy2 will be ignored`,
+					`RateLimit policy default/rateLimit-policy2 with limit request option dryRun='true' is overridden to dryRun='false' by the first policy reference in this context`,
+					`RateLimit policy default/rateLimit-policy2 with limit request option logLevel='info' is overridden to logLevel='error' by the first policy reference in this context`,
+					`RateLimit policy default/rateLimit-policy2 with limit request option rejectCode='505' is overridden to rejectCode='503' by the first policy reference in this context`,
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "multi ingress mtls",
+			msg:          "rate limit policy limit request option override",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "ingress-mtls-policy",
+					Name:      "jwt-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/ingress-mtls-policy": {
+				"default/jwt-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "ingress-mtls-policy",
+						Name:      "jwt-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						IngressMTLS: &conf_v1.IngressMTLS{
-							ClientCertSecret: "ingress-mtls-secret",
+						JWTAuth: &conf_v1.JWTAuth{
+							Realm:  "test",
+							Secret: "jwt-secret",
 						},
 					},
 				},
 			},
 			policyOpts: policyOptions{
-				tls: true,
 				secretRefs: map[string]*secrets.SecretReference{
-					"default/ingress-mtls-secret": {
+					"default/jwt-secret": {
 						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeCA,
+							Type: secrets.SecretTypeJWK,
 						},
-						Path: "/etc/nginx/secrets/default-ingress-mtls-secret",
+						Error: errors.New("secret is invalid"),
 					},
 				},
 			},
-			context: "route",
 			expected: policiesCfg{
 				ErrorReturn: &version2.Return{
 					Code: 500,
@@ -3642,44 +3859,42 @@ func TestGeneratePoliciesFails(t *testing.T) {
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`IngressMTLS policy default/ingress-mtls-policy is not allowed in the route context`,
+					`JWT policy default/jwt-policy references an invalid secret default/jwt-secret: secret is invalid`,
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "ingress mtls in the wrong context",
+			msg:          "jwt reference missing secret",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "ingress-mtls-policy",
+					Name:      "jwt-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/ingress-mtls-policy": {
+				"default/jwt-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "ingress-mtls-policy",
+						Name:      "jwt-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						IngressMTLS: &conf_v1.IngressMTLS{
-							ClientCertSecret: "ingress-mtls-secret",
+						JWTAuth: &conf_v1.JWTAuth{
+							Realm:  "test",
+							Secret: "jwt-secret",
 						},
 					},
 				},
 			},
 			policyOpts: policyOptions{
-				tls: false,
 				secretRefs: map[string]*secrets.SecretReference{
-					"default/ingress-mtls-secret": {
+					"default/jwt-secret": {
 						Secret: &api_v1.Secret{
 							Type: secrets.SecretTypeCA,
 						},
-						Path: "/etc/nginx/secrets/default-ingress-mtls-secret",
 					},
 				},
 			},
-			context: "route",
 			expected: policiesCfg{
 				ErrorReturn: &version2.Return{
 					Code: 500,
@@ -3687,110 +3902,110 @@ func TestGeneratePoliciesFails(t *testing.T) {
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`TLS must be enabled in VirtualServer for IngressMTLS policy default/ingress-mtls-policy`,
+					`JWT policy default/jwt-policy references a secret default/jwt-secret of a wrong type 'nginx.org/ca', must be 'nginx.org/jwk'`,
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "ingress mtls missing TLS config",
+			msg:          "jwt references wrong secret type",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "egress-mtls-policy",
+					Name:      "jwt-policy",
 					Namespace: "default",
 				},
 				{
-					Name:      "egress-mtls-policy2",
+					Name:      "jwt-policy2",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/egress-mtls-policy": {
+				"default/jwt-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "egress-mtls-policy",
+						Name:      "jwt-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						EgressMTLS: &conf_v1.EgressMTLS{
-							TLSSecret: "egress-mtls-secret",
+						JWTAuth: &conf_v1.JWTAuth{
+							Realm:  "test",
+							Secret: "jwt-secret",
 						},
 					},
 				},
-				"default/egress-mtls-policy2": {
+				"default/jwt-policy2": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "egress-mtls-policy2",
+						Name:      "jwt-policy2",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						EgressMTLS: &conf_v1.EgressMTLS{
-							TLSSecret: "egress-mtls-secret2",
+						JWTAuth: &conf_v1.JWTAuth{
+							Realm:  "test",
+							Secret: "jwt-secret2",
 						},
 					},
 				},
 			},
 			policyOpts: policyOptions{
 				secretRefs: map[string]*secrets.SecretReference{
-					"default/egress-mtls-secret": {
+					"default/jwt-secret": {
 						Secret: &api_v1.Secret{
-							Type: api_v1.SecretTypeTLS,
+							Type: secrets.SecretTypeJWK,
 						},
-						Path: "/etc/nginx/secrets/default-egress-mtls-secret",
+						Path: "/etc/nginx/secrets/default-jwt-secret",
+					},
+					"default/jwt-secret2": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeJWK,
+						},
+						Path: "/etc/nginx/secrets/default-jwt-secret2",
 					},
 				},
 			},
-			context: "route",
 			expected: policiesCfg{
-				EgressMTLS: &version2.EgressMTLS{
-					Certificate:    "/etc/nginx/secrets/default-egress-mtls-secret",
-					CertificateKey: "/etc/nginx/secrets/default-egress-mtls-secret",
-					VerifyServer:   false,
-					VerifyDepth:    1,
-					Ciphers:        "DEFAULT",
-					Protocols:      "TLSv1 TLSv1.1 TLSv1.2",
-					SessionReuse:   true,
-					SSLName:        "$proxy_host",
+				JWTAuth: &version2.JWTAuth{
+					Secret: "/etc/nginx/secrets/default-jwt-secret",
+					Realm:  "test",
 				},
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`Multiple egressMTLS policies in the same context is not valid. EgressMTLS policy default/egress-mtls-policy2 will be ignored`,
+					`Multiple jwt policies in the same context is not valid. JWT policy default/jwt-policy2 will be ignored`,
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "multi egress mtls",
+			msg:          "multi jwt reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "egress-mtls-policy",
+					Name:      "basic-auth-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/egress-mtls-policy": {
+				"default/basic-auth-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "egress-mtls-policy",
+						Name:      "basic-auth-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						EgressMTLS: &conf_v1.EgressMTLS{
-							TrustedCertSecret: "egress-trusted-secret",
-							SSLName:           "foo.com",
+						BasicAuth: &conf_v1.BasicAuth{
+							Realm:  "test",
+							Secret: "htpasswd-secret",
 						},
 					},
 				},
 			},
 			policyOpts: policyOptions{
 				secretRefs: map[string]*secrets.SecretReference{
-					"default/egress-trusted-secret": {
+					"default/htpasswd-secret": {
 						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeCA,
+							Type: secrets.SecretTypeHtpasswd,
 						},
 						Error: errors.New("secret is invalid"),
 					},
 				},
 			},
-			context: "route",
 			expected: policiesCfg{
 				ErrorReturn: &version2.Return{
 					Code: 500,
@@ -3798,43 +4013,42 @@ func TestGeneratePoliciesFails(t *testing.T) {
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`EgressMTLS policy default/egress-mtls-policy references an invalid secret default/egress-trusted-secret: secret is invalid`,
+					`Basic Auth policy default/basic-auth-policy references an invalid secret default/htpasswd-secret: secret is invalid`,
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "egress mtls referencing an invalid CA secret",
+			msg:          "basic auth reference missing secret",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "egress-mtls-policy",
+					Name:      "basic-auth-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/egress-mtls-policy": {
+				"default/basic-auth-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "egress-mtls-policy",
+						Name:      "basic-auth-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						EgressMTLS: &conf_v1.EgressMTLS{
-							TLSSecret: "egress-mtls-secret",
-							SSLName:   "foo.com",
+						BasicAuth: &conf_v1.BasicAuth{
+							Realm:  "test",
+							Secret: "htpasswd-secret",
 						},
 					},
 				},
 			},
 			policyOpts: policyOptions{
 				secretRefs: map[string]*secrets.SecretReference{
-					"default/egress-mtls-secret": {
+					"default/htpasswd-secret": {
 						Secret: &api_v1.Secret{
 							Type: secrets.SecretTypeCA,
 						},
 					},
 				},
 			},
-			context: "route",
 			expected: policiesCfg{
 				ErrorReturn: &version2.Return{
 					Code: 500,
@@ -3842,43 +4056,44 @@ func TestGeneratePoliciesFails(t *testing.T) {
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`EgressMTLS policy default/egress-mtls-policy references a secret default/egress-mtls-secret of a wrong type 'nginx.org/ca', must be 'kubernetes.io/tls'`,
+					`Basic Auth policy default/basic-auth-policy references a secret default/htpasswd-secret of a wrong type 'nginx.org/ca', must be 'nginx.org/htpasswd'`,
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "egress mtls referencing wrong secret type",
+			msg:          "basic auth references wrong secret type",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "egress-mtls-policy",
+					Name:      "api-key-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/egress-mtls-policy": {
+				"default/api-key-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "egress-mtls-policy",
+						Name:      "api-key-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						EgressMTLS: &conf_v1.EgressMTLS{
-							TrustedCertSecret: "egress-trusted-secret",
-							SSLName:           "foo.com",
+						APIKey: &conf_v1.APIKey{
+							SuppliedIn: &conf_v1.SuppliedIn{
+								Header: []string{"X-API-Key"},
+							},
+							ClientSecret: "api-key-secret",
 						},
 					},
 				},
 			},
 			policyOpts: policyOptions{
 				secretRefs: map[string]*secrets.SecretReference{
-					"default/egress-trusted-secret": {
+					"default/api-key-secret": {
 						Secret: &api_v1.Secret{
-							Type: api_v1.SecretTypeTLS,
+							Type: secrets.SecretTypeHtpasswd,
 						},
 					},
 				},
 			},
-			context: "route",
 			expected: policiesCfg{
 				ErrorReturn: &version2.Return{
 					Code: 500,
@@ -3886,4810 +4101,6747 @@ func TestGeneratePoliciesFails(t *testing.T) {
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`EgressMTLS policy default/egress-mtls-policy references a secret default/egress-trusted-secret of a wrong type 'kubernetes.io/tls', must be 'nginx.org/ca'`,
+					`API Key policy default/api-key-policy references a secret default/api-key-secret of a wrong type 'nginx.org/htpasswd', must be 'nginx.org/apikey'`,
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "egress trusted secret referencing wrong secret type",
+			msg:          "apiKey references wrong secret type",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "egress-mtls-policy",
+					Name:      "basic-auth-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "basic-auth-policy2",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/egress-mtls-policy": {
+				"default/basic-auth-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "egress-mtls-policy",
+						Name:      "basic-auth-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						EgressMTLS: &conf_v1.EgressMTLS{
-							TLSSecret: "egress-mtls-secret",
-							SSLName:   "foo.com",
+						BasicAuth: &conf_v1.BasicAuth{
+							Realm:  "test",
+							Secret: "htpasswd-secret",
+						},
+					},
+				},
+				"default/basic-auth-policy2": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "basic-auth-policy2",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						BasicAuth: &conf_v1.BasicAuth{
+							Realm:  "test",
+							Secret: "htpasswd-secret2",
 						},
 					},
 				},
 			},
 			policyOpts: policyOptions{
 				secretRefs: map[string]*secrets.SecretReference{
-					"default/egress-mtls-secret": {
+					"default/htpasswd-secret": {
 						Secret: &api_v1.Secret{
-							Type: api_v1.SecretTypeTLS,
+							Type: secrets.SecretTypeHtpasswd,
 						},
-						Error: errors.New("secret is invalid"),
+						Path: "/etc/nginx/secrets/default-htpasswd-secret",
+					},
+					"default/htpasswd-secret2": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeHtpasswd,
+						},
+						Path: "/etc/nginx/secrets/default-htpasswd-secret2",
 					},
 				},
 			},
-			context: "route",
 			expected: policiesCfg{
-				ErrorReturn: &version2.Return{
-					Code: 500,
+				BasicAuth: &version2.BasicAuth{
+					Secret: "/etc/nginx/secrets/default-htpasswd-secret",
+					Realm:  "test",
 				},
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`EgressMTLS policy default/egress-mtls-policy references an invalid secret default/egress-mtls-secret: secret is invalid`,
+					`Multiple basic auth policies in the same context is not valid. Basic auth policy default/basic-auth-policy2 will be ignored`,
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "egress mtls referencing missing tls secret",
+			msg:          "multi basic auth reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "oidc-policy",
+					Name:      "cors-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "cors-policy2",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/oidc-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "oidc-policy",
-						Namespace: "default",
-					},
+				"default/cors-policy": {
 					Spec: conf_v1.PolicySpec{
-						OIDC: &conf_v1.OIDC{
-							ClientSecret: "oidc-secret",
+						CORS: &conf_v1.CORS{
+							AllowOrigin: []string{"*"},
 						},
 					},
 				},
-			},
-			policyOpts: policyOptions{
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/oidc-secret": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeOIDC,
+				"default/cors-policy2": {
+					Spec: conf_v1.PolicySpec{
+						CORS: &conf_v1.CORS{
+							AllowOrigin: []string{"*"},
 						},
-						Error: errors.New("secret is invalid"),
 					},
 				},
 			},
-			context: "route",
+			policyOpts: policyOptions{},
 			expected: policiesCfg{
-				ErrorReturn: &version2.Return{
-					Code: 500,
+				CORS: &version2.CORS{
+					AllowOrigin:       "*",
+					PreflightVariable: "$pol_cors_preflight_default_cors_policy_default_test",
+				},
+				Maps: []version2.Map{
+					{
+						Source:   "$request_method:$http_access_control_request_method",
+						Variable: "$pol_cors_preflight_default_cors_policy_default_test",
+						Parameters: []version2.Parameter{
+							{
+								Value:  `"~^OPTIONS:."`,
+								Result: "1",
+							},
+							{
+								Value:  "default",
+								Result: `""`,
+							},
+						},
+					},
 				},
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`OIDC policy default/oidc-policy references an invalid secret default/oidc-secret: secret is invalid`,
+					`Multiple CORS policies in the same context is not valid. CORS policy default/cors-policy2 will be ignored`,
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "oidc referencing missing oidc secret",
+			msg:          "multi CORS reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "oidc-policy",
+					Name:      "ext-auth-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "ext-auth-policy2",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/oidc-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "oidc-policy",
-						Namespace: "default",
-					},
+				"default/ext-auth-policy": {
 					Spec: conf_v1.PolicySpec{
-						OIDC: &conf_v1.OIDC{
-							ClientSecret:  "oidc-secret",
-							AuthEndpoint:  "http://foo.com/bar",
-							TokenEndpoint: "http://foo.com/bar",
-							JWKSURI:       "http://foo.com/bar",
+						ExternalAuth: &conf_v1.ExternalAuth{
+							AuthService:     "auth-svc",
+							AuthServicePort: 80,
 						},
 					},
 				},
-			},
-			policyOpts: policyOptions{
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/oidc-secret": {
-						Secret: &api_v1.Secret{
-							Type: api_v1.SecretTypeTLS,
+				"default/ext-auth-policy2": {
+					Spec: conf_v1.PolicySpec{
+						ExternalAuth: &conf_v1.ExternalAuth{
+							AuthService:     "auth-svc2",
+							AuthServicePort: 80,
 						},
 					},
 				},
 			},
-			context: "spec",
+			policyOpts: policyOptions{},
 			expected: policiesCfg{
-				ErrorReturn: &version2.Return{
-					Code: 500,
+				ExternalAuth: &version2.ExternalAuth{
+					Path:      "/internal_location_ext_auth_default_ext-auth-policy",
+					ProxyPass: "http://vs_default_test_ext_auth_default_ext-auth-policy/",
 				},
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`OIDC policy default/oidc-policy references a secret default/oidc-secret of a wrong type 'kubernetes.io/tls', must be 'nginx.org/oidc'`,
+					`Multiple externalAuth policies in the same context is not valid. ExternalAuth policy default/ext-auth-policy2 will be ignored`,
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "oidc secret referencing wrong secret type",
+			msg:          "multi externalAuth reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "oidc-policy-2",
+					Name:      "cache-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "cache-policy2",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/oidc-policy-1": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "oidc-policy-1",
-						Namespace: "default",
-					},
+				"default/cache-policy": {
 					Spec: conf_v1.PolicySpec{
-						OIDC: &conf_v1.OIDC{
-							ClientID:      "foo",
-							ClientSecret:  "oidc-secret",
-							AuthEndpoint:  "https://foo.com/auth",
-							TokenEndpoint: "https://foo.com/token",
-							JWKSURI:       "https://foo.com/certs",
+						Cache: &conf_v1.Cache{
+							ZoneSize: "10m",
 						},
 					},
 				},
-				"default/oidc-policy-2": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "oidc-policy-2",
-						Namespace: "default",
-					},
+				"default/cache-policy2": {
 					Spec: conf_v1.PolicySpec{
-						OIDC: &conf_v1.OIDC{
-							ClientID:      "foo",
-							ClientSecret:  "oidc-secret",
-							AuthEndpoint:  "https://bar.com/auth",
-							TokenEndpoint: "https://bar.com/token",
-							JWKSURI:       "https://bar.com/certs",
+						Cache: &conf_v1.Cache{
+							ZoneSize: "20m",
 						},
 					},
 				},
 			},
-			policyOpts: policyOptions{
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/oidc-secret": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeOIDC,
-							Data: map[string][]byte{
-								"client-secret": []byte("super_secret_123"),
-							},
-						},
+			policyOpts: policyOptions{},
+			expected: policiesCfg{
+				Cache: &version2.Cache{
+					ZoneName: "pol_cache_default_cache-policy_default_test",
+				},
+				CacheZones: []version2.CacheZone{
+					{
+						ZoneName: "pol_cache_default_cache-policy_default_test",
+						ZoneSize: "10m",
 					},
 				},
 			},
-			context: "route",
-			oidcPolCfg: &oidcPolicyCfg{
-				oidc: &version2.OIDC{
-					AuthEndpoint:   "https://foo.com/auth",
-					TokenEndpoint:  "https://foo.com/token",
-					JwksURI:        "https://foo.com/certs",
-					ClientID:       "foo",
-					ClientSecret:   "super_secret_123",
-					RedirectURI:    "/_codexch",
-					Scope:          "openid",
-					ZoneSyncLeeway: 0,
+			expectedWarnings: Warnings{
+				nil: {
+					`Multiple cache policies in the same context is not valid. Cache policy default/cache-policy2 will be ignored`,
 				},
-				key: "default/oidc-policy-1",
 			},
-			expected: policiesCfg{
-				ErrorReturn: &version2.Return{
-					Code: 500,
-				},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "multi cache reference",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "resilience-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "resilience-policy2",
+					Namespace: "default",
+				},
 			},
-			expectedWarnings: Warnings{
-				nil: {
-					`Only one oidc policy is allowed in a VirtualServer and its VirtualServerRoutes. Can't use default/oidc-policy-2. Use default/oidc-policy-1`,
+			policies: map[string]*conf_v1.Policy{
+				"default/resilience-policy": {
+					Spec: conf_v1.PolicySpec{
+						Resilience: &conf_v1.Resilience{
+							Retry: &conf_v1.Retry{},
+						},
+					},
+				},
+				"default/resilience-policy2": {
+					Spec: conf_v1.PolicySpec{
+						Resilience: &conf_v1.Resilience{
+							CircuitBreaker: &conf_v1.CircuitBreaker{},
+						},
+					},
 				},
 			},
-			expectedOidc: &oidcPolicyCfg{
-				oidc: &version2.OIDC{
-					AuthEndpoint:  "https://foo.com/auth",
-					TokenEndpoint: "https://foo.com/token",
-					JwksURI:       "https://foo.com/certs",
-					ClientID:      "foo",
-					ClientSecret:  "super_secret_123",
-					RedirectURI:   "/_codexch",
-					Scope:         "openid",
+			policyOpts: policyOptions{},
+			expected: policiesCfg{
+				Resilience: &conf_v1.Resilience{
+					Retry: &conf_v1.Retry{},
 				},
-				key: "default/oidc-policy-1",
 			},
-			msg: "multiple oidc policies",
+			expectedWarnings: Warnings{
+				nil: {
+					`Multiple resilience policies in the same context is not valid. Resilience policy default/resilience-policy2 will be ignored`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "multi resilience reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "oidc-policy",
+					Name:      "geo-policy",
 					Namespace: "default",
 				},
 				{
-					Name:      "oidc-policy2",
+					Name:      "geo-policy2",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/oidc-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "oidc-policy",
-						Namespace: "default",
+				"default/geo-policy": {
+					Spec: conf_v1.PolicySpec{
+						GeoAccess: &conf_v1.GeoAccess{
+							ASNDatabase: "/etc/nginx/geoip/GeoLite2-ASN.mmdb",
+							Deny: &conf_v1.GeoRules{
+								ASNs: []int{64496},
+							},
+						},
 					},
+				},
+				"default/geo-policy2": {
 					Spec: conf_v1.PolicySpec{
-						OIDC: &conf_v1.OIDC{
-							ClientSecret:  "oidc-secret",
-							AuthEndpoint:  "https://foo.com/auth",
-							TokenEndpoint: "https://foo.com/token",
-							JWKSURI:       "https://foo.com/certs",
-							ClientID:      "foo",
+						GeoAccess: &conf_v1.GeoAccess{
+							ASNDatabase: "/etc/nginx/geoip/GeoLite2-ASN.mmdb",
+							Deny: &conf_v1.GeoRules{
+								ASNs: []int{64497},
+							},
 						},
 					},
 				},
-				"default/oidc-policy2": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "oidc-policy2",
-						Namespace: "default",
+			},
+			policyOpts: policyOptions{},
+			expected: policiesCfg{
+				GeoAccess: &version2.GeoAccess{
+					DeniedVariable: "$pol_geo_denied_default_geo_policy_default_test",
+					RejectCode:     403,
+				},
+				GeoIP2: []version2.GeoIP2{
+					{
+						Database: "/etc/nginx/geoip/GeoLite2-ASN.mmdb",
+						Variable: "$pol_geo_asn_default_geo_policy_default_test",
+						Path:     "autonomous_system_number",
 					},
-					Spec: conf_v1.PolicySpec{
-						OIDC: &conf_v1.OIDC{
-							ClientSecret:  "oidc-secret",
-							AuthEndpoint:  "https://bar.com/auth",
-							TokenEndpoint: "https://bar.com/token",
-							JWKSURI:       "https://bar.com/certs",
-							ClientID:      "bar",
+				},
+				Maps: []version2.Map{
+					{
+						Source:   "$pol_geo_asn_default_geo_policy_default_test",
+						Variable: "$pol_geo_asn_default_geo_policy_default_test_match",
+						Parameters: []version2.Parameter{
+							{Value: "64496", Result: "1"},
+							{Value: "default", Result: `""`},
+						},
+					},
+					{
+						Source:   `"$pol_geo_asn_default_geo_policy_default_test_match"`,
+						Variable: "$pol_geo_denied_default_geo_policy_default_test",
+						Parameters: []version2.Parameter{
+							{Value: `""`, Result: "0"},
+							{Value: "default", Result: "1"},
 						},
 					},
 				},
 			},
-			policyOpts: policyOptions{
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/oidc-secret": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeOIDC,
-							Data: map[string][]byte{
-								"client-secret": []byte("super_secret_123"),
+			expectedWarnings: Warnings{
+				nil: {
+					`Multiple geoAccess policies in the same context is not valid. GeoAccess policy default/geo-policy2 will be ignored`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "multi geoAccess reference",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "headers-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "headers-policy2",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/headers-policy": {
+					Spec: conf_v1.PolicySpec{
+						Headers: &conf_v1.Headers{
+							SecurityHeaders: &conf_v1.SecurityHeaders{
+								FrameOptions: "DENY",
+							},
+						},
+					},
+				},
+				"default/headers-policy2": {
+					Spec: conf_v1.PolicySpec{
+						Headers: &conf_v1.Headers{
+							SecurityHeaders: &conf_v1.SecurityHeaders{
+								FrameOptions: "SAMEORIGIN",
 							},
 						},
 					},
 				},
 			},
-			context: "route",
+			policyOpts: policyOptions{},
 			expected: policiesCfg{
-				OIDC: true,
+				Headers: &conf_v1.Headers{
+					SecurityHeaders: &conf_v1.SecurityHeaders{
+						FrameOptions: "DENY",
+					},
+				},
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`Multiple oidc policies in the same context is not valid. OIDC policy default/oidc-policy2 will be ignored`,
+					`Multiple headers policies in the same context is not valid. Headers policy default/headers-policy2 will be ignored`,
 				},
 			},
-			expectedOidc: &oidcPolicyCfg{
-				&version2.OIDC{
-					AuthEndpoint:   "https://foo.com/auth",
-					TokenEndpoint:  "https://foo.com/token",
-					JwksURI:        "https://foo.com/certs",
-					ClientID:       "foo",
-					ClientSecret:   "super_secret_123",
-					RedirectURI:    "/_codexch",
-					Scope:          "openid",
-					ZoneSyncLeeway: 200,
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "multi headers reference",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "compression-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "compression-policy2",
+					Namespace: "default",
 				},
-				"default/oidc-policy",
 			},
-			msg: "multi oidc",
+			policies: map[string]*conf_v1.Policy{
+				"default/compression-policy": {
+					Spec: conf_v1.PolicySpec{
+						Compression: &conf_v1.Compression{
+							Algorithms: []string{"gzip"},
+						},
+					},
+				},
+				"default/compression-policy2": {
+					Spec: conf_v1.PolicySpec{
+						Compression: &conf_v1.Compression{
+							Algorithms: []string{"brotli"},
+						},
+					},
+				},
+			},
+			policyOpts: policyOptions{},
+			expected: policiesCfg{
+				Compression: &version2.Compression{
+					Gzip:        true,
+					GzipLevel:   1,
+					BrotliLevel: 6,
+					MinLength:   20,
+					Types:       "text/plain text/css text/xml application/javascript application/json application/xml",
+				},
+			},
+			expectedWarnings: Warnings{
+				nil: {
+					`Multiple compression policies in the same context is not valid. Compression policy default/compression-policy2 will be ignored`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "multi compression reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "waf-policy",
+					Name:      "connection-limit-policy",
 					Namespace: "default",
 				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/connection-limit-policy": {
+					Spec: conf_v1.PolicySpec{
+						ConnectionLimit: &conf_v1.ConnectionLimit{
+							Key:            "${binary_remote_addr}",
+							MaxConnections: 10,
+							ZoneSize:       "10M",
+						},
+					},
+				},
+			},
+			policyOpts: policyOptions{},
+			expected:   policiesCfg{},
+			expectedWarnings: Warnings{
+				nil: {
+					`Policy default/connection-limit-policy is supported only in TransportServers and will be ignored`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "connection limit reference",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "waf-policy2",
+					Name:      "ingress-mtls-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/waf-policy": {
+				"default/ingress-mtls-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "waf-policy",
+						Name:      "ingress-mtls-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						WAF: &conf_v1.WAF{
-							Enable:   true,
-							ApPolicy: "default/dataguard-alarm",
+						IngressMTLS: &conf_v1.IngressMTLS{
+							ClientCertSecret: "ingress-mtls-secret",
 						},
 					},
 				},
-				"default/waf-policy2": {
+			},
+			policyOpts: policyOptions{
+				tls: true,
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/ingress-mtls-secret": {
+						Error: errors.New("secret is invalid"),
+					},
+				},
+			},
+			context: "spec",
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
+				},
+			},
+			expectedWarnings: Warnings{
+				nil: {
+					`IngressMTLS policy "default/ingress-mtls-policy" references an invalid secret default/ingress-mtls-secret: secret is invalid`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "ingress mtls reference an invalid secret",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "ingress-mtls-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/ingress-mtls-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "waf-policy2",
+						Name:      "ingress-mtls-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						WAF: &conf_v1.WAF{
-							Enable:   true,
-							ApPolicy: "default/dataguard-alarm",
+						IngressMTLS: &conf_v1.IngressMTLS{
+							ClientCertSecret: "ingress-mtls-secret",
 						},
 					},
 				},
 			},
 			policyOpts: policyOptions{
-				apResources: &appProtectResourcesForVS{
-					Policies: map[string]string{
-						"default/dataguard-alarm": "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
-					},
-					LogConfs: map[string]string{
-						"default/logconf": "/etc/nginx/waf/nac-logconfs/default-logconf",
+				tls: true,
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/ingress-mtls-secret": {
+						Secret: &api_v1.Secret{
+							Type: api_v1.SecretTypeTLS,
+						},
 					},
 				},
 			},
-			context: "route",
+			context: "spec",
 			expected: policiesCfg{
-				WAF: &version2.WAF{
-					Enable:   "on",
-					ApPolicy: "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
+				ErrorReturn: &version2.Return{
+					Code: 500,
 				},
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`Multiple WAF policies in the same context is not valid. WAF policy default/waf-policy2 will be ignored`,
+					`IngressMTLS policy default/ingress-mtls-policy references a secret default/ingress-mtls-secret of a wrong type 'kubernetes.io/tls', must be 'nginx.org/ca'`,
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "multi waf",
+			msg:          "ingress mtls references wrong secret type",
 		},
-	}
-
-	for _, test := range tests {
-		vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{}, false)
-
-		if test.oidcPolCfg != nil {
-			vsc.oidcPolCfg = test.oidcPolCfg
-		}
-
-		result := vsc.generatePolicies(ownerDetails, test.policyRefs, test.policies, test.context, test.policyOpts)
-		if diff := cmp.Diff(test.expected, result); diff != "" {
-			t.Errorf("generatePolicies() '%v' mismatch (-want +got):\n%s", test.msg, diff)
-		}
-		if !reflect.DeepEqual(vsc.warnings, test.expectedWarnings) {
-			t.Errorf(
-				"generatePolicies() returned warnings of \n%v but expected \n%v for the case of %s",
-				vsc.warnings,
-				test.expectedWarnings,
-				test.msg,
-			)
-		}
-		if diff := cmp.Diff(test.expectedOidc.oidc, vsc.oidcPolCfg.oidc); diff != "" {
-			t.Errorf("generatePolicies() '%v' mismatch (-want +got):\n%s", test.msg, diff)
-		}
-		if diff := cmp.Diff(test.expectedOidc.key, vsc.oidcPolCfg.key); diff != "" {
-			t.Errorf("generatePolicies() '%v' mismatch (-want +got):\n%s", test.msg, diff)
-		}
-	}
-}
-
-func TestRemoveDuplicates(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		rlz      []version2.LimitReqZone
-		expected []version2.LimitReqZone
-	}{
 		{
-			rlz: []version2.LimitReqZone{
-				{ZoneName: "test"},
-				{ZoneName: "test"},
-				{ZoneName: "test2"},
-				{ZoneName: "test3"},
-			},
-			expected: []version2.LimitReqZone{
-				{ZoneName: "test"},
-				{ZoneName: "test2"},
-				{ZoneName: "test3"},
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "ingress-mtls-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "ingress-mtls-policy2",
+					Namespace: "default",
+				},
 			},
-		},
-		{
-			rlz: []version2.LimitReqZone{
-				{ZoneName: "test"},
-				{ZoneName: "test"},
-				{ZoneName: "test2"},
-				{ZoneName: "test3"},
-				{ZoneName: "test3"},
+			policies: map[string]*conf_v1.Policy{
+				"default/ingress-mtls-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "ingress-mtls-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						IngressMTLS: &conf_v1.IngressMTLS{
+							ClientCertSecret: "ingress-mtls-secret",
+						},
+					},
+				},
+				"default/ingress-mtls-policy2": {
+					Spec: conf_v1.PolicySpec{
+						IngressMTLS: &conf_v1.IngressMTLS{
+							ClientCertSecret: "ingress-mtls-secret2",
+						},
+					},
+				},
 			},
-			expected: []version2.LimitReqZone{
-				{ZoneName: "test"},
-				{ZoneName: "test2"},
-				{ZoneName: "test3"},
+			policyOpts: policyOptions{
+				tls: true,
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/ingress-mtls-secret": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeCA,
+						},
+						Path: "/etc/nginx/secrets/default-ingress-mtls-secret",
+					},
+				},
 			},
-		},
-	}
-	for _, test := range tests {
-		result := removeDuplicateLimitReqZones(test.rlz)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("removeDuplicateLimitReqZones() returned \n%v, but expected \n%v", result, test.expected)
-		}
-	}
-}
-
-func TestAddPoliciesCfgToLocations(t *testing.T) {
-	t.Parallel()
-	cfg := policiesCfg{
-		Allow: []string{"127.0.0.1"},
-		Deny:  []string{"127.0.0.2"},
-		ErrorReturn: &version2.Return{
-			Code: 400,
-		},
-	}
-
-	locations := []version2.Location{
-		{
-			Path: "/",
-		},
-	}
-
-	expectedLocations := []version2.Location{
-		{
-			Path:  "/",
-			Allow: []string{"127.0.0.1"},
-			Deny:  []string{"127.0.0.2"},
-			PoliciesErrorReturn: &version2.Return{
-				Code: 400,
+			context: "spec",
+			expected: policiesCfg{
+				IngressMTLS: &version2.IngressMTLS{
+					ClientCert:   "/etc/nginx/secrets/default-ingress-mtls-secret",
+					VerifyClient: "on",
+					VerifyDepth:  1,
+				},
 			},
-		},
-	}
-
-	addPoliciesCfgToLocations(cfg, locations)
-	if !reflect.DeepEqual(locations, expectedLocations) {
-		t.Errorf("addPoliciesCfgToLocations() returned \n%+v but expected \n%+v", locations, expectedLocations)
-	}
-}
-
-func TestGenerateUpstream(t *testing.T) {
-	t.Parallel()
-	name := "test-upstream"
-	upstream := conf_v1.Upstream{Service: name, Port: 80}
-	endpoints := []string{
-		"192.168.10.10:8080",
-	}
-	cfgParams := ConfigParams{
-		LBMethod:         "random",
-		MaxFails:         1,
-		MaxConns:         0,
-		FailTimeout:      "10s",
-		Keepalive:        21,
-		UpstreamZoneSize: "256k",
-	}
-
-	expected := version2.Upstream{
-		Name: "test-upstream",
-		UpstreamLabels: version2.UpstreamLabels{
-			Service: "test-upstream",
-		},
-		Servers: []version2.UpstreamServer{
-			{
-				Address: "192.168.10.10:8080",
+			expectedWarnings: Warnings{
+				nil: {
+					`Multiple ingressMTLS policies are not allowed. IngressMTLS policy default/ingress-mtls-policy2 will be ignored`,
+				},
 			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "multi ingress mtls",
 		},
-		MaxFails:         1,
-		MaxConns:         0,
-		FailTimeout:      "10s",
-		LBMethod:         "random",
-		Keepalive:        21,
-		UpstreamZoneSize: "256k",
-	}
-
-	vsc := newVirtualServerConfigurator(&cfgParams, false, false, &StaticConfigParams{}, false)
-	result := vsc.generateUpstream(nil, name, upstream, false, endpoints)
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("generateUpstream() returned %v but expected %v", result, expected)
-	}
-
-	if len(vsc.warnings) != 0 {
-		t.Errorf("generateUpstream returned warnings for %v", upstream)
-	}
-}
-
-func TestGenerateUpstreamWithKeepalive(t *testing.T) {
-	t.Parallel()
-	name := "test-upstream"
-	noKeepalive := 0
-	keepalive := 32
-	endpoints := []string{
-		"192.168.10.10:8080",
-	}
-
-	tests := []struct {
-		upstream  conf_v1.Upstream
-		cfgParams *ConfigParams
-		expected  version2.Upstream
-		msg       string
-	}{
 		{
-			conf_v1.Upstream{Keepalive: &keepalive, Service: name, Port: 80},
-			&ConfigParams{Keepalive: 21},
-			version2.Upstream{
-				Name: "test-upstream",
-				UpstreamLabels: version2.UpstreamLabels{
-					Service: "test-upstream",
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "ingress-mtls-policy",
+					Namespace: "default",
 				},
-				Servers: []version2.UpstreamServer{
-					{
-						Address: "192.168.10.10:8080",
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/ingress-mtls-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "ingress-mtls-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						IngressMTLS: &conf_v1.IngressMTLS{
+							ClientCertSecret: "ingress-mtls-secret",
+						},
 					},
 				},
-				Keepalive: 32,
 			},
-			"upstream keepalive set, configparam set",
-		},
-		{
-			conf_v1.Upstream{Service: name, Port: 80},
-			&ConfigParams{Keepalive: 21},
-			version2.Upstream{
-				Name: "test-upstream",
-				UpstreamLabels: version2.UpstreamLabels{
-					Service: "test-upstream",
-				},
-				Servers: []version2.UpstreamServer{
-					{
-						Address: "192.168.10.10:8080",
+			policyOpts: policyOptions{
+				tls: true,
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/ingress-mtls-secret": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeCA,
+						},
+						Path: "/etc/nginx/secrets/default-ingress-mtls-secret",
 					},
 				},
-				Keepalive: 21,
 			},
-			"upstream keepalive not set, configparam set",
-		},
-		{
-			conf_v1.Upstream{Keepalive: &noKeepalive, Service: name, Port: 80},
-			&ConfigParams{Keepalive: 21},
-			version2.Upstream{
-				Name: "test-upstream",
-				UpstreamLabels: version2.UpstreamLabels{
-					Service: "test-upstream",
+			context: "route",
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
 				},
-				Servers: []version2.UpstreamServer{
-					{
-						Address: "192.168.10.10:8080",
-					},
+			},
+			expectedWarnings: Warnings{
+				nil: {
+					`IngressMTLS policy default/ingress-mtls-policy is not allowed in the route context`,
 				},
 			},
-			"upstream keepalive set to 0, configparam set",
-		},
-	}
-
-	for _, test := range tests {
-		vsc := newVirtualServerConfigurator(test.cfgParams, false, false, &StaticConfigParams{}, false)
-		result := vsc.generateUpstream(nil, name, test.upstream, false, endpoints)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateUpstream() returned %v but expected %v for the case of %v", result, test.expected, test.msg)
-		}
-
-		if len(vsc.warnings) != 0 {
-			t.Errorf("generateUpstream() returned warnings for %v", test.upstream)
-		}
-	}
-}
-
-func TestGenerateUpstreamForExternalNameService(t *testing.T) {
-	t.Parallel()
-	name := "test-upstream"
-	endpoints := []string{"example.com"}
-	upstream := conf_v1.Upstream{Service: name}
-	cfgParams := ConfigParams{}
-
-	expected := version2.Upstream{
-		Name: name,
-		UpstreamLabels: version2.UpstreamLabels{
-			Service: "test-upstream",
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "ingress mtls in the wrong context",
 		},
-		Servers: []version2.UpstreamServer{
-			{
-				Address: "example.com",
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "ingress-mtls-policy",
+					Namespace: "default",
+				},
 			},
-		},
-		Resolve: true,
-	}
-
-	vsc := newVirtualServerConfigurator(&cfgParams, true, true, &StaticConfigParams{}, false)
-	result := vsc.generateUpstream(nil, name, upstream, true, endpoints)
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("generateUpstream() returned %v but expected %v", result, expected)
-	}
-
-	if len(vsc.warnings) != 0 {
-		t.Errorf("generateUpstream() returned warnings for %v", upstream)
-	}
-}
-
-func TestGenerateUpstreamWithNTLM(t *testing.T) {
-	t.Parallel()
-	name := "test-upstream"
-	upstream := conf_v1.Upstream{Service: name, Port: 80, NTLM: true}
-	endpoints := []string{
-		"192.168.10.10:8080",
-	}
-	cfgParams := ConfigParams{
-		LBMethod:         "random",
-		MaxFails:         1,
-		MaxConns:         0,
-		FailTimeout:      "10s",
-		Keepalive:        21,
-		UpstreamZoneSize: "256k",
-	}
-
-	expected := version2.Upstream{
-		Name: "test-upstream",
-		UpstreamLabels: version2.UpstreamLabels{
-			Service: "test-upstream",
-		},
-		Servers: []version2.UpstreamServer{
-			{
-				Address: "192.168.10.10:8080",
+			policies: map[string]*conf_v1.Policy{
+				"default/ingress-mtls-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "ingress-mtls-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						IngressMTLS: &conf_v1.IngressMTLS{
+							ClientCertSecret: "ingress-mtls-secret",
+						},
+					},
+				},
 			},
-		},
-		MaxFails:         1,
-		MaxConns:         0,
-		FailTimeout:      "10s",
-		LBMethod:         "random",
-		Keepalive:        21,
-		UpstreamZoneSize: "256k",
-		NTLM:             true,
-	}
-
-	vsc := newVirtualServerConfigurator(&cfgParams, true, false, &StaticConfigParams{}, false)
-	result := vsc.generateUpstream(nil, name, upstream, false, endpoints)
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("generateUpstream() returned %v but expected %v", result, expected)
-	}
-
-	if len(vsc.warnings) != 0 {
-		t.Errorf("generateUpstream returned warnings for %v", upstream)
-	}
-}
-
-func TestGenerateProxyPass(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		tlsEnabled   bool
-		upstreamName string
-		internal     bool
-		expected     string
-	}{
-		{
-			tlsEnabled:   false,
-			upstreamName: "test-upstream",
-			internal:     false,
-			expected:     "http://test-upstream",
-		},
-		{
-			tlsEnabled:   true,
-			upstreamName: "test-upstream",
-			internal:     false,
-			expected:     "https://test-upstream",
-		},
-		{
-			tlsEnabled:   false,
-			upstreamName: "test-upstream",
-			internal:     true,
-			expected:     "http://test-upstream$request_uri",
-		},
-		{
-			tlsEnabled:   true,
-			upstreamName: "test-upstream",
-			internal:     true,
-			expected:     "https://test-upstream$request_uri",
-		},
-	}
-
-	for _, test := range tests {
-		result := generateProxyPass(test.tlsEnabled, test.upstreamName, test.internal, nil)
-		if result != test.expected {
-			t.Errorf("generateProxyPass(%v, %v, %v) returned %v but expected %v", test.tlsEnabled, test.upstreamName, test.internal, result, test.expected)
-		}
-	}
-}
-
-func TestGenerateProxyPassProtocol(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		upstream conf_v1.Upstream
-		expected string
-	}{
-		{
-			upstream: conf_v1.Upstream{},
-			expected: "http",
-		},
-		{
-			upstream: conf_v1.Upstream{
-				TLS: conf_v1.UpstreamTLS{
-					Enable: true,
+			policyOpts: policyOptions{
+				tls: false,
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/ingress-mtls-secret": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeCA,
+						},
+						Path: "/etc/nginx/secrets/default-ingress-mtls-secret",
+					},
 				},
 			},
-			expected: "https",
-		},
-	}
-
-	for _, test := range tests {
-		result := generateProxyPassProtocol(test.upstream.TLS.Enable)
-		if result != test.expected {
-			t.Errorf("generateProxyPassProtocol(%v) returned %v but expected %v", test.upstream.TLS.Enable, result, test.expected)
-		}
-	}
-}
-
-func TestGenerateGRPCPass(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		grpcEnabled  bool
-		tlsEnabled   bool
-		upstreamName string
-		expected     string
-	}{
-		{
-			grpcEnabled:  false,
-			tlsEnabled:   false,
-			upstreamName: "test-upstream",
-			expected:     "",
-		},
-		{
-			grpcEnabled:  true,
-			tlsEnabled:   false,
-			upstreamName: "test-upstream",
-			expected:     "grpc://test-upstream",
-		},
-		{
-			grpcEnabled:  true,
-			tlsEnabled:   true,
-			upstreamName: "test-upstream",
-			expected:     "grpcs://test-upstream",
-		},
-	}
-
-	for _, test := range tests {
-		result := generateGRPCPass(test.grpcEnabled, test.tlsEnabled, test.upstreamName)
-		if result != test.expected {
-			t.Errorf("generateGRPCPass(%v, %v, %v) returned %v but expected %v", test.grpcEnabled, test.tlsEnabled, test.upstreamName, result, test.expected)
-		}
-	}
-}
-
-func TestGenerateGRPCPassProtocol(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		upstream conf_v1.Upstream
-		expected string
-	}{
-		{
-			upstream: conf_v1.Upstream{},
-			expected: "grpc",
-		},
-		{
-			upstream: conf_v1.Upstream{
-				TLS: conf_v1.UpstreamTLS{
-					Enable: true,
+			context: "route",
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
 				},
 			},
-			expected: "grpcs",
-		},
-	}
-
-	for _, test := range tests {
-		result := generateGRPCPassProtocol(test.upstream.TLS.Enable)
-		if result != test.expected {
-			t.Errorf("generateGRPCPassProtocol(%v) returned %v but expected %v", test.upstream.TLS.Enable, result, test.expected)
-		}
-	}
-}
-
-func TestGenerateString(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		inputS   string
-		expected string
-	}{
-		{
-			inputS:   "http_404",
-			expected: "http_404",
+			expectedWarnings: Warnings{
+				nil: {
+					`TLS must be enabled in VirtualServer for IngressMTLS policy default/ingress-mtls-policy`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "ingress mtls missing TLS config",
 		},
 		{
-			inputS:   "",
-			expected: "error timeout",
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "egress-mtls-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "egress-mtls-policy2",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/egress-mtls-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "egress-mtls-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						EgressMTLS: &conf_v1.EgressMTLS{
+							TLSSecret: "egress-mtls-secret",
+						},
+					},
+				},
+				"default/egress-mtls-policy2": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "egress-mtls-policy2",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						EgressMTLS: &conf_v1.EgressMTLS{
+							TLSSecret: "egress-mtls-secret2",
+						},
+					},
+				},
+			},
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/egress-mtls-secret": {
+						Secret: &api_v1.Secret{
+							Type: api_v1.SecretTypeTLS,
+						},
+						Path: "/etc/nginx/secrets/default-egress-mtls-secret",
+					},
+				},
+			},
+			context: "route",
+			expected: policiesCfg{
+				EgressMTLS: &version2.EgressMTLS{
+					Certificate:    "/etc/nginx/secrets/default-egress-mtls-secret",
+					CertificateKey: "/etc/nginx/secrets/default-egress-mtls-secret",
+					VerifyServer:   false,
+					VerifyDepth:    1,
+					Ciphers:        "DEFAULT",
+					Protocols:      "TLSv1 TLSv1.1 TLSv1.2",
+					SessionReuse:   true,
+					SSLName:        "$proxy_host",
+				},
+			},
+			expectedWarnings: Warnings{
+				nil: {
+					`Multiple egressMTLS policies in the same context is not valid. EgressMTLS policy default/egress-mtls-policy2 will be ignored`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "multi egress mtls",
 		},
-	}
-
-	for _, test := range tests {
-		result := generateString(test.inputS, "error timeout")
-		if result != test.expected {
-			t.Errorf("generateString() return %v but expected %v", result, test.expected)
-		}
-	}
-}
-
-func TestGenerateSnippets(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		enableSnippets bool
-		s              string
-		defaultS       []string
-		expected       []string
-	}{
 		{
-			true,
-			"test",
-			[]string{},
-			[]string{"test"},
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "egress-mtls-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/egress-mtls-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "egress-mtls-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						EgressMTLS: &conf_v1.EgressMTLS{
+							TrustedCertSecret: "egress-trusted-secret",
+							SSLName:           "foo.com",
+						},
+					},
+				},
+			},
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/egress-trusted-secret": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeCA,
+						},
+						Error: errors.New("secret is invalid"),
+					},
+				},
+			},
+			context: "route",
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
+				},
+			},
+			expectedWarnings: Warnings{
+				nil: {
+					`EgressMTLS policy default/egress-mtls-policy references an invalid secret default/egress-trusted-secret: secret is invalid`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "egress mtls referencing an invalid CA secret",
 		},
 		{
-			true,
-			"",
-			[]string{"default"},
-			[]string{"default"},
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "egress-mtls-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/egress-mtls-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "egress-mtls-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						EgressMTLS: &conf_v1.EgressMTLS{
+							TLSSecret: "egress-mtls-secret",
+							SSLName:   "foo.com",
+						},
+					},
+				},
+			},
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/egress-mtls-secret": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeCA,
+						},
+					},
+				},
+			},
+			context: "route",
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
+				},
+			},
+			expectedWarnings: Warnings{
+				nil: {
+					`EgressMTLS policy default/egress-mtls-policy references a secret default/egress-mtls-secret of a wrong type 'nginx.org/ca', must be 'kubernetes.io/tls'`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "egress mtls referencing wrong secret type",
 		},
 		{
-			true,
-			"test\none\ntwo",
-			[]string{},
-			[]string{"test", "one", "two"},
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "egress-mtls-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/egress-mtls-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "egress-mtls-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						EgressMTLS: &conf_v1.EgressMTLS{
+							TrustedCertSecret: "egress-trusted-secret",
+							SSLName:           "foo.com",
+						},
+					},
+				},
+			},
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/egress-trusted-secret": {
+						Secret: &api_v1.Secret{
+							Type: api_v1.SecretTypeTLS,
+						},
+					},
+				},
+			},
+			context: "route",
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
+				},
+			},
+			expectedWarnings: Warnings{
+				nil: {
+					`EgressMTLS policy default/egress-mtls-policy references a secret default/egress-trusted-secret of a wrong type 'kubernetes.io/tls', must be 'nginx.org/ca'`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "egress trusted secret referencing wrong secret type",
 		},
 		{
-			false,
-			"test",
-			nil,
-			nil,
-		},
-	}
-	for _, test := range tests {
-		result := generateSnippets(test.enableSnippets, test.s, test.defaultS)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateSnippets() return %v, but expected %v", result, test.expected)
-		}
-	}
-}
-
-func TestGenerateBuffer(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		inputS   *conf_v1.UpstreamBuffers
-		expected string
-	}{
-		{
-			inputS:   nil,
-			expected: "8 4k",
-		},
-		{
-			inputS:   &conf_v1.UpstreamBuffers{Number: 8, Size: "16K"},
-			expected: "8 16K",
-		},
-	}
-
-	for _, test := range tests {
-		result := generateBuffers(test.inputS, "8 4k")
-		if result != test.expected {
-			t.Errorf("generateBuffer() return %v but expected %v", result, test.expected)
-		}
-	}
-}
-
-func TestGenerateLocationForProxying(t *testing.T) {
-	t.Parallel()
-	cfgParams := ConfigParams{
-		ProxyConnectTimeout:  "30s",
-		ProxyReadTimeout:     "31s",
-		ProxySendTimeout:     "32s",
-		ClientMaxBodySize:    "1m",
-		ProxyMaxTempFileSize: "1024m",
-		ProxyBuffering:       true,
-		ProxyBuffers:         "8 4k",
-		ProxyBufferSize:      "4k",
-		LocationSnippets:     []string{"# location snippet"},
-	}
-	path := "/"
-	upstreamName := "test-upstream"
-	vsLocSnippets := []string{"# vs location snippet"}
-
-	expected := version2.Location{
-		Path:                     "/",
-		Snippets:                 vsLocSnippets,
-		ProxyConnectTimeout:      "30s",
-		ProxyReadTimeout:         "31s",
-		ProxySendTimeout:         "32s",
-		ClientMaxBodySize:        "1m",
-		ProxyMaxTempFileSize:     "1024m",
-		ProxyBuffering:           true,
-		ProxyBuffers:             "8 4k",
-		ProxyBufferSize:          "4k",
-		ProxyPass:                "http://test-upstream",
-		ProxyNextUpstream:        "error timeout",
-		ProxyNextUpstreamTimeout: "0s",
-		ProxyNextUpstreamTries:   0,
-		ProxyPassRequestHeaders:  true,
-		ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-		ServiceName:              "",
-		IsVSR:                    false,
-		VSRName:                  "",
-		VSRNamespace:             "",
-	}
-
-	result := generateLocationForProxying(path, upstreamName, conf_v1.Upstream{}, &cfgParams, nil, false, 0, "", nil, "", vsLocSnippets, false, "", "")
-	if diff := cmp.Diff(expected, result); diff != "" {
-		t.Errorf("generateLocationForProxying() mismatch (-want +got):\n%s", diff)
-	}
-}
-
-func TestGenerateLocationForGrpcProxying(t *testing.T) {
-	t.Parallel()
-	cfgParams := ConfigParams{
-		ProxyConnectTimeout:  "30s",
-		ProxyReadTimeout:     "31s",
-		ProxySendTimeout:     "32s",
-		ClientMaxBodySize:    "1m",
-		ProxyMaxTempFileSize: "1024m",
-		ProxyBuffering:       true,
-		ProxyBuffers:         "8 4k",
-		ProxyBufferSize:      "4k",
-		LocationSnippets:     []string{"# location snippet"},
-		HTTP2:                true,
-	}
-	path := "/"
-	upstreamName := "test-upstream"
-	vsLocSnippets := []string{"# vs location snippet"}
-
-	expected := version2.Location{
-		Path:                     "/",
-		Snippets:                 vsLocSnippets,
-		ProxyConnectTimeout:      "30s",
-		ProxyReadTimeout:         "31s",
-		ProxySendTimeout:         "32s",
-		ClientMaxBodySize:        "1m",
-		ProxyMaxTempFileSize:     "1024m",
-		ProxyBuffering:           true,
-		ProxyBuffers:             "8 4k",
-		ProxyBufferSize:          "4k",
-		ProxyPass:                "http://test-upstream",
-		ProxyNextUpstream:        "error timeout",
-		ProxyNextUpstreamTimeout: "0s",
-		ProxyNextUpstreamTries:   0,
-		ProxyPassRequestHeaders:  true,
-		ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-		GRPCPass:                 "grpc://test-upstream",
-	}
-
-	result := generateLocationForProxying(path, upstreamName, conf_v1.Upstream{Type: "grpc"}, &cfgParams, nil, false, 0, "", nil, "", vsLocSnippets, false, "", "")
-	if diff := cmp.Diff(expected, result); diff != "" {
-		t.Errorf("generateLocationForForGrpcProxying() mismatch (-want +got):\n%s", diff)
-	}
-}
-
-func TestGenerateReturnBlock(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		text        string
-		code        int
-		defaultCode int
-		expected    *version2.Return
-	}{
-		{
-			text:        "Hello World!",
-			code:        0, // Not set
-			defaultCode: 200,
-			expected: &version2.Return{
-				Code: 200,
-				Text: "Hello World!",
-			},
-		},
-		{
-			text:        "Hello World!",
-			code:        400,
-			defaultCode: 200,
-			expected: &version2.Return{
-				Code: 400,
-				Text: "Hello World!",
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "egress-mtls-policy",
+					Namespace: "default",
+				},
 			},
-		},
-	}
-
-	for _, test := range tests {
-		result := generateReturnBlock(test.text, test.code, test.defaultCode)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateReturnBlock() returned %v but expected %v", result, test.expected)
-		}
-	}
-}
-
-func TestGenerateLocationForReturn(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		actionReturn           *conf_v1.ActionReturn
-		expectedLocation       version2.Location
-		expectedReturnLocation *version2.ReturnLocation
-		msg                    string
-	}{
-		{
-			actionReturn: &conf_v1.ActionReturn{
-				Body: "hello",
+			policies: map[string]*conf_v1.Policy{
+				"default/egress-mtls-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "egress-mtls-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						EgressMTLS: &conf_v1.EgressMTLS{
+							TLSSecret: "egress-mtls-secret",
+							SSLName:   "foo.com",
+						},
+					},
+				},
 			},
-
-			expectedLocation: version2.Location{
-				Path:     "/",
-				Snippets: []string{"# location snippet"},
-				ErrorPages: []version2.ErrorPage{
-					{
-						Name:         "@return_1",
-						Codes:        "418",
-						ResponseCode: 200,
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/egress-mtls-secret": {
+						Secret: &api_v1.Secret{
+							Type: api_v1.SecretTypeTLS,
+						},
+						Error: errors.New("secret is invalid"),
 					},
 				},
-				ProxyInterceptErrors: true,
-				InternalProxyPass:    "http://unix:/var/lib/nginx/nginx-418-server.sock",
 			},
-			expectedReturnLocation: &version2.ReturnLocation{
-				Name:        "@return_1",
-				DefaultType: "text/plain",
-				Return: version2.Return{
-					Code: 0,
-					Text: "hello",
+			context: "route",
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
 				},
 			},
-			msg: "return without code and type",
+			expectedWarnings: Warnings{
+				nil: {
+					`EgressMTLS policy default/egress-mtls-policy references an invalid secret default/egress-mtls-secret: secret is invalid`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "egress mtls referencing missing tls secret",
 		},
 		{
-			actionReturn: &conf_v1.ActionReturn{
-				Code: 400,
-				Type: "text/html",
-				Body: "hello",
-			},
-
-			expectedLocation: version2.Location{
-				Path:     "/",
-				Snippets: []string{"# location snippet"},
-				ErrorPages: []version2.ErrorPage{
-					{
-						Name:         "@return_1",
-						Codes:        "418",
-						ResponseCode: 400,
-					},
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "oidc-policy",
+					Namespace: "default",
 				},
-				ProxyInterceptErrors: true,
-				InternalProxyPass:    "http://unix:/var/lib/nginx/nginx-418-server.sock",
 			},
-			expectedReturnLocation: &version2.ReturnLocation{
-				Name:        "@return_1",
-				DefaultType: "text/html",
-				Return: version2.Return{
-					Code: 0,
-					Text: "hello",
+			policies: map[string]*conf_v1.Policy{
+				"default/oidc-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "oidc-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						OIDC: &conf_v1.OIDC{
+							ClientSecret: "oidc-secret",
+						},
+					},
 				},
 			},
-			msg: "return with all fields defined",
-		},
-	}
-	path := "/"
-	snippets := []string{"# location snippet"}
-	returnLocationIndex := 1
-
-	for _, test := range tests {
-		location, returnLocation := generateLocationForReturn(path, snippets, test.actionReturn, returnLocationIndex)
-		if !reflect.DeepEqual(location, test.expectedLocation) {
-			t.Errorf("generateLocationForReturn() returned  \n%+v but expected \n%+v for the case of %s",
-				location, test.expectedLocation, test.msg)
-		}
-		if !reflect.DeepEqual(returnLocation, test.expectedReturnLocation) {
-			t.Errorf("generateLocationForReturn() returned  \n%+v but expected \n%+v for the case of %s",
-				returnLocation, test.expectedReturnLocation, test.msg)
-		}
-	}
-}
-
-func TestGenerateLocationForRedirect(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		redirect *conf_v1.ActionRedirect
-		expected version2.Location
-		msg      string
-	}{
-		{
-			redirect: &conf_v1.ActionRedirect{
-				URL: "http://nginx.org",
-			},
-
-			expected: version2.Location{
-				Path:     "/",
-				Snippets: []string{"# location snippet"},
-				ErrorPages: []version2.ErrorPage{
-					{
-						Name:         "http://nginx.org",
-						Codes:        "418",
-						ResponseCode: 301,
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/oidc-secret": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeOIDC,
+						},
+						Error: errors.New("secret is invalid"),
 					},
 				},
-				ProxyInterceptErrors: true,
-				InternalProxyPass:    "http://unix:/var/lib/nginx/nginx-418-server.sock",
 			},
-			msg: "redirect without code",
-		},
-		{
-			redirect: &conf_v1.ActionRedirect{
-				Code: 302,
-				URL:  "http://nginx.org",
-			},
-
-			expected: version2.Location{
-				Path:     "/",
-				Snippets: []string{"# location snippet"},
-				ErrorPages: []version2.ErrorPage{
-					{
-						Name:         "http://nginx.org",
-						Codes:        "418",
-						ResponseCode: 302,
-					},
+			context: "route",
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
 				},
-				ProxyInterceptErrors: true,
-				InternalProxyPass:    "http://unix:/var/lib/nginx/nginx-418-server.sock",
 			},
-			msg: "redirect with all fields defined",
-		},
-	}
-
-	for _, test := range tests {
-		result := generateLocationForRedirect("/", []string{"# location snippet"}, test.redirect)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateLocationForReturn() returned \n%+v but expected \n%+v for the case of %s",
-				result, test.expected, test.msg)
-		}
-	}
-}
-
-func TestGenerateSSLConfig(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		inputTLS         *conf_v1.TLS
-		inputSecretRefs  map[string]*secrets.SecretReference
-		inputCfgParams   *ConfigParams
-		wildcard         bool
-		expectedSSL      *version2.SSL
-		expectedWarnings Warnings
-		msg              string
-	}{
-		{
-			inputTLS:         nil,
-			inputSecretRefs:  map[string]*secrets.SecretReference{},
-			inputCfgParams:   &ConfigParams{},
-			wildcard:         false,
-			expectedSSL:      nil,
-			expectedWarnings: Warnings{},
-			msg:              "no TLS field",
-		},
-		{
-			inputTLS: &conf_v1.TLS{
-				Secret: "",
+			expectedWarnings: Warnings{
+				nil: {
+					`OIDC policy default/oidc-policy references an invalid secret default/oidc-secret: secret is invalid`,
+				},
 			},
-			inputSecretRefs:  map[string]*secrets.SecretReference{},
-			inputCfgParams:   &ConfigParams{},
-			wildcard:         false,
-			expectedSSL:      nil,
-			expectedWarnings: Warnings{},
-			msg:              "TLS field with empty secret and wildcard cert disabled",
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "oidc referencing missing oidc secret",
 		},
 		{
-			inputTLS: &conf_v1.TLS{
-				Secret: "",
-			},
-			inputSecretRefs: map[string]*secrets.SecretReference{},
-			inputCfgParams:  &ConfigParams{},
-			wildcard:        true,
-			expectedSSL: &version2.SSL{
-				HTTP2:           false,
-				Certificate:     pemFileNameForWildcardTLSSecret,
-				CertificateKey:  pemFileNameForWildcardTLSSecret,
-				RejectHandshake: false,
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "oidc-policy",
+					Namespace: "default",
+				},
 			},
-			expectedWarnings: Warnings{},
-			msg:              "TLS field with empty secret and wildcard cert enabled",
-		},
-		{
-			inputTLS: &conf_v1.TLS{
-				Secret: "missing",
+			policies: map[string]*conf_v1.Policy{
+				"default/oidc-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "oidc-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						OIDC: &conf_v1.OIDC{
+							ClientSecret:  "oidc-secret",
+							AuthEndpoint:  "http://foo.com/bar",
+							TokenEndpoint: "http://foo.com/bar",
+							JWKSURI:       "http://foo.com/bar",
+						},
+					},
+				},
 			},
-			inputCfgParams: &ConfigParams{},
-			wildcard:       false,
-			inputSecretRefs: map[string]*secrets.SecretReference{
-				"default/missing": {
-					Error: errors.New("missing doesn't exist"),
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/oidc-secret": {
+						Secret: &api_v1.Secret{
+							Type: api_v1.SecretTypeTLS,
+						},
+					},
 				},
 			},
-			expectedSSL: &version2.SSL{
-				HTTP2:           false,
-				RejectHandshake: true,
+			context: "spec",
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
+				},
 			},
 			expectedWarnings: Warnings{
-				nil: []string{"TLS secret missing is invalid: missing doesn't exist"},
+				nil: {
+					`OIDC policy default/oidc-policy references a secret default/oidc-secret of a wrong type 'kubernetes.io/tls', must be 'nginx.org/oidc'`,
+				},
 			},
-			msg: "missing doesn't exist in the cluster with HTTPS",
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "oidc secret referencing wrong secret type",
 		},
 		{
-			inputTLS: &conf_v1.TLS{
-				Secret: "mistyped",
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "oidc-policy-2",
+					Namespace: "default",
+				},
 			},
-			inputCfgParams: &ConfigParams{},
-			wildcard:       false,
-			inputSecretRefs: map[string]*secrets.SecretReference{
-				"default/mistyped": {
-					Secret: &api_v1.Secret{
-						Type: secrets.SecretTypeCA,
+			policies: map[string]*conf_v1.Policy{
+				"default/oidc-policy-1": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "oidc-policy-1",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						OIDC: &conf_v1.OIDC{
+							ClientID:      "foo",
+							ClientSecret:  "oidc-secret",
+							AuthEndpoint:  "https://foo.com/auth",
+							TokenEndpoint: "https://foo.com/token",
+							JWKSURI:       "https://foo.com/certs",
+						},
+					},
+				},
+				"default/oidc-policy-2": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "oidc-policy-2",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						OIDC: &conf_v1.OIDC{
+							ClientID:      "foo",
+							ClientSecret:  "oidc-secret",
+							AuthEndpoint:  "https://bar.com/auth",
+							TokenEndpoint: "https://bar.com/token",
+							JWKSURI:       "https://bar.com/certs",
+						},
 					},
 				},
 			},
-			expectedSSL: &version2.SSL{
-				HTTP2:           false,
-				RejectHandshake: true,
-			},
-			expectedWarnings: Warnings{
-				nil: []string{"TLS secret mistyped is of a wrong type 'nginx.org/ca', must be 'kubernetes.io/tls'"},
-			},
-			msg: "wrong secret type",
-		},
-		{
-			inputTLS: &conf_v1.TLS{
-				Secret: "secret",
-			},
-			inputSecretRefs: map[string]*secrets.SecretReference{
-				"default/secret": {
-					Secret: &api_v1.Secret{
-						Type: api_v1.SecretTypeTLS,
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/oidc-secret": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeOIDC,
+							Data: map[string][]byte{
+								"client-secret": []byte("super_secret_123"),
+							},
+						},
 					},
-					Path: "secret.pem",
 				},
 			},
-			inputCfgParams: &ConfigParams{},
-			wildcard:       false,
-			expectedSSL: &version2.SSL{
-				HTTP2:           false,
-				Certificate:     "secret.pem",
-				CertificateKey:  "secret.pem",
-				RejectHandshake: false,
-			},
-			expectedWarnings: Warnings{},
-			msg:              "normal case with HTTPS",
-		},
-	}
-
-	namespace := "default"
-
-	for _, test := range tests {
-		vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{}, test.wildcard)
-
-		// it is ok to use nil as the owner
-		result := vsc.generateSSLConfig(nil, test.inputTLS, namespace, test.inputSecretRefs, test.inputCfgParams)
-		if !reflect.DeepEqual(result, test.expectedSSL) {
-			t.Errorf("generateSSLConfig() returned %v but expected %v for the case of %s", result, test.expectedSSL, test.msg)
-		}
-		if !reflect.DeepEqual(vsc.warnings, test.expectedWarnings) {
-			t.Errorf("generateSSLConfig() returned warnings of \n%v but expected \n%v for the case of %s", vsc.warnings, test.expectedWarnings, test.msg)
-		}
-	}
-}
-
-func TestGenerateRedirectConfig(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		inputTLS *conf_v1.TLS
-		expected *version2.TLSRedirect
-		msg      string
-	}{
-		{
-			inputTLS: nil,
-			expected: nil,
-			msg:      "no TLS field",
-		},
-		{
-			inputTLS: &conf_v1.TLS{
-				Secret:   "secret",
-				Redirect: nil,
-			},
-			expected: nil,
-			msg:      "no redirect field",
-		},
-		{
-			inputTLS: &conf_v1.TLS{
-				Secret:   "secret",
-				Redirect: &conf_v1.TLSRedirect{Enable: false},
-			},
-			expected: nil,
-			msg:      "redirect disabled",
-		},
-		{
-			inputTLS: &conf_v1.TLS{
-				Secret: "secret",
-				Redirect: &conf_v1.TLSRedirect{
-					Enable: true,
+			context: "route",
+			oidcPolCfg: &oidcPolicyCfg{
+				oidc: &version2.OIDC{
+					AuthEndpoint:   "https://foo.com/auth",
+					TokenEndpoint:  "https://foo.com/token",
+					JwksURI:        "https://foo.com/certs",
+					ClientID:       "foo",
+					ClientSecret:   "super_secret_123",
+					RedirectURI:    "/_codexch",
+					Scope:          "openid",
+					ZoneSyncLeeway: 0,
 				},
+				key: "default/oidc-policy-1",
 			},
-			expected: &version2.TLSRedirect{
-				Code:    301,
-				BasedOn: "$scheme",
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
+				},
 			},
-			msg: "normal case with defaults",
-		},
-		{
-			inputTLS: &conf_v1.TLS{
-				Secret: "secret",
-				Redirect: &conf_v1.TLSRedirect{
-					Enable:  true,
-					BasedOn: "x-forwarded-proto",
+			expectedWarnings: Warnings{
+				nil: {
+					`Only one oidc policy is allowed in a VirtualServer and its VirtualServerRoutes. Can't use default/oidc-policy-2. Use default/oidc-policy-1`,
 				},
 			},
-			expected: &version2.TLSRedirect{
-				Code:    301,
-				BasedOn: "$http_x_forwarded_proto",
+			expectedOidc: &oidcPolicyCfg{
+				oidc: &version2.OIDC{
+					AuthEndpoint:  "https://foo.com/auth",
+					TokenEndpoint: "https://foo.com/token",
+					JwksURI:       "https://foo.com/certs",
+					ClientID:      "foo",
+					ClientSecret:  "super_secret_123",
+					RedirectURI:   "/_codexch",
+					Scope:         "openid",
+				},
+				key: "default/oidc-policy-1",
 			},
-			msg: "normal case with BasedOn set",
-		},
-	}
-
-	for _, test := range tests {
-		result := generateTLSRedirectConfig(test.inputTLS)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateTLSRedirectConfig() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
-		}
-	}
-}
-
-func TestGenerateTLSRedirectBasedOn(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		basedOn  string
-		expected string
-	}{
-		{
-			basedOn:  "scheme",
-			expected: "$scheme",
-		},
-		{
-			basedOn:  "x-forwarded-proto",
-			expected: "$http_x_forwarded_proto",
+			msg: "multiple oidc policies",
 		},
 		{
-			basedOn:  "",
-			expected: "$scheme",
-		},
-	}
-	for _, test := range tests {
-		result := generateTLSRedirectBasedOn(test.basedOn)
-		if result != test.expected {
-			t.Errorf("generateTLSRedirectBasedOn(%v) returned %v but expected %v", test.basedOn, result, test.expected)
-		}
-	}
-}
-
-func TestCreateUpstreamsForPlus(t *testing.T) {
-	t.Parallel()
-	virtualServerEx := VirtualServerEx{
-		VirtualServer: &conf_v1.VirtualServer{
-			ObjectMeta: meta_v1.ObjectMeta{
-				Name:      "cafe",
-				Namespace: "default",
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "oidc-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "oidc-policy2",
+					Namespace: "default",
+				},
 			},
-			Spec: conf_v1.VirtualServerSpec{
-				Host: "cafe.example.com",
-				Upstreams: []conf_v1.Upstream{
-					{
-						Name:    "tea",
-						Service: "tea-svc",
-						Port:    80,
-					},
-					{
-						Name:    "test",
-						Service: "test-svc",
-						Port:    80,
-					},
-					{
-						Name:        "subselector-test",
-						Service:     "test-svc",
-						Subselector: map[string]string{"vs": "works"},
-						Port:        80,
+			policies: map[string]*conf_v1.Policy{
+				"default/oidc-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "oidc-policy",
+						Namespace: "default",
 					},
-					{
-						Name:    "external",
-						Service: "external-svc",
-						Port:    80,
+					Spec: conf_v1.PolicySpec{
+						OIDC: &conf_v1.OIDC{
+							ClientSecret:  "oidc-secret",
+							AuthEndpoint:  "https://foo.com/auth",
+							TokenEndpoint: "https://foo.com/token",
+							JWKSURI:       "https://foo.com/certs",
+							ClientID:      "foo",
+						},
 					},
 				},
-				Routes: []conf_v1.Route{
-					{
-						Path: "/tea",
-						Action: &conf_v1.Action{
-							Pass: "tea",
-						},
+				"default/oidc-policy2": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "oidc-policy2",
+						Namespace: "default",
 					},
-					{
-						Path:  "/coffee",
-						Route: "default/coffee",
+					Spec: conf_v1.PolicySpec{
+						OIDC: &conf_v1.OIDC{
+							ClientSecret:  "oidc-secret",
+							AuthEndpoint:  "https://bar.com/auth",
+							TokenEndpoint: "https://bar.com/token",
+							JWKSURI:       "https://bar.com/certs",
+							ClientID:      "bar",
+						},
 					},
-					{
-						Path: "/external",
-						Action: &conf_v1.Action{
-							Pass: "external",
+				},
+			},
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/oidc-secret": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeOIDC,
+							Data: map[string][]byte{
+								"client-secret": []byte("super_secret_123"),
+							},
 						},
 					},
 				},
 			},
-		},
-		Endpoints: map[string][]string{
-			"default/tea-svc:80": {
-				"10.0.0.20:80",
+			context: "route",
+			expected: policiesCfg{
+				OIDC: true,
 			},
-			"default/test-svc:80": {},
-			"default/test-svc_vs=works:80": {
-				"10.0.0.30:80",
+			expectedWarnings: Warnings{
+				nil: {
+					`Multiple oidc policies in the same context is not valid. OIDC policy default/oidc-policy2 will be ignored`,
+				},
 			},
-			"default/coffee-svc:80": {
-				"10.0.0.40:80",
+			expectedOidc: &oidcPolicyCfg{
+				&version2.OIDC{
+					AuthEndpoint:   "https://foo.com/auth",
+					TokenEndpoint:  "https://foo.com/token",
+					JwksURI:        "https://foo.com/certs",
+					ClientID:       "foo",
+					ClientSecret:   "super_secret_123",
+					RedirectURI:    "/_codexch",
+					Scope:          "openid",
+					ZoneSyncLeeway: 200,
+				},
+				"default/oidc-policy",
 			},
-			"default/test-svc_vsr=works:80": {
-				"10.0.0.50:80",
-			},
-			"default/external-svc:80": {
-				"example.com:80",
-			},
-		},
-		ExternalNameSvcs: map[string]bool{
-			"default/external-svc": true,
+			msg: "multi oidc",
 		},
-		VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
-			{
-				ObjectMeta: meta_v1.ObjectMeta{
-					Name:      "coffee",
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "waf-policy",
 					Namespace: "default",
 				},
-				Spec: conf_v1.VirtualServerRouteSpec{
-					Host: "cafe.example.com",
-					Upstreams: []conf_v1.Upstream{
-						{
-							Name:    "coffee",
-							Service: "coffee-svc",
-							Port:    80,
-						},
-						{
-							Name:        "subselector-test",
-							Service:     "test-svc",
-							Subselector: map[string]string{"vsr": "works"},
-							Port:        80,
-						},
+				{
+					Name:      "waf-policy2",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/waf-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "waf-policy",
+						Namespace: "default",
 					},
-					Subroutes: []conf_v1.Route{
-						{
-							Path: "/coffee",
-							Action: &conf_v1.Action{
-								Pass: "coffee",
-							},
+					Spec: conf_v1.PolicySpec{
+						WAF: &conf_v1.WAF{
+							Enable:   true,
+							ApPolicy: "default/dataguard-alarm",
 						},
-						{
-							Path: "/coffee/sub",
-							Action: &conf_v1.Action{
-								Pass: "subselector-test",
-							},
+					},
+				},
+				"default/waf-policy2": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "waf-policy2",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						WAF: &conf_v1.WAF{
+							Enable:   true,
+							ApPolicy: "default/dataguard-alarm",
 						},
 					},
 				},
 			},
-		},
-	}
-
-	expected := []version2.Upstream{
-		{
-			Name: "vs_default_cafe_tea",
-			UpstreamLabels: version2.UpstreamLabels{
-				Service:           "tea-svc",
-				ResourceType:      "virtualserver",
-				ResourceNamespace: "default",
-				ResourceName:      "cafe",
-			},
-			Servers: []version2.UpstreamServer{
-				{
-					Address: "10.0.0.20:80",
+			policyOpts: policyOptions{
+				apResources: &appProtectResourcesForVS{
+					Policies: map[string]string{
+						"default/dataguard-alarm": "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
+					},
+					LogConfs: map[string]string{
+						"default/logconf": "/etc/nginx/waf/nac-logconfs/default-logconf",
+					},
 				},
 			},
-		},
-		{
-			Name: "vs_default_cafe_test",
-			UpstreamLabels: version2.UpstreamLabels{
-				Service:           "test-svc",
-				ResourceType:      "virtualserver",
-				ResourceNamespace: "default",
-				ResourceName:      "cafe",
-			},
-			Servers: nil,
-		},
-		{
-			Name: "vs_default_cafe_subselector-test",
-			UpstreamLabels: version2.UpstreamLabels{
-				Service:           "test-svc",
-				ResourceType:      "virtualserver",
-				ResourceNamespace: "default",
-				ResourceName:      "cafe",
+			context: "route",
+			expected: policiesCfg{
+				WAF: &version2.WAF{
+					Enable:   "on",
+					ApPolicy: "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
+				},
 			},
-			Servers: []version2.UpstreamServer{
-				{
-					Address: "10.0.0.30:80",
+			expectedWarnings: Warnings{
+				nil: {
+					`Multiple WAF policies in the same context is not valid. WAF policy default/waf-policy2 will be ignored`,
 				},
 			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "multi waf",
 		},
+	}
+
+	for _, test := range tests {
+		vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{}, false)
+
+		if test.oidcPolCfg != nil {
+			vsc.oidcPolCfg = test.oidcPolCfg
+		}
+
+		result := vsc.generatePolicies(ownerDetails, test.policyRefs, test.policies, test.context, test.policyOpts)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("generatePolicies() '%v' mismatch (-want +got):\n%s", test.msg, diff)
+		}
+		if !reflect.DeepEqual(vsc.warnings, test.expectedWarnings) {
+			t.Errorf(
+				"generatePolicies() returned warnings of \n%v but expected \n%v for the case of %s",
+				vsc.warnings,
+				test.expectedWarnings,
+				test.msg,
+			)
+		}
+		if diff := cmp.Diff(test.expectedOidc.oidc, vsc.oidcPolCfg.oidc); diff != "" {
+			t.Errorf("generatePolicies() '%v' mismatch (-want +got):\n%s", test.msg, diff)
+		}
+		if diff := cmp.Diff(test.expectedOidc.key, vsc.oidcPolCfg.key); diff != "" {
+			t.Errorf("generatePolicies() '%v' mismatch (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestRemoveDuplicates(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		rlz      []version2.LimitReqZone
+		expected []version2.LimitReqZone
+	}{
 		{
-			Name: "vs_default_cafe_vsr_default_coffee_coffee",
-			UpstreamLabels: version2.UpstreamLabels{
-				Service:           "coffee-svc",
-				ResourceType:      "virtualserverroute",
-				ResourceNamespace: "default",
-				ResourceName:      "coffee",
+			rlz: []version2.LimitReqZone{
+				{ZoneName: "test"},
+				{ZoneName: "test"},
+				{ZoneName: "test2"},
+				{ZoneName: "test3"},
 			},
-			Servers: []version2.UpstreamServer{
-				{
-					Address: "10.0.0.40:80",
-				},
+			expected: []version2.LimitReqZone{
+				{ZoneName: "test"},
+				{ZoneName: "test2"},
+				{ZoneName: "test3"},
 			},
 		},
 		{
-			Name: "vs_default_cafe_vsr_default_coffee_subselector-test",
-			UpstreamLabels: version2.UpstreamLabels{
-				Service:           "test-svc",
-				ResourceType:      "virtualserverroute",
-				ResourceNamespace: "default",
-				ResourceName:      "coffee",
+			rlz: []version2.LimitReqZone{
+				{ZoneName: "test"},
+				{ZoneName: "test"},
+				{ZoneName: "test2"},
+				{ZoneName: "test3"},
+				{ZoneName: "test3"},
 			},
-			Servers: []version2.UpstreamServer{
-				{
-					Address: "10.0.0.50:80",
-				},
+			expected: []version2.LimitReqZone{
+				{ZoneName: "test"},
+				{ZoneName: "test2"},
+				{ZoneName: "test3"},
 			},
 		},
 	}
-
-	result := createUpstreamsForPlus(&virtualServerEx, &ConfigParams{}, &StaticConfigParams{})
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("createUpstreamsForPlus returned \n%v but expected \n%v", result, expected)
+	for _, test := range tests {
+		result := removeDuplicateLimitReqZones(test.rlz)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("removeDuplicateLimitReqZones() returned \n%v, but expected \n%v", result, test.expected)
+		}
 	}
 }
 
-func TestCreateUpstreamServersConfigForPlus(t *testing.T) {
+func TestAddPoliciesCfgToLocations(t *testing.T) {
 	t.Parallel()
-	upstream := version2.Upstream{
-		Servers: []version2.UpstreamServer{
-			{
-				Address: "10.0.0.20:80",
-			},
+	cfg := policiesCfg{
+		Allow: []string{"127.0.0.1"},
+		Deny:  []string{"127.0.0.2"},
+		ErrorReturn: &version2.Return{
+			Code: 400,
 		},
-		MaxFails:    21,
-		MaxConns:    16,
-		FailTimeout: "30s",
-		SlowStart:   "50s",
 	}
 
-	expected := nginx.ServerConfig{
-		MaxFails:    21,
-		MaxConns:    16,
-		FailTimeout: "30s",
-		SlowStart:   "50s",
+	locations := []version2.Location{
+		{
+			Path: "/",
+		},
 	}
 
-	result := createUpstreamServersConfigForPlus(upstream)
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("createUpstreamServersConfigForPlus returned %v but expected %v", result, expected)
+	expectedLocations := []version2.Location{
+		{
+			Path:  "/",
+			Allow: []string{"127.0.0.1"},
+			Deny:  []string{"127.0.0.2"},
+			PoliciesErrorReturn: &version2.Return{
+				Code: 400,
+			},
+		},
 	}
-}
-
-func TestCreateUpstreamServersConfigForPlusNoUpstreams(t *testing.T) {
-	t.Parallel()
-	noUpstream := version2.Upstream{}
-	expected := nginx.ServerConfig{}
 
-	result := createUpstreamServersConfigForPlus(noUpstream)
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("createUpstreamServersConfigForPlus returned %v but expected %v", result, expected)
+	addPoliciesCfgToLocations(cfg, locations)
+	if !reflect.DeepEqual(locations, expectedLocations) {
+		t.Errorf("addPoliciesCfgToLocations() returned \n%+v but expected \n%+v", locations, expectedLocations)
 	}
 }
 
-func TestGenerateSplits(t *testing.T) {
+func TestGenerateUpstream(t *testing.T) {
 	t.Parallel()
-	originalPath := "/path"
-	splits := []conf_v1.Split{
-		{
-			Weight: 90,
-			Action: &conf_v1.Action{
-				Proxy: &conf_v1.ActionProxy{
-					Upstream:    "coffee-v1",
-					RewritePath: "/rewrite",
-				},
-			},
-		},
-		{
-			Weight: 9,
-			Action: &conf_v1.Action{
-				Pass: "coffee-v2",
-			},
+	name := "test-upstream"
+	upstream := conf_v1.Upstream{Service: name, Port: 80}
+	endpoints := []string{
+		"192.168.10.10:8080",
+	}
+	cfgParams := ConfigParams{
+		LBMethod:         "random",
+		MaxFails:         1,
+		MaxConns:         0,
+		FailTimeout:      "10s",
+		Keepalive:        21,
+		UpstreamZoneSize: "256k",
+	}
+
+	expected := version2.Upstream{
+		Name: "test-upstream",
+		UpstreamLabels: version2.UpstreamLabels{
+			Service: "test-upstream",
 		},
-		{
-			Weight: 1,
-			Action: &conf_v1.Action{
-				Return: &conf_v1.ActionReturn{
-					Body: "hello",
-				},
+		Servers: []version2.UpstreamServer{
+			{
+				Address: "192.168.10.10:8080",
 			},
 		},
+		MaxFails:         1,
+		MaxConns:         0,
+		FailTimeout:      "10s",
+		LBMethod:         "random",
+		Keepalive:        21,
+		UpstreamZoneSize: "256k",
 	}
 
-	virtualServer := conf_v1.VirtualServer{
-		ObjectMeta: meta_v1.ObjectMeta{
-			Name:      "cafe",
-			Namespace: "default",
-		},
+	vsc := newVirtualServerConfigurator(&cfgParams, false, false, &StaticConfigParams{}, false)
+	result := vsc.generateUpstream(nil, name, upstream, false, endpoints)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateUpstream() returned %v but expected %v", result, expected)
 	}
-	upstreamNamer := newUpstreamNamerForVirtualServer(&virtualServer)
-	variableNamer := newVariableNamer(&virtualServer)
-	scIndex := 1
-	cfgParams := ConfigParams{}
-	crUpstreams := map[string]conf_v1.Upstream{
-		"vs_default_cafe_coffee-v1": {
-			Service: "coffee-v1",
-		},
-		"vs_default_cafe_coffee-v2": {
-			Service: "coffee-v2",
-		},
+
+	if len(vsc.warnings) != 0 {
+		t.Errorf("generateUpstream returned warnings for %v", upstream)
 	}
-	locSnippet := "# location snippet"
-	enableSnippets := true
-	errorPages := []conf_v1.ErrorPage{
+}
+
+func TestGenerateUpstreamWithKeepalive(t *testing.T) {
+	t.Parallel()
+	name := "test-upstream"
+	noKeepalive := 0
+	keepalive := 32
+	endpoints := []string{
+		"192.168.10.10:8080",
+	}
+
+	tests := []struct {
+		upstream  conf_v1.Upstream
+		cfgParams *ConfigParams
+		expected  version2.Upstream
+		msg       string
+	}{
 		{
-			Codes: []int{400, 500},
-			Return: &conf_v1.ErrorPageReturn{
-				ActionReturn: conf_v1.ActionReturn{
-					Code: 200,
-					Type: "application/json",
-					Body: `{\"message\": \"ok\"}`,
+			conf_v1.Upstream{Keepalive: &keepalive, Service: name, Port: 80},
+			&ConfigParams{Keepalive: 21},
+			version2.Upstream{
+				Name: "test-upstream",
+				UpstreamLabels: version2.UpstreamLabels{
+					Service: "test-upstream",
 				},
-				Headers: []conf_v1.Header{
+				Servers: []version2.UpstreamServer{
 					{
-						Name:  "Set-Cookie",
-						Value: "cookie1=value",
+						Address: "192.168.10.10:8080",
 					},
 				},
+				Keepalive: 32,
 			},
-			Redirect: nil,
-		},
-		{
-			Codes:  []int{500, 502},
-			Return: nil,
-			Redirect: &conf_v1.ErrorPageRedirect{
-				ActionRedirect: conf_v1.ActionRedirect{
-					URL:  "http://nginx.com",
-					Code: 301,
-				},
-			},
-		},
-	}
-	expectedSplitClient := version2.SplitClient{
-		Source:   "$request_id",
-		Variable: "$vs_default_cafe_splits_1",
-		Distributions: []version2.Distribution{
-			{
-				Weight: "90%",
-				Value:  "/internal_location_splits_1_split_0",
-			},
-			{
-				Weight: "9%",
-				Value:  "/internal_location_splits_1_split_1",
-			},
-			{
-				Weight: "1%",
-				Value:  "/internal_location_splits_1_split_2",
-			},
+			"upstream keepalive set, configparam set",
 		},
-	}
-	expectedLocations := []version2.Location{
 		{
-			Path:      "/internal_location_splits_1_split_0",
-			ProxyPass: "http://vs_default_cafe_coffee-v1",
-			Rewrites: []string{
-				"^ $request_uri_no_args",
-				fmt.Sprintf(`"^%v(.*)$" "/rewrite$1" break`, originalPath),
-			},
-			ProxyNextUpstream:        "error timeout",
-			ProxyNextUpstreamTimeout: "0s",
-			ProxyNextUpstreamTries:   0,
-			ProxyInterceptErrors:     true,
-			Internal:                 true,
-			ErrorPages: []version2.ErrorPage{
-				{
-					Name:         "@error_page_0_0",
-					Codes:        "400 500",
-					ResponseCode: 200,
+			conf_v1.Upstream{Service: name, Port: 80},
+			&ConfigParams{Keepalive: 21},
+			version2.Upstream{
+				Name: "test-upstream",
+				UpstreamLabels: version2.UpstreamLabels{
+					Service: "test-upstream",
 				},
-				{
-					Name:         "http://nginx.com",
-					Codes:        "500 502",
-					ResponseCode: 301,
+				Servers: []version2.UpstreamServer{
+					{
+						Address: "192.168.10.10:8080",
+					},
 				},
+				Keepalive: 21,
 			},
-			ProxySSLName:            "coffee-v1.default.svc",
-			ProxyPassRequestHeaders: true,
-			ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
-			Snippets:                []string{locSnippet},
-			ServiceName:             "coffee-v1",
-			IsVSR:                   true,
-			VSRName:                 "coffee",
-			VSRNamespace:            "default",
+			"upstream keepalive not set, configparam set",
 		},
 		{
-			Path:                     "/internal_location_splits_1_split_1",
-			ProxyPass:                "http://vs_default_cafe_coffee-v2$request_uri",
-			ProxyNextUpstream:        "error timeout",
-			ProxyNextUpstreamTimeout: "0s",
-			ProxyNextUpstreamTries:   0,
-			ProxyInterceptErrors:     true,
-			Internal:                 true,
-			ErrorPages: []version2.ErrorPage{
-				{
-					Name:         "@error_page_0_0",
-					Codes:        "400 500",
-					ResponseCode: 200,
-				},
-				{
-					Name:         "http://nginx.com",
-					Codes:        "500 502",
-					ResponseCode: 301,
+			conf_v1.Upstream{Keepalive: &noKeepalive, Service: name, Port: 80},
+			&ConfigParams{Keepalive: 21},
+			version2.Upstream{
+				Name: "test-upstream",
+				UpstreamLabels: version2.UpstreamLabels{
+					Service: "test-upstream",
 				},
-			},
-			ProxySSLName:            "coffee-v2.default.svc",
-			ProxyPassRequestHeaders: true,
-			ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
-			Snippets:                []string{locSnippet},
-			ServiceName:             "coffee-v2",
-			IsVSR:                   true,
-			VSRName:                 "coffee",
-			VSRNamespace:            "default",
-		},
-		{
-			Path:                 "/internal_location_splits_1_split_2",
-			ProxyInterceptErrors: true,
-			ErrorPages: []version2.ErrorPage{
-				{
-					Name:         "@return_1",
-					Codes:        "418",
-					ResponseCode: 200,
+				Servers: []version2.UpstreamServer{
+					{
+						Address: "192.168.10.10:8080",
+					},
 				},
 			},
-			InternalProxyPass: "http://unix:/var/lib/nginx/nginx-418-server.sock",
-		},
-	}
-	expectedReturnLocations := []version2.ReturnLocation{
-		{
-			Name:        "@return_1",
-			DefaultType: "text/plain",
-			Return: version2.Return{
-				Code: 0,
-				Text: "hello",
-			},
+			"upstream keepalive set to 0, configparam set",
 		},
 	}
-	returnLocationIndex := 1
 
-	errorPageDetails := errorPageDetails{
-		pages: errorPages,
-		index: 0,
-		owner: nil,
-	}
+	for _, test := range tests {
+		vsc := newVirtualServerConfigurator(test.cfgParams, false, false, &StaticConfigParams{}, false)
+		result := vsc.generateUpstream(nil, name, test.upstream, false, endpoints)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateUpstream() returned %v but expected %v for the case of %v", result, test.expected, test.msg)
+		}
 
-	vsc := newVirtualServerConfigurator(&cfgParams, false, false, &StaticConfigParams{}, false)
+		if len(vsc.warnings) != 0 {
+			t.Errorf("generateUpstream() returned warnings for %v", test.upstream)
+		}
+	}
+}
 
-	resultSplitClient, resultLocations, resultReturnLocations := generateSplits(
-		splits,
-		upstreamNamer,
-		crUpstreams,
-		variableNamer,
-		scIndex,
-		&cfgParams,
-		errorPageDetails,
-		originalPath,
-		locSnippet,
-		enableSnippets,
-		returnLocationIndex,
-		true,
-		"coffee",
-		"default",
-		vsc.warnings,
-	)
+func TestGenerateUpstreamForExternalNameService(t *testing.T) {
+	t.Parallel()
+	name := "test-upstream"
+	endpoints := []string{"example.com"}
+	upstream := conf_v1.Upstream{Service: name}
+	cfgParams := ConfigParams{}
 
-	if diff := cmp.Diff(expectedSplitClient, resultSplitClient); diff != "" {
-		t.Errorf("generateSplits() resultSplitClient mismatch (-want +got):\n%s", diff)
+	expected := version2.Upstream{
+		Name: name,
+		UpstreamLabels: version2.UpstreamLabels{
+			Service: "test-upstream",
+		},
+		Servers: []version2.UpstreamServer{
+			{
+				Address: "example.com",
+			},
+		},
+		Resolve: true,
 	}
-	if diff := cmp.Diff(expectedLocations, resultLocations); diff != "" {
-		t.Errorf("generateSplits() resultLocations mismatch (-want +got):\n%s", diff)
+
+	vsc := newVirtualServerConfigurator(&cfgParams, true, true, &StaticConfigParams{}, false)
+	result := vsc.generateUpstream(nil, name, upstream, true, endpoints)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateUpstream() returned %v but expected %v", result, expected)
 	}
-	if diff := cmp.Diff(expectedReturnLocations, resultReturnLocations); diff != "" {
-		t.Errorf("generateSplits() resultReturnLocations mismatch (-want +got):\n%s", diff)
+
+	if len(vsc.warnings) != 0 {
+		t.Errorf("generateUpstream() returned warnings for %v", upstream)
 	}
 }
 
-func TestGenerateDefaultSplitsConfig(t *testing.T) {
+func TestGenerateUpstreamWithNTLM(t *testing.T) {
 	t.Parallel()
-	route := conf_v1.Route{
-		Path: "/",
-		Splits: []conf_v1.Split{
-			{
-				Weight: 90,
-				Action: &conf_v1.Action{
-					Pass: "coffee-v1",
-				},
-			},
-			{
-				Weight: 10,
-				Action: &conf_v1.Action{
-					Pass: "coffee-v2",
-				},
-			},
-		},
+	name := "test-upstream"
+	upstream := conf_v1.Upstream{Service: name, Port: 80, NTLM: true}
+	endpoints := []string{
+		"192.168.10.10:8080",
 	}
-	virtualServer := conf_v1.VirtualServer{
-		ObjectMeta: meta_v1.ObjectMeta{
-			Name:      "cafe",
-			Namespace: "default",
-		},
+	cfgParams := ConfigParams{
+		LBMethod:         "random",
+		MaxFails:         1,
+		MaxConns:         0,
+		FailTimeout:      "10s",
+		Keepalive:        21,
+		UpstreamZoneSize: "256k",
 	}
-	upstreamNamer := newUpstreamNamerForVirtualServer(&virtualServer)
-	variableNamer := newVariableNamer(&virtualServer)
-	index := 1
 
-	expected := routingCfg{
-		SplitClients: []version2.SplitClient{
-			{
-				Source:   "$request_id",
-				Variable: "$vs_default_cafe_splits_1",
-				Distributions: []version2.Distribution{
-					{
-						Weight: "90%",
-						Value:  "/internal_location_splits_1_split_0",
-					},
-					{
-						Weight: "10%",
-						Value:  "/internal_location_splits_1_split_1",
-					},
-				},
-			},
+	expected := version2.Upstream{
+		Name: "test-upstream",
+		UpstreamLabels: version2.UpstreamLabels{
+			Service: "test-upstream",
 		},
-		Locations: []version2.Location{
-			{
-				Path:                     "/internal_location_splits_1_split_0",
-				ProxyPass:                "http://vs_default_cafe_coffee-v1$request_uri",
-				ProxyNextUpstream:        "error timeout",
-				ProxyNextUpstreamTimeout: "0s",
-				ProxyNextUpstreamTries:   0,
-				Internal:                 true,
-				ProxySSLName:             "coffee-v1.default.svc",
-				ProxyPassRequestHeaders:  true,
-				ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-				ServiceName:              "coffee-v1",
-				IsVSR:                    true,
-				VSRName:                  "coffee",
-				VSRNamespace:             "default",
-			},
+		Servers: []version2.UpstreamServer{
 			{
-				Path:                     "/internal_location_splits_1_split_1",
-				ProxyPass:                "http://vs_default_cafe_coffee-v2$request_uri",
-				ProxyNextUpstream:        "error timeout",
-				ProxyNextUpstreamTimeout: "0s",
-				ProxyNextUpstreamTries:   0,
-				Internal:                 true,
-				ProxySSLName:             "coffee-v2.default.svc",
-				ProxyPassRequestHeaders:  true,
-				ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-				ServiceName:              "coffee-v2",
-				IsVSR:                    true,
-				VSRName:                  "coffee",
-				VSRNamespace:             "default",
+				Address: "192.168.10.10:8080",
 			},
 		},
-		InternalRedirectLocation: version2.InternalRedirectLocation{
-			Path:        "/",
-			Destination: "$vs_default_cafe_splits_1",
-		},
+		MaxFails:         1,
+		MaxConns:         0,
+		FailTimeout:      "10s",
+		LBMethod:         "random",
+		Keepalive:        21,
+		UpstreamZoneSize: "256k",
+		NTLM:             true,
 	}
 
-	cfgParams := ConfigParams{}
-	locSnippet := ""
-	enableSnippets := false
-	crUpstreams := map[string]conf_v1.Upstream{
-		"vs_default_cafe_coffee-v1": {
-			Service: "coffee-v1",
-		},
-		"vs_default_cafe_coffee-v2": {
-			Service: "coffee-v2",
-		},
+	vsc := newVirtualServerConfigurator(&cfgParams, true, false, &StaticConfigParams{}, false)
+	result := vsc.generateUpstream(nil, name, upstream, false, endpoints)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateUpstream() returned %v but expected %v", result, expected)
 	}
 
-	errorPageDetails := errorPageDetails{
-		pages: route.ErrorPages,
-		index: 0,
-		owner: nil,
+	if len(vsc.warnings) != 0 {
+		t.Errorf("generateUpstream returned warnings for %v", upstream)
 	}
+}
 
-	result := generateDefaultSplitsConfig(route, upstreamNamer, crUpstreams, variableNamer, index, &cfgParams,
-		errorPageDetails, "", locSnippet, enableSnippets, 0, true, "coffee", "default", Warnings{})
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("generateDefaultSplitsConfig() returned \n%+v but expected \n%+v", result, expected)
+func TestGenerateProxyPass(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		tlsEnabled   bool
+		upstreamName string
+		internal     bool
+		expected     string
+	}{
+		{
+			tlsEnabled:   false,
+			upstreamName: "test-upstream",
+			internal:     false,
+			expected:     "http://test-upstream",
+		},
+		{
+			tlsEnabled:   true,
+			upstreamName: "test-upstream",
+			internal:     false,
+			expected:     "https://test-upstream",
+		},
+		{
+			tlsEnabled:   false,
+			upstreamName: "test-upstream",
+			internal:     true,
+			expected:     "http://test-upstream$request_uri",
+		},
+		{
+			tlsEnabled:   true,
+			upstreamName: "test-upstream",
+			internal:     true,
+			expected:     "https://test-upstream$request_uri",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateProxyPass(test.tlsEnabled, test.upstreamName, test.internal, nil)
+		if result != test.expected {
+			t.Errorf("generateProxyPass(%v, %v, %v) returned %v but expected %v", test.tlsEnabled, test.upstreamName, test.internal, result, test.expected)
+		}
 	}
 }
 
-func TestGenerateMatchesConfig(t *testing.T) {
+func TestGenerateProxyPassProtocol(t *testing.T) {
 	t.Parallel()
-	route := conf_v1.Route{
-		Path: "/",
-		Matches: []conf_v1.Match{
-			{
-				Conditions: []conf_v1.Condition{
-					{
-						Header: "x-version",
-						Value:  "v1",
-					},
-					{
-						Cookie: "user",
-						Value:  "john",
-					},
-					{
-						Argument: "answer",
-						Value:    "yes",
-					},
-					{
-						Variable: "$request_method",
-						Value:    "GET",
-					},
-				},
-				Action: &conf_v1.Action{
-					Pass: "coffee-v1",
+	tests := []struct {
+		upstream conf_v1.Upstream
+		expected string
+	}{
+		{
+			upstream: conf_v1.Upstream{},
+			expected: "http",
+		},
+		{
+			upstream: conf_v1.Upstream{
+				TLS: conf_v1.UpstreamTLS{
+					Enable: true,
 				},
 			},
-			{
-				Conditions: []conf_v1.Condition{
-					{
-						Header: "x-version",
-						Value:  "v2",
-					},
-					{
-						Cookie: "user",
-						Value:  "paul",
-					},
-					{
-						Argument: "answer",
-						Value:    "no",
-					},
-					{
-						Variable: "$request_method",
-						Value:    "POST",
-					},
-				},
-				Splits: []conf_v1.Split{
-					{
-						Weight: 90,
-						Action: &conf_v1.Action{
-							Pass: "coffee-v1",
-						},
-					},
-					{
-						Weight: 10,
-						Action: &conf_v1.Action{
-							Pass: "coffee-v2",
-						},
-					},
+			expected: "https",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateProxyPassProtocol(test.upstream.TLS.Enable)
+		if result != test.expected {
+			t.Errorf("generateProxyPassProtocol(%v) returned %v but expected %v", test.upstream.TLS.Enable, result, test.expected)
+		}
+	}
+}
+
+func TestGenerateGRPCPass(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		grpcEnabled  bool
+		tlsEnabled   bool
+		upstreamName string
+		expected     string
+	}{
+		{
+			grpcEnabled:  false,
+			tlsEnabled:   false,
+			upstreamName: "test-upstream",
+			expected:     "",
+		},
+		{
+			grpcEnabled:  true,
+			tlsEnabled:   false,
+			upstreamName: "test-upstream",
+			expected:     "grpc://test-upstream",
+		},
+		{
+			grpcEnabled:  true,
+			tlsEnabled:   true,
+			upstreamName: "test-upstream",
+			expected:     "grpcs://test-upstream",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateGRPCPass(test.grpcEnabled, test.tlsEnabled, test.upstreamName)
+		if result != test.expected {
+			t.Errorf("generateGRPCPass(%v, %v, %v) returned %v but expected %v", test.grpcEnabled, test.tlsEnabled, test.upstreamName, result, test.expected)
+		}
+	}
+}
+
+func TestGenerateGRPCPassProtocol(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		upstream conf_v1.Upstream
+		expected string
+	}{
+		{
+			upstream: conf_v1.Upstream{},
+			expected: "grpc",
+		},
+		{
+			upstream: conf_v1.Upstream{
+				TLS: conf_v1.UpstreamTLS{
+					Enable: true,
 				},
 			},
+			expected: "grpcs",
 		},
-		Action: &conf_v1.Action{
-			Pass: "tea",
+	}
+
+	for _, test := range tests {
+		result := generateGRPCPassProtocol(test.upstream.TLS.Enable)
+		if result != test.expected {
+			t.Errorf("generateGRPCPassProtocol(%v) returned %v but expected %v", test.upstream.TLS.Enable, result, test.expected)
+		}
+	}
+}
+
+func TestGenerateString(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		inputS   string
+		expected string
+	}{
+		{
+			inputS:   "http_404",
+			expected: "http_404",
+		},
+		{
+			inputS:   "",
+			expected: "error timeout",
 		},
 	}
-	virtualServer := conf_v1.VirtualServer{
-		ObjectMeta: meta_v1.ObjectMeta{
-			Name:      "cafe",
-			Namespace: "default",
+
+	for _, test := range tests {
+		result := generateString(test.inputS, "error timeout")
+		if result != test.expected {
+			t.Errorf("generateString() return %v but expected %v", result, test.expected)
+		}
+	}
+}
+
+func TestGenerateSnippets(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		enableSnippets bool
+		s              string
+		defaultS       []string
+		expected       []string
+	}{
+		{
+			true,
+			"test",
+			[]string{},
+			[]string{"test"},
+		},
+		{
+			true,
+			"",
+			[]string{"default"},
+			[]string{"default"},
+		},
+		{
+			true,
+			"test\none\ntwo",
+			[]string{},
+			[]string{"test", "one", "two"},
+		},
+		{
+			false,
+			"test",
+			nil,
+			nil,
 		},
 	}
-	errorPages := []conf_v1.ErrorPage{
+	for _, test := range tests {
+		result := generateSnippets(test.enableSnippets, test.s, test.defaultS)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateSnippets() return %v, but expected %v", result, test.expected)
+		}
+	}
+}
+
+func TestGenerateBuffer(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		inputS   *conf_v1.UpstreamBuffers
+		expected string
+	}{
 		{
-			Codes: []int{400, 500},
-			Return: &conf_v1.ErrorPageReturn{
-				ActionReturn: conf_v1.ActionReturn{
-					Code: 200,
-					Type: "application/json",
-					Body: `{\"message\": \"ok\"}`,
-				},
-				Headers: []conf_v1.Header{
-					{
-						Name:  "Set-Cookie",
-						Value: "cookie1=value",
-					},
-				},
-			},
-			Redirect: nil,
+			inputS:   nil,
+			expected: "8 4k",
 		},
 		{
-			Codes:  []int{500, 502},
-			Return: nil,
-			Redirect: &conf_v1.ErrorPageRedirect{
-				ActionRedirect: conf_v1.ActionRedirect{
-					URL:  "http://nginx.com",
-					Code: 301,
-				},
-			},
+			inputS:   &conf_v1.UpstreamBuffers{Number: 8, Size: "16K"},
+			expected: "8 16K",
 		},
 	}
-	upstreamNamer := newUpstreamNamerForVirtualServer(&virtualServer)
-	variableNamer := newVariableNamer(&virtualServer)
-	index := 1
-	scIndex := 2
 
-	expected := routingCfg{
-		Maps: []version2.Map{
-			{
-				Source:   "$http_x_version",
-				Variable: "$vs_default_cafe_matches_1_match_0_cond_0",
-				Parameters: []version2.Parameter{
-					{
-						Value:  `"v1"`,
-						Result: "$vs_default_cafe_matches_1_match_0_cond_1",
-					},
-					{
-						Value:  "default",
-						Result: "0",
-					},
-				},
-			},
-			{
-				Source:   "$cookie_user",
-				Variable: "$vs_default_cafe_matches_1_match_0_cond_1",
-				Parameters: []version2.Parameter{
-					{
-						Value:  `"john"`,
-						Result: "$vs_default_cafe_matches_1_match_0_cond_2",
-					},
-					{
-						Value:  "default",
-						Result: "0",
-					},
-				},
-			},
-			{
-				Source:   "$arg_answer",
-				Variable: "$vs_default_cafe_matches_1_match_0_cond_2",
-				Parameters: []version2.Parameter{
-					{
-						Value:  `"yes"`,
-						Result: "$vs_default_cafe_matches_1_match_0_cond_3",
-					},
-					{
-						Value:  "default",
-						Result: "0",
-					},
-				},
-			},
-			{
-				Source:   "$request_method",
-				Variable: "$vs_default_cafe_matches_1_match_0_cond_3",
-				Parameters: []version2.Parameter{
-					{
-						Value:  `"GET"`,
-						Result: "1",
-					},
-					{
-						Value:  "default",
-						Result: "0",
-					},
-				},
+	for _, test := range tests {
+		result := generateBuffers(test.inputS, "8 4k")
+		if result != test.expected {
+			t.Errorf("generateBuffer() return %v but expected %v", result, test.expected)
+		}
+	}
+}
+
+func TestGenerateLocationForProxying(t *testing.T) {
+	t.Parallel()
+	cfgParams := ConfigParams{
+		ProxyConnectTimeout:  "30s",
+		ProxyReadTimeout:     "31s",
+		ProxySendTimeout:     "32s",
+		ClientMaxBodySize:    "1m",
+		ProxyMaxTempFileSize: "1024m",
+		ProxyBuffering:       true,
+		ProxyBuffers:         "8 4k",
+		ProxyBufferSize:      "4k",
+		LocationSnippets:     []string{"# location snippet"},
+	}
+	path := "/"
+	upstreamName := "test-upstream"
+	vsLocSnippets := []string{"# vs location snippet"}
+
+	expected := version2.Location{
+		Path:                     "/",
+		Snippets:                 vsLocSnippets,
+		ProxyConnectTimeout:      "30s",
+		ProxyReadTimeout:         "31s",
+		ProxySendTimeout:         "32s",
+		ClientMaxBodySize:        "1m",
+		ProxyMaxTempFileSize:     "1024m",
+		ProxyBuffering:           true,
+		ProxyBuffers:             "8 4k",
+		ProxyBufferSize:          "4k",
+		ProxyPass:                "http://test-upstream",
+		ProxyNextUpstream:        "error timeout",
+		ProxyNextUpstreamTimeout: "0s",
+		ProxyNextUpstreamTries:   0,
+		ProxyPassRequestHeaders:  true,
+		ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+		ServiceName:              "",
+		IsVSR:                    false,
+		VSRName:                  "",
+		VSRNamespace:             "",
+	}
+
+	result := generateLocationForProxying(path, upstreamName, conf_v1.Upstream{}, &cfgParams, nil, false, 0, "", nil, "", vsLocSnippets, false, "", "")
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("generateLocationForProxying() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateLocationForGrpcProxying(t *testing.T) {
+	t.Parallel()
+	cfgParams := ConfigParams{
+		ProxyConnectTimeout:  "30s",
+		ProxyReadTimeout:     "31s",
+		ProxySendTimeout:     "32s",
+		ClientMaxBodySize:    "1m",
+		ProxyMaxTempFileSize: "1024m",
+		ProxyBuffering:       true,
+		ProxyBuffers:         "8 4k",
+		ProxyBufferSize:      "4k",
+		LocationSnippets:     []string{"# location snippet"},
+		HTTP2:                true,
+	}
+	path := "/"
+	upstreamName := "test-upstream"
+	vsLocSnippets := []string{"# vs location snippet"}
+
+	expected := version2.Location{
+		Path:                     "/",
+		Snippets:                 vsLocSnippets,
+		ProxyConnectTimeout:      "30s",
+		ProxyReadTimeout:         "31s",
+		ProxySendTimeout:         "32s",
+		ClientMaxBodySize:        "1m",
+		ProxyMaxTempFileSize:     "1024m",
+		ProxyBuffering:           true,
+		ProxyBuffers:             "8 4k",
+		ProxyBufferSize:          "4k",
+		ProxyPass:                "http://test-upstream",
+		ProxyNextUpstream:        "error timeout",
+		ProxyNextUpstreamTimeout: "0s",
+		ProxyNextUpstreamTries:   0,
+		ProxyPassRequestHeaders:  true,
+		ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+		GRPCPass:                 "grpc://test-upstream",
+	}
+
+	result := generateLocationForProxying(path, upstreamName, conf_v1.Upstream{Type: "grpc"}, &cfgParams, nil, false, 0, "", nil, "", vsLocSnippets, false, "", "")
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("generateLocationForForGrpcProxying() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateReturnBlock(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		text        string
+		code        int
+		defaultCode int
+		expected    *version2.Return
+	}{
+		{
+			text:        "Hello World!",
+			code:        0, // Not set
+			defaultCode: 200,
+			expected: &version2.Return{
+				Code: 200,
+				Text: "Hello World!",
 			},
-			{
-				Source:   "$http_x_version",
-				Variable: "$vs_default_cafe_matches_1_match_1_cond_0",
-				Parameters: []version2.Parameter{
-					{
-						Value:  `"v2"`,
-						Result: "$vs_default_cafe_matches_1_match_1_cond_1",
-					},
-					{
-						Value:  "default",
-						Result: "0",
-					},
-				},
+		},
+		{
+			text:        "Hello World!",
+			code:        400,
+			defaultCode: 200,
+			expected: &version2.Return{
+				Code: 400,
+				Text: "Hello World!",
 			},
-			{
-				Source:   "$cookie_user",
-				Variable: "$vs_default_cafe_matches_1_match_1_cond_1",
-				Parameters: []version2.Parameter{
-					{
-						Value:  `"paul"`,
-						Result: "$vs_default_cafe_matches_1_match_1_cond_2",
-					},
+		},
+	}
+
+	for _, test := range tests {
+		result := generateReturnBlock(test.text, test.code, test.defaultCode)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateReturnBlock() returned %v but expected %v", result, test.expected)
+		}
+	}
+}
+
+func TestGenerateLocationForReturn(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		actionReturn           *conf_v1.ActionReturn
+		expectedLocation       version2.Location
+		expectedReturnLocation *version2.ReturnLocation
+		msg                    string
+	}{
+		{
+			actionReturn: &conf_v1.ActionReturn{
+				Body: "hello",
+			},
+
+			expectedLocation: version2.Location{
+				Path:     "/",
+				Snippets: []string{"# location snippet"},
+				ErrorPages: []version2.ErrorPage{
 					{
-						Value:  "default",
-						Result: "0",
+						Name:         "@return_1",
+						Codes:        "418",
+						ResponseCode: 200,
 					},
 				},
+				ProxyInterceptErrors: true,
+				InternalProxyPass:    "http://unix:/var/lib/nginx/nginx-418-server.sock",
 			},
-			{
-				Source:   "$arg_answer",
-				Variable: "$vs_default_cafe_matches_1_match_1_cond_2",
-				Parameters: []version2.Parameter{
-					{
-						Value:  `"no"`,
-						Result: "$vs_default_cafe_matches_1_match_1_cond_3",
-					},
-					{
-						Value:  "default",
-						Result: "0",
-					},
+			expectedReturnLocation: &version2.ReturnLocation{
+				Name:        "@return_1",
+				DefaultType: "text/plain",
+				Return: version2.Return{
+					Code: 0,
+					Text: "hello",
 				},
 			},
-			{
-				Source:   "$request_method",
-				Variable: "$vs_default_cafe_matches_1_match_1_cond_3",
-				Parameters: []version2.Parameter{
-					{
-						Value:  `"POST"`,
-						Result: "1",
-					},
+			msg: "return without code and type",
+		},
+		{
+			actionReturn: &conf_v1.ActionReturn{
+				Code: 400,
+				Type: "text/html",
+				Body: "hello",
+			},
+
+			expectedLocation: version2.Location{
+				Path:     "/",
+				Snippets: []string{"# location snippet"},
+				ErrorPages: []version2.ErrorPage{
 					{
-						Value:  "default",
-						Result: "0",
+						Name:         "@return_1",
+						Codes:        "418",
+						ResponseCode: 400,
 					},
 				},
+				ProxyInterceptErrors: true,
+				InternalProxyPass:    "http://unix:/var/lib/nginx/nginx-418-server.sock",
 			},
-			{
-				Source:   "$vs_default_cafe_matches_1_match_0_cond_0$vs_default_cafe_matches_1_match_1_cond_0",
-				Variable: "$vs_default_cafe_matches_1",
-				Parameters: []version2.Parameter{
-					{
-						Value:  "~^1",
-						Result: "/internal_location_matches_1_match_0",
-					},
-					{
-						Value:  "~^01",
-						Result: "$vs_default_cafe_splits_2",
-					},
-					{
-						Value:  "default",
-						Result: "/internal_location_matches_1_default",
-					},
+			expectedReturnLocation: &version2.ReturnLocation{
+				Name:        "@return_1",
+				DefaultType: "text/html",
+				Return: version2.Return{
+					Code: 0,
+					Text: "hello",
 				},
 			},
+			msg: "return with all fields defined",
 		},
-		Locations: []version2.Location{
-			{
-				Path:                     "/internal_location_matches_1_match_0",
-				ProxyPass:                "http://vs_default_cafe_coffee-v1$request_uri",
-				ProxyNextUpstream:        "error timeout",
-				ProxyNextUpstreamTimeout: "0s",
-				ProxyNextUpstreamTries:   0,
-				ProxyInterceptErrors:     true,
-				Internal:                 true,
+	}
+	path := "/"
+	snippets := []string{"# location snippet"}
+	returnLocationIndex := 1
+
+	for _, test := range tests {
+		location, returnLocation := generateLocationForReturn(path, snippets, test.actionReturn, returnLocationIndex)
+		if !reflect.DeepEqual(location, test.expectedLocation) {
+			t.Errorf("generateLocationForReturn() returned  \n%+v but expected \n%+v for the case of %s",
+				location, test.expectedLocation, test.msg)
+		}
+		if !reflect.DeepEqual(returnLocation, test.expectedReturnLocation) {
+			t.Errorf("generateLocationForReturn() returned  \n%+v but expected \n%+v for the case of %s",
+				returnLocation, test.expectedReturnLocation, test.msg)
+		}
+	}
+}
+
+func TestGenerateLocationForRedirect(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		redirect *conf_v1.ActionRedirect
+		expected version2.Location
+		msg      string
+	}{
+		{
+			redirect: &conf_v1.ActionRedirect{
+				URL: "http://nginx.org",
+			},
+
+			expected: version2.Location{
+				Path:     "/",
+				Snippets: []string{"# location snippet"},
 				ErrorPages: []version2.ErrorPage{
 					{
-						Name:         "@error_page_2_0",
-						Codes:        "400 500",
-						ResponseCode: 200,
-					},
-					{
-						Name:         "http://nginx.com",
-						Codes:        "500 502",
+						Name:         "http://nginx.org",
+						Codes:        "418",
 						ResponseCode: 301,
 					},
 				},
-				ProxySSLName:            "coffee-v1.default.svc",
-				ProxyPassRequestHeaders: true,
-				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
-				ServiceName:             "coffee-v1",
-				IsVSR:                   false,
-				VSRName:                 "",
-				VSRNamespace:            "",
+				ProxyInterceptErrors: true,
+				InternalProxyPass:    "http://unix:/var/lib/nginx/nginx-418-server.sock",
 			},
-			{
-				Path:                     "/internal_location_splits_2_split_0",
-				ProxyPass:                "http://vs_default_cafe_coffee-v1$request_uri",
-				ProxyNextUpstream:        "error timeout",
-				ProxyNextUpstreamTimeout: "0s",
-				ProxyNextUpstreamTries:   0,
-				ProxyInterceptErrors:     true,
-				Internal:                 true,
+			msg: "redirect without code",
+		},
+		{
+			redirect: &conf_v1.ActionRedirect{
+				Code: 302,
+				URL:  "http://nginx.org",
+			},
+
+			expected: version2.Location{
+				Path:     "/",
+				Snippets: []string{"# location snippet"},
 				ErrorPages: []version2.ErrorPage{
 					{
-						Name:         "@error_page_2_0",
-						Codes:        "400 500",
-						ResponseCode: 200,
-					},
-					{
-						Name:         "http://nginx.com",
-						Codes:        "500 502",
-						ResponseCode: 301,
+						Name:         "http://nginx.org",
+						Codes:        "418",
+						ResponseCode: 302,
 					},
 				},
-				ProxySSLName:            "coffee-v1.default.svc",
-				ProxyPassRequestHeaders: true,
-				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
-				ServiceName:             "coffee-v1",
-				IsVSR:                   false,
-				VSRName:                 "",
-				VSRNamespace:            "",
+				ProxyInterceptErrors: true,
+				InternalProxyPass:    "http://unix:/var/lib/nginx/nginx-418-server.sock",
 			},
-			{
-				Path:                     "/internal_location_splits_2_split_1",
-				ProxyPass:                "http://vs_default_cafe_coffee-v2$request_uri",
-				ProxyNextUpstream:        "error timeout",
-				ProxyNextUpstreamTimeout: "0s",
-				ProxyNextUpstreamTries:   0,
-				ProxyInterceptErrors:     true,
-				Internal:                 true,
-				ErrorPages: []version2.ErrorPage{
-					{
-						Name:         "@error_page_2_0",
-						Codes:        "400 500",
-						ResponseCode: 200,
+			msg: "redirect with all fields defined",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateLocationForRedirect("/", []string{"# location snippet"}, test.redirect)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateLocationForReturn() returned \n%+v but expected \n%+v for the case of %s",
+				result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestGenerateSSLConfig(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		inputTLS         *conf_v1.TLS
+		inputSecretRefs  map[string]*secrets.SecretReference
+		inputCfgParams   *ConfigParams
+		wildcard         bool
+		expectedSSL      *version2.SSL
+		expectedWarnings Warnings
+		msg              string
+	}{
+		{
+			inputTLS:         nil,
+			inputSecretRefs:  map[string]*secrets.SecretReference{},
+			inputCfgParams:   &ConfigParams{},
+			wildcard:         false,
+			expectedSSL:      nil,
+			expectedWarnings: Warnings{},
+			msg:              "no TLS field",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret: "",
+			},
+			inputSecretRefs:  map[string]*secrets.SecretReference{},
+			inputCfgParams:   &ConfigParams{},
+			wildcard:         false,
+			expectedSSL:      nil,
+			expectedWarnings: Warnings{},
+			msg:              "TLS field with empty secret and wildcard cert disabled",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret: "",
+			},
+			inputSecretRefs: map[string]*secrets.SecretReference{},
+			inputCfgParams:  &ConfigParams{},
+			wildcard:        true,
+			expectedSSL: &version2.SSL{
+				HTTP2:           false,
+				Certificate:     pemFileNameForWildcardTLSSecret,
+				CertificateKey:  pemFileNameForWildcardTLSSecret,
+				RejectHandshake: false,
+			},
+			expectedWarnings: Warnings{},
+			msg:              "TLS field with empty secret and wildcard cert enabled",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret: "missing",
+			},
+			inputCfgParams: &ConfigParams{},
+			wildcard:       false,
+			inputSecretRefs: map[string]*secrets.SecretReference{
+				"default/missing": {
+					Error: errors.New("missing doesn't exist"),
+				},
+			},
+			expectedSSL: &version2.SSL{
+				HTTP2:           false,
+				RejectHandshake: true,
+			},
+			expectedWarnings: Warnings{
+				nil: []string{"TLS secret missing is invalid: missing doesn't exist"},
+			},
+			msg: "missing doesn't exist in the cluster with HTTPS",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret: "mistyped",
+			},
+			inputCfgParams: &ConfigParams{},
+			wildcard:       false,
+			inputSecretRefs: map[string]*secrets.SecretReference{
+				"default/mistyped": {
+					Secret: &api_v1.Secret{
+						Type: secrets.SecretTypeCA,
 					},
-					{
-						Name:         "http://nginx.com",
-						Codes:        "500 502",
-						ResponseCode: 301,
+				},
+			},
+			expectedSSL: &version2.SSL{
+				HTTP2:           false,
+				RejectHandshake: true,
+			},
+			expectedWarnings: Warnings{
+				nil: []string{"TLS secret mistyped is of a wrong type 'nginx.org/ca', must be 'kubernetes.io/tls'"},
+			},
+			msg: "wrong secret type",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret: "secret",
+			},
+			inputSecretRefs: map[string]*secrets.SecretReference{
+				"default/secret": {
+					Secret: &api_v1.Secret{
+						Type: api_v1.SecretTypeTLS,
 					},
+					Path: "secret.pem",
 				},
-				ProxySSLName:            "coffee-v2.default.svc",
-				ProxyPassRequestHeaders: true,
-				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
-				ServiceName:             "coffee-v2",
-				IsVSR:                   false,
-				VSRName:                 "",
-				VSRNamespace:            "",
 			},
-			{
-				Path:                     "/internal_location_matches_1_default",
-				ProxyPass:                "http://vs_default_cafe_tea$request_uri",
-				ProxyNextUpstream:        "error timeout",
-				ProxyNextUpstreamTimeout: "0s",
-				ProxyNextUpstreamTries:   0,
-				ProxyInterceptErrors:     true,
-				Internal:                 true,
-				ErrorPages: []version2.ErrorPage{
-					{
-						Name:         "@error_page_2_0",
-						Codes:        "400 500",
-						ResponseCode: 200,
-					},
-					{
-						Name:         "http://nginx.com",
-						Codes:        "500 502",
-						ResponseCode: 301,
-					},
+			inputCfgParams: &ConfigParams{},
+			wildcard:       false,
+			expectedSSL: &version2.SSL{
+				HTTP2:           false,
+				Certificate:     "secret.pem",
+				CertificateKey:  "secret.pem",
+				RejectHandshake: false,
+			},
+			expectedWarnings: Warnings{},
+			msg:              "normal case with HTTPS",
+		},
+	}
+
+	namespace := "default"
+
+	for _, test := range tests {
+		vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{}, test.wildcard)
+
+		// it is ok to use nil as the owner
+		result := vsc.generateSSLConfig(nil, test.inputTLS, namespace, test.inputSecretRefs, test.inputCfgParams)
+		if !reflect.DeepEqual(result, test.expectedSSL) {
+			t.Errorf("generateSSLConfig() returned %v but expected %v for the case of %s", result, test.expectedSSL, test.msg)
+		}
+		if !reflect.DeepEqual(vsc.warnings, test.expectedWarnings) {
+			t.Errorf("generateSSLConfig() returned warnings of \n%v but expected \n%v for the case of %s", vsc.warnings, test.expectedWarnings, test.msg)
+		}
+	}
+}
+
+func TestGenerateRedirectConfig(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		inputTLS *conf_v1.TLS
+		expected *version2.TLSRedirect
+		msg      string
+	}{
+		{
+			inputTLS: nil,
+			expected: nil,
+			msg:      "no TLS field",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret:   "secret",
+				Redirect: nil,
+			},
+			expected: nil,
+			msg:      "no redirect field",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret:   "secret",
+				Redirect: &conf_v1.TLSRedirect{Enable: false},
+			},
+			expected: nil,
+			msg:      "redirect disabled",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret: "secret",
+				Redirect: &conf_v1.TLSRedirect{
+					Enable: true,
+				},
+			},
+			expected: &version2.TLSRedirect{
+				Code:    301,
+				BasedOn: "$scheme",
+			},
+			msg: "normal case with defaults",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret: "secret",
+				Redirect: &conf_v1.TLSRedirect{
+					Enable:  true,
+					BasedOn: "x-forwarded-proto",
+				},
+			},
+			expected: &version2.TLSRedirect{
+				Code:    301,
+				BasedOn: "$http_x_forwarded_proto",
+			},
+			msg: "normal case with BasedOn set",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateTLSRedirectConfig(test.inputTLS)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateTLSRedirectConfig() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestGenerateTLSRedirectBasedOn(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		basedOn  string
+		expected string
+	}{
+		{
+			basedOn:  "scheme",
+			expected: "$scheme",
+		},
+		{
+			basedOn:  "x-forwarded-proto",
+			expected: "$http_x_forwarded_proto",
+		},
+		{
+			basedOn:  "",
+			expected: "$scheme",
+		},
+	}
+	for _, test := range tests {
+		result := generateTLSRedirectBasedOn(test.basedOn)
+		if result != test.expected {
+			t.Errorf("generateTLSRedirectBasedOn(%v) returned %v but expected %v", test.basedOn, result, test.expected)
+		}
+	}
+}
+
+func TestCreateUpstreamsForPlus(t *testing.T) {
+	t.Parallel()
+	virtualServerEx := VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:    "tea",
+						Service: "tea-svc",
+						Port:    80,
+					},
+					{
+						Name:    "test",
+						Service: "test-svc",
+						Port:    80,
+					},
+					{
+						Name:        "subselector-test",
+						Service:     "test-svc",
+						Subselector: map[string]string{"vs": "works"},
+						Port:        80,
+					},
+					{
+						Name:    "external",
+						Service: "external-svc",
+						Port:    80,
+					},
+				},
+				Routes: []conf_v1.Route{
+					{
+						Path: "/tea",
+						Action: &conf_v1.Action{
+							Pass: "tea",
+						},
+					},
+					{
+						Path:  "/coffee",
+						Route: "default/coffee",
+					},
+					{
+						Path: "/external",
+						Action: &conf_v1.Action{
+							Pass: "external",
+						},
+					},
+				},
+			},
+		},
+		Endpoints: map[string][]string{
+			"default/tea-svc:80": {
+				"10.0.0.20:80",
+			},
+			"default/test-svc:80": {},
+			"default/test-svc_vs=works:80": {
+				"10.0.0.30:80",
+			},
+			"default/coffee-svc:80": {
+				"10.0.0.40:80",
+			},
+			"default/test-svc_vsr=works:80": {
+				"10.0.0.50:80",
+			},
+			"default/external-svc:80": {
+				"example.com:80",
+			},
+		},
+		ExternalNameSvcs: map[string]bool{
+			"default/external-svc": true,
+		},
+		VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
+			{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "coffee",
+					Namespace: "default",
+				},
+				Spec: conf_v1.VirtualServerRouteSpec{
+					Host: "cafe.example.com",
+					Upstreams: []conf_v1.Upstream{
+						{
+							Name:    "coffee",
+							Service: "coffee-svc",
+							Port:    80,
+						},
+						{
+							Name:        "subselector-test",
+							Service:     "test-svc",
+							Subselector: map[string]string{"vsr": "works"},
+							Port:        80,
+						},
+					},
+					Subroutes: []conf_v1.Route{
+						{
+							Path: "/coffee",
+							Action: &conf_v1.Action{
+								Pass: "coffee",
+							},
+						},
+						{
+							Path: "/coffee/sub",
+							Action: &conf_v1.Action{
+								Pass: "subselector-test",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expected := []version2.Upstream{
+		{
+			Name: "vs_default_cafe_tea",
+			UpstreamLabels: version2.UpstreamLabels{
+				Service:           "tea-svc",
+				ResourceType:      "virtualserver",
+				ResourceNamespace: "default",
+				ResourceName:      "cafe",
+			},
+			Servers: []version2.UpstreamServer{
+				{
+					Address: "10.0.0.20:80",
+				},
+			},
+		},
+		{
+			Name: "vs_default_cafe_test",
+			UpstreamLabels: version2.UpstreamLabels{
+				Service:           "test-svc",
+				ResourceType:      "virtualserver",
+				ResourceNamespace: "default",
+				ResourceName:      "cafe",
+			},
+			Servers: nil,
+		},
+		{
+			Name: "vs_default_cafe_subselector-test",
+			UpstreamLabels: version2.UpstreamLabels{
+				Service:           "test-svc",
+				ResourceType:      "virtualserver",
+				ResourceNamespace: "default",
+				ResourceName:      "cafe",
+			},
+			Servers: []version2.UpstreamServer{
+				{
+					Address: "10.0.0.30:80",
+				},
+			},
+		},
+		{
+			Name: "vs_default_cafe_vsr_default_coffee_coffee",
+			UpstreamLabels: version2.UpstreamLabels{
+				Service:           "coffee-svc",
+				ResourceType:      "virtualserverroute",
+				ResourceNamespace: "default",
+				ResourceName:      "coffee",
+			},
+			Servers: []version2.UpstreamServer{
+				{
+					Address: "10.0.0.40:80",
+				},
+			},
+		},
+		{
+			Name: "vs_default_cafe_vsr_default_coffee_subselector-test",
+			UpstreamLabels: version2.UpstreamLabels{
+				Service:           "test-svc",
+				ResourceType:      "virtualserverroute",
+				ResourceNamespace: "default",
+				ResourceName:      "coffee",
+			},
+			Servers: []version2.UpstreamServer{
+				{
+					Address: "10.0.0.50:80",
+				},
+			},
+		},
+	}
+
+	result := createUpstreamsForPlus(&virtualServerEx, nil, &ConfigParams{}, &StaticConfigParams{})
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("createUpstreamsForPlus returned \n%v but expected \n%v", result, expected)
+	}
+}
+
+func TestCreateUpstreamServersConfigForPlus(t *testing.T) {
+	t.Parallel()
+	upstream := version2.Upstream{
+		Servers: []version2.UpstreamServer{
+			{
+				Address: "10.0.0.20:80",
+			},
+		},
+		MaxFails:    21,
+		MaxConns:    16,
+		FailTimeout: "30s",
+		SlowStart:   "50s",
+	}
+
+	expected := nginx.ServerConfig{
+		MaxFails:    21,
+		MaxConns:    16,
+		FailTimeout: "30s",
+		SlowStart:   "50s",
+	}
+
+	result := createUpstreamServersConfigForPlus(upstream)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("createUpstreamServersConfigForPlus returned %v but expected %v", result, expected)
+	}
+}
+
+func TestCreateUpstreamServersConfigForPlusNoUpstreams(t *testing.T) {
+	t.Parallel()
+	noUpstream := version2.Upstream{}
+	expected := nginx.ServerConfig{}
+
+	result := createUpstreamServersConfigForPlus(noUpstream)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("createUpstreamServersConfigForPlus returned %v but expected %v", result, expected)
+	}
+}
+
+func TestGenerateSplits(t *testing.T) {
+	t.Parallel()
+	originalPath := "/path"
+	splits := []conf_v1.Split{
+		{
+			Weight: 90,
+			Action: &conf_v1.Action{
+				Proxy: &conf_v1.ActionProxy{
+					Upstream:    "coffee-v1",
+					RewritePath: "/rewrite",
+				},
+			},
+		},
+		{
+			Weight: 9,
+			Action: &conf_v1.Action{
+				Pass: "coffee-v2",
+			},
+		},
+		{
+			Weight: 1,
+			Action: &conf_v1.Action{
+				Return: &conf_v1.ActionReturn{
+					Body: "hello",
+				},
+			},
+		},
+	}
+
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+	upstreamNamer := newUpstreamNamerForVirtualServer(&virtualServer)
+	variableNamer := newVariableNamer(&virtualServer)
+	scIndex := 1
+	cfgParams := ConfigParams{}
+	crUpstreams := map[string]conf_v1.Upstream{
+		"vs_default_cafe_coffee-v1": {
+			Service: "coffee-v1",
+		},
+		"vs_default_cafe_coffee-v2": {
+			Service: "coffee-v2",
+		},
+	}
+	locSnippet := "# location snippet"
+	enableSnippets := true
+	errorPages := []conf_v1.ErrorPage{
+		{
+			Codes: []int{400, 500},
+			Return: &conf_v1.ErrorPageReturn{
+				ActionReturn: conf_v1.ActionReturn{
+					Code: 200,
+					Type: "application/json",
+					Body: `{\"message\": \"ok\"}`,
+				},
+				Headers: []conf_v1.Header{
+					{
+						Name:  "Set-Cookie",
+						Value: "cookie1=value",
+					},
+				},
+			},
+			Redirect: nil,
+		},
+		{
+			Codes:  []int{500, 502},
+			Return: nil,
+			Redirect: &conf_v1.ErrorPageRedirect{
+				ActionRedirect: conf_v1.ActionRedirect{
+					URL:  "http://nginx.com",
+					Code: 301,
+				},
+			},
+		},
+	}
+	expectedSplitClient := version2.SplitClient{
+		Source:   "$request_id",
+		Variable: "$vs_default_cafe_splits_1",
+		Distributions: []version2.Distribution{
+			{
+				Weight: "90%",
+				Value:  "/internal_location_splits_1_split_0",
+			},
+			{
+				Weight: "9%",
+				Value:  "/internal_location_splits_1_split_1",
+			},
+			{
+				Weight: "1%",
+				Value:  "/internal_location_splits_1_split_2",
+			},
+		},
+	}
+	expectedLocations := []version2.Location{
+		{
+			Path:      "/internal_location_splits_1_split_0",
+			ProxyPass: "http://vs_default_cafe_coffee-v1",
+			Rewrites: []string{
+				"^ $request_uri_no_args",
+				fmt.Sprintf(`"^%v(.*)$" "/rewrite$1" break`, originalPath),
+			},
+			ProxyNextUpstream:        "error timeout",
+			ProxyNextUpstreamTimeout: "0s",
+			ProxyNextUpstreamTries:   0,
+			ProxyInterceptErrors:     true,
+			Internal:                 true,
+			ErrorPages: []version2.ErrorPage{
+				{
+					Name:         "@error_page_0_0",
+					Codes:        "400 500",
+					ResponseCode: 200,
+				},
+				{
+					Name:         "http://nginx.com",
+					Codes:        "500 502",
+					ResponseCode: 301,
+				},
+			},
+			ProxySSLName:            "coffee-v1.default.svc",
+			ProxyPassRequestHeaders: true,
+			ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
+			Snippets:                []string{locSnippet},
+			ServiceName:             "coffee-v1",
+			IsVSR:                   true,
+			VSRName:                 "coffee",
+			VSRNamespace:            "default",
+		},
+		{
+			Path:                     "/internal_location_splits_1_split_1",
+			ProxyPass:                "http://vs_default_cafe_coffee-v2$request_uri",
+			ProxyNextUpstream:        "error timeout",
+			ProxyNextUpstreamTimeout: "0s",
+			ProxyNextUpstreamTries:   0,
+			ProxyInterceptErrors:     true,
+			Internal:                 true,
+			ErrorPages: []version2.ErrorPage{
+				{
+					Name:         "@error_page_0_0",
+					Codes:        "400 500",
+					ResponseCode: 200,
+				},
+				{
+					Name:         "http://nginx.com",
+					Codes:        "500 502",
+					ResponseCode: 301,
+				},
+			},
+			ProxySSLName:            "coffee-v2.default.svc",
+			ProxyPassRequestHeaders: true,
+			ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
+			Snippets:                []string{locSnippet},
+			ServiceName:             "coffee-v2",
+			IsVSR:                   true,
+			VSRName:                 "coffee",
+			VSRNamespace:            "default",
+		},
+		{
+			Path:                 "/internal_location_splits_1_split_2",
+			ProxyInterceptErrors: true,
+			ErrorPages: []version2.ErrorPage{
+				{
+					Name:         "@return_1",
+					Codes:        "418",
+					ResponseCode: 200,
+				},
+			},
+			InternalProxyPass: "http://unix:/var/lib/nginx/nginx-418-server.sock",
+		},
+	}
+	expectedReturnLocations := []version2.ReturnLocation{
+		{
+			Name:        "@return_1",
+			DefaultType: "text/plain",
+			Return: version2.Return{
+				Code: 0,
+				Text: "hello",
+			},
+		},
+	}
+	returnLocationIndex := 1
+
+	errorPageDetails := errorPageDetails{
+		pages: errorPages,
+		index: 0,
+		owner: nil,
+	}
+
+	vsc := newVirtualServerConfigurator(&cfgParams, false, false, &StaticConfigParams{}, false)
+
+	resultSplitClient, resultLocations, resultReturnLocations := generateSplits(
+		splits,
+		upstreamNamer,
+		crUpstreams,
+		variableNamer,
+		scIndex,
+		&cfgParams,
+		errorPageDetails,
+		originalPath,
+		locSnippet,
+		enableSnippets,
+		returnLocationIndex,
+		true,
+		"coffee",
+		"default",
+		vsc.warnings,
+	)
+
+	if diff := cmp.Diff(expectedSplitClient, resultSplitClient); diff != "" {
+		t.Errorf("generateSplits() resultSplitClient mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedLocations, resultLocations); diff != "" {
+		t.Errorf("generateSplits() resultLocations mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedReturnLocations, resultReturnLocations); diff != "" {
+		t.Errorf("generateSplits() resultReturnLocations mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateDefaultSplitsConfig(t *testing.T) {
+	t.Parallel()
+	route := conf_v1.Route{
+		Path: "/",
+		Splits: []conf_v1.Split{
+			{
+				Weight: 90,
+				Action: &conf_v1.Action{
+					Pass: "coffee-v1",
+				},
+			},
+			{
+				Weight: 10,
+				Action: &conf_v1.Action{
+					Pass: "coffee-v2",
+				},
+			},
+		},
+	}
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+	upstreamNamer := newUpstreamNamerForVirtualServer(&virtualServer)
+	variableNamer := newVariableNamer(&virtualServer)
+	index := 1
+
+	expected := routingCfg{
+		SplitClients: []version2.SplitClient{
+			{
+				Source:   "$request_id",
+				Variable: "$vs_default_cafe_splits_1",
+				Distributions: []version2.Distribution{
+					{
+						Weight: "90%",
+						Value:  "/internal_location_splits_1_split_0",
+					},
+					{
+						Weight: "10%",
+						Value:  "/internal_location_splits_1_split_1",
+					},
+				},
+			},
+		},
+		Locations: []version2.Location{
+			{
+				Path:                     "/internal_location_splits_1_split_0",
+				ProxyPass:                "http://vs_default_cafe_coffee-v1$request_uri",
+				ProxyNextUpstream:        "error timeout",
+				ProxyNextUpstreamTimeout: "0s",
+				ProxyNextUpstreamTries:   0,
+				Internal:                 true,
+				ProxySSLName:             "coffee-v1.default.svc",
+				ProxyPassRequestHeaders:  true,
+				ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+				ServiceName:              "coffee-v1",
+				IsVSR:                    true,
+				VSRName:                  "coffee",
+				VSRNamespace:             "default",
+			},
+			{
+				Path:                     "/internal_location_splits_1_split_1",
+				ProxyPass:                "http://vs_default_cafe_coffee-v2$request_uri",
+				ProxyNextUpstream:        "error timeout",
+				ProxyNextUpstreamTimeout: "0s",
+				ProxyNextUpstreamTries:   0,
+				Internal:                 true,
+				ProxySSLName:             "coffee-v2.default.svc",
+				ProxyPassRequestHeaders:  true,
+				ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+				ServiceName:              "coffee-v2",
+				IsVSR:                    true,
+				VSRName:                  "coffee",
+				VSRNamespace:             "default",
+			},
+		},
+		InternalRedirectLocation: version2.InternalRedirectLocation{
+			Path:        "/",
+			Destination: "$vs_default_cafe_splits_1",
+		},
+	}
+
+	cfgParams := ConfigParams{}
+	locSnippet := ""
+	enableSnippets := false
+	crUpstreams := map[string]conf_v1.Upstream{
+		"vs_default_cafe_coffee-v1": {
+			Service: "coffee-v1",
+		},
+		"vs_default_cafe_coffee-v2": {
+			Service: "coffee-v2",
+		},
+	}
+
+	errorPageDetails := errorPageDetails{
+		pages: route.ErrorPages,
+		index: 0,
+		owner: nil,
+	}
+
+	result := generateDefaultSplitsConfig(route, upstreamNamer, crUpstreams, variableNamer, index, &cfgParams,
+		errorPageDetails, "", locSnippet, enableSnippets, 0, true, "coffee", "default", Warnings{})
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateDefaultSplitsConfig() returned \n%+v but expected \n%+v", result, expected)
+	}
+}
+
+func TestGenerateMatchesConfig(t *testing.T) {
+	t.Parallel()
+	route := conf_v1.Route{
+		Path: "/",
+		Matches: []conf_v1.Match{
+			{
+				Conditions: []conf_v1.Condition{
+					{
+						Header: "x-version",
+						Value:  "v1",
+					},
+					{
+						Cookie: "user",
+						Value:  "john",
+					},
+					{
+						Argument: "answer",
+						Value:    "yes",
+					},
+					{
+						Variable: "$request_method",
+						Value:    "GET",
+					},
+				},
+				Action: &conf_v1.Action{
+					Pass: "coffee-v1",
+				},
+			},
+			{
+				Conditions: []conf_v1.Condition{
+					{
+						Header: "x-version",
+						Value:  "v2",
+					},
+					{
+						Cookie: "user",
+						Value:  "paul",
+					},
+					{
+						Argument: "answer",
+						Value:    "no",
+					},
+					{
+						Variable: "$request_method",
+						Value:    "POST",
+					},
+				},
+				Splits: []conf_v1.Split{
+					{
+						Weight: 90,
+						Action: &conf_v1.Action{
+							Pass: "coffee-v1",
+						},
+					},
+					{
+						Weight: 10,
+						Action: &conf_v1.Action{
+							Pass: "coffee-v2",
+						},
+					},
+				},
+			},
+		},
+		Action: &conf_v1.Action{
+			Pass: "tea",
+		},
+	}
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+	errorPages := []conf_v1.ErrorPage{
+		{
+			Codes: []int{400, 500},
+			Return: &conf_v1.ErrorPageReturn{
+				ActionReturn: conf_v1.ActionReturn{
+					Code: 200,
+					Type: "application/json",
+					Body: `{\"message\": \"ok\"}`,
+				},
+				Headers: []conf_v1.Header{
+					{
+						Name:  "Set-Cookie",
+						Value: "cookie1=value",
+					},
+				},
+			},
+			Redirect: nil,
+		},
+		{
+			Codes:  []int{500, 502},
+			Return: nil,
+			Redirect: &conf_v1.ErrorPageRedirect{
+				ActionRedirect: conf_v1.ActionRedirect{
+					URL:  "http://nginx.com",
+					Code: 301,
+				},
+			},
+		},
+	}
+	upstreamNamer := newUpstreamNamerForVirtualServer(&virtualServer)
+	variableNamer := newVariableNamer(&virtualServer)
+	index := 1
+	scIndex := 2
+
+	expected := routingCfg{
+		Maps: []version2.Map{
+			{
+				Source:   "$http_x_version",
+				Variable: "$vs_default_cafe_matches_1_match_0_cond_0",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `"v1"`,
+						Result: "$vs_default_cafe_matches_1_match_0_cond_1",
+					},
+					{
+						Value:  "default",
+						Result: "0",
+					},
+				},
+			},
+			{
+				Source:   "$cookie_user",
+				Variable: "$vs_default_cafe_matches_1_match_0_cond_1",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `"john"`,
+						Result: "$vs_default_cafe_matches_1_match_0_cond_2",
+					},
+					{
+						Value:  "default",
+						Result: "0",
+					},
+				},
+			},
+			{
+				Source:   "$arg_answer",
+				Variable: "$vs_default_cafe_matches_1_match_0_cond_2",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `"yes"`,
+						Result: "$vs_default_cafe_matches_1_match_0_cond_3",
+					},
+					{
+						Value:  "default",
+						Result: "0",
+					},
+				},
+			},
+			{
+				Source:   "$request_method",
+				Variable: "$vs_default_cafe_matches_1_match_0_cond_3",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `"GET"`,
+						Result: "1",
+					},
+					{
+						Value:  "default",
+						Result: "0",
+					},
+				},
+			},
+			{
+				Source:   "$http_x_version",
+				Variable: "$vs_default_cafe_matches_1_match_1_cond_0",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `"v2"`,
+						Result: "$vs_default_cafe_matches_1_match_1_cond_1",
+					},
+					{
+						Value:  "default",
+						Result: "0",
+					},
+				},
+			},
+			{
+				Source:   "$cookie_user",
+				Variable: "$vs_default_cafe_matches_1_match_1_cond_1",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `"paul"`,
+						Result: "$vs_default_cafe_matches_1_match_1_cond_2",
+					},
+					{
+						Value:  "default",
+						Result: "0",
+					},
+				},
+			},
+			{
+				Source:   "$arg_answer",
+				Variable: "$vs_default_cafe_matches_1_match_1_cond_2",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `"no"`,
+						Result: "$vs_default_cafe_matches_1_match_1_cond_3",
+					},
+					{
+						Value:  "default",
+						Result: "0",
+					},
+				},
+			},
+			{
+				Source:   "$request_method",
+				Variable: "$vs_default_cafe_matches_1_match_1_cond_3",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `"POST"`,
+						Result: "1",
+					},
+					{
+						Value:  "default",
+						Result: "0",
+					},
+				},
+			},
+			{
+				Source:   "$vs_default_cafe_matches_1_match_0_cond_0$vs_default_cafe_matches_1_match_1_cond_0",
+				Variable: "$vs_default_cafe_matches_1",
+				Parameters: []version2.Parameter{
+					{
+						Value:  "~^1",
+						Result: "/internal_location_matches_1_match_0",
+					},
+					{
+						Value:  "~^01",
+						Result: "$vs_default_cafe_splits_2",
+					},
+					{
+						Value:  "default",
+						Result: "/internal_location_matches_1_default",
+					},
+				},
+			},
+		},
+		Locations: []version2.Location{
+			{
+				Path:                     "/internal_location_matches_1_match_0",
+				ProxyPass:                "http://vs_default_cafe_coffee-v1$request_uri",
+				ProxyNextUpstream:        "error timeout",
+				ProxyNextUpstreamTimeout: "0s",
+				ProxyNextUpstreamTries:   0,
+				ProxyInterceptErrors:     true,
+				Internal:                 true,
+				ErrorPages: []version2.ErrorPage{
+					{
+						Name:         "@error_page_2_0",
+						Codes:        "400 500",
+						ResponseCode: 200,
+					},
+					{
+						Name:         "http://nginx.com",
+						Codes:        "500 502",
+						ResponseCode: 301,
+					},
+				},
+				ProxySSLName:            "coffee-v1.default.svc",
+				ProxyPassRequestHeaders: true,
+				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
+				ServiceName:             "coffee-v1",
+				IsVSR:                   false,
+				VSRName:                 "",
+				VSRNamespace:            "",
+			},
+			{
+				Path:                     "/internal_location_splits_2_split_0",
+				ProxyPass:                "http://vs_default_cafe_coffee-v1$request_uri",
+				ProxyNextUpstream:        "error timeout",
+				ProxyNextUpstreamTimeout: "0s",
+				ProxyNextUpstreamTries:   0,
+				ProxyInterceptErrors:     true,
+				Internal:                 true,
+				ErrorPages: []version2.ErrorPage{
+					{
+						Name:         "@error_page_2_0",
+						Codes:        "400 500",
+						ResponseCode: 200,
+					},
+					{
+						Name:         "http://nginx.com",
+						Codes:        "500 502",
+						ResponseCode: 301,
+					},
+				},
+				ProxySSLName:            "coffee-v1.default.svc",
+				ProxyPassRequestHeaders: true,
+				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
+				ServiceName:             "coffee-v1",
+				IsVSR:                   false,
+				VSRName:                 "",
+				VSRNamespace:            "",
+			},
+			{
+				Path:                     "/internal_location_splits_2_split_1",
+				ProxyPass:                "http://vs_default_cafe_coffee-v2$request_uri",
+				ProxyNextUpstream:        "error timeout",
+				ProxyNextUpstreamTimeout: "0s",
+				ProxyNextUpstreamTries:   0,
+				ProxyInterceptErrors:     true,
+				Internal:                 true,
+				ErrorPages: []version2.ErrorPage{
+					{
+						Name:         "@error_page_2_0",
+						Codes:        "400 500",
+						ResponseCode: 200,
+					},
+					{
+						Name:         "http://nginx.com",
+						Codes:        "500 502",
+						ResponseCode: 301,
+					},
+				},
+				ProxySSLName:            "coffee-v2.default.svc",
+				ProxyPassRequestHeaders: true,
+				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
+				ServiceName:             "coffee-v2",
+				IsVSR:                   false,
+				VSRName:                 "",
+				VSRNamespace:            "",
+			},
+			{
+				Path:                     "/internal_location_matches_1_default",
+				ProxyPass:                "http://vs_default_cafe_tea$request_uri",
+				ProxyNextUpstream:        "error timeout",
+				ProxyNextUpstreamTimeout: "0s",
+				ProxyNextUpstreamTries:   0,
+				ProxyInterceptErrors:     true,
+				Internal:                 true,
+				ErrorPages: []version2.ErrorPage{
+					{
+						Name:         "@error_page_2_0",
+						Codes:        "400 500",
+						ResponseCode: 200,
+					},
+					{
+						Name:         "http://nginx.com",
+						Codes:        "500 502",
+						ResponseCode: 301,
+					},
+				},
+				ProxySSLName:            "tea.default.svc",
+				ProxyPassRequestHeaders: true,
+				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
+				ServiceName:             "tea",
+				IsVSR:                   false,
+				VSRName:                 "",
+				VSRNamespace:            "",
+			},
+		},
+		InternalRedirectLocation: version2.InternalRedirectLocation{
+			Path:        "/",
+			Destination: "$vs_default_cafe_matches_1",
+		},
+		SplitClients: []version2.SplitClient{
+			{
+				Source:   "$request_id",
+				Variable: "$vs_default_cafe_splits_2",
+				Distributions: []version2.Distribution{
+					{
+						Weight: "90%",
+						Value:  "/internal_location_splits_2_split_0",
+					},
+					{
+						Weight: "10%",
+						Value:  "/internal_location_splits_2_split_1",
+					},
+				},
+			},
+		},
+	}
+
+	cfgParams := ConfigParams{}
+	enableSnippets := false
+	locSnippets := ""
+	crUpstreams := map[string]conf_v1.Upstream{
+		"vs_default_cafe_coffee-v1": {Service: "coffee-v1"},
+		"vs_default_cafe_coffee-v2": {Service: "coffee-v2"},
+		"vs_default_cafe_tea":       {Service: "tea"},
+	}
+
+	errorPageDetails := errorPageDetails{
+		pages: errorPages,
+		index: 2,
+		owner: nil,
+	}
+
+	result := generateMatchesConfig(
+		route,
+		upstreamNamer,
+		crUpstreams,
+		variableNamer,
+		index,
+		scIndex,
+		&cfgParams,
+		errorPageDetails,
+		locSnippets,
+		enableSnippets,
+		0,
+		false,
+		"",
+		"",
+		Warnings{},
+	)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateMatchesConfig() returned \n%+v but expected \n%+v", result, expected)
+	}
+}
+
+func TestGenerateMatchesConfigWithMultipleSplits(t *testing.T) {
+	t.Parallel()
+	route := conf_v1.Route{
+		Path: "/",
+		Matches: []conf_v1.Match{
+			{
+				Conditions: []conf_v1.Condition{
+					{
+						Header: "x-version",
+						Value:  "v1",
+					},
+				},
+				Splits: []conf_v1.Split{
+					{
+						Weight: 30,
+						Action: &conf_v1.Action{
+							Pass: "coffee-v1",
+						},
+					},
+					{
+						Weight: 70,
+						Action: &conf_v1.Action{
+							Pass: "coffee-v2",
+						},
+					},
+				},
+			},
+			{
+				Conditions: []conf_v1.Condition{
+					{
+						Header: "x-version",
+						Value:  "v2",
+					},
+				},
+				Splits: []conf_v1.Split{
+					{
+						Weight: 90,
+						Action: &conf_v1.Action{
+							Pass: "coffee-v2",
+						},
+					},
+					{
+						Weight: 10,
+						Action: &conf_v1.Action{
+							Pass: "coffee-v1",
+						},
+					},
+				},
+			},
+		},
+		Splits: []conf_v1.Split{
+			{
+				Weight: 99,
+				Action: &conf_v1.Action{
+					Pass: "coffee-v1",
+				},
+			},
+			{
+				Weight: 1,
+				Action: &conf_v1.Action{
+					Pass: "coffee-v2",
+				},
+			},
+		},
+	}
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+	upstreamNamer := newUpstreamNamerForVirtualServer(&virtualServer)
+	variableNamer := newVariableNamer(&virtualServer)
+	index := 1
+	scIndex := 2
+	errorPages := []conf_v1.ErrorPage{
+		{
+			Codes: []int{400, 500},
+			Return: &conf_v1.ErrorPageReturn{
+				ActionReturn: conf_v1.ActionReturn{
+					Code: 200,
+					Type: "application/json",
+					Body: `{\"message\": \"ok\"}`,
+				},
+				Headers: []conf_v1.Header{
+					{
+						Name:  "Set-Cookie",
+						Value: "cookie1=value",
+					},
+				},
+			},
+			Redirect: nil,
+		},
+		{
+			Codes:  []int{500, 502},
+			Return: nil,
+			Redirect: &conf_v1.ErrorPageRedirect{
+				ActionRedirect: conf_v1.ActionRedirect{
+					URL:  "http://nginx.com",
+					Code: 301,
+				},
+			},
+		},
+	}
+
+	expected := routingCfg{
+		Maps: []version2.Map{
+			{
+				Source:   "$http_x_version",
+				Variable: "$vs_default_cafe_matches_1_match_0_cond_0",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `"v1"`,
+						Result: "1",
+					},
+					{
+						Value:  "default",
+						Result: "0",
+					},
+				},
+			},
+			{
+				Source:   "$http_x_version",
+				Variable: "$vs_default_cafe_matches_1_match_1_cond_0",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `"v2"`,
+						Result: "1",
+					},
+					{
+						Value:  "default",
+						Result: "0",
+					},
+				},
+			},
+			{
+				Source:   "$vs_default_cafe_matches_1_match_0_cond_0$vs_default_cafe_matches_1_match_1_cond_0",
+				Variable: "$vs_default_cafe_matches_1",
+				Parameters: []version2.Parameter{
+					{
+						Value:  "~^1",
+						Result: "$vs_default_cafe_splits_2",
+					},
+					{
+						Value:  "~^01",
+						Result: "$vs_default_cafe_splits_3",
+					},
+					{
+						Value:  "default",
+						Result: "$vs_default_cafe_splits_4",
+					},
+				},
+			},
+		},
+		Locations: []version2.Location{
+			{
+				Path:                     "/internal_location_splits_2_split_0",
+				ProxyPass:                "http://vs_default_cafe_coffee-v1$request_uri",
+				ProxyNextUpstream:        "error timeout",
+				ProxyNextUpstreamTimeout: "0s",
+				ProxyNextUpstreamTries:   0,
+				Internal:                 true,
+				ErrorPages: []version2.ErrorPage{
+					{
+						Name:         "@error_page_0_0",
+						Codes:        "400 500",
+						ResponseCode: 200,
+					},
+					{
+						Name:         "http://nginx.com",
+						Codes:        "500 502",
+						ResponseCode: 301,
+					},
+				},
+				ProxyInterceptErrors:    true,
+				ProxySSLName:            "coffee-v1.default.svc",
+				ProxyPassRequestHeaders: true,
+				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
+				ServiceName:             "coffee-v1",
+				IsVSR:                   true,
+				VSRName:                 "coffee",
+				VSRNamespace:            "default",
+			},
+			{
+				Path:                     "/internal_location_splits_2_split_1",
+				ProxyPass:                "http://vs_default_cafe_coffee-v2$request_uri",
+				ProxyNextUpstream:        "error timeout",
+				ProxyNextUpstreamTimeout: "0s",
+				ProxyNextUpstreamTries:   0,
+				Internal:                 true,
+				ErrorPages: []version2.ErrorPage{
+					{
+						Name:         "@error_page_0_0",
+						Codes:        "400 500",
+						ResponseCode: 200,
+					},
+					{
+						Name:         "http://nginx.com",
+						Codes:        "500 502",
+						ResponseCode: 301,
+					},
+				},
+				ProxyInterceptErrors:    true,
+				ProxySSLName:            "coffee-v2.default.svc",
+				ProxyPassRequestHeaders: true,
+				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
+				ServiceName:             "coffee-v2",
+				IsVSR:                   true,
+				VSRName:                 "coffee",
+				VSRNamespace:            "default",
+			},
+			{
+				Path:                     "/internal_location_splits_3_split_0",
+				ProxyPass:                "http://vs_default_cafe_coffee-v2$request_uri",
+				ProxyNextUpstream:        "error timeout",
+				ProxyNextUpstreamTimeout: "0s",
+				ProxyNextUpstreamTries:   0,
+				Internal:                 true,
+				ErrorPages: []version2.ErrorPage{
+					{
+						Name:         "@error_page_0_0",
+						Codes:        "400 500",
+						ResponseCode: 200,
+					},
+					{
+						Name:         "http://nginx.com",
+						Codes:        "500 502",
+						ResponseCode: 301,
+					},
+				},
+				ProxyInterceptErrors:    true,
+				ProxySSLName:            "coffee-v2.default.svc",
+				ProxyPassRequestHeaders: true,
+				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
+				ServiceName:             "coffee-v2",
+				IsVSR:                   true,
+				VSRName:                 "coffee",
+				VSRNamespace:            "default",
+			},
+			{
+				Path:                     "/internal_location_splits_3_split_1",
+				ProxyPass:                "http://vs_default_cafe_coffee-v1$request_uri",
+				ProxyNextUpstream:        "error timeout",
+				ProxyNextUpstreamTimeout: "0s",
+				ProxyNextUpstreamTries:   0,
+				Internal:                 true,
+				ErrorPages: []version2.ErrorPage{
+					{
+						Name:         "@error_page_0_0",
+						Codes:        "400 500",
+						ResponseCode: 200,
+					},
+					{
+						Name:         "http://nginx.com",
+						Codes:        "500 502",
+						ResponseCode: 301,
+					},
+				},
+				ProxyInterceptErrors:    true,
+				ProxySSLName:            "coffee-v1.default.svc",
+				ProxyPassRequestHeaders: true,
+				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
+				ServiceName:             "coffee-v1",
+				IsVSR:                   true,
+				VSRName:                 "coffee",
+				VSRNamespace:            "default",
+			},
+			{
+				Path:                     "/internal_location_splits_4_split_0",
+				ProxyPass:                "http://vs_default_cafe_coffee-v1$request_uri",
+				ProxyNextUpstream:        "error timeout",
+				ProxyNextUpstreamTimeout: "0s",
+				ProxyNextUpstreamTries:   0,
+				Internal:                 true,
+				ErrorPages: []version2.ErrorPage{
+					{
+						Name:         "@error_page_0_0",
+						Codes:        "400 500",
+						ResponseCode: 200,
+					},
+					{
+						Name:         "http://nginx.com",
+						Codes:        "500 502",
+						ResponseCode: 301,
+					},
+				},
+				ProxyInterceptErrors:    true,
+				ProxySSLName:            "coffee-v1.default.svc",
+				ProxyPassRequestHeaders: true,
+				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
+				ServiceName:             "coffee-v1",
+				IsVSR:                   true,
+				VSRName:                 "coffee",
+				VSRNamespace:            "default",
+			},
+			{
+				Path:                     "/internal_location_splits_4_split_1",
+				ProxyPass:                "http://vs_default_cafe_coffee-v2$request_uri",
+				ProxyNextUpstream:        "error timeout",
+				ProxyNextUpstreamTimeout: "0s",
+				ProxyNextUpstreamTries:   0,
+				Internal:                 true,
+				ErrorPages: []version2.ErrorPage{
+					{
+						Name:         "@error_page_0_0",
+						Codes:        "400 500",
+						ResponseCode: 200,
+					},
+					{
+						Name:         "http://nginx.com",
+						Codes:        "500 502",
+						ResponseCode: 301,
+					},
+				},
+				ProxyInterceptErrors:    true,
+				ProxySSLName:            "coffee-v2.default.svc",
+				ProxyPassRequestHeaders: true,
+				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
+				ServiceName:             "coffee-v2",
+				IsVSR:                   true,
+				VSRName:                 "coffee",
+				VSRNamespace:            "default",
+			},
+		},
+		InternalRedirectLocation: version2.InternalRedirectLocation{
+			Path:        "/",
+			Destination: "$vs_default_cafe_matches_1",
+		},
+		SplitClients: []version2.SplitClient{
+			{
+				Source:   "$request_id",
+				Variable: "$vs_default_cafe_splits_2",
+				Distributions: []version2.Distribution{
+					{
+						Weight: "30%",
+						Value:  "/internal_location_splits_2_split_0",
+					},
+					{
+						Weight: "70%",
+						Value:  "/internal_location_splits_2_split_1",
+					},
+				},
+			},
+			{
+				Source:   "$request_id",
+				Variable: "$vs_default_cafe_splits_3",
+				Distributions: []version2.Distribution{
+					{
+						Weight: "90%",
+						Value:  "/internal_location_splits_3_split_0",
+					},
+					{
+						Weight: "10%",
+						Value:  "/internal_location_splits_3_split_1",
+					},
+				},
+			},
+			{
+				Source:   "$request_id",
+				Variable: "$vs_default_cafe_splits_4",
+				Distributions: []version2.Distribution{
+					{
+						Weight: "99%",
+						Value:  "/internal_location_splits_4_split_0",
+					},
+					{
+						Weight: "1%",
+						Value:  "/internal_location_splits_4_split_1",
+					},
+				},
+			},
+		},
+	}
+
+	cfgParams := ConfigParams{}
+	enableSnippets := false
+	locSnippets := ""
+	crUpstreams := map[string]conf_v1.Upstream{
+		"vs_default_cafe_coffee-v1": {Service: "coffee-v1"},
+		"vs_default_cafe_coffee-v2": {Service: "coffee-v2"},
+	}
+
+	errorPageDetails := errorPageDetails{
+		pages: errorPages,
+		index: 0,
+		owner: nil,
+	}
+
+	result := generateMatchesConfig(
+		route,
+		upstreamNamer,
+		crUpstreams,
+		variableNamer,
+		index,
+		scIndex,
+		&cfgParams,
+		errorPageDetails,
+		locSnippets,
+		enableSnippets,
+		0,
+		true,
+		"coffee",
+		"default",
+		Warnings{},
+	)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateMatchesConfig() returned \n%+v but expected \n%+v", result, expected)
+	}
+}
+
+func TestGenerateValueForMatchesRouteMap(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input              string
+		expectedValue      string
+		expectedIsNegative bool
+	}{
+		{
+			input:              "default",
+			expectedValue:      `\default`,
+			expectedIsNegative: false,
+		},
+		{
+			input:              "!default",
+			expectedValue:      `\default`,
+			expectedIsNegative: true,
+		},
+		{
+			input:              "hostnames",
+			expectedValue:      `\hostnames`,
+			expectedIsNegative: false,
+		},
+		{
+			input:              "include",
+			expectedValue:      `\include`,
+			expectedIsNegative: false,
+		},
+		{
+			input:              "volatile",
+			expectedValue:      `\volatile`,
+			expectedIsNegative: false,
+		},
+		{
+			input:              "abc",
+			expectedValue:      `"abc"`,
+			expectedIsNegative: false,
+		},
+		{
+			input:              "!abc",
+			expectedValue:      `"abc"`,
+			expectedIsNegative: true,
+		},
+		{
+			input:              "",
+			expectedValue:      `""`,
+			expectedIsNegative: false,
+		},
+		{
+			input:              "!",
+			expectedValue:      `""`,
+			expectedIsNegative: true,
+		},
+	}
+
+	for _, test := range tests {
+		resultValue, resultIsNegative := generateValueForMatchesRouteMap(test.input)
+		if resultValue != test.expectedValue {
+			t.Errorf("generateValueForMatchesRouteMap(%q) returned %q but expected %q as the value", test.input, resultValue, test.expectedValue)
+		}
+		if resultIsNegative != test.expectedIsNegative {
+			t.Errorf("generateValueForMatchesRouteMap(%q) returned %v but expected %v as the isNegative", test.input, resultIsNegative, test.expectedIsNegative)
+		}
+	}
+}
+
+func TestGenerateParametersForMatchesRouteMap(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		inputMatchedValue     string
+		inputSuccessfulResult string
+		expected              []version2.Parameter
+	}{
+		{
+			inputMatchedValue:     "abc",
+			inputSuccessfulResult: "1",
+			expected: []version2.Parameter{
+				{
+					Value:  `"abc"`,
+					Result: "1",
+				},
+				{
+					Value:  "default",
+					Result: "0",
+				},
+			},
+		},
+		{
+			inputMatchedValue:     "!abc",
+			inputSuccessfulResult: "1",
+			expected: []version2.Parameter{
+				{
+					Value:  `"abc"`,
+					Result: "0",
+				},
+				{
+					Value:  "default",
+					Result: "1",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		result := generateParametersForMatchesRouteMap(test.inputMatchedValue, test.inputSuccessfulResult)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateParametersForMatchesRouteMap(%q, %q) returned %v but expected %v", test.inputMatchedValue, test.inputSuccessfulResult, result, test.expected)
+		}
+	}
+}
+
+func TestGetNameForSourceForMatchesRouteMapFromCondition(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    conf_v1.Condition
+		expected string
+	}{
+		{
+			input: conf_v1.Condition{
+				Header: "x-version",
+			},
+			expected: "$http_x_version",
+		},
+		{
+			input: conf_v1.Condition{
+				Cookie: "mycookie",
+			},
+			expected: "$cookie_mycookie",
+		},
+		{
+			input: conf_v1.Condition{
+				Argument: "arg",
+			},
+			expected: "$arg_arg",
+		},
+		{
+			input: conf_v1.Condition{
+				Variable: "$request_method",
+			},
+			expected: "$request_method",
+		},
+	}
+
+	for _, test := range tests {
+		result := getNameForSourceForMatchesRouteMapFromCondition(test.input)
+		if result != test.expected {
+			t.Errorf("getNameForSourceForMatchesRouteMapFromCondition() returned %q but expected %q for input %v", result, test.expected, test.input)
+		}
+	}
+}
+
+func TestGenerateLBMethod(t *testing.T) {
+	t.Parallel()
+	defaultMethod := "random two least_conn"
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    "",
+			expected: defaultMethod,
+		},
+		{
+			input:    "round_robin",
+			expected: "",
+		},
+		{
+			input:    "random",
+			expected: "random",
+		},
+	}
+	for _, test := range tests {
+		result := generateLBMethod(test.input, defaultMethod)
+		if result != test.expected {
+			t.Errorf("generateLBMethod() returned %q but expected %q for input '%v'", result, test.expected, test.input)
+		}
+	}
+}
+
+func TestUpstreamHasKeepalive(t *testing.T) {
+	t.Parallel()
+	noKeepalive := 0
+	keepalive := 32
+
+	tests := []struct {
+		upstream  conf_v1.Upstream
+		cfgParams *ConfigParams
+		expected  bool
+		msg       string
+	}{
+		{
+			conf_v1.Upstream{},
+			&ConfigParams{Keepalive: keepalive},
+			true,
+			"upstream keepalive not set, configparam keepalive set",
+		},
+		{
+			conf_v1.Upstream{Keepalive: &noKeepalive},
+			&ConfigParams{Keepalive: keepalive},
+			false,
+			"upstream keepalive set to 0, configparam keepalive set",
+		},
+		{
+			conf_v1.Upstream{Keepalive: &keepalive},
+			&ConfigParams{Keepalive: noKeepalive},
+			true,
+			"upstream keepalive set, configparam keepalive set to 0",
+		},
+	}
+
+	for _, test := range tests {
+		result := upstreamHasKeepalive(test.upstream, test.cfgParams)
+		if result != test.expected {
+			t.Errorf("upstreamHasKeepalive() returned %v, but expected %v for the case of %v", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestNewHealthCheckWithDefaults(t *testing.T) {
+	t.Parallel()
+	upstreamName := "test-upstream"
+	baseCfgParams := &ConfigParams{
+		ProxySendTimeout:    "5s",
+		ProxyReadTimeout:    "5s",
+		ProxyConnectTimeout: "5s",
+	}
+	expected := &version2.HealthCheck{
+		Name:                upstreamName,
+		ProxySendTimeout:    "5s",
+		ProxyReadTimeout:    "5s",
+		ProxyConnectTimeout: "5s",
+		ProxyPass:           fmt.Sprintf("http://%v", upstreamName),
+		URI:                 "/",
+		Interval:            "5s",
+		Jitter:              "0s",
+		Fails:               1,
+		Passes:              1,
+		Headers:             make(map[string]string),
+	}
+
+	result := newHealthCheckWithDefaults(conf_v1.Upstream{}, upstreamName, baseCfgParams)
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("newHealthCheckWithDefaults returned \n%v but expected \n%v", result, expected)
+	}
+}
+
+func TestGenerateHealthCheck(t *testing.T) {
+	t.Parallel()
+	upstreamName := "test-upstream"
+	tests := []struct {
+		upstream     conf_v1.Upstream
+		upstreamName string
+		expected     *version2.HealthCheck
+		msg          string
+	}{
+		{
+			upstream: conf_v1.Upstream{
+				HealthCheck: &conf_v1.HealthCheck{
+					Enable:         true,
+					Path:           "/healthz",
+					Interval:       "5s",
+					Jitter:         "2s",
+					Fails:          3,
+					Passes:         2,
+					Port:           8080,
+					ConnectTimeout: "20s",
+					SendTimeout:    "20s",
+					ReadTimeout:    "20s",
+					Headers: []conf_v1.Header{
+						{
+							Name:  "Host",
+							Value: "my.service",
+						},
+						{
+							Name:  "User-Agent",
+							Value: "nginx",
+						},
+					},
+					StatusMatch: "! 500",
+				},
+			},
+			upstreamName: upstreamName,
+			expected: &version2.HealthCheck{
+				Name:                upstreamName,
+				ProxyConnectTimeout: "20s",
+				ProxySendTimeout:    "20s",
+				ProxyReadTimeout:    "20s",
+				ProxyPass:           fmt.Sprintf("http://%v", upstreamName),
+				URI:                 "/healthz",
+				Interval:            "5s",
+				Jitter:              "2s",
+				Fails:               3,
+				Passes:              2,
+				Port:                8080,
+				Headers: map[string]string{
+					"Host":       "my.service",
+					"User-Agent": "nginx",
+				},
+				Match: fmt.Sprintf("%v_match", upstreamName),
+			},
+			msg: "HealthCheck with changed parameters",
+		},
+		{
+			upstream: conf_v1.Upstream{
+				HealthCheck: &conf_v1.HealthCheck{
+					Enable: true,
+				},
+				ProxyConnectTimeout: "30s",
+				ProxyReadTimeout:    "30s",
+				ProxySendTimeout:    "30s",
+			},
+			upstreamName: upstreamName,
+			expected: &version2.HealthCheck{
+				Name:                upstreamName,
+				ProxyConnectTimeout: "30s",
+				ProxyReadTimeout:    "30s",
+				ProxySendTimeout:    "30s",
+				ProxyPass:           fmt.Sprintf("http://%v", upstreamName),
+				URI:                 "/",
+				Interval:            "5s",
+				Jitter:              "0s",
+				Fails:               1,
+				Passes:              1,
+				Headers:             make(map[string]string),
+			},
+			msg: "HealthCheck with default parameters from Upstream",
+		},
+		{
+			upstream: conf_v1.Upstream{
+				HealthCheck: &conf_v1.HealthCheck{
+					Enable: true,
 				},
-				ProxySSLName:            "tea.default.svc",
-				ProxyPassRequestHeaders: true,
-				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
-				ServiceName:             "tea",
-				IsVSR:                   false,
-				VSRName:                 "",
-				VSRNamespace:            "",
 			},
+			upstreamName: upstreamName,
+			expected: &version2.HealthCheck{
+				Name:                upstreamName,
+				ProxyConnectTimeout: "5s",
+				ProxyReadTimeout:    "5s",
+				ProxySendTimeout:    "5s",
+				ProxyPass:           fmt.Sprintf("http://%v", upstreamName),
+				URI:                 "/",
+				Interval:            "5s",
+				Jitter:              "0s",
+				Fails:               1,
+				Passes:              1,
+				Headers:             make(map[string]string),
+			},
+			msg: "HealthCheck with default parameters from ConfigMap (not defined in Upstream)",
 		},
-		InternalRedirectLocation: version2.InternalRedirectLocation{
-			Path:        "/",
-			Destination: "$vs_default_cafe_matches_1",
+		{
+			upstream:     conf_v1.Upstream{},
+			upstreamName: upstreamName,
+			expected:     nil,
+			msg:          "HealthCheck not enabled",
 		},
-		SplitClients: []version2.SplitClient{
-			{
-				Source:   "$request_id",
-				Variable: "$vs_default_cafe_splits_2",
-				Distributions: []version2.Distribution{
-					{
-						Weight: "90%",
-						Value:  "/internal_location_splits_2_split_0",
-					},
-					{
-						Weight: "10%",
-						Value:  "/internal_location_splits_2_split_1",
-					},
+		{
+			upstream: conf_v1.Upstream{
+				HealthCheck: &conf_v1.HealthCheck{
+					Enable:         true,
+					Interval:       "1m 5s",
+					Jitter:         "2m 3s",
+					ConnectTimeout: "1m 10s",
+					SendTimeout:    "1m 20s",
+					ReadTimeout:    "1m 30s",
 				},
 			},
+			upstreamName: upstreamName,
+			expected: &version2.HealthCheck{
+				Name:                upstreamName,
+				ProxyConnectTimeout: "1m10s",
+				ProxySendTimeout:    "1m20s",
+				ProxyReadTimeout:    "1m30s",
+				ProxyPass:           fmt.Sprintf("http://%v", upstreamName),
+				URI:                 "/",
+				Interval:            "1m5s",
+				Jitter:              "2m3s",
+				Fails:               1,
+				Passes:              1,
+				Headers:             make(map[string]string),
+			},
+			msg: "HealthCheck with time parameters have correct format",
+		},
+		{
+			upstream: conf_v1.Upstream{
+				HealthCheck: &conf_v1.HealthCheck{
+					Enable:     true,
+					Mandatory:  true,
+					Persistent: true,
+				},
+				ProxyConnectTimeout: "30s",
+				ProxyReadTimeout:    "30s",
+				ProxySendTimeout:    "30s",
+			},
+			upstreamName: upstreamName,
+			expected: &version2.HealthCheck{
+				Name:                upstreamName,
+				ProxyConnectTimeout: "30s",
+				ProxyReadTimeout:    "30s",
+				ProxySendTimeout:    "30s",
+				ProxyPass:           fmt.Sprintf("http://%v", upstreamName),
+				URI:                 "/",
+				Interval:            "5s",
+				Jitter:              "0s",
+				Fails:               1,
+				Passes:              1,
+				Headers:             make(map[string]string),
+				Mandatory:           true,
+				Persistent:          true,
+			},
+			msg: "HealthCheck with mandatory and persistent set",
 		},
 	}
 
-	cfgParams := ConfigParams{}
-	enableSnippets := false
-	locSnippets := ""
-	crUpstreams := map[string]conf_v1.Upstream{
-		"vs_default_cafe_coffee-v1": {Service: "coffee-v1"},
-		"vs_default_cafe_coffee-v2": {Service: "coffee-v2"},
-		"vs_default_cafe_tea":       {Service: "tea"},
-	}
-
-	errorPageDetails := errorPageDetails{
-		pages: errorPages,
-		index: 2,
-		owner: nil,
+	baseCfgParams := &ConfigParams{
+		ProxySendTimeout:    "5s",
+		ProxyReadTimeout:    "5s",
+		ProxyConnectTimeout: "5s",
 	}
 
-	result := generateMatchesConfig(
-		route,
-		upstreamNamer,
-		crUpstreams,
-		variableNamer,
-		index,
-		scIndex,
-		&cfgParams,
-		errorPageDetails,
-		locSnippets,
-		enableSnippets,
-		0,
-		false,
-		"",
-		"",
-		Warnings{},
-	)
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("generateMatchesConfig() returned \n%+v but expected \n%+v", result, expected)
+	for _, test := range tests {
+		result := generateHealthCheck(test.upstream, test.upstreamName, baseCfgParams)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateHealthCheck returned \n%v but expected \n%v \n for case: %v", result, test.expected, test.msg)
+		}
 	}
 }
 
-func TestGenerateMatchesConfigWithMultipleSplits(t *testing.T) {
+func TestGenerateGrpcHealthCheck(t *testing.T) {
 	t.Parallel()
-	route := conf_v1.Route{
-		Path: "/",
-		Matches: []conf_v1.Match{
-			{
-				Conditions: []conf_v1.Condition{
-					{
-						Header: "x-version",
-						Value:  "v1",
-					},
-				},
-				Splits: []conf_v1.Split{
-					{
-						Weight: 30,
-						Action: &conf_v1.Action{
-							Pass: "coffee-v1",
-						},
-					},
-					{
-						Weight: 70,
-						Action: &conf_v1.Action{
-							Pass: "coffee-v2",
-						},
-					},
-				},
-			},
-			{
-				Conditions: []conf_v1.Condition{
-					{
-						Header: "x-version",
-						Value:  "v2",
-					},
-				},
-				Splits: []conf_v1.Split{
-					{
-						Weight: 90,
-						Action: &conf_v1.Action{
-							Pass: "coffee-v2",
-						},
-					},
-					{
-						Weight: 10,
-						Action: &conf_v1.Action{
-							Pass: "coffee-v1",
+	upstreamName := "test-upstream"
+	tests := []struct {
+		upstream     conf_v1.Upstream
+		upstreamName string
+		expected     *version2.HealthCheck
+		msg          string
+	}{
+		{
+			upstream: conf_v1.Upstream{
+				HealthCheck: &conf_v1.HealthCheck{
+					Enable:         true,
+					Interval:       "5s",
+					Jitter:         "2s",
+					Fails:          3,
+					Passes:         2,
+					Port:           50051,
+					ConnectTimeout: "20s",
+					SendTimeout:    "20s",
+					ReadTimeout:    "20s",
+					GRPCStatus:     createPointerFromInt(12),
+					GRPCService:    "grpc-service",
+					Headers: []conf_v1.Header{
+						{
+							Name:  "Host",
+							Value: "my.service",
 						},
-					},
-				},
-			},
-		},
-		Splits: []conf_v1.Split{
-			{
-				Weight: 99,
-				Action: &conf_v1.Action{
-					Pass: "coffee-v1",
-				},
-			},
-			{
-				Weight: 1,
-				Action: &conf_v1.Action{
-					Pass: "coffee-v2",
+						{
+							Name:  "User-Agent",
+							Value: "nginx",
+						},
+					},
 				},
+				Type: "grpc",
 			},
-		},
-	}
-	virtualServer := conf_v1.VirtualServer{
-		ObjectMeta: meta_v1.ObjectMeta{
-			Name:      "cafe",
-			Namespace: "default",
-		},
-	}
-	upstreamNamer := newUpstreamNamerForVirtualServer(&virtualServer)
-	variableNamer := newVariableNamer(&virtualServer)
-	index := 1
-	scIndex := 2
-	errorPages := []conf_v1.ErrorPage{
-		{
-			Codes: []int{400, 500},
-			Return: &conf_v1.ErrorPageReturn{
-				ActionReturn: conf_v1.ActionReturn{
-					Code: 200,
-					Type: "application/json",
-					Body: `{\"message\": \"ok\"}`,
-				},
-				Headers: []conf_v1.Header{
-					{
-						Name:  "Set-Cookie",
-						Value: "cookie1=value",
-					},
+			upstreamName: upstreamName,
+			expected: &version2.HealthCheck{
+				Name:                upstreamName,
+				ProxyConnectTimeout: "20s",
+				ProxySendTimeout:    "20s",
+				ProxyReadTimeout:    "20s",
+				ProxyPass:           fmt.Sprintf("http://%v", upstreamName),
+				GRPCPass:            fmt.Sprintf("grpc://%v", upstreamName),
+				Interval:            "5s",
+				Jitter:              "2s",
+				Fails:               3,
+				Passes:              2,
+				Port:                50051,
+				GRPCStatus:          createPointerFromInt(12),
+				GRPCService:         "grpc-service",
+				Headers: map[string]string{
+					"Host":       "my.service",
+					"User-Agent": "nginx",
 				},
 			},
-			Redirect: nil,
+			msg: "HealthCheck with changed parameters",
 		},
 		{
-			Codes:  []int{500, 502},
-			Return: nil,
-			Redirect: &conf_v1.ErrorPageRedirect{
-				ActionRedirect: conf_v1.ActionRedirect{
-					URL:  "http://nginx.com",
-					Code: 301,
+			upstream: conf_v1.Upstream{
+				HealthCheck: &conf_v1.HealthCheck{
+					Enable: true,
 				},
+				ProxyConnectTimeout: "30s",
+				ProxyReadTimeout:    "30s",
+				ProxySendTimeout:    "30s",
+				Type:                "grpc",
+			},
+			upstreamName: upstreamName,
+			expected: &version2.HealthCheck{
+				Name:                upstreamName,
+				ProxyConnectTimeout: "30s",
+				ProxyReadTimeout:    "30s",
+				ProxySendTimeout:    "30s",
+				ProxyPass:           fmt.Sprintf("http://%v", upstreamName),
+				GRPCPass:            fmt.Sprintf("grpc://%v", upstreamName),
+				Interval:            "5s",
+				Jitter:              "0s",
+				Fails:               1,
+				Passes:              1,
+				Headers:             make(map[string]string),
 			},
+			msg: "HealthCheck with default parameters from Upstream",
 		},
 	}
 
-	expected := routingCfg{
-		Maps: []version2.Map{
-			{
-				Source:   "$http_x_version",
-				Variable: "$vs_default_cafe_matches_1_match_0_cond_0",
-				Parameters: []version2.Parameter{
-					{
-						Value:  `"v1"`,
-						Result: "1",
-					},
-					{
-						Value:  "default",
-						Result: "0",
-					},
-				},
+	baseCfgParams := &ConfigParams{
+		ProxySendTimeout:    "5s",
+		ProxyReadTimeout:    "5s",
+		ProxyConnectTimeout: "5s",
+	}
+
+	for _, test := range tests {
+		result := generateHealthCheck(test.upstream, test.upstreamName, baseCfgParams)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateHealthCheck returned \n%v but expected \n%v \n for case: %v", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestGenerateEndpointsForUpstream(t *testing.T) {
+	t.Parallel()
+	name := "test"
+	namespace := "test-namespace"
+
+	tests := []struct {
+		upstream             conf_v1.Upstream
+		vsEx                 *VirtualServerEx
+		isPlus               bool
+		isResolverConfigured bool
+		expected             []string
+		warningsExpected     bool
+		msg                  string
+	}{
+		{
+			upstream: conf_v1.Upstream{
+				Service: name,
+				Port:    80,
 			},
-			{
-				Source:   "$http_x_version",
-				Variable: "$vs_default_cafe_matches_1_match_1_cond_0",
-				Parameters: []version2.Parameter{
-					{
-						Value:  `"v2"`,
-						Result: "1",
-					},
-					{
-						Value:  "default",
-						Result: "0",
+			vsEx: &VirtualServerEx{
+				VirtualServer: &conf_v1.VirtualServer{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      name,
+						Namespace: namespace,
 					},
 				},
-			},
-			{
-				Source:   "$vs_default_cafe_matches_1_match_0_cond_0$vs_default_cafe_matches_1_match_1_cond_0",
-				Variable: "$vs_default_cafe_matches_1",
-				Parameters: []version2.Parameter{
-					{
-						Value:  "~^1",
-						Result: "$vs_default_cafe_splits_2",
-					},
-					{
-						Value:  "~^01",
-						Result: "$vs_default_cafe_splits_3",
-					},
-					{
-						Value:  "default",
-						Result: "$vs_default_cafe_splits_4",
-					},
+				Endpoints: map[string][]string{
+					"test-namespace/test:80": {"example.com:80"},
+				},
+				ExternalNameSvcs: map[string]bool{
+					"test-namespace/test": true,
 				},
 			},
+			isPlus:               true,
+			isResolverConfigured: true,
+			expected:             []string{"example.com:80"},
+			msg:                  "ExternalName service",
 		},
-		Locations: []version2.Location{
-			{
-				Path:                     "/internal_location_splits_2_split_0",
-				ProxyPass:                "http://vs_default_cafe_coffee-v1$request_uri",
-				ProxyNextUpstream:        "error timeout",
-				ProxyNextUpstreamTimeout: "0s",
-				ProxyNextUpstreamTries:   0,
-				Internal:                 true,
-				ErrorPages: []version2.ErrorPage{
-					{
-						Name:         "@error_page_0_0",
-						Codes:        "400 500",
-						ResponseCode: 200,
-					},
-					{
-						Name:         "http://nginx.com",
-						Codes:        "500 502",
-						ResponseCode: 301,
-					},
-				},
-				ProxyInterceptErrors:    true,
-				ProxySSLName:            "coffee-v1.default.svc",
-				ProxyPassRequestHeaders: true,
-				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
-				ServiceName:             "coffee-v1",
-				IsVSR:                   true,
-				VSRName:                 "coffee",
-				VSRNamespace:            "default",
+		{
+			upstream: conf_v1.Upstream{
+				Service: name,
+				Port:    80,
 			},
-			{
-				Path:                     "/internal_location_splits_2_split_1",
-				ProxyPass:                "http://vs_default_cafe_coffee-v2$request_uri",
-				ProxyNextUpstream:        "error timeout",
-				ProxyNextUpstreamTimeout: "0s",
-				ProxyNextUpstreamTries:   0,
-				Internal:                 true,
-				ErrorPages: []version2.ErrorPage{
-					{
-						Name:         "@error_page_0_0",
-						Codes:        "400 500",
-						ResponseCode: 200,
-					},
-					{
-						Name:         "http://nginx.com",
-						Codes:        "500 502",
-						ResponseCode: 301,
+			vsEx: &VirtualServerEx{
+				VirtualServer: &conf_v1.VirtualServer{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      name,
+						Namespace: namespace,
 					},
 				},
-				ProxyInterceptErrors:    true,
-				ProxySSLName:            "coffee-v2.default.svc",
-				ProxyPassRequestHeaders: true,
-				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
-				ServiceName:             "coffee-v2",
-				IsVSR:                   true,
-				VSRName:                 "coffee",
-				VSRNamespace:            "default",
+				Endpoints: map[string][]string{
+					"test-namespace/test:80": {"example.com:80"},
+				},
+				ExternalNameSvcs: map[string]bool{
+					"test-namespace/test": true,
+				},
 			},
-			{
-				Path:                     "/internal_location_splits_3_split_0",
-				ProxyPass:                "http://vs_default_cafe_coffee-v2$request_uri",
-				ProxyNextUpstream:        "error timeout",
-				ProxyNextUpstreamTimeout: "0s",
-				ProxyNextUpstreamTries:   0,
-				Internal:                 true,
-				ErrorPages: []version2.ErrorPage{
-					{
-						Name:         "@error_page_0_0",
-						Codes:        "400 500",
-						ResponseCode: 200,
-					},
-					{
-						Name:         "http://nginx.com",
-						Codes:        "500 502",
-						ResponseCode: 301,
+			isPlus:               true,
+			isResolverConfigured: false,
+			warningsExpected:     true,
+			expected:             []string{},
+			msg:                  "ExternalName service without resolver configured",
+		},
+		{
+			upstream: conf_v1.Upstream{
+				Service: name,
+				Port:    8080,
+			},
+			vsEx: &VirtualServerEx{
+				VirtualServer: &conf_v1.VirtualServer{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      name,
+						Namespace: namespace,
 					},
 				},
-				ProxyInterceptErrors:    true,
-				ProxySSLName:            "coffee-v2.default.svc",
-				ProxyPassRequestHeaders: true,
-				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
-				ServiceName:             "coffee-v2",
-				IsVSR:                   true,
-				VSRName:                 "coffee",
-				VSRNamespace:            "default",
+				Endpoints: map[string][]string{
+					"test-namespace/test:8080": {"192.168.10.10:8080"},
+				},
 			},
-			{
-				Path:                     "/internal_location_splits_3_split_1",
-				ProxyPass:                "http://vs_default_cafe_coffee-v1$request_uri",
-				ProxyNextUpstream:        "error timeout",
-				ProxyNextUpstreamTimeout: "0s",
-				ProxyNextUpstreamTries:   0,
-				Internal:                 true,
-				ErrorPages: []version2.ErrorPage{
-					{
-						Name:         "@error_page_0_0",
-						Codes:        "400 500",
-						ResponseCode: 200,
-					},
-					{
-						Name:         "http://nginx.com",
-						Codes:        "500 502",
-						ResponseCode: 301,
+			isPlus:               false,
+			isResolverConfigured: false,
+			expected:             []string{"192.168.10.10:8080"},
+			msg:                  "Service with endpoints",
+		},
+		{
+			upstream: conf_v1.Upstream{
+				Service: name,
+				Port:    8080,
+			},
+			vsEx: &VirtualServerEx{
+				VirtualServer: &conf_v1.VirtualServer{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      name,
+						Namespace: namespace,
 					},
 				},
-				ProxyInterceptErrors:    true,
-				ProxySSLName:            "coffee-v1.default.svc",
-				ProxyPassRequestHeaders: true,
-				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
-				ServiceName:             "coffee-v1",
-				IsVSR:                   true,
-				VSRName:                 "coffee",
-				VSRNamespace:            "default",
+				Endpoints: map[string][]string{},
 			},
-			{
-				Path:                     "/internal_location_splits_4_split_0",
-				ProxyPass:                "http://vs_default_cafe_coffee-v1$request_uri",
-				ProxyNextUpstream:        "error timeout",
-				ProxyNextUpstreamTimeout: "0s",
-				ProxyNextUpstreamTries:   0,
-				Internal:                 true,
-				ErrorPages: []version2.ErrorPage{
-					{
-						Name:         "@error_page_0_0",
-						Codes:        "400 500",
-						ResponseCode: 200,
-					},
-					{
-						Name:         "http://nginx.com",
-						Codes:        "500 502",
-						ResponseCode: 301,
+			isPlus:               false,
+			isResolverConfigured: false,
+			expected:             []string{nginx502Server},
+			msg:                  "Service with no endpoints",
+		},
+		{
+			upstream: conf_v1.Upstream{
+				Service: name,
+				Port:    8080,
+			},
+			vsEx: &VirtualServerEx{
+				VirtualServer: &conf_v1.VirtualServer{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      name,
+						Namespace: namespace,
 					},
 				},
-				ProxyInterceptErrors:    true,
-				ProxySSLName:            "coffee-v1.default.svc",
-				ProxyPassRequestHeaders: true,
-				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
-				ServiceName:             "coffee-v1",
-				IsVSR:                   true,
-				VSRName:                 "coffee",
-				VSRNamespace:            "default",
+				Endpoints: map[string][]string{},
 			},
-			{
-				Path:                     "/internal_location_splits_4_split_1",
-				ProxyPass:                "http://vs_default_cafe_coffee-v2$request_uri",
-				ProxyNextUpstream:        "error timeout",
-				ProxyNextUpstreamTimeout: "0s",
-				ProxyNextUpstreamTries:   0,
-				Internal:                 true,
-				ErrorPages: []version2.ErrorPage{
-					{
-						Name:         "@error_page_0_0",
-						Codes:        "400 500",
-						ResponseCode: 200,
-					},
-					{
-						Name:         "http://nginx.com",
-						Codes:        "500 502",
-						ResponseCode: 301,
+			isPlus:               true,
+			isResolverConfigured: false,
+			expected:             nil,
+			msg:                  "Service with no endpoints",
+		},
+		{
+			upstream: conf_v1.Upstream{
+				Service:     name,
+				Port:        8080,
+				Subselector: map[string]string{"version": "test"},
+			},
+			vsEx: &VirtualServerEx{
+				VirtualServer: &conf_v1.VirtualServer{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      name,
+						Namespace: namespace,
 					},
 				},
-				ProxyInterceptErrors:    true,
-				ProxySSLName:            "coffee-v2.default.svc",
-				ProxyPassRequestHeaders: true,
-				ProxySetHeaders:         []version2.Header{{Name: "Host", Value: "$host"}},
-				ServiceName:             "coffee-v2",
-				IsVSR:                   true,
-				VSRName:                 "coffee",
-				VSRNamespace:            "default",
+				Endpoints: map[string][]string{
+					"test-namespace/test_version=test:8080": {"192.168.10.10:8080"},
+				},
 			},
+			isPlus:               false,
+			isResolverConfigured: false,
+			expected:             []string{"192.168.10.10:8080"},
+			msg:                  "Upstream with subselector, with a matching endpoint",
 		},
-		InternalRedirectLocation: version2.InternalRedirectLocation{
-			Path:        "/",
-			Destination: "$vs_default_cafe_matches_1",
-		},
-		SplitClients: []version2.SplitClient{
-			{
-				Source:   "$request_id",
-				Variable: "$vs_default_cafe_splits_2",
-				Distributions: []version2.Distribution{
-					{
-						Weight: "30%",
-						Value:  "/internal_location_splits_2_split_0",
-					},
-					{
-						Weight: "70%",
-						Value:  "/internal_location_splits_2_split_1",
+		{
+			upstream: conf_v1.Upstream{
+				Service:     name,
+				Port:        8080,
+				Subselector: map[string]string{"version": "test"},
+			},
+			vsEx: &VirtualServerEx{
+				VirtualServer: &conf_v1.VirtualServer{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      name,
+						Namespace: namespace,
 					},
 				},
+				Endpoints: map[string][]string{
+					"test-namespace/test:8080": {"192.168.10.10:8080"},
+				},
 			},
+			isPlus:               false,
+			isResolverConfigured: false,
+			expected:             []string{nginx502Server},
+			msg:                  "Upstream with subselector, without a matching endpoint",
+		},
+	}
+
+	for _, test := range tests {
+		isWildcardEnabled := false
+		vsc := newVirtualServerConfigurator(
+			&ConfigParams{},
+			test.isPlus,
+			test.isResolverConfigured,
+			&StaticConfigParams{},
+			isWildcardEnabled,
+		)
+		result := vsc.generateEndpointsForUpstream(test.vsEx.VirtualServer, namespace, test.upstream, test.vsEx)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateEndpointsForUpstream(isPlus=%v, isResolverConfigured=%v) returned %v, but expected %v for case: %v",
+				test.isPlus, test.isResolverConfigured, result, test.expected, test.msg)
+		}
+
+		if len(vsc.warnings) == 0 && test.warningsExpected {
+			t.Errorf(
+				"generateEndpointsForUpstream(isPlus=%v, isResolverConfigured=%v) didn't return any warnings for %v but warnings expected",
+				test.isPlus,
+				test.isResolverConfigured,
+				test.upstream,
+			)
+		}
+
+		if len(vsc.warnings) != 0 && !test.warningsExpected {
+			t.Errorf("generateEndpointsForUpstream(isPlus=%v, isResolverConfigured=%v) returned warnings for %v",
+				test.isPlus, test.isResolverConfigured, test.upstream)
+		}
+	}
+}
+
+func TestGenerateSlowStartForPlusWithInCompatibleLBMethods(t *testing.T) {
+	t.Parallel()
+	serviceName := "test-slowstart-with-incompatible-LBMethods"
+	upstream := conf_v1.Upstream{Service: serviceName, Port: 80, SlowStart: "10s"}
+	expected := ""
+
+	tests := []string{
+		"random",
+		"ip_hash",
+		"hash 123",
+		"random two",
+		"random two least_conn",
+		"random two least_time=header",
+		"random two least_time=last_byte",
+	}
+
+	for _, lbMethod := range tests {
+		vsc := newVirtualServerConfigurator(&ConfigParams{}, true, false, &StaticConfigParams{}, false)
+		result := vsc.generateSlowStartForPlus(&conf_v1.VirtualServer{}, upstream, lbMethod)
+
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("generateSlowStartForPlus returned %v, but expected %v for lbMethod %v", result, expected, lbMethod)
+		}
+
+		if len(vsc.warnings) == 0 {
+			t.Errorf("generateSlowStartForPlus returned no warnings for %v but warnings expected", upstream)
+		}
+	}
+}
+
+func TestGenerateSlowStartForPlus(t *testing.T) {
+	serviceName := "test-slowstart"
+
+	tests := []struct {
+		upstream conf_v1.Upstream
+		lbMethod string
+		expected string
+	}{
+		{
+			upstream: conf_v1.Upstream{Service: serviceName, Port: 80, SlowStart: "", LBMethod: "least_conn"},
+			lbMethod: "least_conn",
+			expected: "",
+		},
+		{
+			upstream: conf_v1.Upstream{Service: serviceName, Port: 80, SlowStart: "10s", LBMethod: "least_conn"},
+			lbMethod: "least_conn",
+			expected: "10s",
+		},
+	}
+
+	for _, test := range tests {
+		vsc := newVirtualServerConfigurator(&ConfigParams{}, true, false, &StaticConfigParams{}, false)
+		result := vsc.generateSlowStartForPlus(&conf_v1.VirtualServer{}, test.upstream, test.lbMethod)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateSlowStartForPlus returned %v, but expected %v", result, test.expected)
+		}
+
+		if len(vsc.warnings) != 0 {
+			t.Errorf("generateSlowStartForPlus returned warnings for %v", test.upstream)
+		}
+	}
+}
+
+func TestCreateEndpointsFromUpstream(t *testing.T) {
+	t.Parallel()
+	ups := version2.Upstream{
+		Servers: []version2.UpstreamServer{
 			{
-				Source:   "$request_id",
-				Variable: "$vs_default_cafe_splits_3",
-				Distributions: []version2.Distribution{
-					{
-						Weight: "90%",
-						Value:  "/internal_location_splits_3_split_0",
-					},
-					{
-						Weight: "10%",
-						Value:  "/internal_location_splits_3_split_1",
-					},
-				},
+				Address: "10.0.0.20:80",
 			},
 			{
-				Source:   "$request_id",
-				Variable: "$vs_default_cafe_splits_4",
-				Distributions: []version2.Distribution{
-					{
-						Weight: "99%",
-						Value:  "/internal_location_splits_4_split_0",
-					},
-					{
-						Weight: "1%",
-						Value:  "/internal_location_splits_4_split_1",
-					},
-				},
+				Address: "10.0.0.30:80",
 			},
 		},
 	}
 
-	cfgParams := ConfigParams{}
-	enableSnippets := false
-	locSnippets := ""
-	crUpstreams := map[string]conf_v1.Upstream{
-		"vs_default_cafe_coffee-v1": {Service: "coffee-v1"},
-		"vs_default_cafe_coffee-v2": {Service: "coffee-v2"},
-	}
-
-	errorPageDetails := errorPageDetails{
-		pages: errorPages,
-		index: 0,
-		owner: nil,
+	expected := []string{
+		"10.0.0.20:80",
+		"10.0.0.30:80",
 	}
 
-	result := generateMatchesConfig(
-		route,
-		upstreamNamer,
-		crUpstreams,
-		variableNamer,
-		index,
-		scIndex,
-		&cfgParams,
-		errorPageDetails,
-		locSnippets,
-		enableSnippets,
-		0,
-		true,
-		"coffee",
-		"default",
-		Warnings{},
-	)
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("generateMatchesConfig() returned \n%+v but expected \n%+v", result, expected)
+	endpoints := createEndpointsFromUpstream(ups)
+	if !reflect.DeepEqual(endpoints, expected) {
+		t.Errorf("createEndpointsFromUpstream returned %v, but expected %v", endpoints, expected)
 	}
 }
 
-func TestGenerateValueForMatchesRouteMap(t *testing.T) {
+func TestGenerateUpstreamWithQueue(t *testing.T) {
 	t.Parallel()
+	serviceName := "test-queue"
+
 	tests := []struct {
-		input              string
-		expectedValue      string
-		expectedIsNegative bool
+		name     string
+		upstream conf_v1.Upstream
+		isPlus   bool
+		expected version2.Upstream
+		msg      string
 	}{
 		{
-			input:              "default",
-			expectedValue:      `\default`,
-			expectedIsNegative: false,
-		},
-		{
-			input:              "!default",
-			expectedValue:      `\default`,
-			expectedIsNegative: true,
-		},
-		{
-			input:              "hostnames",
-			expectedValue:      `\hostnames`,
-			expectedIsNegative: false,
-		},
-		{
-			input:              "include",
-			expectedValue:      `\include`,
-			expectedIsNegative: false,
+			name: "test-upstream-queue",
+			upstream: conf_v1.Upstream{Service: serviceName, Port: 80, Queue: &conf_v1.UpstreamQueue{
+				Size:    10,
+				Timeout: "10s",
+			}},
+			isPlus: true,
+			expected: version2.Upstream{
+				UpstreamLabels: version2.UpstreamLabels{
+					Service: "test-queue",
+				},
+				Name: "test-upstream-queue",
+				Queue: &version2.Queue{
+					Size:    10,
+					Timeout: "10s",
+				},
+			},
+			msg: "upstream queue with size and timeout",
 		},
 		{
-			input:              "volatile",
-			expectedValue:      `\volatile`,
-			expectedIsNegative: false,
+			name: "test-upstream-queue-with-default-timeout",
+			upstream: conf_v1.Upstream{
+				Service: serviceName,
+				Port:    80,
+				Queue:   &conf_v1.UpstreamQueue{Size: 10, Timeout: ""},
+			},
+			isPlus: true,
+			expected: version2.Upstream{
+				UpstreamLabels: version2.UpstreamLabels{
+					Service: "test-queue",
+				},
+				Name: "test-upstream-queue-with-default-timeout",
+				Queue: &version2.Queue{
+					Size:    10,
+					Timeout: "60s",
+				},
+			},
+			msg: "upstream queue with only size",
 		},
 		{
-			input:              "abc",
-			expectedValue:      `"abc"`,
-			expectedIsNegative: false,
+			name:     "test-upstream-queue-nil",
+			upstream: conf_v1.Upstream{Service: serviceName, Port: 80, Queue: nil},
+			isPlus:   false,
+			expected: version2.Upstream{
+				UpstreamLabels: version2.UpstreamLabels{
+					Service: "test-queue",
+				},
+				Name: "test-upstream-queue-nil",
+			},
+			msg: "upstream queue with nil for OSS",
 		},
+	}
+
+	for _, test := range tests {
+		vsc := newVirtualServerConfigurator(&ConfigParams{}, test.isPlus, false, &StaticConfigParams{}, false)
+		result := vsc.generateUpstream(nil, test.name, test.upstream, false, []string{})
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateUpstream() returned %v but expected %v for the case of %v", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestGenerateQueueForPlus(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		upstreamQueue *conf_v1.UpstreamQueue
+		expected      *version2.Queue
+		msg           string
+	}{
 		{
-			input:              "!abc",
-			expectedValue:      `"abc"`,
-			expectedIsNegative: true,
+			upstreamQueue: &conf_v1.UpstreamQueue{Size: 10, Timeout: "10s"},
+			expected:      &version2.Queue{Size: 10, Timeout: "10s"},
+			msg:           "upstream queue with size and timeout",
 		},
 		{
-			input:              "",
-			expectedValue:      `""`,
-			expectedIsNegative: false,
+			upstreamQueue: nil,
+			expected:      nil,
+			msg:           "upstream queue with nil",
 		},
 		{
-			input:              "!",
-			expectedValue:      `""`,
-			expectedIsNegative: true,
+			upstreamQueue: &conf_v1.UpstreamQueue{Size: 10},
+			expected:      &version2.Queue{Size: 10, Timeout: "60s"},
+			msg:           "upstream queue with only size",
 		},
 	}
 
 	for _, test := range tests {
-		resultValue, resultIsNegative := generateValueForMatchesRouteMap(test.input)
-		if resultValue != test.expectedValue {
-			t.Errorf("generateValueForMatchesRouteMap(%q) returned %q but expected %q as the value", test.input, resultValue, test.expectedValue)
-		}
-		if resultIsNegative != test.expectedIsNegative {
-			t.Errorf("generateValueForMatchesRouteMap(%q) returned %v but expected %v as the isNegative", test.input, resultIsNegative, test.expectedIsNegative)
+		result := generateQueueForPlus(test.upstreamQueue, "60s")
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateQueueForPlus() returned %v but expected %v for the case of %v", result, test.expected, test.msg)
 		}
 	}
 }
 
-func TestGenerateParametersForMatchesRouteMap(t *testing.T) {
+func TestGenerateSessionCookie(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		inputMatchedValue     string
-		inputSuccessfulResult string
-		expected              []version2.Parameter
+		sc       *conf_v1.SessionCookie
+		expected *version2.SessionCookie
+		msg      string
 	}{
 		{
-			inputMatchedValue:     "abc",
-			inputSuccessfulResult: "1",
-			expected: []version2.Parameter{
-				{
-					Value:  `"abc"`,
-					Result: "1",
-				},
-				{
-					Value:  "default",
-					Result: "0",
-				},
-			},
-		},
-		{
-			inputMatchedValue:     "!abc",
-			inputSuccessfulResult: "1",
-			expected: []version2.Parameter{
-				{
-					Value:  `"abc"`,
-					Result: "0",
-				},
-				{
-					Value:  "default",
-					Result: "1",
-				},
-			},
+			sc:       &conf_v1.SessionCookie{Enable: true, Name: "test"},
+			expected: &version2.SessionCookie{Enable: true, Name: "test"},
+			msg:      "session cookie with name",
+		},
+		{
+			sc:       nil,
+			expected: nil,
+			msg:      "session cookie with nil",
+		},
+		{
+			sc:       &conf_v1.SessionCookie{Name: "test"},
+			expected: nil,
+			msg:      "session cookie not enabled",
 		},
 	}
-
 	for _, test := range tests {
-		result := generateParametersForMatchesRouteMap(test.inputMatchedValue, test.inputSuccessfulResult)
+		result := generateSessionCookie(test.sc)
 		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateParametersForMatchesRouteMap(%q, %q) returned %v but expected %v", test.inputMatchedValue, test.inputSuccessfulResult, result, test.expected)
+			t.Errorf("generateSessionCookie() returned %v, but expected %v for the case of: %v", result, test.expected, test.msg)
 		}
 	}
 }
 
-func TestGetNameForSourceForMatchesRouteMapFromCondition(t *testing.T) {
+func TestGeneratePath(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		input    conf_v1.Condition
+		path     string
 		expected string
 	}{
 		{
-			input: conf_v1.Condition{
-				Header: "x-version",
-			},
-			expected: "$http_x_version",
+			path:     "/",
+			expected: "/",
 		},
 		{
-			input: conf_v1.Condition{
-				Cookie: "mycookie",
-			},
-			expected: "$cookie_mycookie",
+			path:     "=/exact/match",
+			expected: "=/exact/match",
 		},
 		{
-			input: conf_v1.Condition{
-				Argument: "arg",
-			},
-			expected: "$arg_arg",
+			path:     `~ *\\.jpg`,
+			expected: `~ "*\\.jpg"`,
 		},
 		{
-			input: conf_v1.Condition{
-				Variable: "$request_method",
-			},
-			expected: "$request_method",
+			path:     `~* *\\.PNG`,
+			expected: `~* "*\\.PNG"`,
 		},
 	}
 
 	for _, test := range tests {
-		result := getNameForSourceForMatchesRouteMapFromCondition(test.input)
+		result := generatePath(test.path)
 		if result != test.expected {
-			t.Errorf("getNameForSourceForMatchesRouteMapFromCondition() returned %q but expected %q for input %v", result, test.expected, test.input)
+			t.Errorf("generatePath() returned %v, but expected %v.", result, test.expected)
 		}
 	}
 }
 
-func TestGenerateLBMethod(t *testing.T) {
+func TestGenerateErrorPageName(t *testing.T) {
 	t.Parallel()
-	defaultMethod := "random two least_conn"
-
 	tests := []struct {
-		input    string
-		expected string
+		routeIndex int
+		index      int
+		expected   string
 	}{
 		{
-			input:    "",
-			expected: defaultMethod,
+			0,
+			0,
+			"@error_page_0_0",
 		},
 		{
-			input:    "round_robin",
-			expected: "",
+			0,
+			1,
+			"@error_page_0_1",
 		},
 		{
-			input:    "random",
-			expected: "random",
+			1,
+			0,
+			"@error_page_1_0",
 		},
 	}
+
 	for _, test := range tests {
-		result := generateLBMethod(test.input, defaultMethod)
+		result := generateErrorPageName(test.routeIndex, test.index)
 		if result != test.expected {
-			t.Errorf("generateLBMethod() returned %q but expected %q for input '%v'", result, test.expected, test.input)
+			t.Errorf("generateErrorPageName(%v, %v) returned %v but expected %v", test.routeIndex, test.index, result, test.expected)
 		}
 	}
 }
 
-func TestUpstreamHasKeepalive(t *testing.T) {
+func TestGenerateErrorPageCodes(t *testing.T) {
 	t.Parallel()
-	noKeepalive := 0
-	keepalive := 32
-
 	tests := []struct {
-		upstream  conf_v1.Upstream
-		cfgParams *ConfigParams
-		expected  bool
-		msg       string
+		codes    []int
+		expected string
 	}{
 		{
-			conf_v1.Upstream{},
-			&ConfigParams{Keepalive: keepalive},
-			true,
-			"upstream keepalive not set, configparam keepalive set",
-		},
-		{
-			conf_v1.Upstream{Keepalive: &noKeepalive},
-			&ConfigParams{Keepalive: keepalive},
-			false,
-			"upstream keepalive set to 0, configparam keepalive set",
+			codes:    []int{400},
+			expected: "400",
 		},
 		{
-			conf_v1.Upstream{Keepalive: &keepalive},
-			&ConfigParams{Keepalive: noKeepalive},
-			true,
-			"upstream keepalive set, configparam keepalive set to 0",
+			codes:    []int{404, 405, 502},
+			expected: "404 405 502",
 		},
 	}
 
 	for _, test := range tests {
-		result := upstreamHasKeepalive(test.upstream, test.cfgParams)
+		result := generateErrorPageCodes(test.codes)
 		if result != test.expected {
-			t.Errorf("upstreamHasKeepalive() returned %v, but expected %v for the case of %v", result, test.expected, test.msg)
+			t.Errorf("generateErrorPageCodes(%v) returned %v but expected %v", test.codes, result, test.expected)
 		}
 	}
 }
 
-func TestNewHealthCheckWithDefaults(t *testing.T) {
+func TestGenerateErrorPages(t *testing.T) {
 	t.Parallel()
-	upstreamName := "test-upstream"
-	baseCfgParams := &ConfigParams{
-		ProxySendTimeout:    "5s",
-		ProxyReadTimeout:    "5s",
-		ProxyConnectTimeout: "5s",
-	}
-	expected := &version2.HealthCheck{
-		Name:                upstreamName,
-		ProxySendTimeout:    "5s",
-		ProxyReadTimeout:    "5s",
-		ProxyConnectTimeout: "5s",
-		ProxyPass:           fmt.Sprintf("http://%v", upstreamName),
-		URI:                 "/",
-		Interval:            "5s",
-		Jitter:              "0s",
-		Fails:               1,
-		Passes:              1,
-		Headers:             make(map[string]string),
+	tests := []struct {
+		upstreamName string
+		errorPages   []conf_v1.ErrorPage
+		expected     []version2.ErrorPage
+	}{
+		{}, // empty errorPages
+		{
+			"vs_test_test",
+			[]conf_v1.ErrorPage{
+				{
+					Codes: []int{404, 405, 500, 502},
+					Return: &conf_v1.ErrorPageReturn{
+						ActionReturn: conf_v1.ActionReturn{
+							Code: 200,
+						},
+						Headers: nil,
+					},
+					Redirect: nil,
+				},
+			},
+			[]version2.ErrorPage{
+				{
+					Name:         "@error_page_1_0",
+					Codes:        "404 405 500 502",
+					ResponseCode: 200,
+				},
+			},
+		},
+		{
+			"vs_test_test",
+			[]conf_v1.ErrorPage{
+				{
+					Codes:  []int{404, 405, 500, 502},
+					Return: nil,
+					Redirect: &conf_v1.ErrorPageRedirect{
+						ActionRedirect: conf_v1.ActionRedirect{
+							URL:  "http://nginx.org",
+							Code: 302,
+						},
+					},
+				},
+			},
+			[]version2.ErrorPage{
+				{
+					Name:         "http://nginx.org",
+					Codes:        "404 405 500 502",
+					ResponseCode: 302,
+				},
+			},
+		},
 	}
 
-	result := newHealthCheckWithDefaults(conf_v1.Upstream{}, upstreamName, baseCfgParams)
-
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("newHealthCheckWithDefaults returned \n%v but expected \n%v", result, expected)
+	for i, test := range tests {
+		result := generateErrorPages(i, test.errorPages)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateErrorPages(%v, %v) returned %v but expected %v", test.upstreamName, test.errorPages, result, test.expected)
+		}
 	}
 }
 
-func TestGenerateHealthCheck(t *testing.T) {
+func TestGenerateErrorPageLocations(t *testing.T) {
 	t.Parallel()
-	upstreamName := "test-upstream"
 	tests := []struct {
-		upstream     conf_v1.Upstream
 		upstreamName string
-		expected     *version2.HealthCheck
-		msg          string
+		errorPages   []conf_v1.ErrorPage
+		expected     []version2.ErrorPageLocation
 	}{
+		{},
 		{
-			upstream: conf_v1.Upstream{
-				HealthCheck: &conf_v1.HealthCheck{
-					Enable:         true,
-					Path:           "/healthz",
-					Interval:       "5s",
-					Jitter:         "2s",
-					Fails:          3,
-					Passes:         2,
-					Port:           8080,
-					ConnectTimeout: "20s",
-					SendTimeout:    "20s",
-					ReadTimeout:    "20s",
-					Headers: []conf_v1.Header{
-						{
-							Name:  "Host",
-							Value: "my.service",
+			"vs_test_test",
+			[]conf_v1.ErrorPage{
+				{
+					Codes:  []int{404, 405, 500, 502},
+					Return: nil,
+					Redirect: &conf_v1.ErrorPageRedirect{
+						ActionRedirect: conf_v1.ActionRedirect{
+							URL:  "http://nginx.org",
+							Code: 302,
+						},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"vs_test_test",
+			[]conf_v1.ErrorPage{
+				{
+					Codes: []int{404, 405, 500, 502},
+					Return: &conf_v1.ErrorPageReturn{
+						ActionReturn: conf_v1.ActionReturn{
+							Code: 200,
+							Type: "application/json",
+							Body: "Hello World",
+						},
+						Headers: []conf_v1.Header{
+							{
+								Name:  "HeaderName",
+								Value: "HeaderValue",
+							},
 						},
+					},
+					Redirect: nil,
+				},
+			},
+			[]version2.ErrorPageLocation{
+				{
+					Name:        "@error_page_2_0",
+					DefaultType: "application/json",
+					Return: &version2.Return{
+						Code: 0,
+						Text: "Hello World",
+					},
+					Headers: []version2.Header{
 						{
-							Name:  "User-Agent",
-							Value: "nginx",
+							Name:  "HeaderName",
+							Value: "HeaderValue",
 						},
 					},
-					StatusMatch: "! 500",
-				},
-			},
-			upstreamName: upstreamName,
-			expected: &version2.HealthCheck{
-				Name:                upstreamName,
-				ProxyConnectTimeout: "20s",
-				ProxySendTimeout:    "20s",
-				ProxyReadTimeout:    "20s",
-				ProxyPass:           fmt.Sprintf("http://%v", upstreamName),
-				URI:                 "/healthz",
-				Interval:            "5s",
-				Jitter:              "2s",
-				Fails:               3,
-				Passes:              2,
-				Port:                8080,
-				Headers: map[string]string{
-					"Host":       "my.service",
-					"User-Agent": "nginx",
 				},
-				Match: fmt.Sprintf("%v_match", upstreamName),
 			},
-			msg: "HealthCheck with changed parameters",
 		},
+	}
+
+	for i, test := range tests {
+		result := generateErrorPageLocations(i, test.errorPages)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateErrorPageLocations(%v, %v) returned %v but expected %v", test.upstreamName, test.errorPages, result, test.expected)
+		}
+	}
+}
+
+func TestGenerateProxySSLName(t *testing.T) {
+	t.Parallel()
+	result := generateProxySSLName("coffee-v1", "default")
+	if result != "coffee-v1.default.svc" {
+		t.Errorf("generateProxySSLName(coffee-v1, default) returned %v but expected coffee-v1.default.svc", result)
+	}
+}
+
+func TestIsTLSEnabled(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		upstream   conf_v1.Upstream
+		spiffeCert bool
+		expected   bool
+	}{
 		{
 			upstream: conf_v1.Upstream{
-				HealthCheck: &conf_v1.HealthCheck{
-					Enable: true,
+				TLS: conf_v1.UpstreamTLS{
+					Enable: false,
 				},
-				ProxyConnectTimeout: "30s",
-				ProxyReadTimeout:    "30s",
-				ProxySendTimeout:    "30s",
-			},
-			upstreamName: upstreamName,
-			expected: &version2.HealthCheck{
-				Name:                upstreamName,
-				ProxyConnectTimeout: "30s",
-				ProxyReadTimeout:    "30s",
-				ProxySendTimeout:    "30s",
-				ProxyPass:           fmt.Sprintf("http://%v", upstreamName),
-				URI:                 "/",
-				Interval:            "5s",
-				Jitter:              "0s",
-				Fails:               1,
-				Passes:              1,
-				Headers:             make(map[string]string),
 			},
-			msg: "HealthCheck with default parameters from Upstream",
+			spiffeCert: false,
+			expected:   false,
 		},
 		{
 			upstream: conf_v1.Upstream{
-				HealthCheck: &conf_v1.HealthCheck{
-					Enable: true,
+				TLS: conf_v1.UpstreamTLS{
+					Enable: false,
 				},
 			},
-			upstreamName: upstreamName,
-			expected: &version2.HealthCheck{
-				Name:                upstreamName,
-				ProxyConnectTimeout: "5s",
-				ProxyReadTimeout:    "5s",
-				ProxySendTimeout:    "5s",
-				ProxyPass:           fmt.Sprintf("http://%v", upstreamName),
-				URI:                 "/",
-				Interval:            "5s",
-				Jitter:              "0s",
-				Fails:               1,
-				Passes:              1,
-				Headers:             make(map[string]string),
-			},
-			msg: "HealthCheck with default parameters from ConfigMap (not defined in Upstream)",
-		},
-		{
-			upstream:     conf_v1.Upstream{},
-			upstreamName: upstreamName,
-			expected:     nil,
-			msg:          "HealthCheck not enabled",
+			spiffeCert: true,
+			expected:   true,
 		},
 		{
 			upstream: conf_v1.Upstream{
-				HealthCheck: &conf_v1.HealthCheck{
-					Enable:         true,
-					Interval:       "1m 5s",
-					Jitter:         "2m 3s",
-					ConnectTimeout: "1m 10s",
-					SendTimeout:    "1m 20s",
-					ReadTimeout:    "1m 30s",
+				TLS: conf_v1.UpstreamTLS{
+					Enable: true,
 				},
 			},
-			upstreamName: upstreamName,
-			expected: &version2.HealthCheck{
-				Name:                upstreamName,
-				ProxyConnectTimeout: "1m10s",
-				ProxySendTimeout:    "1m20s",
-				ProxyReadTimeout:    "1m30s",
-				ProxyPass:           fmt.Sprintf("http://%v", upstreamName),
-				URI:                 "/",
-				Interval:            "1m5s",
-				Jitter:              "2m3s",
-				Fails:               1,
-				Passes:              1,
-				Headers:             make(map[string]string),
-			},
-			msg: "HealthCheck with time parameters have correct format",
+			spiffeCert: true,
+			expected:   true,
 		},
 		{
 			upstream: conf_v1.Upstream{
-				HealthCheck: &conf_v1.HealthCheck{
-					Enable:     true,
-					Mandatory:  true,
-					Persistent: true,
+				TLS: conf_v1.UpstreamTLS{
+					Enable: true,
 				},
-				ProxyConnectTimeout: "30s",
-				ProxyReadTimeout:    "30s",
-				ProxySendTimeout:    "30s",
-			},
-			upstreamName: upstreamName,
-			expected: &version2.HealthCheck{
-				Name:                upstreamName,
-				ProxyConnectTimeout: "30s",
-				ProxyReadTimeout:    "30s",
-				ProxySendTimeout:    "30s",
-				ProxyPass:           fmt.Sprintf("http://%v", upstreamName),
-				URI:                 "/",
-				Interval:            "5s",
-				Jitter:              "0s",
-				Fails:               1,
-				Passes:              1,
-				Headers:             make(map[string]string),
-				Mandatory:           true,
-				Persistent:          true,
 			},
-			msg: "HealthCheck with mandatory and persistent set",
+			spiffeCert: false,
+			expected:   true,
 		},
 	}
 
-	baseCfgParams := &ConfigParams{
-		ProxySendTimeout:    "5s",
-		ProxyReadTimeout:    "5s",
-		ProxyConnectTimeout: "5s",
-	}
-
 	for _, test := range tests {
-		result := generateHealthCheck(test.upstream, test.upstreamName, baseCfgParams)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateHealthCheck returned \n%v but expected \n%v \n for case: %v", result, test.expected, test.msg)
+		result := isTLSEnabled(test.upstream, test.spiffeCert)
+		if result != test.expected {
+			t.Errorf("isTLSEnabled(%v, %v) returned %v but expected %v", test.upstream, test.spiffeCert, result, test.expected)
 		}
 	}
 }
 
-func TestGenerateGrpcHealthCheck(t *testing.T) {
+func TestGenerateRewrites(t *testing.T) {
 	t.Parallel()
-	upstreamName := "test-upstream"
 	tests := []struct {
-		upstream     conf_v1.Upstream
-		upstreamName string
-		expected     *version2.HealthCheck
+		path         string
+		proxy        *conf_v1.ActionProxy
+		internal     bool
+		originalPath string
+		grpcEnabled  bool
+		expected     []string
 		msg          string
 	}{
 		{
-			upstream: conf_v1.Upstream{
-				HealthCheck: &conf_v1.HealthCheck{
-					Enable:         true,
-					Interval:       "5s",
-					Jitter:         "2s",
-					Fails:          3,
-					Passes:         2,
-					Port:           50051,
-					ConnectTimeout: "20s",
-					SendTimeout:    "20s",
-					ReadTimeout:    "20s",
-					GRPCStatus:     createPointerFromInt(12),
-					GRPCService:    "grpc-service",
-					Headers: []conf_v1.Header{
-						{
-							Name:  "Host",
-							Value: "my.service",
-						},
-						{
-							Name:  "User-Agent",
-							Value: "nginx",
-						},
-					},
-				},
-				Type: "grpc",
+			proxy:    nil,
+			expected: nil,
+			msg:      "action isn't proxy",
+		},
+		{
+			proxy: &conf_v1.ActionProxy{
+				RewritePath: "",
 			},
-			upstreamName: upstreamName,
-			expected: &version2.HealthCheck{
-				Name:                upstreamName,
-				ProxyConnectTimeout: "20s",
-				ProxySendTimeout:    "20s",
-				ProxyReadTimeout:    "20s",
-				ProxyPass:           fmt.Sprintf("http://%v", upstreamName),
-				GRPCPass:            fmt.Sprintf("grpc://%v", upstreamName),
-				Interval:            "5s",
-				Jitter:              "2s",
-				Fails:               3,
-				Passes:              2,
-				Port:                50051,
-				GRPCStatus:          createPointerFromInt(12),
-				GRPCService:         "grpc-service",
-				Headers: map[string]string{
-					"Host":       "my.service",
-					"User-Agent": "nginx",
-				},
+			expected: nil,
+			msg:      "no rewrite is configured",
+		},
+		{
+			path: "/path",
+			proxy: &conf_v1.ActionProxy{
+				RewritePath: "/rewrite",
+			},
+			expected: nil,
+			msg:      "non-regex rewrite for non-internal location is not needed",
+		},
+		{
+			path:     "/_internal_path",
+			internal: true,
+			proxy: &conf_v1.ActionProxy{
+				RewritePath: "/rewrite",
+			},
+			originalPath: "/path",
+			expected:     []string{`^ $request_uri_no_args`, `"^/path(.*)$" "/rewrite$1" break`},
+			msg:          "non-regex rewrite for internal location",
+		},
+		{
+			path:     "~/regex",
+			internal: true,
+			proxy: &conf_v1.ActionProxy{
+				RewritePath: "/rewrite",
 			},
-			msg: "HealthCheck with changed parameters",
+			originalPath: "/path",
+			expected:     []string{`^ $request_uri_no_args`, `"^/path(.*)$" "/rewrite$1" break`},
+			msg:          "regex rewrite for internal location",
 		},
 		{
-			upstream: conf_v1.Upstream{
-				HealthCheck: &conf_v1.HealthCheck{
-					Enable: true,
-				},
-				ProxyConnectTimeout: "30s",
-				ProxyReadTimeout:    "30s",
-				ProxySendTimeout:    "30s",
-				Type:                "grpc",
+			path:     "~/regex",
+			internal: false,
+			proxy: &conf_v1.ActionProxy{
+				RewritePath: "/rewrite",
 			},
-			upstreamName: upstreamName,
-			expected: &version2.HealthCheck{
-				Name:                upstreamName,
-				ProxyConnectTimeout: "30s",
-				ProxyReadTimeout:    "30s",
-				ProxySendTimeout:    "30s",
-				ProxyPass:           fmt.Sprintf("http://%v", upstreamName),
-				GRPCPass:            fmt.Sprintf("grpc://%v", upstreamName),
-				Interval:            "5s",
-				Jitter:              "0s",
-				Fails:               1,
-				Passes:              1,
-				Headers:             make(map[string]string),
+			expected: []string{`"^/regex" "/rewrite" break`},
+			msg:      "regex rewrite for non-internal location",
+		},
+		{
+			path:     "/_internal_path",
+			internal: true,
+			proxy: &conf_v1.ActionProxy{
+				RewritePath: "/rewrite",
 			},
-			msg: "HealthCheck with default parameters from Upstream",
+			originalPath: "/path",
+			grpcEnabled:  true,
+			expected:     []string{`^ $request_uri_no_args`, `"^/path(.*)$" "/rewrite$1" break`},
+			msg:          "non-regex rewrite for internal location with grpc enabled",
+		},
+		{
+			path:         "/_internal_path",
+			internal:     true,
+			originalPath: "/path",
+			grpcEnabled:  true,
+			expected:     []string{`^ $request_uri break`},
+			msg:          "empty rewrite for internal location with grpc enabled",
 		},
-	}
-
-	baseCfgParams := &ConfigParams{
-		ProxySendTimeout:    "5s",
-		ProxyReadTimeout:    "5s",
-		ProxyConnectTimeout: "5s",
 	}
 
 	for _, test := range tests {
-		result := generateHealthCheck(test.upstream, test.upstreamName, baseCfgParams)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateHealthCheck returned \n%v but expected \n%v \n for case: %v", result, test.expected, test.msg)
+		result := generateRewrites(test.path, test.proxy, test.internal, test.originalPath, test.grpcEnabled)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("generateRewrites() '%v' mismatch (-want +got):\n%s", test.msg, diff)
 		}
 	}
 }
 
-func TestGenerateEndpointsForUpstream(t *testing.T) {
+func TestGenerateProxyPassRewrite(t *testing.T) {
 	t.Parallel()
-	name := "test"
-	namespace := "test-namespace"
-
 	tests := []struct {
-		upstream             conf_v1.Upstream
-		vsEx                 *VirtualServerEx
-		isPlus               bool
-		isResolverConfigured bool
-		expected             []string
-		warningsExpected     bool
-		msg                  string
+		path     string
+		proxy    *conf_v1.ActionProxy
+		internal bool
+		expected string
 	}{
 		{
-			upstream: conf_v1.Upstream{
-				Service: name,
-				Port:    80,
-			},
-			vsEx: &VirtualServerEx{
-				VirtualServer: &conf_v1.VirtualServer{
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      name,
-						Namespace: namespace,
-					},
-				},
-				Endpoints: map[string][]string{
-					"test-namespace/test:80": {"example.com:80"},
-				},
-				ExternalNameSvcs: map[string]bool{
-					"test-namespace/test": true,
-				},
+			expected: "",
+		},
+		{
+			internal: true,
+			proxy: &conf_v1.ActionProxy{
+				RewritePath: "/rewrite",
 			},
-			isPlus:               true,
-			isResolverConfigured: true,
-			expected:             []string{"example.com:80"},
-			msg:                  "ExternalName service",
+			expected: "",
 		},
 		{
-			upstream: conf_v1.Upstream{
-				Service: name,
-				Port:    80,
+			path: "/path",
+			proxy: &conf_v1.ActionProxy{
+				RewritePath: "/rewrite",
 			},
-			vsEx: &VirtualServerEx{
-				VirtualServer: &conf_v1.VirtualServer{
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      name,
-						Namespace: namespace,
-					},
-				},
-				Endpoints: map[string][]string{
-					"test-namespace/test:80": {"example.com:80"},
-				},
-				ExternalNameSvcs: map[string]bool{
-					"test-namespace/test": true,
-				},
+			expected: "/rewrite",
+		},
+		{
+			path: "=/path",
+			proxy: &conf_v1.ActionProxy{
+				RewritePath: "/rewrite",
 			},
-			isPlus:               true,
-			isResolverConfigured: false,
-			warningsExpected:     true,
-			expected:             []string{},
-			msg:                  "ExternalName service without resolver configured",
+			expected: "/rewrite",
 		},
 		{
-			upstream: conf_v1.Upstream{
-				Service: name,
-				Port:    8080,
+			path: "~/path",
+			proxy: &conf_v1.ActionProxy{
+				RewritePath: "/rewrite",
 			},
-			vsEx: &VirtualServerEx{
-				VirtualServer: &conf_v1.VirtualServer{
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      name,
-						Namespace: namespace,
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateProxyPassRewrite(test.path, test.proxy, test.internal)
+		if result != test.expected {
+			t.Errorf("generateProxyPassRewrite(%v, %v, %v) returned %v but expected %v",
+				test.path, test.proxy, test.internal, result, test.expected)
+		}
+	}
+}
+
+func TestGenerateProxySetHeaders(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		proxy    *conf_v1.ActionProxy
+		expected []version2.Header
+		msg      string
+	}{
+		{
+			proxy:    nil,
+			expected: []version2.Header{{Name: "Host", Value: "$host"}},
+			msg:      "no action proxy",
+		},
+		{
+			proxy:    &conf_v1.ActionProxy{},
+			expected: []version2.Header{{Name: "Host", Value: "$host"}},
+			msg:      "empty action proxy",
+		},
+		{
+			proxy: &conf_v1.ActionProxy{
+				RequestHeaders: &conf_v1.ProxyRequestHeaders{
+					Set: []conf_v1.Header{
+						{
+							Name:  "Header-Name",
+							Value: "HeaderValue",
+						},
 					},
 				},
-				Endpoints: map[string][]string{
-					"test-namespace/test:8080": {"192.168.10.10:8080"},
+			},
+			expected: []version2.Header{
+				{
+					Name:  "Header-Name",
+					Value: "HeaderValue",
+				},
+				{
+					Name:  "Host",
+					Value: "$host",
 				},
 			},
-			isPlus:               false,
-			isResolverConfigured: false,
-			expected:             []string{"192.168.10.10:8080"},
-			msg:                  "Service with endpoints",
+			msg: "set headers without host",
 		},
 		{
-			upstream: conf_v1.Upstream{
-				Service: name,
-				Port:    8080,
-			},
-			vsEx: &VirtualServerEx{
-				VirtualServer: &conf_v1.VirtualServer{
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      name,
-						Namespace: namespace,
+			proxy: &conf_v1.ActionProxy{
+				RequestHeaders: &conf_v1.ProxyRequestHeaders{
+					Set: []conf_v1.Header{
+						{
+							Name:  "Header-Name",
+							Value: "HeaderValue",
+						},
+						{
+							Name:  "Host",
+							Value: "example.com",
+						},
 					},
 				},
-				Endpoints: map[string][]string{},
 			},
-			isPlus:               false,
-			isResolverConfigured: false,
-			expected:             []string{nginx502Server},
-			msg:                  "Service with no endpoints",
+			expected: []version2.Header{
+				{
+					Name:  "Header-Name",
+					Value: "HeaderValue",
+				},
+				{
+					Name:  "Host",
+					Value: "example.com",
+				},
+			},
+			msg: "set headers with host capitalized",
 		},
 		{
-			upstream: conf_v1.Upstream{
-				Service: name,
-				Port:    8080,
-			},
-			vsEx: &VirtualServerEx{
-				VirtualServer: &conf_v1.VirtualServer{
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      name,
-						Namespace: namespace,
+			proxy: &conf_v1.ActionProxy{
+				RequestHeaders: &conf_v1.ProxyRequestHeaders{
+					Set: []conf_v1.Header{
+						{
+							Name:  "Header-Name",
+							Value: "HeaderValue",
+						},
+						{
+							Name:  "hoST",
+							Value: "example.com",
+						},
 					},
 				},
-				Endpoints: map[string][]string{},
-			},
-			isPlus:               true,
-			isResolverConfigured: false,
-			expected:             nil,
-			msg:                  "Service with no endpoints",
-		},
-		{
-			upstream: conf_v1.Upstream{
-				Service:     name,
-				Port:        8080,
-				Subselector: map[string]string{"version": "test"},
 			},
-			vsEx: &VirtualServerEx{
-				VirtualServer: &conf_v1.VirtualServer{
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      name,
-						Namespace: namespace,
-					},
+			expected: []version2.Header{
+				{
+					Name:  "Header-Name",
+					Value: "HeaderValue",
 				},
-				Endpoints: map[string][]string{
-					"test-namespace/test_version=test:8080": {"192.168.10.10:8080"},
+				{
+					Name:  "hoST",
+					Value: "example.com",
 				},
 			},
-			isPlus:               false,
-			isResolverConfigured: false,
-			expected:             []string{"192.168.10.10:8080"},
-			msg:                  "Upstream with subselector, with a matching endpoint",
+			msg: "set headers with host in mixed case",
 		},
 		{
-			upstream: conf_v1.Upstream{
-				Service:     name,
-				Port:        8080,
-				Subselector: map[string]string{"version": "test"},
-			},
-			vsEx: &VirtualServerEx{
-				VirtualServer: &conf_v1.VirtualServer{
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      name,
-						Namespace: namespace,
+			proxy: &conf_v1.ActionProxy{
+				RequestHeaders: &conf_v1.ProxyRequestHeaders{
+					Set: []conf_v1.Header{
+						{
+							Name:  "Header-Name",
+							Value: "HeaderValue",
+						},
+						{
+							Name:  "Host",
+							Value: "one.example.com",
+						},
+						{
+							Name:  "Host",
+							Value: "two.example.com",
+						},
 					},
 				},
-				Endpoints: map[string][]string{
-					"test-namespace/test:8080": {"192.168.10.10:8080"},
+			},
+			expected: []version2.Header{
+				{
+					Name:  "Header-Name",
+					Value: "HeaderValue",
+				},
+				{
+					Name:  "Host",
+					Value: "one.example.com",
+				},
+				{
+					Name:  "Host",
+					Value: "two.example.com",
 				},
 			},
-			isPlus:               false,
-			isResolverConfigured: false,
-			expected:             []string{nginx502Server},
-			msg:                  "Upstream with subselector, without a matching endpoint",
+			msg: "set headers with multiple hosts",
 		},
 	}
 
 	for _, test := range tests {
-		isWildcardEnabled := false
-		vsc := newVirtualServerConfigurator(
-			&ConfigParams{},
-			test.isPlus,
-			test.isResolverConfigured,
-			&StaticConfigParams{},
-			isWildcardEnabled,
-		)
-		result := vsc.generateEndpointsForUpstream(test.vsEx.VirtualServer, namespace, test.upstream, test.vsEx)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateEndpointsForUpstream(isPlus=%v, isResolverConfigured=%v) returned %v, but expected %v for case: %v",
-				test.isPlus, test.isResolverConfigured, result, test.expected, test.msg)
-		}
-
-		if len(vsc.warnings) == 0 && test.warningsExpected {
-			t.Errorf(
-				"generateEndpointsForUpstream(isPlus=%v, isResolverConfigured=%v) didn't return any warnings for %v but warnings expected",
-				test.isPlus,
-				test.isResolverConfigured,
-				test.upstream,
-			)
-		}
-
-		if len(vsc.warnings) != 0 && !test.warningsExpected {
-			t.Errorf("generateEndpointsForUpstream(isPlus=%v, isResolverConfigured=%v) returned warnings for %v",
-				test.isPlus, test.isResolverConfigured, test.upstream)
+		result := generateProxySetHeaders(test.proxy)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("generateProxySetHeaders() '%v' mismatch (-want +got):\n%s", test.msg, diff)
 		}
 	}
 }
 
-func TestGenerateSlowStartForPlusWithInCompatibleLBMethods(t *testing.T) {
+func TestGenerateProxyPassRequestHeaders(t *testing.T) {
 	t.Parallel()
-	serviceName := "test-slowstart-with-incompatible-LBMethods"
-	upstream := conf_v1.Upstream{Service: serviceName, Port: 80, SlowStart: "10s"}
-	expected := ""
-
-	tests := []string{
-		"random",
-		"ip_hash",
-		"hash 123",
-		"random two",
-		"random two least_conn",
-		"random two least_time=header",
-		"random two least_time=last_byte",
-	}
-
-	for _, lbMethod := range tests {
-		vsc := newVirtualServerConfigurator(&ConfigParams{}, true, false, &StaticConfigParams{}, false)
-		result := vsc.generateSlowStartForPlus(&conf_v1.VirtualServer{}, upstream, lbMethod)
-
-		if !reflect.DeepEqual(result, expected) {
-			t.Errorf("generateSlowStartForPlus returned %v, but expected %v for lbMethod %v", result, expected, lbMethod)
-		}
-
-		if len(vsc.warnings) == 0 {
-			t.Errorf("generateSlowStartForPlus returned no warnings for %v but warnings expected", upstream)
-		}
-	}
-}
-
-func TestGenerateSlowStartForPlus(t *testing.T) {
-	serviceName := "test-slowstart"
-
+	passTrue := true
+	passFalse := false
 	tests := []struct {
-		upstream conf_v1.Upstream
-		lbMethod string
-		expected string
+		proxy    *conf_v1.ActionProxy
+		expected bool
 	}{
 		{
-			upstream: conf_v1.Upstream{Service: serviceName, Port: 80, SlowStart: "", LBMethod: "least_conn"},
-			lbMethod: "least_conn",
-			expected: "",
+			proxy:    nil,
+			expected: true,
 		},
 		{
-			upstream: conf_v1.Upstream{Service: serviceName, Port: 80, SlowStart: "10s", LBMethod: "least_conn"},
-			lbMethod: "least_conn",
-			expected: "10s",
-		},
-	}
-
-	for _, test := range tests {
-		vsc := newVirtualServerConfigurator(&ConfigParams{}, true, false, &StaticConfigParams{}, false)
-		result := vsc.generateSlowStartForPlus(&conf_v1.VirtualServer{}, test.upstream, test.lbMethod)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateSlowStartForPlus returned %v, but expected %v", result, test.expected)
-		}
-
-		if len(vsc.warnings) != 0 {
-			t.Errorf("generateSlowStartForPlus returned warnings for %v", test.upstream)
-		}
-	}
-}
-
-func TestCreateEndpointsFromUpstream(t *testing.T) {
-	t.Parallel()
-	ups := version2.Upstream{
-		Servers: []version2.UpstreamServer{
-			{
-				Address: "10.0.0.20:80",
-			},
-			{
-				Address: "10.0.0.30:80",
-			},
+			proxy:    &conf_v1.ActionProxy{},
+			expected: true,
 		},
-	}
-
-	expected := []string{
-		"10.0.0.20:80",
-		"10.0.0.30:80",
-	}
-
-	endpoints := createEndpointsFromUpstream(ups)
-	if !reflect.DeepEqual(endpoints, expected) {
-		t.Errorf("createEndpointsFromUpstream returned %v, but expected %v", endpoints, expected)
-	}
-}
-
-func TestGenerateUpstreamWithQueue(t *testing.T) {
-	t.Parallel()
-	serviceName := "test-queue"
-
-	tests := []struct {
-		name     string
-		upstream conf_v1.Upstream
-		isPlus   bool
-		expected version2.Upstream
-		msg      string
-	}{
 		{
-			name: "test-upstream-queue",
-			upstream: conf_v1.Upstream{Service: serviceName, Port: 80, Queue: &conf_v1.UpstreamQueue{
-				Size:    10,
-				Timeout: "10s",
-			}},
-			isPlus: true,
-			expected: version2.Upstream{
-				UpstreamLabels: version2.UpstreamLabels{
-					Service: "test-queue",
-				},
-				Name: "test-upstream-queue",
-				Queue: &version2.Queue{
-					Size:    10,
-					Timeout: "10s",
+			proxy: &conf_v1.ActionProxy{
+				RequestHeaders: &conf_v1.ProxyRequestHeaders{
+					Pass: nil,
 				},
 			},
-			msg: "upstream queue with size and timeout",
+			expected: true,
 		},
 		{
-			name: "test-upstream-queue-with-default-timeout",
-			upstream: conf_v1.Upstream{
-				Service: serviceName,
-				Port:    80,
-				Queue:   &conf_v1.UpstreamQueue{Size: 10, Timeout: ""},
-			},
-			isPlus: true,
-			expected: version2.Upstream{
-				UpstreamLabels: version2.UpstreamLabels{
-					Service: "test-queue",
-				},
-				Name: "test-upstream-queue-with-default-timeout",
-				Queue: &version2.Queue{
-					Size:    10,
-					Timeout: "60s",
+			proxy: &conf_v1.ActionProxy{
+				RequestHeaders: &conf_v1.ProxyRequestHeaders{
+					Pass: &passTrue,
 				},
 			},
-			msg: "upstream queue with only size",
-		},
-		{
-			name:     "test-upstream-queue-nil",
-			upstream: conf_v1.Upstream{Service: serviceName, Port: 80, Queue: nil},
-			isPlus:   false,
-			expected: version2.Upstream{
-				UpstreamLabels: version2.UpstreamLabels{
-					Service: "test-queue",
+			expected: true,
+		},
+		{
+			proxy: &conf_v1.ActionProxy{
+				RequestHeaders: &conf_v1.ProxyRequestHeaders{
+					Pass: &passFalse,
 				},
-				Name: "test-upstream-queue-nil",
 			},
-			msg: "upstream queue with nil for OSS",
+			expected: false,
 		},
 	}
 
 	for _, test := range tests {
-		vsc := newVirtualServerConfigurator(&ConfigParams{}, test.isPlus, false, &StaticConfigParams{}, false)
-		result := vsc.generateUpstream(nil, test.name, test.upstream, false, []string{})
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateUpstream() returned %v but expected %v for the case of %v", result, test.expected, test.msg)
+		result := generateProxyPassRequestHeaders(test.proxy)
+		if result != test.expected {
+			t.Errorf("generateProxyPassRequestHeaders(%v) returned %v but expected %v", test.proxy, result, test.expected)
 		}
 	}
 }
 
-func TestGenerateQueueForPlus(t *testing.T) {
+func TestGenerateProxyHideHeaders(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		upstreamQueue *conf_v1.UpstreamQueue
-		expected      *version2.Queue
-		msg           string
+		proxy    *conf_v1.ActionProxy
+		expected []string
 	}{
 		{
-			upstreamQueue: &conf_v1.UpstreamQueue{Size: 10, Timeout: "10s"},
-			expected:      &version2.Queue{Size: 10, Timeout: "10s"},
-			msg:           "upstream queue with size and timeout",
+			proxy:    nil,
+			expected: nil,
 		},
 		{
-			upstreamQueue: nil,
-			expected:      nil,
-			msg:           "upstream queue with nil",
+			proxy: &conf_v1.ActionProxy{
+				ResponseHeaders: nil,
+			},
 		},
 		{
-			upstreamQueue: &conf_v1.UpstreamQueue{Size: 10},
-			expected:      &version2.Queue{Size: 10, Timeout: "60s"},
-			msg:           "upstream queue with only size",
+			proxy: &conf_v1.ActionProxy{
+				ResponseHeaders: &conf_v1.ProxyResponseHeaders{
+					Hide: []string{"Header", "Header-2"},
+				},
+			},
+			expected: []string{"Header", "Header-2"},
 		},
 	}
 
 	for _, test := range tests {
-		result := generateQueueForPlus(test.upstreamQueue, "60s")
+		result := generateProxyHideHeaders(test.proxy)
 		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateQueueForPlus() returned %v but expected %v for the case of %v", result, test.expected, test.msg)
+			t.Errorf("generateProxyHideHeaders(%v) returned %v but expected %v", test.proxy, result, test.expected)
 		}
 	}
 }
 
-func TestGenerateSessionCookie(t *testing.T) {
+func TestGenerateProxyPassHeaders(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		sc       *conf_v1.SessionCookie
-		expected *version2.SessionCookie
-		msg      string
+		proxy    *conf_v1.ActionProxy
+		expected []string
 	}{
 		{
-			sc:       &conf_v1.SessionCookie{Enable: true, Name: "test"},
-			expected: &version2.SessionCookie{Enable: true, Name: "test"},
-			msg:      "session cookie with name",
+			proxy:    nil,
+			expected: nil,
 		},
 		{
-			sc:       nil,
-			expected: nil,
-			msg:      "session cookie with nil",
+			proxy: &conf_v1.ActionProxy{
+				ResponseHeaders: nil,
+			},
 		},
 		{
-			sc:       &conf_v1.SessionCookie{Name: "test"},
-			expected: nil,
-			msg:      "session cookie not enabled",
+			proxy: &conf_v1.ActionProxy{
+				ResponseHeaders: &conf_v1.ProxyResponseHeaders{
+					Pass: []string{"Header", "Header-2"},
+				},
+			},
+			expected: []string{"Header", "Header-2"},
 		},
 	}
+
 	for _, test := range tests {
-		result := generateSessionCookie(test.sc)
+		result := generateProxyPassHeaders(test.proxy)
 		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateSessionCookie() returned %v, but expected %v for the case of: %v", result, test.expected, test.msg)
+			t.Errorf("generateProxyPassHeaders(%v) returned %v but expected %v", test.proxy, result, test.expected)
 		}
 	}
 }
 
-func TestGeneratePath(t *testing.T) {
+func TestGenerateProxyIgnoreHeaders(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		path     string
+		proxy    *conf_v1.ActionProxy
 		expected string
 	}{
 		{
-			path:     "/",
-			expected: "/",
-		},
-		{
-			path:     "=/exact/match",
-			expected: "=/exact/match",
+			proxy:    nil,
+			expected: "",
 		},
 		{
-			path:     `~ *\\.jpg`,
-			expected: `~ "*\\.jpg"`,
+			proxy: &conf_v1.ActionProxy{
+				ResponseHeaders: nil,
+			},
+			expected: "",
 		},
 		{
-			path:     `~* *\\.PNG`,
-			expected: `~* "*\\.PNG"`,
+			proxy: &conf_v1.ActionProxy{
+				ResponseHeaders: &conf_v1.ProxyResponseHeaders{
+					Ignore: []string{"Header", "Header-2"},
+				},
+			},
+			expected: "Header Header-2",
 		},
 	}
 
 	for _, test := range tests {
-		result := generatePath(test.path)
+		result := generateProxyIgnoreHeaders(test.proxy)
 		if result != test.expected {
-			t.Errorf("generatePath() returned %v, but expected %v.", result, test.expected)
+			t.Errorf("generateProxyIgnoreHeaders(%v) returned %v but expected %v", test.proxy, result, test.expected)
 		}
 	}
 }
 
-func TestGenerateErrorPageName(t *testing.T) {
+func TestGenerateProxyAddHeaders(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		routeIndex int
-		index      int
-		expected   string
+		proxy    *conf_v1.ActionProxy
+		expected []version2.AddHeader
 	}{
 		{
-			0,
-			0,
-			"@error_page_0_0",
+			proxy:    nil,
+			expected: nil,
 		},
 		{
-			0,
-			1,
-			"@error_page_0_1",
+			proxy:    &conf_v1.ActionProxy{},
+			expected: nil,
 		},
 		{
-			1,
-			0,
-			"@error_page_1_0",
+			proxy: &conf_v1.ActionProxy{
+				ResponseHeaders: &conf_v1.ProxyResponseHeaders{
+					Add: []conf_v1.AddHeader{
+						{
+							Header: conf_v1.Header{
+								Name:  "Header-Name",
+								Value: "HeaderValue",
+							},
+							Always: true,
+						},
+						{
+							Header: conf_v1.Header{
+								Name:  "Server",
+								Value: "myServer",
+							},
+							Always: false,
+						},
+					},
+				},
+			},
+			expected: []version2.AddHeader{
+				{
+					Header: version2.Header{
+						Name:  "Header-Name",
+						Value: "HeaderValue",
+					},
+					Always: true,
+				},
+				{
+					Header: version2.Header{
+						Name:  "Server",
+						Value: "myServer",
+					},
+					Always: false,
+				},
+			},
 		},
 	}
 
 	for _, test := range tests {
-		result := generateErrorPageName(test.routeIndex, test.index)
-		if result != test.expected {
-			t.Errorf("generateErrorPageName(%v, %v) returned %v but expected %v", test.routeIndex, test.index, result, test.expected)
+		result := generateProxyAddHeaders(test.proxy)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateProxyAddHeaders(%v) returned %v but expected %v", test.proxy, result, test.expected)
 		}
 	}
 }
 
-func TestGenerateErrorPageCodes(t *testing.T) {
+func TestGetUpstreamResourceLabels(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		codes    []int
-		expected string
+		owner    runtime.Object
+		expected version2.UpstreamLabels
 	}{
 		{
-			codes:    []int{400},
-			expected: "400",
-		},
-		{
-			codes:    []int{404, 405, 502},
-			expected: "404 405 502",
+			owner:    nil,
+			expected: version2.UpstreamLabels{},
 		},
-	}
-
-	for _, test := range tests {
-		result := generateErrorPageCodes(test.codes)
-		if result != test.expected {
-			t.Errorf("generateErrorPageCodes(%v) returned %v but expected %v", test.codes, result, test.expected)
-		}
-	}
-}
-
-func TestGenerateErrorPages(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		upstreamName string
-		errorPages   []conf_v1.ErrorPage
-		expected     []version2.ErrorPage
-	}{
-		{}, // empty errorPages
 		{
-			"vs_test_test",
-			[]conf_v1.ErrorPage{
-				{
-					Codes: []int{404, 405, 500, 502},
-					Return: &conf_v1.ErrorPageReturn{
-						ActionReturn: conf_v1.ActionReturn{
-							Code: 200,
-						},
-						Headers: nil,
-					},
-					Redirect: nil,
+			owner: &conf_v1.VirtualServer{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Namespace: "namespace",
+					Name:      "name",
 				},
 			},
-			[]version2.ErrorPage{
-				{
-					Name:         "@error_page_1_0",
-					Codes:        "404 405 500 502",
-					ResponseCode: 200,
-				},
+			expected: version2.UpstreamLabels{
+				ResourceNamespace: "namespace",
+				ResourceName:      "name",
+				ResourceType:      "virtualserver",
 			},
 		},
 		{
-			"vs_test_test",
-			[]conf_v1.ErrorPage{
-				{
-					Codes:  []int{404, 405, 500, 502},
-					Return: nil,
-					Redirect: &conf_v1.ErrorPageRedirect{
-						ActionRedirect: conf_v1.ActionRedirect{
-							URL:  "http://nginx.org",
-							Code: 302,
-						},
-					},
+			owner: &conf_v1.VirtualServerRoute{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Namespace: "namespace",
+					Name:      "name",
 				},
 			},
-			[]version2.ErrorPage{
-				{
-					Name:         "http://nginx.org",
-					Codes:        "404 405 500 502",
-					ResponseCode: 302,
-				},
+			expected: version2.UpstreamLabels{
+				ResourceNamespace: "namespace",
+				ResourceName:      "name",
+				ResourceType:      "virtualserverroute",
 			},
 		},
 	}
-
-	for i, test := range tests {
-		result := generateErrorPages(i, test.errorPages)
+	for _, test := range tests {
+		result := getUpstreamResourceLabels(test.owner)
 		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateErrorPages(%v, %v) returned %v but expected %v", test.upstreamName, test.errorPages, result, test.expected)
+			t.Errorf("getUpstreamResourceLabels(%+v) returned %+v but expected %+v", test.owner, result, test.expected)
 		}
 	}
 }
 
-func TestGenerateErrorPageLocations(t *testing.T) {
+func TestAddWafConfig(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		upstreamName string
-		errorPages   []conf_v1.ErrorPage
-		expected     []version2.ErrorPageLocation
+		wafInput     *conf_v1.WAF
+		polKey       string
+		polNamespace string
+		apResources  *appProtectResourcesForVS
+		wafConfig    *version2.WAF
+		expected     *validationResults
+		msg          string
 	}{
-		{},
 		{
-			"vs_test_test",
-			[]conf_v1.ErrorPage{
-				{
-					Codes:  []int{404, 405, 500, 502},
-					Return: nil,
-					Redirect: &conf_v1.ErrorPageRedirect{
-						ActionRedirect: conf_v1.ActionRedirect{
-							URL:  "http://nginx.org",
-							Code: 302,
-						},
-					},
+			wafInput: &conf_v1.WAF{
+				Enable: true,
+			},
+			polKey:       "default/waf-policy",
+			polNamespace: "default",
+			apResources: &appProtectResourcesForVS{
+				Policies: map[string]string{},
+				LogConfs: map[string]string{},
+			},
+			wafConfig: &version2.WAF{
+				Enable: "on",
+			},
+			expected: &validationResults{isError: false},
+			msg:      "valid waf config, default App Protect config",
+		},
+		{
+			wafInput: &conf_v1.WAF{
+				Enable:   true,
+				ApPolicy: "dataguard-alarm",
+				SecurityLog: &conf_v1.SecurityLog{
+					Enable:    true,
+					ApLogConf: "logconf",
+					LogDest:   "syslog:server=127.0.0.1:514",
 				},
 			},
-			nil,
+			polKey:       "default/waf-policy",
+			polNamespace: "default",
+			apResources: &appProtectResourcesForVS{
+				Policies: map[string]string{
+					"default/dataguard-alarm": "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
+				},
+				LogConfs: map[string]string{
+					"default/logconf": "/etc/nginx/waf/nac-logconfs/default-logconf",
+				},
+			},
+			wafConfig: &version2.WAF{
+				ApPolicy:            "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
+				ApSecurityLogEnable: true,
+				ApLogConf:           []string{"/etc/nginx/waf/nac-logconfs/default-logconf"},
+			},
+			expected: &validationResults{isError: false},
+			msg:      "valid waf config",
 		},
 		{
-			"vs_test_test",
-			[]conf_v1.ErrorPage{
-				{
-					Codes: []int{404, 405, 500, 502},
-					Return: &conf_v1.ErrorPageReturn{
-						ActionReturn: conf_v1.ActionReturn{
-							Code: 200,
-							Type: "application/json",
-							Body: "Hello World",
-						},
-						Headers: []conf_v1.Header{
-							{
-								Name:  "HeaderName",
-								Value: "HeaderValue",
-							},
-						},
+			wafInput: &conf_v1.WAF{
+				Enable:   true,
+				ApPolicy: "dataguard-alarm",
+				SecurityLogs: []*conf_v1.SecurityLog{
+					{
+						Enable:    true,
+						ApLogConf: "logconf",
+						LogDest:   "syslog:server=127.0.0.1:514",
 					},
-					Redirect: nil,
 				},
 			},
-			[]version2.ErrorPageLocation{
-				{
-					Name:        "@error_page_2_0",
-					DefaultType: "application/json",
-					Return: &version2.Return{
-						Code: 0,
-						Text: "Hello World",
-					},
-					Headers: []version2.Header{
-						{
-							Name:  "HeaderName",
-							Value: "HeaderValue",
-						},
-					},
+			polKey:       "default/waf-policy",
+			polNamespace: "default",
+			apResources: &appProtectResourcesForVS{
+				Policies: map[string]string{
+					"default/dataguard-alarm": "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
+				},
+				LogConfs: map[string]string{
+					"default/logconf": "/etc/nginx/waf/nac-logconfs/default-logconf",
 				},
 			},
+			wafConfig: &version2.WAF{
+				ApPolicy:            "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
+				ApSecurityLogEnable: true,
+				ApLogConf:           []string{"/etc/nginx/waf/nac-logconfs/default-logconf"},
+			},
+			expected: &validationResults{isError: false},
+			msg:      "valid waf config",
 		},
-	}
-
-	for i, test := range tests {
-		result := generateErrorPageLocations(i, test.errorPages)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateErrorPageLocations(%v, %v) returned %v but expected %v", test.upstreamName, test.errorPages, result, test.expected)
-		}
-	}
-}
-
-func TestGenerateProxySSLName(t *testing.T) {
-	t.Parallel()
-	result := generateProxySSLName("coffee-v1", "default")
-	if result != "coffee-v1.default.svc" {
-		t.Errorf("generateProxySSLName(coffee-v1, default) returned %v but expected coffee-v1.default.svc", result)
-	}
-}
-
-func TestIsTLSEnabled(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		upstream   conf_v1.Upstream
-		spiffeCert bool
-		expected   bool
-	}{
 		{
-			upstream: conf_v1.Upstream{
-				TLS: conf_v1.UpstreamTLS{
-					Enable: false,
+			wafInput: &conf_v1.WAF{
+				Enable:   true,
+				ApPolicy: "default/dataguard-alarm",
+				SecurityLog: &conf_v1.SecurityLog{
+					Enable:    true,
+					ApLogConf: "default/logconf",
+					LogDest:   "syslog:server=127.0.0.1:514",
 				},
 			},
-			spiffeCert: false,
-			expected:   false,
+			polKey:       "default/waf-policy",
+			polNamespace: "",
+			apResources: &appProtectResourcesForVS{
+				Policies: map[string]string{
+					"default/dataguard-alarm": "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
+				},
+				LogConfs: map[string]string{},
+			},
+			wafConfig: &version2.WAF{
+				ApPolicy:            "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
+				ApSecurityLogEnable: true,
+				ApLogConf:           []string{"/etc/nginx/waf/nac-logconfs/default-logconf"},
+			},
+			expected: &validationResults{
+				isError: true,
+				warnings: []string{
+					`WAF policy default/waf-policy references an invalid or non-existing log config default/logconf`,
+				},
+			},
+			msg: "invalid waf config, apLogConf references non-existing log conf",
 		},
 		{
-			upstream: conf_v1.Upstream{
-				TLS: conf_v1.UpstreamTLS{
-					Enable: false,
+			wafInput: &conf_v1.WAF{
+				Enable:   true,
+				ApPolicy: "default/dataguard-alarm",
+				SecurityLog: &conf_v1.SecurityLog{
+					Enable:  true,
+					LogDest: "syslog:server=127.0.0.1:514",
 				},
 			},
-			spiffeCert: true,
-			expected:   true,
+			polKey:       "default/waf-policy",
+			polNamespace: "",
+			apResources: &appProtectResourcesForVS{
+				Policies: map[string]string{},
+				LogConfs: map[string]string{
+					"default/logconf": "/etc/nginx/waf/nac-logconfs/default-logconf",
+				},
+			},
+			wafConfig: &version2.WAF{
+				ApPolicy:            "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
+				ApSecurityLogEnable: true,
+				ApLogConf:           []string{"/etc/nginx/waf/nac-logconfs/default-logconf"},
+			},
+			expected: &validationResults{
+				isError: true,
+				warnings: []string{
+					`WAF policy default/waf-policy references an invalid or non-existing App Protect policy default/dataguard-alarm`,
+				},
+			},
+			msg: "invalid waf config, apLogConf references non-existing ap conf",
 		},
 		{
-			upstream: conf_v1.Upstream{
-				TLS: conf_v1.UpstreamTLS{
-					Enable: true,
+			wafInput: &conf_v1.WAF{
+				Enable:   true,
+				ApPolicy: "ns1/dataguard-alarm",
+				SecurityLog: &conf_v1.SecurityLog{
+					Enable:    true,
+					ApLogConf: "ns2/logconf",
+					LogDest:   "syslog:server=127.0.0.1:514",
 				},
 			},
-			spiffeCert: true,
-			expected:   true,
+			polKey:       "default/waf-policy",
+			polNamespace: "",
+			apResources: &appProtectResourcesForVS{
+				Policies: map[string]string{
+					"ns1/dataguard-alarm": "/etc/nginx/waf/nac-policies/ns1-dataguard-alarm",
+				},
+				LogConfs: map[string]string{
+					"ns2/logconf": "/etc/nginx/waf/nac-logconfs/ns2-logconf",
+				},
+			},
+			wafConfig: &version2.WAF{
+				ApPolicy:            "/etc/nginx/waf/nac-policies/ns1-dataguard-alarm",
+				ApSecurityLogEnable: true,
+				ApLogConf:           []string{"/etc/nginx/waf/nac-logconfs/ns2-logconf"},
+			},
+			expected: &validationResults{},
+			msg:      "valid waf config, cross ns reference",
 		},
 		{
-			upstream: conf_v1.Upstream{
-				TLS: conf_v1.UpstreamTLS{
-					Enable: true,
+			wafInput: &conf_v1.WAF{
+				Enable:   false,
+				ApPolicy: "dataguard-alarm",
+			},
+			polKey:       "default/waf-policy",
+			polNamespace: "default",
+			apResources: &appProtectResourcesForVS{
+				Policies: map[string]string{
+					"default/dataguard-alarm": "/etc/nginx/waf/nac-policies/ns1-dataguard-alarm",
 				},
+				LogConfs: map[string]string{
+					"default/logconf": "/etc/nginx/waf/nac-logconfs/ns2-logconf",
+				},
+			},
+			wafConfig: &version2.WAF{
+				Enable:   "off",
+				ApPolicy: "/etc/nginx/waf/nac-policies/ns1-dataguard-alarm",
 			},
-			spiffeCert: false,
-			expected:   true,
+			expected: &validationResults{},
+			msg:      "valid waf config, disable waf",
 		},
 	}
 
 	for _, test := range tests {
-		result := isTLSEnabled(test.upstream, test.spiffeCert)
-		if result != test.expected {
-			t.Errorf("isTLSEnabled(%v, %v) returned %v but expected %v", test.upstream, test.spiffeCert, result, test.expected)
+		polCfg := newPoliciesConfig()
+		result := polCfg.addWAFConfig(test.wafInput, test.polKey, test.polNamespace, test.apResources)
+		if diff := cmp.Diff(test.expected.warnings, result.warnings); diff != "" {
+			t.Errorf("policiesCfg.addWAFConfig() '%v' mismatch (-want +got):\n%s", test.msg, diff)
 		}
 	}
 }
 
-func TestGenerateRewrites(t *testing.T) {
+func TestGenerateTime(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		path         string
-		proxy        *conf_v1.ActionProxy
-		internal     bool
-		originalPath string
-		grpcEnabled  bool
-		expected     []string
-		msg          string
+		value, expected string
 	}{
 		{
-			proxy:    nil,
-			expected: nil,
-			msg:      "action isn't proxy",
-		},
-		{
-			proxy: &conf_v1.ActionProxy{
-				RewritePath: "",
-			},
-			expected: nil,
-			msg:      "no rewrite is configured",
-		},
-		{
-			path: "/path",
-			proxy: &conf_v1.ActionProxy{
-				RewritePath: "/rewrite",
-			},
-			expected: nil,
-			msg:      "non-regex rewrite for non-internal location is not needed",
-		},
-		{
-			path:     "/_internal_path",
-			internal: true,
-			proxy: &conf_v1.ActionProxy{
-				RewritePath: "/rewrite",
-			},
-			originalPath: "/path",
-			expected:     []string{`^ $request_uri_no_args`, `"^/path(.*)$" "/rewrite$1" break`},
-			msg:          "non-regex rewrite for internal location",
-		},
-		{
-			path:     "~/regex",
-			internal: true,
-			proxy: &conf_v1.ActionProxy{
-				RewritePath: "/rewrite",
-			},
-			originalPath: "/path",
-			expected:     []string{`^ $request_uri_no_args`, `"^/path(.*)$" "/rewrite$1" break`},
-			msg:          "regex rewrite for internal location",
+			value:    "0s",
+			expected: "0s",
 		},
 		{
-			path:     "~/regex",
-			internal: false,
-			proxy: &conf_v1.ActionProxy{
-				RewritePath: "/rewrite",
-			},
-			expected: []string{`"^/regex" "/rewrite" break`},
-			msg:      "regex rewrite for non-internal location",
+			value:    "0",
+			expected: "0s",
 		},
 		{
-			path:     "/_internal_path",
-			internal: true,
-			proxy: &conf_v1.ActionProxy{
-				RewritePath: "/rewrite",
-			},
-			originalPath: "/path",
-			grpcEnabled:  true,
-			expected:     []string{`^ $request_uri_no_args`, `"^/path(.*)$" "/rewrite$1" break`},
-			msg:          "non-regex rewrite for internal location with grpc enabled",
+			value:    "1h",
+			expected: "1h",
 		},
 		{
-			path:         "/_internal_path",
-			internal:     true,
-			originalPath: "/path",
-			grpcEnabled:  true,
-			expected:     []string{`^ $request_uri break`},
-			msg:          "empty rewrite for internal location with grpc enabled",
+			value:    "1h 30m",
+			expected: "1h30m",
 		},
 	}
 
 	for _, test := range tests {
-		result := generateRewrites(test.path, test.proxy, test.internal, test.originalPath, test.grpcEnabled)
-		if diff := cmp.Diff(test.expected, result); diff != "" {
-			t.Errorf("generateRewrites() '%v' mismatch (-want +got):\n%s", test.msg, diff)
+		result := generateTime(test.value)
+		if result != test.expected {
+			t.Errorf("generateTime(%q) returned %q but expected %q", test.value, result, test.expected)
 		}
 	}
 }
 
-func TestGenerateProxyPassRewrite(t *testing.T) {
+func TestGenerateTimeWithDefault(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		path     string
-		proxy    *conf_v1.ActionProxy
-		internal bool
-		expected string
+		value, defaultValue, expected string
 	}{
 		{
-			expected: "",
-		},
-		{
-			internal: true,
-			proxy: &conf_v1.ActionProxy{
-				RewritePath: "/rewrite",
-			},
-			expected: "",
-		},
-		{
-			path: "/path",
-			proxy: &conf_v1.ActionProxy{
-				RewritePath: "/rewrite",
-			},
-			expected: "/rewrite",
+			value:        "1h 30m",
+			defaultValue: "",
+			expected:     "1h30m",
 		},
 		{
-			path: "=/path",
-			proxy: &conf_v1.ActionProxy{
-				RewritePath: "/rewrite",
-			},
-			expected: "/rewrite",
+			value:        "",
+			defaultValue: "60s",
+			expected:     "60s",
 		},
 		{
-			path: "~/path",
-			proxy: &conf_v1.ActionProxy{
-				RewritePath: "/rewrite",
-			},
-			expected: "",
+			value:        "",
+			defaultValue: "test",
+			expected:     "test",
 		},
 	}
 
 	for _, test := range tests {
-		result := generateProxyPassRewrite(test.path, test.proxy, test.internal)
+		result := generateTimeWithDefault(test.value, test.defaultValue)
 		if result != test.expected {
-			t.Errorf("generateProxyPassRewrite(%v, %v, %v) returned %v but expected %v",
-				test.path, test.proxy, test.internal, result, test.expected)
+			t.Errorf("generateTimeWithDefault(%q, %q) returned %q but expected %q", test.value, test.defaultValue, result, test.expected)
 		}
 	}
 }
 
-func TestGenerateProxySetHeaders(t *testing.T) {
+func TestGenerateCanaryRoute(t *testing.T) {
 	t.Parallel()
+	stableAction := &conf_v1.Action{
+		Pass: "tea",
+	}
+	canaryAction := &conf_v1.Action{
+		Pass: "tea-canary",
+	}
+
 	tests := []struct {
-		proxy    *conf_v1.ActionProxy
-		expected []version2.Header
+		route    conf_v1.Route
+		expected conf_v1.Route
 		msg      string
 	}{
 		{
-			proxy:    nil,
-			expected: []version2.Header{{Name: "Host", Value: "$host"}},
-			msg:      "no action proxy",
-		},
-		{
-			proxy:    &conf_v1.ActionProxy{},
-			expected: []version2.Header{{Name: "Host", Value: "$host"}},
-			msg:      "empty action proxy",
-		},
-		{
-			proxy: &conf_v1.ActionProxy{
-				RequestHeaders: &conf_v1.ProxyRequestHeaders{
-					Set: []conf_v1.Header{
-						{
-							Name:  "Header-Name",
-							Value: "HeaderValue",
-						},
-					},
-				},
-			},
-			expected: []version2.Header{
-				{
-					Name:  "Header-Name",
-					Value: "HeaderValue",
-				},
-				{
-					Name:  "Host",
-					Value: "$host",
+			route: conf_v1.Route{
+				Path:   "/tea",
+				Action: stableAction,
+				Canary: &conf_v1.Canary{
+					Header: "x-canary",
+					Cookie: "canary",
+					Weight: 20,
+					Action: canaryAction,
 				},
 			},
-			msg: "set headers without host",
-		},
-		{
-			proxy: &conf_v1.ActionProxy{
-				RequestHeaders: &conf_v1.ProxyRequestHeaders{
-					Set: []conf_v1.Header{
-						{
-							Name:  "Header-Name",
-							Value: "HeaderValue",
-						},
-						{
-							Name:  "Host",
-							Value: "example.com",
-						},
+			expected: conf_v1.Route{
+				Path: "/tea",
+				Matches: []conf_v1.Match{
+					{
+						Conditions: []conf_v1.Condition{{Header: "x-canary", Value: "always"}},
+						Action:     canaryAction,
+					},
+					{
+						Conditions: []conf_v1.Condition{{Header: "x-canary", Value: "never"}},
+						Action:     stableAction,
+					},
+					{
+						Conditions: []conf_v1.Condition{{Cookie: "canary", Value: "always"}},
+						Action:     canaryAction,
+					},
+					{
+						Conditions: []conf_v1.Condition{{Cookie: "canary", Value: "never"}},
+						Action:     stableAction,
 					},
 				},
-			},
-			expected: []version2.Header{
-				{
-					Name:  "Header-Name",
-					Value: "HeaderValue",
-				},
-				{
-					Name:  "Host",
-					Value: "example.com",
+				Splits: []conf_v1.Split{
+					{
+						Weight: 20,
+						Action: canaryAction,
+					},
+					{
+						Weight: 80,
+						Action: stableAction,
+					},
 				},
 			},
-			msg: "set headers with host capitalized",
+			msg: "header, cookie and weight",
 		},
 		{
-			proxy: &conf_v1.ActionProxy{
-				RequestHeaders: &conf_v1.ProxyRequestHeaders{
-					Set: []conf_v1.Header{
-						{
-							Name:  "Header-Name",
-							Value: "HeaderValue",
-						},
-						{
-							Name:  "hoST",
-							Value: "example.com",
-						},
-					},
-				},
-			},
-			expected: []version2.Header{
-				{
-					Name:  "Header-Name",
-					Value: "HeaderValue",
-				},
-				{
-					Name:  "hoST",
-					Value: "example.com",
+			route: conf_v1.Route{
+				Path:   "/tea",
+				Action: stableAction,
+				Canary: &conf_v1.Canary{
+					Header: "x-canary",
+					Action: canaryAction,
 				},
 			},
-			msg: "set headers with host in mixed case",
-		},
-		{
-			proxy: &conf_v1.ActionProxy{
-				RequestHeaders: &conf_v1.ProxyRequestHeaders{
-					Set: []conf_v1.Header{
-						{
-							Name:  "Header-Name",
-							Value: "HeaderValue",
-						},
-						{
-							Name:  "Host",
-							Value: "one.example.com",
-						},
-						{
-							Name:  "Host",
-							Value: "two.example.com",
-						},
+			expected: conf_v1.Route{
+				Path:   "/tea",
+				Action: stableAction,
+				Matches: []conf_v1.Match{
+					{
+						Conditions: []conf_v1.Condition{{Header: "x-canary", Value: "always"}},
+						Action:     canaryAction,
+					},
+					{
+						Conditions: []conf_v1.Condition{{Header: "x-canary", Value: "never"}},
+						Action:     stableAction,
 					},
 				},
 			},
-			expected: []version2.Header{
-				{
-					Name:  "Header-Name",
-					Value: "HeaderValue",
-				},
-				{
-					Name:  "Host",
-					Value: "one.example.com",
-				},
-				{
-					Name:  "Host",
-					Value: "two.example.com",
+			msg: "header without weight",
+		},
+		{
+			route: conf_v1.Route{
+				Path:   "/tea",
+				Action: stableAction,
+				Canary: &conf_v1.Canary{
+					Weight: 100,
+					Action: canaryAction,
 				},
 			},
-			msg: "set headers with multiple hosts",
+			expected: conf_v1.Route{
+				Path:   "/tea",
+				Action: canaryAction,
+			},
+			msg: "full weight",
 		},
 	}
 
 	for _, test := range tests {
-		result := generateProxySetHeaders(test.proxy)
+		result := generateCanaryRoute(test.route)
 		if diff := cmp.Diff(test.expected, result); diff != "" {
-			t.Errorf("generateProxySetHeaders() '%v' mismatch (-want +got):\n%s", test.msg, diff)
+			t.Errorf("generateCanaryRoute() returned unexpected result for the case of %s (-want +got):\n%s", test.msg, diff)
 		}
 	}
 }
 
-func TestGenerateProxyPassRequestHeaders(t *testing.T) {
+func TestApplySplitWeight(t *testing.T) {
 	t.Parallel()
-	passTrue := true
-	passFalse := false
+	stableAction := &conf_v1.Action{
+		Pass: "tea-v1",
+	}
+	shiftedAction := &conf_v1.Action{
+		Proxy: &conf_v1.ActionProxy{
+			Upstream: "tea-v2",
+		},
+	}
+	route := conf_v1.Route{
+		Path: "/tea",
+		Splits: []conf_v1.Split{
+			{
+				Weight: 90,
+				Action: stableAction,
+			},
+			{
+				Weight: 10,
+				Action: shiftedAction,
+			},
+		},
+	}
+
 	tests := []struct {
-		proxy    *conf_v1.ActionProxy
-		expected bool
+		splitWeight SplitWeight
+		expected    conf_v1.Route
+		msg         string
 	}{
 		{
-			proxy:    nil,
-			expected: true,
-		},
-		{
-			proxy:    &conf_v1.ActionProxy{},
-			expected: true,
+			splitWeight: SplitWeight{
+				Upstream: "tea-v2",
+				Weight:   30,
+			},
+			expected: conf_v1.Route{
+				Path: "/tea",
+				Splits: []conf_v1.Split{
+					{
+						Weight: 70,
+						Action: stableAction,
+					},
+					{
+						Weight: 30,
+						Action: shiftedAction,
+					},
+				},
+			},
+			msg: "weight between 0 and 100",
 		},
 		{
-			proxy: &conf_v1.ActionProxy{
-				RequestHeaders: &conf_v1.ProxyRequestHeaders{
-					Pass: nil,
-				},
+			splitWeight: SplitWeight{
+				Upstream: "tea-v2",
+				Weight:   0,
 			},
-			expected: true,
+			expected: conf_v1.Route{
+				Path:   "/tea",
+				Action: stableAction,
+			},
+			msg: "weight 0",
 		},
 		{
-			proxy: &conf_v1.ActionProxy{
-				RequestHeaders: &conf_v1.ProxyRequestHeaders{
-					Pass: &passTrue,
-				},
+			splitWeight: SplitWeight{
+				Upstream: "tea-v2",
+				Weight:   100,
 			},
-			expected: true,
+			expected: conf_v1.Route{
+				Path:   "/tea",
+				Action: shiftedAction,
+			},
+			msg: "weight 100",
 		},
 		{
-			proxy: &conf_v1.ActionProxy{
-				RequestHeaders: &conf_v1.ProxyRequestHeaders{
-					Pass: &passFalse,
-				},
+			splitWeight: SplitWeight{
+				Upstream: "coffee",
+				Weight:   50,
 			},
-			expected: false,
+			expected: route,
+			msg:      "upstream not in the splits",
 		},
 	}
 
 	for _, test := range tests {
-		result := generateProxyPassRequestHeaders(test.proxy)
-		if result != test.expected {
-			t.Errorf("generateProxyPassRequestHeaders(%v) returned %v but expected %v", test.proxy, result, test.expected)
+		result := applySplitWeight(route, test.splitWeight)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("applySplitWeight() mismatch for the case of %s (-want +got):\n%s", test.msg, diff)
 		}
 	}
+
+	if route.Splits[1].Weight != 10 {
+		t.Errorf("applySplitWeight() changed the splits of the original route")
+	}
 }
 
-func TestGenerateProxyHideHeaders(t *testing.T) {
+func TestGenerateMirror(t *testing.T) {
 	t.Parallel()
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+	upstreamNamer := newUpstreamNamerForVirtualServer(&virtualServer)
+	crUpstreams := map[string]conf_v1.Upstream{
+		"vs_default_cafe_coffee-v2": {
+			Service: "coffee-v2-svc",
+			TLS: conf_v1.UpstreamTLS{
+				Enable: true,
+			},
+		},
+		"vs_default_cafe_coffee-v3": {
+			Service: "coffee-v3-svc",
+		},
+	}
+	percentage := 20
+	requestBody := false
+
 	tests := []struct {
-		proxy    *conf_v1.ActionProxy
-		expected []string
+		action   *conf_v1.Action
+		expected *version2.Mirror
+		msg      string
 	}{
 		{
-			proxy:    nil,
+			action:   &conf_v1.Action{Pass: "coffee-v1"},
 			expected: nil,
+			msg:      "pass action",
 		},
 		{
-			proxy: &conf_v1.ActionProxy{
-				ResponseHeaders: nil,
+			action: &conf_v1.Action{
+				Proxy: &conf_v1.ActionProxy{
+					Upstream: "coffee-v1",
+					Mirror: &conf_v1.ActionProxyMirror{
+						Upstream: "coffee-v2",
+					},
+				},
 			},
+			expected: &version2.Mirror{
+				ProxyPass:    "https://vs_default_cafe_coffee-v2",
+				RequestBody:  true,
+				Percentage:   100,
+				ProxySSLName: "coffee-v2-svc.default.svc",
+			},
+			msg: "default mirror",
 		},
 		{
-			proxy: &conf_v1.ActionProxy{
-				ResponseHeaders: &conf_v1.ProxyResponseHeaders{
-					Hide: []string{"Header", "Header-2"},
+			action: &conf_v1.Action{
+				Proxy: &conf_v1.ActionProxy{
+					Upstream: "coffee-v1",
+					Mirror: &conf_v1.ActionProxyMirror{
+						Upstream:    "coffee-v3",
+						Percentage:  &percentage,
+						RequestBody: &requestBody,
+					},
 				},
 			},
-			expected: []string{"Header", "Header-2"},
+			expected: &version2.Mirror{
+				ProxyPass:    "http://vs_default_cafe_coffee-v3",
+				RequestBody:  false,
+				Percentage:   20,
+				ProxySSLName: "coffee-v3-svc.default.svc",
+			},
+			msg: "mirror with percentage and without request body",
 		},
 	}
 
 	for _, test := range tests {
-		result := generateProxyHideHeaders(test.proxy)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateProxyHideHeaders(%v) returned %v but expected %v", test.proxy, result, test.expected)
+		result := generateMirror(test.action, upstreamNamer, crUpstreams)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("generateMirror() returned unexpected result for the case of %s (-want +got):\n%s", test.msg, diff)
 		}
 	}
 }
 
-func TestGenerateProxyPassHeaders(t *testing.T) {
+func TestGenerateMirrorLocations(t *testing.T) {
 	t.Parallel()
-	tests := []struct {
-		proxy    *conf_v1.ActionProxy
-		expected []string
-	}{
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+	variableNamer := newVariableNamer(&virtualServer)
+	egressMTLS := &version2.EgressMTLS{
+		Certificate:    "/etc/nginx/secrets/default-egress-mtls-secret",
+		CertificateKey: "/etc/nginx/secrets/default-egress-mtls-secret",
+		VerifyDepth:    1,
+		Protocols:      "TLSv1 TLSv1.1 TLSv1.2",
+		Ciphers:        "DEFAULT",
+		SSLName:        "$proxy_host",
+	}
+
+	locations := []version2.Location{
 		{
-			proxy:    nil,
-			expected: nil,
+			Path: "/tea",
 		},
 		{
-			proxy: &conf_v1.ActionProxy{
-				ResponseHeaders: nil,
+			Path: "/coffee",
+			Mirror: &version2.Mirror{
+				ProxyPass:    "https://vs_default_cafe_coffee-v2",
+				RequestBody:  true,
+				Percentage:   100,
+				ProxySSLName: "coffee-v2-svc.default.svc",
 			},
+			EgressMTLS: egressMTLS,
 		},
 		{
-			proxy: &conf_v1.ActionProxy{
-				ResponseHeaders: &conf_v1.ProxyResponseHeaders{
-					Pass: []string{"Header", "Header-2"},
+			Path: "/juice",
+			Mirror: &version2.Mirror{
+				ProxyPass:   "http://vs_default_cafe_juice-v2",
+				RequestBody: false,
+				Percentage:  20,
+			},
+		},
+	}
+
+	expectedMirrorLocations := []version2.Mirror{
+		{
+			Path:         "/internal_location_mirror_0",
+			ProxyPass:    "https://vs_default_cafe_coffee-v2",
+			RequestBody:  true,
+			Percentage:   100,
+			ProxySSLName: "coffee-v2-svc.default.svc",
+			EgressMTLS:   egressMTLS,
+		},
+		{
+			Path:           "/internal_location_mirror_1",
+			ProxyPass:      "http://vs_default_cafe_juice-v2",
+			RequestBody:    false,
+			Percentage:     20,
+			SampleVariable: "$vs_default_cafe_mirror_1",
+		},
+	}
+	expectedSplitClients := []version2.SplitClient{
+		{
+			Source:   "$request_id",
+			Variable: "$vs_default_cafe_mirror_1",
+			Distributions: []version2.Distribution{
+				{
+					Weight: "20%",
+					Value:  "1",
+				},
+				{
+					Weight: "*",
+					Value:  `""`,
 				},
 			},
-			expected: []string{"Header", "Header-2"},
 		},
 	}
 
-	for _, test := range tests {
-		result := generateProxyPassHeaders(test.proxy)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateProxyPassHeaders(%v) returned %v but expected %v", test.proxy, result, test.expected)
-		}
+	mirrorLocations, splitClients := generateMirrorLocations(locations, variableNamer)
+	if diff := cmp.Diff(expectedMirrorLocations, mirrorLocations); diff != "" {
+		t.Errorf("generateMirrorLocations() returned unexpected mirror locations (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedSplitClients, splitClients); diff != "" {
+		t.Errorf("generateMirrorLocations() returned unexpected split clients (-want +got):\n%s", diff)
+	}
+	if locations[2].Mirror.Path != "/internal_location_mirror_1" {
+		t.Errorf("generateMirrorLocations() didn't set the path of the mirror of the location")
 	}
 }
 
-func TestGenerateProxyIgnoreHeaders(t *testing.T) {
+func TestGenerateExternalAuthLocations(t *testing.T) {
 	t.Parallel()
-	tests := []struct {
-		proxy    *conf_v1.ActionProxy
-		expected string
-	}{
+	extAuth := &version2.ExternalAuth{
+		Path:      "/internal_location_ext_auth_default_ext-auth-policy",
+		ProxyPass: "http://vs_default_cafe_ext_auth_default_ext-auth-policy/",
+		Cache: &version2.ExternalAuthCache{
+			ZoneName: "pol_ext_auth_default_ext-auth-policy_default_cafe",
+			Key:      "${http_authorization}",
+			Valid:    "1m",
+		},
+	}
+	extAuth2 := &version2.ExternalAuth{
+		Path:      "/internal_location_ext_auth_tea_ext-auth-policy",
+		ProxyPass: "http://vs_default_cafe_ext_auth_tea_ext-auth-policy/",
+	}
+
+	locations := []version2.Location{
 		{
-			proxy:    nil,
-			expected: "",
+			Path:         "/coffee",
+			ExternalAuth: extAuth,
+		},
+		{
+			Path: "/juice",
+		},
+		{
+			Path:         "/latte",
+			ExternalAuth: extAuth,
+		},
+		{
+			Path:         "/tea",
+			ExternalAuth: extAuth2,
+		},
+	}
+
+	expectedLocations := []version2.ExternalAuth{*extAuth, *extAuth2}
+	expectedCaches := []version2.ExternalAuthCache{*extAuth.Cache}
+
+	extAuthLocations, caches := generateExternalAuthLocations(locations)
+	if diff := cmp.Diff(expectedLocations, extAuthLocations); diff != "" {
+		t.Errorf("generateExternalAuthLocations() returned unexpected locations (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedCaches, caches); diff != "" {
+		t.Errorf("generateExternalAuthLocations() returned unexpected caches (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateJwksURILocations(t *testing.T) {
+	t.Parallel()
+	serverJWTAuth := &version2.JWTAuth{
+		Realm: "My API",
+		JwksURI: &version2.JwksURI{
+			Path:     "/_jwks_uri_default_jwt-policy",
+			URI:      "https://idp.example.com/keys",
+			KeyCache: "12h",
+			ZoneName: "jwks_uri_default_jwt-policy_default_cafe",
+		},
+	}
+	routeJWTAuth := &version2.JWTAuth{
+		Realm: "My Tea API",
+		JwksURI: &version2.JwksURI{
+			Path:     "/_jwks_uri_tea_jwt-policy",
+			URI:      "https://idp.example.com/tea/keys",
+			KeyCache: "1h",
+			ZoneName: "jwks_uri_tea_jwt-policy_default_cafe",
+		},
+	}
+
+	locations := []version2.Location{
+		{
+			Path:    "/coffee",
+			JWTAuth: serverJWTAuth,
+		},
+		{
+			Path: "/juice",
+			JWTAuth: &version2.JWTAuth{
+				Realm:  "My Juice API",
+				Secret: "/etc/nginx/secrets/default-jwk-secret",
+			},
+		},
+		{
+			Path:    "/tea",
+			JWTAuth: routeJWTAuth,
+		},
+		{
+			Path:    "/latte",
+			JWTAuth: routeJWTAuth,
+		},
+	}
+
+	expected := []version2.JwksURI{*serverJWTAuth.JwksURI, *routeJWTAuth.JwksURI}
+
+	result := generateJwksURILocations(serverJWTAuth, locations)
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("generateJwksURILocations() returned unexpected result (-want +got):\n%s", diff)
+	}
+
+	result = generateJwksURILocations(nil, nil)
+	if len(result) != 0 {
+		t.Errorf("generateJwksURILocations() returned %v for no JWT policies, expected no locations", result)
+	}
+}
+
+func TestGenerateExternalAuthUpstreams(t *testing.T) {
+	t.Parallel()
+	vsEx := &VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+		},
+		Endpoints: map[string][]string{
+			"tea/auth-svc:8080": {"10.0.0.20:8080"},
+		},
+		Policies: map[string]*conf_v1.Policy{
+			"tea/ext-auth-policy": {
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "ext-auth-policy",
+					Namespace: "tea",
+				},
+				Spec: conf_v1.PolicySpec{
+					ExternalAuth: &conf_v1.ExternalAuth{
+						AuthService:     "auth-svc",
+						AuthServicePort: 8080,
+					},
+				},
+			},
+			"default/allow-policy": {
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "allow-policy",
+					Namespace: "default",
+				},
+				Spec: conf_v1.PolicySpec{
+					AccessControl: &conf_v1.AccessControl{
+						Allow: []string{"127.0.0.1"},
+					},
+				},
+			},
+		},
+	}
+
+	expected := []version2.Upstream{
+		{
+			Name: "vs_default_cafe_ext_auth_tea_ext-auth-policy",
+			UpstreamLabels: version2.UpstreamLabels{
+				Service:           "auth-svc",
+				ResourceType:      "virtualserver",
+				ResourceName:      "cafe",
+				ResourceNamespace: "default",
+			},
+			Servers: []version2.UpstreamServer{
+				{
+					Address: "10.0.0.20:8080",
+				},
+			},
 		},
+	}
+
+	vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{}, false)
+
+	result := vsc.generateExternalAuthUpstreams(vsEx)
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("generateExternalAuthUpstreams() returned unexpected result (-want +got):\n%s", diff)
+	}
+}
+
+func TestAddRetryToLocation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		retry    *conf_v1.Retry
+		expected version2.Location
+		msg      string
+	}{
 		{
-			proxy: &conf_v1.ActionProxy{
-				ResponseHeaders: nil,
+			retry: &conf_v1.Retry{},
+			expected: version2.Location{
+				ProxyNextUpstream:        "error timeout",
+				ProxyNextUpstreamTimeout: "0s",
+				ProxyNextUpstreamTries:   0,
+				ProxyReadTimeout:         "60s",
+				ProxySendTimeout:         "60s",
 			},
-			expected: "",
+			msg: "empty retry",
 		},
 		{
-			proxy: &conf_v1.ActionProxy{
-				ResponseHeaders: &conf_v1.ProxyResponseHeaders{
-					Ignore: []string{"Header", "Header-2"},
-				},
+			retry: &conf_v1.Retry{
+				Conditions:    []string{"error", "http_503"},
+				NonIdempotent: true,
+				MaxAttempts:   createPointerFromInt(3),
+				Timeout:       "10s",
+				PerTryTimeout: "2s",
 			},
-			expected: "Header Header-2",
+			expected: version2.Location{
+				ProxyNextUpstream:        "error http_503 non_idempotent",
+				ProxyNextUpstreamTimeout: "10s",
+				ProxyNextUpstreamTries:   3,
+				ProxyReadTimeout:         "2s",
+				ProxySendTimeout:         "2s",
+			},
+			msg: "all fields",
 		},
 	}
 
 	for _, test := range tests {
-		result := generateProxyIgnoreHeaders(test.proxy)
-		if result != test.expected {
-			t.Errorf("generateProxyIgnoreHeaders(%v) returned %v but expected %v", test.proxy, result, test.expected)
+		location := version2.Location{
+			ProxyNextUpstream:        "timeout",
+			ProxyNextUpstreamTimeout: "0s",
+			ProxyNextUpstreamTries:   0,
+			ProxyReadTimeout:         "60s",
+			ProxySendTimeout:         "60s",
+		}
+
+		addRetryToLocation(test.retry, &location)
+
+		if diff := cmp.Diff(test.expected, location); diff != "" {
+			t.Errorf("addRetryToLocation() '%v' mismatch (-want +got):\n%s", test.msg, diff)
 		}
 	}
 }
 
-func TestGenerateProxyAddHeaders(t *testing.T) {
+func TestCreateUpstreamsForPlusWithCircuitBreakers(t *testing.T) {
 	t.Parallel()
-	tests := []struct {
-		proxy    *conf_v1.ActionProxy
-		expected []version2.AddHeader
-	}{
-		{
-			proxy:    nil,
-			expected: nil,
-		},
-		{
-			proxy:    &conf_v1.ActionProxy{},
-			expected: nil,
+	cb := &conf_v1.CircuitBreaker{
+		MaxFails:    createPointerFromInt(5),
+		FailTimeout: "1m",
+		SlowStart:   "30s",
+	}
+	vsrCB := &conf_v1.CircuitBreaker{
+		MaxFails: createPointerFromInt(2),
+	}
+
+	virtualServerEx := VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:    "tea",
+						Service: "tea-svc",
+						Port:    80,
+					},
+					{
+						Name:    "coffee",
+						Service: "coffee-svc",
+						Port:    80,
+					},
+				},
+				Routes: []conf_v1.Route{
+					{
+						Path: "/tea",
+						Policies: []conf_v1.PolicyReference{
+							{
+								Name: "resilience-policy",
+							},
+						},
+						Action: &conf_v1.Action{
+							Pass: "tea",
+						},
+					},
+					{
+						Path: "/coffee",
+						Action: &conf_v1.Action{
+							Pass: "coffee",
+						},
+					},
+					{
+						Path:  "/juice",
+						Route: "juice",
+						Policies: []conf_v1.PolicyReference{
+							{
+								Name: "vsr-resilience-policy",
+							},
+						},
+					},
+				},
+			},
 		},
-		{
-			proxy: &conf_v1.ActionProxy{
-				ResponseHeaders: &conf_v1.ProxyResponseHeaders{
-					Add: []conf_v1.AddHeader{
+		VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
+			{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "juice",
+					Namespace: "default",
+				},
+				Spec: conf_v1.VirtualServerRouteSpec{
+					Host: "cafe.example.com",
+					Upstreams: []conf_v1.Upstream{
 						{
-							Header: conf_v1.Header{
-								Name:  "Header-Name",
-								Value: "HeaderValue",
-							},
-							Always: true,
+							Name:    "orange",
+							Service: "orange-svc",
+							Port:    80,
 						},
+					},
+					Subroutes: []conf_v1.Route{
 						{
-							Header: conf_v1.Header{
-								Name:  "Server",
-								Value: "myServer",
+							Path: "/juice/orange",
+							Action: &conf_v1.Action{
+								Pass: "orange",
 							},
-							Always: false,
 						},
 					},
 				},
 			},
-			expected: []version2.AddHeader{
-				{
-					Header: version2.Header{
-						Name:  "Header-Name",
-						Value: "HeaderValue",
+		},
+		Policies: map[string]*conf_v1.Policy{
+			"default/resilience-policy": {
+				Spec: conf_v1.PolicySpec{
+					Resilience: &conf_v1.Resilience{
+						CircuitBreaker: cb,
 					},
-					Always: true,
 				},
-				{
-					Header: version2.Header{
-						Name:  "Server",
-						Value: "myServer",
+			},
+			"default/vsr-resilience-policy": {
+				Spec: conf_v1.PolicySpec{
+					Resilience: &conf_v1.Resilience{
+						CircuitBreaker: vsrCB,
 					},
-					Always: false,
 				},
 			},
 		},
+		Endpoints: map[string][]string{
+			"default/tea-svc:80":    {"10.0.0.20:80"},
+			"default/coffee-svc:80": {"10.0.0.30:80"},
+			"default/orange-svc:80": {"10.0.0.40:80"},
+		},
 	}
 
-	for _, test := range tests {
-		result := generateProxyAddHeaders(test.proxy)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateProxyAddHeaders(%v) returned %v but expected %v", test.proxy, result, test.expected)
-		}
-	}
-}
-
-func TestGetUpstreamResourceLabels(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		owner    runtime.Object
-		expected version2.UpstreamLabels
-	}{
-		{
-			owner:    nil,
-			expected: version2.UpstreamLabels{},
-		},
-		{
-			owner: &conf_v1.VirtualServer{
-				ObjectMeta: meta_v1.ObjectMeta{
-					Namespace: "namespace",
-					Name:      "name",
-				},
-			},
-			expected: version2.UpstreamLabels{
-				ResourceNamespace: "namespace",
-				ResourceName:      "name",
-				ResourceType:      "virtualserver",
-			},
+	expected := map[string]nginx.ServerConfig{
+		"vs_default_cafe_tea": {
+			MaxFails:    5,
+			FailTimeout: "1m",
+			SlowStart:   "30s",
 		},
-		{
-			owner: &conf_v1.VirtualServerRoute{
-				ObjectMeta: meta_v1.ObjectMeta{
-					Namespace: "namespace",
-					Name:      "name",
-				},
-			},
-			expected: version2.UpstreamLabels{
-				ResourceNamespace: "namespace",
-				ResourceName:      "name",
-				ResourceType:      "virtualserverroute",
-			},
+		"vs_default_cafe_coffee": {},
+		"vs_default_cafe_vsr_default_juice_orange": {
+			MaxFails: 2,
 		},
 	}
-	for _, test := range tests {
-		result := getUpstreamResourceLabels(test.owner)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("getUpstreamResourceLabels(%+v) returned %+v but expected %+v", test.owner, result, test.expected)
-		}
+
+	vsc := newVirtualServerConfigurator(&ConfigParams{}, true, false, &StaticConfigParams{}, false)
+	vsCfg, _ := vsc.GenerateVirtualServerConfig(&virtualServerEx, nil, nil)
+
+	generated := make(map[string]nginx.ServerConfig)
+	for _, u := range vsCfg.Upstreams {
+		generated[u.Name] = createUpstreamServersConfigForPlus(u)
+	}
+	if diff := cmp.Diff(expected, generated); diff != "" {
+		t.Errorf("GenerateVirtualServerConfig() mismatch of the server configs of the upstreams (-want +got):\n%s", diff)
+	}
+
+	result := make(map[string]nginx.ServerConfig)
+	for _, u := range createUpstreamsForPlus(&virtualServerEx, vsc.circuitBreakers, &ConfigParams{}, &StaticConfigParams{}) {
+		result[u.Name] = createUpstreamServersConfigForPlus(u)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("createUpstreamsForPlus() mismatch of the server configs of the upstreams (-want +got):\n%s", diff)
 	}
 }
 
-func TestAddWafConfig(t *testing.T) {
+func TestGenerateVirtualServerConfigIgnoresCircuitBreakersOfRoutesWithInvalidPolicies(t *testing.T) {
 	t.Parallel()
-	tests := []struct {
-		wafInput     *conf_v1.WAF
-		polKey       string
-		polNamespace string
-		apResources  *appProtectResourcesForVS
-		wafConfig    *version2.WAF
-		expected     *validationResults
-		msg          string
-	}{
-		{
-			wafInput: &conf_v1.WAF{
-				Enable: true,
-			},
-			polKey:       "default/waf-policy",
-			polNamespace: "default",
-			apResources: &appProtectResourcesForVS{
-				Policies: map[string]string{},
-				LogConfs: map[string]string{},
-			},
-			wafConfig: &version2.WAF{
-				Enable: "on",
-			},
-			expected: &validationResults{isError: false},
-			msg:      "valid waf config, default App Protect config",
-		},
-		{
-			wafInput: &conf_v1.WAF{
-				Enable:   true,
-				ApPolicy: "dataguard-alarm",
-				SecurityLog: &conf_v1.SecurityLog{
-					Enable:    true,
-					ApLogConf: "logconf",
-					LogDest:   "syslog:server=127.0.0.1:514",
-				},
+	virtualServerEx := VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
 			},
-			polKey:       "default/waf-policy",
-			polNamespace: "default",
-			apResources: &appProtectResourcesForVS{
-				Policies: map[string]string{
-					"default/dataguard-alarm": "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				Policies: []conf_v1.PolicyReference{
+					{
+						Name: "resilience-policy",
+					},
 				},
-				LogConfs: map[string]string{
-					"default/logconf": "/etc/nginx/waf/nac-logconfs/default-logconf",
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:    "tea",
+						Service: "tea-svc",
+						Port:    80,
+					},
 				},
-			},
-			wafConfig: &version2.WAF{
-				ApPolicy:            "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
-				ApSecurityLogEnable: true,
-				ApLogConf:           []string{"/etc/nginx/waf/nac-logconfs/default-logconf"},
-			},
-			expected: &validationResults{isError: false},
-			msg:      "valid waf config",
-		},
-		{
-			wafInput: &conf_v1.WAF{
-				Enable:   true,
-				ApPolicy: "dataguard-alarm",
-				SecurityLogs: []*conf_v1.SecurityLog{
+				Routes: []conf_v1.Route{
 					{
-						Enable:    true,
-						ApLogConf: "logconf",
-						LogDest:   "syslog:server=127.0.0.1:514",
+						Path: "/tea",
+						Policies: []conf_v1.PolicyReference{
+							{
+								Name: "missing-policy",
+							},
+						},
+						Action: &conf_v1.Action{
+							Pass: "tea",
+						},
 					},
 				},
 			},
-			polKey:       "default/waf-policy",
-			polNamespace: "default",
-			apResources: &appProtectResourcesForVS{
-				Policies: map[string]string{
-					"default/dataguard-alarm": "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
-				},
-				LogConfs: map[string]string{
-					"default/logconf": "/etc/nginx/waf/nac-logconfs/default-logconf",
+		},
+		Policies: map[string]*conf_v1.Policy{
+			"default/resilience-policy": {
+				Spec: conf_v1.PolicySpec{
+					Resilience: &conf_v1.Resilience{
+						CircuitBreaker: &conf_v1.CircuitBreaker{
+							MaxFails: createPointerFromInt(5),
+						},
+					},
 				},
 			},
-			wafConfig: &version2.WAF{
-				ApPolicy:            "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
-				ApSecurityLogEnable: true,
-				ApLogConf:           []string{"/etc/nginx/waf/nac-logconfs/default-logconf"},
-			},
-			expected: &validationResults{isError: false},
-			msg:      "valid waf config",
 		},
+		Endpoints: map[string][]string{
+			"default/tea-svc:80": {"10.0.0.20:80"},
+		},
+	}
+
+	vsc := newVirtualServerConfigurator(&ConfigParams{}, true, false, &StaticConfigParams{}, false)
+	vsc.GenerateVirtualServerConfig(&virtualServerEx, nil, nil)
+
+	if len(vsc.circuitBreakers) != 0 {
+		t.Errorf("GenerateVirtualServerConfig() recorded circuit breakers %v for a route with a missing policy", vsc.circuitBreakers)
+	}
+}
+
+func TestAddCircuitBreakers(t *testing.T) {
+	t.Parallel()
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+	upstreamNamer := newUpstreamNamerForVirtualServer(&virtualServer)
+
+	cb := &conf_v1.CircuitBreaker{
+		MaxFails:    createPointerFromInt(5),
+		FailTimeout: "1m",
+		SlowStart:   "30s",
+	}
+	otherCB := &conf_v1.CircuitBreaker{
+		MaxFails: createPointerFromInt(1),
+	}
+
+	routes := []conf_v1.Route{
 		{
-			wafInput: &conf_v1.WAF{
-				Enable:   true,
-				ApPolicy: "default/dataguard-alarm",
-				SecurityLog: &conf_v1.SecurityLog{
-					Enable:    true,
-					ApLogConf: "default/logconf",
-					LogDest:   "syslog:server=127.0.0.1:514",
-				},
-			},
-			polKey:       "default/waf-policy",
-			polNamespace: "",
-			apResources: &appProtectResourcesForVS{
-				Policies: map[string]string{
-					"default/dataguard-alarm": "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
+			Path: "/tea",
+			Splits: []conf_v1.Split{
+				{
+					Weight: 90,
+					Action: &conf_v1.Action{
+						Pass: "tea-v1",
+					},
 				},
-				LogConfs: map[string]string{},
-			},
-			wafConfig: &version2.WAF{
-				ApPolicy:            "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
-				ApSecurityLogEnable: true,
-				ApLogConf:           []string{"/etc/nginx/waf/nac-logconfs/default-logconf"},
-			},
-			expected: &validationResults{
-				isError: true,
-				warnings: []string{
-					`WAF policy default/waf-policy references an invalid or non-existing log config default/logconf`,
+				{
+					Weight: 10,
+					Action: &conf_v1.Action{
+						Proxy: &conf_v1.ActionProxy{
+							Upstream: "tea-v2",
+						},
+					},
 				},
 			},
-			msg: "invalid waf config, apLogConf references non-existing log conf",
 		},
 		{
-			wafInput: &conf_v1.WAF{
-				Enable:   true,
-				ApPolicy: "default/dataguard-alarm",
-				SecurityLog: &conf_v1.SecurityLog{
-					Enable:  true,
-					LogDest: "syslog:server=127.0.0.1:514",
-				},
-			},
-			polKey:       "default/waf-policy",
-			polNamespace: "",
-			apResources: &appProtectResourcesForVS{
-				Policies: map[string]string{},
-				LogConfs: map[string]string{
-					"default/logconf": "/etc/nginx/waf/nac-logconfs/default-logconf",
+			Path: "/coffee",
+			Matches: []conf_v1.Match{
+				{
+					Action: &conf_v1.Action{
+						Pass: "tea-v1",
+					},
 				},
 			},
-			wafConfig: &version2.WAF{
-				ApPolicy:            "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
-				ApSecurityLogEnable: true,
-				ApLogConf:           []string{"/etc/nginx/waf/nac-logconfs/default-logconf"},
-			},
-			expected: &validationResults{
-				isError: true,
-				warnings: []string{
-					`WAF policy default/waf-policy references an invalid or non-existing App Protect policy default/dataguard-alarm`,
+			Action: &conf_v1.Action{
+				Return: &conf_v1.ActionReturn{
+					Body: "coffee",
 				},
 			},
-			msg: "invalid waf config, apLogConf references non-existing ap conf",
 		},
+	}
+
+	vsc := newVirtualServerConfigurator(&ConfigParams{}, true, false, &StaticConfigParams{}, false)
+	circuitBreakers := make(map[string]*conf_v1.CircuitBreaker)
+
+	vsc.addCircuitBreaker(&virtualServer, circuitBreakers, &conf_v1.Resilience{CircuitBreaker: cb}, routes[0], upstreamNamer)
+	vsc.addCircuitBreaker(&virtualServer, circuitBreakers, &conf_v1.Resilience{CircuitBreaker: otherCB}, routes[1], upstreamNamer)
+
+	expectedCircuitBreakers := map[string]*conf_v1.CircuitBreaker{
+		"vs_default_cafe_tea-v1": cb,
+		"vs_default_cafe_tea-v2": cb,
+	}
+	if diff := cmp.Diff(expectedCircuitBreakers, circuitBreakers); diff != "" {
+		t.Errorf("addCircuitBreaker() mismatch (-want +got):\n%s", diff)
+	}
+
+	expectedWarnings := Warnings{
+		&virtualServer: {
+			"The circuit breaker of the resilience policy of route /coffee is ignored for upstream vs_default_cafe_tea-v1, " +
+				"because the upstream already uses the circuit breaker of another route",
+		},
+	}
+	if diff := cmp.Diff(expectedWarnings, vsc.warnings); diff != "" {
+		t.Errorf("addCircuitBreaker() returned unexpected warnings (-want +got):\n%s", diff)
+	}
+
+	upstreams := []version2.Upstream{
 		{
-			wafInput: &conf_v1.WAF{
-				Enable:   true,
-				ApPolicy: "ns1/dataguard-alarm",
-				SecurityLog: &conf_v1.SecurityLog{
-					Enable:    true,
-					ApLogConf: "ns2/logconf",
-					LogDest:   "syslog:server=127.0.0.1:514",
-				},
-			},
-			polKey:       "default/waf-policy",
-			polNamespace: "",
-			apResources: &appProtectResourcesForVS{
-				Policies: map[string]string{
-					"ns1/dataguard-alarm": "/etc/nginx/waf/nac-policies/ns1-dataguard-alarm",
-				},
-				LogConfs: map[string]string{
-					"ns2/logconf": "/etc/nginx/waf/nac-logconfs/ns2-logconf",
-				},
+			Name:        "vs_default_cafe_tea-v1",
+			LBMethod:    "least_conn",
+			MaxFails:    1,
+			FailTimeout: "10s",
+		},
+		{
+			Name:        "vs_default_cafe_coffee",
+			LBMethod:    "least_conn",
+			MaxFails:    1,
+			FailTimeout: "10s",
+		},
+	}
+	expectedUpstreams := []version2.Upstream{
+		{
+			Name:        "vs_default_cafe_tea-v1",
+			LBMethod:    "least_conn",
+			MaxFails:    5,
+			FailTimeout: "1m",
+			SlowStart:   "30s",
+		},
+		{
+			Name:        "vs_default_cafe_coffee",
+			LBMethod:    "least_conn",
+			MaxFails:    1,
+			FailTimeout: "10s",
+		},
+	}
+
+	vsc.applyCircuitBreakers(&virtualServer, upstreams, circuitBreakers)
+
+	if diff := cmp.Diff(expectedUpstreams, upstreams); diff != "" {
+		t.Errorf("applyCircuitBreakers() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAddHeadersToLocation(t *testing.T) {
+	t.Parallel()
+	headers := &conf_v1.Headers{
+		Request: &conf_v1.RequestHeadersTransform{
+			Set: []conf_v1.Header{
+				{Name: "X-Env", Value: "prod"},
+				{Name: "X-Version", Value: "2"},
 			},
-			wafConfig: &version2.WAF{
-				ApPolicy:            "/etc/nginx/waf/nac-policies/ns1-dataguard-alarm",
-				ApSecurityLogEnable: true,
-				ApLogConf:           []string{"/etc/nginx/waf/nac-logconfs/ns2-logconf"},
+			Remove: []string{"X-Debug"},
+			Rename: []conf_v1.HeaderRename{
+				{From: "X-User", To: "X-User-ID"},
 			},
-			expected: &validationResults{},
-			msg:      "valid waf config, cross ns reference",
 		},
-		{
-			wafInput: &conf_v1.WAF{
-				Enable:   false,
-				ApPolicy: "dataguard-alarm",
+		Response: &conf_v1.ResponseHeadersTransform{
+			Add: []conf_v1.AddHeader{
+				{Header: conf_v1.Header{Name: "X-Served-By", Value: "nginx"}},
 			},
-			polKey:       "default/waf-policy",
-			polNamespace: "default",
-			apResources: &appProtectResourcesForVS{
-				Policies: map[string]string{
-					"default/dataguard-alarm": "/etc/nginx/waf/nac-policies/ns1-dataguard-alarm",
-				},
-				LogConfs: map[string]string{
-					"default/logconf": "/etc/nginx/waf/nac-logconfs/ns2-logconf",
-				},
+			Remove: []string{"Server"},
+			Rename: []conf_v1.HeaderRename{
+				{From: "X-Internal-ID", To: "X-Request-ID"},
 			},
-			wafConfig: &version2.WAF{
-				Enable:   "off",
-				ApPolicy: "/etc/nginx/waf/nac-policies/ns1-dataguard-alarm",
+			RewriteLocation: []conf_v1.HeaderRewrite{
+				{From: "http://tea.internal/", To: "https://cafe.example.com/"},
+			},
+			RewriteCookieDomain: []conf_v1.HeaderRewrite{
+				{From: "tea.internal", To: "cafe.example.com"},
 			},
-			expected: &validationResults{},
-			msg:      "valid waf config, disable waf",
+		},
+		SecurityHeaders: &conf_v1.SecurityHeaders{
+			FrameOptions:   "DENY",
+			ReferrerPolicy: "no-referrer",
 		},
 	}
 
-	for _, test := range tests {
-		polCfg := newPoliciesConfig()
-		result := polCfg.addWAFConfig(test.wafInput, test.polKey, test.polNamespace, test.apResources)
-		if diff := cmp.Diff(test.expected.warnings, result.warnings); diff != "" {
-			t.Errorf("policiesCfg.addWAFConfig() '%v' mismatch (-want +got):\n%s", test.msg, diff)
-		}
+	actionHideHeaders := []string{"X-Powered-By"}
+	location := version2.Location{
+		ProxySetHeaders: []version2.Header{
+			{Name: "x-version", Value: "1"},
+			{Name: "Host", Value: "$host"},
+		},
+		ProxyHideHeaders: actionHideHeaders,
+		AddHeaders: []version2.AddHeader{
+			{Header: version2.Header{Name: "X-Frame-Options", Value: "SAMEORIGIN"}},
+		},
 	}
-}
 
-func TestGenerateTime(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		value, expected string
-	}{
-		{
-			value:    "0s",
-			expected: "0s",
+	expected := version2.Location{
+		ProxySetHeaders: []version2.Header{
+			{Name: "x-version", Value: "1"},
+			{Name: "Host", Value: "$host"},
+			{Name: "X-Env", Value: "prod"},
+			{Name: "X-Debug", Value: ""},
+			{Name: "X-User-ID", Value: "$http_x_user"},
+			{Name: "X-User", Value: ""},
 		},
-		{
-			value:    "0",
-			expected: "0s",
+		ProxyHideHeaders: []string{"X-Powered-By", "Server", "X-Internal-ID"},
+		AddHeaders: []version2.AddHeader{
+			{Header: version2.Header{Name: "X-Frame-Options", Value: "SAMEORIGIN"}},
+			{Header: version2.Header{Name: "X-Served-By", Value: "nginx"}},
+			{Header: version2.Header{Name: "X-Request-ID", Value: "$upstream_http_x_internal_id"}, Always: true},
+			{Header: version2.Header{Name: "Referrer-Policy", Value: "no-referrer"}, Always: true},
 		},
-		{
-			value:    "1h",
-			expected: "1h",
+		ProxyRedirects: []version2.HeaderRewrite{
+			{From: "http://tea.internal/", To: "https://cafe.example.com/"},
 		},
-		{
-			value:    "1h 30m",
-			expected: "1h30m",
+		ProxyCookieDomains: []version2.HeaderRewrite{
+			{From: "tea.internal", To: "cafe.example.com"},
 		},
 	}
 
-	for _, test := range tests {
-		result := generateTime(test.value)
-		if result != test.expected {
-			t.Errorf("generateTime(%q) returned %q but expected %q", test.value, result, test.expected)
-		}
+	addHeadersToLocation(headers, &location)
+
+	if diff := cmp.Diff(expected, location); diff != "" {
+		t.Errorf("addHeadersToLocation() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"X-Powered-By"}, actionHideHeaders); diff != "" {
+		t.Errorf("addHeadersToLocation() modified the hidden headers of the action (-want +got):\n%s", diff)
 	}
 }
 
-func TestGenerateTimeWithDefault(t *testing.T) {
+func TestCheckHeadersPolicy(t *testing.T) {
 	t.Parallel()
-	tests := []struct {
-		value, defaultValue, expected string
-	}{
-		{
-			value:        "1h 30m",
-			defaultValue: "",
-			expected:     "1h30m",
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
 		},
-		{
-			value:        "",
-			defaultValue: "60s",
-			expected:     "60s",
+	}
+
+	headers := &conf_v1.Headers{
+		Request: &conf_v1.RequestHeadersTransform{
+			Set: []conf_v1.Header{
+				{Name: "X-Env", Value: "prod"},
+				{Name: "X-Version", Value: "2"},
+			},
 		},
-		{
-			value:        "",
-			defaultValue: "test",
-			expected:     "test",
+		SecurityHeaders: &conf_v1.SecurityHeaders{
+			FrameOptions: "DENY",
 		},
 	}
 
-	for _, test := range tests {
-		result := generateTimeWithDefault(test.value, test.defaultValue)
-		if result != test.expected {
-			t.Errorf("generateTimeWithDefault(%q, %q) returned %q but expected %q", test.value, test.defaultValue, result, test.expected)
-		}
+	route := conf_v1.Route{
+		Path: "/tea",
+		Splits: []conf_v1.Split{
+			{
+				Weight: 90,
+				Action: &conf_v1.Action{
+					Pass: "tea-v1",
+				},
+			},
+			{
+				Weight: 10,
+				Action: &conf_v1.Action{
+					Proxy: &conf_v1.ActionProxy{
+						Upstream: "tea-v2",
+						RequestHeaders: &conf_v1.ProxyRequestHeaders{
+							Set: []conf_v1.Header{
+								{Name: "x-version", Value: "1"},
+							},
+						},
+						ResponseHeaders: &conf_v1.ProxyResponseHeaders{
+							Add: []conf_v1.AddHeader{
+								{Header: conf_v1.Header{Name: "X-Frame-Options", Value: "SAMEORIGIN"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{}, false)
+	vsc.checkHeadersPolicy(&virtualServer, headers, route)
+	vsc.checkHeadersPolicy(&virtualServer, nil, route)
+
+	expectedWarnings := Warnings{
+		&virtualServer: {
+			"The request header X-Version of the headers policy of route /tea is ignored, because the action of the route sets the header",
+			"The response header X-Frame-Options of the headers policy of route /tea is ignored, because the action of the route adds the header",
+		},
+	}
+	if diff := cmp.Diff(expectedWarnings, vsc.warnings); diff != "" {
+		t.Errorf("checkHeadersPolicy() returned unexpected warnings (-want +got):\n%s", diff)
 	}
 }
@@ -0,0 +1,87 @@
+package k8s
+
+import (
+	"sync"
+)
+
+// changeSequencer lets multiple workers sync the resources concurrently, while the changes of the configuration
+// of NGINX are applied one at a time and in the order the workers made the changes of the Configuration.
+// A worker makes a change of the Configuration via change and gets a turn for it. Then, the worker creates
+// the extended resources of the change concurrently with other workers, waits for its turn and applies the change.
+// This way, a change based on an older version of a resource never overwrites a change based on a newer one.
+type changeSequencer struct {
+	// changeLock makes the turns be given in the order of the changes.
+	changeLock sync.Mutex
+
+	// lock protects the fields below
+	lock     sync.Mutex
+	turnDone *sync.Cond
+	next     uint64
+	current  uint64
+}
+
+// changeTurn is the turn of a worker to apply a change.
+type changeTurn struct {
+	sequencer *changeSequencer
+	number    uint64
+}
+
+func newChangeSequencer() *changeSequencer {
+	s := &changeSequencer{}
+	s.turnDone = sync.NewCond(&s.lock)
+	return s
+}
+
+// change makes the change, for example, to the Configuration, and returns the turn to apply it.
+// The turn must be ended via done, even if there is nothing to apply. Otherwise, the following turns never come.
+func (s *changeSequencer) change(makeChange func()) *changeTurn {
+	s.changeLock.Lock()
+	defer s.changeLock.Unlock()
+
+	makeChange()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	turn := &changeTurn{
+		sequencer: s,
+		number:    s.next,
+	}
+	s.next++
+
+	return turn
+}
+
+// inTurn runs fn once the changes made so far are applied. fn runs one at a time with the changes applied by other workers.
+func (s *changeSequencer) inTurn(fn func()) {
+	turn := s.change(func() {})
+	defer turn.done()
+
+	turn.wait()
+	fn()
+}
+
+// wait waits until the changes with the previous turns are applied.
+func (t *changeTurn) wait() {
+	s := t.sequencer
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for s.current != t.number {
+		s.turnDone.Wait()
+	}
+}
+
+// done ends the turn, so that the change with the next turn can be applied. It waits for the turn first, if needed.
+func (t *changeTurn) done() {
+	t.wait()
+
+	s := t.sequencer
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.current++
+	s.turnDone.Broadcast()
+}
@@ -0,0 +1,81 @@
+package k8s
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChangeSequencerAppliesChangesInOrder(t *testing.T) {
+	t.Parallel()
+
+	sequencer := newChangeSequencer()
+
+	var lock sync.Mutex
+	var changed []int
+	var applied []int
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			turn := sequencer.change(func() {
+				changed = append(changed, i)
+			})
+			defer turn.done()
+
+			// the workers that made the changes later finish their work earlier
+			time.Sleep(time.Duration(10-i) * time.Millisecond)
+
+			turn.wait()
+
+			lock.Lock()
+			applied = append(applied, i)
+			lock.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	if diff := cmp.Diff(changed, applied); diff != "" {
+		t.Errorf("changeSequencer applied the changes out of order (-want +got):\n%s", diff)
+	}
+}
+
+func TestChangeSequencerDoneWithoutWait(t *testing.T) {
+	t.Parallel()
+
+	sequencer := newChangeSequencer()
+
+	first := sequencer.change(func() {})
+	second := sequencer.change(func() {})
+
+	doneCh := make(chan struct{})
+	go func() {
+		second.done()
+		close(doneCh)
+	}()
+
+	select {
+	case <-doneCh:
+		t.Fatal("changeSequencer ended the turn before the previous turn was done")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	first.done()
+
+	select {
+	case <-doneCh:
+	case <-time.After(time.Second):
+		t.Fatal("changeSequencer didn't end the turn after the previous turn was done")
+	}
+
+	ran := false
+	sequencer.inTurn(func() { ran = true })
+	if !ran {
+		t.Error("changeSequencer didn't run the function in turn")
+	}
+}
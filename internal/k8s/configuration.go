@@ -13,6 +13,7 @@ import (
 	"github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/validation"
 	networking "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	gateway_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -39,6 +40,7 @@ const (
 // - Regular or Master Ingress
 // - VirtualServer
 // - TransportServer
+// - Gateway (a host of a Gateway)
 type Resource interface {
 	GetObjectMeta() *metav1.ObjectMeta
 	GetKeyWithKind() string
@@ -333,6 +335,8 @@ type Configuration struct {
 	virtualServers      map[string]*conf_v1.VirtualServer
 	virtualServerRoutes map[string]*conf_v1.VirtualServerRoute
 	transportServers    map[string]*conf_v1alpha1.TransportServer
+	gateways            map[string]*gateway_v1alpha2.Gateway
+	httpRoutes          map[string]*gateway_v1alpha2.HTTPRoute
 
 	globalConfiguration *conf_v1alpha1.GlobalConfiguration
 
@@ -384,6 +388,8 @@ func NewConfiguration(
 		virtualServers:               make(map[string]*conf_v1.VirtualServer),
 		virtualServerRoutes:          make(map[string]*conf_v1.VirtualServerRoute),
 		transportServers:             make(map[string]*conf_v1alpha1.TransportServer),
+		gateways:                     make(map[string]*gateway_v1alpha2.Gateway),
+		httpRoutes:                   make(map[string]*gateway_v1alpha2.HTTPRoute),
 		hostProblems:                 make(map[string]ConfigurationProblem),
 		hasCorrectIngressClass:       hasCorrectIngressClass,
 		virtualServerValidator:       virtualServerValidator,
@@ -705,6 +711,59 @@ func (c *Configuration) DeleteTransportServer(key string) ([]ResourceChange, []C
 	return changes, problems
 }
 
+// AddOrUpdateGateway adds or updates the Gateway.
+// The Gateway must belong to a GatewayClass handled by the Ingress Controller.
+func (c *Configuration) AddOrUpdateGateway(gw *gateway_v1alpha2.Gateway) ([]ResourceChange, []ConfigurationProblem) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key := getResourceKey(&gw.ObjectMeta)
+	c.gateways[key] = gw
+
+	return c.rebuildHosts()
+}
+
+// DeleteGateway deletes a Gateway by the key.
+func (c *Configuration) DeleteGateway(key string) ([]ResourceChange, []ConfigurationProblem) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, exists := c.gateways[key]
+	if !exists {
+		return nil, nil
+	}
+
+	delete(c.gateways, key)
+
+	return c.rebuildHosts()
+}
+
+// AddOrUpdateHTTPRoute adds or updates the HTTPRoute.
+func (c *Configuration) AddOrUpdateHTTPRoute(route *gateway_v1alpha2.HTTPRoute) ([]ResourceChange, []ConfigurationProblem) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key := getResourceKey(&route.ObjectMeta)
+	c.httpRoutes[key] = route
+
+	return c.rebuildHosts()
+}
+
+// DeleteHTTPRoute deletes an HTTPRoute by the key.
+func (c *Configuration) DeleteHTTPRoute(key string) ([]ResourceChange, []ConfigurationProblem) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, exists := c.httpRoutes[key]
+	if !exists {
+		return nil, nil
+	}
+
+	delete(c.httpRoutes, key)
+
+	return c.rebuildHosts()
+}
+
 func (c *Configuration) rebuildListeners() ([]ResourceChange, []ConfigurationProblem) {
 	newListeners, newTSConfigs := c.buildListenersAndTSConfigurations()
 
@@ -769,31 +828,93 @@ func (c *Configuration) buildListenersAndTSConfigurations() (newListeners map[st
 
 		tsc.ListenerPort = listener.Port
 
-		holder, exists := newListeners[listener.Name]
+		listenerKey := getListenerKey(listener.Name, ts.Spec.Host)
+
+		holder, exists := newListeners[listenerKey]
 		if !exists {
-			newListeners[listener.Name] = tsc
+			newListeners[listenerKey] = tsc
 			continue
 		}
 
 		warning := fmt.Sprintf("listener %s is taken by another resource", listener.Name)
+		if ts.Spec.Host != "" {
+			warning = fmt.Sprintf("host %s is taken by another resource", ts.Spec.Host)
+		}
 
 		if !holder.Wins(tsc) {
 			holder.AddWarning(warning)
-			newListeners[listener.Name] = tsc
+			newListeners[listenerKey] = tsc
 		} else {
 			tsc.AddWarning(warning)
 		}
 	}
 
+	removeListenerConflictsBetweenHosts(newListeners)
+
 	return newListeners, newTSConfigs
 }
 
+// getListenerKey returns the key of a TransportServer in the listeners of the Configuration.
+// TransportServers with a host share their listener with the TransportServers with other hosts,
+// while a TransportServer without a host takes the whole listener.
+func getListenerKey(listenerName string, host string) string {
+	if host == "" {
+		return listenerName
+	}
+
+	return fmt.Sprintf("%s/%s", listenerName, host)
+}
+
+// removeListenerConflictsBetweenHosts resolves the collisions between TransportServers with and without a host,
+// which can't share a listener. The TransportServer without a host competes with the winner among the
+// TransportServers with a host: if it wins, it takes the whole listener, otherwise it loses the listener.
+func removeListenerConflictsBetweenHosts(listeners map[string]*TransportServerConfiguration) {
+	hostWinners := make(map[string]*TransportServerConfiguration)
+
+	for _, key := range getSortedTransportServerConfigurationKeys(listeners) {
+		tsc := listeners[key]
+		if tsc.TransportServer.Spec.Host == "" {
+			continue
+		}
+
+		listenerName := tsc.TransportServer.Spec.Listener.Name
+		winner, exists := hostWinners[listenerName]
+		if !exists || !winner.Wins(tsc) {
+			hostWinners[listenerName] = tsc
+		}
+	}
+
+	for listenerName, winner := range hostWinners {
+		holder, exists := listeners[listenerName]
+		if !exists {
+			continue
+		}
+
+		warning := fmt.Sprintf("listener %s is taken by another resource", listenerName)
+
+		if !holder.Wins(winner) {
+			holder.AddWarning(warning)
+			delete(listeners, listenerName)
+			continue
+		}
+
+		for _, key := range getSortedTransportServerConfigurationKeys(listeners) {
+			tsc := listeners[key]
+			if tsc.TransportServer.Spec.Host != "" && tsc.TransportServer.Spec.Listener.Name == listenerName {
+				tsc.AddWarning(warning)
+				delete(listeners, key)
+			}
+		}
+	}
+}
+
 // GetResources returns all configuration resources.
 func (c *Configuration) GetResources() []Resource {
 	return c.GetResourcesWithFilter(resourceFilter{
 		Ingresses:        true,
 		VirtualServers:   true,
 		TransportServers: true,
+		Gateways:         true,
 	})
 }
 
@@ -801,6 +922,7 @@ type resourceFilter struct {
 	Ingresses        bool
 	VirtualServers   bool
 	TransportServers bool
+	Gateways         bool
 }
 
 // GetResourcesWithFilter returns resources using the filter.
@@ -824,6 +946,10 @@ func (c *Configuration) GetResourcesWithFilter(filter resourceFilter) []Resource
 			if filter.TransportServers {
 				resources[r.GetKeyWithKind()] = r
 			}
+		case *GatewayConfiguration:
+			if filter.Gateways {
+				resources[r.GetKeyWithKind()] = r
+			}
 		}
 	}
 
@@ -841,6 +967,25 @@ func (c *Configuration) GetResourcesWithFilter(filter resourceFilter) []Resource
 	return result
 }
 
+// GetProblems returns the current problems of the resources that don't have an active host or listener.
+// Validation problems are not included: they are only returned by the AddOrUpdate methods.
+func (c *Configuration) GetProblems() []ConfigurationProblem {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var problems []ConfigurationProblem
+
+	for _, key := range getSortedProblemKeys(c.hostProblems) {
+		problems = append(problems, c.hostProblems[key])
+	}
+
+	for _, key := range getSortedProblemKeys(c.listenerProblems) {
+		problems = append(problems, c.listenerProblems[key])
+	}
+
+	return problems
+}
+
 // FindResourcesForService finds resources that reference the specified service.
 func (c *Configuration) FindResourcesForService(svcNamespace string, svcName string) []Resource {
 	return c.findResourcesForResourceReference(svcNamespace, svcName, c.serviceReferenceChecker)
@@ -916,6 +1061,12 @@ func (c *Configuration) findResourcesForResourceReference(namespace string, name
 				result = append(result, r)
 				continue
 			}
+		case *GatewayConfiguration:
+			// the Gateway references resources through the VirtualServer it was translated into
+			if checker.IsReferencedByVirtualServer(namespace, name, impl.VirtualServer) {
+				result = append(result, r)
+				continue
+			}
 		}
 	}
 
@@ -1009,27 +1160,38 @@ func detectChangesInProblems(newProblems map[string]ConfigurationProblem, oldPro
 
 func (c *Configuration) addProblemsForTSConfigsWithoutActiveListener(tsConfigs map[string]*TransportServerConfiguration, problems map[string]ConfigurationProblem) {
 	for _, tsc := range tsConfigs {
-		holder, exists := c.listeners[tsc.TransportServer.Spec.Listener.Name]
-		if !exists {
+		listenerName := tsc.TransportServer.Spec.Listener.Name
+		host := tsc.TransportServer.Spec.Host
+
+		// only the TransportServers that reference an existing listener get a listener port
+		if tsc.ListenerPort == 0 {
 			p := ConfigurationProblem{
 				Object:  tsc.TransportServer,
 				IsError: false,
 				Reason:  "Rejected",
-				Message: fmt.Sprintf("Listener %s doesn't exist", tsc.TransportServer.Spec.Listener.Name),
+				Message: fmt.Sprintf("Listener %s doesn't exist", listenerName),
 			}
 			problems[tsc.GetKeyWithKind()] = p
 			continue
 		}
 
-		if !tsc.IsEqual(holder) {
-			p := ConfigurationProblem{
-				Object:  tsc.TransportServer,
-				IsError: false,
-				Reason:  "Rejected",
-				Message: fmt.Sprintf("Listener %s is taken by another resource", tsc.TransportServer.Spec.Listener.Name),
-			}
-			problems[tsc.GetKeyWithKind()] = p
+		holder, exists := c.listeners[getListenerKey(listenerName, host)]
+		if exists && tsc.IsEqual(holder) {
+			continue
+		}
+
+		message := fmt.Sprintf("Listener %s is taken by another resource", listenerName)
+		if exists && host != "" {
+			message = fmt.Sprintf("Host %s is taken by another resource", host)
+		}
+
+		p := ConfigurationProblem{
+			Object:  tsc.TransportServer,
+			IsError: false,
+			Reason:  "Rejected",
+			Message: message,
 		}
+		problems[tsc.GetKeyWithKind()] = p
 	}
 }
 
@@ -1077,6 +1239,29 @@ func (c *Configuration) addProblemsForResourcesWithoutActiveHost(resources map[s
 				}
 				problems[r.GetKeyWithKind()] = p
 			}
+		case *GatewayConfiguration:
+			if impl.ValidationError != nil {
+				p := ConfigurationProblem{
+					Object:  impl.Gateway,
+					IsError: true,
+					Reason:  "Rejected",
+					Message: fmt.Sprintf("Configuration for host %s was rejected with error: %s", impl.Host, impl.ValidationError.Error()),
+				}
+				problems[r.GetKeyWithKind()] = p
+				continue
+			}
+
+			res := c.hosts[impl.Host]
+
+			if res.GetKeyWithKind() != r.GetKeyWithKind() {
+				p := ConfigurationProblem{
+					Object:  impl.Gateway,
+					IsError: false,
+					Reason:  "Rejected",
+					Message: fmt.Sprintf("Host %s is taken by another resource", impl.Host),
+				}
+				problems[r.GetKeyWithKind()] = p
+			}
 		}
 	}
 }
@@ -1391,6 +1576,44 @@ func (c *Configuration) buildHostsAndResources() (newHosts map[string]Resource,
 		}
 	}
 
+	// Step - 4 - Build hosts from Gateway resources
+
+	for _, key := range getSortedGatewayKeys(c.gateways) {
+		gw := c.gateways[key]
+
+		var routes []*gateway_v1alpha2.HTTPRoute
+		for _, routeKey := range getSortedHTTPRouteKeys(c.httpRoutes) {
+			route := c.httpRoutes[routeKey]
+			if isRouteAttachedToGateway(route, gw) {
+				routes = append(routes, route)
+			}
+		}
+
+		for _, resource := range translateGateway(gw, routes) {
+			newResources[resource.GetKeyWithKind()] = resource
+
+			resource.ValidationError = c.virtualServerValidator.ValidateVirtualServer(resource.VirtualServer)
+			if resource.ValidationError != nil {
+				continue
+			}
+
+			holder, exists := newHosts[resource.Host]
+			if !exists {
+				newHosts[resource.Host] = resource
+				continue
+			}
+
+			warning := fmt.Sprintf("host %s is taken by another resource", resource.Host)
+
+			if !holder.Wins(resource) {
+				newHosts[resource.Host] = resource
+				holder.AddWarning(warning)
+			} else {
+				resource.AddWarning(warning)
+			}
+		}
+	}
+
 	return newHosts, newResources
 }
 
@@ -1575,6 +1798,30 @@ func getSortedVirtualServerRouteKeys(m map[string]*conf_v1.VirtualServerRoute) [
 	return keys
 }
 
+func getSortedGatewayKeys(m map[string]*gateway_v1alpha2.Gateway) []string {
+	var keys []string
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func getSortedHTTPRouteKeys(m map[string]*gateway_v1alpha2.HTTPRoute) []string {
+	var keys []string
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
 func getSortedProblemKeys(m map[string]ConfigurationProblem) []string {
 	var keys []string
 
@@ -11,6 +11,7 @@ import (
 	"github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/validation"
 	networking "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gateway_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 )
 
 func createTestConfiguration() *Configuration {
@@ -1873,6 +1874,65 @@ func TestHostCollisions(t *testing.T) {
 	}
 }
 
+func TestAddGatewayWithHTTPRoute(t *testing.T) {
+	configuration := createTestConfiguration()
+
+	gw := createTestGateway("gateway", createTestHTTPListener("http"))
+	route := createTestHTTPRoute("cafe", "gateway", "cafe.example.com", gateway_v1alpha2.HTTPRouteRule{
+		BackendRefs: []gateway_v1alpha2.HTTPBackendRef{
+			createTestHTTPBackendRef("coffee", 80, 1),
+		},
+	})
+
+	var expectedChanges []ResourceChange
+	var expectedProblems []ConfigurationProblem
+
+	// Add Gateway without HTTPRoutes
+
+	changes, problems := configuration.AddOrUpdateGateway(gw)
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Errorf("AddOrUpdateGateway() returned unexpected result (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedProblems, problems); diff != "" {
+		t.Errorf("AddOrUpdateGateway() returned unexpected result (-want +got):\n%s", diff)
+	}
+
+	// Add HTTPRoute
+
+	gwConfigs := translateGateway(gw, []*gateway_v1alpha2.HTTPRoute{route})
+	expectedChanges = []ResourceChange{
+		{
+			Op:       AddOrUpdate,
+			Resource: gwConfigs[0],
+		},
+	}
+
+	changes, problems = configuration.AddOrUpdateHTTPRoute(route)
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Errorf("AddOrUpdateHTTPRoute() returned unexpected result (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedProblems, problems); diff != "" {
+		t.Errorf("AddOrUpdateHTTPRoute() returned unexpected result (-want +got):\n%s", diff)
+	}
+
+	// Delete HTTPRoute
+
+	expectedChanges = []ResourceChange{
+		{
+			Op:       Delete,
+			Resource: gwConfigs[0],
+		},
+	}
+
+	changes, problems = configuration.DeleteHTTPRoute("default/cafe")
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Errorf("DeleteHTTPRoute() returned unexpected result (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedProblems, problems); diff != "" {
+		t.Errorf("DeleteHTTPRoute() returned unexpected result (-want +got):\n%s", diff)
+	}
+}
+
 func TestAddTransportServer(t *testing.T) {
 	configuration := createTestConfiguration()
 
@@ -2267,6 +2327,186 @@ func TestAddTransportServerWithNonExistingListener(t *testing.T) {
 	}
 }
 
+func TestAddTransportServersWithHostsOnSharedListener(t *testing.T) {
+	configuration := createTestConfiguration()
+
+	listeners := []conf_v1alpha1.Listener{
+		{
+			Name:     "tcp-7777",
+			Port:     7777,
+			Protocol: "TCP",
+		},
+	}
+	gc := createTestGlobalConfiguration(listeners)
+	mustInitGlobalConfiguration(configuration, gc)
+
+	now := metav1.Now()
+
+	tsOne := createTestTransportServerWithHost("ts-one", "tcp-7777", "one.example.com")
+	tsOne.CreationTimestamp = now
+	tsTwo := createTestTransportServerWithHost("ts-two", "tcp-7777", "two.example.com")
+	tsTwo.CreationTimestamp = metav1.NewTime(now.Add(1 * time.Second))
+	tsThree := createTestTransportServerWithHost("ts-three", "tcp-7777", "one.example.com")
+	tsThree.CreationTimestamp = metav1.NewTime(now.Add(2 * time.Second))
+	tsWithoutHost := createTestTransportServer("ts-without-host", "tcp-7777", "TCP")
+	tsWithoutHost.CreationTimestamp = metav1.NewTime(now.Add(3 * time.Second))
+
+	var expectedProblems []ConfigurationProblem
+
+	// Add the first TransportServer
+
+	expectedChanges := []ResourceChange{
+		{
+			Op: AddOrUpdate,
+			Resource: &TransportServerConfiguration{
+				ListenerPort:    7777,
+				TransportServer: tsOne,
+			},
+		},
+	}
+
+	changes, problems := configuration.AddOrUpdateTransportServer(tsOne)
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Errorf("AddOrUpdateTransportServer() returned unexpected result (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedProblems, problems); diff != "" {
+		t.Errorf("AddOrUpdateTransportServer() returned unexpected result (-want +got):\n%s", diff)
+	}
+
+	// Add a TransportServer with another host on the same listener
+
+	expectedChanges = []ResourceChange{
+		{
+			Op: AddOrUpdate,
+			Resource: &TransportServerConfiguration{
+				ListenerPort:    7777,
+				TransportServer: tsTwo,
+			},
+		},
+	}
+
+	changes, problems = configuration.AddOrUpdateTransportServer(tsTwo)
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Errorf("AddOrUpdateTransportServer() returned unexpected result (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedProblems, problems); diff != "" {
+		t.Errorf("AddOrUpdateTransportServer() returned unexpected result (-want +got):\n%s", diff)
+	}
+
+	// Add a TransportServer with a host that is taken
+
+	expectedChanges = nil
+	expectedProblems = []ConfigurationProblem{
+		{
+			Object:  tsThree,
+			IsError: false,
+			Reason:  "Rejected",
+			Message: "Host one.example.com is taken by another resource",
+		},
+	}
+
+	changes, problems = configuration.AddOrUpdateTransportServer(tsThree)
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Errorf("AddOrUpdateTransportServer() returned unexpected result (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedProblems, problems); diff != "" {
+		t.Errorf("AddOrUpdateTransportServer() returned unexpected result (-want +got):\n%s", diff)
+	}
+
+	// Add a TransportServer without a host on the listener shared by the TransportServers with hosts
+
+	expectedChanges = nil
+	expectedProblems = []ConfigurationProblem{
+		{
+			Object:  tsWithoutHost,
+			IsError: false,
+			Reason:  "Rejected",
+			Message: "Listener tcp-7777 is taken by another resource",
+		},
+	}
+
+	changes, problems = configuration.AddOrUpdateTransportServer(tsWithoutHost)
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Errorf("AddOrUpdateTransportServer() returned unexpected result (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedProblems, problems); diff != "" {
+		t.Errorf("AddOrUpdateTransportServer() returned unexpected result (-want +got):\n%s", diff)
+	}
+
+	// Delete the first TransportServer, so that the TransportServer with the same host takes the host
+
+	expectedChanges = []ResourceChange{
+		{
+			Op: Delete,
+			Resource: &TransportServerConfiguration{
+				ListenerPort:    7777,
+				TransportServer: tsOne,
+			},
+		},
+		{
+			Op: AddOrUpdate,
+			Resource: &TransportServerConfiguration{
+				ListenerPort:    7777,
+				TransportServer: tsThree,
+			},
+		},
+	}
+	expectedProblems = nil
+
+	changes, problems = configuration.DeleteTransportServer("default/ts-one")
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Errorf("DeleteTransportServer() returned unexpected result (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedProblems, problems); diff != "" {
+		t.Errorf("DeleteTransportServer() returned unexpected result (-want +got):\n%s", diff)
+	}
+
+	// Delete the TransportServers with hosts, so that the TransportServer without a host takes the listener
+
+	expectedChanges = []ResourceChange{
+		{
+			Op: Delete,
+			Resource: &TransportServerConfiguration{
+				ListenerPort:    7777,
+				TransportServer: tsTwo,
+			},
+		},
+	}
+
+	changes, problems = configuration.DeleteTransportServer("default/ts-two")
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Errorf("DeleteTransportServer() returned unexpected result (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedProblems, problems); diff != "" {
+		t.Errorf("DeleteTransportServer() returned unexpected result (-want +got):\n%s", diff)
+	}
+
+	expectedChanges = []ResourceChange{
+		{
+			Op: Delete,
+			Resource: &TransportServerConfiguration{
+				ListenerPort:    7777,
+				TransportServer: tsThree,
+			},
+		},
+		{
+			Op: AddOrUpdate,
+			Resource: &TransportServerConfiguration{
+				ListenerPort:    7777,
+				TransportServer: tsWithoutHost,
+			},
+		},
+	}
+
+	changes, problems = configuration.DeleteTransportServer("default/ts-three")
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Errorf("DeleteTransportServer() returned unexpected result (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedProblems, problems); diff != "" {
+		t.Errorf("DeleteTransportServer() returned unexpected result (-want +got):\n%s", diff)
+	}
+}
+
 func TestDeleteNonExistingTransportServer(t *testing.T) {
 	configuration := createTestConfiguration()
 
@@ -2971,6 +3211,16 @@ func createTestTLSPassthroughTransportServer(name string, host string) *conf_v1a
 	return ts
 }
 
+func createTestTransportServerWithHost(name string, listenerName string, host string) *conf_v1alpha1.TransportServer {
+	ts := createTestTransportServer(name, listenerName, "TCP")
+	ts.Spec.Host = host
+	ts.Spec.TLS = &conf_v1alpha1.TransportServerTLS{
+		Secret: "tls-secret",
+	}
+
+	return ts
+}
+
 func createTestGlobalConfiguration(listeners []conf_v1alpha1.Listener) *conf_v1alpha1.GlobalConfiguration {
 	return &conf_v1alpha1.GlobalConfiguration{
 		ObjectMeta: metav1.ObjectMeta{
@@ -18,8 +18,10 @@ package k8s
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -53,6 +55,7 @@ import (
 	"github.com/nginxinc/kubernetes-ingress/internal/metrics/collectors"
 
 	api_v1 "k8s.io/api/core/v1"
+	discovery_v1 "k8s.io/api/discovery/v1"
 	networking "k8s.io/api/networking/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -66,6 +69,10 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+
+	gateway_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gateway_versioned "sigs.k8s.io/gateway-api/pkg/client/clientset/gateway/versioned"
+	gateway_informers "sigs.k8s.io/gateway-api/pkg/client/informers/gateway/externalversions"
 )
 
 const (
@@ -99,11 +106,13 @@ type podEndpoint struct {
 type LoadBalancerController struct {
 	client                        kubernetes.Interface
 	confClient                    k8s_nginx.Interface
+	gatewayClient                 gateway_versioned.Interface
 	dynClient                     dynamic.Interface
 	restConfig                    *rest.Config
 	cacheSyncs                    []cache.InformerSynced
 	sharedInformerFactory         informers.SharedInformerFactory
 	confSharedInformerFactory     k8s_nginx_informers.SharedInformerFactory
+	gatewaySharedInformerFactory  gateway_informers.SharedInformerFactory
 	configMapController           cache.Controller
 	dynInformerFactory            dynamicinformer.DynamicSharedInformerFactory
 	globalConfigurationController cache.Controller
@@ -111,6 +120,7 @@ type LoadBalancerController struct {
 	ingressLister                 storeToIngressLister
 	svcLister                     cache.Store
 	endpointLister                storeToEndpointLister
+	endpointSliceLister           indexerToEndpointSliceLister
 	configMapLister               storeToConfigMapLister
 	podLister                     indexerToPodLister
 	secretLister                  cache.Store
@@ -125,7 +135,11 @@ type LoadBalancerController struct {
 	appProtectUserSigLister       cache.Store
 	transportServerLister         cache.Store
 	policyLister                  cache.Store
+	trafficShiftLister            cache.Store
 	ingressLinkLister             cache.Store
+	gatewayClassLister            cache.Store
+	gatewayLister                 cache.Store
+	httpRouteLister               cache.Store
 	syncQueue                     *taskQueue
 	ctx                           context.Context
 	cancel                        context.CancelFunc
@@ -149,16 +163,22 @@ type LoadBalancerController struct {
 	controllerNamespace           string
 	wildcardTLSSecret             string
 	areCustomResourcesEnabled     bool
+	isGatewayAPIEnabled           bool
 	enableOIDC                    bool
+	enableGeoIP2                  bool
+	enableBrotli                  bool
+	enableAPIKeyAuth              bool
 	metricsCollector              collectors.ControllerCollector
 	globalConfigurationValidator  *validation.GlobalConfigurationValidator
 	transportServerValidator      *validation.TransportServerValidator
 	spiffeCertFetcher             *SpiffeCertFetcher
 	internalRoutesEnabled         bool
-	syncLock                      sync.Mutex
+	syncLock                      sync.RWMutex
+	changeSequencer               *changeSequencer
 	isNginxReady                  bool
 	isPrometheusEnabled           bool
 	isLatencyMetricsEnabled       bool
+	isEndpointSlicesEnabled       bool
 	configuration                 *Configuration
 	secretStore                   secrets.SecretStore
 	appProtectConfiguration       appprotect.Configuration
@@ -166,6 +186,7 @@ type LoadBalancerController struct {
 	configMap                     *api_v1.ConfigMap
 	certManagerController         *cm_controller.CmController
 	externalDNSController         *ed_controller.ExtDNSController
+	trafficShifter                *trafficShifter
 }
 
 var keyFunc = cache.DeletionHandlingMetaNamespaceKeyFunc
@@ -174,6 +195,7 @@ var keyFunc = cache.DeletionHandlingMetaNamespaceKeyFunc
 type NewLoadBalancerControllerInput struct {
 	KubeClient                   kubernetes.Interface
 	ConfClient                   k8s_nginx.Interface
+	GatewayClient                gateway_versioned.Interface
 	DynClient                    dynamic.Interface
 	RestConfig                   *rest.Config
 	ResyncPeriod                 time.Duration
@@ -194,7 +216,11 @@ type NewLoadBalancerControllerInput struct {
 	ConfigMaps                   string
 	GlobalConfiguration          string
 	AreCustomResourcesEnabled    bool
+	IsGatewayAPIEnabled          bool
 	EnableOIDC                   bool
+	EnableGeoIP2                 bool
+	EnableBrotli                 bool
+	EnableAPIKeyAuth             bool
 	MetricsCollector             collectors.ControllerCollector
 	GlobalConfigurationValidator *validation.GlobalConfigurationValidator
 	TransportServerValidator     *validation.TransportServerValidator
@@ -203,10 +229,13 @@ type NewLoadBalancerControllerInput struct {
 	InternalRoutesEnabled        bool
 	IsPrometheusEnabled          bool
 	IsLatencyMetricsEnabled      bool
+	IsEndpointSlicesEnabled      bool
 	IsTLSPassthroughEnabled      bool
 	SnippetsEnabled              bool
 	CertManagerEnabled           bool
 	ExternalDNSEnabled           bool
+	SyncWorkers                  int
+	UpstreamResponsesSource      UpstreamResponsesSource
 }
 
 // NewLoadBalancerController creates a controller
@@ -214,6 +243,7 @@ func NewLoadBalancerController(input NewLoadBalancerControllerInput) *LoadBalanc
 	lbc := &LoadBalancerController{
 		client:                       input.KubeClient,
 		confClient:                   input.ConfClient,
+		gatewayClient:                input.GatewayClient,
 		dynClient:                    input.DynClient,
 		restConfig:                   input.RestConfig,
 		configurator:                 input.NginxConfigurator,
@@ -230,13 +260,19 @@ func NewLoadBalancerController(input NewLoadBalancerControllerInput) *LoadBalanc
 		controllerNamespace:          input.ControllerNamespace,
 		wildcardTLSSecret:            input.WildcardTLSSecret,
 		areCustomResourcesEnabled:    input.AreCustomResourcesEnabled,
+		isGatewayAPIEnabled:          input.IsGatewayAPIEnabled,
 		enableOIDC:                   input.EnableOIDC,
+		enableGeoIP2:                 input.EnableGeoIP2,
+		enableBrotli:                 input.EnableBrotli,
+		enableAPIKeyAuth:             input.EnableAPIKeyAuth,
 		metricsCollector:             input.MetricsCollector,
 		globalConfigurationValidator: input.GlobalConfigurationValidator,
 		transportServerValidator:     input.TransportServerValidator,
 		internalRoutesEnabled:        input.InternalRoutesEnabled,
 		isPrometheusEnabled:          input.IsPrometheusEnabled,
 		isLatencyMetricsEnabled:      input.IsLatencyMetricsEnabled,
+		isEndpointSlicesEnabled:      input.IsEndpointSlicesEnabled,
+		changeSequencer:              newChangeSequencer(),
 	}
 
 	eventBroadcaster := record.NewBroadcaster()
@@ -247,7 +283,8 @@ func NewLoadBalancerController(input NewLoadBalancerControllerInput) *LoadBalanc
 	lbc.recorder = eventBroadcaster.NewRecorder(scheme.Scheme,
 		api_v1.EventSource{Component: "nginx-ingress-controller"})
 
-	lbc.syncQueue = newTaskQueue(lbc.sync)
+	lbc.syncQueue = newTaskQueue(lbc.sync, input.SyncWorkers)
+	lbc.syncQueue.idle = lbc.syncNginxReady
 	if input.SpireAgentAddress != "" {
 		var err error
 		lbc.spiffeCertFetcher, err = NewSpiffeCertFetcher(lbc.syncSVIDRotation, input.SpireAgentAddress)
@@ -272,7 +309,11 @@ func NewLoadBalancerController(input NewLoadBalancerControllerInput) *LoadBalanc
 	lbc.addSecretHandler(createSecretHandlers(lbc))
 	lbc.addIngressHandler(createIngressHandlers(lbc))
 	lbc.addServiceHandler(createServiceHandlers(lbc))
-	lbc.addEndpointHandler(createEndpointHandlers(lbc))
+	if lbc.isEndpointSlicesEnabled {
+		lbc.addEndpointSliceHandler(createEndpointSliceHandlers(lbc))
+	} else {
+		lbc.addEndpointHandler(createEndpointHandlers(lbc))
+	}
 	lbc.addPodHandler()
 
 	if lbc.areCustomResourcesEnabled {
@@ -283,6 +324,11 @@ func NewLoadBalancerController(input NewLoadBalancerControllerInput) *LoadBalanc
 		lbc.addTransportServerHandler(createTransportServerHandlers(lbc))
 		lbc.addPolicyHandler(createPolicyHandlers(lbc))
 
+		if input.UpstreamResponsesSource != nil {
+			lbc.trafficShifter = newTrafficShifter(input.UpstreamResponsesSource)
+			lbc.addTrafficShiftHandler(createTrafficShiftHandlers(lbc))
+		}
+
 		if input.GlobalConfiguration != "" {
 			lbc.watchGlobalConfiguration = true
 			ns, name, _ := ParseNamespaceName(input.GlobalConfiguration)
@@ -290,6 +336,14 @@ func NewLoadBalancerController(input NewLoadBalancerControllerInput) *LoadBalanc
 		}
 	}
 
+	if lbc.isGatewayAPIEnabled {
+		lbc.gatewaySharedInformerFactory = gateway_informers.NewSharedInformerFactoryWithOptions(lbc.gatewayClient, input.ResyncPeriod, gateway_informers.WithNamespace(lbc.namespace))
+
+		lbc.addGatewayClassHandler(createGatewayClassHandlers(lbc))
+		lbc.addGatewayHandler(createGatewayHandlers(lbc))
+		lbc.addHTTPRouteHandler(createHTTPRouteHandlers(lbc))
+	}
+
 	if lbc.appProtectEnabled || lbc.appProtectDosEnabled {
 		lbc.dynInformerFactory = dynamicinformer.NewFilteredDynamicSharedInformerFactory(lbc.dynClient, 0, lbc.namespace, nil)
 
@@ -334,9 +388,14 @@ func NewLoadBalancerController(input NewLoadBalancerControllerInput) *LoadBalanc
 		virtualServerRouteLister: lbc.virtualServerRouteLister,
 		transportServerLister:    lbc.transportServerLister,
 		policyLister:             lbc.policyLister,
+		trafficShiftLister:       lbc.trafficShiftLister,
 		keyFunc:                  keyFunc,
 		confClient:               input.ConfClient,
 		hasCorrectIngressClass:   lbc.HasCorrectIngressClass,
+		gatewayClient:            input.GatewayClient,
+		gatewayClassLister:       lbc.gatewayClassLister,
+		gatewayLister:            lbc.gatewayLister,
+		httpRouteLister:          lbc.httpRouteLister,
 	}
 
 	lbc.configuration = NewConfiguration(
@@ -465,6 +524,19 @@ func (lbc *LoadBalancerController) addEndpointHandler(handlers cache.ResourceEve
 	lbc.cacheSyncs = append(lbc.cacheSyncs, informer.HasSynced)
 }
 
+// addEndpointSliceHandler adds the handler for endpoint slices to the controller
+func (lbc *LoadBalancerController) addEndpointSliceHandler(handlers cache.ResourceEventHandlerFuncs) {
+	informer := lbc.sharedInformerFactory.Discovery().V1().EndpointSlices().Informer()
+	err := informer.AddIndexers(cache.Indexers{endpointSliceServiceIndex: endpointSliceServiceIndexFunc})
+	if err != nil {
+		glog.Fatalf("Error adding the service index to the EndpointSlice informer: %v", err)
+	}
+	informer.AddEventHandler(handlers)
+	lbc.endpointSliceLister.Indexer = informer.GetIndexer()
+
+	lbc.cacheSyncs = append(lbc.cacheSyncs, informer.HasSynced)
+}
+
 // addConfigMapHandler adds the handler for config maps to the controller
 func (lbc *LoadBalancerController) addConfigMapHandler(handlers cache.ResourceEventHandlerFuncs, namespace string) {
 	lbc.configMapLister.Store, lbc.configMapController = cache.NewInformer(
@@ -511,6 +583,14 @@ func (lbc *LoadBalancerController) addPolicyHandler(handlers cache.ResourceEvent
 	lbc.cacheSyncs = append(lbc.cacheSyncs, informer.HasSynced)
 }
 
+func (lbc *LoadBalancerController) addTrafficShiftHandler(handlers cache.ResourceEventHandlerFuncs) {
+	informer := lbc.confSharedInformerFactory.K8s().V1alpha1().TrafficShifts().Informer()
+	informer.AddEventHandler(handlers)
+	lbc.trafficShiftLister = informer.GetStore()
+
+	lbc.cacheSyncs = append(lbc.cacheSyncs, informer.HasSynced)
+}
+
 func (lbc *LoadBalancerController) addGlobalConfigurationHandler(handlers cache.ResourceEventHandlerFuncs, namespace string, name string) {
 	lbc.globalConfigurationLister, lbc.globalConfigurationController = cache.NewInformer(
 		cache.NewListWatchFromClient(
@@ -533,6 +613,30 @@ func (lbc *LoadBalancerController) addTransportServerHandler(handlers cache.Reso
 	lbc.cacheSyncs = append(lbc.cacheSyncs, informer.HasSynced)
 }
 
+func (lbc *LoadBalancerController) addGatewayClassHandler(handlers cache.ResourceEventHandlerFuncs) {
+	informer := lbc.gatewaySharedInformerFactory.Gateway().V1alpha2().GatewayClasses().Informer()
+	informer.AddEventHandler(handlers)
+	lbc.gatewayClassLister = informer.GetStore()
+
+	lbc.cacheSyncs = append(lbc.cacheSyncs, informer.HasSynced)
+}
+
+func (lbc *LoadBalancerController) addGatewayHandler(handlers cache.ResourceEventHandlerFuncs) {
+	informer := lbc.gatewaySharedInformerFactory.Gateway().V1alpha2().Gateways().Informer()
+	informer.AddEventHandler(handlers)
+	lbc.gatewayLister = informer.GetStore()
+
+	lbc.cacheSyncs = append(lbc.cacheSyncs, informer.HasSynced)
+}
+
+func (lbc *LoadBalancerController) addHTTPRouteHandler(handlers cache.ResourceEventHandlerFuncs) {
+	informer := lbc.gatewaySharedInformerFactory.Gateway().V1alpha2().HTTPRoutes().Informer()
+	informer.AddEventHandler(handlers)
+	lbc.httpRouteLister = informer.GetStore()
+
+	lbc.cacheSyncs = append(lbc.cacheSyncs, informer.HasSynced)
+}
+
 func (lbc *LoadBalancerController) addIngressLinkHandler(handlers cache.ResourceEventHandlerFuncs, name string) {
 	optionsModifier := func(options *meta_v1.ListOptions) {
 		options.FieldSelector = fields.Set{"metadata.name": name}.String()
@@ -576,6 +680,9 @@ func (lbc *LoadBalancerController) Run() {
 	if lbc.areCustomResourcesEnabled {
 		go lbc.confSharedInformerFactory.Start(lbc.ctx.Done())
 	}
+	if lbc.isGatewayAPIEnabled {
+		go lbc.gatewaySharedInformerFactory.Start(lbc.ctx.Done())
+	}
 	if lbc.watchGlobalConfiguration {
 		go lbc.globalConfigurationController.Run(lbc.ctx.Done())
 	}
@@ -596,10 +703,31 @@ func (lbc *LoadBalancerController) Run() {
 
 	glog.V(3).Infof("Starting the queue with %d initial elements", lbc.syncQueue.Len())
 
+	go lbc.runReloadBatches()
+
+	if lbc.trafficShifter != nil {
+		go lbc.runTrafficShifts()
+	}
+
 	go lbc.syncQueue.Run(time.Second, lbc.ctx.Done())
 	<-lbc.ctx.Done()
 }
 
+// runReloadBatches reloads NGINX for the batched configuration changes when the batch is due.
+// It does nothing if reload batching is not enabled.
+func (lbc *LoadBalancerController) runReloadBatches() {
+	for {
+		select {
+		case <-lbc.ctx.Done():
+			return
+		case <-lbc.configurator.ReloadBatchDue():
+			lbc.syncLock.Lock()
+			lbc.configurator.ReloadBatch()
+			lbc.syncLock.Unlock()
+		}
+	}
+}
+
 // Stop shutdowns the load balancer controller
 func (lbc *LoadBalancerController) Stop() {
 	lbc.cancel()
@@ -622,13 +750,41 @@ func (lbc *LoadBalancerController) syncEndpoints(task task) {
 	}
 
 	endp := obj.(*api_v1.Endpoints)
-	resources := lbc.configuration.FindResourcesForEndpoints(endp.Namespace, endp.Name)
+	lbc.updateEndpointsForService(endp.Namespace, endp.Name)
+}
+
+// syncEndpointSlices updates the endpoints of the resources that reference the service of the task.
+// Unlike Endpoints, the task key of EndpointSlices is the namespace/name of the service.
+func (lbc *LoadBalancerController) syncEndpointSlices(task task) {
+	key := task.Key
+	glog.V(3).Infof("Syncing EndpointSlices of service %v", key)
+
+	// it is safe to ignore the error
+	namespace, name, _ := ParseNamespaceName(key)
+
+	lbc.updateEndpointsForService(namespace, name)
+}
+
+func (lbc *LoadBalancerController) updateEndpointsForService(namespace string, name string) {
+	var resources []Resource
+
+	turn := lbc.changeSequencer.change(func() {
+		resources = lbc.configuration.FindResourcesForEndpoints(namespace, name)
+
+		if lbc.areCustomResourcesEnabled {
+			resources = append(resources, lbc.findResourcesForExternalAuthService(namespace, name)...)
+			resources = removeDuplicateResources(resources)
+		}
+	})
+	defer turn.done()
 
 	resourceExes := lbc.createExtendedResources(resources)
 
+	turn.wait()
+
 	if len(resourceExes.IngressExes) > 0 {
 		glog.V(3).Infof("Updating Endpoints for %v", resourceExes.IngressExes)
-		err = lbc.configurator.UpdateEndpoints(resourceExes.IngressExes)
+		err := lbc.configurator.UpdateEndpoints(resourceExes.IngressExes)
 		if err != nil {
 			glog.Errorf("Error updating endpoints for %v: %v", resourceExes.IngressExes, err)
 		}
@@ -636,7 +792,7 @@ func (lbc *LoadBalancerController) syncEndpoints(task task) {
 
 	if len(resourceExes.MergeableIngresses) > 0 {
 		glog.V(3).Infof("Updating Endpoints for %v", resourceExes.MergeableIngresses)
-		err = lbc.configurator.UpdateEndpointsMergeableIngress(resourceExes.MergeableIngresses)
+		err := lbc.configurator.UpdateEndpointsMergeableIngress(resourceExes.MergeableIngresses)
 		if err != nil {
 			glog.Errorf("Error updating endpoints for %v: %v", resourceExes.MergeableIngresses, err)
 		}
@@ -682,6 +838,9 @@ func (lbc *LoadBalancerController) createExtendedResources(resources []Resource)
 		case *TransportServerConfiguration:
 			tsEx := lbc.createTransportServerEx(impl.TransportServer, impl.ListenerPort)
 			result.TransportServerExes = append(result.TransportServerExes, tsEx)
+		case *GatewayConfiguration:
+			vsEx := lbc.createVirtualServerEx(impl.VirtualServer, nil)
+			result.VirtualServerExes = append(result.VirtualServerExes, vsEx)
 		}
 	}
 
@@ -730,30 +889,34 @@ func (lbc *LoadBalancerController) updateAllConfigs() {
 
 	warnings, updateErr := lbc.configurator.UpdateConfig(cfgParams, resourceExes)
 
-	eventTitle := "Updated"
-	eventType := api_v1.EventTypeNormal
-	eventWarningMessage := ""
+	configMap := lbc.configMap
+	gc := lbc.configuration.GetGlobalConfiguration()
 
-	if updateErr != nil {
-		eventTitle = "UpdatedWithError"
-		eventType = api_v1.EventTypeWarning
-		eventWarningMessage = fmt.Sprintf("but was not applied: %v", updateErr)
-	}
+	lbc.reportAfterReload(updateErr, func(err error) {
+		eventTitle := "Updated"
+		eventType := api_v1.EventTypeNormal
+		eventWarningMessage := ""
 
-	if len(warnings) > 0 && updateErr == nil {
-		eventWarningMessage = "with warnings. Please check the logs"
-	}
+		if err != nil {
+			eventTitle = "UpdatedWithError"
+			eventType = api_v1.EventTypeWarning
+			eventWarningMessage = fmt.Sprintf("but was not applied: %v", err)
+		}
 
-	if lbc.configMap != nil {
-		key := getResourceKey(&lbc.configMap.ObjectMeta)
-		lbc.recorder.Eventf(lbc.configMap, eventType, eventTitle, "Configuration from %v was updated %s", key, eventWarningMessage)
-	}
+		if len(warnings) > 0 && err == nil {
+			eventWarningMessage = "with warnings. Please check the logs"
+		}
 
-	gc := lbc.configuration.GetGlobalConfiguration()
-	if gc != nil {
-		key := getResourceKey(&lbc.configMap.ObjectMeta)
-		lbc.recorder.Eventf(gc, eventType, eventTitle, fmt.Sprintf("GlobalConfiguration %s was updated %s", key, eventWarningMessage))
-	}
+		if configMap != nil {
+			key := getResourceKey(&configMap.ObjectMeta)
+			lbc.recorder.Eventf(configMap, eventType, eventTitle, "Configuration from %v was updated %s", key, eventWarningMessage)
+		}
+
+		if gc != nil {
+			key := getResourceKey(&configMap.ObjectMeta)
+			lbc.recorder.Eventf(gc, eventType, eventTitle, fmt.Sprintf("GlobalConfiguration %s was updated %s", key, eventWarningMessage))
+		}
+	})
 
 	lbc.updateResourcesStatusAndEvents(resources, warnings, updateErr)
 }
@@ -781,40 +944,88 @@ func (lbc *LoadBalancerController) preSyncSecrets() {
 	}
 }
 
+// concurrentSyncKinds are the kinds of the tasks that are synced concurrently by multiple workers.
+// The tasks of the other kinds change the configuration of many resources and are synced by one worker at a time.
+var concurrentSyncKinds = map[kind]bool{
+	ingress:            true,
+	endpoints:          true,
+	endpointSlices:     true,
+	virtualserver:      true,
+	virtualServerRoute: true,
+	transportserver:    true,
+}
+
 func (lbc *LoadBalancerController) sync(task task) {
 	glog.V(3).Infof("Syncing %v", task.Key)
-	if lbc.spiffeCertFetcher != nil {
+
+	// the tasks of the resources are synced concurrently, while the changes of the configuration of NGINX
+	// are applied one at a time by the changeSequencer. The tasks of other kinds are synced exclusively.
+	if concurrentSyncKinds[task.Kind] {
+		lbc.syncLock.RLock()
+		defer lbc.syncLock.RUnlock()
+	} else {
 		lbc.syncLock.Lock()
 		defer lbc.syncLock.Unlock()
 	}
+
+	lbc.syncTask(task)
+}
+
+// syncNginxReady makes NGINX ready once the initial sync of the resources completes.
+func (lbc *LoadBalancerController) syncNginxReady() {
+	lbc.syncLock.Lock()
+	defer lbc.syncLock.Unlock()
+
+	if lbc.isNginxReady {
+		return
+	}
+
+	lbc.configurator.EnableReloads()
+	lbc.updateAllConfigs()
+	// NGINX must be reloaded with the complete configuration before it is reported as ready
+	lbc.configurator.ReloadBatch()
+
+	lbc.isNginxReady = true
+	glog.V(3).Infof("NGINX is ready")
+}
+
+func (lbc *LoadBalancerController) syncTask(task task) {
 	switch task.Kind {
 	case ingress:
 		lbc.syncIngress(task)
-		lbc.updateIngressMetrics()
-		lbc.updateTransportServerMetrics()
+		lbc.changeSequencer.inTurn(func() {
+			lbc.updateIngressMetrics()
+			lbc.updateTransportServerMetrics()
+		})
 	case configMap:
 		lbc.syncConfigMap(task)
 	case endpoints:
 		lbc.syncEndpoints(task)
+	case endpointSlices:
+		lbc.syncEndpointSlices(task)
 	case secret:
 		lbc.syncSecret(task)
 	case service:
 		lbc.syncService(task)
 	case virtualserver:
 		lbc.syncVirtualServer(task)
-		lbc.updateVirtualServerMetrics()
-		lbc.updateTransportServerMetrics()
+		lbc.changeSequencer.inTurn(func() {
+			lbc.updateVirtualServerMetrics()
+			lbc.updateTransportServerMetrics()
+		})
 	case virtualServerRoute:
 		lbc.syncVirtualServerRoute(task)
-		lbc.updateVirtualServerMetrics()
+		lbc.changeSequencer.inTurn(lbc.updateVirtualServerMetrics)
 	case globalConfiguration:
 		lbc.syncGlobalConfiguration(task)
 		lbc.updateTransportServerMetrics()
 	case transportserver:
 		lbc.syncTransportServer(task)
-		lbc.updateTransportServerMetrics()
+		lbc.changeSequencer.inTurn(lbc.updateTransportServerMetrics)
 	case policy:
 		lbc.syncPolicy(task)
+	case trafficShift:
+		lbc.syncTrafficShift(task)
 	case appProtectPolicy:
 		lbc.syncAppProtectPolicy(task)
 	case appProtectLogConf:
@@ -829,14 +1040,14 @@ func (lbc *LoadBalancerController) sync(task task) {
 		lbc.syncDosProtectedResource(task)
 	case ingressLink:
 		lbc.syncIngressLink(task)
-	}
-
-	if !lbc.isNginxReady && lbc.syncQueue.Len() == 0 {
-		lbc.configurator.EnableReloads()
-		lbc.updateAllConfigs()
-
-		lbc.isNginxReady = true
-		glog.V(3).Infof("NGINX is ready")
+	case gatewayClass:
+		lbc.syncGatewayClass(task)
+	case gateway:
+		lbc.syncGateway(task)
+		lbc.updateVirtualServerMetrics()
+	case httpRoute:
+		lbc.syncHTTPRoute(task)
+		lbc.updateVirtualServerMetrics()
 	}
 }
 
@@ -885,7 +1096,7 @@ func (lbc *LoadBalancerController) syncIngressLink(task task) {
 	}
 
 	if lbc.areCustomResourcesEnabled && lbc.reportCustomResourceStatusEnabled() {
-		virtualServers := lbc.configuration.GetResourcesWithFilter(resourceFilter{VirtualServers: true})
+		virtualServers := lbc.configuration.GetResourcesWithFilter(resourceFilter{VirtualServers: true, Gateways: true})
 
 		glog.V(3).Infof("Updating status for %v VirtualServers", len(virtualServers))
 
@@ -896,6 +1107,18 @@ func (lbc *LoadBalancerController) syncIngressLink(task task) {
 	}
 }
 
+// policyValidationOptions returns the options for the validation of the Policies, based on the enabled features.
+func (lbc *LoadBalancerController) policyValidationOptions() validation.PolicyValidationOptions {
+	return validation.PolicyValidationOptions{
+		IsPlus:           lbc.isNginxPlus,
+		EnableOIDC:       lbc.enableOIDC,
+		EnableAppProtect: lbc.appProtectEnabled,
+		EnableGeoIP2:     lbc.enableGeoIP2,
+		EnableBrotli:     lbc.enableBrotli,
+		EnableAPIKeyAuth: lbc.enableAPIKeyAuth,
+	}
+}
+
 func (lbc *LoadBalancerController) syncPolicy(task task) {
 	key := task.Key
 	obj, polExists, err := lbc.policyLister.GetByKey(key)
@@ -906,9 +1129,14 @@ func (lbc *LoadBalancerController) syncPolicy(task task) {
 
 	glog.V(2).Infof("Adding, Updating or Deleting Policy: %v\n", key)
 
+	// it is safe to ignore the error
+	namespace, name, _ := ParseNamespaceName(key)
+
+	resources := lbc.configuration.FindResourcesForPolicy(namespace, name)
+
 	if polExists && lbc.HasCorrectIngressClass(obj) {
 		pol := obj.(*conf_v1.Policy)
-		err := validation.ValidatePolicy(pol, lbc.isNginxPlus, lbc.enableOIDC, lbc.appProtectEnabled)
+		err := validation.ValidatePolicy(pol, lbc.policyValidationOptions())
 		if err != nil {
 			msg := fmt.Sprintf("Policy %v/%v is invalid and was rejected: %v", pol.Namespace, pol.Name, err)
 			lbc.recorder.Eventf(pol, api_v1.EventTypeWarning, "Rejected", msg)
@@ -919,6 +1147,16 @@ func (lbc *LoadBalancerController) syncPolicy(task task) {
 					glog.V(3).Infof("Failed to update policy %s status: %v", key, err)
 				}
 			}
+		} else if warning := getPolicyWarningForTransportServers(pol, resources); warning != "" {
+			msg := fmt.Sprintf("Policy %v/%v was added or updated with warning(s): %s", pol.Namespace, pol.Name, warning)
+			lbc.recorder.Eventf(pol, api_v1.EventTypeWarning, "AddedOrUpdatedWithWarning", msg)
+
+			if lbc.reportCustomResourceStatusEnabled() {
+				err = lbc.statusUpdater.UpdatePolicyStatus(pol, conf_v1.StateWarning, "AddedOrUpdatedWithWarning", msg)
+				if err != nil {
+					glog.V(3).Infof("Failed to update policy %s status: %v", key, err)
+				}
+			}
 		} else {
 			msg := fmt.Sprintf("Policy %v/%v was added or updated", pol.Namespace, pol.Name)
 			lbc.recorder.Eventf(pol, api_v1.EventTypeNormal, "AddedOrUpdated", msg)
@@ -932,18 +1170,14 @@ func (lbc *LoadBalancerController) syncPolicy(task task) {
 		}
 	}
 
-	// it is safe to ignore the error
-	namespace, name, _ := ParseNamespaceName(key)
-
-	resources := lbc.configuration.FindResourcesForPolicy(namespace, name)
 	resourceExes := lbc.createExtendedResources(resources)
 
-	// Only VirtualServers support policies
-	if len(resourceExes.VirtualServerExes) == 0 {
+	// Only VirtualServers and TransportServers support policies
+	if len(resourceExes.VirtualServerExes) == 0 && len(resourceExes.TransportServerExes) == 0 {
 		return
 	}
 
-	warnings, updateErr := lbc.configurator.AddOrUpdateVirtualServers(resourceExes.VirtualServerExes)
+	warnings, updateErr := lbc.configurator.AddOrUpdateResources(resourceExes)
 	lbc.updateResourcesStatusAndEvents(resources, warnings, updateErr)
 
 	// Note: updating the status of a policy based on a reload is not needed.
@@ -960,17 +1194,18 @@ func (lbc *LoadBalancerController) syncTransportServer(task task) {
 	var changes []ResourceChange
 	var problems []ConfigurationProblem
 
-	if !tsExists {
-		glog.V(2).Infof("Deleting TransportServer: %v\n", key)
-		changes, problems = lbc.configuration.DeleteTransportServer(key)
-	} else {
-		glog.V(2).Infof("Adding or Updating TransportServer: %v\n", key)
-		ts := obj.(*conf_v1alpha1.TransportServer)
-		changes, problems = lbc.configuration.AddOrUpdateTransportServer(ts)
-	}
+	turn := lbc.changeSequencer.change(func() {
+		if !tsExists {
+			glog.V(2).Infof("Deleting TransportServer: %v\n", key)
+			changes, problems = lbc.configuration.DeleteTransportServer(key)
+		} else {
+			glog.V(2).Infof("Adding or Updating TransportServer: %v\n", key)
+			ts := obj.(*conf_v1alpha1.TransportServer)
+			changes, problems = lbc.configuration.AddOrUpdateTransportServer(ts)
+		}
+	})
 
-	lbc.processChanges(changes)
-	lbc.processProblems(problems)
+	lbc.processChangesInTurn(changes, problems, turn)
 }
 
 func (lbc *LoadBalancerController) syncGlobalConfiguration(task task) {
@@ -1033,19 +1268,23 @@ func (lbc *LoadBalancerController) syncVirtualServer(task task) {
 	var changes []ResourceChange
 	var problems []ConfigurationProblem
 
-	if !vsExists {
-		glog.V(2).Infof("Deleting VirtualServer: %v\n", key)
+	turn := lbc.changeSequencer.change(func() {
+		if !vsExists {
+			glog.V(2).Infof("Deleting VirtualServer: %v\n", key)
 
-		changes, problems = lbc.configuration.DeleteVirtualServer(key)
-	} else {
-		glog.V(2).Infof("Adding or Updating VirtualServer: %v\n", key)
+			changes, problems = lbc.configuration.DeleteVirtualServer(key)
+		} else {
+			glog.V(2).Infof("Adding or Updating VirtualServer: %v\n", key)
 
-		vs := obj.(*conf_v1.VirtualServer)
-		changes, problems = lbc.configuration.AddOrUpdateVirtualServer(vs)
-	}
+			vs := obj.(*conf_v1.VirtualServer)
+			changes, problems = lbc.configuration.AddOrUpdateVirtualServer(vs)
+		}
+	})
 
-	lbc.processChanges(changes)
-	lbc.processProblems(problems)
+	lbc.processChangesInTurn(changes, problems, turn)
+
+	// the TrafficShifts of the VirtualServer are checked against the new routes and splits of the VirtualServer
+	lbc.enqueueTrafficShiftsForVirtualServer(key)
 }
 
 func (lbc *LoadBalancerController) processProblems(problems []ConfigurationProblem) {
@@ -1083,96 +1322,175 @@ func (lbc *LoadBalancerController) processProblems(problems []ConfigurationProbl
 				if err != nil {
 					glog.Errorf("Error when updating the status for VirtualServerRoute %v/%v: %v", obj.Namespace, obj.Name, err)
 				}
+			case *gateway_v1alpha2.Gateway:
+				err := lbc.statusUpdater.UpdateGatewayStatus(obj, false, p.Reason, p.Message, nil)
+				if err != nil {
+					glog.Errorf("Error when updating the status for Gateway %v/%v: %v", obj.Namespace, obj.Name, err)
+				}
 			}
 		}
 	}
 }
 
 func (lbc *LoadBalancerController) processChanges(changes []ResourceChange) {
+	for _, apply := range lbc.prepareChanges(changes) {
+		apply()
+	}
+}
+
+// processChangesInTurn processes the changes and the problems of a task synced concurrently with other tasks.
+// The extended resources for the changes are created right away, while the changes are applied and the problems
+// are reported in the turn of the task.
+func (lbc *LoadBalancerController) processChangesInTurn(changes []ResourceChange, problems []ConfigurationProblem, turn *changeTurn) {
+	defer turn.done()
+
+	prepared := lbc.prepareChanges(changes)
+
+	turn.wait()
+
+	for _, apply := range prepared {
+		apply()
+	}
+	lbc.processProblems(problems)
+}
+
+// prepareChanges creates the extended resources for the changes and returns the functions that apply the changes
+// to the configuration of NGINX. The extended resources can be created concurrently by multiple workers,
+// while the changes must be applied in the turn of the worker.
+func (lbc *LoadBalancerController) prepareChanges(changes []ResourceChange) []func() {
 	glog.V(3).Infof("Processing %v changes", len(changes))
 
+	var result []func()
+
 	for _, c := range changes {
 		if c.Op == AddOrUpdate {
 			switch impl := c.Resource.(type) {
 			case *VirtualServerConfiguration:
 				vsEx := lbc.createVirtualServerEx(impl.VirtualServer, impl.VirtualServerRoutes)
 
-				warnings, addOrUpdateErr := lbc.configurator.AddOrUpdateVirtualServer(vsEx)
-				lbc.updateVirtualServerStatusAndEvents(impl, warnings, addOrUpdateErr)
+				result = append(result, func() {
+					warnings, addOrUpdateErr := lbc.configurator.AddOrUpdateVirtualServer(vsEx)
+					lbc.reportAfterReload(addOrUpdateErr, func(err error) {
+						lbc.updateVirtualServerStatusAndEvents(impl, warnings, err)
+					})
+				})
 			case *IngressConfiguration:
 				if impl.IsMaster {
 					mergeableIng := lbc.createMergeableIngresses(impl)
 
-					warnings, addOrUpdateErr := lbc.configurator.AddOrUpdateMergeableIngress(mergeableIng)
-					lbc.updateMergeableIngressStatusAndEvents(impl, warnings, addOrUpdateErr)
+					result = append(result, func() {
+						warnings, addOrUpdateErr := lbc.configurator.AddOrUpdateMergeableIngress(mergeableIng)
+						lbc.reportAfterReload(addOrUpdateErr, func(err error) {
+							lbc.updateMergeableIngressStatusAndEvents(impl, warnings, err)
+						})
+					})
 				} else {
 					// for regular Ingress, validMinionPaths is nil
 					ingEx := lbc.createIngressEx(impl.Ingress, impl.ValidHosts, nil)
 
-					warnings, addOrUpdateErr := lbc.configurator.AddOrUpdateIngress(ingEx)
-					lbc.updateRegularIngressStatusAndEvents(impl, warnings, addOrUpdateErr)
+					result = append(result, func() {
+						warnings, addOrUpdateErr := lbc.configurator.AddOrUpdateIngress(ingEx)
+						lbc.reportAfterReload(addOrUpdateErr, func(err error) {
+							lbc.updateRegularIngressStatusAndEvents(impl, warnings, err)
+						})
+					})
 				}
 			case *TransportServerConfiguration:
 				tsEx := lbc.createTransportServerEx(impl.TransportServer, impl.ListenerPort)
 
-				addOrUpdateErr := lbc.configurator.AddOrUpdateTransportServer(tsEx)
-				lbc.updateTransportServerStatusAndEvents(impl, addOrUpdateErr)
+				result = append(result, func() {
+					warnings, addOrUpdateErr := lbc.configurator.AddOrUpdateTransportServer(tsEx)
+					lbc.reportAfterReload(addOrUpdateErr, func(err error) {
+						lbc.updateTransportServerStatusAndEvents(impl, warnings, err)
+					})
+				})
+			case *GatewayConfiguration:
+				vsEx := lbc.createVirtualServerEx(impl.VirtualServer, nil)
+
+				result = append(result, func() {
+					warnings, addOrUpdateErr := lbc.configurator.AddOrUpdateVirtualServer(vsEx)
+					lbc.reportAfterReload(addOrUpdateErr, func(err error) {
+						lbc.updateGatewayStatusAndEvents(impl, warnings, err)
+					})
+				})
 			}
 		} else if c.Op == Delete {
-			switch impl := c.Resource.(type) {
-			case *VirtualServerConfiguration:
-				key := getResourceKey(&impl.VirtualServer.ObjectMeta)
+			changeError := c.Error
+			resource := c.Resource
 
-				deleteErr := lbc.configurator.DeleteVirtualServer(key)
-				if deleteErr != nil {
-					glog.Errorf("Error when deleting configuration for VirtualServer %v: %v", key, deleteErr)
-				}
+			result = append(result, func() {
+				switch impl := resource.(type) {
+				case *VirtualServerConfiguration:
+					key := getResourceKey(&impl.VirtualServer.ObjectMeta)
 
-				_, vsExists, err := lbc.virtualServerLister.GetByKey(key)
-				if err != nil {
-					glog.Errorf("Error when getting VirtualServer for %v: %v", key, err)
-				}
+					deleteErr := lbc.configurator.DeleteVirtualServer(key)
+					if deleteErr != nil {
+						glog.Errorf("Error when deleting configuration for VirtualServer %v: %v", key, deleteErr)
+					}
 
-				if vsExists {
-					lbc.UpdateVirtualServerStatusAndEventsOnDelete(impl, c.Error, deleteErr)
-				}
-			case *IngressConfiguration:
-				key := getResourceKey(&impl.Ingress.ObjectMeta)
+					_, vsExists, err := lbc.virtualServerLister.GetByKey(key)
+					if err != nil {
+						glog.Errorf("Error when getting VirtualServer for %v: %v", key, err)
+					}
 
-				glog.V(2).Infof("Deleting Ingress: %v\n", key)
+					if vsExists {
+						lbc.reportAfterReload(deleteErr, func(err error) {
+							lbc.UpdateVirtualServerStatusAndEventsOnDelete(impl, changeError, err)
+						})
+					}
+				case *IngressConfiguration:
+					key := getResourceKey(&impl.Ingress.ObjectMeta)
 
-				deleteErr := lbc.configurator.DeleteIngress(key)
-				if deleteErr != nil {
-					glog.Errorf("Error when deleting configuration for Ingress %v: %v", key, deleteErr)
-				}
+					glog.V(2).Infof("Deleting Ingress: %v\n", key)
 
-				_, ingExists, err := lbc.ingressLister.GetByKeySafe(key)
-				if err != nil {
-					glog.Errorf("Error when getting Ingress for %v: %v", key, err)
-				}
+					deleteErr := lbc.configurator.DeleteIngress(key)
+					if deleteErr != nil {
+						glog.Errorf("Error when deleting configuration for Ingress %v: %v", key, deleteErr)
+					}
 
-				if ingExists {
-					lbc.UpdateIngressStatusAndEventsOnDelete(impl, c.Error, deleteErr)
-				}
-			case *TransportServerConfiguration:
-				key := getResourceKey(&impl.TransportServer.ObjectMeta)
+					_, ingExists, err := lbc.ingressLister.GetByKeySafe(key)
+					if err != nil {
+						glog.Errorf("Error when getting Ingress for %v: %v", key, err)
+					}
 
-				deleteErr := lbc.configurator.DeleteTransportServer(key)
+					if ingExists {
+						lbc.reportAfterReload(deleteErr, func(err error) {
+							lbc.UpdateIngressStatusAndEventsOnDelete(impl, changeError, err)
+						})
+					}
+				case *TransportServerConfiguration:
+					key := getResourceKey(&impl.TransportServer.ObjectMeta)
 
-				if deleteErr != nil {
-					glog.Errorf("Error when deleting configuration for TransportServer %v: %v", key, deleteErr)
-				}
+					deleteErr := lbc.configurator.DeleteTransportServer(key)
 
-				_, tsExists, err := lbc.transportServerLister.GetByKey(key)
-				if err != nil {
-					glog.Errorf("Error when getting TransportServer for %v: %v", key, err)
-				}
-				if tsExists {
-					lbc.updateTransportServerStatusAndEventsOnDelete(impl, c.Error, deleteErr)
+					if deleteErr != nil {
+						glog.Errorf("Error when deleting configuration for TransportServer %v: %v", key, deleteErr)
+					}
+
+					_, tsExists, err := lbc.transportServerLister.GetByKey(key)
+					if err != nil {
+						glog.Errorf("Error when getting TransportServer for %v: %v", key, err)
+					}
+					if tsExists {
+						lbc.reportAfterReload(deleteErr, func(err error) {
+							lbc.updateTransportServerStatusAndEventsOnDelete(impl, changeError, err)
+						})
+					}
+				case *GatewayConfiguration:
+					key := getResourceKey(&impl.VirtualServer.ObjectMeta)
+
+					deleteErr := lbc.configurator.DeleteVirtualServer(key)
+					if deleteErr != nil {
+						glog.Errorf("Error when deleting configuration for host %v of Gateway %v: %v", impl.Host, getResourceKey(&impl.Gateway.ObjectMeta), deleteErr)
+					}
+
+					// the status of the Gateway is updated by the remaining hosts or when the Gateway is synced
 				}
-			}
+			})
 		}
 	}
+
+	return result
 }
 
 // processChangesFromGlobalConfiguration processes changes that come from updates to the GlobalConfiguration resource.
@@ -1198,9 +1516,9 @@ func (lbc *LoadBalancerController) processChangesFromGlobalConfiguration(changes
 		}
 	}
 
-	updateErr := lbc.configurator.UpdateTransportServers(updatedTSExes, deletedKeys)
+	warnings, updateErr := lbc.configurator.UpdateTransportServers(updatedTSExes, deletedKeys)
 
-	lbc.updateResourcesStatusAndEvents(updatedResources, configs.Warnings{}, updateErr)
+	lbc.updateResourcesStatusAndEvents(updatedResources, warnings, updateErr)
 
 	return updateErr
 }
@@ -1374,6 +1692,8 @@ func (lbc *LoadBalancerController) processAppProtectDosProblems(problems []apppr
 }
 
 func (lbc *LoadBalancerController) updateTransportServerStatusAndEventsOnDelete(tsConfig *TransportServerConfiguration, changeError string, deleteErr error) {
+	deleteErr = lbc.configurator.GetTransportServerError(deleteErr, tsConfig.TransportServer)
+
 	eventType := api_v1.EventTypeWarning
 	eventTitle := "Rejected"
 	eventWarningMessage := ""
@@ -1414,6 +1734,8 @@ func (lbc *LoadBalancerController) updateTransportServerStatusAndEventsOnDelete(
 
 // UpdateVirtualServerStatusAndEventsOnDelete updates the virtual server status and events
 func (lbc *LoadBalancerController) UpdateVirtualServerStatusAndEventsOnDelete(vsConfig *VirtualServerConfiguration, changeError string, deleteErr error) {
+	deleteErr = lbc.configurator.GetVirtualServerError(deleteErr, vsConfig.VirtualServer)
+
 	eventType := api_v1.EventTypeWarning
 	eventTitle := "Rejected"
 	eventWarningMessage := ""
@@ -1456,6 +1778,8 @@ func (lbc *LoadBalancerController) UpdateVirtualServerStatusAndEventsOnDelete(vs
 
 // UpdateIngressStatusAndEventsOnDelete updates the ingress status and events.
 func (lbc *LoadBalancerController) UpdateIngressStatusAndEventsOnDelete(ingConfig *IngressConfiguration, changeError string, deleteErr error) {
+	deleteErr = lbc.configurator.GetIngressError(deleteErr, ingConfig.Ingress)
+
 	eventTitle := "Rejected"
 	eventWarningMessage := ""
 
@@ -1488,24 +1812,52 @@ func (lbc *LoadBalancerController) UpdateIngressStatusAndEventsOnDelete(ingConfi
 	// for each minion, a dedicated problem exists
 }
 
+// reportAfterReload calls report with the error of the configuration operation.
+// If the operation succeeded, report is called with the outcome of the NGINX reload that applies the operation,
+// which might be batched with the reloads for the operations on other resources.
+func (lbc *LoadBalancerController) reportAfterReload(operationErr error, report func(error)) {
+	if operationErr != nil {
+		report(operationErr)
+		return
+	}
+
+	lbc.configurator.AfterReload(report)
+}
+
 func (lbc *LoadBalancerController) updateResourcesStatusAndEvents(resources []Resource, warnings configs.Warnings, operationErr error) {
-	for _, r := range resources {
-		switch impl := r.(type) {
-		case *VirtualServerConfiguration:
-			lbc.updateVirtualServerStatusAndEvents(impl, warnings, operationErr)
-		case *IngressConfiguration:
-			if impl.IsMaster {
-				lbc.updateMergeableIngressStatusAndEvents(impl, warnings, operationErr)
-			} else {
-				lbc.updateRegularIngressStatusAndEvents(impl, warnings, operationErr)
+	lbc.reportAfterReload(operationErr, func(err error) {
+		for _, r := range resources {
+			switch impl := r.(type) {
+			case *VirtualServerConfiguration:
+				lbc.updateVirtualServerStatusAndEvents(impl, warnings, err)
+			case *IngressConfiguration:
+				if impl.IsMaster {
+					lbc.updateMergeableIngressStatusAndEvents(impl, warnings, err)
+				} else {
+					lbc.updateRegularIngressStatusAndEvents(impl, warnings, err)
+				}
+			case *TransportServerConfiguration:
+				lbc.updateTransportServerStatusAndEvents(impl, warnings, err)
+			case *GatewayConfiguration:
+				lbc.updateGatewayStatusAndEvents(impl, warnings, err)
 			}
-		case *TransportServerConfiguration:
-			lbc.updateTransportServerStatusAndEvents(impl, operationErr)
 		}
+	})
+}
+
+// getReloadBlockedWarning returns a warning instead of the error of the operation, if the config of the resource
+// was not applied only because NGINX rejected the config of other resources. Such a resource is not invalid.
+func getReloadBlockedWarning(operationErr error) (string, error) {
+	var blockedErr *configs.ReloadBlockedError
+	if errors.As(operationErr, &blockedErr) {
+		return blockedErr.Error(), nil
 	}
+	return "", operationErr
 }
 
 func (lbc *LoadBalancerController) updateMergeableIngressStatusAndEvents(ingConfig *IngressConfiguration, warnings configs.Warnings, operationErr error) {
+	blockedWarning, operationErr := getReloadBlockedWarning(lbc.configurator.GetIngressError(operationErr, ingConfig.Ingress))
+
 	eventType := api_v1.EventTypeNormal
 	eventTitle := "AddedOrUpdated"
 	eventWarningMessage := ""
@@ -1525,6 +1877,13 @@ func (lbc *LoadBalancerController) updateMergeableIngressStatusAndEvents(ingConf
 		eventWarningSuffix = "; "
 	}
 
+	if blockedWarning != "" {
+		eventType = api_v1.EventTypeWarning
+		eventTitle = "AddedOrUpdatedWithWarning"
+		eventWarningMessage = fmt.Sprintf("%s%swith warning(s): %v", eventWarningMessage, eventWarningSuffix, blockedWarning)
+		eventWarningSuffix = "; "
+	}
+
 	if operationErr != nil {
 		eventType = api_v1.EventTypeWarning
 		eventTitle = "AddedOrUpdatedWithError"
@@ -1561,6 +1920,13 @@ func (lbc *LoadBalancerController) updateMergeableIngressStatusAndEvents(ingConf
 			minionEventWarningSuffix = "; "
 		}
 
+		if blockedWarning != "" {
+			minionEventType = api_v1.EventTypeWarning
+			minionEventTitle = "AddedOrUpdatedWithWarning"
+			minionEventWarningMessage = fmt.Sprintf("%s%swith warning(s): %v", minionEventWarningMessage, minionEventWarningSuffix, blockedWarning)
+			minionEventWarningSuffix = "; "
+		}
+
 		if operationErr != nil {
 			minionEventType = api_v1.EventTypeWarning
 			minionEventTitle = "AddedOrUpdatedWithError"
@@ -1591,6 +1957,8 @@ func (lbc *LoadBalancerController) updateMergeableIngressStatusAndEvents(ingConf
 }
 
 func (lbc *LoadBalancerController) updateRegularIngressStatusAndEvents(ingConfig *IngressConfiguration, warnings configs.Warnings, operationErr error) {
+	blockedWarning, operationErr := getReloadBlockedWarning(lbc.configurator.GetIngressError(operationErr, ingConfig.Ingress))
+
 	eventType := api_v1.EventTypeNormal
 	eventTitle := "AddedOrUpdated"
 	eventWarningMessage := ""
@@ -1607,6 +1975,12 @@ func (lbc *LoadBalancerController) updateRegularIngressStatusAndEvents(ingConfig
 		eventWarningMessage = fmt.Sprintf("%s; with warning(s): %v", eventWarningMessage, formatWarningMessages(messages))
 	}
 
+	if blockedWarning != "" {
+		eventType = api_v1.EventTypeWarning
+		eventTitle = "AddedOrUpdatedWithWarning"
+		eventWarningMessage = fmt.Sprintf("%s; with warning(s): %v", eventWarningMessage, blockedWarning)
+	}
+
 	if operationErr != nil {
 		eventType = api_v1.EventTypeWarning
 		eventTitle = "AddedOrUpdatedWithError"
@@ -1624,7 +1998,9 @@ func (lbc *LoadBalancerController) updateRegularIngressStatusAndEvents(ingConfig
 	}
 }
 
-func (lbc *LoadBalancerController) updateTransportServerStatusAndEvents(tsConfig *TransportServerConfiguration, operationErr error) {
+func (lbc *LoadBalancerController) updateTransportServerStatusAndEvents(tsConfig *TransportServerConfiguration, warnings configs.Warnings, operationErr error) {
+	blockedWarning, operationErr := getReloadBlockedWarning(lbc.configurator.GetTransportServerError(operationErr, tsConfig.TransportServer))
+
 	eventTitle := "AddedOrUpdated"
 	eventType := api_v1.EventTypeNormal
 	eventWarningMessage := ""
@@ -1637,6 +2013,20 @@ func (lbc *LoadBalancerController) updateTransportServerStatusAndEvents(tsConfig
 		state = conf_v1.StateWarning
 	}
 
+	if messages, ok := warnings[tsConfig.TransportServer]; ok {
+		eventType = api_v1.EventTypeWarning
+		eventTitle = "AddedOrUpdatedWithWarning"
+		eventWarningMessage = fmt.Sprintf("%s; with warning(s): %v", eventWarningMessage, formatWarningMessages(messages))
+		state = conf_v1.StateWarning
+	}
+
+	if blockedWarning != "" {
+		eventType = api_v1.EventTypeWarning
+		eventTitle = "AddedOrUpdatedWithWarning"
+		eventWarningMessage = fmt.Sprintf("%s; with warning(s): %v", eventWarningMessage, blockedWarning)
+		state = conf_v1.StateWarning
+	}
+
 	if operationErr != nil {
 		eventType = api_v1.EventTypeWarning
 		eventTitle = "AddedOrUpdatedWithError"
@@ -1656,6 +2046,8 @@ func (lbc *LoadBalancerController) updateTransportServerStatusAndEvents(tsConfig
 }
 
 func (lbc *LoadBalancerController) updateVirtualServerStatusAndEvents(vsConfig *VirtualServerConfiguration, warnings configs.Warnings, operationErr error) {
+	blockedWarning, operationErr := getReloadBlockedWarning(lbc.configurator.GetVirtualServerError(operationErr, vsConfig.VirtualServer))
+
 	eventType := api_v1.EventTypeNormal
 	eventTitle := "AddedOrUpdated"
 	eventWarningMessage := ""
@@ -1675,6 +2067,13 @@ func (lbc *LoadBalancerController) updateVirtualServerStatusAndEvents(vsConfig *
 		state = conf_v1.StateWarning
 	}
 
+	if blockedWarning != "" {
+		eventType = api_v1.EventTypeWarning
+		eventTitle = "AddedOrUpdatedWithWarning"
+		eventWarningMessage = fmt.Sprintf("%s; with warning(s): %v", eventWarningMessage, blockedWarning)
+		state = conf_v1.StateWarning
+	}
+
 	if operationErr != nil {
 		eventType = api_v1.EventTypeWarning
 		eventTitle = "AddedOrUpdatedWithError"
@@ -1705,6 +2104,13 @@ func (lbc *LoadBalancerController) updateVirtualServerStatusAndEvents(vsConfig *
 			vsrState = conf_v1.StateWarning
 		}
 
+		if blockedWarning != "" {
+			vsrEventType = api_v1.EventTypeWarning
+			vsrEventTitle = "AddedOrUpdatedWithWarning"
+			vsrEventWarningMessage = fmt.Sprintf("%s with warning(s): %v", vsrEventWarningMessage, blockedWarning)
+			vsrState = conf_v1.StateWarning
+		}
+
 		if operationErr != nil {
 			vsrEventType = api_v1.EventTypeWarning
 			vsrEventTitle = "AddedOrUpdatedWithError"
@@ -1736,24 +2142,144 @@ func (lbc *LoadBalancerController) syncVirtualServerRoute(task task) {
 	var changes []ResourceChange
 	var problems []ConfigurationProblem
 
-	if !exists {
-		glog.V(2).Infof("Deleting VirtualServerRoute: %v\n", key)
+	turn := lbc.changeSequencer.change(func() {
+		if !exists {
+			glog.V(2).Infof("Deleting VirtualServerRoute: %v\n", key)
+
+			changes, problems = lbc.configuration.DeleteVirtualServerRoute(key)
+		} else {
+			glog.V(2).Infof("Adding or Updating VirtualServerRoute: %v\n", key)
+
+			vsr := obj.(*conf_v1.VirtualServerRoute)
+			changes, problems = lbc.configuration.AddOrUpdateVirtualServerRoute(vsr)
+		}
+	})
+
+	lbc.processChangesInTurn(changes, problems, turn)
+}
+
+func (lbc *LoadBalancerController) syncGatewayClass(task task) {
+	key := task.Key
+	obj, exists, err := lbc.gatewayClassLister.GetByKey(key)
+	if err != nil {
+		lbc.syncQueue.Requeue(task, err)
+		return
+	}
+
+	if exists {
+		gc := obj.(*gateway_v1alpha2.GatewayClass)
+		if gc.Spec.ControllerName != GatewayControllerName {
+			return
+		}
+
+		glog.V(2).Infof("Adding or Updating GatewayClass: %v\n", key)
+
+		if lbc.reportCustomResourceStatusEnabled() {
+			err := lbc.statusUpdater.UpdateGatewayClassStatus(gc)
+			if err != nil {
+				glog.Errorf("Error when updating the status for GatewayClass %v: %v", gc.Name, err)
+			}
+		}
+	}
+
+	// the Gateways of the class either became ours or not ours anymore
+	for _, obj := range lbc.gatewayLister.List() {
+		gw := obj.(*gateway_v1alpha2.Gateway)
+		if string(gw.Spec.GatewayClassName) == key {
+			lbc.AddSyncQueue(gw)
+		}
+	}
+}
+
+// hasCorrectGatewayClass checks if the GatewayClass of the Gateway is handled by the Ingress Controller.
+func (lbc *LoadBalancerController) hasCorrectGatewayClass(gw *gateway_v1alpha2.Gateway) bool {
+	obj, exists, err := lbc.gatewayClassLister.GetByKey(string(gw.Spec.GatewayClassName))
+	if err != nil {
+		glog.Errorf("Error when getting GatewayClass %v: %v", gw.Spec.GatewayClassName, err)
+		return false
+	}
+
+	if !exists {
+		return false
+	}
+
+	return obj.(*gateway_v1alpha2.GatewayClass).Spec.ControllerName == GatewayControllerName
+}
 
-		changes, problems = lbc.configuration.DeleteVirtualServerRoute(key)
+func (lbc *LoadBalancerController) syncGateway(task task) {
+	key := task.Key
+	obj, exists, err := lbc.gatewayLister.GetByKey(key)
+	if err != nil {
+		lbc.syncQueue.Requeue(task, err)
+		return
+	}
+
+	var changes []ResourceChange
+	var problems []ConfigurationProblem
+
+	if !exists || !lbc.hasCorrectGatewayClass(obj.(*gateway_v1alpha2.Gateway)) {
+		glog.V(2).Infof("Deleting Gateway: %v\n", key)
+
+		changes, problems = lbc.configuration.DeleteGateway(key)
 	} else {
-		glog.V(2).Infof("Adding or Updating VirtualServerRoute: %v\n", key)
+		glog.V(2).Infof("Adding or Updating Gateway: %v\n", key)
 
-		vsr := obj.(*conf_v1.VirtualServerRoute)
-		changes, problems = lbc.configuration.AddOrUpdateVirtualServerRoute(vsr)
+		gw := obj.(*gateway_v1alpha2.Gateway)
+		changes, problems = lbc.configuration.AddOrUpdateGateway(gw)
 	}
 
 	lbc.processChanges(changes)
 	lbc.processProblems(problems)
+
+	if exists && lbc.hasCorrectGatewayClass(obj.(*gateway_v1alpha2.Gateway)) {
+		lbc.updateGatewayWithoutHostsStatus(obj.(*gateway_v1alpha2.Gateway))
+	}
 }
 
-func (lbc *LoadBalancerController) syncIngress(task task) {
+// updateGatewayWithoutHostsStatus reports the status of a Gateway that doesn't have any hosts configured.
+// The status of a Gateway with hosts is reported when the configuration for its hosts is applied.
+func (lbc *LoadBalancerController) updateGatewayWithoutHostsStatus(gw *gateway_v1alpha2.Gateway) {
+	if !lbc.reportCustomResourceStatusEnabled() {
+		return
+	}
+
+	key := getResourceKey(&gw.ObjectMeta)
+
+	for _, r := range lbc.configuration.GetResourcesWithFilter(resourceFilter{Gateways: true}) {
+		if getResourceKey(r.GetObjectMeta()) == key {
+			return
+		}
+	}
+
+	err := lbc.statusUpdater.UpdateGatewayStatus(gw, false, "ListenersNotReady", "No HTTPRoutes are attached to the Gateway", getGatewayListenerStatuses(gw))
+	if err != nil {
+		glog.Errorf("Error when updating the status for Gateway %v: %v", key, err)
+	}
+}
+
+// updateGatewayAPIStatus updates the status of the GatewayClasses handled by the Ingress Controller
+// and the addresses of the Gateways with configured hosts.
+func (lbc *LoadBalancerController) updateGatewayAPIStatus() error {
+	for _, obj := range lbc.gatewayClassLister.List() {
+		gc := obj.(*gateway_v1alpha2.GatewayClass)
+		if gc.Spec.ControllerName != GatewayControllerName {
+			continue
+		}
+
+		err := lbc.statusUpdater.UpdateGatewayClassStatus(gc)
+		if err != nil {
+			return err
+		}
+	}
+
+	gateways := lbc.configuration.GetResourcesWithFilter(resourceFilter{Gateways: true})
+
+	return lbc.statusUpdater.UpdateExternalEndpointsForResources(gateways)
+}
+
+func (lbc *LoadBalancerController) syncHTTPRoute(task task) {
 	key := task.Key
-	ing, ingExists, err := lbc.ingressLister.GetByKeySafe(key)
+	obj, exists, err := lbc.httpRouteLister.GetByKey(key)
 	if err != nil {
 		lbc.syncQueue.Requeue(task, err)
 		return
@@ -1762,20 +2288,102 @@ func (lbc *LoadBalancerController) syncIngress(task task) {
 	var changes []ResourceChange
 	var problems []ConfigurationProblem
 
-	if !ingExists {
-		glog.V(2).Infof("Deleting Ingress: %v\n", key)
+	if !exists {
+		glog.V(2).Infof("Deleting HTTPRoute: %v\n", key)
 
-		changes, problems = lbc.configuration.DeleteIngress(key)
+		changes, problems = lbc.configuration.DeleteHTTPRoute(key)
 	} else {
-		glog.V(2).Infof("Adding or Updating Ingress: %v\n", key)
+		glog.V(2).Infof("Adding or Updating HTTPRoute: %v\n", key)
 
-		changes, problems = lbc.configuration.AddOrUpdateIngress(ing)
+		route := obj.(*gateway_v1alpha2.HTTPRoute)
+		changes, problems = lbc.configuration.AddOrUpdateHTTPRoute(route)
 	}
 
 	lbc.processChanges(changes)
 	lbc.processProblems(problems)
 }
 
+func (lbc *LoadBalancerController) updateGatewayStatusAndEvents(gwConfig *GatewayConfiguration, warnings configs.Warnings, operationErr error) {
+	blockedWarning, operationErr := getReloadBlockedWarning(lbc.configurator.GetVirtualServerError(operationErr, gwConfig.VirtualServer))
+
+	eventType := api_v1.EventTypeNormal
+	eventTitle := "AddedOrUpdated"
+	eventWarningMessage := ""
+	ready := true
+	reason := string(gateway_v1alpha2.GatewayReasonReady)
+
+	if len(gwConfig.Warnings) > 0 {
+		eventType = api_v1.EventTypeWarning
+		eventTitle = "AddedOrUpdatedWithWarning"
+		eventWarningMessage = fmt.Sprintf("with warning(s): %s", formatWarningMessages(gwConfig.Warnings))
+	}
+
+	if messages, ok := warnings[gwConfig.VirtualServer]; ok {
+		eventType = api_v1.EventTypeWarning
+		eventTitle = "AddedOrUpdatedWithWarning"
+		eventWarningMessage = fmt.Sprintf("%s; with warning(s): %v", eventWarningMessage, formatWarningMessages(messages))
+	}
+
+	if blockedWarning != "" {
+		eventType = api_v1.EventTypeWarning
+		eventTitle = "AddedOrUpdatedWithWarning"
+		eventWarningMessage = fmt.Sprintf("%s; with warning(s): %v", eventWarningMessage, blockedWarning)
+	}
+
+	if operationErr != nil {
+		eventType = api_v1.EventTypeWarning
+		eventTitle = "AddedOrUpdatedWithError"
+		eventWarningMessage = fmt.Sprintf("%s; but was not applied: %v", eventWarningMessage, operationErr)
+		ready = false
+		reason = string(gateway_v1alpha2.GatewayReasonListenersNotReady)
+	}
+
+	msg := fmt.Sprintf("Configuration for host %v of %v was added or updated %s", gwConfig.Host, getResourceKey(&gwConfig.Gateway.ObjectMeta), eventWarningMessage)
+	lbc.recorder.Eventf(gwConfig.Gateway, eventType, eventTitle, msg)
+
+	if !lbc.reportCustomResourceStatusEnabled() {
+		return
+	}
+
+	err := lbc.statusUpdater.UpdateGatewayStatus(gwConfig.Gateway, ready, reason, msg, gwConfig.ListenerStatuses)
+	if err != nil {
+		glog.Errorf("Error when updating the status for Gateway %v/%v: %v", gwConfig.Gateway.Namespace, gwConfig.Gateway.Name, err)
+	}
+
+	for _, route := range gwConfig.HTTPRoutes {
+		err := lbc.statusUpdater.UpdateHTTPRouteStatus(route, gwConfig.Gateway, gwConfig.RouteRefErrors[getResourceKey(&route.ObjectMeta)])
+		if err != nil {
+			glog.Errorf("Error when updating the status for HTTPRoute %v/%v: %v", route.Namespace, route.Name, err)
+		}
+	}
+}
+
+func (lbc *LoadBalancerController) syncIngress(task task) {
+	key := task.Key
+	ing, ingExists, err := lbc.ingressLister.GetByKeySafe(key)
+	if err != nil {
+		lbc.syncQueue.Requeue(task, err)
+		return
+	}
+
+	var changes []ResourceChange
+	var problems []ConfigurationProblem
+
+	turn := lbc.changeSequencer.change(func() {
+		if !ingExists {
+			glog.V(2).Infof("Deleting Ingress: %v\n", key)
+
+			changes, problems = lbc.configuration.DeleteIngress(key)
+		} else {
+			glog.V(2).Infof("Adding or Updating Ingress: %v\n", key)
+
+			changes, problems = lbc.configuration.AddOrUpdateIngress(ing)
+		}
+	})
+
+	lbc.processChangesInTurn(changes, problems, turn)
+}
+
 func (lbc *LoadBalancerController) updateIngressMetrics() {
 	counters := lbc.configurator.GetIngressCounts()
 	for nType, count := range counters {
@@ -1833,7 +2441,7 @@ func (lbc *LoadBalancerController) syncService(task task) {
 		}
 
 		if lbc.areCustomResourcesEnabled && lbc.reportCustomResourceStatusEnabled() {
-			virtualServers := lbc.configuration.GetResourcesWithFilter(resourceFilter{VirtualServers: true})
+			virtualServers := lbc.configuration.GetResourcesWithFilter(resourceFilter{VirtualServers: true, Gateways: true})
 
 			glog.V(3).Infof("Updating status for %v VirtualServers", len(virtualServers))
 
@@ -1853,6 +2461,11 @@ func (lbc *LoadBalancerController) syncService(task task) {
 
 	resources := lbc.configuration.FindResourcesForService(namespace, name)
 
+	if lbc.areCustomResourcesEnabled {
+		resources = append(resources, lbc.findResourcesForExternalAuthService(namespace, name)...)
+		resources = removeDuplicateResources(resources)
+	}
+
 	if len(resources) == 0 {
 		return
 	}
@@ -2125,13 +2738,19 @@ func (lbc *LoadBalancerController) updatePoliciesStatus() error {
 	for _, obj := range lbc.policyLister.List() {
 		pol := obj.(*conf_v1.Policy)
 
-		err := validation.ValidatePolicy(pol, lbc.isNginxPlus, lbc.enableOIDC, lbc.appProtectEnabled)
+		err := validation.ValidatePolicy(pol, lbc.policyValidationOptions())
 		if err != nil {
 			msg := fmt.Sprintf("Policy %v/%v is invalid and was rejected: %v", pol.Namespace, pol.Name, err)
 			err = lbc.statusUpdater.UpdatePolicyStatus(pol, conf_v1.StateInvalid, "Rejected", msg)
 			if err != nil {
 				allErrs = append(allErrs, err)
 			}
+		} else if warning := getPolicyWarningForTransportServers(pol, lbc.configuration.FindResourcesForPolicy(pol.Namespace, pol.Name)); warning != "" {
+			msg := fmt.Sprintf("Policy %v/%v was added or updated with warning(s): %s", pol.Namespace, pol.Name, warning)
+			err = lbc.statusUpdater.UpdatePolicyStatus(pol, conf_v1.StateWarning, "AddedOrUpdatedWithWarning", msg)
+			if err != nil {
+				allErrs = append(allErrs, err)
+			}
 		} else {
 			msg := fmt.Sprintf("Policy %v/%v was added or updated", pol.Namespace, pol.Name)
 			err = lbc.statusUpdater.UpdatePolicyStatus(pol, conf_v1.StateValid, "AddedOrUpdated", msg)
@@ -2435,6 +3054,7 @@ func (lbc *LoadBalancerController) createVirtualServerEx(virtualServer *conf_v1.
 		ApPolRefs:      make(map[string]*unstructured.Unstructured),
 		LogConfRefs:    make(map[string]*unstructured.Unstructured),
 		DosProtectedEx: make(map[string]*configs.DosEx),
+		SplitWeights:   lbc.getSplitWeights(virtualServer),
 	}
 
 	if virtualServer.Spec.TLS != nil && virtualServer.Spec.TLS.Secret != "" {
@@ -2461,6 +3081,10 @@ func (lbc *LoadBalancerController) createVirtualServerEx(virtualServer *conf_v1.
 	if err != nil {
 		glog.Warningf("Error getting Basic Auth secrets for VirtualServer %v/%v: %v", virtualServer.Namespace, virtualServer.Name, err)
 	}
+	err = lbc.addAPIKeySecretRefs(virtualServerEx.SecretRefs, policies)
+	if err != nil {
+		glog.Warningf("Error getting API Key secrets for VirtualServer %v/%v: %v", virtualServer.Namespace, virtualServer.Name, err)
+	}
 	err = lbc.addIngressMTLSSecretRefs(virtualServerEx.SecretRefs, policies)
 	if err != nil {
 		glog.Warningf("Error getting IngressMTLS secret for VirtualServer %v/%v: %v", virtualServer.Namespace, virtualServer.Name, err)
@@ -2555,6 +3179,10 @@ func (lbc *LoadBalancerController) createVirtualServerEx(virtualServer *conf_v1.
 		if err != nil {
 			glog.Warningf("Error getting Basic Auth secrets for VirtualServer %v/%v: %v", virtualServer.Namespace, virtualServer.Name, err)
 		}
+		err = lbc.addAPIKeySecretRefs(virtualServerEx.SecretRefs, vsRoutePolicies)
+		if err != nil {
+			glog.Warningf("Error getting API Key secrets for VirtualServer %v/%v: %v", virtualServer.Namespace, virtualServer.Name, err)
+		}
 		err = lbc.addEgressMTLSSecretRefs(virtualServerEx.SecretRefs, vsRoutePolicies)
 		if err != nil {
 			glog.Warningf("Error getting EgressMTLS secrets for VirtualServer %v/%v: %v", virtualServer.Namespace, virtualServer.Name, err)
@@ -2596,6 +3224,10 @@ func (lbc *LoadBalancerController) createVirtualServerEx(virtualServer *conf_v1.
 			if err != nil {
 				glog.Warningf("Error getting Basic Auth secrets for VirtualServerRoute %v/%v: %v", vsr.Namespace, vsr.Name, err)
 			}
+			err = lbc.addAPIKeySecretRefs(virtualServerEx.SecretRefs, vsrSubroutePolicies)
+			if err != nil {
+				glog.Warningf("Error getting API Key secrets for VirtualServerRoute %v/%v: %v", vsr.Namespace, vsr.Name, err)
+			}
 
 			err = lbc.addEgressMTLSSecretRefs(virtualServerEx.SecretRefs, vsrSubroutePolicies)
 			if err != nil {
@@ -2665,6 +3297,27 @@ func (lbc *LoadBalancerController) createVirtualServerEx(virtualServer *conf_v1.
 		}
 	}
 
+	for _, pol := range policies {
+		if pol.Spec.ExternalAuth == nil {
+			continue
+		}
+
+		svcName := pol.Spec.ExternalAuth.AuthService
+		port := uint16(pol.Spec.ExternalAuth.AuthServicePort)
+		endpointsKey := configs.GenerateEndpointsKey(pol.Namespace, svcName, nil, port)
+
+		podEndps, external, err := lbc.getEndpointsForUpstream(pol.Namespace, svcName, port)
+		if err != nil {
+			glog.Warningf("Error getting Endpoints for the external authorization service of Policy %v/%v: %v", pol.Namespace, pol.Name, err)
+		}
+
+		if err == nil && external && lbc.isNginxPlus {
+			externalNameSvcs[configs.GenerateExternalNameSvcKey(pol.Namespace, svcName)] = true
+		}
+
+		endpoints[endpointsKey] = getIPAddressesFromEndpoints(podEndps)
+	}
+
 	virtualServerEx.Endpoints = endpoints
 	virtualServerEx.VirtualServerRoutes = virtualServerRoutes
 	virtualServerEx.ExternalNameSvcs = externalNameSvcs
@@ -2691,7 +3344,7 @@ func (lbc *LoadBalancerController) getAllPolicies() []*conf_v1.Policy {
 	for _, obj := range lbc.policyLister.List() {
 		pol := obj.(*conf_v1.Policy)
 
-		err := validation.ValidatePolicy(pol, lbc.isNginxPlus, lbc.enableOIDC, lbc.appProtectEnabled)
+		err := validation.ValidatePolicy(pol, lbc.policyValidationOptions())
 		if err != nil {
 			glog.V(3).Infof("Skipping invalid Policy %s/%s: %v", pol.Namespace, pol.Name, err)
 			continue
@@ -2733,7 +3386,7 @@ func (lbc *LoadBalancerController) getPolicies(policies []conf_v1.PolicyReferenc
 			continue
 		}
 
-		err = validation.ValidatePolicy(policy, lbc.isNginxPlus, lbc.enableOIDC, lbc.appProtectEnabled)
+		err = validation.ValidatePolicy(policy, lbc.policyValidationOptions())
 		if err != nil {
 			errors = append(errors, fmt.Errorf("Policy %s is invalid: %w", policyKey, err))
 			continue
@@ -2747,7 +3400,7 @@ func (lbc *LoadBalancerController) getPolicies(policies []conf_v1.PolicyReferenc
 
 func (lbc *LoadBalancerController) addJWTSecretRefs(secretRefs map[string]*secrets.SecretReference, policies []*conf_v1.Policy) error {
 	for _, pol := range policies {
-		if pol.Spec.JWTAuth == nil {
+		if pol.Spec.JWTAuth == nil || pol.Spec.JWTAuth.JwksURI != "" {
 			continue
 		}
 
@@ -2783,6 +3436,25 @@ func (lbc *LoadBalancerController) addBasicSecretRefs(secretRefs map[string]*sec
 	return nil
 }
 
+func (lbc *LoadBalancerController) addAPIKeySecretRefs(secretRefs map[string]*secrets.SecretReference, policies []*conf_v1.Policy) error {
+	for _, pol := range policies {
+		if pol.Spec.APIKey == nil {
+			continue
+		}
+
+		secretKey := fmt.Sprintf("%v/%v", pol.Namespace, pol.Spec.APIKey.ClientSecret)
+		secretRef := lbc.secretStore.GetSecret(secretKey)
+
+		secretRefs[secretKey] = secretRef
+
+		if secretRef.Error != nil {
+			return secretRef.Error
+		}
+	}
+
+	return nil
+}
+
 func (lbc *LoadBalancerController) addIngressMTLSSecretRefs(secretRefs map[string]*secrets.SecretReference, policies []*conf_v1.Policy) error {
 	for _, pol := range policies {
 		if pol.Spec.IngressMTLS == nil {
@@ -2906,6 +3578,29 @@ func (lbc *LoadBalancerController) addWAFPolicyRefs(
 	return nil
 }
 
+// findResourcesForExternalAuthService finds the resources that reference the service through externalAuth policies.
+func (lbc *LoadBalancerController) findResourcesForExternalAuthService(svcNamespace string, svcName string) []Resource {
+	var resources []Resource
+
+	for _, pol := range findPoliciesForService(lbc.getAllPolicies(), svcNamespace, svcName) {
+		resources = append(resources, lbc.configuration.FindResourcesForPolicy(pol.Namespace, pol.Name)...)
+	}
+
+	return resources
+}
+
+func findPoliciesForService(policies []*conf_v1.Policy, svcNamespace string, svcName string) []*conf_v1.Policy {
+	var res []*conf_v1.Policy
+
+	for _, pol := range policies {
+		if pol.Spec.ExternalAuth != nil && pol.Spec.ExternalAuth.AuthService == svcName && pol.Namespace == svcNamespace {
+			res = append(res, pol)
+		}
+	}
+
+	return res
+}
+
 func (lbc *LoadBalancerController) getPoliciesForSecret(secretNamespace string, secretName string) []*conf_v1.Policy {
 	return findPoliciesForSecret(lbc.getAllPolicies(), secretNamespace, secretName)
 }
@@ -2926,6 +3621,8 @@ func findPoliciesForSecret(policies []*conf_v1.Policy, secretNamespace string, s
 			res = append(res, pol)
 		} else if pol.Spec.OIDC != nil && pol.Spec.OIDC.ClientSecret == secretName && pol.Namespace == secretNamespace {
 			res = append(res, pol)
+		} else if pol.Spec.APIKey != nil && pol.Spec.APIKey.ClientSecret == secretName && pol.Namespace == secretNamespace {
+			res = append(res, pol)
 		}
 	}
 
@@ -2998,12 +3695,96 @@ func (lbc *LoadBalancerController) createTransportServerEx(transportServer *conf
 		}
 	}
 
+	secretRefs := make(map[string]*secrets.SecretReference)
+
+	for _, secretName := range getSecretsForTransportServer(transportServer) {
+		secretKey := transportServer.Namespace + "/" + secretName
+
+		secretRef := lbc.secretStore.GetSecret(secretKey)
+		if secretRef.Error != nil {
+			glog.Warningf("Error trying to get the secret %v for TransportServer %v: %v", secretKey, transportServer.Name, secretRef.Error)
+		}
+
+		secretRefs[secretKey] = secretRef
+	}
+
+	policies, policyErrors := lbc.getPolicies(getPolicyReferencesForTransportServer(transportServer), transportServer.Namespace)
+	for _, err := range policyErrors {
+		glog.Warningf("Error getting policy for TransportServer %s/%s: %v", transportServer.Namespace, transportServer.Name, err)
+	}
+
 	return &configs.TransportServerEx{
 		ListenerPort:    listenerPort,
 		TransportServer: transportServer,
 		Endpoints:       endpoints,
 		PodsByIP:        podsByIP,
+		SecretRefs:      secretRefs,
+		Policies:        createPolicyMap(policies),
+	}
+}
+
+// getPolicyWarningForTransportServers returns a warning when a policy of a type that TransportServers don't support
+// is referenced by any of the TransportServers among the resources, or an empty string otherwise.
+func getPolicyWarningForTransportServers(pol *conf_v1.Policy, resources []Resource) string {
+	if isPolicySupportedInTransportServer(pol) {
+		return ""
+	}
+
+	var tsKeys []string
+	for _, r := range resources {
+		if tsConfig, ok := r.(*TransportServerConfiguration); ok {
+			tsKeys = append(tsKeys, getResourceKey(&tsConfig.TransportServer.ObjectMeta))
+		}
+	}
+
+	if len(tsKeys) == 0 {
+		return ""
 	}
+
+	return fmt.Sprintf("the policy is not supported in TransportServer(s) %s", strings.Join(tsKeys, ", "))
+}
+
+func isPolicySupportedInTransportServer(pol *conf_v1.Policy) bool {
+	return pol.Spec.AccessControl != nil || pol.Spec.ConnectionLimit != nil || pol.Spec.Bandwidth != nil
+}
+
+// getPolicyReferencesForTransportServer returns the policy references of a TransportServer as the references of VirtualServers,
+// so that both resources can share the lookup of the policies.
+func getPolicyReferencesForTransportServer(ts *conf_v1alpha1.TransportServer) []conf_v1.PolicyReference {
+	var refs []conf_v1.PolicyReference
+
+	for _, p := range ts.Spec.Policies {
+		refs = append(refs, conf_v1.PolicyReference{
+			Name:      p.Name,
+			Namespace: p.Namespace,
+		})
+	}
+
+	return refs
+}
+
+// getSecretsForTransportServer returns the names of the secrets referenced by the TLS of a TransportServer and of its upstreams.
+func getSecretsForTransportServer(ts *conf_v1alpha1.TransportServer) []string {
+	var names []string
+
+	if ts.Spec.TLS != nil {
+		names = append(names, ts.Spec.TLS.Secret, ts.Spec.TLS.ClientCertSecret)
+	}
+
+	for _, u := range ts.Spec.Upstreams {
+		if u.TLS != nil && u.TLS.Enable {
+			names = append(names, u.TLS.TLSSecret, u.TLS.TrustedCertSecret)
+		}
+	}
+
+	var result []string
+	for _, name := range names {
+		if name != "" {
+			result = append(result, name)
+		}
+	}
+
+	return result
 }
 
 func (lbc *LoadBalancerController) getEndpointsForUpstream(namespace string, upstreamService string, upstreamPort uint16) (endps []podEndpoint, isExternal bool, err error) {
@@ -3065,6 +3846,17 @@ func (lbc *LoadBalancerController) getEndpointsForServiceWithSubselector(targetP
 		return nil, fmt.Errorf("Error getting pods in namespace %v that match the selector %v: %w", svc.Namespace, labels.Merge(svc.Spec.Selector, subselector), err)
 	}
 
+	if lbc.isEndpointSlicesEnabled {
+		endpointSlices, err := lbc.endpointSliceLister.GetServiceEndpointSlices(svc)
+		if err != nil {
+			glog.V(3).Infof("Error getting endpoint slices for service %s from the cache: %v", svc.Name, err)
+			return nil, err
+		}
+
+		endps = getEndpointsBySubselectedPodsFromEndpointSlices(targetPort, pods, endpointSlices)
+		return endps, nil
+	}
+
 	svcEps, err := lbc.endpointLister.GetServiceEndpoints(svc)
 	if err != nil {
 		glog.V(3).Infof("Error getting endpoints for service %s from the cache: %v", svc.Name, err)
@@ -3075,6 +3867,107 @@ func (lbc *LoadBalancerController) getEndpointsForServiceWithSubselector(targetP
 	return endps, nil
 }
 
+func getEndpointsBySubselectedPodsFromEndpointSlices(targetPort int32, pods []*api_v1.Pod, endpointSlices []*discovery_v1.EndpointSlice) (endps []podEndpoint) {
+	sliceEndps := getEndpointsFromEndpointSlices(endpointSlices, targetPort)
+	for _, pod := range pods {
+		addr := ipv6SafeAddrPort(pod.Status.PodIP, targetPort)
+		for _, sliceEndp := range sliceEndps {
+			if sliceEndp.Address != addr {
+				continue
+			}
+			ownerType, ownerName := getPodOwnerTypeAndName(pod)
+			podEnd := podEndpoint{
+				Address: addr,
+				PodName: sliceEndp.PodName,
+				MeshPodOwner: configs.MeshPodOwner{
+					OwnerType: ownerType,
+					OwnerName: ownerName,
+				},
+			}
+			endps = append(endps, podEnd)
+		}
+	}
+	return endps
+}
+
+// getEndpointsFromEndpointSlices merges the endpoints with the target port from the EndpointSlices of a service.
+// Ready endpoints are preferred. If none of the endpoints are ready, the endpoints that are still serving while
+// terminating are returned instead, so that the traffic is not dropped until the new endpoints become ready.
+// The returned endpoints have only the Address and PodName fields set and are sorted by the address.
+func getEndpointsFromEndpointSlices(endpointSlices []*discovery_v1.EndpointSlice, targetPort int32) []podEndpoint {
+	var ready, terminating []podEndpoint
+	seen := make(map[string]bool)
+
+	for _, endpointSlice := range endpointSlices {
+		if endpointSlice.AddressType != discovery_v1.AddressTypeIPv4 && endpointSlice.AddressType != discovery_v1.AddressTypeIPv6 {
+			continue
+		}
+
+		for _, port := range endpointSlice.Ports {
+			if port.Port == nil || *port.Port != targetPort {
+				continue
+			}
+
+			for _, endpoint := range endpointSlice.Endpoints {
+				isReady := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+				isServing := isReady
+				if endpoint.Conditions.Serving != nil {
+					isServing = *endpoint.Conditions.Serving
+				}
+				isTerminating := endpoint.Conditions.Terminating != nil && *endpoint.Conditions.Terminating
+
+				if !isReady && !(isServing && isTerminating) {
+					continue
+				}
+
+				for _, address := range endpoint.Addresses {
+					addr := ipv6SafeAddrPort(address, targetPort)
+					if seen[addr] {
+						continue
+					}
+					seen[addr] = true
+
+					podEnd := podEndpoint{
+						Address: addr,
+					}
+					if endpoint.TargetRef != nil && endpoint.TargetRef.Kind == "Pod" {
+						podEnd.PodName = endpoint.TargetRef.Name
+					}
+
+					if isReady {
+						ready = append(ready, podEnd)
+					} else {
+						terminating = append(terminating, podEnd)
+					}
+				}
+			}
+		}
+	}
+
+	result := ready
+	if len(result) == 0 {
+		result = terminating
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Address < result[j].Address
+	})
+
+	return result
+}
+
+// hasEndpointSlicePort checks if any of the EndpointSlices of a service exposes the target port.
+func hasEndpointSlicePort(endpointSlices []*discovery_v1.EndpointSlice, targetPort int32) bool {
+	for _, endpointSlice := range endpointSlices {
+		for _, port := range endpointSlice.Ports {
+			if port.Port != nil && *port.Port == targetPort {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func getEndpointsBySubselectedPods(targetPort int32, pods []*api_v1.Pod, svcEps api_v1.Endpoints) (endps []podEndpoint) {
 	for _, pod := range pods {
 		for _, subset := range svcEps.Subsets {
@@ -3175,17 +4068,23 @@ func (lbc *LoadBalancerController) getExternalEndpointsForIngressBackend(backend
 }
 
 func (lbc *LoadBalancerController) getEndpointsForIngressBackend(backend *networking.IngressBackend, svc *api_v1.Service) (result []podEndpoint, isExternal bool, err error) {
+	if lbc.isEndpointSlicesEnabled {
+		endpointSlices, err := lbc.endpointSliceLister.GetServiceEndpointSlices(svc)
+		if err != nil {
+			return lbc.getEndpointsForMissingServiceEndpoints(backend, svc, err)
+		}
+
+		result, err = lbc.getEndpointsForPortFromEndpointSlices(endpointSlices, backend.Service.Port, svc)
+		if err != nil {
+			glog.V(3).Infof("Error getting endpoints for service %s port %v: %v", svc.Name, configs.GetBackendPortAsString(backend.Service.Port), err)
+			return nil, false, err
+		}
+		return result, false, nil
+	}
+
 	endps, err := lbc.endpointLister.GetServiceEndpoints(svc)
 	if err != nil {
-		if svc.Spec.Type == api_v1.ServiceTypeExternalName {
-			if !lbc.isNginxPlus {
-				return nil, false, fmt.Errorf("Type ExternalName Services feature is only available in NGINX Plus")
-			}
-			result = lbc.getExternalEndpointsForIngressBackend(backend, svc)
-			return result, true, nil
-		}
-		glog.V(3).Infof("Error getting endpoints for service %s from the cache: %v", svc.Name, err)
-		return nil, false, err
+		return lbc.getEndpointsForMissingServiceEndpoints(backend, svc, err)
 	}
 
 	result, err = lbc.getEndpointsForPort(endps, backend.Service.Port, svc)
@@ -3196,7 +4095,21 @@ func (lbc *LoadBalancerController) getEndpointsForIngressBackend(backend *networ
 	return result, false, nil
 }
 
-func (lbc *LoadBalancerController) getEndpointsForPort(endps api_v1.Endpoints, backendPort networking.ServiceBackendPort, svc *api_v1.Service) ([]podEndpoint, error) {
+// getEndpointsForMissingServiceEndpoints handles a service without Endpoints or EndpointSlices in the cache.
+// Such a service is either an ExternalName service or the lookup error err is returned.
+func (lbc *LoadBalancerController) getEndpointsForMissingServiceEndpoints(backend *networking.IngressBackend, svc *api_v1.Service, err error) (result []podEndpoint, isExternal bool, _ error) {
+	if svc.Spec.Type == api_v1.ServiceTypeExternalName {
+		if !lbc.isNginxPlus {
+			return nil, false, fmt.Errorf("Type ExternalName Services feature is only available in NGINX Plus")
+		}
+		result = lbc.getExternalEndpointsForIngressBackend(backend, svc)
+		return result, true, nil
+	}
+	glog.V(3).Infof("Error getting endpoints for service %s from the cache: %v", svc.Name, err)
+	return nil, false, err
+}
+
+func (lbc *LoadBalancerController) getTargetPortForBackendPort(backendPort networking.ServiceBackendPort, svc *api_v1.Service) (int32, error) {
 	var targetPort int32
 	var err error
 
@@ -3204,14 +4117,45 @@ func (lbc *LoadBalancerController) getEndpointsForPort(endps api_v1.Endpoints, b
 		if (backendPort.Name == "" && port.Port == backendPort.Number) || port.Name == backendPort.Name {
 			targetPort, err = lbc.getTargetPort(port, svc)
 			if err != nil {
-				return nil, fmt.Errorf("Error determining target port for port %v in Ingress: %w", backendPort, err)
+				return 0, fmt.Errorf("Error determining target port for port %v in Ingress: %w", backendPort, err)
 			}
 			break
 		}
 	}
 
 	if targetPort == 0 {
-		return nil, fmt.Errorf("No port %v in service %s", backendPort, svc.Name)
+		return 0, fmt.Errorf("No port %v in service %s", backendPort, svc.Name)
+	}
+
+	return targetPort, nil
+}
+
+func (lbc *LoadBalancerController) getEndpointsForPortFromEndpointSlices(endpointSlices []*discovery_v1.EndpointSlice, backendPort networking.ServiceBackendPort, svc *api_v1.Service) ([]podEndpoint, error) {
+	targetPort, err := lbc.getTargetPortForBackendPort(backendPort, svc)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasEndpointSlicePort(endpointSlices, targetPort) {
+		return nil, fmt.Errorf("No endpoints for target port %v in service %s", targetPort, svc.Name)
+	}
+
+	endpoints := getEndpointsFromEndpointSlices(endpointSlices, targetPort)
+	for i := range endpoints {
+		if endpoints[i].PodName != "" {
+			parentType, parentName := lbc.getPodOwnerTypeAndNameFromAddress(svc.Namespace, endpoints[i].PodName)
+			endpoints[i].OwnerType = parentType
+			endpoints[i].OwnerName = parentName
+		}
+	}
+
+	return endpoints, nil
+}
+
+func (lbc *LoadBalancerController) getEndpointsForPort(endps api_v1.Endpoints, backendPort networking.ServiceBackendPort, svc *api_v1.Service) ([]podEndpoint, error) {
+	targetPort, err := lbc.getTargetPortForBackendPort(backendPort, svc)
+	if err != nil {
+		return nil, err
 	}
 
 	for _, subset := range endps.Subsets {
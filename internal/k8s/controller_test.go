@@ -6,7 +6,9 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/nginxinc/kubernetes-ingress/internal/configs"
@@ -20,12 +22,14 @@ import (
 	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
 	api_v1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	discovery_v1 "k8s.io/api/discovery/v1"
 	networking "k8s.io/api/networking/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 )
 
 func TestHasCorrectIngressClass(t *testing.T) {
@@ -544,6 +548,282 @@ func TestGetEndpointsBySubselectedPods(t *testing.T) {
 	}
 }
 
+func TestGetEndpointsFromEndpointSlices(t *testing.T) {
+	boolPointer := func(b bool) *bool { return &b }
+	int32Pointer := func(i int32) *int32 { return &i }
+
+	newEndpointSlice := func(addressType discovery_v1.AddressType, port int32, endpoints ...discovery_v1.Endpoint) *discovery_v1.EndpointSlice {
+		return &discovery_v1.EndpointSlice{
+			AddressType: addressType,
+			Ports: []discovery_v1.EndpointPort{
+				{
+					Port: int32Pointer(port),
+				},
+			},
+			Endpoints: endpoints,
+		}
+	}
+
+	readyEndpoint := func(address string, podName string) discovery_v1.Endpoint {
+		return discovery_v1.Endpoint{
+			Addresses: []string{address},
+			Conditions: discovery_v1.EndpointConditions{
+				Ready: boolPointer(true),
+			},
+			TargetRef: &v1.ObjectReference{
+				Kind: "Pod",
+				Name: podName,
+			},
+		}
+	}
+
+	terminatingEndpoint := func(address string, serving bool) discovery_v1.Endpoint {
+		return discovery_v1.Endpoint{
+			Addresses: []string{address},
+			Conditions: discovery_v1.EndpointConditions{
+				Ready:       boolPointer(false),
+				Serving:     boolPointer(serving),
+				Terminating: boolPointer(true),
+			},
+		}
+	}
+
+	tests := []struct {
+		desc           string
+		endpointSlices []*discovery_v1.EndpointSlice
+		targetPort     int32
+		expected       []podEndpoint
+	}{
+		{
+			desc: "merges endpoints of multiple slices",
+			endpointSlices: []*discovery_v1.EndpointSlice{
+				newEndpointSlice(discovery_v1.AddressTypeIPv4, 80, readyEndpoint("10.0.0.2", "pod-2")),
+				newEndpointSlice(discovery_v1.AddressTypeIPv4, 80, readyEndpoint("10.0.0.1", "pod-1"), readyEndpoint("10.0.0.2", "pod-2")),
+				newEndpointSlice(discovery_v1.AddressTypeIPv6, 80, readyEndpoint("fd00::1", "pod-3")),
+			},
+			targetPort: 80,
+			expected: []podEndpoint{
+				{Address: "10.0.0.1:80", PodName: "pod-1"},
+				{Address: "10.0.0.2:80", PodName: "pod-2"},
+				{Address: "[fd00::1]:80", PodName: "pod-3"},
+			},
+		},
+		{
+			desc: "ignores slices with a different port and FQDN slices",
+			endpointSlices: []*discovery_v1.EndpointSlice{
+				newEndpointSlice(discovery_v1.AddressTypeIPv4, 8080, readyEndpoint("10.0.0.1", "pod-1")),
+				newEndpointSlice(discovery_v1.AddressTypeFQDN, 80, readyEndpoint("example.com", "")),
+			},
+			targetPort: 80,
+			expected:   nil,
+		},
+		{
+			desc: "treats unknown readiness as ready",
+			endpointSlices: []*discovery_v1.EndpointSlice{
+				newEndpointSlice(discovery_v1.AddressTypeIPv4, 80, discovery_v1.Endpoint{Addresses: []string{"10.0.0.1"}}),
+			},
+			targetPort: 80,
+			expected: []podEndpoint{
+				{Address: "10.0.0.1:80"},
+			},
+		},
+		{
+			desc: "prefers ready endpoints over terminating endpoints",
+			endpointSlices: []*discovery_v1.EndpointSlice{
+				newEndpointSlice(discovery_v1.AddressTypeIPv4, 80, readyEndpoint("10.0.0.1", "pod-1"), terminatingEndpoint("10.0.0.2", true)),
+			},
+			targetPort: 80,
+			expected: []podEndpoint{
+				{Address: "10.0.0.1:80", PodName: "pod-1"},
+			},
+		},
+		{
+			desc: "falls back to serving terminating endpoints",
+			endpointSlices: []*discovery_v1.EndpointSlice{
+				newEndpointSlice(discovery_v1.AddressTypeIPv4, 80, terminatingEndpoint("10.0.0.2", true), terminatingEndpoint("10.0.0.3", false)),
+			},
+			targetPort: 80,
+			expected: []podEndpoint{
+				{Address: "10.0.0.2:80"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			result := getEndpointsFromEndpointSlices(test.endpointSlices, test.targetPort)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("getEndpointsFromEndpointSlices() returned %v but expected %v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestGetEndpointsBySubselectedPodsFromEndpointSlices(t *testing.T) {
+	boolPointer := func(b bool) *bool { return &b }
+	int32Pointer := func(i int32) *int32 { return &i }
+
+	pods := []*v1.Pod{
+		{
+			ObjectMeta: meta_v1.ObjectMeta{
+				OwnerReferences: []meta_v1.OwnerReference{
+					{
+						Kind:       "Deployment",
+						Name:       "deploy-1",
+						Controller: boolPointer(true),
+					},
+				},
+			},
+			Status: v1.PodStatus{
+				PodIP: "1.2.3.4",
+			},
+		},
+	}
+
+	endpointSlices := []*discovery_v1.EndpointSlice{
+		{
+			AddressType: discovery_v1.AddressTypeIPv4,
+			Ports: []discovery_v1.EndpointPort{
+				{
+					Port: int32Pointer(80),
+				},
+			},
+			Endpoints: []discovery_v1.Endpoint{
+				{
+					Addresses: []string{"1.2.3.4"},
+					TargetRef: &v1.ObjectReference{
+						Kind: "Pod",
+						Name: "pod-1",
+					},
+				},
+				{
+					Addresses: []string{"5.6.7.8"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		desc        string
+		targetPort  int32
+		expectedEps []podEndpoint
+	}{
+		{
+			desc:       "find one endpoint",
+			targetPort: 80,
+			expectedEps: []podEndpoint{
+				{
+					Address: "1.2.3.4:80",
+					PodName: "pod-1",
+					MeshPodOwner: configs.MeshPodOwner{
+						OwnerType: "deployment",
+						OwnerName: "deploy-1",
+					},
+				},
+			},
+		},
+		{
+			desc:        "targetPort mismatch",
+			targetPort:  21,
+			expectedEps: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			gotEndps := getEndpointsBySubselectedPodsFromEndpointSlices(test.targetPort, pods, endpointSlices)
+			if !reflect.DeepEqual(gotEndps, test.expectedEps) {
+				t.Errorf("getEndpointsBySubselectedPodsFromEndpointSlices() = %v, want %v", gotEndps, test.expectedEps)
+			}
+		})
+	}
+}
+
+func TestGetServiceEndpointSlices(t *testing.T) {
+	lister := indexerToEndpointSliceLister{
+		Indexer: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{endpointSliceServiceIndex: endpointSliceServiceIndexFunc}),
+	}
+
+	newEndpointSlice := func(namespace, name, svcName string) *discovery_v1.EndpointSlice {
+		return &discovery_v1.EndpointSlice{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Namespace: namespace,
+				Name:      name,
+				Labels: map[string]string{
+					discovery_v1.LabelServiceName: svcName,
+				},
+			},
+		}
+	}
+
+	for _, endpointSlice := range []*discovery_v1.EndpointSlice{
+		newEndpointSlice("default", "coffee-abcde", "coffee"),
+		newEndpointSlice("default", "coffee-fghij", "coffee"),
+		newEndpointSlice("default", "tea-abcde", "tea"),
+		newEndpointSlice("other", "coffee-abcde", "coffee"),
+	} {
+		err := lister.Add(endpointSlice)
+		if err != nil {
+			t.Fatalf("Add() returned an unexpected error: %v", err)
+		}
+	}
+
+	svc := &v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Namespace: "default",
+			Name:      "coffee",
+		},
+	}
+
+	result, err := lister.GetServiceEndpointSlices(svc)
+	if err != nil {
+		t.Fatalf("GetServiceEndpointSlices() returned an unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, endpointSlice := range result {
+		names = append(names, endpointSlice.Namespace+"/"+endpointSlice.Name)
+	}
+	sort.Strings(names)
+
+	expected := []string{"default/coffee-abcde", "default/coffee-fghij"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("GetServiceEndpointSlices() returned %v but expected %v", names, expected)
+	}
+
+	svc.Name = "milk"
+	_, err = lister.GetServiceEndpointSlices(svc)
+	if err == nil {
+		t.Errorf("GetServiceEndpointSlices() returned no error for a service without EndpointSlices")
+	}
+}
+
+func TestTaskKeyFuncForEndpointSlice(t *testing.T) {
+	endpointSlice := &discovery_v1.EndpointSlice{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Namespace: "default",
+			Name:      "coffee-abcde",
+			Labels: map[string]string{
+				discovery_v1.LabelServiceName: "coffee",
+			},
+		},
+	}
+
+	key, err := taskKeyFunc(endpointSlice)
+	if err != nil {
+		t.Fatalf("taskKeyFunc() returned an unexpected error: %v", err)
+	}
+	if key != "default/coffee" {
+		t.Errorf("taskKeyFunc() returned %q but expected %q", key, "default/coffee")
+	}
+
+	endpointSlice.Labels = nil
+	_, err = taskKeyFunc(endpointSlice)
+	if err == nil {
+		t.Errorf("taskKeyFunc() returned no error for an EndpointSlice without the service name label")
+	}
+}
+
 func TestGetStatusFromEventTitle(t *testing.T) {
 	tests := []struct {
 		eventTitle string
@@ -682,7 +962,7 @@ func TestGetPolicies(t *testing.T) {
 
 	expectedPolicies := []*conf_v1.Policy{validPolicy}
 	expectedErrors := []error{
-		errors.New("Policy default/invalid-policy is invalid: spec: Invalid value: \"\": must specify exactly one of: `accessControl`, `rateLimit`, `ingressMTLS`, `egressMTLS`, `basicAuth`, `jwt`, `oidc`, `waf`"),
+		errors.New("Policy default/invalid-policy is invalid: spec: Invalid value: \"\": must specify exactly one of: `accessControl`, `rateLimit`, `ingressMTLS`, `egressMTLS`, `basicAuth`, `cors`, `externalAuth`, `apiKey`, `cache`, `resilience`, `geoAccess`, `headers`, `compression`, `connectionLimit`, `bandwidth`, `jwt`, `oidc`, `waf`"),
 		errors.New("Policy nginx-ingress/valid-policy doesn't exist"),
 		errors.New("Failed to get policy nginx-ingress/some-policy: GetByKey error"),
 		errors.New("referenced policy default/valid-policy-ingress-class has incorrect ingress class: test-class (controller ingress class: )"),
@@ -1026,6 +1306,17 @@ func TestFindPoliciesForSecret(t *testing.T) {
 			},
 		},
 	}
+	apiKeyPol := &conf_v1.Policy{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "api-key-policy",
+			Namespace: "default",
+		},
+		Spec: conf_v1.PolicySpec{
+			APIKey: &conf_v1.APIKey{
+				ClientSecret: "api-key-secret",
+			},
+		},
+	}
 
 	tests := []struct {
 		policies        []*conf_v1.Policy
@@ -1132,6 +1423,13 @@ func TestFindPoliciesForSecret(t *testing.T) {
 			expected:        []*conf_v1.Policy{oidcPol},
 			msg:             "Find policy in default ns, ignore other types",
 		},
+		{
+			policies:        []*conf_v1.Policy{oidcPol, apiKeyPol},
+			secretNamespace: "default",
+			secretName:      "api-key-secret",
+			expected:        []*conf_v1.Policy{apiKeyPol},
+			msg:             "Find policy in default ns, ignore other types",
+		},
 	}
 	for _, test := range tests {
 		result := findPoliciesForSecret(test.policies, test.secretNamespace, test.secretName)
@@ -1141,6 +1439,81 @@ func TestFindPoliciesForSecret(t *testing.T) {
 	}
 }
 
+func TestFindPoliciesForService(t *testing.T) {
+	t.Parallel()
+	extAuthPol := &conf_v1.Policy{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "ext-auth-policy",
+			Namespace: "default",
+		},
+		Spec: conf_v1.PolicySpec{
+			ExternalAuth: &conf_v1.ExternalAuth{
+				AuthService:     "auth-svc",
+				AuthServicePort: 80,
+			},
+		},
+	}
+	extAuthPol2 := &conf_v1.Policy{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "ext-auth-policy",
+			Namespace: "ns-1",
+		},
+		Spec: conf_v1.PolicySpec{
+			ExternalAuth: &conf_v1.ExternalAuth{
+				AuthService:     "auth-svc",
+				AuthServicePort: 80,
+			},
+		},
+	}
+	basicPol := &conf_v1.Policy{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "basic-auth-policy",
+			Namespace: "default",
+		},
+		Spec: conf_v1.PolicySpec{
+			BasicAuth: &conf_v1.BasicAuth{
+				Secret: "auth-svc",
+			},
+		},
+	}
+
+	tests := []struct {
+		policies     []*conf_v1.Policy
+		svcNamespace string
+		svcName      string
+		expected     []*conf_v1.Policy
+		msg          string
+	}{
+		{
+			policies:     []*conf_v1.Policy{extAuthPol},
+			svcNamespace: "default",
+			svcName:      "auth-svc",
+			expected:     []*conf_v1.Policy{extAuthPol},
+			msg:          "Find policy in default ns",
+		},
+		{
+			policies:     []*conf_v1.Policy{extAuthPol, extAuthPol2, basicPol},
+			svcNamespace: "ns-1",
+			svcName:      "auth-svc",
+			expected:     []*conf_v1.Policy{extAuthPol2},
+			msg:          "Find policy in ns-1, ignore other namespaces and types",
+		},
+		{
+			policies:     []*conf_v1.Policy{extAuthPol},
+			svcNamespace: "default",
+			svcName:      "other-svc",
+			expected:     nil,
+			msg:          "Ignore policy with other service",
+		},
+	}
+	for _, test := range tests {
+		result := findPoliciesForService(test.policies, test.svcNamespace, test.svcName)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("findPoliciesForService() '%v' mismatch (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
 func errorComparer(e1, e2 error) bool {
 	if e1 == nil || e2 == nil {
 		return errors.Is(e1, e2)
@@ -2312,3 +2685,324 @@ func TestPreSyncSecrets(t *testing.T) {
 		t.Errorf("GetSecret(%q) returned a reference without an expected error", unsupportedKey)
 	}
 }
+
+func TestGetPolicyWarningForTransportServers(t *testing.T) {
+	ts := &conf_v1alpha1.TransportServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "tcp-server",
+			Namespace: "default",
+		},
+	}
+	vs := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+
+	tests := []struct {
+		pol       *conf_v1.Policy
+		resources []Resource
+		expected  string
+		msg       string
+	}{
+		{
+			pol: &conf_v1.Policy{
+				Spec: conf_v1.PolicySpec{
+					ConnectionLimit: &conf_v1.ConnectionLimit{},
+				},
+			},
+			resources: []Resource{NewTransportServerConfiguration(ts)},
+			expected:  "",
+			msg:       "supported policy referenced by a TransportServer",
+		},
+		{
+			pol: &conf_v1.Policy{
+				Spec: conf_v1.PolicySpec{
+					RateLimit: &conf_v1.RateLimit{},
+				},
+			},
+			resources: []Resource{NewVirtualServerConfiguration(vs, nil, nil)},
+			expected:  "",
+			msg:       "unsupported policy referenced only by a VirtualServer",
+		},
+		{
+			pol: &conf_v1.Policy{
+				Spec: conf_v1.PolicySpec{
+					RateLimit: &conf_v1.RateLimit{},
+				},
+			},
+			resources: []Resource{NewVirtualServerConfiguration(vs, nil, nil), NewTransportServerConfiguration(ts)},
+			expected:  "the policy is not supported in TransportServer(s) default/tcp-server",
+			msg:       "unsupported policy referenced by a TransportServer",
+		},
+	}
+
+	for _, test := range tests {
+		result := getPolicyWarningForTransportServers(test.pol, test.resources)
+		if result != test.expected {
+			t.Errorf("getPolicyWarningForTransportServers() returned %q but expected %q for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestUpdateResourcesStatusAndEventsForRejectedConfig(t *testing.T) {
+	t.Parallel()
+	createVirtualServer := func(name string) *conf_v1.VirtualServer {
+		return &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+		}
+	}
+
+	rejectedEvent := `Warning AddedOrUpdatedWithError Configuration for default/cafe was added or updated ; but was not applied: NGINX rejected the config: unknown directive "proxy_passs" in /etc/nginx/conf.d/vs_default_cafe.conf:25`
+
+	tests := []struct {
+		applied  bool
+		expected []string
+		msg      string
+	}{
+		{
+			applied: true,
+			expected: []string{
+				rejectedEvent,
+				"Normal AddedOrUpdated Configuration for default/tea was added or updated ",
+			},
+			msg: "the other resources were applied",
+		},
+		{
+			applied: false,
+			expected: []string{
+				rejectedEvent,
+				"Warning AddedOrUpdatedWithWarning Configuration for default/tea was added or updated ; with warning(s): the config was not applied because NGINX rejected the config in /etc/nginx/conf.d/vs_default_cafe.conf",
+			},
+			msg: "the other resources were not applied",
+		},
+	}
+
+	for _, test := range tests {
+		recorder := record.NewFakeRecorder(10)
+		lbc := LoadBalancerController{
+			isLeaderElectionEnabled: true,
+			recorder:                recorder,
+			configurator: configs.NewConfigurator(nginx.NewFakeManager("/etc/nginx"), &configs.StaticConfigParams{}, &configs.ConfigParams{},
+				&version1.TemplateExecutor{}, &version2.TemplateExecutor{}, false, false, nil, false, nil, false),
+		}
+
+		resources := []Resource{
+			NewVirtualServerConfiguration(createVirtualServer("cafe"), nil, nil),
+			NewVirtualServerConfiguration(createVirtualServer("tea"), nil, nil),
+		}
+
+		reloadErr := fmt.Errorf("nginx config test failed: %w", &nginx.ValidationError{
+			Errors: []nginx.ConfigError{
+				{
+					Filename: "/etc/nginx/conf.d/vs_default_cafe.conf",
+					Line:     25,
+					Message:  `unknown directive "proxy_passs"`,
+				},
+			},
+			Applied: test.applied,
+		})
+
+		lbc.updateResourcesStatusAndEvents(resources, configs.Warnings{}, reloadErr)
+
+		var events []string
+		for len(recorder.Events) > 0 {
+			events = append(events, <-recorder.Events)
+		}
+
+		if diff := cmp.Diff(test.expected, events); diff != "" {
+			t.Errorf("updateResourcesStatusAndEvents() recorded unexpected events for the case of %s (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+// failingReloadManager is a fake NGINX manager with a reload that fails with the error.
+type failingReloadManager struct {
+	*nginx.FakeManager
+	err error
+}
+
+func (m *failingReloadManager) Reload(bool) error {
+	return m.err
+}
+
+func TestUpdateResourcesStatusAndEventsForRejectedConfigInReloadBatch(t *testing.T) {
+	t.Parallel()
+	manager := &failingReloadManager{
+		FakeManager: nginx.NewFakeManager("/etc/nginx"),
+		err: &nginx.ValidationError{
+			Errors: []nginx.ConfigError{
+				{
+					Filename: "/etc/nginx/conf.d/vs_default_cafe.conf",
+					Line:     25,
+					Message:  `unknown directive "proxy_passs"`,
+				},
+			},
+			Applied: true,
+		},
+	}
+
+	cnf := configs.NewConfigurator(manager, &configs.StaticConfigParams{}, &configs.ConfigParams{},
+		&version1.TemplateExecutor{}, &version2.TemplateExecutor{}, false, false, nil, false, nil, false)
+	cnf.EnableReloads()
+	cnf.EnableReloadBatching(time.Hour, time.Hour)
+
+	recorder := record.NewFakeRecorder(10)
+	lbc := LoadBalancerController{
+		isLeaderElectionEnabled: true,
+		recorder:                recorder,
+		configurator:            cnf,
+	}
+
+	for _, name := range []string{"cafe", "tea"} {
+		vs := &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+		}
+
+		// the deletion requests a reload, so that the reports of the resources are batched
+		if err := cnf.DeleteVirtualServer(getResourceKey(&vs.ObjectMeta)); err != nil {
+			t.Fatalf("DeleteVirtualServer() returned unexpected error: %v", err)
+		}
+
+		lbc.updateResourcesStatusAndEvents([]Resource{NewVirtualServerConfiguration(vs, nil, nil)}, configs.Warnings{}, nil)
+	}
+
+	if len(recorder.Events) != 0 {
+		t.Fatalf("updateResourcesStatusAndEvents() recorded events before the batched reload")
+	}
+
+	cnf.ReloadBatch()
+
+	expected := []string{
+		`Warning AddedOrUpdatedWithError Configuration for default/cafe was added or updated ; but was not applied: NGINX rejected the config: unknown directive "proxy_passs" in /etc/nginx/conf.d/vs_default_cafe.conf:25`,
+		"Normal AddedOrUpdated Configuration for default/tea was added or updated ",
+	}
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+
+	if diff := cmp.Diff(expected, events); diff != "" {
+		t.Errorf("ReloadBatch() recorded unexpected events (-want +got):\n%s", diff)
+	}
+}
+
+// rendezvousSecretStore is a fake secret store that blocks the lookups of the secrets until the given number
+// of lookups run concurrently or the timeout expires.
+type rendezvousSecretStore struct {
+	*secrets.FakeSecretStore
+	arrived chan struct{}
+	met     chan struct{}
+	lookups int
+	timeout time.Duration
+	once    sync.Once
+
+	lock     sync.Mutex
+	timeouts int
+}
+
+func newRendezvousSecretStore(lookups int, timeout time.Duration) *rendezvousSecretStore {
+	return &rendezvousSecretStore{
+		FakeSecretStore: secrets.NewEmptyFakeSecretsStore(),
+		arrived:         make(chan struct{}, lookups),
+		met:             make(chan struct{}),
+		lookups:         lookups,
+		timeout:         timeout,
+	}
+}
+
+func (s *rendezvousSecretStore) GetSecret(key string) *secrets.SecretReference {
+	s.arrived <- struct{}{}
+
+	s.once.Do(func() {
+		go func() {
+			for i := 0; i < s.lookups; i++ {
+				<-s.arrived
+			}
+			close(s.met)
+		}()
+	})
+
+	select {
+	case <-s.met:
+	case <-time.After(s.timeout):
+		s.lock.Lock()
+		s.timeouts++
+		s.lock.Unlock()
+	}
+
+	return s.FakeSecretStore.GetSecret(key)
+}
+
+func TestSyncVirtualServersConcurrently(t *testing.T) {
+	t.Parallel()
+
+	templateExecutorV2, err := version2.NewTemplateExecutor("../configs/version2/nginx.virtualserver.tmpl", "../configs/version2/nginx.transportserver.tmpl")
+	if err != nil {
+		t.Fatalf("Failed to create the template executor: %v", err)
+	}
+
+	secretStore := newRendezvousSecretStore(2, 5*time.Second)
+	virtualServerLister := cache.NewStore(cache.MetaNamespaceKeyFunc)
+
+	lbc := &LoadBalancerController{
+		isNginxReady:            true,
+		isLeaderElectionEnabled: true,
+		recorder:                record.NewFakeRecorder(10),
+		configuration:           createTestConfiguration(),
+		configurator: configs.NewConfigurator(nginx.NewFakeManager("/etc/nginx"), &configs.StaticConfigParams{}, configs.NewDefaultConfigParams(false),
+			&version1.TemplateExecutor{}, templateExecutorV2, false, false, nil, false, nil, false),
+		secretStore:         secretStore,
+		virtualServerLister: virtualServerLister,
+		metricsCollector:    collectors.NewControllerFakeCollector(),
+		changeSequencer:     newChangeSequencer(),
+	}
+
+	var tasks []task
+	for _, name := range []string{"cafe", "tea"} {
+		vs := &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: fmt.Sprintf("%s.example.com", name),
+				TLS: &conf_v1.TLS{
+					Secret: fmt.Sprintf("%s-secret", name),
+				},
+			},
+		}
+		err := virtualServerLister.Add(vs)
+		if err != nil {
+			t.Fatalf("Failed to add the VirtualServer %s: %v", name, err)
+		}
+		tasks = append(tasks, task{Kind: virtualserver, Key: fmt.Sprintf("default/%s", name)})
+	}
+
+	var wg sync.WaitGroup
+	for _, tsk := range tasks {
+		wg.Add(1)
+		go func(tsk task) {
+			defer wg.Done()
+			lbc.sync(tsk)
+		}(tsk)
+	}
+	wg.Wait()
+
+	if secretStore.timeouts > 0 {
+		t.Error("sync() didn't sync the VirtualServers concurrently")
+	}
+
+	vsCount, _ := lbc.configurator.GetVirtualServerCounts()
+	if vsCount != len(tasks) {
+		t.Errorf("sync() added %d VirtualServer(s), expected %d", vsCount, len(tasks))
+	}
+}
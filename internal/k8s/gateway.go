@@ -0,0 +1,826 @@
+package k8s
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gateway_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// GatewayControllerName holds the name of the controller in the spec.controllerName field of GatewayClasses
+// handled by the Ingress Controller.
+const GatewayControllerName = "nginx.org/gateway-controller"
+
+const (
+	gatewayKind   = "Gateway"
+	httpRouteKind = "HTTPRoute"
+	serviceKind   = "Service"
+)
+
+// GatewayConfiguration holds the configuration of a host of a Gateway: the VirtualServer translated from
+// the Gateway listeners and the HTTPRoutes attached to them for that host.
+// A Gateway with several hosts is represented by several GatewayConfigurations.
+type GatewayConfiguration struct {
+	Gateway          *gateway_v1alpha2.Gateway
+	HTTPRoutes       []*gateway_v1alpha2.HTTPRoute
+	Host             string
+	VirtualServer    *conf_v1.VirtualServer
+	ListenerStatuses []gateway_v1alpha2.ListenerStatus
+	// RouteRefErrors holds the errors of the unresolved references (backendRefs) of the HTTPRoutes, by the HTTPRoute key.
+	RouteRefErrors  map[string][]string
+	ValidationError error
+	Warnings        []string
+}
+
+// GetObjectMeta returns the resource ObjectMeta.
+func (gc *GatewayConfiguration) GetObjectMeta() *metav1.ObjectMeta {
+	return &gc.Gateway.ObjectMeta
+}
+
+// GetKeyWithKind returns the key of the resource with its kind and host. For example, Gateway/my-namespace/my-name/cafe.example.com.
+func (gc *GatewayConfiguration) GetKeyWithKind() string {
+	key := getResourceKey(&gc.Gateway.ObjectMeta)
+	return fmt.Sprintf("%s/%s/%s", gatewayKind, key, gc.Host)
+}
+
+// Wins tells if this resource wins over the specified resource.
+// It is used to determine which resource should win over a host.
+func (gc *GatewayConfiguration) Wins(resource Resource) bool {
+	return chooseObjectMetaWinner(gc.GetObjectMeta(), resource.GetObjectMeta())
+}
+
+// AddWarning adds a warning.
+func (gc *GatewayConfiguration) AddWarning(warning string) {
+	gc.Warnings = append(gc.Warnings, warning)
+}
+
+// IsEqual tests if the GatewayConfiguration is equal to the resource.
+// Besides the Gateway and the HTTPRoutes, it compares the translated VirtualServers,
+// so that no change of the translation is dropped as a no-op.
+func (gc *GatewayConfiguration) IsEqual(resource Resource) bool {
+	gwConfig, ok := resource.(*GatewayConfiguration)
+	if !ok {
+		return false
+	}
+
+	if gc.Host != gwConfig.Host {
+		return false
+	}
+
+	if !compareGatewaySourceObjectMetas(&gc.Gateway.ObjectMeta, &gwConfig.Gateway.ObjectMeta) {
+		return false
+	}
+
+	if len(gc.HTTPRoutes) != len(gwConfig.HTTPRoutes) {
+		return false
+	}
+
+	for i := range gc.HTTPRoutes {
+		if !compareGatewaySourceObjectMetas(&gc.HTTPRoutes[i].ObjectMeta, &gwConfig.HTTPRoutes[i].ObjectMeta) {
+			return false
+		}
+	}
+
+	return reflect.DeepEqual(gc.VirtualServer.Spec, gwConfig.VirtualServer.Spec)
+}
+
+// compareGatewaySourceObjectMetas compares the metas of a Gateway or of an HTTPRoute.
+// The generation changes with every change of the spec. If the generation is not set, the resource versions are compared.
+func compareGatewaySourceObjectMetas(meta1 *metav1.ObjectMeta, meta2 *metav1.ObjectMeta) bool {
+	if !compareObjectMetas(meta1, meta2) {
+		return false
+	}
+
+	if meta1.Generation == 0 {
+		return meta1.ResourceVersion == meta2.ResourceVersion
+	}
+
+	return true
+}
+
+// gatewayHost holds the HTTPRoutes attached to a host of a Gateway and the TLS Secret of the host.
+type gatewayHost struct {
+	secret string
+	routes []*gateway_v1alpha2.HTTPRoute
+}
+
+func (h *gatewayHost) addRoute(route *gateway_v1alpha2.HTTPRoute) {
+	for _, r := range h.routes {
+		if r == route {
+			return
+		}
+	}
+	h.routes = append(h.routes, route)
+}
+
+// translateGateway translates a Gateway and the HTTPRoutes into GatewayConfigurations, one per host.
+// The HTTPRoutes must be sorted, so that the translation is stable. HTTPRoutes that don't reference the Gateway
+// in their parentRefs are ignored.
+func translateGateway(gw *gateway_v1alpha2.Gateway, routes []*gateway_v1alpha2.HTTPRoute) []*GatewayConfiguration {
+	var warnings []string
+
+	hosts := make(map[string]*gatewayHost)
+	attachedRoutes := make(map[string]int32)
+	var listenerStatuses []gateway_v1alpha2.ListenerStatus
+
+	for _, l := range gw.Spec.Listeners {
+		secret, listenerConditions, listenerWarnings := translateGatewayListener(gw, l)
+		warnings = append(warnings, listenerWarnings...)
+
+		supported := isGatewayListenerSupported(listenerConditions)
+
+		for _, route := range routes {
+			if !supported || !isRouteAttachedToGatewayListener(route, gw, l) {
+				continue
+			}
+
+			routeHosts, hostWarnings := getHostsForGatewayListener(l, route)
+			warnings = append(warnings, hostWarnings...)
+
+			if len(routeHosts) > 0 {
+				attachedRoutes[string(l.Name)]++
+			}
+
+			for _, host := range routeHosts {
+				h, exists := hosts[host]
+				if !exists {
+					h = &gatewayHost{}
+					hosts[host] = h
+				}
+				if secret != "" {
+					h.secret = secret
+				}
+				h.addRoute(route)
+			}
+		}
+
+		listenerStatuses = append(listenerStatuses, newGatewayListenerStatus(l, listenerConditions, attachedRoutes[string(l.Name)]))
+	}
+
+	var result []*GatewayConfiguration
+
+	for _, host := range getSortedGatewayHostKeys(hosts) {
+		h := hosts[host]
+
+		vs, routeRefErrors, vsWarnings := buildVirtualServerForGatewayHost(gw, host, h)
+
+		hostWarnings := append([]string{}, warnings...)
+		hostWarnings = append(hostWarnings, vsWarnings...)
+
+		result = append(result, &GatewayConfiguration{
+			Gateway:          gw,
+			HTTPRoutes:       h.routes,
+			Host:             host,
+			VirtualServer:    vs,
+			ListenerStatuses: listenerStatuses,
+			RouteRefErrors:   routeRefErrors,
+			Warnings:         hostWarnings,
+		})
+	}
+
+	return result
+}
+
+// getGatewayListenerStatuses returns the statuses of the listeners of a Gateway without attached HTTPRoutes.
+func getGatewayListenerStatuses(gw *gateway_v1alpha2.Gateway) []gateway_v1alpha2.ListenerStatus {
+	var result []gateway_v1alpha2.ListenerStatus
+
+	for _, l := range gw.Spec.Listeners {
+		_, conditions, _ := translateGatewayListener(gw, l)
+		result = append(result, newGatewayListenerStatus(l, conditions, 0))
+	}
+
+	return result
+}
+
+func newGatewayListenerStatus(l gateway_v1alpha2.Listener, conditions []metav1.Condition, attachedRoutes int32) gateway_v1alpha2.ListenerStatus {
+	routeGroup := gateway_v1alpha2.Group(gateway_v1alpha2.GroupName)
+
+	return gateway_v1alpha2.ListenerStatus{
+		Name: l.Name,
+		SupportedKinds: []gateway_v1alpha2.RouteGroupKind{
+			{
+				Group: &routeGroup,
+				Kind:  httpRouteKind,
+			},
+		},
+		AttachedRoutes: attachedRoutes,
+		Conditions:     conditions,
+	}
+}
+
+// translateGatewayListener returns the name of the TLS Secret of the listener (for HTTPS listeners),
+// the status conditions of the listener and the warnings.
+func translateGatewayListener(gw *gateway_v1alpha2.Gateway, l gateway_v1alpha2.Listener) (string, []metav1.Condition, []string) {
+	var warnings []string
+
+	detached := newCondition(string(gateway_v1alpha2.ListenerConditionDetached), metav1.ConditionFalse, string(gateway_v1alpha2.ListenerReasonAttached), "")
+	resolvedRefs := newCondition(string(gateway_v1alpha2.ListenerConditionResolvedRefs), metav1.ConditionTrue, string(gateway_v1alpha2.ListenerReasonResolvedRefs), "")
+
+	if l.AllowedRoutes != nil && l.AllowedRoutes.Namespaces != nil && l.AllowedRoutes.Namespaces.From != nil &&
+		*l.AllowedRoutes.Namespaces.From != gateway_v1alpha2.NamespacesFromSame {
+		warnings = append(warnings, fmt.Sprintf("listener %s: only HTTPRoutes from the namespace of the Gateway are supported", l.Name))
+	}
+
+	var secret string
+
+	switch l.Protocol {
+	case gateway_v1alpha2.HTTPProtocolType:
+	case gateway_v1alpha2.HTTPSProtocolType:
+		var msg string
+		secret, msg = getGatewayListenerSecret(gw, l)
+		if msg != "" {
+			resolvedRefs = newCondition(string(gateway_v1alpha2.ListenerConditionResolvedRefs), metav1.ConditionFalse, string(gateway_v1alpha2.ListenerReasonInvalidCertificateRef), msg)
+			warnings = append(warnings, fmt.Sprintf("listener %s: %s", l.Name, msg))
+		}
+	default:
+		msg := fmt.Sprintf("protocol %s is not supported", l.Protocol)
+		detached = newCondition(string(gateway_v1alpha2.ListenerConditionDetached), metav1.ConditionTrue, string(gateway_v1alpha2.ListenerReasonUnsupportedProtocol), msg)
+		warnings = append(warnings, fmt.Sprintf("listener %s: %s", l.Name, msg))
+	}
+
+	ready := newCondition(string(gateway_v1alpha2.ListenerConditionReady), metav1.ConditionTrue, string(gateway_v1alpha2.ListenerReasonReady), "")
+	if detached.Status == metav1.ConditionTrue || resolvedRefs.Status == metav1.ConditionFalse {
+		ready = newCondition(string(gateway_v1alpha2.ListenerConditionReady), metav1.ConditionFalse, string(gateway_v1alpha2.ListenerReasonInvalid), "listener is invalid")
+	}
+
+	return secret, []metav1.Condition{detached, resolvedRefs, ready}, warnings
+}
+
+func isGatewayListenerSupported(conditions []metav1.Condition) bool {
+	for _, c := range conditions {
+		if c.Type == string(gateway_v1alpha2.ListenerConditionReady) {
+			return c.Status == metav1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// getGatewayListenerSecret returns the name of the TLS Secret referenced by an HTTPS listener.
+// If the listener doesn't reference a supported Secret, the message explains why.
+func getGatewayListenerSecret(gw *gateway_v1alpha2.Gateway, l gateway_v1alpha2.Listener) (secret string, msg string) {
+	if l.TLS == nil {
+		return "", "tls is required for HTTPS listeners"
+	}
+
+	if l.TLS.Mode != nil && *l.TLS.Mode != gateway_v1alpha2.TLSModeTerminate {
+		return "", fmt.Sprintf("tls mode %s is not supported", *l.TLS.Mode)
+	}
+
+	if len(l.TLS.CertificateRefs) == 0 || l.TLS.CertificateRefs[0] == nil {
+		return "", "a certificateRef is required"
+	}
+
+	ref := l.TLS.CertificateRefs[0]
+
+	if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != "Secret") {
+		return "", "certificateRef must reference a Secret"
+	}
+
+	if ref.Namespace != nil && string(*ref.Namespace) != gw.Namespace {
+		return "", fmt.Sprintf("certificateRef to Secret %s/%s in a different namespace is not permitted", *ref.Namespace, ref.Name)
+	}
+
+	return string(ref.Name), ""
+}
+
+// isRouteAttachedToGateway tells if one of the parentRefs of the HTTPRoute references the Gateway.
+// Only HTTPRoutes in the namespace of the Gateway can be attached to it.
+func isRouteAttachedToGateway(route *gateway_v1alpha2.HTTPRoute, gw *gateway_v1alpha2.Gateway) bool {
+	return len(getParentRefsForGateway(route, gw)) > 0
+}
+
+func isRouteAttachedToGatewayListener(route *gateway_v1alpha2.HTTPRoute, gw *gateway_v1alpha2.Gateway, l gateway_v1alpha2.Listener) bool {
+	for _, ref := range getParentRefsForGateway(route, gw) {
+		if ref.SectionName == nil || *ref.SectionName == l.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func getParentRefsForGateway(route *gateway_v1alpha2.HTTPRoute, gw *gateway_v1alpha2.Gateway) []gateway_v1alpha2.ParentRef {
+	if route.Namespace != gw.Namespace {
+		return nil
+	}
+
+	var result []gateway_v1alpha2.ParentRef
+
+	for _, ref := range route.Spec.ParentRefs {
+		if ref.Group != nil && string(*ref.Group) != gateway_v1alpha2.GroupName {
+			continue
+		}
+		if ref.Kind != nil && string(*ref.Kind) != gatewayKind {
+			continue
+		}
+		if ref.Namespace != nil && string(*ref.Namespace) != gw.Namespace {
+			continue
+		}
+		if string(ref.Name) != gw.Name {
+			continue
+		}
+		result = append(result, ref)
+	}
+
+	return result
+}
+
+// getHostsForGatewayListener returns the hosts of the HTTPRoute that match the hostname of the listener.
+// Wildcard hosts are not supported by VirtualServers, so they are skipped with a warning.
+func getHostsForGatewayListener(l gateway_v1alpha2.Listener, route *gateway_v1alpha2.HTTPRoute) ([]string, []string) {
+	var warnings []string
+
+	var listenerHost string
+	if l.Hostname != nil {
+		listenerHost = string(*l.Hostname)
+	}
+
+	var candidates []string
+	if len(route.Spec.Hostnames) == 0 {
+		if listenerHost == "" {
+			warnings = append(warnings, fmt.Sprintf("HTTPRoute %s/%s: hostnames are required when the listener %s has no hostname", route.Namespace, route.Name, l.Name))
+			return nil, warnings
+		}
+		candidates = append(candidates, listenerHost)
+	} else {
+		for _, h := range route.Spec.Hostnames {
+			if matchesGatewayHostname(listenerHost, string(h)) {
+				candidates = append(candidates, string(h))
+			}
+		}
+	}
+
+	var hosts []string
+	for _, h := range candidates {
+		if strings.HasPrefix(h, "*") {
+			warnings = append(warnings, fmt.Sprintf("HTTPRoute %s/%s: wildcard hostname %s is not supported", route.Namespace, route.Name, h))
+			continue
+		}
+		hosts = append(hosts, h)
+	}
+
+	return hosts, warnings
+}
+
+// matchesGatewayHostname tells if the host matches the hostname of a listener.
+// An empty listener hostname matches all hosts. A wildcard listener hostname (*.example.com) matches the hosts of
+// the subdomains.
+func matchesGatewayHostname(listenerHost string, host string) bool {
+	if listenerHost == "" || listenerHost == host {
+		return true
+	}
+
+	if strings.HasPrefix(listenerHost, "*.") {
+		suffix := strings.TrimPrefix(listenerHost, "*")
+		return strings.HasSuffix(host, suffix) && len(host) > len(suffix) && !strings.HasPrefix(host, "*")
+	}
+
+	return false
+}
+
+// gatewayRoutePath holds the matches and the default action of a VirtualServer route built from HTTPRoute rules
+// that share the same path.
+type gatewayRoutePath struct {
+	path       string
+	matches    []conf_v1.Match
+	action     *conf_v1.Action
+	splits     []conf_v1.Split
+	hasDefault bool
+}
+
+// buildVirtualServerForGatewayHost builds a VirtualServer for the host from the HTTPRoutes attached to it.
+func buildVirtualServerForGatewayHost(gw *gateway_v1alpha2.Gateway, host string, h *gatewayHost) (*conf_v1.VirtualServer, map[string][]string, []string) {
+	var warnings []string
+	routeRefErrors := make(map[string][]string)
+
+	upstreams := make(map[string]conf_v1.Upstream)
+	var upstreamNames []string
+
+	paths := make(map[string]*gatewayRoutePath)
+	var pathOrder []string
+
+	for _, route := range h.routes {
+		routeKey := getResourceKey(&route.ObjectMeta)
+
+		for i, rule := range route.Spec.Rules {
+			action, splits, ruleUpstreams, refErrors, ruleWarnings := translateHTTPRouteRule(route, rule)
+			for _, w := range ruleWarnings {
+				warnings = append(warnings, fmt.Sprintf("HTTPRoute %s rule %d: %s", routeKey, i, w))
+			}
+			for _, e := range refErrors {
+				routeRefErrors[routeKey] = append(routeRefErrors[routeKey], fmt.Sprintf("rule %d: %s", i, e))
+			}
+			for _, u := range ruleUpstreams {
+				if _, exists := upstreams[u.Name]; !exists {
+					upstreams[u.Name] = u
+					upstreamNames = append(upstreamNames, u.Name)
+				}
+			}
+
+			matches := rule.Matches
+			if len(matches) == 0 {
+				matches = []gateway_v1alpha2.HTTPRouteMatch{{}}
+			}
+
+			for _, m := range matches {
+				matchPaths, err := convertHTTPPathMatch(m.Path)
+				if err != nil {
+					warnings = append(warnings, fmt.Sprintf("HTTPRoute %s rule %d: %v", routeKey, i, err))
+					continue
+				}
+
+				conditions, err := convertHTTPRouteMatchConditions(m)
+				if err != nil {
+					warnings = append(warnings, fmt.Sprintf("HTTPRoute %s rule %d: %v", routeKey, i, err))
+					continue
+				}
+
+				for _, path := range matchPaths {
+					p, exists := paths[path]
+					if !exists {
+						p = &gatewayRoutePath{path: path}
+						paths[path] = p
+						pathOrder = append(pathOrder, path)
+					}
+
+					if len(conditions) == 0 {
+						if p.hasDefault {
+							warnings = append(warnings, fmt.Sprintf("HTTPRoute %s rule %d: path %s is already matched by another rule", routeKey, i, path))
+							continue
+						}
+						p.hasDefault = true
+						p.action = action
+						p.splits = splits
+						continue
+					}
+
+					p.matches = append(p.matches, conf_v1.Match{
+						Conditions: conditions,
+						Action:     action,
+						Splits:     splits,
+					})
+				}
+			}
+		}
+	}
+
+	var vsRoutes []conf_v1.Route
+	for _, path := range pathOrder {
+		p := paths[path]
+
+		// Matches with more conditions take precedence
+		sort.SliceStable(p.matches, func(i, j int) bool {
+			return len(p.matches[i].Conditions) > len(p.matches[j].Conditions)
+		})
+
+		r := conf_v1.Route{
+			Path:    p.path,
+			Matches: p.matches,
+			Action:  p.action,
+			Splits:  p.splits,
+		}
+
+		if !p.hasDefault {
+			r.Action = &conf_v1.Action{
+				Return: &conf_v1.ActionReturn{
+					Code: 404,
+					Body: "Not Found",
+				},
+			}
+		}
+
+		vsRoutes = append(vsRoutes, r)
+	}
+
+	var vsUpstreams []conf_v1.Upstream
+	for _, name := range upstreamNames {
+		vsUpstreams = append(vsUpstreams, upstreams[name])
+	}
+
+	vs := &conf_v1.VirtualServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              getGatewayVirtualServerName(gw.Name, host),
+			Namespace:         gw.Namespace,
+			CreationTimestamp: gw.CreationTimestamp,
+			Generation:        gw.Generation,
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			Host:      host,
+			Upstreams: vsUpstreams,
+			Routes:    vsRoutes,
+		},
+	}
+
+	if h.secret != "" {
+		vs.Spec.TLS = &conf_v1.TLS{
+			Secret: h.secret,
+		}
+	}
+
+	return vs, routeRefErrors, warnings
+}
+
+// getGatewayVirtualServerName returns the name of the VirtualServer of a host of a Gateway.
+// The name includes underscores, which are not allowed in the names of VirtualServer resources,
+// so that it never collides with an existing VirtualServer.
+func getGatewayVirtualServerName(gatewayName string, host string) string {
+	replacer := strings.NewReplacer(".", "_", "-", "_")
+	return fmt.Sprintf("gateway_%s_%s", replacer.Replace(gatewayName), replacer.Replace(host))
+}
+
+// translateHTTPRouteRule translates the filters and the backendRefs of an HTTPRoute rule into an action or splits.
+func translateHTTPRouteRule(route *gateway_v1alpha2.HTTPRoute, rule gateway_v1alpha2.HTTPRouteRule) (
+	action *conf_v1.Action, splits []conf_v1.Split, upstreams []conf_v1.Upstream, refErrors []string, warnings []string,
+) {
+	var headers []conf_v1.Header
+	var redirect *conf_v1.ActionRedirect
+
+	for _, f := range rule.Filters {
+		switch f.Type {
+		case gateway_v1alpha2.HTTPRouteFilterRequestHeaderModifier:
+			if f.RequestHeaderModifier == nil {
+				continue
+			}
+			headers = append(headers, convertHTTPRequestHeaderFilter(f.RequestHeaderModifier)...)
+		case gateway_v1alpha2.HTTPRouteFilterRequestRedirect:
+			if f.RequestRedirect == nil {
+				continue
+			}
+			redirect = convertHTTPRequestRedirectFilter(f.RequestRedirect)
+		default:
+			warnings = append(warnings, fmt.Sprintf("filter type %s is not supported", f.Type))
+		}
+	}
+
+	if redirect != nil {
+		return &conf_v1.Action{Redirect: redirect}, nil, nil, nil, warnings
+	}
+
+	type weightedUpstream struct {
+		name   string
+		weight int
+	}
+	var backends []weightedUpstream
+
+	for _, ref := range rule.BackendRefs {
+		if len(ref.Filters) > 0 {
+			warnings = append(warnings, fmt.Sprintf("filters of backendRef %s are not supported", ref.Name))
+		}
+
+		u, err := convertHTTPBackendRef(route, ref)
+		if err != nil {
+			refErrors = append(refErrors, err.Error())
+			continue
+		}
+
+		weight := 1
+		if ref.Weight != nil {
+			weight = int(*ref.Weight)
+		}
+		if weight == 0 {
+			continue
+		}
+
+		upstreams = append(upstreams, u)
+		backends = append(backends, weightedUpstream{name: u.Name, weight: weight})
+	}
+
+	if len(backends) == 0 {
+		// requests to a rule without valid backends must get the 500 response
+		return &conf_v1.Action{
+			Return: &conf_v1.ActionReturn{
+				Code: 500,
+				Body: "Internal Server Error",
+			},
+		}, nil, upstreams, refErrors, warnings
+	}
+
+	if len(backends) == 1 {
+		return newGatewayAction(backends[0].name, headers), nil, upstreams, refErrors, warnings
+	}
+
+	weights := make([]int, len(backends))
+	for i, b := range backends {
+		weights[i] = b.weight
+	}
+
+	for i, w := range normalizeWeights(weights) {
+		if w == 0 {
+			continue
+		}
+		splits = append(splits, conf_v1.Split{
+			Weight: w,
+			Action: newGatewayAction(backends[i].name, headers),
+		})
+	}
+
+	if len(splits) == 1 {
+		return splits[0].Action, nil, upstreams, refErrors, warnings
+	}
+
+	return nil, splits, upstreams, refErrors, warnings
+}
+
+func newGatewayAction(upstream string, headers []conf_v1.Header) *conf_v1.Action {
+	if len(headers) == 0 {
+		return &conf_v1.Action{Pass: upstream}
+	}
+
+	return &conf_v1.Action{
+		Proxy: &conf_v1.ActionProxy{
+			Upstream: upstream,
+			RequestHeaders: &conf_v1.ProxyRequestHeaders{
+				Set: headers,
+			},
+		},
+	}
+}
+
+// normalizeWeights converts the weights into percentages that add up to 100.
+// The remainder of the rounding goes to the largest weight.
+func normalizeWeights(weights []int) []int {
+	total := 0
+	largest := 0
+	for i, w := range weights {
+		total += w
+		if w > weights[largest] {
+			largest = i
+		}
+	}
+
+	result := make([]int, len(weights))
+	if total == 0 {
+		return result
+	}
+
+	sum := 0
+	for i, w := range weights {
+		result[i] = w * 100 / total
+		sum += result[i]
+	}
+	result[largest] += 100 - sum
+
+	return result
+}
+
+// convertHTTPBackendRef converts a backendRef into an upstream. Only Services in the namespace of the HTTPRoute are supported.
+func convertHTTPBackendRef(route *gateway_v1alpha2.HTTPRoute, ref gateway_v1alpha2.HTTPBackendRef) (conf_v1.Upstream, error) {
+	if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != serviceKind) {
+		return conf_v1.Upstream{}, fmt.Errorf("backendRef %s must reference a Service", ref.Name)
+	}
+
+	if ref.Namespace != nil && string(*ref.Namespace) != route.Namespace {
+		return conf_v1.Upstream{}, fmt.Errorf("backendRef to Service %s/%s in a different namespace is not permitted", *ref.Namespace, ref.Name)
+	}
+
+	if ref.Port == nil {
+		return conf_v1.Upstream{}, fmt.Errorf("backendRef %s must specify a port", ref.Name)
+	}
+
+	return conf_v1.Upstream{
+		Name:    fmt.Sprintf("%s-%d", ref.Name, *ref.Port),
+		Service: string(ref.Name),
+		Port:    uint16(*ref.Port),
+	}, nil
+}
+
+// convertHTTPPathMatch converts a path match into the paths of VirtualServer routes.
+// A path prefix matches the path element by element, so /foo matches /foo and /foo/bar, but not /foobar.
+// Thus, it is converted into the exact path /foo and the prefix /foo/.
+func convertHTTPPathMatch(m *gateway_v1alpha2.HTTPPathMatch) ([]string, error) {
+	matchType := gateway_v1alpha2.PathMatchPathPrefix
+	value := "/"
+
+	if m != nil {
+		if m.Type != nil {
+			matchType = *m.Type
+		}
+		if m.Value != nil {
+			value = *m.Value
+		}
+	}
+
+	switch matchType {
+	case gateway_v1alpha2.PathMatchPathPrefix:
+		// the trailing slash of the prefix is ignored
+		value = strings.TrimRight(value, "/")
+		if value == "" {
+			return []string{"/"}, nil
+		}
+		return []string{"=" + value, value + "/"}, nil
+	case gateway_v1alpha2.PathMatchExact:
+		return []string{"=" + value}, nil
+	case gateway_v1alpha2.PathMatchRegularExpression:
+		return []string{"~ " + value}, nil
+	}
+
+	return nil, fmt.Errorf("path match type %s is not supported", matchType)
+}
+
+// convertHTTPRouteMatchConditions converts the header, query param and method matches into the conditions of a VirtualServer match.
+func convertHTTPRouteMatchConditions(m gateway_v1alpha2.HTTPRouteMatch) ([]conf_v1.Condition, error) {
+	var conditions []conf_v1.Condition
+
+	for _, h := range m.Headers {
+		if h.Type != nil && *h.Type != gateway_v1alpha2.HeaderMatchExact {
+			return nil, fmt.Errorf("header match type %s is not supported", *h.Type)
+		}
+		conditions = append(conditions, conf_v1.Condition{
+			Header: string(h.Name),
+			Value:  h.Value,
+		})
+	}
+
+	for _, q := range m.QueryParams {
+		if q.Type != nil && *q.Type != gateway_v1alpha2.QueryParamMatchExact {
+			return nil, fmt.Errorf("query param match type %s is not supported", *q.Type)
+		}
+		conditions = append(conditions, conf_v1.Condition{
+			Argument: q.Name,
+			Value:    q.Value,
+		})
+	}
+
+	if m.Method != nil {
+		conditions = append(conditions, conf_v1.Condition{
+			Variable: "$request_method",
+			Value:    string(*m.Method),
+		})
+	}
+
+	return conditions, nil
+}
+
+// convertHTTPRequestHeaderFilter converts a RequestHeaderModifier filter into the headers set by a VirtualServer action.
+// Because NGINX can only set request headers, added headers are set, and removed headers are set to an empty value,
+// which makes NGINX not pass them.
+func convertHTTPRequestHeaderFilter(f *gateway_v1alpha2.HTTPRequestHeaderFilter) []conf_v1.Header {
+	var headers []conf_v1.Header
+
+	for _, h := range f.Set {
+		headers = append(headers, conf_v1.Header{Name: string(h.Name), Value: h.Value})
+	}
+
+	for _, h := range f.Add {
+		headers = append(headers, conf_v1.Header{Name: string(h.Name), Value: h.Value})
+	}
+
+	for _, name := range f.Remove {
+		headers = append(headers, conf_v1.Header{Name: name, Value: ""})
+	}
+
+	return headers
+}
+
+// convertHTTPRequestRedirectFilter converts a RequestRedirect filter into a VirtualServer redirect.
+func convertHTTPRequestRedirectFilter(f *gateway_v1alpha2.HTTPRequestRedirectFilter) *conf_v1.ActionRedirect {
+	scheme := "${scheme}"
+	if f.Scheme != nil {
+		scheme = *f.Scheme
+	}
+
+	host := "${host}"
+	if f.Hostname != nil {
+		host = string(*f.Hostname)
+	}
+
+	var port string
+	if f.Port != nil {
+		port = fmt.Sprintf(":%d", *f.Port)
+	}
+
+	code := 302
+	if f.StatusCode != nil {
+		code = *f.StatusCode
+	}
+
+	return &conf_v1.ActionRedirect{
+		URL:  fmt.Sprintf("%s://%s%s${request_uri}", scheme, host, port),
+		Code: code,
+	}
+}
+
+func newCondition(conditionType string, status metav1.ConditionStatus, reason string, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:    conditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+func getSortedGatewayHostKeys(m map[string]*gatewayHost) []string {
+	var keys []string
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,556 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gateway_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+func createTestGateway(name string, listeners ...gateway_v1alpha2.Listener) *gateway_v1alpha2.Gateway {
+	return &gateway_v1alpha2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.Now(),
+			Generation:        1,
+		},
+		Spec: gateway_v1alpha2.GatewaySpec{
+			GatewayClassName: "nginx",
+			Listeners:        listeners,
+		},
+	}
+}
+
+func createTestHTTPListener(name string) gateway_v1alpha2.Listener {
+	return gateway_v1alpha2.Listener{
+		Name:     gateway_v1alpha2.SectionName(name),
+		Port:     80,
+		Protocol: gateway_v1alpha2.HTTPProtocolType,
+	}
+}
+
+func createTestHTTPRoute(name string, gatewayName string, host string, rules ...gateway_v1alpha2.HTTPRouteRule) *gateway_v1alpha2.HTTPRoute {
+	return &gateway_v1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.Now(),
+			Generation:        1,
+		},
+		Spec: gateway_v1alpha2.HTTPRouteSpec{
+			CommonRouteSpec: gateway_v1alpha2.CommonRouteSpec{
+				ParentRefs: []gateway_v1alpha2.ParentRef{
+					{
+						Name: gateway_v1alpha2.ObjectName(gatewayName),
+					},
+				},
+			},
+			Hostnames: []gateway_v1alpha2.Hostname{gateway_v1alpha2.Hostname(host)},
+			Rules:     rules,
+		},
+	}
+}
+
+func createTestHTTPBackendRef(service string, port int32, weight int32) gateway_v1alpha2.HTTPBackendRef {
+	p := gateway_v1alpha2.PortNumber(port)
+	return gateway_v1alpha2.HTTPBackendRef{
+		BackendRef: gateway_v1alpha2.BackendRef{
+			BackendObjectReference: gateway_v1alpha2.BackendObjectReference{
+				Name: gateway_v1alpha2.ObjectName(service),
+				Port: &p,
+			},
+			Weight: &weight,
+		},
+	}
+}
+
+func TestTranslateGateway(t *testing.T) {
+	pathPrefix := gateway_v1alpha2.PathMatchPathPrefix
+	teaPath := "/tea"
+
+	gw := createTestGateway("gateway", createTestHTTPListener("http"))
+	route := createTestHTTPRoute("cafe", "gateway", "cafe.example.com",
+		gateway_v1alpha2.HTTPRouteRule{
+			Matches: []gateway_v1alpha2.HTTPRouteMatch{
+				{
+					Path: &gateway_v1alpha2.HTTPPathMatch{
+						Type:  &pathPrefix,
+						Value: &teaPath,
+					},
+				},
+			},
+			BackendRefs: []gateway_v1alpha2.HTTPBackendRef{
+				createTestHTTPBackendRef("tea", 80, 80),
+				createTestHTTPBackendRef("tea-v2", 80, 20),
+			},
+		},
+		gateway_v1alpha2.HTTPRouteRule{
+			BackendRefs: []gateway_v1alpha2.HTTPBackendRef{
+				createTestHTTPBackendRef("coffee", 8080, 1),
+			},
+		},
+	)
+
+	expectedVS := &conf_v1.VirtualServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "gateway_gateway_cafe_example_com",
+			Namespace:         "default",
+			CreationTimestamp: gw.CreationTimestamp,
+			Generation:        1,
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			Host: "cafe.example.com",
+			Upstreams: []conf_v1.Upstream{
+				{
+					Name:    "tea-80",
+					Service: "tea",
+					Port:    80,
+				},
+				{
+					Name:    "tea-v2-80",
+					Service: "tea-v2",
+					Port:    80,
+				},
+				{
+					Name:    "coffee-8080",
+					Service: "coffee",
+					Port:    8080,
+				},
+			},
+			Routes: []conf_v1.Route{
+				{
+					Path: "=/tea",
+					Splits: []conf_v1.Split{
+						{
+							Weight: 80,
+							Action: &conf_v1.Action{
+								Pass: "tea-80",
+							},
+						},
+						{
+							Weight: 20,
+							Action: &conf_v1.Action{
+								Pass: "tea-v2-80",
+							},
+						},
+					},
+				},
+				{
+					Path: "/tea/",
+					Splits: []conf_v1.Split{
+						{
+							Weight: 80,
+							Action: &conf_v1.Action{
+								Pass: "tea-80",
+							},
+						},
+						{
+							Weight: 20,
+							Action: &conf_v1.Action{
+								Pass: "tea-v2-80",
+							},
+						},
+					},
+				},
+				{
+					Path: "/",
+					Action: &conf_v1.Action{
+						Pass: "coffee-8080",
+					},
+				},
+			},
+		},
+	}
+
+	result := translateGateway(gw, []*gateway_v1alpha2.HTTPRoute{route})
+	if len(result) != 1 {
+		t.Fatalf("translateGateway() returned %d configurations but expected 1", len(result))
+	}
+
+	gwConfig := result[0]
+
+	if gwConfig.Host != "cafe.example.com" {
+		t.Errorf("translateGateway() returned host %q but expected %q", gwConfig.Host, "cafe.example.com")
+	}
+	if diff := cmp.Diff(expectedVS, gwConfig.VirtualServer); diff != "" {
+		t.Errorf("translateGateway() returned unexpected VirtualServer (-want +got):\n%s", diff)
+	}
+	if len(gwConfig.Warnings) > 0 {
+		t.Errorf("translateGateway() returned unexpected warnings %v", gwConfig.Warnings)
+	}
+	if len(gwConfig.ListenerStatuses) != 1 || gwConfig.ListenerStatuses[0].AttachedRoutes != 1 {
+		t.Errorf("translateGateway() returned unexpected listener statuses %v", gwConfig.ListenerStatuses)
+	}
+}
+
+func TestTranslateGatewayIgnoresUnsupportedHosts(t *testing.T) {
+	wildcardListenerHost := gateway_v1alpha2.Hostname("*.example.com")
+	listener := createTestHTTPListener("http")
+	listener.Hostname = &wildcardListenerHost
+
+	gw := createTestGateway("gateway", listener)
+
+	tests := []struct {
+		route *gateway_v1alpha2.HTTPRoute
+		msg   string
+	}{
+		{
+			route: createTestHTTPRoute("route", "gateway", "cafe.example.org"),
+			msg:   "host doesn't match listener hostname",
+		},
+		{
+			route: createTestHTTPRoute("route", "gateway", "*.example.com"),
+			msg:   "wildcard host",
+		},
+		{
+			route: createTestHTTPRoute("route", "other-gateway", "cafe.example.com"),
+			msg:   "route attached to another gateway",
+		},
+	}
+
+	for _, test := range tests {
+		result := translateGateway(gw, []*gateway_v1alpha2.HTTPRoute{test.route})
+		if len(result) != 0 {
+			t.Errorf("translateGateway() returned %d configurations but expected 0 for the case of %s", len(result), test.msg)
+		}
+	}
+}
+
+func TestIsEqualForGatewayConfigurations(t *testing.T) {
+	t.Parallel()
+
+	createGatewayConfiguration := func() *GatewayConfiguration {
+		gw := createTestGateway("gateway", createTestHTTPListener("http"))
+		route := createTestHTTPRoute("cafe", "gateway", "cafe.example.com",
+			gateway_v1alpha2.HTTPRouteRule{
+				BackendRefs: []gateway_v1alpha2.HTTPBackendRef{
+					createTestHTTPBackendRef("coffee", 8080, 1),
+				},
+			},
+		)
+
+		return translateGateway(gw, []*gateway_v1alpha2.HTTPRoute{route})[0]
+	}
+
+	gwConfigWithChangedGatewayGeneration := createGatewayConfiguration()
+	gwConfigWithChangedGatewayGeneration.Gateway.Generation++
+
+	gwConfigWithChangedRouteGeneration := createGatewayConfiguration()
+	gwConfigWithChangedRouteGeneration.HTTPRoutes[0].Generation++
+
+	gwConfigWithoutGenerations := createGatewayConfiguration()
+	gwConfigWithoutGenerations.Gateway.Generation = 0
+	gwConfigWithoutGenerations.Gateway.ResourceVersion = "1"
+
+	gwConfigWithChangedResourceVersion := createGatewayConfiguration()
+	gwConfigWithChangedResourceVersion.Gateway.Generation = 0
+	gwConfigWithChangedResourceVersion.Gateway.ResourceVersion = "2"
+
+	gwConfigWithChangedVirtualServer := createGatewayConfiguration()
+	gwConfigWithChangedVirtualServer.VirtualServer.Spec.Routes[0].Action.Pass = "tea-80"
+
+	gwConfigWithoutRoutes := createGatewayConfiguration()
+	gwConfigWithoutRoutes.HTTPRoutes = nil
+
+	tests := []struct {
+		gwConfig1 *GatewayConfiguration
+		gwConfig2 Resource
+		expected  bool
+		msg       string
+	}{
+		{
+			gwConfig1: createGatewayConfiguration(),
+			gwConfig2: createGatewayConfiguration(),
+			expected:  true,
+			msg:       "equal configurations",
+		},
+		{
+			gwConfig1: createGatewayConfiguration(),
+			gwConfig2: gwConfigWithChangedGatewayGeneration,
+			expected:  false,
+			msg:       "gateway generation changed",
+		},
+		{
+			gwConfig1: createGatewayConfiguration(),
+			gwConfig2: gwConfigWithChangedRouteGeneration,
+			expected:  false,
+			msg:       "httproute generation changed",
+		},
+		{
+			gwConfig1: gwConfigWithoutGenerations,
+			gwConfig2: gwConfigWithChangedResourceVersion,
+			expected:  false,
+			msg:       "gateway resource version changed without generations",
+		},
+		{
+			gwConfig1: createGatewayConfiguration(),
+			gwConfig2: gwConfigWithChangedVirtualServer,
+			expected:  false,
+			msg:       "translated virtualserver changed",
+		},
+		{
+			gwConfig1: createGatewayConfiguration(),
+			gwConfig2: gwConfigWithoutRoutes,
+			expected:  false,
+			msg:       "httproutes removed",
+		},
+		{
+			gwConfig1: createGatewayConfiguration(),
+			gwConfig2: NewVirtualServerConfiguration(&conf_v1.VirtualServer{}, nil, nil),
+			expected:  false,
+			msg:       "different resource type",
+		},
+	}
+
+	for _, test := range tests {
+		result := test.gwConfig1.IsEqual(test.gwConfig2)
+		if result != test.expected {
+			t.Errorf("IsEqual() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestNormalizeWeights(t *testing.T) {
+	tests := []struct {
+		weights  []int
+		expected []int
+	}{
+		{
+			weights:  []int{1, 1},
+			expected: []int{50, 50},
+		},
+		{
+			weights:  []int{80, 20},
+			expected: []int{80, 20},
+		},
+		{
+			weights:  []int{1, 2},
+			expected: []int{33, 67},
+		},
+		{
+			weights:  []int{1, 1, 1},
+			expected: []int{34, 33, 33},
+		},
+		{
+			weights:  []int{0, 0},
+			expected: []int{0, 0},
+		},
+	}
+
+	for _, test := range tests {
+		result := normalizeWeights(test.weights)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("normalizeWeights(%v) returned unexpected result (-want +got):\n%s", test.weights, diff)
+		}
+	}
+}
+
+func TestConvertHTTPPathMatch(t *testing.T) {
+	pathPrefix := gateway_v1alpha2.PathMatchPathPrefix
+	exact := gateway_v1alpha2.PathMatchExact
+	regex := gateway_v1alpha2.PathMatchRegularExpression
+	value := "/coffee"
+	valueWithSlash := "/coffee/"
+	rootValue := "/"
+	regexValue := "^/coffee/[a-z]+$"
+
+	tests := []struct {
+		match    *gateway_v1alpha2.HTTPPathMatch
+		expected []string
+	}{
+		{
+			match:    nil,
+			expected: []string{"/"},
+		},
+		{
+			match: &gateway_v1alpha2.HTTPPathMatch{
+				Type:  &pathPrefix,
+				Value: &rootValue,
+			},
+			expected: []string{"/"},
+		},
+		{
+			match: &gateway_v1alpha2.HTTPPathMatch{
+				Type:  &pathPrefix,
+				Value: &value,
+			},
+			expected: []string{"=/coffee", "/coffee/"},
+		},
+		{
+			match: &gateway_v1alpha2.HTTPPathMatch{
+				Type:  &pathPrefix,
+				Value: &valueWithSlash,
+			},
+			expected: []string{"=/coffee", "/coffee/"},
+		},
+		{
+			match: &gateway_v1alpha2.HTTPPathMatch{
+				Type:  &exact,
+				Value: &value,
+			},
+			expected: []string{"=/coffee"},
+		},
+		{
+			match: &gateway_v1alpha2.HTTPPathMatch{
+				Type:  &regex,
+				Value: &regexValue,
+			},
+			expected: []string{"~ ^/coffee/[a-z]+$"},
+		},
+	}
+
+	for _, test := range tests {
+		result, err := convertHTTPPathMatch(test.match)
+		if err != nil {
+			t.Errorf("convertHTTPPathMatch() returned unexpected error %v", err)
+		}
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("convertHTTPPathMatch() mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestConvertHTTPRouteMatchConditions(t *testing.T) {
+	method := gateway_v1alpha2.HTTPMethodPost
+
+	match := gateway_v1alpha2.HTTPRouteMatch{
+		Headers: []gateway_v1alpha2.HTTPHeaderMatch{
+			{
+				Name:  "x-version",
+				Value: "v2",
+			},
+		},
+		QueryParams: []gateway_v1alpha2.HTTPQueryParamMatch{
+			{
+				Name:  "user",
+				Value: "john",
+			},
+		},
+		Method: &method,
+	}
+
+	expected := []conf_v1.Condition{
+		{
+			Header: "x-version",
+			Value:  "v2",
+		},
+		{
+			Argument: "user",
+			Value:    "john",
+		},
+		{
+			Variable: "$request_method",
+			Value:    "POST",
+		},
+	}
+
+	result, err := convertHTTPRouteMatchConditions(match)
+	if err != nil {
+		t.Errorf("convertHTTPRouteMatchConditions() returned unexpected error %v", err)
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("convertHTTPRouteMatchConditions() returned unexpected result (-want +got):\n%s", diff)
+	}
+}
+
+func TestConvertHTTPRouteMatchConditionsFails(t *testing.T) {
+	regex := gateway_v1alpha2.HeaderMatchRegularExpression
+
+	match := gateway_v1alpha2.HTTPRouteMatch{
+		Headers: []gateway_v1alpha2.HTTPHeaderMatch{
+			{
+				Type:  &regex,
+				Name:  "x-version",
+				Value: "v[0-9]",
+			},
+		},
+	}
+
+	_, err := convertHTTPRouteMatchConditions(match)
+	if err == nil {
+		t.Errorf("convertHTTPRouteMatchConditions() returned no error for a regular expression header match")
+	}
+}
+
+func TestConvertHTTPRequestRedirectFilter(t *testing.T) {
+	https := "https"
+	hostname := gateway_v1alpha2.PreciseHostname("www.example.com")
+	port := gateway_v1alpha2.PortNumber(8443)
+	code := 301
+
+	tests := []struct {
+		filter   *gateway_v1alpha2.HTTPRequestRedirectFilter
+		expected *conf_v1.ActionRedirect
+	}{
+		{
+			filter: &gateway_v1alpha2.HTTPRequestRedirectFilter{},
+			expected: &conf_v1.ActionRedirect{
+				URL:  "${scheme}://${host}${request_uri}",
+				Code: 302,
+			},
+		},
+		{
+			filter: &gateway_v1alpha2.HTTPRequestRedirectFilter{
+				Scheme:     &https,
+				Hostname:   &hostname,
+				Port:       &port,
+				StatusCode: &code,
+			},
+			expected: &conf_v1.ActionRedirect{
+				URL:  "https://www.example.com:8443${request_uri}",
+				Code: 301,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		result := convertHTTPRequestRedirectFilter(test.filter)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("convertHTTPRequestRedirectFilter() returned unexpected result (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestMatchesGatewayHostname(t *testing.T) {
+	tests := []struct {
+		listenerHost string
+		host         string
+		expected     bool
+	}{
+		{
+			listenerHost: "",
+			host:         "cafe.example.com",
+			expected:     true,
+		},
+		{
+			listenerHost: "cafe.example.com",
+			host:         "cafe.example.com",
+			expected:     true,
+		},
+		{
+			listenerHost: "*.example.com",
+			host:         "cafe.example.com",
+			expected:     true,
+		},
+		{
+			listenerHost: "*.example.com",
+			host:         "example.com",
+			expected:     false,
+		},
+		{
+			listenerHost: "cafe.example.com",
+			host:         "tea.example.com",
+			expected:     false,
+		},
+	}
+
+	for _, test := range tests {
+		result := matchesGatewayHostname(test.listenerHost, test.host)
+		if result != test.expected {
+			t.Errorf("matchesGatewayHostname(%q, %q) returned %v but expected %v", test.listenerHost, test.host, result, test.expected)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/nginxinc/kubernetes-ingress/internal/k8s/secrets"
 	v1 "k8s.io/api/core/v1"
+	discovery_v1 "k8s.io/api/discovery/v1"
 	networking "k8s.io/api/networking/v1"
 	"k8s.io/client-go/tools/cache"
 
@@ -17,6 +18,7 @@ import (
 	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	gateway_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 )
 
 // createConfigMapHandlers builds the handler funcs for config maps
@@ -94,6 +96,42 @@ func createEndpointHandlers(lbc *LoadBalancerController) cache.ResourceEventHand
 	}
 }
 
+// createEndpointSliceHandlers builds the handler funcs for endpoint slices
+func createEndpointSliceHandlers(lbc *LoadBalancerController) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			endpointSlice := obj.(*discovery_v1.EndpointSlice)
+			glog.V(3).Infof("Adding EndpointSlice: %v", endpointSlice.Name)
+			lbc.AddSyncQueue(obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			endpointSlice, isEndpointSlice := obj.(*discovery_v1.EndpointSlice)
+			if !isEndpointSlice {
+				deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					glog.V(3).Infof("Error received unexpected object: %v", obj)
+					return
+				}
+				endpointSlice, ok = deletedState.Obj.(*discovery_v1.EndpointSlice)
+				if !ok {
+					glog.V(3).Infof("Error DeletedFinalStateUnknown contained non-EndpointSlice object: %v", deletedState.Obj)
+					return
+				}
+			}
+			glog.V(3).Infof("Removing EndpointSlice: %v", endpointSlice.Name)
+			// the task is keyed by the service of the EndpointSlice, which allows us to
+			// update the endpoints of the service even though the EndpointSlice is gone
+			lbc.AddSyncQueue(endpointSlice)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			if !reflect.DeepEqual(old, cur) {
+				glog.V(3).Infof("EndpointSlice %v changed, syncing", cur.(*discovery_v1.EndpointSlice).Name)
+				lbc.AddSyncQueue(cur)
+			}
+		},
+	}
+}
+
 // createIngressHandlers builds the handler funcs for ingresses
 func createIngressHandlers(lbc *LoadBalancerController) cache.ResourceEventHandlerFuncs {
 	return cache.ResourceEventHandlerFuncs{
@@ -456,6 +494,41 @@ func createPolicyHandlers(lbc *LoadBalancerController) cache.ResourceEventHandle
 	}
 }
 
+func createTrafficShiftHandlers(lbc *LoadBalancerController) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			ts := obj.(*conf_v1alpha1.TrafficShift)
+			glog.V(3).Infof("Adding TrafficShift: %v", ts.Name)
+			lbc.AddSyncQueue(ts)
+		},
+		DeleteFunc: func(obj interface{}) {
+			ts, isTs := obj.(*conf_v1alpha1.TrafficShift)
+			if !isTs {
+				deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					glog.V(3).Infof("Error received unexpected object: %v", obj)
+					return
+				}
+				ts, ok = deletedState.Obj.(*conf_v1alpha1.TrafficShift)
+				if !ok {
+					glog.V(3).Infof("Error DeletedFinalStateUnknown contained non-TrafficShift object: %v", deletedState.Obj)
+					return
+				}
+			}
+			glog.V(3).Infof("Removing TrafficShift: %v", ts.Name)
+			lbc.AddSyncQueue(ts)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			curTs := cur.(*conf_v1alpha1.TrafficShift)
+			oldTs := old.(*conf_v1alpha1.TrafficShift)
+			if !reflect.DeepEqual(oldTs.Spec, curTs.Spec) {
+				glog.V(3).Infof("TrafficShift %v changed, syncing", curTs.Name)
+				lbc.AddSyncQueue(curTs)
+			}
+		},
+	}
+}
+
 func createIngressLinkHandlers(lbc *LoadBalancerController) cache.ResourceEventHandlerFuncs {
 	return cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
@@ -498,6 +571,111 @@ func createIngressLinkHandlers(lbc *LoadBalancerController) cache.ResourceEventH
 	}
 }
 
+func createGatewayClassHandlers(lbc *LoadBalancerController) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			gc := obj.(*gateway_v1alpha2.GatewayClass)
+			glog.V(3).Infof("Adding GatewayClass: %v", gc.Name)
+			lbc.AddSyncQueue(gc)
+		},
+		DeleteFunc: func(obj interface{}) {
+			gc, isGc := obj.(*gateway_v1alpha2.GatewayClass)
+			if !isGc {
+				deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					glog.V(3).Infof("Error received unexpected object: %v", obj)
+					return
+				}
+				gc, ok = deletedState.Obj.(*gateway_v1alpha2.GatewayClass)
+				if !ok {
+					glog.V(3).Infof("Error DeletedFinalStateUnknown contained non-GatewayClass object: %v", deletedState.Obj)
+					return
+				}
+			}
+			glog.V(3).Infof("Removing GatewayClass: %v", gc.Name)
+			lbc.AddSyncQueue(gc)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			curGc := cur.(*gateway_v1alpha2.GatewayClass)
+			oldGc := old.(*gateway_v1alpha2.GatewayClass)
+			if !reflect.DeepEqual(oldGc.Spec, curGc.Spec) {
+				glog.V(3).Infof("GatewayClass %v changed, syncing", curGc.Name)
+				lbc.AddSyncQueue(curGc)
+			}
+		},
+	}
+}
+
+func createGatewayHandlers(lbc *LoadBalancerController) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			gw := obj.(*gateway_v1alpha2.Gateway)
+			glog.V(3).Infof("Adding Gateway: %v", gw.Name)
+			lbc.AddSyncQueue(gw)
+		},
+		DeleteFunc: func(obj interface{}) {
+			gw, isGw := obj.(*gateway_v1alpha2.Gateway)
+			if !isGw {
+				deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					glog.V(3).Infof("Error received unexpected object: %v", obj)
+					return
+				}
+				gw, ok = deletedState.Obj.(*gateway_v1alpha2.Gateway)
+				if !ok {
+					glog.V(3).Infof("Error DeletedFinalStateUnknown contained non-Gateway object: %v", deletedState.Obj)
+					return
+				}
+			}
+			glog.V(3).Infof("Removing Gateway: %v", gw.Name)
+			lbc.AddSyncQueue(gw)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			curGw := cur.(*gateway_v1alpha2.Gateway)
+			oldGw := old.(*gateway_v1alpha2.Gateway)
+			if !reflect.DeepEqual(oldGw.Spec, curGw.Spec) {
+				glog.V(3).Infof("Gateway %v changed, syncing", curGw.Name)
+				lbc.AddSyncQueue(curGw)
+			}
+		},
+	}
+}
+
+func createHTTPRouteHandlers(lbc *LoadBalancerController) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			route := obj.(*gateway_v1alpha2.HTTPRoute)
+			glog.V(3).Infof("Adding HTTPRoute: %v", route.Name)
+			lbc.AddSyncQueue(route)
+		},
+		DeleteFunc: func(obj interface{}) {
+			route, isRoute := obj.(*gateway_v1alpha2.HTTPRoute)
+			if !isRoute {
+				deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					glog.V(3).Infof("Error received unexpected object: %v", obj)
+					return
+				}
+				route, ok = deletedState.Obj.(*gateway_v1alpha2.HTTPRoute)
+				if !ok {
+					glog.V(3).Infof("Error DeletedFinalStateUnknown contained non-HTTPRoute object: %v", deletedState.Obj)
+					return
+				}
+			}
+			glog.V(3).Infof("Removing HTTPRoute: %v", route.Name)
+			lbc.AddSyncQueue(route)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			curRoute := cur.(*gateway_v1alpha2.HTTPRoute)
+			oldRoute := old.(*gateway_v1alpha2.HTTPRoute)
+			if !reflect.DeepEqual(oldRoute.Spec, curRoute.Spec) {
+				glog.V(3).Infof("HTTPRoute %v changed, syncing", curRoute.Name)
+				lbc.AddSyncQueue(curRoute)
+			}
+		},
+	}
+}
+
 func createAppProtectPolicyHandlers(lbc *LoadBalancerController) cache.ResourceEventHandlerFuncs {
 	handlers := cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
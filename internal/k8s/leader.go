@@ -84,6 +84,15 @@ func createLeaderHandler(lbc *LoadBalancerController) leaderelection.LeaderCallb
 					glog.V(3).Infof("error updating TransportServers status when starting leading: %v", err)
 				}
 			}
+
+			if lbc.isGatewayAPIEnabled {
+				glog.V(3).Info("updating GatewayClasses and Gateways status")
+
+				err := lbc.updateGatewayAPIStatus()
+				if err != nil {
+					glog.V(3).Infof("error updating GatewayClasses and Gateways status when starting leading: %v", err)
+				}
+			}
 		},
 		OnStoppedLeading: func() {
 			glog.V(3).Info("stopped leading")
@@ -91,7 +91,17 @@ func (rc *secretReferenceChecker) IsReferencedByVirtualServerRoute(_ string, _ s
 	return false
 }
 
-func (rc *secretReferenceChecker) IsReferencedByTransportServer(_ string, _ string, _ *conf_v1alpha1.TransportServer) bool {
+func (rc *secretReferenceChecker) IsReferencedByTransportServer(secretNamespace string, secretName string, ts *conf_v1alpha1.TransportServer) bool {
+	if ts.Namespace != secretNamespace {
+		return false
+	}
+
+	for _, name := range getSecretsForTransportServer(ts) {
+		if name == secretName {
+			return true
+		}
+	}
+
 	return false
 }
 
@@ -217,8 +227,8 @@ func (rc *policyReferenceChecker) IsReferencedByVirtualServerRoute(policyNamespa
 	return false
 }
 
-func (rc *policyReferenceChecker) IsReferencedByTransportServer(_ string, _ string, _ *conf_v1alpha1.TransportServer) bool {
-	return false
+func (rc *policyReferenceChecker) IsReferencedByTransportServer(policyNamespace string, policyName string, ts *conf_v1alpha1.TransportServer) bool {
+	return isPolicyReferenced(getPolicyReferencesForTransportServer(ts), ts.Namespace, policyNamespace, policyName)
 }
 
 // appProtectResourceReferenceChecker is a reference checker for AppProtect related resources.
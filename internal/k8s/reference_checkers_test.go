@@ -309,13 +309,121 @@ func TestSecretIsReferencedByVirtualServerRoute(t *testing.T) {
 }
 
 func TestSecretIsReferencedByTransportServer(t *testing.T) {
-	isPlus := false // doesn't matter for TransportServer
-	rc := newSecretReferenceChecker(isPlus)
+	tests := []struct {
+		ts              *conf_v1alpha1.TransportServer
+		secretNamespace string
+		secretName      string
+		expected        bool
+		msg             string
+	}{
+		{
+			ts: &conf_v1alpha1.TransportServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: conf_v1alpha1.TransportServerSpec{
+					TLS: &conf_v1alpha1.TransportServerTLS{
+						Secret: "test-secret",
+					},
+				},
+			},
+			secretNamespace: "default",
+			secretName:      "test-secret",
+			expected:        true,
+			msg:             "tls secret is referenced",
+		},
+		{
+			ts: &conf_v1alpha1.TransportServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: conf_v1alpha1.TransportServerSpec{
+					TLS: &conf_v1alpha1.TransportServerTLS{
+						Secret:           "test-secret",
+						ClientCertSecret: "ca-secret",
+					},
+				},
+			},
+			secretNamespace: "default",
+			secretName:      "ca-secret",
+			expected:        true,
+			msg:             "client cert secret is referenced",
+		},
+		{
+			ts: &conf_v1alpha1.TransportServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: conf_v1alpha1.TransportServerSpec{
+					Upstreams: []conf_v1alpha1.Upstream{
+						{
+							Name: "db",
+							TLS: &conf_v1alpha1.UpstreamTLS{
+								Enable:            true,
+								TrustedCertSecret: "db-ca-secret",
+							},
+						},
+					},
+				},
+			},
+			secretNamespace: "default",
+			secretName:      "db-ca-secret",
+			expected:        true,
+			msg:             "upstream trusted cert secret is referenced",
+		},
+		{
+			ts: &conf_v1alpha1.TransportServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: conf_v1alpha1.TransportServerSpec{
+					TLS: &conf_v1alpha1.TransportServerTLS{
+						Secret: "test-secret",
+					},
+				},
+			},
+			secretNamespace: "default",
+			secretName:      "some-secret",
+			expected:        false,
+			msg:             "wrong name for tls secret",
+		},
+		{
+			ts: &conf_v1alpha1.TransportServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: conf_v1alpha1.TransportServerSpec{
+					TLS: &conf_v1alpha1.TransportServerTLS{
+						Secret: "test-secret",
+					},
+				},
+			},
+			secretNamespace: "some-namespace",
+			secretName:      "test-secret",
+			expected:        false,
+			msg:             "wrong namespace for tls secret",
+		},
+		{
+			ts: &conf_v1alpha1.TransportServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+			},
+			secretNamespace: "default",
+			secretName:      "test-secret",
+			expected:        false,
+			msg:             "no tls",
+		},
+	}
 
-	// always returns false
-	result := rc.IsReferencedByTransportServer("", "", nil)
-	if result != false {
-		t.Error("IsReferencedByTransportServer() returned true but expected false")
+	for _, test := range tests {
+		isPlus := false // doesn't matter for TransportServer
+		rc := newSecretReferenceChecker(isPlus)
+
+		result := rc.IsReferencedByTransportServer(test.secretNamespace, test.secretName, test.ts)
+		if result != test.expected {
+			t.Errorf("IsReferencedByTransportServer() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
+		}
 	}
 }
 
@@ -643,7 +751,7 @@ func TestIsServiceReferencedByTransportServer(t *testing.T) {
 	}
 }
 
-func TestPolicyIsReferencedByIngressesAndTransportServers(t *testing.T) {
+func TestPolicyIsReferencedByIngresses(t *testing.T) {
 	rc := newPolicyReferenceChecker()
 
 	result := rc.IsReferencedByIngress("", "", nil)
@@ -655,10 +763,91 @@ func TestPolicyIsReferencedByIngressesAndTransportServers(t *testing.T) {
 	if result != false {
 		t.Error("IsReferencedByMinion() returned true but expected false")
 	}
+}
 
-	result = rc.IsReferencedByTransportServer("", "", nil)
-	if result != false {
-		t.Error("IsReferencedByTransportServer() returned true but expected false")
+func TestPolicyIsReferencedByTransportServer(t *testing.T) {
+	tests := []struct {
+		ts              *conf_v1alpha1.TransportServer
+		policyNamespace string
+		policyName      string
+		expected        bool
+		msg             string
+	}{
+		{
+			ts: &conf_v1alpha1.TransportServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: conf_v1alpha1.TransportServerSpec{
+					Policies: []conf_v1alpha1.PolicyReference{
+						{
+							Name: "test-policy",
+						},
+					},
+				},
+			},
+			policyNamespace: "default",
+			policyName:      "test-policy",
+			expected:        true,
+			msg:             "policy is referenced",
+		},
+		{
+			ts: &conf_v1alpha1.TransportServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: conf_v1alpha1.TransportServerSpec{
+					Policies: []conf_v1alpha1.PolicyReference{
+						{
+							Name:      "test-policy",
+							Namespace: "policies",
+						},
+					},
+				},
+			},
+			policyNamespace: "policies",
+			policyName:      "test-policy",
+			expected:        true,
+			msg:             "policy in another namespace is referenced",
+		},
+		{
+			ts: &conf_v1alpha1.TransportServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: conf_v1alpha1.TransportServerSpec{
+					Policies: []conf_v1alpha1.PolicyReference{
+						{
+							Name: "test-policy",
+						},
+					},
+				},
+			},
+			policyNamespace: "policies",
+			policyName:      "test-policy",
+			expected:        false,
+			msg:             "wrong namespace for policy",
+		},
+		{
+			ts: &conf_v1alpha1.TransportServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+			},
+			policyNamespace: "default",
+			policyName:      "test-policy",
+			expected:        false,
+			msg:             "no policies",
+		},
+	}
+
+	for _, test := range tests {
+		rc := newPolicyReferenceChecker()
+
+		result := rc.IsReferencedByTransportServer(test.policyNamespace, test.policyName, test.ts)
+		if result != test.expected {
+			t.Errorf("IsReferencedByTransportServer() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
+		}
 	}
 }
 
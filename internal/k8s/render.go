@@ -0,0 +1,262 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/golang/glog"
+	"github.com/nginxinc/kubernetes-ingress/internal/configs"
+	"github.com/nginxinc/kubernetes-ingress/internal/k8s/appprotect"
+	"github.com/nginxinc/kubernetes-ingress/internal/k8s/appprotectdos"
+	"github.com/nginxinc/kubernetes-ingress/internal/k8s/secrets"
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+	"github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/validation"
+	api_v1 "k8s.io/api/core/v1"
+	discovery_v1 "k8s.io/api/discovery/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// RenderInput holds the input needed to call Render.
+type RenderInput struct {
+	NginxConfigurator            *configs.Configurator
+	Objects                      []runtime.Object
+	IngressClass                 string
+	IsNginxPlus                  bool
+	IsTLSPassthroughEnabled      bool
+	SnippetsEnabled              bool
+	EnableOIDC                   bool
+	EnableGeoIP2                 bool
+	EnableBrotli                 bool
+	EnableAPIKeyAuth             bool
+	VirtualServerValidator       *validation.VirtualServerValidator
+	GlobalConfigurationValidator *validation.GlobalConfigurationValidator
+	TransportServerValidator     *validation.TransportServerValidator
+}
+
+// RenderResult holds the problems and the warnings of the resources processed by Render.
+type RenderResult struct {
+	Problems []ConfigurationProblem
+	// Warnings holds the warnings by the kind and the namespace/name of the resource. For example, VirtualServer default/cafe.
+	Warnings map[string][]string
+}
+
+// Render generates the NGINX configuration for the objects without a cluster.
+// The objects are processed the same way as the resources received from the Kubernetes API:
+// Services, Endpoints, EndpointSlices, Pods, Secrets and Policies are used to resolve the references,
+// while Ingresses, VirtualServers, VirtualServerRoutes, TransportServers and GlobalConfiguration are validated
+// and added to the Configuration. The configuration files are written through the nginx.Manager of the Configurator.
+// If EndpointSlices are among the objects, they are used instead of Endpoints.
+func Render(input RenderInput) (*RenderResult, error) {
+	lbc := &LoadBalancerController{
+		configurator:              input.NginxConfigurator,
+		ingressClass:              input.IngressClass,
+		isNginxPlus:               input.IsNginxPlus,
+		areCustomResourcesEnabled: true,
+		enableOIDC:                input.EnableOIDC,
+		enableGeoIP2:              input.EnableGeoIP2,
+		enableBrotli:              input.EnableBrotli,
+		enableAPIKeyAuth:          input.EnableAPIKeyAuth,
+		svcLister:                 cache.NewStore(keyFunc),
+		secretLister:              cache.NewStore(keyFunc),
+		policyLister:              cache.NewStore(keyFunc),
+		endpointLister:            storeToEndpointLister{Store: cache.NewStore(keyFunc)},
+		endpointSliceLister: indexerToEndpointSliceLister{
+			Indexer: cache.NewIndexer(keyFunc, cache.Indexers{endpointSliceServiceIndex: endpointSliceServiceIndexFunc}),
+		},
+		podLister: indexerToPodLister{
+			Indexer: cache.NewIndexer(keyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}),
+		},
+		appProtectConfiguration: appprotect.NewConfiguration(),
+		dosConfiguration:        appprotectdos.NewConfiguration(false),
+	}
+
+	lbc.secretStore = secrets.NewLocalSecretStore(lbc.configurator)
+
+	lbc.configuration = NewConfiguration(
+		lbc.HasCorrectIngressClass,
+		input.IsNginxPlus,
+		false,
+		false,
+		false,
+		input.VirtualServerValidator,
+		input.GlobalConfigurationValidator,
+		input.TransportServerValidator,
+		input.IsTLSPassthroughEnabled,
+		input.SnippetsEnabled,
+		false,
+	)
+
+	var problems []ConfigurationProblem
+	var resourceObjects []runtime.Object
+
+	for _, obj := range input.Objects {
+		var err error
+
+		switch impl := obj.(type) {
+		case *api_v1.Service:
+			err = lbc.svcLister.Add(impl)
+		case *api_v1.Endpoints:
+			err = lbc.endpointLister.Add(impl)
+		case *discovery_v1.EndpointSlice:
+			lbc.isEndpointSlicesEnabled = true
+			err = lbc.endpointSliceLister.Add(impl)
+		case *api_v1.Pod:
+			err = lbc.podLister.Add(impl)
+		case *api_v1.Secret:
+			err = lbc.secretLister.Add(impl)
+			if err == nil && secrets.IsSupportedSecretType(impl.Type) {
+				lbc.secretStore.AddOrUpdateSecret(impl)
+			}
+		case *conf_v1.Policy:
+			err = lbc.policyLister.Add(impl)
+			if err == nil && lbc.HasCorrectIngressClass(impl) {
+				problems = append(problems, validatePolicyForRender(impl, input.IsNginxPlus, input.EnableOIDC, input.EnableGeoIP2, input.EnableBrotli, input.EnableAPIKeyAuth)...)
+			}
+		case *conf_v1alpha1.GlobalConfiguration:
+			_, _, validationErr := lbc.configuration.AddOrUpdateGlobalConfiguration(impl)
+			if validationErr != nil {
+				problems = append(problems, ConfigurationProblem{
+					Object:  impl,
+					IsError: true,
+					Reason:  "Rejected",
+					Message: fmt.Sprintf("GlobalConfiguration %s is invalid and was rejected: %v", getResourceKey(&impl.ObjectMeta), validationErr),
+				})
+			}
+		case *networking.Ingress, *conf_v1.VirtualServer, *conf_v1.VirtualServerRoute, *conf_v1alpha1.TransportServer:
+			// added after the GlobalConfiguration, so that TransportServers can find their listeners
+			resourceObjects = append(resourceObjects, obj)
+		default:
+			glog.Warningf("Ignoring object of unsupported type %T", obj)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("error adding object %s: %w", getRenderObjectKey(obj), err)
+		}
+	}
+
+	for _, obj := range resourceObjects {
+		var addProblems []ConfigurationProblem
+
+		switch impl := obj.(type) {
+		case *networking.Ingress:
+			_, addProblems = lbc.configuration.AddOrUpdateIngress(impl)
+		case *conf_v1.VirtualServer:
+			_, addProblems = lbc.configuration.AddOrUpdateVirtualServer(impl)
+		case *conf_v1.VirtualServerRoute:
+			_, addProblems = lbc.configuration.AddOrUpdateVirtualServerRoute(impl)
+		case *conf_v1alpha1.TransportServer:
+			_, addProblems = lbc.configuration.AddOrUpdateTransportServer(impl)
+		}
+
+		// validation problems are reported for the added object only, while the problems of the hosts and
+		// the listeners might change when other objects are added, so they are collected at the end.
+		for _, p := range addProblems {
+			if p.IsError && p.Object == obj {
+				problems = append(problems, p)
+			}
+		}
+	}
+
+	problems = append(problems, lbc.configuration.GetProblems()...)
+
+	resources := lbc.configuration.GetResources()
+	resourceExes := lbc.createExtendedResources(resources)
+
+	configuratorWarnings, err := lbc.configurator.AddOrUpdateResources(resourceExes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RenderResult{
+		Problems: problems,
+		Warnings: collectRenderWarnings(resources, configuratorWarnings),
+	}, nil
+}
+
+func validatePolicyForRender(pol *conf_v1.Policy, isNginxPlus bool, enableOIDC bool, enableGeoIP2 bool, enableBrotli bool, enableAPIKeyAuth bool) []ConfigurationProblem {
+	err := validation.ValidatePolicy(pol, validation.PolicyValidationOptions{
+		IsPlus:           isNginxPlus,
+		EnableOIDC:       enableOIDC,
+		EnableGeoIP2:     enableGeoIP2,
+		EnableBrotli:     enableBrotli,
+		EnableAPIKeyAuth: enableAPIKeyAuth,
+	})
+	if err == nil {
+		return nil
+	}
+
+	return []ConfigurationProblem{
+		{
+			Object:  pol,
+			IsError: true,
+			Reason:  "Rejected",
+			Message: fmt.Sprintf("Policy %v/%v is invalid and was rejected: %v", pol.Namespace, pol.Name, err),
+		},
+	}
+}
+
+func collectRenderWarnings(resources []Resource, configuratorWarnings configs.Warnings) map[string][]string {
+	result := make(map[string][]string)
+
+	for _, r := range resources {
+		switch impl := r.(type) {
+		case *IngressConfiguration:
+			key := getRenderObjectKey(impl.Ingress)
+			result[key] = append(result[key], impl.Warnings...)
+
+			for minionKey, warnings := range impl.ChildWarnings {
+				key := fmt.Sprintf("Ingress %s", minionKey)
+				result[key] = append(result[key], warnings...)
+			}
+		case *VirtualServerConfiguration:
+			key := getRenderObjectKey(impl.VirtualServer)
+			result[key] = append(result[key], impl.Warnings...)
+		case *TransportServerConfiguration:
+			key := getRenderObjectKey(impl.TransportServer)
+			result[key] = append(result[key], impl.Warnings...)
+		}
+	}
+
+	for obj, warnings := range configuratorWarnings {
+		key := getRenderObjectKey(obj)
+		result[key] = append(result[key], warnings...)
+	}
+
+	for key, warnings := range result {
+		if len(warnings) == 0 {
+			delete(result, key)
+			continue
+		}
+		sort.Strings(warnings)
+	}
+
+	return result
+}
+
+// getRenderObjectKey returns the kind and the namespace/name of the object. For example, VirtualServer default/cafe.
+func getRenderObjectKey(obj runtime.Object) string {
+	var kind string
+
+	switch obj.(type) {
+	case *networking.Ingress:
+		kind = "Ingress"
+	case *conf_v1.VirtualServer:
+		kind = virtualServerKind
+	case *conf_v1.VirtualServerRoute:
+		kind = "VirtualServerRoute"
+	case *conf_v1alpha1.TransportServer:
+		kind = transportServerKind
+	default:
+		kind = fmt.Sprintf("%T", obj)
+	}
+
+	key, err := keyFunc(obj)
+	if err != nil {
+		return kind
+	}
+
+	return fmt.Sprintf("%s %s", kind, key)
+}
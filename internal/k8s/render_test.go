@@ -0,0 +1,120 @@
+package k8s
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nginxinc/kubernetes-ingress/internal/configs"
+	"github.com/nginxinc/kubernetes-ingress/internal/configs/version1"
+	"github.com/nginxinc/kubernetes-ingress/internal/configs/version2"
+	"github.com/nginxinc/kubernetes-ingress/internal/nginx"
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	"github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/validation"
+	api_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func createTestRenderInput(t *testing.T, outputPath string, objects []runtime.Object) RenderInput {
+	t.Helper()
+
+	templateExecutor, err := version1.NewTemplateExecutor("../configs/version1/nginx.tmpl", "../configs/version1/nginx.ingress.tmpl")
+	if err != nil {
+		t.Fatalf("Failed to create the template executor: %v", err)
+	}
+
+	templateExecutorV2, err := version2.NewTemplateExecutor("../configs/version2/nginx.virtualserver.tmpl", "../configs/version2/nginx.transportserver.tmpl")
+	if err != nil {
+		t.Fatalf("Failed to create the template executor: %v", err)
+	}
+
+	manager, err := nginx.NewFileManager(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to create the file manager: %v", err)
+	}
+
+	cnf := configs.NewConfigurator(manager, &configs.StaticConfigParams{}, configs.NewDefaultConfigParams(false),
+		templateExecutor, templateExecutorV2, false, false, nil, false, nil, false)
+
+	return RenderInput{
+		NginxConfigurator:            cnf,
+		Objects:                      objects,
+		IngressClass:                 "nginx",
+		VirtualServerValidator:       validation.NewVirtualServerValidator(),
+		GlobalConfigurationValidator: validation.NewGlobalConfigurationValidator(map[int]bool{80: true, 443: true}),
+		TransportServerValidator:     validation.NewTransportServerValidator(false, false, false),
+	}
+}
+
+func TestRender(t *testing.T) {
+	svc := &api_v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "coffee-svc", Namespace: "default"},
+		Spec: api_v1.ServiceSpec{
+			Ports: []api_v1.ServicePort{
+				{Port: 80, TargetPort: intstr.FromInt(8080)},
+			},
+		},
+	}
+	endpoints := &api_v1.Endpoints{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "coffee-svc", Namespace: "default"},
+		Subsets: []api_v1.EndpointSubset{
+			{
+				Addresses: []api_v1.EndpointAddress{{IP: "10.0.0.1"}},
+				Ports:     []api_v1.EndpointPort{{Port: 8080}},
+			},
+		},
+	}
+	vs := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "cafe", Namespace: "default"},
+		Spec: conf_v1.VirtualServerSpec{
+			Host: "cafe.example.com",
+			Upstreams: []conf_v1.Upstream{
+				{Name: "coffee", Service: "coffee-svc", Port: 80},
+			},
+			Routes: []conf_v1.Route{
+				{Path: "/coffee", Action: &conf_v1.Action{Pass: "coffee"}},
+			},
+		},
+	}
+	invalidVS := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "invalid", Namespace: "default"},
+		Spec: conf_v1.VirtualServerSpec{
+			Host: "cafe.example.com",
+			Routes: []conf_v1.Route{
+				{Path: "/tea", Action: &conf_v1.Action{Pass: "tea"}},
+			},
+		},
+	}
+
+	outputPath := t.TempDir()
+
+	result, err := Render(createTestRenderInput(t, outputPath, []runtime.Object{svc, endpoints, vs, invalidVS}))
+	if err != nil {
+		t.Fatalf("Render() returned unexpected error: %v", err)
+	}
+
+	var problemObjects []string
+	for _, p := range result.Problems {
+		problemObjects = append(problemObjects, getRenderObjectKey(p.Object))
+	}
+	expectedProblemObjects := []string{"VirtualServer default/invalid"}
+	if diff := cmp.Diff(expectedProblemObjects, problemObjects); diff != "" {
+		t.Errorf("Render() returned unexpected problems (-want +got):\n%s", diff)
+	}
+
+	if len(result.Warnings) != 0 {
+		t.Errorf("Render() returned unexpected warnings: %v", result.Warnings)
+	}
+
+	content, err := os.ReadFile(path.Join(outputPath, "conf.d", "vs_default_cafe.conf"))
+	if err != nil {
+		t.Fatalf("Failed to read the generated config: %v", err)
+	}
+	if !strings.Contains(string(content), "server 10.0.0.1:8080") {
+		t.Errorf("Render() generated config without the upstream server 10.0.0.1:8080:\n%s", content)
+	}
+}
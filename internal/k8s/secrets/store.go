@@ -2,6 +2,7 @@ package secrets
 
 import (
 	"fmt"
+	"sync"
 
 	api_v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -29,7 +30,9 @@ type SecretStore interface {
 
 // LocalSecretStore implements SecretStore interface.
 // It validates the secrets and manages them on the file system (via SecretFileManager).
+// LocalSecretStore is safe for concurrent use.
 type LocalSecretStore struct {
+	lock    sync.Mutex
 	secrets map[string]*SecretReference
 	manager SecretFileManager
 }
@@ -46,6 +49,9 @@ func NewLocalSecretStore(manager SecretFileManager) *LocalSecretStore {
 // The secret will only be updated on the file system if it is valid and if it is already on the file system.
 // If the secret becomes invalid, it will be removed from the filesystem.
 func (s *LocalSecretStore) AddOrUpdateSecret(secret *api_v1.Secret) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
 	secretRef, exists := s.secrets[getResourceKey(&secret.ObjectMeta)]
 	if !exists {
 		secretRef = &SecretReference{Secret: secret}
@@ -69,6 +75,9 @@ func (s *LocalSecretStore) AddOrUpdateSecret(secret *api_v1.Secret) {
 
 // DeleteSecret deletes a secret.
 func (s *LocalSecretStore) DeleteSecret(key string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
 	storedSecret, exists := s.secrets[key]
 	if !exists {
 		return
@@ -87,6 +96,9 @@ func (s *LocalSecretStore) DeleteSecret(key string) {
 // If the secret doesn't exist, is of an unsupported type, or invalid, the Error field will include an error.
 // If the secret is valid but isn't present on the file system, the secret will be written to the file system.
 func (s *LocalSecretStore) GetSecret(key string) *SecretReference {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
 	secretRef, exists := s.secrets[key]
 	if !exists {
 		return &SecretReference{
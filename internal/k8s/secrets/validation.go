@@ -6,6 +6,8 @@ import (
 	"encoding/pem"
 	"fmt"
 	"regexp"
+	"sort"
+	"strings"
 
 	api_v1 "k8s.io/api/core/v1"
 )
@@ -22,6 +24,9 @@ const ClientSecretKey = "client-secret"
 // HtpasswdFileKey is the key of the data field of a Secret where the HTTP basic authorization list must be stored
 const HtpasswdFileKey = "htpasswd"
 
+// SecretTypeAPIKey contains a list of client IDs and their API keys for use in API key authentication. #nosec G101
+const SecretTypeAPIKey api_v1.SecretType = "nginx.org/apikey"
+
 // SecretTypeCA contains a certificate authority for TLS certificate verification. #nosec G101
 const SecretTypeCA api_v1.SecretType = "nginx.org/ca"
 
@@ -125,13 +130,54 @@ func ValidateHtpasswdSecret(secret *api_v1.Secret) error {
 	return nil
 }
 
+// ValidateAPIKeySecret validates the secret. If it is valid, the function returns nil.
+func ValidateAPIKeySecret(secret *api_v1.Secret) error {
+	if secret.Type != SecretTypeAPIKey {
+		return fmt.Errorf("API Key secret must be of the type %v", SecretTypeAPIKey)
+	}
+
+	if len(secret.Data) == 0 {
+		return fmt.Errorf("API Key secret must have at least one client ID with an API key")
+	}
+
+	clients := make(map[string]string)
+
+	for _, clientID := range getSortedDataKeys(secret) {
+		key := string(secret.Data[clientID])
+
+		if key == "" {
+			return fmt.Errorf("API key of the client ID %s must not be empty", clientID)
+		}
+		if strings.ContainsAny(key, " \t\r\n") {
+			return fmt.Errorf("API key of the client ID %s must not contain whitespace characters", clientID)
+		}
+		if other, exists := clients[key]; exists {
+			return fmt.Errorf("API key of the client ID %s is the same as of the client ID %s", clientID, other)
+		}
+
+		clients[key] = clientID
+	}
+
+	return nil
+}
+
+func getSortedDataKeys(secret *api_v1.Secret) []string {
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // IsSupportedSecretType checks if the secret type is supported.
 func IsSupportedSecretType(secretType api_v1.SecretType) bool {
 	return secretType == api_v1.SecretTypeTLS ||
 		secretType == SecretTypeCA ||
 		secretType == SecretTypeJWK ||
 		secretType == SecretTypeOIDC ||
-		secretType == SecretTypeHtpasswd
+		secretType == SecretTypeHtpasswd ||
+		secretType == SecretTypeAPIKey
 }
 
 // ValidateSecret validates the secret. If it is valid, the function returns nil.
@@ -147,6 +193,8 @@ func ValidateSecret(secret *api_v1.Secret) error {
 		return ValidateOIDCSecret(secret)
 	case SecretTypeHtpasswd:
 		return ValidateHtpasswdSecret(secret)
+	case SecretTypeAPIKey:
+		return ValidateAPIKeySecret(secret)
 	}
 
 	return fmt.Errorf("Secret is of the unsupported type %v", secret.Type)
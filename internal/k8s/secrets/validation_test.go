@@ -123,6 +123,105 @@ func TestValidateHtpasswdSecretFails(t *testing.T) {
 	}
 }
 
+func TestValidateAPIKeySecret(t *testing.T) {
+	t.Parallel()
+	secret := &v1.Secret{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "api-key-secret",
+			Namespace: "default",
+		},
+		Type: SecretTypeAPIKey,
+		Data: map[string][]byte{
+			"client1": []byte("password"),
+			"client2": []byte("another-password"),
+		},
+	}
+
+	err := ValidateAPIKeySecret(secret)
+	if err != nil {
+		t.Errorf("ValidateAPIKeySecret() returned error %v", err)
+	}
+}
+
+func TestValidateAPIKeySecretFails(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		secret *v1.Secret
+		msg    string
+	}{
+		{
+			secret: &v1.Secret{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "api-key-secret",
+					Namespace: "default",
+				},
+				Type: "some-type",
+				Data: map[string][]byte{
+					"client1": []byte("password"),
+				},
+			},
+			msg: "Incorrect type for API Key secret",
+		},
+		{
+			secret: &v1.Secret{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "api-key-secret",
+					Namespace: "default",
+				},
+				Type: SecretTypeAPIKey,
+			},
+			msg: "Missing clients for API Key secret",
+		},
+		{
+			secret: &v1.Secret{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "api-key-secret",
+					Namespace: "default",
+				},
+				Type: SecretTypeAPIKey,
+				Data: map[string][]byte{
+					"client1": nil,
+				},
+			},
+			msg: "Empty API key",
+		},
+		{
+			secret: &v1.Secret{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "api-key-secret",
+					Namespace: "default",
+				},
+				Type: SecretTypeAPIKey,
+				Data: map[string][]byte{
+					"client1": []byte("password\n"),
+				},
+			},
+			msg: "API key with a newline",
+		},
+		{
+			secret: &v1.Secret{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "api-key-secret",
+					Namespace: "default",
+				},
+				Type: SecretTypeAPIKey,
+				Data: map[string][]byte{
+					"client1": []byte("password"),
+					"client2": []byte("password"),
+				},
+			},
+			msg: "Same API key for two clients",
+		},
+	}
+
+	for _, test := range tests {
+		err := ValidateAPIKeySecret(test.secret)
+		if err == nil {
+			t.Errorf("ValidateAPIKeySecret() returned no error for the case of %s", test.msg)
+		}
+	}
+}
+
 func TestValidateCASecret(t *testing.T) {
 	t.Parallel()
 	secret := &v1.Secret{
@@ -450,6 +549,19 @@ func TestValidateSecret(t *testing.T) {
 			},
 			msg: "Valid OIDC secret",
 		},
+		{
+			secret: &v1.Secret{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "api-key-secret",
+					Namespace: "default",
+				},
+				Type: SecretTypeAPIKey,
+				Data: map[string][]byte{
+					"client1": []byte("password"),
+				},
+			},
+			msg: "Valid API Key secret",
+		},
 	}
 
 	for _, test := range tests {
@@ -545,6 +657,10 @@ func TestHasCorrectSecretType(t *testing.T) {
 			secretType: SecretTypeHtpasswd,
 			expected:   true,
 		},
+		{
+			secretType: SecretTypeAPIKey,
+			expected:   true,
+		},
 		{
 			secretType: "some-type",
 			expected:   false,
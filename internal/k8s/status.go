@@ -15,8 +15,11 @@ import (
 	k8s_nginx "github.com/nginxinc/kubernetes-ingress/pkg/client/clientset/versioned"
 	api_v1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	typednetworking "k8s.io/client-go/kubernetes/typed/networking/v1"
+	gateway_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gateway_versioned "sigs.k8s.io/gateway-api/pkg/client/clientset/gateway/versioned"
 
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
@@ -26,6 +29,7 @@ import (
 // statusUpdater reports Ingress, VirtualServer and VirtualServerRoute status information via the kubernetes
 // API. For external information, it primarily reports the IP or host of the LoadBalancer Service exposing the
 // Ingress Controller, or an external IP specified in the ConfigMap.
+// For GatewayClass, Gateway and HTTPRoute resources, it reports the status conditions.
 type statusUpdater struct {
 	client                   kubernetes.Interface
 	namespace                string
@@ -44,8 +48,13 @@ type statusUpdater struct {
 	virtualServerRouteLister cache.Store
 	transportServerLister    cache.Store
 	policyLister             cache.Store
+	trafficShiftLister       cache.Store
 	confClient               k8s_nginx.Interface
 	hasCorrectIngressClass   func(interface{}) bool
+	gatewayClient            gateway_versioned.Interface
+	gatewayClassLister       cache.Store
+	gatewayLister            cache.Store
+	httpRouteLister          cache.Store
 }
 
 func (su *statusUpdater) UpdateExternalEndpointsForResources(resource []Resource) error {
@@ -94,6 +103,8 @@ func (su *statusUpdater) UpdateExternalEndpointsForResource(r Resource) error {
 		if failed {
 			return fmt.Errorf("not all Resources updated")
 		}
+	case *GatewayConfiguration:
+		return su.updateGatewayAddresses(impl.Gateway)
 	}
 
 	return nil
@@ -686,3 +697,299 @@ func (su *statusUpdater) retryUpdatePolicyStatus(polCopy *v1.Policy) error {
 
 	return nil
 }
+
+// UpdateTrafficShiftStatus updates the status of a TrafficShift.
+func (su *statusUpdater) UpdateTrafficShiftStatus(ts *conf_v1alpha1.TrafficShift, status conf_v1alpha1.TrafficShiftStatus) error {
+	// Get an up-to-date TrafficShift from the Store
+	tsLatest, exists, err := su.trafficShiftLister.Get(ts)
+	if err != nil {
+		glog.V(3).Infof("error getting TrafficShift from Store: %v", err)
+		return err
+	}
+	if !exists {
+		glog.V(3).Infof("TrafficShift doesn't exist in Store")
+		return nil
+	}
+
+	if tsLatest.(*conf_v1alpha1.TrafficShift).Status == status {
+		return nil
+	}
+
+	tsCopy := tsLatest.(*conf_v1alpha1.TrafficShift).DeepCopy()
+	tsCopy.Status = status
+
+	_, err = su.confClient.K8sV1alpha1().TrafficShifts(tsCopy.Namespace).UpdateStatus(context.TODO(), tsCopy, metav1.UpdateOptions{})
+	if err != nil {
+		glog.V(3).Infof("error setting TrafficShift %v/%v status, retrying: %v", tsCopy.Namespace, tsCopy.Name, err)
+		return su.retryUpdateTrafficShiftStatus(tsCopy)
+	}
+
+	return nil
+}
+
+func (su *statusUpdater) retryUpdateTrafficShiftStatus(tsCopy *conf_v1alpha1.TrafficShift) error {
+	ts, err := su.confClient.K8sV1alpha1().TrafficShifts(tsCopy.Namespace).Get(context.TODO(), tsCopy.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	ts.Status = tsCopy.Status
+	_, err = su.confClient.K8sV1alpha1().TrafficShifts(ts.Namespace).UpdateStatus(context.TODO(), ts, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// mergeConditions returns the conditions with the ObservedGeneration set. The LastTransitionTime of a condition
+// is preserved if the status of the condition didn't change.
+func mergeConditions(existing []metav1.Condition, conditions []metav1.Condition, generation int64) []metav1.Condition {
+	var result []metav1.Condition
+
+	for _, c := range conditions {
+		c.ObservedGeneration = generation
+		c.LastTransitionTime = metav1.Now()
+
+		if old := meta.FindStatusCondition(existing, c.Type); old != nil && old.Status == c.Status {
+			c.LastTransitionTime = old.LastTransitionTime
+		}
+
+		result = append(result, c)
+	}
+
+	return result
+}
+
+func (su *statusUpdater) generateGatewayAddresses() []gateway_v1alpha2.GatewayAddress {
+	var addresses []gateway_v1alpha2.GatewayAddress
+
+	for _, lb := range su.status {
+		addressType := gateway_v1alpha2.IPAddressType
+		value := lb.IP
+
+		if lb.IP == "" {
+			addressType = gateway_v1alpha2.HostnameAddressType
+			value = lb.Hostname
+		}
+
+		addresses = append(addresses, gateway_v1alpha2.GatewayAddress{
+			Type:  &addressType,
+			Value: value,
+		})
+	}
+
+	return addresses
+}
+
+// UpdateGatewayClassStatus marks a GatewayClass as accepted by the Ingress Controller.
+func (su *statusUpdater) UpdateGatewayClassStatus(gc *gateway_v1alpha2.GatewayClass) error {
+	gcLatest, exists, err := su.gatewayClassLister.Get(gc)
+	if err != nil {
+		glog.V(3).Infof("error getting GatewayClass from Store: %v", err)
+		return err
+	}
+	if !exists {
+		glog.V(3).Infof("GatewayClass doesn't exist in Store")
+		return nil
+	}
+
+	gcCopy := gcLatest.(*gateway_v1alpha2.GatewayClass).DeepCopy()
+
+	accepted := newCondition(string(gateway_v1alpha2.GatewayClassConditionStatusAccepted), metav1.ConditionTrue,
+		string(gateway_v1alpha2.GatewayClassReasonAccepted), fmt.Sprintf("GatewayClass is handled by %s", GatewayControllerName))
+	gcCopy.Status.Conditions = mergeConditions(gcCopy.Status.Conditions, []metav1.Condition{accepted}, gcCopy.Generation)
+
+	if reflect.DeepEqual(gcCopy.Status, gcLatest.(*gateway_v1alpha2.GatewayClass).Status) {
+		return nil
+	}
+
+	_, err = su.gatewayClient.GatewayV1alpha2().GatewayClasses().UpdateStatus(context.TODO(), gcCopy, metav1.UpdateOptions{})
+	if err != nil {
+		glog.V(3).Infof("error setting GatewayClass %v status, retrying: %v", gcCopy.Name, err)
+		return su.retryUpdateGatewayClassStatus(gcCopy)
+	}
+	return nil
+}
+
+func (su *statusUpdater) retryUpdateGatewayClassStatus(gcCopy *gateway_v1alpha2.GatewayClass) error {
+	gc, err := su.gatewayClient.GatewayV1alpha2().GatewayClasses().Get(context.TODO(), gcCopy.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	gc.Status = gcCopy.Status
+	_, err = su.gatewayClient.GatewayV1alpha2().GatewayClasses().UpdateStatus(context.TODO(), gc, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateGatewayStatus updates the conditions, the addresses and the listener statuses of a Gateway.
+// The Gateway is always Scheduled. The Ready condition is set to the specified status, reason and message.
+// If listeners is nil, the existing listener statuses are preserved.
+func (su *statusUpdater) UpdateGatewayStatus(gw *gateway_v1alpha2.Gateway, ready bool, reason string, message string, listeners []gateway_v1alpha2.ListenerStatus) error {
+	gwLatest, exists, err := su.gatewayLister.Get(gw)
+	if err != nil {
+		glog.V(3).Infof("error getting Gateway from Store: %v", err)
+		return err
+	}
+	if !exists {
+		glog.V(3).Infof("Gateway doesn't exist in Store")
+		return nil
+	}
+
+	gwCopy := gwLatest.(*gateway_v1alpha2.Gateway).DeepCopy()
+
+	readyStatus := metav1.ConditionFalse
+	if ready {
+		readyStatus = metav1.ConditionTrue
+	}
+
+	conditions := []metav1.Condition{
+		newCondition(string(gateway_v1alpha2.GatewayConditionScheduled), metav1.ConditionTrue, string(gateway_v1alpha2.GatewayReasonScheduled), ""),
+		newCondition(string(gateway_v1alpha2.GatewayConditionReady), readyStatus, reason, message),
+	}
+	gwCopy.Status.Conditions = mergeConditions(gwCopy.Status.Conditions, conditions, gwCopy.Generation)
+
+	if listeners != nil {
+		var listenerStatuses []gateway_v1alpha2.ListenerStatus
+		for _, l := range listeners {
+			var existing []metav1.Condition
+			for _, old := range gwCopy.Status.Listeners {
+				if old.Name == l.Name {
+					existing = old.Conditions
+					break
+				}
+			}
+
+			l.Conditions = mergeConditions(existing, l.Conditions, gwCopy.Generation)
+			listenerStatuses = append(listenerStatuses, l)
+		}
+		gwCopy.Status.Listeners = listenerStatuses
+	}
+	gwCopy.Status.Addresses = su.generateGatewayAddresses()
+
+	if reflect.DeepEqual(gwCopy.Status, gwLatest.(*gateway_v1alpha2.Gateway).Status) {
+		return nil
+	}
+
+	_, err = su.gatewayClient.GatewayV1alpha2().Gateways(gwCopy.Namespace).UpdateStatus(context.TODO(), gwCopy, metav1.UpdateOptions{})
+	if err != nil {
+		glog.V(3).Infof("error setting Gateway %v/%v status, retrying: %v", gwCopy.Namespace, gwCopy.Name, err)
+		return su.retryUpdateGatewayStatus(gwCopy)
+	}
+	return nil
+}
+
+func (su *statusUpdater) updateGatewayAddresses(gw *gateway_v1alpha2.Gateway) error {
+	gwLatest, exists, err := su.gatewayLister.Get(gw)
+	if err != nil {
+		glog.V(3).Infof("error getting Gateway from Store: %v", err)
+		return err
+	}
+	if !exists {
+		glog.V(3).Infof("Gateway doesn't exist in Store")
+		return nil
+	}
+
+	gwCopy := gwLatest.(*gateway_v1alpha2.Gateway).DeepCopy()
+	gwCopy.Status.Addresses = su.generateGatewayAddresses()
+
+	_, err = su.gatewayClient.GatewayV1alpha2().Gateways(gwCopy.Namespace).UpdateStatus(context.TODO(), gwCopy, metav1.UpdateOptions{})
+	if err != nil {
+		glog.V(3).Infof("error setting Gateway %v/%v status, retrying: %v", gwCopy.Namespace, gwCopy.Name, err)
+		return su.retryUpdateGatewayStatus(gwCopy)
+	}
+	return nil
+}
+
+func (su *statusUpdater) retryUpdateGatewayStatus(gwCopy *gateway_v1alpha2.Gateway) error {
+	gw, err := su.gatewayClient.GatewayV1alpha2().Gateways(gwCopy.Namespace).Get(context.TODO(), gwCopy.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	gw.Status = gwCopy.Status
+	_, err = su.gatewayClient.GatewayV1alpha2().Gateways(gw.Namespace).UpdateStatus(context.TODO(), gw, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateHTTPRouteStatus updates the status of an HTTPRoute for the parentRefs that reference the Gateway.
+// The statuses of the parents handled by other controllers are preserved.
+func (su *statusUpdater) UpdateHTTPRouteStatus(route *gateway_v1alpha2.HTTPRoute, gw *gateway_v1alpha2.Gateway, refErrors []string) error {
+	routeLatest, exists, err := su.httpRouteLister.Get(route)
+	if err != nil {
+		glog.V(3).Infof("error getting HTTPRoute from Store: %v", err)
+		return err
+	}
+	if !exists {
+		glog.V(3).Infof("HTTPRoute doesn't exist in Store")
+		return nil
+	}
+
+	routeCopy := routeLatest.(*gateway_v1alpha2.HTTPRoute).DeepCopy()
+
+	resolvedRefs := newCondition(string(gateway_v1alpha2.ConditionRouteResolvedRefs), metav1.ConditionTrue, "ResolvedRefs", "")
+	if len(refErrors) > 0 {
+		resolvedRefs = newCondition(string(gateway_v1alpha2.ConditionRouteResolvedRefs), metav1.ConditionFalse, "RefNotPermitted", strings.Join(refErrors, "; "))
+	}
+
+	conditions := []metav1.Condition{
+		newCondition(string(gateway_v1alpha2.ConditionRouteAccepted), metav1.ConditionTrue, "Accepted", ""),
+		resolvedRefs,
+	}
+
+	for _, ref := range getParentRefsForGateway(routeCopy, gw) {
+		index := -1
+		for i, p := range routeCopy.Status.Parents {
+			if p.ControllerName == GatewayControllerName && reflect.DeepEqual(p.ParentRef, ref) {
+				index = i
+				break
+			}
+		}
+
+		if index == -1 {
+			routeCopy.Status.Parents = append(routeCopy.Status.Parents, gateway_v1alpha2.RouteParentStatus{
+				ParentRef:      ref,
+				ControllerName: GatewayControllerName,
+			})
+			index = len(routeCopy.Status.Parents) - 1
+		}
+
+		parent := &routeCopy.Status.Parents[index]
+		parent.Conditions = mergeConditions(parent.Conditions, conditions, routeCopy.Generation)
+	}
+
+	if reflect.DeepEqual(routeCopy.Status, routeLatest.(*gateway_v1alpha2.HTTPRoute).Status) {
+		return nil
+	}
+
+	_, err = su.gatewayClient.GatewayV1alpha2().HTTPRoutes(routeCopy.Namespace).UpdateStatus(context.TODO(), routeCopy, metav1.UpdateOptions{})
+	if err != nil {
+		glog.V(3).Infof("error setting HTTPRoute %v/%v status, retrying: %v", routeCopy.Namespace, routeCopy.Name, err)
+		return su.retryUpdateHTTPRouteStatus(routeCopy)
+	}
+	return nil
+}
+
+func (su *statusUpdater) retryUpdateHTTPRouteStatus(routeCopy *gateway_v1alpha2.HTTPRoute) error {
+	route, err := su.gatewayClient.GatewayV1alpha2().HTTPRoutes(routeCopy.Namespace).Get(context.TODO(), routeCopy.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	route.Status = routeCopy.Status
+	_, err = su.gatewayClient.GatewayV1alpha2().HTTPRoutes(route.Namespace).UpdateStatus(context.TODO(), route, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
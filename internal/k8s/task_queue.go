@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/nginxinc/kubernetes-ingress/pkg/apis/dos/v1beta1"
@@ -12,41 +13,85 @@ import (
 	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
 	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
 	v1 "k8s.io/api/core/v1"
+	discovery_v1 "k8s.io/api/discovery/v1"
 	networking "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/workqueue"
+	gateway_v1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 )
 
-// taskQueue manages a work queue through an independent worker that
-// invokes the given sync function for every work item inserted.
+const (
+	// taskQueueBaseDelay is the delay before the first retry of a failed task.
+	// The delay doubles with every subsequent failure of the same task.
+	taskQueueBaseDelay = 5 * time.Millisecond
+	// taskQueueMaxDelay is the maximum delay before a retry of a failed task.
+	taskQueueMaxDelay = 5 * time.Minute
+)
+
+// taskQueue manages a rate limited work queue through a pool of workers that
+// invoke the given sync function for every work item inserted.
+// Tasks with the same key are never synced concurrently and are synced in the order
+// they were taken from the queue, even if they are of different kinds. For example, a Service,
+// its Endpoints and its EndpointSlices share the same key.
 type taskQueue struct {
-	// queue is the work queue the worker polls
-	queue *workqueue.Type
+	// queue is the work queue the workers poll
+	queue workqueue.RateLimitingInterface
 	// sync is called for each item in the queue
 	sync func(task)
-	// workerDone is closed when the worker exits
-	workerDone chan struct{}
+	// idle, if set, is called after a task is synced if no other tasks are waiting or being synced
+	idle func()
+	// workers is the number of workers
+	workers int
+	// workersDone is used to wait for the workers to exit
+	workersDone sync.WaitGroup
+
+	// lock protects the fields below
+	lock sync.Mutex
+	// activeKeys contains the keys of the tasks that are being synced
+	activeKeys map[string]bool
+	// deferredTasks contains the tasks taken from the queue while another task with the same key was being synced
+	deferredTasks map[string][]task
+	// requeuedTasks contains the tasks requeued by the sync function while being synced
+	requeuedTasks map[task]bool
 }
 
-// newTaskQueue creates a new task queue with the given sync function.
+// newTaskQueue creates a new task queue with the given sync function and the number of workers.
 // The sync function is called for every element inserted into the queue.
-func newTaskQueue(syncFn func(task)) *taskQueue {
+func newTaskQueue(syncFn func(task), workers int) *taskQueue {
+	if workers < 1 {
+		workers = 1
+	}
+
+	rateLimiter := workqueue.NewItemExponentialFailureRateLimiter(taskQueueBaseDelay, taskQueueMaxDelay)
+
 	return &taskQueue{
-		queue:      workqueue.NewNamed("taskQueue"),
-		sync:       syncFn,
-		workerDone: make(chan struct{}),
+		queue:         workqueue.NewNamedRateLimitingQueue(rateLimiter, "taskQueue"),
+		sync:          syncFn,
+		workers:       workers,
+		activeKeys:    make(map[string]bool),
+		deferredTasks: make(map[string][]task),
+		requeuedTasks: make(map[task]bool),
 	}
 }
 
-// Run begins running the worker for the given duration
+// Run begins running the workers for the given duration.
+// It blocks until stopCh is closed.
 func (tq *taskQueue) Run(period time.Duration, stopCh <-chan struct{}) {
-	wait.Until(tq.worker, period, stopCh)
+	for i := 0; i < tq.workers; i++ {
+		tq.workersDone.Add(1)
+		go func() {
+			defer tq.workersDone.Done()
+			wait.Until(tq.worker, period, stopCh)
+		}()
+	}
+
+	<-stopCh
 }
 
 // Enqueue enqueues ns/name of the given api object in the task queue.
 func (tq *taskQueue) Enqueue(obj interface{}) {
-	key, err := keyFunc(obj)
+	key, err := taskKeyFunc(obj)
 	if err != nil {
 		glog.V(3).Infof("Couldn't get key for object %v: %v", obj, err)
 		return
@@ -62,45 +107,137 @@ func (tq *taskQueue) Enqueue(obj interface{}) {
 	tq.queue.Add(task)
 }
 
-// Requeue adds the task to the queue again and logs the given error
-func (tq *taskQueue) Requeue(task task, err error) {
-	glog.Errorf("Requeuing %v, err %v", task.Key, err)
-	tq.queue.Add(task)
+// Requeue adds the task to the queue again after the backoff delay and logs the given error.
+// The delay grows exponentially with the number of the consecutive failures of the task.
+func (tq *taskQueue) Requeue(t task, err error) {
+	tq.lock.Lock()
+	tq.requeuedTasks[t] = true
+	tq.lock.Unlock()
+
+	glog.Errorf("Requeuing %v after %d failure(s), err %v", t.Key, tq.queue.NumRequeues(t)+1, err)
+	tq.queue.AddRateLimited(t)
 }
 
-// Len returns the length of the queue
+// Len returns the number of the tasks waiting to be synced
 func (tq *taskQueue) Len() int {
-	glog.V(3).Infof("The queue has %v element(s)", tq.queue.Len())
-	return tq.queue.Len()
+	tq.lock.Lock()
+	deferred := 0
+	for _, tasks := range tq.deferredTasks {
+		deferred += len(tasks)
+	}
+	tq.lock.Unlock()
+
+	length := tq.queue.Len() + deferred
+	glog.V(3).Infof("The queue has %v element(s)", length)
+	return length
+}
+
+// isIdle returns true if no tasks are waiting or being synced
+func (tq *taskQueue) isIdle() bool {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+
+	return len(tq.activeKeys) == 0 && len(tq.deferredTasks) == 0 && tq.queue.Len() == 0
 }
 
 // RequeueAfter adds the task to the queue after the given duration
 func (tq *taskQueue) RequeueAfter(t task, err error, after time.Duration) {
 	glog.Errorf("Requeuing %v after %s, err %v", t.Key, after.String(), err)
-	go func(t task, after time.Duration) {
-		time.Sleep(after)
-		tq.queue.Add(t)
-	}(t, after)
+	tq.queue.AddAfter(t, after)
 }
 
-// Worker processes work in the queue through sync.
+// worker processes work in the queue through sync.
 func (tq *taskQueue) worker() {
-	for {
-		t, quit := tq.queue.Get()
-		if quit {
-			close(tq.workerDone)
-			return
+	for tq.processNextTask() {
+	}
+}
+
+// processNextTask syncs the next task from the queue. It returns false when the queue is shut down.
+func (tq *taskQueue) processNextTask() bool {
+	item, quit := tq.queue.Get()
+	if quit {
+		return false
+	}
+
+	t := item.(task)
+
+	if !tq.activate(t) {
+		glog.V(3).Infof("Deferring %v until the sync of another task with the same key completes", t.Key)
+		tq.queue.Done(item)
+		return true
+	}
+
+	glog.V(3).Infof("Syncing %v", t.Key)
+	tq.sync(t)
+
+	tq.deactivate(t)
+	tq.queue.Done(item)
+
+	if tq.idle != nil && tq.isIdle() {
+		tq.idle()
+	}
+
+	return true
+}
+
+// activate marks the key of the task as active. If the key is already active,
+// activate defers the task and returns false.
+func (tq *taskQueue) activate(t task) bool {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+
+	if tq.activeKeys[t.Key] {
+		for _, deferred := range tq.deferredTasks[t.Key] {
+			if deferred == t {
+				return false
+			}
 		}
-		glog.V(3).Infof("Syncing %v", t.(task).Key)
-		tq.sync(t.(task))
-		tq.queue.Done(t)
+		tq.deferredTasks[t.Key] = append(tq.deferredTasks[t.Key], t)
+		return false
 	}
+
+	tq.activeKeys[t.Key] = true
+	return true
 }
 
-// Shutdown shuts down the work queue and waits for the worker to ACK
+// deactivate marks the key of the task as inactive and adds the tasks deferred for the key back to the queue.
+// Unless the task was requeued during the sync, its failure history is cleared.
+func (tq *taskQueue) deactivate(t task) {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+
+	if tq.requeuedTasks[t] {
+		delete(tq.requeuedTasks, t)
+	} else {
+		tq.queue.Forget(t)
+	}
+
+	delete(tq.activeKeys, t.Key)
+
+	for _, deferred := range tq.deferredTasks[t.Key] {
+		tq.queue.Add(deferred)
+	}
+	delete(tq.deferredTasks, t.Key)
+}
+
+// Shutdown shuts down the work queue and waits for the workers to ACK
 func (tq *taskQueue) Shutdown() {
 	tq.queue.ShutDown()
-	<-tq.workerDone
+	tq.workersDone.Wait()
+}
+
+// taskKeyFunc returns the key of the task for the given api object.
+// EndpointSlices are keyed by the namespace/name of their service, so that changes to
+// several EndpointSlices of the same service are collapsed into a single task.
+func taskKeyFunc(obj interface{}) (string, error) {
+	if endpointSlice, ok := obj.(*discovery_v1.EndpointSlice); ok {
+		svcName, exists := endpointSlice.Labels[discovery_v1.LabelServiceName]
+		if !exists || svcName == "" {
+			return "", fmt.Errorf("EndpointSlice %s/%s has no %s label", endpointSlice.Namespace, endpointSlice.Name, discovery_v1.LabelServiceName)
+		}
+		return endpointSlice.Namespace + "/" + svcName, nil
+	}
+	return keyFunc(obj)
 }
 
 // kind represents the kind of the Kubernetes resources of a task
@@ -110,6 +247,7 @@ type kind int
 const (
 	ingress = iota
 	endpoints
+	endpointSlices
 	configMap
 	secret
 	service
@@ -125,6 +263,10 @@ const (
 	appProtectDosLogConf
 	appProtectDosProtectedResource
 	ingressLink
+	gatewayClass
+	gateway
+	httpRoute
+	trafficShift
 )
 
 // task is an element of a taskQueue
@@ -141,6 +283,8 @@ func newTask(key string, obj interface{}) (task, error) {
 		k = ingress
 	case *v1.Endpoints:
 		k = endpoints
+	case *discovery_v1.EndpointSlice:
+		k = endpointSlices
 	case *v1.ConfigMap:
 		k = configMap
 	case *v1.Secret:
@@ -157,6 +301,14 @@ func newTask(key string, obj interface{}) (task, error) {
 		k = globalConfiguration
 	case *conf_v1alpha1.TransportServer:
 		k = transportserver
+	case *conf_v1alpha1.TrafficShift:
+		k = trafficShift
+	case *gateway_v1alpha2.GatewayClass:
+		k = gatewayClass
+	case *gateway_v1alpha2.Gateway:
+		k = gateway
+	case *gateway_v1alpha2.HTTPRoute:
+		k = httpRoute
 	case *v1beta1.DosProtectedResource:
 		k = appProtectDosProtectedResource
 	case *unstructured.Unstructured:
@@ -0,0 +1,172 @@
+package k8s
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestTaskQueueSerializesTasksWithSameKey(t *testing.T) {
+	var lock sync.Mutex
+	active := make(map[string]bool)
+	var synced []task
+	var concurrent []task
+
+	syncFn := func(tsk task) {
+		lock.Lock()
+		if active[tsk.Key] {
+			concurrent = append(concurrent, tsk)
+		}
+		active[tsk.Key] = true
+		lock.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		lock.Lock()
+		delete(active, tsk.Key)
+		synced = append(synced, tsk)
+		lock.Unlock()
+	}
+
+	tq := newTaskQueue(syncFn, 4)
+
+	tasks := []task{
+		{Kind: service, Key: "default/coffee"},
+		{Kind: endpoints, Key: "default/coffee"},
+		{Kind: endpointSlices, Key: "default/coffee"},
+		{Kind: service, Key: "default/tea"},
+		{Kind: endpoints, Key: "default/tea"},
+	}
+	for _, tsk := range tasks {
+		tq.queue.Add(tsk)
+	}
+
+	stopCh := make(chan struct{})
+	go tq.Run(time.Millisecond, stopCh)
+
+	err := wait.PollImmediate(time.Millisecond, time.Second, func() (bool, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(synced) == len(tasks), nil
+	})
+	if err != nil {
+		t.Fatalf("taskQueue didn't sync all tasks: %v", synced)
+	}
+
+	close(stopCh)
+	tq.Shutdown()
+
+	if len(concurrent) > 0 {
+		t.Errorf("taskQueue synced tasks with the same key concurrently: %v", concurrent)
+	}
+
+	var coffeeTasks []task
+	for _, tsk := range synced {
+		if tsk.Key == "default/coffee" {
+			coffeeTasks = append(coffeeTasks, tsk)
+		}
+	}
+	if diff := cmp.Diff(tasks[:3], coffeeTasks); diff != "" {
+		t.Errorf("taskQueue synced tasks with the same key out of order (-want +got):\n%s", diff)
+	}
+}
+
+func TestTaskQueueRequeueWithBackoff(t *testing.T) {
+	tsk := task{Kind: virtualserver, Key: "default/cafe"}
+	failures := 3
+
+	var lock sync.Mutex
+	syncs := 0
+
+	var tq *taskQueue
+	syncFn := func(tsk task) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		syncs++
+		if syncs <= failures {
+			tq.Requeue(tsk, errors.New("sync failed"))
+		}
+	}
+
+	tq = newTaskQueue(syncFn, 1)
+	tq.queue.Add(tsk)
+
+	stopCh := make(chan struct{})
+	go tq.Run(time.Millisecond, stopCh)
+
+	err := wait.PollImmediate(time.Millisecond, time.Second, func() (bool, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		return syncs == failures+1, nil
+	})
+	if err != nil {
+		t.Fatalf("taskQueue synced the task %d times, expected %d", syncs, failures+1)
+	}
+
+	close(stopCh)
+	tq.Shutdown()
+
+	if requeues := tq.queue.NumRequeues(tsk); requeues != 0 {
+		t.Errorf("taskQueue didn't forget the failures of the successfully synced task: %d requeues", requeues)
+	}
+}
+
+func TestTaskQueueIdle(t *testing.T) {
+	var lock sync.Mutex
+	synced := 0
+	var syncedWhenIdle []int
+
+	syncFn := func(tsk task) {
+		time.Sleep(10 * time.Millisecond)
+
+		lock.Lock()
+		defer lock.Unlock()
+		synced++
+	}
+
+	tq := newTaskQueue(syncFn, 4)
+	tq.idle = func() {
+		lock.Lock()
+		defer lock.Unlock()
+		syncedWhenIdle = append(syncedWhenIdle, synced)
+	}
+
+	tasks := []task{
+		{Kind: virtualserver, Key: "default/cafe"},
+		{Kind: virtualserver, Key: "default/tea"},
+		{Kind: ingress, Key: "default/coffee"},
+		{Kind: ingress, Key: "default/coffee"},
+		{Kind: transportserver, Key: "default/dns"},
+	}
+	for _, tsk := range tasks {
+		tq.queue.Add(tsk)
+	}
+
+	stopCh := make(chan struct{})
+	go tq.Run(time.Millisecond, stopCh)
+
+	err := wait.PollImmediate(time.Millisecond, time.Second, func() (bool, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(syncedWhenIdle) > 0, nil
+	})
+	if err != nil {
+		t.Fatalf("taskQueue didn't become idle")
+	}
+
+	close(stopCh)
+	tq.Shutdown()
+
+	// the duplicate task is collapsed by the queue
+	expected := len(tasks) - 1
+	for _, s := range syncedWhenIdle {
+		if s != expected {
+			t.Errorf("taskQueue became idle after syncing %d task(s), expected %d", s, expected)
+		}
+	}
+}
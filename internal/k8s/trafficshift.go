@@ -0,0 +1,495 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/nginxinc/kubernetes-ingress/internal/configs"
+	"github.com/nginxinc/kubernetes-ingress/internal/metrics/collectors"
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+	"github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/validation"
+	"github.com/nginxinc/nginx-plus-go-client/client"
+	api_v1 "k8s.io/api/core/v1"
+)
+
+// trafficShiftCheckPeriod is how often the controller checks if the steps of the TrafficShifts are due.
+const trafficShiftCheckPeriod = 5 * time.Second
+
+// trafficShiftMinStepResponses is the minimum number of the responses of the upstream during a step
+// for the weight of the upstream to be increased. With fewer responses, the weight is kept until the next step.
+const trafficShiftMinStepResponses = 10
+
+// UpstreamResponsesSource reports the number of the responses of the NGINX upstreams.
+type UpstreamResponsesSource interface {
+	// GetUpstreamResponses returns the total number of the responses of the servers of the upstream
+	// and the number of the 5xx responses among them. The numbers only grow until NGINX resets its statistics.
+	GetUpstreamResponses(upstream string) (total uint64, responses5xx uint64, err error)
+}
+
+type plusUpstreamResponsesSource struct {
+	plusClient *client.NginxClient
+}
+
+// NewPlusUpstreamResponsesSource creates an UpstreamResponsesSource that gets the responses from the NGINX Plus API.
+func NewPlusUpstreamResponsesSource(plusClient *client.NginxClient) UpstreamResponsesSource {
+	return &plusUpstreamResponsesSource{
+		plusClient: plusClient,
+	}
+}
+
+func (s *plusUpstreamResponsesSource) GetUpstreamResponses(upstream string) (total uint64, responses5xx uint64, err error) {
+	upstreams, err := s.plusClient.GetUpstreams()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	u, exists := (*upstreams)[upstream]
+	if !exists {
+		return 0, 0, fmt.Errorf("upstream %v not found", upstream)
+	}
+
+	for _, peer := range u.Peers {
+		total += peer.Responses.Total
+		responses5xx += peer.Responses.Responses5xx
+	}
+
+	return total, responses5xx, nil
+}
+
+type latencyUpstreamResponsesSource struct {
+	latencyCollector collectors.LatencyCollector
+}
+
+// NewLatencyUpstreamResponsesSource creates an UpstreamResponsesSource that gets the responses
+// from the latency metrics collector.
+func NewLatencyUpstreamResponsesSource(latencyCollector collectors.LatencyCollector) UpstreamResponsesSource {
+	return &latencyUpstreamResponsesSource{
+		latencyCollector: latencyCollector,
+	}
+}
+
+func (s *latencyUpstreamResponsesSource) GetUpstreamResponses(upstream string) (total uint64, responses5xx uint64, err error) {
+	total, responses5xx = s.latencyCollector.GetUpstreamResponses(upstream)
+	return total, responses5xx, nil
+}
+
+// trafficShifter keeps the state of the TrafficShifts. It must be accessed under the sync lock of the controller.
+type trafficShifter struct {
+	responsesSource UpstreamResponsesSource
+	shifts          map[string]*trafficShiftState
+}
+
+func newTrafficShifter(responsesSource UpstreamResponsesSource) *trafficShifter {
+	return &trafficShifter{
+		responsesSource: responsesSource,
+		shifts:          make(map[string]*trafficShiftState),
+	}
+}
+
+// trafficShiftState is the state of a valid TrafficShift.
+type trafficShiftState struct {
+	spec             conf_v1alpha1.TrafficShiftSpec
+	generation       int64
+	virtualServerKey string
+	nginxUpstream    string
+	interval         time.Duration
+	weight           int
+	state            string
+	reason           string
+	message          string
+	lastStepTime     time.Time
+	// hasResponses tells if total and responses5xx hold the responses of the upstream at the start of the step.
+	hasResponses bool
+	total        uint64
+	responses5xx uint64
+}
+
+func (s *trafficShiftState) maxWeight() int {
+	if s.spec.MaxWeight != nil {
+		return *s.spec.MaxWeight
+	}
+	return 100
+}
+
+// step moves the shift to the next step based on the responses of the upstream during the last step.
+// It returns false if the upstream had too few responses to increase the weight, so that the responses
+// are counted together with the responses of the next step.
+func (s *trafficShiftState) step(total uint64, responses5xx uint64) bool {
+	if responses5xx*100 > uint64(s.spec.ErrorThreshold)*total {
+		ratio := fmt.Sprintf("%d of %d responses of upstream %s during the last step were 5xx, which is more than %d%%",
+			responses5xx, total, s.spec.Upstream, s.spec.ErrorThreshold)
+
+		s.reason = "ErrorThresholdExceeded"
+		if s.spec.OnFailure == conf_v1alpha1.TrafficShiftOnFailureRollback {
+			s.weight = 0
+			s.state = conf_v1alpha1.TrafficShiftStateRolledBack
+			s.message = fmt.Sprintf("The traffic was shifted back from upstream %s: %s", s.spec.Upstream, ratio)
+		} else {
+			s.state = conf_v1alpha1.TrafficShiftStatePaused
+			s.message = fmt.Sprintf("The shift was paused at weight %d: %s", s.weight, ratio)
+		}
+
+		return true
+	}
+
+	if total < trafficShiftMinStepResponses {
+		s.state = conf_v1alpha1.TrafficShiftStateProgressing
+		s.reason = "NotEnoughResponses"
+		s.message = fmt.Sprintf("The weight of upstream %s was kept at %d: upstream %s had %d of at least %d responses",
+			s.spec.Upstream, s.weight, s.spec.Upstream, total, trafficShiftMinStepResponses)
+		return false
+	}
+
+	s.weight += s.spec.StepWeight
+	if s.weight >= s.maxWeight() {
+		s.weight = s.maxWeight()
+		s.state = conf_v1alpha1.TrafficShiftStateSucceeded
+		s.reason = "Succeeded"
+		s.message = fmt.Sprintf("The weight of upstream %s reached %d", s.spec.Upstream, s.weight)
+		return true
+	}
+
+	s.state = conf_v1alpha1.TrafficShiftStateProgressing
+	s.reason = "Stepped"
+	s.message = fmt.Sprintf("The weight of upstream %s was increased to %d", s.spec.Upstream, s.weight)
+	return true
+}
+
+func (s *trafficShiftState) status() conf_v1alpha1.TrafficShiftStatus {
+	return conf_v1alpha1.TrafficShiftStatus{
+		State:        s.state,
+		Reason:       s.reason,
+		Message:      s.message,
+		Weight:       s.weight,
+		LastStepTime: s.lastStepTime.UTC().Format(time.RFC3339),
+	}
+}
+
+// getShiftedSplitWeight returns the weight of the split of the route that passes the requests to the upstream.
+func getShiftedSplitWeight(route conf_v1.Route, upstream string) (int, error) {
+	if len(route.Splits) != 2 {
+		return 0, fmt.Errorf("route %s must have two splits", route.Path)
+	}
+
+	for _, s := range route.Splits {
+		if s.Action == nil {
+			continue
+		}
+		if s.Action.Pass == upstream || (s.Action.Proxy != nil && s.Action.Proxy.Upstream == upstream) {
+			return s.Weight, nil
+		}
+	}
+
+	return 0, fmt.Errorf("none of the splits of route %s passes requests to upstream %s", route.Path, upstream)
+}
+
+// getSplitWeights returns the weights of the splits of the routes of the VirtualServer set by the TrafficShifts.
+func (lbc *LoadBalancerController) getSplitWeights(virtualServer *conf_v1.VirtualServer) map[string]configs.SplitWeight {
+	if lbc.trafficShifter == nil {
+		return nil
+	}
+
+	vsKey := getResourceKey(&virtualServer.ObjectMeta)
+	splitWeights := make(map[string]configs.SplitWeight)
+
+	for _, shift := range lbc.trafficShifter.shifts {
+		if shift.virtualServerKey != vsKey {
+			continue
+		}
+		splitWeights[shift.spec.Route] = configs.SplitWeight{
+			Upstream: shift.spec.Upstream,
+			Weight:   shift.weight,
+		}
+	}
+
+	return splitWeights
+}
+
+// findTrafficShiftsForVirtualServer returns the TrafficShifts that reference the VirtualServer.
+func (lbc *LoadBalancerController) findTrafficShiftsForVirtualServer(vsKey string) []*conf_v1alpha1.TrafficShift {
+	var result []*conf_v1alpha1.TrafficShift
+
+	for _, obj := range lbc.trafficShiftLister.List() {
+		ts := obj.(*conf_v1alpha1.TrafficShift)
+		if ts.Namespace+"/"+ts.Spec.VirtualServer == vsKey {
+			result = append(result, ts)
+		}
+	}
+
+	return result
+}
+
+// enqueueTrafficShiftsForVirtualServer enqueues the TrafficShifts that reference the VirtualServer,
+// so that they are revalidated after the VirtualServer is changed or deleted.
+func (lbc *LoadBalancerController) enqueueTrafficShiftsForVirtualServer(vsKey string) {
+	if lbc.trafficShifter == nil {
+		return
+	}
+
+	for _, ts := range lbc.findTrafficShiftsForVirtualServer(vsKey) {
+		lbc.syncQueue.Enqueue(ts)
+	}
+}
+
+func (lbc *LoadBalancerController) syncTrafficShift(task task) {
+	key := task.Key
+	obj, tsExists, err := lbc.trafficShiftLister.GetByKey(key)
+	if err != nil {
+		lbc.syncQueue.Requeue(task, err)
+		return
+	}
+
+	previous := lbc.trafficShifter.shifts[key]
+	delete(lbc.trafficShifter.shifts, key)
+
+	if !tsExists {
+		glog.V(2).Infof("Deleting TrafficShift: %v\n", key)
+
+		if previous != nil {
+			lbc.updateVirtualServerForTrafficShift(previous.virtualServerKey)
+		}
+		return
+	}
+
+	glog.V(2).Infof("Adding or Updating TrafficShift: %v\n", key)
+
+	ts := obj.(*conf_v1alpha1.TrafficShift)
+
+	shift, err := lbc.createTrafficShiftState(ts, previous)
+	if err != nil {
+		msg := fmt.Sprintf("TrafficShift %v was rejected: %v", key, err)
+		lbc.recorder.Eventf(ts, api_v1.EventTypeWarning, "Rejected", msg)
+		lbc.updateTrafficShiftStatus(ts, conf_v1alpha1.TrafficShiftStatus{
+			State:   conf_v1alpha1.TrafficShiftStateInvalid,
+			Reason:  "Rejected",
+			Message: msg,
+		})
+	} else if shift != nil && shift == previous {
+		// the TrafficShift is still valid for its VirtualServer and its spec didn't change
+		lbc.trafficShifter.shifts[key] = shift
+	} else if shift != nil {
+		lbc.trafficShifter.shifts[key] = shift
+		lbc.recorder.Eventf(ts, api_v1.EventTypeNormal, "AddedOrUpdated", "TrafficShift %v was added or updated", key)
+		lbc.updateTrafficShiftStatus(ts, shift.status())
+		lbc.updateVirtualServerForTrafficShift(shift.virtualServerKey)
+	}
+
+	if previous != nil && (shift == nil || previous.virtualServerKey != shift.virtualServerKey) {
+		lbc.updateVirtualServerForTrafficShift(previous.virtualServerKey)
+	}
+}
+
+// createTrafficShiftState creates the state of the TrafficShift. The state of a TrafficShift that was already handled
+// is resumed from the previous state or, after a restart of the Ingress Controller, from the status of the TrafficShift.
+// It returns nil if the VirtualServer of the TrafficShift is handled by another Ingress Controller.
+func (lbc *LoadBalancerController) createTrafficShiftState(ts *conf_v1alpha1.TrafficShift, previous *trafficShiftState) (*trafficShiftState, error) {
+	if err := validation.ValidateTrafficShift(ts); err != nil {
+		return nil, err
+	}
+
+	vsKey := ts.Namespace + "/" + ts.Spec.VirtualServer
+	obj, vsExists, err := lbc.virtualServerLister.GetByKey(vsKey)
+	if err != nil {
+		return nil, err
+	}
+	if !vsExists {
+		return nil, fmt.Errorf("VirtualServer %s doesn't exist", vsKey)
+	}
+
+	vs := obj.(*conf_v1.VirtualServer)
+	if !lbc.HasCorrectIngressClass(vs) {
+		return nil, nil
+	}
+
+	var route *conf_v1.Route
+	for i := range vs.Spec.Routes {
+		if vs.Spec.Routes[i].Path == ts.Spec.Route {
+			route = &vs.Spec.Routes[i]
+			break
+		}
+	}
+	if route == nil {
+		return nil, fmt.Errorf("route %s doesn't exist in VirtualServer %s", ts.Spec.Route, vsKey)
+	}
+
+	weight, err := getShiftedSplitWeight(*route, ts.Spec.Upstream)
+	if err != nil {
+		return nil, err
+	}
+
+	// the interval is validated
+	interval, _ := time.ParseDuration(ts.Spec.Interval)
+
+	shift := &trafficShiftState{
+		spec:             ts.Spec,
+		generation:       ts.Generation,
+		virtualServerKey: vsKey,
+		nginxUpstream:    configs.GetUpstreamNameForVirtualServer(vs, ts.Spec.Upstream),
+		interval:         interval,
+		weight:           weight,
+		state:            conf_v1alpha1.TrafficShiftStateProgressing,
+		reason:           "Started",
+		message:          fmt.Sprintf("The traffic is shifted to upstream %s from weight %d", ts.Spec.Upstream, weight),
+		lastStepTime:     time.Now(),
+	}
+
+	switch {
+	case previous != nil && previous.generation == ts.Generation:
+		return previous, nil
+	case previous != nil:
+		// the spec changed, for example, to resume a paused shift
+		shift.weight = previous.weight
+		shift.message = fmt.Sprintf("The traffic is shifted to upstream %s from weight %d", ts.Spec.Upstream, shift.weight)
+	case isTrafficShiftStateResumable(ts.Status.State):
+		shift.weight = ts.Status.Weight
+		shift.state = ts.Status.State
+		shift.reason = ts.Status.Reason
+		shift.message = ts.Status.Message
+	}
+
+	if shift.state == conf_v1alpha1.TrafficShiftStateProgressing && shift.weight >= shift.maxWeight() {
+		shift.state = conf_v1alpha1.TrafficShiftStateSucceeded
+		shift.reason = "Succeeded"
+		shift.message = fmt.Sprintf("The weight of upstream %s reached %d", ts.Spec.Upstream, shift.weight)
+	}
+
+	shift.total, shift.responses5xx, err = lbc.trafficShifter.responsesSource.GetUpstreamResponses(shift.nginxUpstream)
+	if err != nil {
+		glog.V(3).Infof("Failed to get the responses of upstream %s for TrafficShift %s/%s: %v", shift.nginxUpstream, ts.Namespace, ts.Name, err)
+	} else {
+		shift.hasResponses = true
+	}
+
+	return shift, nil
+}
+
+func isTrafficShiftStateResumable(state string) bool {
+	switch state {
+	case conf_v1alpha1.TrafficShiftStateProgressing, conf_v1alpha1.TrafficShiftStatePaused,
+		conf_v1alpha1.TrafficShiftStateRolledBack, conf_v1alpha1.TrafficShiftStateSucceeded:
+		return true
+	}
+	return false
+}
+
+// runTrafficShifts steps the progressing TrafficShifts when their steps are due.
+func (lbc *LoadBalancerController) runTrafficShifts() {
+	ticker := time.NewTicker(trafficShiftCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-lbc.ctx.Done():
+			return
+		case now := <-ticker.C:
+			lbc.syncLock.Lock()
+			lbc.stepTrafficShifts(now)
+			lbc.syncLock.Unlock()
+		}
+	}
+}
+
+func (lbc *LoadBalancerController) stepTrafficShifts(now time.Time) {
+	var keys []string
+	for key := range lbc.trafficShifter.shifts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	changedVirtualServers := make(map[string]bool)
+
+	for _, key := range keys {
+		shift := lbc.trafficShifter.shifts[key]
+		if shift.state != conf_v1alpha1.TrafficShiftStateProgressing || now.Sub(shift.lastStepTime) < shift.interval {
+			continue
+		}
+
+		total, responses5xx, err := lbc.trafficShifter.responsesSource.GetUpstreamResponses(shift.nginxUpstream)
+		if err != nil {
+			glog.Warningf("Failed to get the responses of upstream %s for TrafficShift %s: %v", shift.nginxUpstream, key, err)
+			continue
+		}
+
+		if !shift.hasResponses {
+			// the responses at the start of the step are unknown, so the step starts again
+			shift.total, shift.responses5xx, shift.hasResponses = total, responses5xx, true
+			shift.lastStepTime = now
+			continue
+		}
+
+		stepTotal, stepResponses5xx := total-shift.total, responses5xx-shift.responses5xx
+		statisticsReset := total < shift.total || responses5xx < shift.responses5xx
+		if statisticsReset {
+			stepTotal, stepResponses5xx = total, responses5xx
+		}
+
+		weight := shift.weight
+		if shift.step(stepTotal, stepResponses5xx) || statisticsReset {
+			shift.total, shift.responses5xx = total, responses5xx
+		}
+		shift.lastStepTime = now
+
+		glog.V(3).Infof("TrafficShift %s: %s", key, shift.message)
+
+		if shift.weight != weight {
+			changedVirtualServers[shift.virtualServerKey] = true
+		}
+
+		obj, exists, err := lbc.trafficShiftLister.GetByKey(key)
+		if err != nil || !exists {
+			continue
+		}
+		ts := obj.(*conf_v1alpha1.TrafficShift)
+
+		if shift.state != conf_v1alpha1.TrafficShiftStateProgressing {
+			eventType := api_v1.EventTypeNormal
+			if shift.state != conf_v1alpha1.TrafficShiftStateSucceeded {
+				eventType = api_v1.EventTypeWarning
+			}
+			lbc.recorder.Eventf(ts, eventType, shift.reason, shift.message)
+		}
+		lbc.updateTrafficShiftStatus(ts, shift.status())
+	}
+
+	var vsKeys []string
+	for vsKey := range changedVirtualServers {
+		vsKeys = append(vsKeys, vsKey)
+	}
+	sort.Strings(vsKeys)
+
+	for _, vsKey := range vsKeys {
+		lbc.updateVirtualServerForTrafficShift(vsKey)
+	}
+}
+
+// updateVirtualServerForTrafficShift regenerates the config of the VirtualServer to apply the weights of the TrafficShifts.
+func (lbc *LoadBalancerController) updateVirtualServerForTrafficShift(vsKey string) {
+	for _, r := range lbc.configuration.GetResourcesWithFilter(resourceFilter{VirtualServers: true}) {
+		vsConfig := r.(*VirtualServerConfiguration)
+		if getResourceKey(&vsConfig.VirtualServer.ObjectMeta) != vsKey {
+			continue
+		}
+
+		resources := []Resource{r}
+		resourceExes := lbc.createExtendedResources(resources)
+
+		warnings, updateErr := lbc.configurator.AddOrUpdateVirtualServers(resourceExes.VirtualServerExes)
+		lbc.updateResourcesStatusAndEvents(resources, warnings, updateErr)
+
+		return
+	}
+}
+
+func (lbc *LoadBalancerController) updateTrafficShiftStatus(ts *conf_v1alpha1.TrafficShift, status conf_v1alpha1.TrafficShiftStatus) {
+	if !lbc.reportCustomResourceStatusEnabled() {
+		return
+	}
+
+	err := lbc.statusUpdater.UpdateTrafficShiftStatus(ts, status)
+	if err != nil {
+		glog.V(3).Infof("Failed to update TrafficShift %s/%s status: %v", ts.Namespace, ts.Name, err)
+	}
+}
@@ -0,0 +1,334 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestTrafficShiftStep(t *testing.T) {
+	t.Parallel()
+	maxWeight := 50
+
+	tests := []struct {
+		spec           conf_v1alpha1.TrafficShiftSpec
+		weight         int
+		total          uint64
+		responses5xx   uint64
+		expectedWeight int
+		expectedState  string
+		msg            string
+	}{
+		{
+			spec: conf_v1alpha1.TrafficShiftSpec{
+				StepWeight:     10,
+				ErrorThreshold: 5,
+			},
+			weight:         10,
+			total:          100,
+			responses5xx:   5,
+			expectedWeight: 20,
+			expectedState:  conf_v1alpha1.TrafficShiftStateProgressing,
+			msg:            "errors at the threshold",
+		},
+		{
+			spec: conf_v1alpha1.TrafficShiftSpec{
+				StepWeight:     10,
+				ErrorThreshold: 5,
+			},
+			weight:         10,
+			total:          0,
+			responses5xx:   0,
+			expectedWeight: 10,
+			expectedState:  conf_v1alpha1.TrafficShiftStateProgressing,
+			msg:            "no responses",
+		},
+		{
+			spec: conf_v1alpha1.TrafficShiftSpec{
+				StepWeight:     10,
+				ErrorThreshold: 5,
+			},
+			weight:         10,
+			total:          trafficShiftMinStepResponses - 1,
+			responses5xx:   0,
+			expectedWeight: 10,
+			expectedState:  conf_v1alpha1.TrafficShiftStateProgressing,
+			msg:            "fewer responses than the minimum",
+		},
+		{
+			spec: conf_v1alpha1.TrafficShiftSpec{
+				StepWeight:     10,
+				ErrorThreshold: 5,
+			},
+			weight:         10,
+			total:          trafficShiftMinStepResponses,
+			responses5xx:   0,
+			expectedWeight: 20,
+			expectedState:  conf_v1alpha1.TrafficShiftStateProgressing,
+			msg:            "minimum responses",
+		},
+		{
+			spec: conf_v1alpha1.TrafficShiftSpec{
+				StepWeight:     30,
+				ErrorThreshold: 5,
+			},
+			weight:         80,
+			total:          100,
+			responses5xx:   0,
+			expectedWeight: 100,
+			expectedState:  conf_v1alpha1.TrafficShiftStateSucceeded,
+			msg:            "default max weight reached",
+		},
+		{
+			spec: conf_v1alpha1.TrafficShiftSpec{
+				StepWeight:     10,
+				MaxWeight:      &maxWeight,
+				ErrorThreshold: 5,
+			},
+			weight:         40,
+			total:          100,
+			responses5xx:   0,
+			expectedWeight: 50,
+			expectedState:  conf_v1alpha1.TrafficShiftStateSucceeded,
+			msg:            "max weight reached",
+		},
+		{
+			spec: conf_v1alpha1.TrafficShiftSpec{
+				StepWeight:     10,
+				ErrorThreshold: 5,
+			},
+			weight:         30,
+			total:          100,
+			responses5xx:   6,
+			expectedWeight: 30,
+			expectedState:  conf_v1alpha1.TrafficShiftStatePaused,
+			msg:            "errors over the threshold with default onFailure",
+		},
+		{
+			spec: conf_v1alpha1.TrafficShiftSpec{
+				StepWeight:     10,
+				ErrorThreshold: 5,
+				OnFailure:      conf_v1alpha1.TrafficShiftOnFailureRollback,
+			},
+			weight:         30,
+			total:          100,
+			responses5xx:   6,
+			expectedWeight: 0,
+			expectedState:  conf_v1alpha1.TrafficShiftStateRolledBack,
+			msg:            "errors over the threshold with rollback",
+		},
+	}
+
+	for _, test := range tests {
+		shift := &trafficShiftState{
+			spec:   test.spec,
+			weight: test.weight,
+			state:  conf_v1alpha1.TrafficShiftStateProgressing,
+		}
+
+		shift.step(test.total, test.responses5xx)
+
+		if shift.weight != test.expectedWeight {
+			t.Errorf("step() set weight %d, expected %d for the case of %s", shift.weight, test.expectedWeight, test.msg)
+		}
+		if shift.state != test.expectedState {
+			t.Errorf("step() set state %q, expected %q for the case of %s", shift.state, test.expectedState, test.msg)
+		}
+	}
+}
+
+func TestGetShiftedSplitWeight(t *testing.T) {
+	t.Parallel()
+	route := conf_v1.Route{
+		Path: "/tea",
+		Splits: []conf_v1.Split{
+			{
+				Weight: 80,
+				Action: &conf_v1.Action{
+					Pass: "tea-v1",
+				},
+			},
+			{
+				Weight: 20,
+				Action: &conf_v1.Action{
+					Proxy: &conf_v1.ActionProxy{
+						Upstream: "tea-v2",
+					},
+				},
+			},
+		},
+	}
+
+	weight, err := getShiftedSplitWeight(route, "tea-v2")
+	if err != nil {
+		t.Errorf("getShiftedSplitWeight() returned unexpected error: %v", err)
+	}
+	if weight != 20 {
+		t.Errorf("getShiftedSplitWeight() returned %d, expected 20", weight)
+	}
+
+	_, err = getShiftedSplitWeight(route, "coffee")
+	if err == nil {
+		t.Errorf("getShiftedSplitWeight() returned no error for an upstream not in the splits")
+	}
+
+	_, err = getShiftedSplitWeight(conf_v1.Route{Path: "/tea", Action: &conf_v1.Action{Pass: "tea-v2"}}, "tea-v2")
+	if err == nil {
+		t.Errorf("getShiftedSplitWeight() returned no error for a route without splits")
+	}
+}
+
+type fakeUpstreamResponsesSource struct {
+	total        uint64
+	responses5xx uint64
+}
+
+func (s *fakeUpstreamResponsesSource) GetUpstreamResponses(_ string) (uint64, uint64, error) {
+	return s.total, s.responses5xx, nil
+}
+
+func TestStepTrafficShiftsAccumulatesResponsesBelowMinimum(t *testing.T) {
+	t.Parallel()
+	source := &fakeUpstreamResponsesSource{}
+	start := time.Now()
+
+	shift := &trafficShiftState{
+		spec: conf_v1alpha1.TrafficShiftSpec{
+			Upstream:       "tea-v2",
+			StepWeight:     10,
+			ErrorThreshold: 5,
+		},
+		virtualServerKey: "default/cafe",
+		nginxUpstream:    "vs_default_cafe_tea-v2",
+		interval:         time.Minute,
+		weight:           10,
+		state:            conf_v1alpha1.TrafficShiftStateProgressing,
+		lastStepTime:     start,
+		hasResponses:     true,
+	}
+
+	lbc := &LoadBalancerController{
+		trafficShiftLister: cache.NewStore(cache.MetaNamespaceKeyFunc),
+		trafficShifter: &trafficShifter{
+			responsesSource: source,
+			shifts:          map[string]*trafficShiftState{"default/tea-v2": shift},
+		},
+		configuration: createTestConfiguration(),
+	}
+
+	source.total = trafficShiftMinStepResponses - 1
+	lbc.stepTrafficShifts(start.Add(time.Minute))
+
+	if shift.weight != 10 {
+		t.Errorf("stepTrafficShifts() set weight %d after a step with too few responses, expected 10", shift.weight)
+	}
+	if shift.total != 0 {
+		t.Errorf("stepTrafficShifts() moved the start of the responses of the step to %d, expected 0", shift.total)
+	}
+
+	source.total = trafficShiftMinStepResponses + 1
+	lbc.stepTrafficShifts(start.Add(2 * time.Minute))
+
+	if shift.weight != 20 {
+		t.Errorf("stepTrafficShifts() set weight %d after the responses of two steps reached the minimum, expected 20", shift.weight)
+	}
+	if shift.total != trafficShiftMinStepResponses+1 {
+		t.Errorf("stepTrafficShifts() set the start of the responses of the step to %d, expected %d", shift.total, trafficShiftMinStepResponses+1)
+	}
+}
+
+func TestSyncTrafficShiftRevalidatesVirtualServer(t *testing.T) {
+	t.Parallel()
+	vs := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			Routes: []conf_v1.Route{
+				{
+					Path: "/tea",
+					Splits: []conf_v1.Split{
+						{Weight: 90, Action: &conf_v1.Action{Pass: "tea-v1"}},
+						{Weight: 10, Action: &conf_v1.Action{Pass: "tea-v2"}},
+					},
+				},
+			},
+		},
+	}
+	ts := &conf_v1alpha1.TrafficShift{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:       "tea-v2",
+			Namespace:  "default",
+			Generation: 1,
+		},
+		Spec: conf_v1alpha1.TrafficShiftSpec{
+			VirtualServer:  "cafe",
+			Route:          "/tea",
+			Upstream:       "tea-v2",
+			StepWeight:     10,
+			Interval:       "1m",
+			ErrorThreshold: 5,
+		},
+	}
+
+	lbc := &LoadBalancerController{
+		ingressClass:            "nginx",
+		isLeaderElectionEnabled: true,
+		recorder:                record.NewFakeRecorder(10),
+		virtualServerLister:     cache.NewStore(cache.MetaNamespaceKeyFunc),
+		trafficShiftLister:      cache.NewStore(cache.MetaNamespaceKeyFunc),
+		trafficShifter:          newTrafficShifter(&fakeUpstreamResponsesSource{}),
+		configuration:           createTestConfiguration(),
+	}
+	_ = lbc.virtualServerLister.Add(vs)
+	_ = lbc.trafficShiftLister.Add(ts)
+
+	if tss := lbc.findTrafficShiftsForVirtualServer("default/cafe"); len(tss) != 1 || tss[0] != ts {
+		t.Fatalf("findTrafficShiftsForVirtualServer() returned %v, expected the TrafficShift of the VirtualServer", tss)
+	}
+	if tss := lbc.findTrafficShiftsForVirtualServer("default/coffee"); len(tss) != 0 {
+		t.Errorf("findTrafficShiftsForVirtualServer() returned %v for another VirtualServer, expected none", tss)
+	}
+
+	tsTask := task{Kind: trafficShift, Key: "default/tea-v2"}
+
+	lbc.syncTrafficShift(tsTask)
+	shift := lbc.trafficShifter.shifts["default/tea-v2"]
+	if shift == nil {
+		t.Fatalf("syncTrafficShift() didn't add the state of a valid TrafficShift")
+	}
+	shift.weight = 30
+
+	lbc.syncTrafficShift(tsTask)
+	if lbc.trafficShifter.shifts["default/tea-v2"] != shift || shift.weight != 30 {
+		t.Errorf("syncTrafficShift() didn't keep the state of the TrafficShift when its VirtualServer didn't change")
+	}
+
+	updatedVS := vs.DeepCopy()
+	updatedVS.Spec.Routes[0].Splits = nil
+	updatedVS.Spec.Routes[0].Action = &conf_v1.Action{Pass: "tea-v1"}
+	_ = lbc.virtualServerLister.Update(updatedVS)
+
+	lbc.syncTrafficShift(tsTask)
+	if _, exists := lbc.trafficShifter.shifts["default/tea-v2"]; exists {
+		t.Errorf("syncTrafficShift() kept the state of the TrafficShift after the splits of its route were removed")
+	}
+
+	_ = lbc.virtualServerLister.Update(vs)
+	lbc.syncTrafficShift(tsTask)
+	if _, exists := lbc.trafficShifter.shifts["default/tea-v2"]; !exists {
+		t.Fatalf("syncTrafficShift() didn't add the state of the TrafficShift after the splits of its route were restored")
+	}
+
+	_ = lbc.virtualServerLister.Delete(vs)
+	lbc.syncTrafficShift(tsTask)
+	if _, exists := lbc.trafficShifter.shifts["default/tea-v2"]; exists {
+		t.Errorf("syncTrafficShift() kept the state of the TrafficShift after its VirtualServer was deleted")
+	}
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/golang/glog"
 	v1 "k8s.io/api/core/v1"
+	discovery_v1 "k8s.io/api/discovery/v1"
 	networking "k8s.io/api/networking/v1"
 
 	"k8s.io/apimachinery/pkg/labels"
@@ -99,6 +100,42 @@ func (s *storeToEndpointLister) GetServiceEndpoints(svc *v1.Service) (ep v1.Endp
 	return ep, fmt.Errorf("could not find endpoints for service: %v", svc.Name)
 }
 
+// endpointSliceServiceIndex is the name of the index of EndpointSlices by the namespace/name of their service.
+const endpointSliceServiceIndex = "service"
+
+// endpointSliceServiceIndexFunc indexes EndpointSlices by the namespace/name of the service they belong to.
+func endpointSliceServiceIndexFunc(obj interface{}) ([]string, error) {
+	endpointSlice, ok := obj.(*discovery_v1.EndpointSlice)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object %T, expected *EndpointSlice", obj)
+	}
+	svcName, exists := endpointSlice.Labels[discovery_v1.LabelServiceName]
+	if !exists || svcName == "" {
+		return nil, nil
+	}
+	return []string{endpointSlice.Namespace + "/" + svcName}, nil
+}
+
+// indexerToEndpointSliceLister makes an Indexer that lists EndpointSlices
+type indexerToEndpointSliceLister struct {
+	cache.Indexer
+}
+
+// GetServiceEndpointSlices returns the EndpointSlices of a service, matched on the service name label.
+func (l *indexerToEndpointSliceLister) GetServiceEndpointSlices(svc *v1.Service) (endpointSlices []*discovery_v1.EndpointSlice, err error) {
+	objects, err := l.Indexer.ByIndex(endpointSliceServiceIndex, svc.Namespace+"/"+svc.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objects {
+		endpointSlices = append(endpointSlices, obj.(*discovery_v1.EndpointSlice))
+	}
+	if len(endpointSlices) == 0 {
+		return nil, fmt.Errorf("could not find endpoint slices for service: %v", svc.Name)
+	}
+	return endpointSlices, nil
+}
+
 // findPort locates the container port for the given pod and portName.  If the
 // targetPort is a number, use that.  If the targetPort is a string, look that
 // string up in all named ports in all containers in the target pod.  If no
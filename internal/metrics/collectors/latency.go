@@ -49,6 +49,7 @@ type LatencyCollector interface {
 	UpdateUpstreamServerPeerLabels(map[string][]string)
 	DeleteUpstreamServerPeerLabels([]string)
 	DeleteMetrics([]string)
+	GetUpstreamResponses(string) (uint64, uint64)
 	Register(*prometheus.Registry) error
 }
 
@@ -73,6 +74,14 @@ type LatencyMetricsCollector struct {
 	metricsPublishedMap          metricsPublishedMap
 	metricsPublishedMutex        sync.Mutex
 	variableLabelsMutex          sync.RWMutex
+	upstreamResponses            map[string]*upstreamResponses
+	upstreamResponsesMutex       sync.Mutex
+}
+
+// upstreamResponses counts the responses of the servers of an upstream.
+type upstreamResponses struct {
+	total        uint64
+	responses5xx uint64
 }
 
 // NewLatencyMetricsCollector creates a new LatencyMetricsCollector
@@ -94,6 +103,7 @@ func NewLatencyMetricsCollector(
 		upstreamServerLabels:         make(map[string][]string),
 		upstreamServerPeerLabels:     make(map[string][]string),
 		metricsPublishedMap:          make(metricsPublishedMap),
+		upstreamResponses:            make(map[string]*upstreamResponses),
 		upstreamServerLabelNames:     upstreamServerLabelNames,
 		upstreamServerPeerLabelNames: upstreamServerPeerLabelNames,
 	}
@@ -126,17 +136,22 @@ func (l *LatencyMetricsCollector) UpdateUpstreamServerLabels(newLabelValues map[
 	l.variableLabelsMutex.Unlock()
 }
 
-// DeleteUpstreamServerLabels deletes upstream server labels
+// DeleteUpstreamServerLabels deletes upstream server labels and the counted responses of the upstreams
 func (l *LatencyMetricsCollector) DeleteUpstreamServerLabels(upstreamNames []string) {
 	l.variableLabelsMutex.Lock()
 	for _, k := range upstreamNames {
 		delete(l.upstreamServerLabels, k)
 	}
 	l.variableLabelsMutex.Unlock()
+
+	l.deleteUpstreamResponses(upstreamNames)
 }
 
 // DeleteMetrics deletes all metrics published associated with the given upstream server peer names.
+// The counted responses of the upstreams that are left without published metrics are deleted too.
 func (l *LatencyMetricsCollector) DeleteMetrics(upstreamServerPeerNames []string) {
+	upstreams := make(map[string]bool)
+
 	for _, name := range upstreamServerPeerNames {
 		for _, labelValues := range l.listAndDeleteMetricsPublished(name) {
 			success := l.httpLatency.DeleteLabelValues(labelValues...)
@@ -144,6 +159,41 @@ func (l *LatencyMetricsCollector) DeleteMetrics(upstreamServerPeerNames []string
 				glog.Warningf("could not delete metric for upstream server peer: %s with values: %v", name, labelValues)
 			}
 		}
+
+		upstream, _, _ := strings.Cut(name, "/")
+		upstreams[upstream] = true
+	}
+
+	var unusedUpstreams []string
+	for upstream := range upstreams {
+		if !l.hasMetricsPublished(upstream) {
+			unusedUpstreams = append(unusedUpstreams, upstream)
+		}
+	}
+
+	l.deleteUpstreamResponses(unusedUpstreams)
+}
+
+func (l *LatencyMetricsCollector) hasMetricsPublished(upstreamName string) bool {
+	l.metricsPublishedMutex.Lock()
+	defer l.metricsPublishedMutex.Unlock()
+
+	prefix := upstreamName + "/"
+	for key := range l.metricsPublishedMap {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *LatencyMetricsCollector) deleteUpstreamResponses(upstreamNames []string) {
+	l.upstreamResponsesMutex.Lock()
+	defer l.upstreamResponsesMutex.Unlock()
+
+	for _, name := range upstreamNames {
+		delete(l.upstreamResponses, name)
 	}
 }
 
@@ -188,6 +238,37 @@ func (l *LatencyMetricsCollector) RecordLatency(syslogMsg string) {
 	}
 	l.httpLatency.WithLabelValues(labelValues...).Observe(lm.Latency * 1000)
 	l.updateMetricsPublished(lm.Upstream, lm.Server, labelValues)
+	l.updateUpstreamResponses(lm.Upstream, lm.Code)
+}
+
+func (l *LatencyMetricsCollector) updateUpstreamResponses(upstreamName, code string) {
+	l.upstreamResponsesMutex.Lock()
+	defer l.upstreamResponsesMutex.Unlock()
+
+	responses, ok := l.upstreamResponses[upstreamName]
+	if !ok {
+		responses = &upstreamResponses{}
+		l.upstreamResponses[upstreamName] = responses
+	}
+
+	responses.total++
+	if strings.HasPrefix(code, "5") {
+		responses.responses5xx++
+	}
+}
+
+// GetUpstreamResponses returns the total number of the recorded responses of the servers of the upstream
+// and the number of the 5xx responses among them.
+func (l *LatencyMetricsCollector) GetUpstreamResponses(upstreamName string) (total uint64, responses5xx uint64) {
+	l.upstreamResponsesMutex.Lock()
+	defer l.upstreamResponsesMutex.Unlock()
+
+	responses, ok := l.upstreamResponses[upstreamName]
+	if !ok {
+		return 0, 0
+	}
+
+	return responses.total, responses.responses5xx
 }
 
 func (l *LatencyMetricsCollector) updateMetricsPublished(upstreamName, server string, labelValues []string) {
@@ -313,3 +394,6 @@ func (l *LatencyFakeCollector) Register(_ *prometheus.Registry) error { return n
 
 // RecordLatency implements a fake RecordLatency
 func (l *LatencyFakeCollector) RecordLatency(_ string) {}
+
+// GetUpstreamResponses implements a fake GetUpstreamResponses
+func (l *LatencyFakeCollector) GetUpstreamResponses(_ string) (uint64, uint64) { return 0, 0 }
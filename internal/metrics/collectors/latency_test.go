@@ -10,6 +10,7 @@ func newTestLatencyMetricsCollector() *LatencyMetricsCollector {
 		upstreamServerLabels:         make(map[string][]string),
 		upstreamServerPeerLabels:     make(map[string][]string),
 		metricsPublishedMap:          make(metricsPublishedMap),
+		upstreamResponses:            make(map[string]*upstreamResponses),
 		upstreamServerLabelNames:     []string{"service", "resource_type", "resource_name", "resource_namespace"},
 		upstreamServerPeerLabelNames: []string{"pod_name"},
 	}
@@ -232,6 +233,76 @@ func TestMetricsPublished(t *testing.T) {
 	}
 }
 
+func TestUpstreamResponses(t *testing.T) {
+	t.Parallel()
+	collector := newTestLatencyMetricsCollector()
+	collector.updateUpstreamResponses("upstream-1", "200")
+	collector.updateUpstreamResponses("upstream-1", "502")
+	collector.updateUpstreamResponses("upstream-1", "404")
+	collector.updateUpstreamResponses("upstream-2", "503")
+
+	tests := []struct {
+		upstream             string
+		expectedTotal        uint64
+		expectedResponses5xx uint64
+	}{
+		{
+			upstream:             "upstream-1",
+			expectedTotal:        3,
+			expectedResponses5xx: 1,
+		},
+		{
+			upstream:             "upstream-2",
+			expectedTotal:        1,
+			expectedResponses5xx: 1,
+		},
+		{
+			upstream:             "upstream-3",
+			expectedTotal:        0,
+			expectedResponses5xx: 0,
+		},
+	}
+
+	for _, test := range tests {
+		total, responses5xx := collector.GetUpstreamResponses(test.upstream)
+		if total != test.expectedTotal || responses5xx != test.expectedResponses5xx {
+			t.Errorf("GetUpstreamResponses(%q) returned %d, %d, expected %d, %d",
+				test.upstream, total, responses5xx, test.expectedTotal, test.expectedResponses5xx)
+		}
+	}
+}
+
+func TestDeleteUpstreamResponses(t *testing.T) {
+	t.Parallel()
+	collector := NewLatencyMetricsCollector(nil, []string{"service"}, []string{"pod_name"})
+	labelValues := []string{"upstream-1", "10.0.0.1:80", "200", "service-1", "pod-1"}
+
+	collector.updateMetricsPublished("upstream-1", "10.0.0.1:80", labelValues)
+	collector.updateMetricsPublished("upstream-1", "10.0.0.2:80", labelValues)
+	collector.updateUpstreamResponses("upstream-1", "200")
+	collector.updateMetricsPublished("upstream-2", "10.0.0.3:80", labelValues)
+	collector.updateUpstreamResponses("upstream-2", "200")
+	collector.updateUpstreamResponses("upstream-3", "200")
+
+	collector.DeleteMetrics([]string{"upstream-1/10.0.0.1:80"})
+	if _, ok := collector.upstreamResponses["upstream-1"]; !ok {
+		t.Errorf("DeleteMetrics() deleted the responses of upstream-1, which still has a peer")
+	}
+
+	collector.DeleteMetrics([]string{"upstream-1/10.0.0.2:80", "upstream-2/10.0.0.3:80"})
+	if _, ok := collector.upstreamResponses["upstream-1"]; ok {
+		t.Errorf("DeleteMetrics() did not delete the responses of upstream-1 without peers")
+	}
+	if _, ok := collector.upstreamResponses["upstream-2"]; ok {
+		t.Errorf("DeleteMetrics() did not delete the responses of upstream-2 without peers")
+	}
+
+	collector.DeleteUpstreamServerLabels([]string{"upstream-3"})
+	if l := len(collector.upstreamResponses); l != 0 {
+		t.Errorf("DeleteUpstreamServerLabels() left %d upstreams in the responses, expected 0", l)
+	}
+}
+
 func contains(x []string, y [][]string) bool {
 	for _, l := range y {
 		if reflect.DeepEqual(x, l) {
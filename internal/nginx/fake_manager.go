@@ -12,6 +12,7 @@ import (
 // FakeManager provides a fake implementation of the Manager interface.
 type FakeManager struct {
 	confdPath       string
+	streamConfdPath string
 	secretsPath     string
 	dhparamFilename string
 }
@@ -20,6 +21,7 @@ type FakeManager struct {
 func NewFakeManager(confPath string) *FakeManager {
 	return &FakeManager{
 		confdPath:       path.Join(confPath, "conf.d"),
+		streamConfdPath: path.Join(confPath, "stream-conf.d"),
 		secretsPath:     path.Join(confPath, "secrets"),
 		dhparamFilename: path.Join(confPath, "secrets", "dhparam.pem"),
 	}
@@ -53,6 +55,16 @@ func (*FakeManager) ClearAppProtectFolder(name string) {
 	glog.V(3).Infof("Deleting Ap Resource folder %v", name)
 }
 
+// GetFilenameForConfig provides a fake implementation of GetFilenameForConfig.
+func (fm *FakeManager) GetFilenameForConfig(name string) string {
+	return path.Join(fm.confdPath, name+".conf")
+}
+
+// GetFilenameForStreamConfig provides a fake implementation of GetFilenameForStreamConfig.
+func (fm *FakeManager) GetFilenameForStreamConfig(name string) string {
+	return path.Join(fm.streamConfdPath, name+".conf")
+}
+
 // DeleteConfig provides a fake implementation of DeleteConfig.
 func (*FakeManager) DeleteConfig(name string) {
 	glog.V(3).Infof("Deleting config %v", name)
@@ -113,6 +125,12 @@ func (*FakeManager) Reload(_ bool) error {
 	return nil
 }
 
+// Validate provides a fake implementation of Validate.
+func (*FakeManager) Validate() error {
+	glog.V(3).Infof("Testing nginx config")
+	return nil
+}
+
 // Quit provides a fake implementation of Quit.
 func (*FakeManager) Quit() {
 	glog.V(3).Info("Quitting nginx")
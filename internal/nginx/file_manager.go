@@ -0,0 +1,99 @@
+package nginx
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/golang/glog"
+)
+
+// FileManager writes the NGINX configuration files to a local directory without running NGINX.
+// The generated configuration references the files (for example, the files of Secrets) in /etc/nginx,
+// the same way as the configuration generated for the LocalManager.
+// The operations not related to the configuration files are provided by the FakeManager.
+type FileManager struct {
+	*FakeManager
+	outputPath string
+}
+
+// NewFileManager creates a FileManager that writes the configuration files to the outputPath directory.
+func NewFileManager(outputPath string) (*FileManager, error) {
+	for _, dir := range []string{"conf.d", "stream-conf.d", "secrets"} {
+		err := os.MkdirAll(path.Join(outputPath, dir), 0o755)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the output directory: %w", err)
+		}
+	}
+
+	return &FileManager{
+		FakeManager: NewFakeManager("/etc/nginx"),
+		outputPath:  outputPath,
+	}, nil
+}
+
+// CreateMainConfig writes the main NGINX configuration file.
+func (fm *FileManager) CreateMainConfig(content []byte) {
+	createConfig(path.Join(fm.outputPath, "nginx.conf"), content)
+}
+
+// CreateConfig writes a configuration file to the conf.d folder.
+func (fm *FileManager) CreateConfig(name string, content []byte) {
+	createConfig(fm.getFilename("conf.d", name+".conf"), content)
+}
+
+// DeleteConfig deletes a configuration file from the conf.d folder.
+func (fm *FileManager) DeleteConfig(name string) {
+	deleteConfig(fm.getFilename("conf.d", name+".conf"))
+}
+
+// CreateStreamConfig writes a configuration file to the stream-conf.d folder.
+func (fm *FileManager) CreateStreamConfig(name string, content []byte) {
+	createConfig(fm.getFilename("stream-conf.d", name+".conf"), content)
+}
+
+// DeleteStreamConfig deletes a configuration file from the stream-conf.d folder.
+func (fm *FileManager) DeleteStreamConfig(name string) {
+	deleteConfig(fm.getFilename("stream-conf.d", name+".conf"))
+}
+
+// CreateTLSPassthroughHostsConfig writes the configuration file with the TLS Passthrough hosts.
+func (fm *FileManager) CreateTLSPassthroughHostsConfig(content []byte) {
+	createConfig(path.Join(fm.outputPath, "tls-passthrough-hosts.conf"), content)
+}
+
+// CreateSecret writes a secret file to the secrets folder.
+// It returns the name of the file in /etc/nginx/secrets, which is referenced in the configuration.
+func (fm *FileManager) CreateSecret(name string, content []byte, mode os.FileMode) string {
+	filename := fm.getFilename("secrets", name)
+
+	glog.V(3).Infof("Writing secret to %v", filename)
+
+	err := os.WriteFile(filename, content, mode)
+	if err != nil {
+		glog.Errorf("Failed to write secret to %v: %v", filename, err)
+	}
+
+	return fm.GetFilenameForSecret(name)
+}
+
+// DeleteSecret deletes a secret file from the secrets folder.
+func (fm *FileManager) DeleteSecret(name string) {
+	deleteConfig(fm.getFilename("secrets", name))
+}
+
+// CreateDHParam writes the dhparam.pem file to the secrets folder.
+func (fm *FileManager) CreateDHParam(content string) (string, error) {
+	filename := fm.getFilename("secrets", "dhparam.pem")
+
+	err := createFileAndWrite(filename, []byte(content))
+	if err != nil {
+		return fm.dhparamFilename, fmt.Errorf("Failed to write dhparam file to %v: %w", filename, err)
+	}
+
+	return fm.dhparamFilename, nil
+}
+
+func (fm *FileManager) getFilename(dir string, name string) string {
+	return path.Join(fm.outputPath, dir, name)
+}
@@ -1,6 +1,7 @@
 package nginx
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -27,6 +28,8 @@ const (
 	JWKSecretFileMode = 0o644
 	// HtpasswdSecretFileMode defines the default filemode for HTTP basic auth user files.
 	HtpasswdSecretFileMode = 0o644
+	// APIKeySecretFileMode defines the default filemode for files with hashed API keys.
+	APIKeySecretFileMode = 0o600
 
 	configFileMode               = 0o644
 	jsonFileForOpenTracingTracer = "/var/lib/nginx/tracer-config.json"
@@ -73,6 +76,9 @@ type Manager interface {
 	Start(done chan error)
 	Version() string
 	Reload(isEndpointsUpdate bool) error
+	Validate() error
+	GetFilenameForConfig(name string) string
+	GetFilenameForStreamConfig(name string) string
 	Quit()
 	UpdateConfigVersionFile(openTracing bool)
 	SetPlusClients(plusClient *client.NginxClient, plusConfigVersionCheckClient *http.Client)
@@ -108,6 +114,8 @@ type LocalManager struct {
 	appProtectPluginPid          int
 	appProtectAgentPid           int
 	appProtectDosAgentPid        int
+	configBackup                 *configBackup
+	configStaging                *configStaging
 }
 
 // NewLocalManager creates a LocalManager.
@@ -130,25 +138,42 @@ func NewLocalManager(confPath string, debug bool, mc collectors.ManagerCollector
 		configVersion:               0,
 		verifyClient:                newVerifyClient(timeout),
 		metricsCollector:            mc,
+		configBackup:                newConfigBackup(),
+		configStaging:               newConfigStaging(confPath, path.Join(confPath, "staging")),
+	}
+
+	if err := manager.configStaging.init(); err != nil {
+		glog.Fatalf("error creating the staged copy of the config: %v", err)
 	}
 
 	return &manager
 }
 
 // CreateMainConfig creates the main NGINX configuration file. If the file already exists, it will be overridden.
+// The file is staged and applied on the next start or successful test of the config.
 func (lm *LocalManager) CreateMainConfig(content []byte) {
-	glog.V(3).Infof("Writing main config to %v", lm.mainConfFilename)
-	glog.V(3).Infof(string(content))
-
-	err := createFileAndWrite(lm.mainConfFilename, content)
+	err := lm.configStaging.stage(lm.mainConfFilename, content)
 	if err != nil {
 		glog.Fatalf("Failed to write main config: %v", err)
 	}
 }
 
 // CreateConfig creates a configuration file. If the file already exists, it will be overridden.
+// The file is staged and applied on the next start or successful test of the config.
 func (lm *LocalManager) CreateConfig(name string, content []byte) {
-	createConfig(lm.getFilenameForConfig(name), content)
+	lm.stageConfig(lm.GetFilenameForConfig(name), content)
+}
+
+func (lm *LocalManager) stageConfig(filename string, content []byte) {
+	if err := lm.configStaging.stage(filename, content); err != nil {
+		glog.Fatalf("Failed to write config to %v: %v", filename, err)
+	}
+}
+
+func (lm *LocalManager) stageConfigDeletion(filename string) {
+	if err := lm.configStaging.stageDeletion(filename); err != nil {
+		glog.Warningf("Failed to delete config from %v: %v", filename, err)
+	}
 }
 
 func createConfig(filename string, content []byte) {
@@ -163,7 +188,7 @@ func createConfig(filename string, content []byte) {
 
 // DeleteConfig deletes the configuration file from the conf.d folder.
 func (lm *LocalManager) DeleteConfig(name string) {
-	deleteConfig(lm.getFilenameForConfig(name))
+	lm.stageConfigDeletion(lm.GetFilenameForConfig(name))
 }
 
 func deleteConfig(filename string) {
@@ -174,22 +199,24 @@ func deleteConfig(filename string) {
 	}
 }
 
-func (lm *LocalManager) getFilenameForConfig(name string) string {
+// GetFilenameForConfig constructs the filename for the configuration file.
+func (lm *LocalManager) GetFilenameForConfig(name string) string {
 	return path.Join(lm.confdPath, name+".conf")
 }
 
 // CreateStreamConfig creates a configuration file for stream module.
 // If the file already exists, it will be overridden.
 func (lm *LocalManager) CreateStreamConfig(name string, content []byte) {
-	createConfig(lm.getFilenameForStreamConfig(name), content)
+	lm.stageConfig(lm.GetFilenameForStreamConfig(name), content)
 }
 
 // DeleteStreamConfig deletes the configuration file from the stream-conf.d folder.
 func (lm *LocalManager) DeleteStreamConfig(name string) {
-	deleteConfig(lm.getFilenameForStreamConfig(name))
+	lm.stageConfigDeletion(lm.GetFilenameForStreamConfig(name))
 }
 
-func (lm *LocalManager) getFilenameForStreamConfig(name string) string {
+// GetFilenameForStreamConfig constructs the filename for the configuration file for stream module.
+func (lm *LocalManager) GetFilenameForStreamConfig(name string) string {
 	return path.Join(lm.streamConfdPath, name+".conf")
 }
 
@@ -198,7 +225,7 @@ func (lm *LocalManager) getFilenameForStreamConfig(name string) string {
 // If the file already exists, it will be overridden.
 func (lm *LocalManager) CreateTLSPassthroughHostsConfig(content []byte) {
 	glog.V(3).Infof("Writing TLS Passthrough Hosts config file to %v", lm.tlsPassthroughHostsFilename)
-	createConfig(lm.tlsPassthroughHostsFilename, content)
+	lm.stageConfig(lm.tlsPassthroughHostsFilename, content)
 }
 
 // CreateSecret creates a secret file with the specified name, content and mode. If the file already exists,
@@ -275,6 +302,10 @@ func (lm *LocalManager) ClearAppProtectFolder(name string) {
 func (lm *LocalManager) Start(done chan error) {
 	glog.V(3).Info("Starting nginx")
 
+	if err := lm.configStaging.apply(lm.configBackup); err != nil {
+		glog.Fatalf("Failed to apply the config: %v", err)
+	}
+
 	binaryFilename := getBinaryFileName(lm.debug)
 	cmd := exec.Command(binaryFilename, "-e", "stderr") // #nosec G204
 	cmd.Stdout = os.Stdout
@@ -290,36 +321,117 @@ func (lm *LocalManager) Start(done chan error) {
 	if err != nil {
 		glog.Fatalf("Could not get newest config version: %v", err)
 	}
+
+	// NGINX is running with the current config, which is the last known good one
+	lm.configBackup.clear()
 }
 
 // Reload reloads NGINX.
+// Before reloading, the staged config is tested. If NGINX rejects the config files of some resources, the changes
+// of those files are discarded and the changes of the other files are applied, so that a resource with an invalid
+// config doesn't block the changes of other resources. In that case, the returned error is a *ValidationError
+// with Applied set to true. If the test fails for another reason, all staged changes are discarded and the config
+// NGINX runs with is not changed. If the reload fails, the config files changed since the last successful reload
+// are rolled back. This way, the failure doesn't affect the next reloads.
 func (lm *LocalManager) Reload(isEndpointsUpdate bool) error {
+	t1 := time.Now()
+
+	rejectedErr, err := rejectConfigFiles(lm.configStaging, lm.Validate)
+	if err != nil {
+		lm.metricsCollector.IncNginxReloadErrors()
+		lm.configStaging.discard()
+		return fmt.Errorf("nginx config test failed, the config was rolled back to the last valid one: %w", err)
+	}
+
+	if err := lm.configStaging.apply(lm.configBackup); err != nil {
+		lm.metricsCollector.IncNginxReloadErrors()
+		lm.rollback()
+		return fmt.Errorf("%w, the config was rolled back to the last valid one", err)
+	}
+
+	if err := lm.reload(); err != nil {
+		lm.metricsCollector.IncNginxReloadErrors()
+		lm.rollback()
+		// NGINX might have applied the invalid config, so it has to be reloaded with the rolled back one
+		if rollbackErr := lm.reload(); rollbackErr != nil {
+			glog.Errorf("Failed to reload nginx with the rolled back config: %v", rollbackErr)
+		}
+		return fmt.Errorf("%w, the config was rolled back to the last valid one", err)
+	}
+
+	lm.configBackup.clear()
+
+	lm.metricsCollector.IncNginxReloadCount(isEndpointsUpdate)
+
+	t2 := time.Now()
+	lm.metricsCollector.UpdateLastReloadTime(t2.Sub(t1))
+
+	if rejectedErr != nil {
+		lm.metricsCollector.IncNginxReloadErrors()
+		rejectedErr.Applied = true
+		return fmt.Errorf("nginx config test failed, the rejected config files were rolled back to the last valid ones: %w", rejectedErr)
+	}
+
+	return nil
+}
+
+func (lm *LocalManager) reload() error {
 	// write a new config version
 	lm.configVersion++
 	lm.UpdateConfigVersionFile(lm.OpenTracing)
 
 	glog.V(3).Infof("Reloading nginx with configVersion: %v", lm.configVersion)
 
-	t1 := time.Now()
-
 	binaryFilename := getBinaryFileName(lm.debug)
 	if err := shellOut(fmt.Sprintf("%v -s %v -e stderr", binaryFilename, "reload")); err != nil {
-		lm.metricsCollector.IncNginxReloadErrors()
 		return fmt.Errorf("nginx reload failed: %w", err)
 	}
 	err := lm.verifyClient.WaitForCorrectVersion(lm.configVersion)
 	if err != nil {
-		lm.metricsCollector.IncNginxReloadErrors()
 		return fmt.Errorf("could not get newest config version: %w", err)
 	}
 
-	lm.metricsCollector.IncNginxReloadCount(isEndpointsUpdate)
-
-	t2 := time.Now()
-	lm.metricsCollector.UpdateLastReloadTime(t2.Sub(t1))
 	return nil
 }
 
+// Validate tests the staged config with NGINX. If NGINX rejects the config, the returned error is
+// a *ValidationError with the errors in the config files.
+func (lm *LocalManager) Validate() error {
+	binaryFilename := getBinaryFileName(lm.debug)
+	stagedMainConfFilename := lm.configStaging.mainConfFilename()
+
+	glog.V(3).Infof("Testing the config %v", stagedMainConfFilename)
+
+	out, err := exec.Command(binaryFilename, "-t", "-q", "-e", "stderr", "-c", stagedMainConfFilename).CombinedOutput() // #nosec G204
+	if err == nil {
+		return nil
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return fmt.Errorf("failed to test the config: %w", err)
+	}
+
+	return parseConfigTestOutput(string(out), lm.configStaging.configFilename)
+}
+
+// rollback restores the config files changed since the last successful reload.
+func (lm *LocalManager) rollback() {
+	glog.Warningf("Rolling back the nginx config to the last valid one")
+
+	filenames := lm.configBackup.filenames()
+
+	if err := lm.configBackup.restore(); err != nil {
+		glog.Errorf("Failed to roll back the nginx config: %v", err)
+	}
+
+	for _, filename := range filenames {
+		if err := lm.configStaging.refresh(filename); err != nil {
+			glog.Errorf("Failed to roll back the staged config %v: %v", filename, err)
+		}
+	}
+}
+
 // Quit shutdowns NGINX gracefully.
 func (lm *LocalManager) Quit() {
 	glog.V(3).Info("Quitting nginx")
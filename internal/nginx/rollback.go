@@ -0,0 +1,91 @@
+package nginx
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/golang/glog"
+)
+
+// configBackup holds the previous contents of the configuration files changed since the last successful reload of NGINX,
+// so that the configuration can be rolled back to the last known good one if the next reload fails.
+type configBackup struct {
+	files map[string]backupFile
+}
+
+type backupFile struct {
+	content []byte
+	exists  bool
+}
+
+func newConfigBackup() *configBackup {
+	return &configBackup{
+		files: make(map[string]backupFile),
+	}
+}
+
+// save backs up the file before it is changed. Only the first change since the last successful reload is backed up.
+func (b *configBackup) save(filename string) {
+	if _, saved := b.files[filename]; saved {
+		return
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			glog.Warningf("Failed to back up %v, the file will not be rolled back: %v", filename, err)
+			return
+		}
+		b.files[filename] = backupFile{exists: false}
+		return
+	}
+
+	b.files[filename] = backupFile{content: content, exists: true}
+}
+
+// restore restores the backed up files and clears the backup.
+func (b *configBackup) restore() error {
+	var errs []error
+
+	for _, filename := range b.filenames() {
+		f := b.files[filename]
+
+		glog.V(3).Infof("Rolling back %v", filename)
+
+		if !f.exists {
+			if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, fmt.Errorf("failed to delete %v: %w", filename, err))
+			}
+			continue
+		}
+
+		if err := createFileAndWrite(filename, f.content); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	b.clear()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to roll back the config: %v", errs)
+	}
+
+	return nil
+}
+
+// filenames returns the sorted names of the backed up files.
+func (b *configBackup) filenames() []string {
+	var filenames []string
+	for filename := range b.files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	return filenames
+}
+
+// clear clears the backup. It must be called once the changed files were successfully applied.
+func (b *configBackup) clear() {
+	b.files = make(map[string]backupFile)
+}
@@ -0,0 +1,60 @@
+package nginx
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestConfigBackupRestore(t *testing.T) {
+	dir := t.TempDir()
+
+	updated := path.Join(dir, "updated.conf")
+	deleted := path.Join(dir, "deleted.conf")
+	added := path.Join(dir, "added.conf")
+
+	for _, filename := range []string{updated, deleted} {
+		if err := os.WriteFile(filename, []byte("valid"), 0o644); err != nil {
+			t.Fatalf("Failed to write %v: %v", filename, err)
+		}
+	}
+
+	backup := newConfigBackup()
+
+	backup.save(updated)
+	createConfig(updated, []byte("invalid"))
+	// only the first change is backed up
+	backup.save(updated)
+	createConfig(updated, []byte("invalid again"))
+
+	backup.save(deleted)
+	deleteConfig(deleted)
+
+	backup.save(added)
+	createConfig(added, []byte("invalid"))
+
+	err := backup.restore()
+	if err != nil {
+		t.Fatalf("restore() returned unexpected error: %v", err)
+	}
+
+	for _, filename := range []string{updated, deleted} {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			t.Errorf("Failed to read %v: %v", filename, err)
+			continue
+		}
+		if string(content) != "valid" {
+			t.Errorf("restore() restored %v with content %q, expected %q", filename, content, "valid")
+		}
+	}
+
+	if _, err := os.Stat(added); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("restore() didn't delete %v added after the backup", added)
+	}
+
+	if len(backup.files) != 0 {
+		t.Errorf("restore() didn't clear the backup")
+	}
+}
@@ -0,0 +1,239 @@
+package nginx
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// stagedConfigPaths are the files and the folders of the config folder that are written by the Ingress Controller
+// and tested in the staged copy of the config folder before they are applied.
+// The other files and folders of the staged copy are links to the ones in the config folder.
+var stagedConfigPaths = []string{"nginx.conf", "conf.d", "stream-conf.d", "tls-passthrough-hosts.conf"}
+
+// configStaging keeps a staged copy of the config folder. The config files are written to the staged copy first,
+// so that NGINX can test them before they are applied to the config folder that NGINX runs with.
+type configStaging struct {
+	confPath    string
+	stagingPath string
+	// replacer replaces the absolute paths of the staged files and folders in the config files with the paths
+	// in the staged copy, so that the staged main config includes the staged config files.
+	replacer *strings.Replacer
+	// pending holds the changes of the config files not yet applied to the config folder.
+	pending map[string]pendingFile
+}
+
+type pendingFile struct {
+	content []byte
+	deleted bool
+}
+
+func newConfigStaging(confPath string, stagingPath string) *configStaging {
+	var replacements []string
+	for _, p := range stagedConfigPaths[1:] {
+		replacements = append(replacements, path.Join(confPath, p), path.Join(stagingPath, p))
+	}
+
+	return &configStaging{
+		confPath:    confPath,
+		stagingPath: stagingPath,
+		replacer:    strings.NewReplacer(replacements...),
+		pending:     make(map[string]pendingFile),
+	}
+}
+
+// init creates the staged copy of the config folder.
+func (s *configStaging) init() error {
+	if err := os.RemoveAll(s.stagingPath); err != nil {
+		return fmt.Errorf("failed to remove %v: %w", s.stagingPath, err)
+	}
+
+	for _, dir := range []string{s.stagingPath, path.Join(s.stagingPath, "conf.d"), path.Join(s.stagingPath, "stream-conf.d")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create %v: %w", dir, err)
+		}
+	}
+
+	entries, err := os.ReadDir(s.confPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %v: %w", s.confPath, err)
+	}
+
+	for _, entry := range entries {
+		filename := path.Join(s.confPath, entry.Name())
+		if filename == s.stagingPath {
+			continue
+		}
+
+		switch entry.Name() {
+		case "conf.d", "stream-conf.d":
+			files, err := os.ReadDir(filename)
+			if err != nil {
+				return fmt.Errorf("failed to read %v: %w", filename, err)
+			}
+			for _, f := range files {
+				if err := s.refresh(path.Join(filename, f.Name())); err != nil {
+					return err
+				}
+			}
+		case "nginx.conf", "tls-passthrough-hosts.conf":
+			if err := s.refresh(filename); err != nil {
+				return err
+			}
+		default:
+			if err := os.Symlink(filename, s.stagedFilename(filename)); err != nil {
+				return fmt.Errorf("failed to link %v: %w", filename, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// stagedFilename returns the name of the file in the staged copy of the config folder.
+func (s *configStaging) stagedFilename(filename string) string {
+	return path.Join(s.stagingPath, strings.TrimPrefix(filename, s.confPath))
+}
+
+// configFilename returns the name of the staged file in the config folder.
+func (s *configStaging) configFilename(stagedFilename string) string {
+	if !strings.HasPrefix(stagedFilename, s.stagingPath+"/") {
+		return stagedFilename
+	}
+	return path.Join(s.confPath, strings.TrimPrefix(stagedFilename, s.stagingPath))
+}
+
+// mainConfFilename returns the name of the staged main config file.
+func (s *configStaging) mainConfFilename() string {
+	return path.Join(s.stagingPath, "nginx.conf")
+}
+
+// stage writes the content of the config file to the staged copy.
+func (s *configStaging) stage(filename string, content []byte) error {
+	s.pending[filename] = pendingFile{content: content}
+
+	stagedFilename := s.stagedFilename(filename)
+
+	glog.V(3).Infof("Writing config to %v", stagedFilename)
+	glog.V(3).Info(string(content))
+
+	return createFileAndWrite(stagedFilename, []byte(s.replacer.Replace(string(content))))
+}
+
+// stageDeletion deletes the config file from the staged copy.
+func (s *configStaging) stageDeletion(filename string) error {
+	s.pending[filename] = pendingFile{deleted: true}
+
+	stagedFilename := s.stagedFilename(filename)
+
+	glog.V(3).Infof("Deleting config from %v", stagedFilename)
+
+	if err := os.Remove(stagedFilename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete %v: %w", stagedFilename, err)
+	}
+
+	return nil
+}
+
+// apply applies the pending changes to the config folder. The changed files are backed up before they are changed.
+func (s *configStaging) apply(backup *configBackup) error {
+	var errs []error
+
+	for _, filename := range s.pendingFilenames() {
+		f := s.pending[filename]
+
+		backup.save(filename)
+
+		if f.deleted {
+			glog.V(3).Infof("Deleting config from %v", filename)
+			if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, fmt.Errorf("failed to delete %v: %w", filename, err))
+			}
+			continue
+		}
+
+		glog.V(3).Infof("Writing config to %v", filename)
+		if err := createFileAndWrite(filename, f.content); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	s.pending = make(map[string]pendingFile)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to apply the config: %v", errs)
+	}
+
+	return nil
+}
+
+// discard discards the pending changes, so that the staged copy matches the config folder again.
+func (s *configStaging) discard() {
+	for _, filename := range s.pendingFilenames() {
+		if err := s.refresh(filename); err != nil {
+			glog.Errorf("Failed to discard the staged changes of %v: %v", filename, err)
+		}
+	}
+
+	s.pending = make(map[string]pendingFile)
+}
+
+// discardFiles discards the pending changes of the config files of the resources, so that the staged copy of
+// the files matches the config folder again. If any of the files isn't a config file of a resource with pending
+// changes, no changes are discarded and false is returned.
+func (s *configStaging) discardFiles(filenames []string) bool {
+	for _, filename := range filenames {
+		if _, ok := s.pending[filename]; !ok || !s.isResourceConfig(filename) {
+			return false
+		}
+	}
+
+	for _, filename := range filenames {
+		if err := s.refresh(filename); err != nil {
+			glog.Errorf("Failed to discard the staged changes of %v: %v", filename, err)
+		}
+		delete(s.pending, filename)
+	}
+
+	return true
+}
+
+// isResourceConfig returns true if the file is in the conf.d or stream-conf.d folder,
+// where the config files of the resources are.
+func (s *configStaging) isResourceConfig(filename string) bool {
+	dir := path.Dir(filename)
+	return dir == path.Join(s.confPath, "conf.d") || dir == path.Join(s.confPath, "stream-conf.d")
+}
+
+// refresh copies the file from the config folder to the staged copy or deletes the staged file
+// if the file doesn't exist in the config folder.
+func (s *configStaging) refresh(filename string) error {
+	stagedFilename := s.stagedFilename(filename)
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read %v: %w", filename, err)
+		}
+		if err := os.Remove(stagedFilename); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to delete %v: %w", stagedFilename, err)
+		}
+		return nil
+	}
+
+	return createFileAndWrite(stagedFilename, []byte(s.replacer.Replace(string(content))))
+}
+
+func (s *configStaging) pendingFilenames() []string {
+	var filenames []string
+	for filename := range s.pending {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	return filenames
+}
@@ -0,0 +1,81 @@
+package nginx
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestConfigStaging(t *testing.T) {
+	confPath := t.TempDir()
+	stagingPath := path.Join(confPath, "staging")
+
+	for _, dir := range []string{"conf.d", "stream-conf.d", "oidc"} {
+		if err := os.Mkdir(path.Join(confPath, dir), 0o755); err != nil {
+			t.Fatalf("Failed to create %v: %v", dir, err)
+		}
+	}
+
+	mainConfFilename := path.Join(confPath, "nginx.conf")
+	mainConf := "include " + path.Join(confPath, "conf.d") + "/*.conf;\ninclude oidc/oidc_common.conf;\n"
+	if err := os.WriteFile(mainConfFilename, []byte(mainConf), 0o644); err != nil {
+		t.Fatalf("Failed to write %v: %v", mainConfFilename, err)
+	}
+
+	staging := newConfigStaging(confPath, stagingPath)
+	if err := staging.init(); err != nil {
+		t.Fatalf("init() returned unexpected error: %v", err)
+	}
+
+	stagedMainConf, err := os.ReadFile(staging.mainConfFilename())
+	if err != nil {
+		t.Fatalf("Failed to read the staged main config: %v", err)
+	}
+	expectedMainConf := "include " + path.Join(stagingPath, "conf.d") + "/*.conf;\ninclude oidc/oidc_common.conf;\n"
+	if string(stagedMainConf) != expectedMainConf {
+		t.Errorf("init() staged the main config %q, expected %q", stagedMainConf, expectedMainConf)
+	}
+
+	if target, err := os.Readlink(path.Join(stagingPath, "oidc")); err != nil || target != path.Join(confPath, "oidc") {
+		t.Errorf("init() didn't link the oidc folder: %v, %v", target, err)
+	}
+
+	filename := path.Join(confPath, "conf.d", "vs_default_cafe.conf")
+
+	// discarded changes are not applied
+	if err := staging.stage(filename, []byte("invalid")); err != nil {
+		t.Fatalf("stage() returned unexpected error: %v", err)
+	}
+	staging.discard()
+	if _, err := os.Stat(staging.stagedFilename(filename)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("discard() didn't delete the staged file %v", staging.stagedFilename(filename))
+	}
+
+	if err := staging.stage(filename, []byte("valid")); err != nil {
+		t.Fatalf("stage() returned unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filename); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("stage() wrote the file %v to the config folder", filename)
+	}
+
+	backup := newConfigBackup()
+	if err := staging.apply(backup); err != nil {
+		t.Fatalf("apply() returned unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("apply() didn't write %v: %v", filename, err)
+	}
+	if string(content) != "valid" {
+		t.Errorf("apply() wrote %v with content %q, expected %q", filename, content, "valid")
+	}
+	if _, saved := backup.files[filename]; !saved {
+		t.Errorf("apply() didn't back up %v", filename)
+	}
+
+	if staged := staging.stagedFilename(filename); staging.configFilename(staged) != filename {
+		t.Errorf("configFilename(%v) returned %v, expected %v", staged, staging.configFilename(staged), filename)
+	}
+}
@@ -0,0 +1,172 @@
+package nginx
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// configTestErrorRegexp matches the errors with a location in the output of nginx -t, for example:
+// nginx: [emerg] unknown directive "proxy_passs" in /etc/nginx/conf.d/vs_default_cafe.conf:25
+var configTestErrorRegexp = regexp.MustCompile(`\[(?:emerg|alert|crit)\] (.+) in (\S+):(\d+)$`)
+
+// ConfigError is an error in a config file reported by NGINX.
+type ConfigError struct {
+	// Filename is the name of the config file in the NGINX config folder.
+	Filename string
+	// Line is the number of the line with the error.
+	Line int
+	// Message is the error message of NGINX. It usually includes the rejected directive.
+	Message string
+	// Directive is the content of the line with the error.
+	Directive string
+}
+
+func (e ConfigError) Error() string {
+	if e.Directive == "" {
+		return fmt.Sprintf("%s in %s:%d", e.Message, e.Filename, e.Line)
+	}
+	return fmt.Sprintf("%s in %s:%d (%q)", e.Message, e.Filename, e.Line, e.Directive)
+}
+
+// ValidationError is returned when NGINX rejects the config.
+type ValidationError struct {
+	// Errors are the errors with a location in a config file.
+	Errors []ConfigError
+	// Output is the output of the test of the config.
+	Output string
+	// Applied is true if the rejected config files were rolled back and NGINX was reloaded with the changes
+	// of the other config files.
+	Applied bool
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("nginx rejected the config: %s", strings.TrimSpace(e.Output))
+	}
+
+	var msgs []string
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+
+	return fmt.Sprintf("nginx rejected the config: %s", strings.Join(msgs, "; "))
+}
+
+// ErrorsForFile returns the errors in the config file.
+func (e *ValidationError) ErrorsForFile(filename string) []ConfigError {
+	var errs []ConfigError
+	for _, err := range e.Errors {
+		if err.Filename == filename {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Filenames returns the sorted names of the config files with errors.
+func (e *ValidationError) Filenames() []string {
+	var filenames []string
+	seen := make(map[string]bool)
+	for _, err := range e.Errors {
+		if seen[err.Filename] {
+			continue
+		}
+		seen[err.Filename] = true
+		filenames = append(filenames, err.Filename)
+	}
+	sort.Strings(filenames)
+	return filenames
+}
+
+// parseConfigTestOutput parses the output of nginx -t into a ValidationError.
+// mapFilename maps the names of the tested files to the names of the files in the NGINX config folder.
+func parseConfigTestOutput(output string, mapFilename func(string) string) *ValidationError {
+	validationErr := &ValidationError{
+		Output: output,
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		matches := configTestErrorRegexp.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+
+		lineNumber, err := strconv.Atoi(matches[3])
+		if err != nil {
+			continue
+		}
+
+		validationErr.Errors = append(validationErr.Errors, ConfigError{
+			Filename:  mapFilename(matches[2]),
+			Line:      lineNumber,
+			Message:   matches[1],
+			Directive: readLine(matches[2], lineNumber),
+		})
+	}
+
+	return validationErr
+}
+
+// rejectConfigFiles tests the staged config and discards the staged changes of the config files NGINX rejects,
+// until NGINX accepts the config. NGINX stops the test at the first error, so the config is tested again after
+// every rejected file. It returns the errors of the rejected files, or nil if NGINX accepted the config as it was.
+// If NGINX rejects a file without staged changes or a file that isn't a config file of a resource, the returned
+// error includes the errors of all rejected files. If the errors can't be attributed to config files, the error
+// of the test is returned unchanged.
+func rejectConfigFiles(staging *configStaging, validate func() error) (*ValidationError, error) {
+	var rejected *ValidationError
+
+	for {
+		err := validate()
+		if err == nil {
+			return rejected, nil
+		}
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			return nil, err
+		}
+
+		if len(validationErr.Errors) == 0 {
+			return nil, err
+		}
+
+		if rejected == nil {
+			rejected = &ValidationError{}
+		}
+		rejected.Errors = append(rejected.Errors, validationErr.Errors...)
+		rejected.Output += validationErr.Output
+
+		if !staging.discardFiles(validationErr.Filenames()) {
+			return nil, rejected
+		}
+
+		glog.Warningf("Discarded the changes of the config files rejected by nginx: %v", validationErr)
+	}
+}
+
+// readLine returns the trimmed content of the line of the file or an empty string if the line can't be read.
+func readLine(filename string, lineNumber int) string {
+	f, err := os.Open(filename)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for i := 1; scanner.Scan(); i++ {
+		if i == lineNumber {
+			return strings.TrimSpace(scanner.Text())
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,185 @@
+package nginx
+
+import (
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseConfigTestOutput(t *testing.T) {
+	stagingPath := t.TempDir()
+	stagedFilename := path.Join(stagingPath, "conf.d", "vs_default_cafe.conf")
+
+	if err := os.MkdirAll(path.Dir(stagedFilename), 0o755); err != nil {
+		t.Fatalf("Failed to create %v: %v", path.Dir(stagedFilename), err)
+	}
+	content := "server {\n    listen 80;\n    proxy_passs http://coffee;\n}\n"
+	if err := os.WriteFile(stagedFilename, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write %v: %v", stagedFilename, err)
+	}
+
+	output := "nginx: [warn] the \"http2\" parameter is deprecated in " + stagedFilename + ":2\n" +
+		"nginx: [emerg] unknown directive \"proxy_passs\" in " + stagedFilename + ":3\n" +
+		"nginx: configuration file " + path.Join(stagingPath, "nginx.conf") + " test failed\n"
+
+	mapFilename := func(filename string) string {
+		return strings.Replace(filename, stagingPath, "/etc/nginx", 1)
+	}
+
+	expected := []ConfigError{
+		{
+			Filename:  "/etc/nginx/conf.d/vs_default_cafe.conf",
+			Line:      3,
+			Message:   `unknown directive "proxy_passs"`,
+			Directive: "proxy_passs http://coffee;",
+		},
+	}
+
+	validationErr := parseConfigTestOutput(output, mapFilename)
+	if diff := cmp.Diff(expected, validationErr.Errors); diff != "" {
+		t.Errorf("parseConfigTestOutput() returned unexpected errors (-want +got):\n%s", diff)
+	}
+
+	if errs := validationErr.ErrorsForFile("/etc/nginx/conf.d/vs_default_tea.conf"); len(errs) != 0 {
+		t.Errorf("ErrorsForFile() returned errors %v for a file without errors", errs)
+	}
+
+	expectedMsg := `nginx rejected the config: unknown directive "proxy_passs" in /etc/nginx/conf.d/vs_default_cafe.conf:3 ("proxy_passs http://coffee;")`
+	if msg := validationErr.Error(); msg != expectedMsg {
+		t.Errorf("Error() returned %q but expected %q", msg, expectedMsg)
+	}
+}
+
+func TestParseConfigTestOutputWithoutLocation(t *testing.T) {
+	output := "nginx: [emerg] no \"events\" section in configuration\n"
+
+	validationErr := parseConfigTestOutput(output, func(filename string) string { return filename })
+	if len(validationErr.Errors) != 0 {
+		t.Errorf("parseConfigTestOutput() returned unexpected errors %v", validationErr.Errors)
+	}
+
+	expectedMsg := `nginx rejected the config: nginx: [emerg] no "events" section in configuration`
+	if msg := validationErr.Error(); msg != expectedMsg {
+		t.Errorf("Error() returned %q but expected %q", msg, expectedMsg)
+	}
+}
+
+func TestRejectConfigFiles(t *testing.T) {
+	t.Parallel()
+	confPath := t.TempDir()
+
+	for _, dir := range []string{"conf.d", "stream-conf.d"} {
+		if err := os.Mkdir(path.Join(confPath, dir), 0o755); err != nil {
+			t.Fatalf("Failed to create %v: %v", dir, err)
+		}
+	}
+
+	staging := newConfigStaging(confPath, path.Join(confPath, "staging"))
+	if err := staging.init(); err != nil {
+		t.Fatalf("init() returned unexpected error: %v", err)
+	}
+
+	cafeFilename := path.Join(confPath, "conf.d", "vs_default_cafe.conf")
+	teaFilename := path.Join(confPath, "conf.d", "vs_default_tea.conf")
+	tcpFilename := path.Join(confPath, "stream-conf.d", "ts_default_tcp.conf")
+	for _, filename := range []string{cafeFilename, teaFilename, tcpFilename} {
+		if err := staging.stage(filename, []byte("config")); err != nil {
+			t.Fatalf("stage() returned unexpected error: %v", err)
+		}
+	}
+
+	// NGINX reports only the first error, so every rejected file is reported by another test
+	rejectedFilenames := []string{cafeFilename, tcpFilename}
+	validate := func() error {
+		for _, filename := range rejectedFilenames {
+			if _, ok := staging.pending[filename]; ok {
+				return &ValidationError{Errors: []ConfigError{{Filename: filename, Line: 1, Message: "invalid"}}}
+			}
+		}
+		return nil
+	}
+
+	rejectedErr, err := rejectConfigFiles(staging, validate)
+	if err != nil {
+		t.Fatalf("rejectConfigFiles() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(rejectedFilenames, rejectedErr.Filenames()); diff != "" {
+		t.Errorf("rejectConfigFiles() returned unexpected rejected files (-want +got):\n%s", diff)
+	}
+
+	for _, filename := range rejectedFilenames {
+		if _, ok := staging.pending[filename]; ok {
+			t.Errorf("rejectConfigFiles() didn't discard the changes of the rejected file %v", filename)
+		}
+		if _, err := os.Stat(staging.stagedFilename(filename)); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("rejectConfigFiles() didn't roll back the staged file %v", staging.stagedFilename(filename))
+		}
+	}
+	if _, ok := staging.pending[teaFilename]; !ok {
+		t.Errorf("rejectConfigFiles() discarded the changes of the accepted file %v", teaFilename)
+	}
+
+	if rejectedErr, err := rejectConfigFiles(staging, func() error { return nil }); rejectedErr != nil || err != nil {
+		t.Errorf("rejectConfigFiles() returned %v, %v for an accepted config, expected nil, nil", rejectedErr, err)
+	}
+}
+
+func TestRejectConfigFilesFails(t *testing.T) {
+	t.Parallel()
+	confPath := t.TempDir()
+
+	if err := os.Mkdir(path.Join(confPath, "conf.d"), 0o755); err != nil {
+		t.Fatalf("Failed to create conf.d: %v", err)
+	}
+
+	staging := newConfigStaging(confPath, path.Join(confPath, "staging"))
+	if err := staging.init(); err != nil {
+		t.Fatalf("init() returned unexpected error: %v", err)
+	}
+
+	cafeFilename := path.Join(confPath, "conf.d", "vs_default_cafe.conf")
+	mainConfFilename := path.Join(confPath, "nginx.conf")
+	for _, filename := range []string{cafeFilename, mainConfFilename} {
+		if err := staging.stage(filename, []byte("config")); err != nil {
+			t.Fatalf("stage() returned unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		validationErr *ValidationError
+		msg           string
+	}{
+		{
+			validationErr: &ValidationError{Errors: []ConfigError{{Filename: mainConfFilename, Line: 1, Message: "invalid"}}},
+			msg:           "error in the main config",
+		},
+		{
+			validationErr: &ValidationError{Errors: []ConfigError{{Filename: path.Join(confPath, "conf.d", "vs_default_tea.conf"), Line: 1, Message: "invalid"}}},
+			msg:           "error in a file without staged changes",
+		},
+		{
+			validationErr: &ValidationError{Output: "nginx: [emerg] no \"events\" section in configuration"},
+			msg:           "error without a location",
+		},
+	}
+
+	for _, test := range tests {
+		rejectedErr, err := rejectConfigFiles(staging, func() error { return test.validationErr })
+		if rejectedErr != nil {
+			t.Errorf("rejectConfigFiles() returned rejected files %v for the case of %s, expected nil", rejectedErr, test.msg)
+		}
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Error() != test.validationErr.Error() {
+			t.Errorf("rejectConfigFiles() returned error %v for the case of %s, expected %v", err, test.msg, test.validationErr)
+		}
+
+		if len(staging.pending) != 2 {
+			t.Errorf("rejectConfigFiles() discarded staged changes for the case of %s", test.msg)
+		}
+	}
+}
@@ -168,6 +168,7 @@ type Route struct {
 	Action           *Action           `json:"action"`
 	Splits           []Split           `json:"splits"`
 	Matches          []Match           `json:"matches"`
+	Canary           *Canary           `json:"canary"`
 	ErrorPages       []ErrorPage       `json:"errorPages"`
 	LocationSnippets string            `json:"location-snippets"`
 	Dos              string            `json:"dos"`
@@ -200,6 +201,14 @@ type ActionProxy struct {
 	RewritePath     string                `json:"rewritePath"`
 	RequestHeaders  *ProxyRequestHeaders  `json:"requestHeaders"`
 	ResponseHeaders *ProxyResponseHeaders `json:"responseHeaders"`
+	Mirror          *ActionProxyMirror    `json:"mirror"`
+}
+
+// ActionProxyMirror defines the mirroring of the requests of an ActionProxy to an upstream.
+type ActionProxyMirror struct {
+	Upstream    string `json:"upstream"`
+	Percentage  *int   `json:"percentage"`
+	RequestBody *bool  `json:"requestBody"`
 }
 
 // ProxyRequestHeaders defines the request headers manipulation in an ActionProxy.
@@ -228,6 +237,16 @@ type Split struct {
 	Action *Action `json:"action"`
 }
 
+// Canary defines a canary release of a route. The requests with the header or the cookie set to "always" are passed
+// to the canary action, the requests with the header or the cookie set to "never" are passed to the action of the route.
+// The weight of the other requests is passed to the canary action.
+type Canary struct {
+	Header string  `json:"header"`
+	Cookie string  `json:"cookie"`
+	Weight int     `json:"weight"`
+	Action *Action `json:"action"`
+}
+
 // Condition defines a condition in a MatchRule.
 type Condition struct {
 	Header   string `json:"header"`
@@ -396,15 +415,25 @@ type PolicyStatus struct {
 // The spec includes multiple fields, where each field represents a different policy.
 // Only one policy (field) is allowed.
 type PolicySpec struct {
-	IngressClass  string         `json:"ingressClassName"`
-	AccessControl *AccessControl `json:"accessControl"`
-	RateLimit     *RateLimit     `json:"rateLimit"`
-	JWTAuth       *JWTAuth       `json:"jwt"`
-	BasicAuth     *BasicAuth     `json:"basicAuth"`
-	IngressMTLS   *IngressMTLS   `json:"ingressMTLS"`
-	EgressMTLS    *EgressMTLS    `json:"egressMTLS"`
-	OIDC          *OIDC          `json:"oidc"`
-	WAF           *WAF           `json:"waf"`
+	IngressClass    string           `json:"ingressClassName"`
+	AccessControl   *AccessControl   `json:"accessControl"`
+	RateLimit       *RateLimit       `json:"rateLimit"`
+	JWTAuth         *JWTAuth         `json:"jwt"`
+	BasicAuth       *BasicAuth       `json:"basicAuth"`
+	IngressMTLS     *IngressMTLS     `json:"ingressMTLS"`
+	EgressMTLS      *EgressMTLS      `json:"egressMTLS"`
+	OIDC            *OIDC            `json:"oidc"`
+	WAF             *WAF             `json:"waf"`
+	CORS            *CORS            `json:"cors"`
+	ExternalAuth    *ExternalAuth    `json:"externalAuth"`
+	APIKey          *APIKey          `json:"apiKey"`
+	Cache           *Cache           `json:"cache"`
+	Resilience      *Resilience      `json:"resilience"`
+	GeoAccess       *GeoAccess       `json:"geoAccess"`
+	Headers         *Headers         `json:"headers"`
+	Compression     *Compression     `json:"compression"`
+	ConnectionLimit *ConnectionLimit `json:"connectionLimit"`
+	Bandwidth       *Bandwidth       `json:"bandwidth"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -423,24 +452,62 @@ type AccessControl struct {
 	Deny  []string `json:"deny"`
 }
 
+// ConnectionLimit defines a connection limit policy. It is supported only in TransportServers.
+type ConnectionLimit struct {
+	Key            string `json:"key"`
+	MaxConnections int    `json:"maxConnections"`
+	ZoneSize       string `json:"zoneSize"`
+	DryRun         *bool  `json:"dryRun"`
+	LogLevel       string `json:"logLevel"`
+}
+
+// Bandwidth defines a bandwidth policy that limits the rate of reading and writing the data of the connections.
+// It is supported only in TransportServers.
+type Bandwidth struct {
+	UploadRate   string `json:"uploadRate"`
+	DownloadRate string `json:"downloadRate"`
+}
+
 // RateLimit defines a rate limit policy.
 type RateLimit struct {
-	Rate       string `json:"rate"`
-	Key        string `json:"key"`
-	Delay      *int   `json:"delay"`
-	NoDelay    *bool  `json:"noDelay"`
-	Burst      *int   `json:"burst"`
-	ZoneSize   string `json:"zoneSize"`
-	DryRun     *bool  `json:"dryRun"`
-	LogLevel   string `json:"logLevel"`
-	RejectCode *int   `json:"rejectCode"`
+	Rate       string          `json:"rate"`
+	Key        string          `json:"key"`
+	Delay      *int            `json:"delay"`
+	NoDelay    *bool           `json:"noDelay"`
+	Burst      *int            `json:"burst"`
+	ZoneSize   string          `json:"zoneSize"`
+	DryRun     *bool           `json:"dryRun"`
+	LogLevel   string          `json:"logLevel"`
+	RejectCode *int            `json:"rejectCode"`
+	Tiers      []RateLimitTier `json:"tiers"`
+}
+
+// RateLimitTier defines the rate of the requests that match the condition of a tier of a rate limit policy.
+// A tier without a condition applies to the requests that match no other tier.
+type RateLimitTier struct {
+	Name      string              `json:"name"`
+	Condition *RateLimitCondition `json:"condition"`
+	Rate      string              `json:"rate"`
+	Burst     *int                `json:"burst"`
+	ZoneSize  string              `json:"zoneSize"`
+}
+
+// RateLimitCondition defines a condition of a tier of a rate limit policy.
+// The condition matches when the value of the JWT claim, the header or the variable equals Value.
+type RateLimitCondition struct {
+	JWTClaim string `json:"jwtClaim"`
+	Header   string `json:"header"`
+	Variable string `json:"variable"`
+	Value    string `json:"value"`
 }
 
 // JWTAuth holds JWT authentication configuration.
 type JWTAuth struct {
-	Realm  string `json:"realm"`
-	Secret string `json:"secret"`
-	Token  string `json:"token"`
+	Realm    string `json:"realm"`
+	Secret   string `json:"secret"`
+	Token    string `json:"token"`
+	JwksURI  string `json:"jwksURI"`
+	KeyCache string `json:"keyCache"`
 }
 
 // BasicAuth holds HTTP Basic authentication configuration
@@ -450,6 +517,131 @@ type BasicAuth struct {
 	Secret string `json:"secret"`
 }
 
+// APIKey defines an API key authentication policy.
+type APIKey struct {
+	SuppliedIn   *SuppliedIn `json:"suppliedIn"`
+	ClientSecret string      `json:"clientSecret"`
+}
+
+// SuppliedIn defines the headers and the query parameters of a request that can hold an API key.
+type SuppliedIn struct {
+	Header []string `json:"header"`
+	Query  []string `json:"query"`
+}
+
+// Cache defines a response caching policy.
+type Cache struct {
+	ZoneSize string       `json:"zoneSize"`
+	MaxSize  string       `json:"maxSize"`
+	Valid    []CacheValid `json:"valid"`
+	Key      string       `json:"key"`
+	Bypass   []string     `json:"bypass"`
+	NoCache  []string     `json:"noCache"`
+	Methods  []string     `json:"methods"`
+	Purge    *CachePurge  `json:"purge"`
+}
+
+// CacheValid defines the caching time of the responses with the status codes.
+type CacheValid struct {
+	Codes []int  `json:"codes"`
+	Time  string `json:"time"`
+}
+
+// CachePurge defines the purging of the cache by the PURGE requests.
+type CachePurge struct {
+	Allow []string `json:"allow"`
+}
+
+// Resilience defines a policy of the retries of the requests and of the circuit breaking of the upstream servers.
+type Resilience struct {
+	Retry          *Retry          `json:"retry"`
+	CircuitBreaker *CircuitBreaker `json:"circuitBreaker"`
+}
+
+// Retry defines the retries of the requests to the next upstream server.
+type Retry struct {
+	Conditions    []string `json:"conditions"`
+	NonIdempotent bool     `json:"nonIdempotent"`
+	MaxAttempts   *int     `json:"maxAttempts"`
+	Timeout       string   `json:"timeout"`
+	PerTryTimeout string   `json:"perTryTimeout"`
+}
+
+// CircuitBreaker defines when the upstream servers are considered unavailable.
+type CircuitBreaker struct {
+	MaxFails    *int   `json:"maxFails"`
+	FailTimeout string `json:"failTimeout"`
+	SlowStart   string `json:"slowStart"`
+}
+
+// GeoAccess defines an access control policy based on the country and the autonomous system of the client IP address.
+type GeoAccess struct {
+	CountryDatabase string    `json:"countryDatabase"`
+	ASNDatabase     string    `json:"asnDatabase"`
+	Allow           *GeoRules `json:"allow"`
+	Deny            *GeoRules `json:"deny"`
+	DryRun          *bool     `json:"dryRun"`
+	RejectCode      *int      `json:"rejectCode"`
+}
+
+// GeoRules defines the countries and the autonomous systems of the clients of a geoAccess policy.
+type GeoRules struct {
+	Countries []string `json:"countries"`
+	ASNs      []int    `json:"asns"`
+}
+
+// Headers defines a policy of the transformation of the headers of the requests and the responses.
+type Headers struct {
+	Request         *RequestHeadersTransform  `json:"request"`
+	Response        *ResponseHeadersTransform `json:"response"`
+	SecurityHeaders *SecurityHeaders          `json:"securityHeaders"`
+}
+
+// RequestHeadersTransform defines the transformation of the headers of the requests passed to the upstreams.
+type RequestHeadersTransform struct {
+	Set    []Header       `json:"set"`
+	Remove []string       `json:"remove"`
+	Rename []HeaderRename `json:"rename"`
+}
+
+// ResponseHeadersTransform defines the transformation of the headers of the responses of the upstreams.
+type ResponseHeadersTransform struct {
+	Add                 []AddHeader     `json:"add"`
+	Remove              []string        `json:"remove"`
+	Rename              []HeaderRename  `json:"rename"`
+	RewriteLocation     []HeaderRewrite `json:"rewriteLocation"`
+	RewriteCookieDomain []HeaderRewrite `json:"rewriteCookieDomain"`
+}
+
+// HeaderRename defines the renaming of a header.
+type HeaderRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// HeaderRewrite defines the replacement of a part of the value of a header.
+type HeaderRewrite struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// SecurityHeaders defines the security headers added to the responses.
+type SecurityHeaders struct {
+	ContentSecurityPolicy string `json:"contentSecurityPolicy"`
+	FrameOptions          string `json:"frameOptions"`
+	ReferrerPolicy        string `json:"referrerPolicy"`
+}
+
+// Compression defines a policy of the compression of the responses.
+type Compression struct {
+	Algorithms  []string `json:"algorithms"`
+	GzipLevel   *int     `json:"gzipLevel"`
+	BrotliLevel *int     `json:"brotliLevel"`
+	MinLength   *int     `json:"minLength"`
+	Types       []string `json:"types"`
+	Proxied     bool     `json:"proxied"`
+}
+
 // IngressMTLS defines an Ingress MTLS policy.
 type IngressMTLS struct {
 	ClientCertSecret string `json:"clientCertSecret"`
@@ -490,6 +682,33 @@ type WAF struct {
 	SecurityLogs []*SecurityLog `json:"securityLogs"`
 }
 
+// CORS defines a Cross-Origin Resource Sharing policy.
+type CORS struct {
+	AllowOrigin      []string `json:"allowOrigin"`
+	AllowMethods     []string `json:"allowMethods"`
+	AllowHeaders     []string `json:"allowHeaders"`
+	ExposeHeaders    []string `json:"exposeHeaders"`
+	AllowCredentials *bool    `json:"allowCredentials"`
+	MaxAge           *int     `json:"maxAge"`
+}
+
+// ExternalAuth defines an external authorization policy.
+type ExternalAuth struct {
+	AuthService     string             `json:"authService"`
+	AuthServicePort int                `json:"authServicePort"`
+	AuthPath        string             `json:"authPath"`
+	ForwardHeaders  []string           `json:"forwardHeaders"`
+	ResponseHeaders []string           `json:"responseHeaders"`
+	Cache           *ExternalAuthCache `json:"cache"`
+}
+
+// ExternalAuthCache defines the caching of the decisions of an external authorization service.
+type ExternalAuthCache struct {
+	ZoneSize string `json:"zoneSize"`
+	Key      string `json:"key"`
+	Valid    string `json:"valid"`
+}
+
 // SecurityLog defines the security log of a WAF policy.
 type SecurityLog struct {
 	Enable    bool   `json:"enable"`
@@ -11,6 +11,26 @@ const (
 	TLSPassthroughListenerProtocol = "TLS_PASSTHROUGH"
 )
 
+const (
+	// TrafficShiftStateProgressing is used when the traffic is being shifted to the upstream.
+	TrafficShiftStateProgressing = "Progressing"
+	// TrafficShiftStatePaused is used when the shift was paused because the upstream returned too many errors.
+	TrafficShiftStatePaused = "Paused"
+	// TrafficShiftStateRolledBack is used when the traffic was shifted back from the upstream because it returned too many errors.
+	TrafficShiftStateRolledBack = "RolledBack"
+	// TrafficShiftStateSucceeded is used when the weight of the upstream reached the max weight.
+	TrafficShiftStateSucceeded = "Succeeded"
+	// TrafficShiftStateInvalid is used when the TrafficShift failed validation or references a route that can't be shifted.
+	TrafficShiftStateInvalid = "Invalid"
+)
+
+const (
+	// TrafficShiftOnFailurePause pauses the shift when the upstream returns too many errors.
+	TrafficShiftOnFailurePause = "pause"
+	// TrafficShiftOnFailureRollback shifts the traffic back from the upstream when it returns too many errors.
+	TrafficShiftOnFailureRollback = "rollback"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:validation:Optional
@@ -71,6 +91,8 @@ type TransportServerSpec struct {
 	ServerSnippets     string                  `json:"serverSnippets"`
 	StreamSnippets     string                  `json:"streamSnippets"`
 	Host               string                  `json:"host"`
+	TLS                *TransportServerTLS     `json:"tls"`
+	Policies           []PolicyReference       `json:"policies"`
 	Upstreams          []Upstream              `json:"upstreams"`
 	UpstreamParameters *UpstreamParameters     `json:"upstreamParameters"`
 	SessionParameters  *SessionParameters      `json:"sessionParameters"`
@@ -83,16 +105,71 @@ type TransportServerListener struct {
 	Protocol string `json:"protocol"`
 }
 
+// PolicyReference references a policy by name and an optional namespace.
+type PolicyReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// TransportServerTLS defines the TLS termination of the connections of a TransportServer.
+type TransportServerTLS struct {
+	Secret           string `json:"secret"`
+	ClientCertSecret string `json:"clientCertSecret"`
+	VerifyClient     string `json:"verifyClient"`
+	VerifyDepth      *int   `json:"verifyDepth"`
+}
+
 // Upstream defines an upstream.
 type Upstream struct {
-	Name                string       `json:"name"`
-	Service             string       `json:"service"`
-	Port                int          `json:"port"`
-	FailTimeout         string       `json:"failTimeout"`
-	MaxFails            *int         `json:"maxFails"`
-	MaxConns            *int         `json:"maxConns"`
-	HealthCheck         *HealthCheck `json:"healthCheck"`
-	LoadBalancingMethod string       `json:"loadBalancingMethod"`
+	Name                string         `json:"name"`
+	Service             string         `json:"service"`
+	Port                int            `json:"port"`
+	FailTimeout         string         `json:"failTimeout"`
+	MaxFails            *int           `json:"maxFails"`
+	MaxConns            *int           `json:"maxConns"`
+	HealthCheck         *HealthCheck   `json:"healthCheck"`
+	LoadBalancingMethod string         `json:"loadBalancingMethod"`
+	LoadBalancing       *LoadBalancing `json:"loadBalancing"`
+	TLS                 *UpstreamTLS   `json:"tls"`
+}
+
+// LoadBalancing defines the method of load balancing the connections between the servers of an upstream.
+// Persistence is a shorthand for a method that keeps the connections of a client on the same server.
+type LoadBalancing struct {
+	Method      string                  `json:"method"`
+	Persistence string                  `json:"persistence"`
+	Hash        *HashLoadBalancing      `json:"hash"`
+	Random      *RandomLoadBalancing    `json:"random"`
+	LeastTime   *LeastTimeLoadBalancing `json:"leastTime"`
+}
+
+// HashLoadBalancing defines the hash load balancing method, which chooses a server by the hash of a key.
+type HashLoadBalancing struct {
+	Key        string `json:"key"`
+	Consistent bool   `json:"consistent"`
+}
+
+// RandomLoadBalancing defines the random load balancing method.
+type RandomLoadBalancing struct {
+	Two    bool   `json:"two"`
+	Method string `json:"method"`
+}
+
+// LeastTimeLoadBalancing defines the least time load balancing method. It is supported only in NGINX Plus.
+type LeastTimeLoadBalancing struct {
+	Measure  string `json:"measure"`
+	Inflight bool   `json:"inflight"`
+}
+
+// UpstreamTLS defines the TLS configuration of the connections to an upstream.
+type UpstreamTLS struct {
+	Enable            bool   `json:"enable"`
+	TLSSecret         string `json:"tlsSecret"`
+	TrustedCertSecret string `json:"trustedCertSecret"`
+	VerifyServer      bool   `json:"verifyServer"`
+	VerifyDepth       *int   `json:"verifyDepth"`
+	ServerName        bool   `json:"serverName"`
+	SSLName           string `json:"sslName"`
 }
 
 // HealthCheck defines the parameters for active Upstream HealthChecks.
@@ -151,6 +228,66 @@ type TransportServerList struct {
 	Items []TransportServer `json:"items"`
 }
 
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:validation:Optional
+// +kubebuilder:resource:shortName=tsh
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`,description="Current state of the TrafficShift."
+// +kubebuilder:printcolumn:name="Weight",type=integer,JSONPath=`.status.weight`,description="Current weight of the upstream."
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// TrafficShift defines the TrafficShift resource. It shifts the traffic of a route of a VirtualServer
+// to an upstream in steps, as long as the ratio of the 5xx responses of the upstream stays below a threshold.
+type TrafficShift struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TrafficShiftSpec   `json:"spec"`
+	Status TrafficShiftStatus `json:"status"`
+}
+
+// TrafficShiftSpec is the spec of the TrafficShift resource.
+type TrafficShiftSpec struct {
+	// VirtualServer is the name of the VirtualServer in the namespace of the TrafficShift.
+	VirtualServer string `json:"virtualServer"`
+	// Route is the path of the route of the VirtualServer. The route must have two splits.
+	Route string `json:"route"`
+	// Upstream is the name of the upstream of one of the splits of the route. The traffic is shifted to this upstream.
+	Upstream string `json:"upstream"`
+	// StepWeight is the weight added to the weight of the upstream at each step.
+	StepWeight int `json:"stepWeight"`
+	// Interval is the time between the steps.
+	Interval string `json:"interval"`
+	// MaxWeight is the weight of the upstream at which the shift succeeds. The default is 100.
+	MaxWeight *int `json:"maxWeight"`
+	// ErrorThreshold is the maximum percentage of the 5xx responses of the upstream during a step.
+	ErrorThreshold int `json:"errorThreshold"`
+	// OnFailure is the action when the ratio of the 5xx responses crosses the threshold: pause or rollback.
+	OnFailure string `json:"onFailure"`
+}
+
+// TrafficShiftStatus defines the status for the TrafficShift resource.
+type TrafficShiftStatus struct {
+	State   string `json:"state"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	// Weight is the current weight of the upstream.
+	Weight int `json:"weight"`
+	// LastStepTime is the time of the last step in RFC 3339 format.
+	LastStepTime string `json:"lastStepTime"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TrafficShiftList is a list of the TrafficShift resources.
+type TrafficShiftList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []TrafficShift `json:"items"`
+}
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:validation:Optional
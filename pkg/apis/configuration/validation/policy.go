@@ -8,19 +8,32 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/nginxinc/kubernetes-ingress/internal/configs"
 	v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
+// PolicyValidationOptions holds the features of the Ingress Controller that the validation of a Policy depends on.
+type PolicyValidationOptions struct {
+	IsPlus           bool
+	EnableOIDC       bool
+	EnableAppProtect bool
+	EnableGeoIP2     bool
+	EnableBrotli     bool
+	EnableAPIKeyAuth bool
+}
+
 // ValidatePolicy validates a Policy.
-func ValidatePolicy(policy *v1.Policy, isPlus, enableOIDC, enableAppProtect bool) error {
-	allErrs := validatePolicySpec(&policy.Spec, field.NewPath("spec"), isPlus, enableOIDC, enableAppProtect)
+func ValidatePolicy(policy *v1.Policy, options PolicyValidationOptions) error {
+	allErrs := validatePolicySpec(&policy.Spec, field.NewPath("spec"), options)
 	return allErrs.ToAggregate()
 }
 
-func validatePolicySpec(spec *v1.PolicySpec, fieldPath *field.Path, isPlus, enableOIDC, enableAppProtect bool) field.ErrorList {
+func validatePolicySpec(spec *v1.PolicySpec, fieldPath *field.Path, options PolicyValidationOptions) field.ErrorList {
 	allErrs := field.ErrorList{}
+	isPlus := options.IsPlus
 
 	fieldCount := 0
 
@@ -59,7 +72,7 @@ func validatePolicySpec(spec *v1.PolicySpec, fieldPath *field.Path, isPlus, enab
 	}
 
 	if spec.OIDC != nil {
-		if !enableOIDC {
+		if !options.EnableOIDC {
 			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("oidc"),
 				"OIDC must be enabled via cli argument -enable-oidc to use OIDC policy"))
 		}
@@ -75,7 +88,7 @@ func validatePolicySpec(spec *v1.PolicySpec, fieldPath *field.Path, isPlus, enab
 		if !isPlus {
 			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("waf"), "WAF is only supported in NGINX Plus"))
 		}
-		if !enableAppProtect {
+		if !options.EnableAppProtect {
 			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("waf"),
 				"App Protect must be enabled via cli argument -enable-appprotect to use WAF policy"))
 		}
@@ -84,8 +97,68 @@ func validatePolicySpec(spec *v1.PolicySpec, fieldPath *field.Path, isPlus, enab
 		fieldCount++
 	}
 
+	if spec.CORS != nil {
+		allErrs = append(allErrs, validateCORS(spec.CORS, fieldPath.Child("cors"))...)
+		fieldCount++
+	}
+
+	if spec.ExternalAuth != nil {
+		allErrs = append(allErrs, validateExternalAuth(spec.ExternalAuth, fieldPath.Child("externalAuth"), isPlus)...)
+		fieldCount++
+	}
+
+	if spec.APIKey != nil {
+		if !options.EnableAPIKeyAuth {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("apiKey"),
+				"API key authentication must be enabled via cli argument -enable-apikey-auth to use apiKey policy"))
+		}
+
+		allErrs = append(allErrs, validateAPIKey(spec.APIKey, fieldPath.Child("apiKey"))...)
+		fieldCount++
+	}
+
+	if spec.Cache != nil {
+		allErrs = append(allErrs, validateCache(spec.Cache, fieldPath.Child("cache"), isPlus)...)
+		fieldCount++
+	}
+
+	if spec.Resilience != nil {
+		allErrs = append(allErrs, validateResilience(spec.Resilience, fieldPath.Child("resilience"), isPlus)...)
+		fieldCount++
+	}
+
+	if spec.GeoAccess != nil {
+		if !options.EnableGeoIP2 {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("geoAccess"),
+				"GeoIP2 must be enabled via cli argument -enable-geoip2 to use geoAccess policy"))
+		}
+
+		allErrs = append(allErrs, validateGeoAccess(spec.GeoAccess, fieldPath.Child("geoAccess"))...)
+		fieldCount++
+	}
+
+	if spec.Headers != nil {
+		allErrs = append(allErrs, validateHeadersPolicy(spec.Headers, fieldPath.Child("headers"), isPlus)...)
+		fieldCount++
+	}
+
+	if spec.Compression != nil {
+		allErrs = append(allErrs, validateCompression(spec.Compression, fieldPath.Child("compression"), options.EnableBrotli)...)
+		fieldCount++
+	}
+
+	if spec.ConnectionLimit != nil {
+		allErrs = append(allErrs, validateConnectionLimit(spec.ConnectionLimit, fieldPath.Child("connectionLimit"), isPlus)...)
+		fieldCount++
+	}
+
+	if spec.Bandwidth != nil {
+		allErrs = append(allErrs, validateBandwidth(spec.Bandwidth, fieldPath.Child("bandwidth"))...)
+		fieldCount++
+	}
+
 	if fieldCount != 1 {
-		msg := "must specify exactly one of: `accessControl`, `rateLimit`, `ingressMTLS`, `egressMTLS`, `basicAuth`"
+		msg := "must specify exactly one of: `accessControl`, `rateLimit`, `ingressMTLS`, `egressMTLS`, `basicAuth`, `cors`, `externalAuth`, `apiKey`, `cache`, `resilience`, `geoAccess`, `headers`, `compression`, `connectionLimit`, `bandwidth`"
 		if isPlus {
 			msg = fmt.Sprint(msg, ", `jwt`, `oidc`, `waf`")
 		}
@@ -125,7 +198,14 @@ func validateRateLimit(rateLimit *v1.RateLimit, fieldPath *field.Path, isPlus bo
 	allErrs := field.ErrorList{}
 
 	allErrs = append(allErrs, validateRateLimitZoneSize(rateLimit.ZoneSize, fieldPath.Child("zoneSize"))...)
-	allErrs = append(allErrs, validateRate(rateLimit.Rate, fieldPath.Child("rate"))...)
+	if len(rateLimit.Tiers) > 0 {
+		if rateLimit.Rate != "" {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("rate"), "can't be used with `tiers`"))
+		}
+		allErrs = append(allErrs, validateRateLimitTiers(rateLimit.Tiers, rateLimit.ZoneSize, fieldPath.Child("tiers"), isPlus)...)
+	} else {
+		allErrs = append(allErrs, validateRate(rateLimit.Rate, fieldPath.Child("rate"))...)
+	}
 	allErrs = append(allErrs, validateRateLimitKey(rateLimit.Key, fieldPath.Child("key"), isPlus)...)
 
 	if rateLimit.Delay != nil {
@@ -150,6 +230,50 @@ func validateRateLimit(rateLimit *v1.RateLimit, fieldPath *field.Path, isPlus bo
 	return allErrs
 }
 
+// connectionLimitKeyVariables includes NGINX stream variables allowed to be used in a connectionLimit policy key.
+var connectionLimitKeyVariables = map[string]bool{
+	"binary_remote_addr": true,
+	"remote_addr":        true,
+	"server_addr":        true,
+	"server_port":        true,
+	"ssl_server_name":    true,
+}
+
+func validateConnectionLimit(connectionLimit *v1.ConnectionLimit, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validateRateLimitZoneSize(connectionLimit.ZoneSize, fieldPath.Child("zoneSize"))...)
+	allErrs = append(allErrs, validatePositiveInt(connectionLimit.MaxConnections, fieldPath.Child("maxConnections"))...)
+
+	if connectionLimit.Key == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("key"), ""))
+	} else {
+		if err := ValidateEscapedString(connectionLimit.Key, `Hello World! \n`, `\"${remote_addr}\" is unavailable. \n`); err != nil {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("key"), connectionLimit.Key, err.Error()))
+		}
+		allErrs = append(allErrs, validateStringWithVariables(connectionLimit.Key, fieldPath.Child("key"), nil, connectionLimitKeyVariables, isPlus)...)
+	}
+
+	if connectionLimit.LogLevel != "" {
+		allErrs = append(allErrs, validateRateLimitLogLevel(connectionLimit.LogLevel, fieldPath.Child("logLevel"))...)
+	}
+
+	return allErrs
+}
+
+func validateBandwidth(bandwidth *v1.Bandwidth, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if bandwidth.UploadRate == "" && bandwidth.DownloadRate == "" {
+		return append(allErrs, field.Required(fieldPath, "must specify at least one of: `uploadRate`, `downloadRate`"))
+	}
+
+	allErrs = append(allErrs, validateSize(bandwidth.UploadRate, fieldPath.Child("uploadRate"))...)
+	allErrs = append(allErrs, validateSize(bandwidth.DownloadRate, fieldPath.Child("downloadRate"))...)
+
+	return allErrs
+}
+
 func validateJWT(jwt *v1.JWTAuth, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -159,10 +283,26 @@ func validateJWT(jwt *v1.JWTAuth, fieldPath *field.Path) field.ErrorList {
 		allErrs = append(allErrs, validateRealm(jwt.Realm, fieldPath.Child("realm"))...)
 	}
 
-	if jwt.Secret == "" {
-		return append(allErrs, field.Required(fieldPath.Child("secret"), ""))
+	if jwt.Secret == "" && jwt.JwksURI == "" {
+		return append(allErrs, field.Required(fieldPath, "must specify exactly one of: `secret` or `jwksURI`"))
+	}
+	if jwt.Secret != "" && jwt.JwksURI != "" {
+		return append(allErrs, field.Invalid(fieldPath, "", "must specify exactly one of: `secret` or `jwksURI`"))
+	}
+
+	if jwt.Secret != "" {
+		allErrs = append(allErrs, validateSecretName(jwt.Secret, fieldPath.Child("secret"))...)
+
+		if jwt.KeyCache != "" {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("keyCache"), "can only be used with `jwksURI`"))
+		}
+	} else {
+		allErrs = append(allErrs, validateURL(jwt.JwksURI, fieldPath.Child("jwksURI"))...)
+
+		if jwt.KeyCache != "" {
+			allErrs = append(allErrs, validateTime(jwt.KeyCache, fieldPath.Child("keyCache"))...)
+		}
 	}
-	allErrs = append(allErrs, validateSecretName(jwt.Secret, fieldPath.Child("secret"))...)
 
 	allErrs = append(allErrs, validateJWTToken(jwt.Token, fieldPath.Child("token"))...)
 
@@ -184,6 +324,48 @@ func validateBasic(basic *v1.BasicAuth, fieldPath *field.Path) field.ErrorList {
 	return allErrs
 }
 
+func validateAPIKey(apiKey *v1.APIKey, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if apiKey.SuppliedIn == nil || (len(apiKey.SuppliedIn.Header) == 0 && len(apiKey.SuppliedIn.Query) == 0) {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("suppliedIn"), "must specify at least one header or query parameter"))
+	} else {
+		for i, h := range apiKey.SuppliedIn.Header {
+			for _, msg := range validation.IsHTTPHeaderName(h) {
+				allErrs = append(allErrs, field.Invalid(fieldPath.Child("suppliedIn", "header").Index(i), h, msg))
+			}
+		}
+		for i, q := range apiKey.SuppliedIn.Query {
+			allErrs = append(allErrs, validateQueryParameterName(q, fieldPath.Child("suppliedIn", "query").Index(i))...)
+		}
+	}
+
+	if apiKey.ClientSecret == "" {
+		return append(allErrs, field.Required(fieldPath.Child("clientSecret"), ""))
+	}
+	allErrs = append(allErrs, validateSecretName(apiKey.ClientSecret, fieldPath.Child("clientSecret"))...)
+
+	return allErrs
+}
+
+const (
+	queryParameterNameFmt    = `[a-zA-Z0-9_-]+`
+	queryParameterNameErrMsg = "a valid query parameter name must consist of alphanumeric characters, '-' or '_'"
+)
+
+var queryParameterNameRegexp = regexp.MustCompile("^" + queryParameterNameFmt + "$")
+
+func validateQueryParameterName(name string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !queryParameterNameRegexp.MatchString(name) {
+		msg := validation.RegexError(queryParameterNameErrMsg, queryParameterNameFmt, "apikey", "api-key")
+		allErrs = append(allErrs, field.Invalid(fieldPath, name, msg))
+	}
+
+	return allErrs
+}
+
 func validateIngressMTLS(ingressMTLS *v1.IngressMTLS, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -275,6 +457,735 @@ func validateWAF(waf *v1.WAF, fieldPath *field.Path) field.ErrorList {
 	return allErrs
 }
 
+// corsMethods are the HTTP methods allowed in the allowMethods field of a CORS policy.
+var corsMethods = map[string]bool{
+	"GET":     true,
+	"HEAD":    true,
+	"POST":    true,
+	"PUT":     true,
+	"DELETE":  true,
+	"PATCH":   true,
+	"OPTIONS": true,
+}
+
+func validateCORS(cors *v1.CORS, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allowCredentials := cors.AllowCredentials != nil && *cors.AllowCredentials
+
+	if len(cors.AllowOrigin) == 0 {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("allowOrigin"), ""))
+	}
+	for i, origin := range cors.AllowOrigin {
+		idxPath := fieldPath.Child("allowOrigin").Index(i)
+		if origin == "*" {
+			if allowCredentials {
+				allErrs = append(allErrs, field.Forbidden(idxPath, "the wildcard origin can't be used with `allowCredentials`"))
+			}
+			continue
+		}
+		allErrs = append(allErrs, validateCORSOrigin(origin, idxPath)...)
+	}
+
+	for i, method := range cors.AllowMethods {
+		if !corsMethods[method] {
+			msg := fmt.Sprintf("not a valid method. Accepted methods are: %v", mapToPrettyString(corsMethods))
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("allowMethods").Index(i), method, msg))
+		}
+	}
+
+	allErrs = append(allErrs, validateCORSHeaders(cors.AllowHeaders, allowCredentials, fieldPath.Child("allowHeaders"))...)
+	allErrs = append(allErrs, validateCORSHeaders(cors.ExposeHeaders, allowCredentials, fieldPath.Child("exposeHeaders"))...)
+
+	allErrs = append(allErrs, validatePositiveIntOrZeroFromPointer(cors.MaxAge, fieldPath.Child("maxAge"))...)
+
+	return allErrs
+}
+
+// validateCORSOrigin validates an origin of a CORS policy. An origin is either a regular expression that starts with `~`
+// or an origin like `https://example.com` with an optional wildcard for the subdomains like `https://*.example.com`.
+func validateCORSOrigin(origin string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if strings.HasPrefix(origin, "~") {
+		return append(allErrs, validateRegexPath(origin[1:], fieldPath)...)
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil ||
+		u.Path != "" || u.RawQuery != "" || u.Fragment != "" {
+		return append(allErrs, field.Invalid(fieldPath, origin, "must be an origin like `https://example.com` or `https://*.example.com`, a regular expression that starts with `~` or `*`"))
+	}
+
+	host := strings.TrimPrefix(u.Hostname(), "*.")
+	if net.ParseIP(host) == nil {
+		for _, msg := range validation.IsDNS1123Subdomain(host) {
+			allErrs = append(allErrs, field.Invalid(fieldPath, origin, msg))
+		}
+	}
+
+	if port := u.Port(); port != "" {
+		allErrs = append(allErrs, validatePortNumber(port, fieldPath)...)
+	}
+
+	return allErrs
+}
+
+func validateCORSHeaders(headers []string, allowCredentials bool, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, h := range headers {
+		if h == "*" {
+			if allowCredentials {
+				allErrs = append(allErrs, field.Forbidden(fieldPath.Index(i), "the wildcard header can't be used with `allowCredentials`"))
+			}
+			continue
+		}
+		for _, msg := range validation.IsHTTPHeaderName(h) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Index(i), h, msg))
+		}
+	}
+
+	return allErrs
+}
+
+func validateExternalAuth(extAuth *v1.ExternalAuth, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validateServiceName(extAuth.AuthService, fieldPath.Child("authService"))...)
+
+	for _, msg := range validation.IsValidPortNum(extAuth.AuthServicePort) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("authServicePort"), extAuth.AuthServicePort, msg))
+	}
+
+	if extAuth.AuthPath != "" {
+		allErrs = append(allErrs, validateExternalAuthPath(extAuth.AuthPath, fieldPath.Child("authPath"))...)
+	}
+
+	for i, h := range extAuth.ForwardHeaders {
+		for _, msg := range validation.IsHTTPHeaderName(h) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("forwardHeaders").Index(i), h, msg))
+		}
+	}
+
+	for i, h := range extAuth.ResponseHeaders {
+		for _, msg := range validation.IsHTTPHeaderName(h) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("responseHeaders").Index(i), h, msg))
+		}
+	}
+
+	if extAuth.Cache != nil {
+		allErrs = append(allErrs, validateExternalAuthCache(extAuth.Cache, fieldPath.Child("cache"), isPlus)...)
+	}
+
+	return allErrs
+}
+
+func validateExternalAuthPath(path string, fieldPath *field.Path) field.ErrorList {
+	allErrs := validatePath(path, fieldPath)
+
+	// the path is a part of the proxy_pass directive, where variables change how NGINX resolves the upstream
+	if strings.Contains(path, "$") {
+		allErrs = append(allErrs, field.Invalid(fieldPath, path, "must not contain `$`"))
+	}
+
+	return allErrs
+}
+
+// cacheSpecialVariables includes the special NGINX variables allowed to be used in the keys and the conditions
+// of the caches of the externalAuth and cache policies.
+var cacheSpecialVariables = []string{"arg_", "http_", "cookie_"}
+
+// externalAuthCacheKeyVariables includes NGINX variables allowed to be used in the cache key of an externalAuth policy.
+var externalAuthCacheKeyVariables = map[string]bool{
+	"remote_addr":    true,
+	"request_method": true,
+	"request_uri":    true,
+	"uri":            true,
+	"args":           true,
+	"host":           true,
+}
+
+// externalAuthCacheKeyRequiredVariables includes NGINX variables that the cache key of an externalAuth policy must include.
+// The authorization service gets the method and the URI of the client request, so its decisions can depend on them.
+var externalAuthCacheKeyRequiredVariables = []string{"request_method", "request_uri"}
+
+func validateExternalAuthCache(cache *v1.ExternalAuthCache, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if cache.ZoneSize != "" {
+		allErrs = append(allErrs, validateSize(cache.ZoneSize, fieldPath.Child("zoneSize"))...)
+	}
+
+	if cache.Key != "" {
+		if err := ValidateEscapedString(cache.Key, `${http_authorization}${request_method}${request_uri}`, `${cookie_session}${request_method}${request_uri}`); err != nil {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("key"), cache.Key, err.Error()))
+		}
+		allErrs = append(allErrs, validateStringWithVariables(cache.Key, fieldPath.Child("key"),
+			cacheSpecialVariables, externalAuthCacheKeyVariables, isPlus)...)
+
+		keyVars := make(map[string]bool)
+		for _, v := range captureVariables(cache.Key) {
+			keyVars[v] = true
+		}
+		for _, v := range externalAuthCacheKeyRequiredVariables {
+			if !keyVars[v] {
+				msg := fmt.Sprintf("must include ${%s}, so that a decision for one request is not reused for requests with other methods or URIs", v)
+				allErrs = append(allErrs, field.Invalid(fieldPath.Child("key"), cache.Key, msg))
+			}
+		}
+	}
+
+	if cache.Valid == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("valid"), ""))
+	} else {
+		allErrs = append(allErrs, validateTime(cache.Valid, fieldPath.Child("valid"))...)
+	}
+
+	return allErrs
+}
+
+// cacheKeyVariables includes NGINX variables allowed to be used in the key of a cache policy.
+var cacheKeyVariables = map[string]bool{
+	"scheme":         true,
+	"host":           true,
+	"request_method": true,
+	"request_uri":    true,
+	"uri":            true,
+	"args":           true,
+}
+
+// cacheMethods includes the methods of the requests that can be cached.
+var cacheMethods = map[string]bool{
+	"GET":  true,
+	"HEAD": true,
+	"POST": true,
+}
+
+func validateCache(cache *v1.Cache, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if cache.ZoneSize == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("zoneSize"), ""))
+	} else {
+		allErrs = append(allErrs, validateSize(cache.ZoneSize, fieldPath.Child("zoneSize"))...)
+	}
+
+	allErrs = append(allErrs, validateOffset(cache.MaxSize, fieldPath.Child("maxSize"))...)
+
+	for i, v := range cache.Valid {
+		allErrs = append(allErrs, validateCacheValid(v, fieldPath.Child("valid").Index(i))...)
+	}
+
+	if cache.Key != "" {
+		if err := ValidateEscapedString(cache.Key, `${scheme}${host}${request_uri}`, `${request_method}${uri}`); err != nil {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("key"), cache.Key, err.Error()))
+		}
+		allErrs = append(allErrs, validateStringWithVariables(cache.Key, fieldPath.Child("key"),
+			cacheSpecialVariables, cacheKeyVariables, isPlus)...)
+	}
+
+	allErrs = append(allErrs, validateCacheConditions(cache.Bypass, fieldPath.Child("bypass"), isPlus)...)
+	allErrs = append(allErrs, validateCacheConditions(cache.NoCache, fieldPath.Child("noCache"), isPlus)...)
+
+	for i, method := range cache.Methods {
+		if !cacheMethods[method] {
+			msg := fmt.Sprintf("not a valid method. Accepted methods are: %v", mapToPrettyString(cacheMethods))
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("methods").Index(i), method, msg))
+		}
+	}
+
+	if cache.Purge != nil {
+		if !isPlus {
+			return append(allErrs, field.Forbidden(fieldPath.Child("purge"), "purge is only supported in NGINX Plus"))
+		}
+
+		if len(cache.Purge.Allow) == 0 {
+			allErrs = append(allErrs, field.Required(fieldPath.Child("purge", "allow"), ""))
+		}
+		for i, ipOrCIDR := range cache.Purge.Allow {
+			allErrs = append(allErrs, validateIPorCIDR(ipOrCIDR, fieldPath.Child("purge", "allow").Index(i))...)
+		}
+	}
+
+	return allErrs
+}
+
+func validateCacheValid(valid v1.CacheValid, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, code := range valid.Codes {
+		if code < 100 || code > 599 {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("codes").Index(i), code, "must be between 100 and 599"))
+		}
+	}
+
+	if valid.Time == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("time"), ""))
+	} else {
+		allErrs = append(allErrs, validateTime(valid.Time, fieldPath.Child("time"))...)
+	}
+
+	return allErrs
+}
+
+// validateCacheConditions validates the conditions of the bypass and noCache fields of a cache policy.
+// A condition is true when it is not empty and not equal to "0".
+func validateCacheConditions(conditions []string, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, c := range conditions {
+		idxPath := fieldPath.Index(i)
+		if c == "" {
+			allErrs = append(allErrs, field.Required(idxPath, ""))
+			continue
+		}
+		if err := ValidateEscapedString(c, `${cookie_nocache}`, `${arg_nocache}${http_pragma}`); err != nil {
+			allErrs = append(allErrs, field.Invalid(idxPath, c, err.Error()))
+		}
+		allErrs = append(allErrs, validateStringWithVariables(c, idxPath, cacheSpecialVariables, map[string]bool{}, isPlus)...)
+	}
+
+	return allErrs
+}
+
+func validateResilience(resilience *v1.Resilience, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if resilience.Retry == nil && resilience.CircuitBreaker == nil {
+		return append(allErrs, field.Required(fieldPath, "must specify at least one of: `retry`, `circuitBreaker`"))
+	}
+
+	if resilience.Retry != nil {
+		allErrs = append(allErrs, validateRetry(resilience.Retry, fieldPath.Child("retry"))...)
+	}
+
+	if resilience.CircuitBreaker != nil {
+		allErrs = append(allErrs, validateCircuitBreaker(resilience.CircuitBreaker, fieldPath.Child("circuitBreaker"), isPlus)...)
+	}
+
+	return allErrs
+}
+
+// retryConditions includes the conditions of the proxy_next_upstream directive allowed in a retry.
+var retryConditions = map[string]bool{
+	"error":          true,
+	"timeout":        true,
+	"invalid_header": true,
+	"http_500":       true,
+	"http_502":       true,
+	"http_503":       true,
+	"http_504":       true,
+	"http_403":       true,
+	"http_404":       true,
+	"http_429":       true,
+}
+
+func validateRetry(retry *v1.Retry, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	conditions := sets.String{}
+	for i, c := range retry.Conditions {
+		idxPath := fieldPath.Child("conditions").Index(i)
+		if !retryConditions[c] {
+			msg := fmt.Sprintf("not a valid condition. Accepted conditions are: %v", mapToPrettyString(retryConditions))
+			allErrs = append(allErrs, field.Invalid(idxPath, c, msg))
+		} else if conditions.Has(c) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, c))
+		}
+		conditions.Insert(c)
+	}
+
+	allErrs = append(allErrs, validatePositiveIntOrZeroFromPointer(retry.MaxAttempts, fieldPath.Child("maxAttempts"))...)
+	allErrs = append(allErrs, validateTime(retry.Timeout, fieldPath.Child("timeout"))...)
+	allErrs = append(allErrs, validateTime(retry.PerTryTimeout, fieldPath.Child("perTryTimeout"))...)
+
+	return allErrs
+}
+
+func validateCircuitBreaker(circuitBreaker *v1.CircuitBreaker, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validatePositiveIntOrZeroFromPointer(circuitBreaker.MaxFails, fieldPath.Child("maxFails"))...)
+	allErrs = append(allErrs, validateTime(circuitBreaker.FailTimeout, fieldPath.Child("failTimeout"))...)
+
+	if circuitBreaker.SlowStart != "" {
+		if !isPlus {
+			return append(allErrs, field.Forbidden(fieldPath.Child("slowStart"), "slow start is only supported in NGINX Plus"))
+		}
+		allErrs = append(allErrs, validateTime(circuitBreaker.SlowStart, fieldPath.Child("slowStart"))...)
+	}
+
+	return allErrs
+}
+
+func validateGeoAccess(geoAccess *v1.GeoAccess, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if geoAccess.CountryDatabase == "" && geoAccess.ASNDatabase == "" {
+		allErrs = append(allErrs, field.Required(fieldPath, "must specify at least one of: `countryDatabase`, `asnDatabase`"))
+	}
+	if geoAccess.CountryDatabase != "" {
+		allErrs = append(allErrs, validateGeoDatabase(geoAccess.CountryDatabase, fieldPath.Child("countryDatabase"))...)
+	}
+	if geoAccess.ASNDatabase != "" {
+		allErrs = append(allErrs, validateGeoDatabase(geoAccess.ASNDatabase, fieldPath.Child("asnDatabase"))...)
+	}
+
+	if (geoAccess.Allow == nil) == (geoAccess.Deny == nil) {
+		allErrs = append(allErrs, field.Invalid(fieldPath, "", "must specify exactly one of: `allow` or `deny`"))
+	}
+	if geoAccess.Allow != nil {
+		allErrs = append(allErrs, validateGeoRules(geoAccess.Allow, geoAccess, fieldPath.Child("allow"))...)
+	}
+	if geoAccess.Deny != nil {
+		allErrs = append(allErrs, validateGeoRules(geoAccess.Deny, geoAccess, fieldPath.Child("deny"))...)
+	}
+
+	if geoAccess.RejectCode != nil {
+		if *geoAccess.RejectCode < 400 || *geoAccess.RejectCode > 599 {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("rejectCode"), geoAccess.RejectCode,
+				"must be within the range [400-599]"))
+		}
+	}
+
+	return allErrs
+}
+
+const (
+	geoDatabaseFmt    = `/[^\s{};"'\\]*`
+	geoDatabaseErrMsg = "must be an absolute path without whitespace characters, `{`, `}`, `;`, quotes or backslashes"
+)
+
+var geoDatabaseRegexp = regexp.MustCompile("^" + geoDatabaseFmt + "$")
+
+func validateGeoDatabase(database string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !geoDatabaseRegexp.MatchString(database) {
+		msg := validation.RegexError(geoDatabaseErrMsg, geoDatabaseFmt, "/etc/nginx/geoip/GeoLite2-Country.mmdb")
+		allErrs = append(allErrs, field.Invalid(fieldPath, database, msg))
+	}
+
+	return allErrs
+}
+
+const (
+	countryCodeFmt    = `[A-Z]{2}`
+	countryCodeErrMsg = "must be an ISO 3166-1 alpha-2 country code in upper case"
+)
+
+var countryCodeRegexp = regexp.MustCompile("^" + countryCodeFmt + "$")
+
+func validateGeoRules(rules *v1.GeoRules, geoAccess *v1.GeoAccess, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(rules.Countries) == 0 && len(rules.ASNs) == 0 {
+		return append(allErrs, field.Required(fieldPath, "must specify at least one of: `countries`, `asns`"))
+	}
+
+	if len(rules.Countries) > 0 && geoAccess.CountryDatabase == "" {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("countries"), "can only be used with `countryDatabase`"))
+	}
+	for i, c := range rules.Countries {
+		if !countryCodeRegexp.MatchString(c) {
+			msg := validation.RegexError(countryCodeErrMsg, countryCodeFmt, "US", "DE")
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("countries").Index(i), c, msg))
+		}
+	}
+
+	if len(rules.ASNs) > 0 && geoAccess.ASNDatabase == "" {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("asns"), "can only be used with `asnDatabase`"))
+	}
+	for i, asn := range rules.ASNs {
+		if asn <= 0 {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("asns").Index(i), asn, "must be positive"))
+		}
+	}
+
+	return allErrs
+}
+
+// headersPolicyReservedRequestHeaders are the headers of the requests that the Ingress Controller sets for every location.
+var headersPolicyReservedRequestHeaders = map[string]bool{
+	"host":              true,
+	"connection":        true,
+	"upgrade":           true,
+	"x-real-ip":         true,
+	"x-forwarded-for":   true,
+	"x-forwarded-host":  true,
+	"x-forwarded-port":  true,
+	"x-forwarded-proto": true,
+}
+
+func validateHeadersPolicy(headers *v1.Headers, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if headers.Request == nil && headers.Response == nil && headers.SecurityHeaders == nil {
+		return append(allErrs, field.Required(fieldPath, "must specify at least one of: `request`, `response`, `securityHeaders`"))
+	}
+
+	if headers.Request != nil {
+		allErrs = append(allErrs, validateRequestHeadersTransform(headers.Request, fieldPath.Child("request"), isPlus)...)
+	}
+
+	if headers.Response != nil {
+		allErrs = append(allErrs, validateResponseHeadersTransform(headers.Response, fieldPath.Child("response"), isPlus)...)
+	}
+
+	if headers.SecurityHeaders != nil {
+		allErrs = append(allErrs, validateSecurityHeaders(headers.SecurityHeaders, fieldPath.Child("securityHeaders"))...)
+	}
+
+	return allErrs
+}
+
+func validateRequestHeadersTransform(request *v1.RequestHeadersTransform, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	names := sets.NewString()
+	validateName := func(name string, namePath *field.Path) {
+		allErrs = append(allErrs, validateHeadersPolicyHeaderName(name, namePath)...)
+		if headersPolicyReservedRequestHeaders[strings.ToLower(name)] {
+			allErrs = append(allErrs, field.Forbidden(namePath, "the header is set by the Ingress Controller"))
+		}
+		if names.Has(strings.ToLower(name)) {
+			allErrs = append(allErrs, field.Duplicate(namePath, name))
+		}
+		names.Insert(strings.ToLower(name))
+	}
+
+	for i, h := range request.Set {
+		idxPath := fieldPath.Child("set").Index(i)
+		validateName(h.Name, idxPath.Child("name"))
+		allErrs = append(allErrs, validateEscapedStringWithVariables(h.Value, idxPath.Child("value"),
+			actionProxyHeaderSpecialVariables, actionProxyHeaderVariables, isPlus)...)
+	}
+
+	for i, h := range request.Remove {
+		validateName(h, fieldPath.Child("remove").Index(i))
+	}
+
+	for i, r := range request.Rename {
+		idxPath := fieldPath.Child("rename").Index(i)
+		validateName(r.From, idxPath.Child("from"))
+		validateName(r.To, idxPath.Child("to"))
+	}
+
+	return allErrs
+}
+
+func validateResponseHeadersTransform(response *v1.ResponseHeadersTransform, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	names := sets.NewString()
+	validateName := func(name string, namePath *field.Path) {
+		allErrs = append(allErrs, validateHeadersPolicyHeaderName(name, namePath)...)
+		if names.Has(strings.ToLower(name)) {
+			allErrs = append(allErrs, field.Duplicate(namePath, name))
+		}
+		names.Insert(strings.ToLower(name))
+	}
+
+	for i, h := range response.Add {
+		idxPath := fieldPath.Child("add").Index(i)
+		validateName(h.Name, idxPath.Child("name"))
+		allErrs = append(allErrs, validateEscapedStringWithVariables(h.Value, idxPath.Child("value"),
+			actionProxyHeaderSpecialVariables, actionProxyHeaderVariables, isPlus)...)
+	}
+
+	for i, h := range response.Remove {
+		validateName(h, fieldPath.Child("remove").Index(i))
+	}
+
+	for i, r := range response.Rename {
+		idxPath := fieldPath.Child("rename").Index(i)
+		validateName(r.From, idxPath.Child("from"))
+		validateName(r.To, idxPath.Child("to"))
+	}
+
+	for i, r := range response.RewriteLocation {
+		allErrs = append(allErrs, validateHeaderRewrite(r, fieldPath.Child("rewriteLocation").Index(i))...)
+	}
+
+	for i, r := range response.RewriteCookieDomain {
+		allErrs = append(allErrs, validateHeaderRewrite(r, fieldPath.Child("rewriteCookieDomain").Index(i))...)
+	}
+
+	return allErrs
+}
+
+func validateHeadersPolicyHeaderName(name string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if name == "" {
+		return append(allErrs, field.Required(fieldPath, ""))
+	}
+
+	for _, msg := range validation.IsHTTPHeaderName(name) {
+		allErrs = append(allErrs, field.Invalid(fieldPath, name, msg))
+	}
+
+	return allErrs
+}
+
+func validateHeaderRewrite(rewrite v1.HeaderRewrite, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if rewrite.From == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("from"), ""))
+	} else if strings.HasPrefix(rewrite.From, "~") {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("from"), rewrite.From, "must not start with '~'"))
+	}
+
+	for _, msg := range isValidHeaderValue(rewrite.From) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("from"), rewrite.From, msg))
+	}
+
+	for _, msg := range isValidHeaderValue(rewrite.To) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("to"), rewrite.To, msg))
+	}
+
+	return allErrs
+}
+
+var validFrameOptions = map[string]bool{
+	"DENY":       true,
+	"SAMEORIGIN": true,
+}
+
+var validReferrerPolicies = map[string]bool{
+	"no-referrer":                     true,
+	"no-referrer-when-downgrade":      true,
+	"origin":                          true,
+	"origin-when-cross-origin":        true,
+	"same-origin":                     true,
+	"strict-origin":                   true,
+	"strict-origin-when-cross-origin": true,
+	"unsafe-url":                      true,
+}
+
+func validateSecurityHeaders(securityHeaders *v1.SecurityHeaders, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if securityHeaders.ContentSecurityPolicy == "" && securityHeaders.FrameOptions == "" && securityHeaders.ReferrerPolicy == "" {
+		return append(allErrs, field.Required(fieldPath, "must specify at least one of: `contentSecurityPolicy`, `frameOptions`, `referrerPolicy`"))
+	}
+
+	for _, msg := range isValidHeaderValue(securityHeaders.ContentSecurityPolicy) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("contentSecurityPolicy"), securityHeaders.ContentSecurityPolicy, msg))
+	}
+
+	if securityHeaders.FrameOptions != "" && !validFrameOptions[securityHeaders.FrameOptions] {
+		allErrs = append(allErrs, field.NotSupported(fieldPath.Child("frameOptions"), securityHeaders.FrameOptions,
+			[]string{"DENY", "SAMEORIGIN"}))
+	}
+
+	if securityHeaders.ReferrerPolicy != "" && !validReferrerPolicies[securityHeaders.ReferrerPolicy] {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("referrerPolicy"), securityHeaders.ReferrerPolicy,
+			fmt.Sprintf("Accepted values: %s", mapToPrettyString(validReferrerPolicies))))
+	}
+
+	return allErrs
+}
+
+var validCompressionAlgorithms = map[string]bool{
+	"gzip":   true,
+	"brotli": true,
+}
+
+func validateCompression(compression *v1.Compression, fieldPath *field.Path, enableBrotli bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	algorithmsPath := fieldPath.Child("algorithms")
+	if len(compression.Algorithms) == 0 {
+		allErrs = append(allErrs, field.Required(algorithmsPath, ""))
+	}
+
+	algorithms := sets.NewString()
+	for i, a := range compression.Algorithms {
+		idxPath := algorithmsPath.Index(i)
+		if !validCompressionAlgorithms[a] {
+			allErrs = append(allErrs, field.NotSupported(idxPath, a, []string{"gzip", "brotli"}))
+			continue
+		}
+		if algorithms.Has(a) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, a))
+			continue
+		}
+		algorithms.Insert(a)
+	}
+
+	if algorithms.Has("brotli") && !enableBrotli {
+		allErrs = append(allErrs, field.Forbidden(algorithmsPath,
+			"Brotli must be enabled via cli argument -enable-brotli to use the brotli algorithm"))
+	}
+
+	if compression.GzipLevel != nil {
+		levelPath := fieldPath.Child("gzipLevel")
+		if !algorithms.Has("gzip") {
+			allErrs = append(allErrs, field.Forbidden(levelPath, "can only be used with the gzip algorithm"))
+		}
+		if *compression.GzipLevel < 1 || *compression.GzipLevel > 9 {
+			allErrs = append(allErrs, field.Invalid(levelPath, *compression.GzipLevel, validation.InclusiveRangeError(1, 9)))
+		}
+	}
+
+	if compression.BrotliLevel != nil {
+		levelPath := fieldPath.Child("brotliLevel")
+		if !algorithms.Has("brotli") {
+			allErrs = append(allErrs, field.Forbidden(levelPath, "can only be used with the brotli algorithm"))
+		}
+		if *compression.BrotliLevel < 0 || *compression.BrotliLevel > 11 {
+			allErrs = append(allErrs, field.Invalid(levelPath, *compression.BrotliLevel, validation.InclusiveRangeError(0, 11)))
+		}
+	}
+
+	allErrs = append(allErrs, validatePositiveIntOrZeroFromPointer(compression.MinLength, fieldPath.Child("minLength"))...)
+
+	allErrs = append(allErrs, validateCompressionTypes(compression.Types, fieldPath.Child("types"))...)
+
+	return allErrs
+}
+
+const (
+	mimeTypeFmt    = `[a-z0-9][a-z0-9!#$&^_.+-]*/[a-z0-9][a-z0-9!#$&^_.+-]*`
+	mimeTypeErrMsg = "must be a MIME type in lower case"
+)
+
+var mimeTypeRegexp = regexp.MustCompile("^" + mimeTypeFmt + "$")
+
+func validateCompressionTypes(types []string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	encountered := sets.NewString()
+	for i, t := range types {
+		idxPath := fieldPath.Index(i)
+		if t == "*" {
+			if len(types) > 1 {
+				allErrs = append(allErrs, field.Invalid(idxPath, t, "`*` can't be used together with other types"))
+			}
+			continue
+		}
+		if !mimeTypeRegexp.MatchString(t) {
+			msg := validation.RegexError(mimeTypeErrMsg, mimeTypeFmt, "application/json", "image/svg+xml")
+			allErrs = append(allErrs, field.Invalid(idxPath, t, msg))
+			continue
+		}
+		if t == "text/html" {
+			allErrs = append(allErrs, field.Invalid(idxPath, t, "the responses of the type text/html are always compressed"))
+			continue
+		}
+		if encountered.Has(t) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, t))
+			continue
+		}
+		encountered.Insert(t)
+	}
+
+	return allErrs
+}
+
 func validateLogConf(logConf, logDest string, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -446,7 +1357,7 @@ func validateRateLimitZoneSize(zoneSize string, fieldPath *field.Path) field.Err
 	return allErrs
 }
 
-var rateLimitKeySpecialVariables = []string{"arg_", "http_", "cookie_"}
+var rateLimitKeySpecialVariables = []string{"arg_", "http_", "cookie_", "jwt_claim_"}
 
 // rateLimitKeyVariables includes NGINX variables allowed to be used in a rateLimit policy key.
 var rateLimitKeyVariables = map[string]bool{
@@ -472,6 +1383,132 @@ func validateRateLimitKey(key string, fieldPath *field.Path, isPlus bool) field.
 	return allErrs
 }
 
+// maxRateLimitTiersZoneSize is the maximum total size of the zones of the tiers of a rateLimit policy in bytes.
+const maxRateLimitTiersZoneSize = 1024 * 1024 * 1024
+
+func validateRateLimitTiers(tiers []v1.RateLimitTier, defaultZoneSize string, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	names := sets.NewString()
+	hasDefault := false
+	var totalZoneSize int64
+
+	for i, t := range tiers {
+		idxPath := fieldPath.Index(i)
+
+		if t.Name == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), ""))
+		} else {
+			for _, msg := range validation.IsDNS1123Label(t.Name) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("name"), t.Name, msg))
+			}
+			if names.Has(t.Name) {
+				allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), t.Name))
+			}
+			names.Insert(t.Name)
+		}
+
+		if t.Condition == nil {
+			if hasDefault {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("condition"), "",
+					"only one tier without a condition is allowed"))
+			}
+			hasDefault = true
+		} else {
+			allErrs = append(allErrs, validateRateLimitCondition(t.Condition, idxPath.Child("condition"), isPlus)...)
+		}
+
+		allErrs = append(allErrs, validateRate(t.Rate, idxPath.Child("rate"))...)
+
+		if t.Burst != nil {
+			allErrs = append(allErrs, validatePositiveInt(*t.Burst, idxPath.Child("burst"))...)
+		}
+
+		zoneSize := defaultZoneSize
+		if t.ZoneSize != "" {
+			allErrs = append(allErrs, validateRateLimitZoneSize(t.ZoneSize, idxPath.Child("zoneSize"))...)
+			zoneSize = t.ZoneSize
+		}
+		if size, err := configs.ParseSizeInBytes(zoneSize); err == nil {
+			totalZoneSize += size
+		}
+	}
+
+	if totalZoneSize > maxRateLimitTiersZoneSize {
+		allErrs = append(allErrs, field.Invalid(fieldPath, "", "the total size of the zones of the tiers must not exceed 1024m"))
+	}
+
+	return allErrs
+}
+
+var rateLimitConditionSpecialVariables = []string{"arg_", "http_", "cookie_", "jwt_claim_", "jwt_header_"}
+
+// rateLimitConditionVariables includes NGINX variables allowed to be used in the variable of a condition of a rateLimit tier.
+var rateLimitConditionVariables = map[string]bool{
+	"host":           true,
+	"request_method": true,
+	"scheme":         true,
+}
+
+const (
+	rateLimitConditionValueFmt    = `[^"$\\~][^"$\\]*`
+	rateLimitConditionValueErrMsg = "must not start with '~' and must not contain '\"', '$' or '\\'"
+)
+
+var rateLimitConditionValueRegexp = regexp.MustCompile("^" + rateLimitConditionValueFmt + "$")
+
+// mapReservedValues are the values that have a special meaning in the NGINX map directive.
+var mapReservedValues = map[string]bool{
+	"default":   true,
+	"hostnames": true,
+	"include":   true,
+	"volatile":  true,
+}
+
+func validateRateLimitCondition(condition *v1.RateLimitCondition, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	fieldCount := 0
+
+	if condition.JWTClaim != "" {
+		allErrs = append(allErrs, validateSpecialVariable("jwt_claim_"+condition.JWTClaim, fieldPath.Child("jwtClaim"), isPlus)...)
+		fieldCount++
+	}
+
+	if condition.Header != "" {
+		for _, msg := range validation.IsHTTPHeaderName(condition.Header) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("header"), condition.Header, msg))
+		}
+		fieldCount++
+	}
+
+	if condition.Variable != "" {
+		variablePath := fieldPath.Child("variable")
+		if err := ValidateEscapedString(condition.Variable, "${cookie_plan}", "${host}:${http_x_plan}"); err != nil {
+			allErrs = append(allErrs, field.Invalid(variablePath, condition.Variable, err.Error()))
+		}
+		allErrs = append(allErrs, validateStringWithVariables(condition.Variable, variablePath,
+			rateLimitConditionSpecialVariables, rateLimitConditionVariables, isPlus)...)
+		fieldCount++
+	}
+
+	if fieldCount != 1 {
+		allErrs = append(allErrs, field.Invalid(fieldPath, "", "must specify exactly one of: `jwtClaim`, `header`, `variable`"))
+	}
+
+	if condition.Value == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("value"), ""))
+	} else if !rateLimitConditionValueRegexp.MatchString(condition.Value) {
+		msg := validation.RegexError(rateLimitConditionValueErrMsg, rateLimitConditionValueFmt, "gold", "premium-plan")
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("value"), condition.Value, msg))
+	} else if mapReservedValues[condition.Value] {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("value"), condition.Value,
+			fmt.Sprintf("must not be one of: %s", mapToPrettyString(mapReservedValues))))
+	}
+
+	return allErrs
+}
+
 var jwtTokenSpecialVariables = []string{"arg_", "http_", "cookie_"}
 
 func validateJWTToken(token string, fieldPath *field.Path) field.ErrorList {
@@ -14,6 +14,9 @@ func TestValidatePolicy(t *testing.T) {
 		isPlus           bool
 		enableOIDC       bool
 		enableAppProtect bool
+		enableGeoIP2     bool
+		enableBrotli     bool
+		enableAPIKeyAuth bool
 		msg              string
 	}{
 		{
@@ -73,9 +76,77 @@ func TestValidatePolicy(t *testing.T) {
 			enableAppProtect: true,
 			msg:              "use WAF(plus only) policy",
 		},
+		{
+			policy: &v1.Policy{
+				Spec: v1.PolicySpec{
+					GeoAccess: &v1.GeoAccess{
+						CountryDatabase: "/etc/nginx/geoip/GeoLite2-Country.mmdb",
+						Allow: &v1.GeoRules{
+							Countries: []string{"US"},
+						},
+					},
+				},
+			},
+			enableGeoIP2: true,
+			msg:          "use geoAccess policy",
+		},
+		{
+			policy: &v1.Policy{
+				Spec: v1.PolicySpec{
+					Compression: &v1.Compression{
+						Algorithms: []string{"gzip", "brotli"},
+					},
+				},
+			},
+			enableBrotli: true,
+			msg:          "use compression policy with brotli",
+		},
+		{
+			policy: &v1.Policy{
+				Spec: v1.PolicySpec{
+					APIKey: &v1.APIKey{
+						SuppliedIn: &v1.SuppliedIn{
+							Header: []string{"X-API-Key"},
+						},
+						ClientSecret: "api-key-secret",
+					},
+				},
+			},
+			enableAPIKeyAuth: true,
+			msg:              "use apiKey policy",
+		},
+		{
+			policy: &v1.Policy{
+				Spec: v1.PolicySpec{
+					ConnectionLimit: &v1.ConnectionLimit{
+						Key:            "${binary_remote_addr}",
+						MaxConnections: 10,
+						ZoneSize:       "10M",
+					},
+				},
+			},
+			msg: "use connectionLimit policy",
+		},
+		{
+			policy: &v1.Policy{
+				Spec: v1.PolicySpec{
+					Bandwidth: &v1.Bandwidth{
+						DownloadRate: "1m",
+					},
+				},
+			},
+			msg: "use bandwidth policy",
+		},
 	}
 	for _, test := range tests {
-		err := ValidatePolicy(test.policy, test.isPlus, test.enableOIDC, test.enableAppProtect)
+		err := ValidatePolicy(test.policy, PolicyValidationOptions{
+			IsPlus:           test.isPlus,
+			EnableOIDC:       test.enableOIDC,
+			EnableAppProtect: test.enableAppProtect,
+			EnableGeoIP2:     test.enableGeoIP2,
+			EnableBrotli:     test.enableBrotli,
+			EnableAPIKeyAuth: test.enableAPIKeyAuth,
+		})
 		if err != nil {
 			t.Errorf("ValidatePolicy() returned error %v for valid input for the case of %v", err, test.msg)
 		}
@@ -89,6 +160,9 @@ func TestValidatePolicyFails(t *testing.T) {
 		isPlus           bool
 		enableOIDC       bool
 		enableAppProtect bool
+		enableGeoIP2     bool
+		enableBrotli     bool
+		enableAPIKeyAuth bool
 		msg              string
 	}{
 		{
@@ -210,9 +284,55 @@ func TestValidatePolicyFails(t *testing.T) {
 			enableOIDC: true,
 			msg:        "OIDC policy with invalid ZoneSyncLeeway",
 		},
+		{
+			policy: &v1.Policy{
+				Spec: v1.PolicySpec{
+					GeoAccess: &v1.GeoAccess{
+						CountryDatabase: "/etc/nginx/geoip/GeoLite2-Country.mmdb",
+						Allow: &v1.GeoRules{
+							Countries: []string{"US"},
+						},
+					},
+				},
+			},
+			enableGeoIP2: false,
+			msg:          "geoAccess policy with GeoIP2 not enabled",
+		},
+		{
+			policy: &v1.Policy{
+				Spec: v1.PolicySpec{
+					Compression: &v1.Compression{
+						Algorithms: []string{"brotli"},
+					},
+				},
+			},
+			enableBrotli: false,
+			msg:          "compression policy with brotli not enabled",
+		},
+		{
+			policy: &v1.Policy{
+				Spec: v1.PolicySpec{
+					APIKey: &v1.APIKey{
+						SuppliedIn: &v1.SuppliedIn{
+							Header: []string{"X-API-Key"},
+						},
+						ClientSecret: "api-key-secret",
+					},
+				},
+			},
+			enableAPIKeyAuth: false,
+			msg:              "apiKey policy with API key authentication not enabled",
+		},
 	}
 	for _, test := range tests {
-		err := ValidatePolicy(test.policy, test.isPlus, test.enableOIDC, test.enableAppProtect)
+		err := ValidatePolicy(test.policy, PolicyValidationOptions{
+			IsPlus:           test.isPlus,
+			EnableOIDC:       test.enableOIDC,
+			EnableAppProtect: test.enableAppProtect,
+			EnableGeoIP2:     test.enableGeoIP2,
+			EnableBrotli:     test.enableBrotli,
+			EnableAPIKeyAuth: test.enableAPIKeyAuth,
+		})
 		if err == nil {
 			t.Errorf("ValidatePolicy() returned no error for invalid input")
 		}
@@ -317,6 +437,37 @@ func TestValidateRateLimit(t *testing.T) {
 			},
 			msg: "ratelimit all fields set",
 		},
+		{
+			rateLimit: &v1.RateLimit{
+				Key:      "${binary_remote_addr}",
+				ZoneSize: "10M",
+				Tiers: []v1.RateLimitTier{
+					{
+						Name: "gold",
+						Condition: &v1.RateLimitCondition{
+							Header: "X-Plan",
+							Value:  "gold",
+						},
+						Rate:     "100r/s",
+						Burst:    createPointerFromInt(50),
+						ZoneSize: "20M",
+					},
+					{
+						Name: "silver",
+						Condition: &v1.RateLimitCondition{
+							Variable: "${cookie_plan}",
+							Value:    "silver",
+						},
+						Rate: "10r/s",
+					},
+					{
+						Name: "free",
+						Rate: "1r/s",
+					},
+				},
+			},
+			msg: "ratelimit with tiers",
+		},
 	}
 
 	isPlus := false
@@ -387,6 +538,99 @@ func TestValidateRateLimitFails(t *testing.T) {
 			}),
 			msg: "invalid rateLimit logLevel",
 		},
+		{
+			rateLimit: createInvalidRateLimit(func(r *v1.RateLimit) {
+				r.Tiers = []v1.RateLimitTier{
+					{
+						Name: "free",
+						Rate: "1r/s",
+					},
+				}
+			}),
+			msg: "rateLimit rate with tiers",
+		},
+		{
+			rateLimit: createInvalidRateLimit(func(r *v1.RateLimit) {
+				r.Rate = ""
+				r.Tiers = []v1.RateLimitTier{
+					{
+						Name: "free",
+						Rate: "1r/s",
+					},
+					{
+						Name: "free",
+						Condition: &v1.RateLimitCondition{
+							Header: "X-Plan",
+							Value:  "free",
+						},
+						Rate: "1r/s",
+					},
+				}
+			}),
+			msg: "duplicate rateLimit tier name",
+		},
+		{
+			rateLimit: createInvalidRateLimit(func(r *v1.RateLimit) {
+				r.Rate = ""
+				r.Tiers = []v1.RateLimitTier{
+					{
+						Name: "free",
+						Rate: "1r/s",
+					},
+					{
+						Name: "basic",
+						Rate: "2r/s",
+					},
+				}
+			}),
+			msg: "multiple rateLimit tiers without a condition",
+		},
+		{
+			rateLimit: createInvalidRateLimit(func(r *v1.RateLimit) {
+				r.Rate = ""
+				r.Tiers = []v1.RateLimitTier{
+					{
+						Name: "free",
+					},
+				}
+			}),
+			msg: "missing rateLimit tier rate",
+		},
+		{
+			rateLimit: createInvalidRateLimit(func(r *v1.RateLimit) {
+				r.Rate = ""
+				r.Tiers = []v1.RateLimitTier{
+					{
+						Name:     "free",
+						Rate:     "1r/s",
+						ZoneSize: "31k",
+					},
+				}
+			}),
+			msg: "invalid rateLimit tier zoneSize",
+		},
+		{
+			rateLimit: createInvalidRateLimit(func(r *v1.RateLimit) {
+				r.Rate = ""
+				r.Tiers = []v1.RateLimitTier{
+					{
+						Name: "gold",
+						Condition: &v1.RateLimitCondition{
+							Header: "X-Plan",
+							Value:  "gold",
+						},
+						Rate:     "100r/s",
+						ZoneSize: "600m",
+					},
+					{
+						Name:     "free",
+						Rate:     "1r/s",
+						ZoneSize: "600m",
+					},
+				}
+			}),
+			msg: "too big total size of the zones of the rateLimit tiers",
+		},
 	}
 
 	isPlus := false
@@ -420,6 +664,21 @@ func TestValidateJWT(t *testing.T) {
 			},
 			msg: "jwt with token",
 		},
+		{
+			jwt: &v1.JWTAuth{
+				Realm:   "My Product API",
+				JwksURI: "https://idp.example.com/.well-known/jwks.json",
+			},
+			msg: "jwt with jwksURI",
+		},
+		{
+			jwt: &v1.JWTAuth{
+				Realm:    "My Product API",
+				JwksURI:  "https://idp.example.com:8443/keys",
+				KeyCache: "1h",
+			},
+			msg: "jwt with jwksURI and keyCache",
+		},
 	}
 	for _, test := range tests {
 		allErrs := validateJWT(test.jwt, field.NewPath("jwt"))
@@ -483,6 +742,37 @@ func TestValidateJWTFails(t *testing.T) {
 			},
 			msg: "invalid variable use in realm without curly braces",
 		},
+		{
+			jwt: &v1.JWTAuth{
+				Realm:   "My Product API",
+				Secret:  "my-jwk",
+				JwksURI: "https://idp.example.com/keys",
+			},
+			msg: "both secret and jwksURI",
+		},
+		{
+			jwt: &v1.JWTAuth{
+				Realm:   "My Product API",
+				JwksURI: "idp.example.com/keys",
+			},
+			msg: "jwksURI without scheme",
+		},
+		{
+			jwt: &v1.JWTAuth{
+				Realm:    "My Product API",
+				JwksURI:  "https://idp.example.com/keys",
+				KeyCache: "1 hour",
+			},
+			msg: "invalid keyCache",
+		},
+		{
+			jwt: &v1.JWTAuth{
+				Realm:    "My Product API",
+				Secret:   "my-jwk",
+				KeyCache: "1h",
+			},
+			msg: "keyCache with secret",
+		},
 	}
 	for _, test := range tests {
 		allErrs := validateJWT(test.jwt, field.NewPath("jwt"))
@@ -1178,3 +1468,1465 @@ func TestValidateWAFInvalid(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateCORS(t *testing.T) {
+	t.Parallel()
+	maxAge := 3600
+	allowCredentials := true
+
+	tests := []struct {
+		cors *v1.CORS
+		msg  string
+	}{
+		{
+			cors: &v1.CORS{
+				AllowOrigin: []string{"*"},
+			},
+			msg: "any origin",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigin:      []string{"https://example.com", "https://*.example.com", "http://localhost:8080", `~^https://app-[0-9]+\.example\.com$`},
+				AllowMethods:     []string{"GET", "POST", "OPTIONS"},
+				AllowHeaders:     []string{"Content-Type", "Authorization"},
+				ExposeHeaders:    []string{"X-Request-Id"},
+				AllowCredentials: &allowCredentials,
+				MaxAge:           &maxAge,
+			},
+			msg: "all fields",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigin:   []string{"*"},
+				AllowHeaders:  []string{"*"},
+				ExposeHeaders: []string{"*"},
+			},
+			msg: "wildcard headers",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateCORS(test.cors, field.NewPath("cors"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateCORS() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateCORSInvalid(t *testing.T) {
+	t.Parallel()
+	maxAge := -1
+	allowCredentials := true
+
+	tests := []struct {
+		cors *v1.CORS
+		msg  string
+	}{
+		{
+			cors: &v1.CORS{},
+			msg:  "missing allowOrigin",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigin: []string{"example.com"},
+			},
+			msg: "origin without scheme",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigin: []string{"https://example.com/path"},
+			},
+			msg: "origin with path",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigin: []string{"ftp://example.com"},
+			},
+			msg: "origin with invalid scheme",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigin: []string{"https://exa_mple.com"},
+			},
+			msg: "origin with invalid host",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigin: []string{"~^https://(example.com"},
+			},
+			msg: "invalid regex origin",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigin: []string{`~^https://"example.com`},
+			},
+			msg: "regex origin with unescaped quote",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigin:      []string{"*"},
+				AllowCredentials: &allowCredentials,
+			},
+			msg: "any origin with credentials",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigin:  []string{"https://example.com"},
+				AllowMethods: []string{"get"},
+			},
+			msg: "invalid method",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigin:  []string{"https://example.com"},
+				AllowHeaders: []string{"Content Type"},
+			},
+			msg: "invalid allow header",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigin:      []string{"https://example.com"},
+				ExposeHeaders:    []string{"*"},
+				AllowCredentials: &allowCredentials,
+			},
+			msg: "wildcard expose header with credentials",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigin: []string{"https://example.com"},
+				MaxAge:      &maxAge,
+			},
+			msg: "negative max age",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateCORS(test.cors, field.NewPath("cors"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateCORS() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
+
+func TestValidateExternalAuth(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		extAuth *v1.ExternalAuth
+		msg     string
+	}{
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService:     "auth-svc",
+				AuthServicePort: 80,
+			},
+			msg: "required fields",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService:     "oauth2-proxy",
+				AuthServicePort: 4180,
+				AuthPath:        "/oauth2/auth",
+				ForwardHeaders:  []string{"Authorization", "Cookie"},
+				ResponseHeaders: []string{"X-Auth-Request-User", "X-Auth-Request-Email"},
+				Cache: &v1.ExternalAuthCache{
+					ZoneSize: "10m",
+					Key:      "${http_authorization}${cookie_session}${request_method}${request_uri}",
+					Valid:    "1m",
+				},
+			},
+			msg: "all fields",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateExternalAuth(test.extAuth, field.NewPath("externalAuth"), false)
+		if len(allErrs) != 0 {
+			t.Errorf("validateExternalAuth() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateExternalAuthInvalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		extAuth *v1.ExternalAuth
+		msg     string
+	}{
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthServicePort: 80,
+			},
+			msg: "missing authService",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService: "auth-svc",
+			},
+			msg: "missing authServicePort",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService:     "auth-svc",
+				AuthServicePort: 80,
+				AuthPath:        "auth",
+			},
+			msg: "authPath without leading slash",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService:     "auth-svc",
+				AuthServicePort: 80,
+				AuthPath:        "/auth/$uri",
+			},
+			msg: "authPath with variable",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService:     "auth-svc",
+				AuthServicePort: 80,
+				ForwardHeaders:  []string{"Bad Header"},
+			},
+			msg: "invalid forward header",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService:     "auth-svc",
+				AuthServicePort: 80,
+				ResponseHeaders: []string{"X-User;"},
+			},
+			msg: "invalid response header",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService:     "auth-svc",
+				AuthServicePort: 80,
+				Cache:           &v1.ExternalAuthCache{},
+			},
+			msg: "cache without valid",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService:     "auth-svc",
+				AuthServicePort: 80,
+				Cache: &v1.ExternalAuthCache{
+					Key:   "${request_body}${request_method}${request_uri}",
+					Valid: "1m",
+				},
+			},
+			msg: "cache key with invalid variable",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService:     "auth-svc",
+				AuthServicePort: 80,
+				Cache: &v1.ExternalAuthCache{
+					Key:   "${http_authorization}${request_uri}",
+					Valid: "1m",
+				},
+			},
+			msg: "cache key without request method",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService:     "auth-svc",
+				AuthServicePort: 80,
+				Cache: &v1.ExternalAuthCache{
+					Key:   "${http_authorization}${request_method}",
+					Valid: "1m",
+				},
+			},
+			msg: "cache key without request uri",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService:     "auth-svc",
+				AuthServicePort: 80,
+				Cache: &v1.ExternalAuthCache{
+					ZoneSize: "1 megabyte",
+					Valid:    "1m",
+				},
+			},
+			msg: "invalid cache zoneSize",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService:     "auth-svc",
+				AuthServicePort: 80,
+				Cache: &v1.ExternalAuthCache{
+					Valid: "1 minute",
+				},
+			},
+			msg: "invalid cache valid",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateExternalAuth(test.extAuth, field.NewPath("externalAuth"), false)
+		if len(allErrs) == 0 {
+			t.Errorf("validateExternalAuth() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		apiKey *v1.APIKey
+		msg    string
+	}{
+		{
+			apiKey: &v1.APIKey{
+				SuppliedIn: &v1.SuppliedIn{
+					Header: []string{"X-API-Key"},
+				},
+				ClientSecret: "api-key-secret",
+			},
+			msg: "header",
+		},
+		{
+			apiKey: &v1.APIKey{
+				SuppliedIn: &v1.SuppliedIn{
+					Header: []string{"X-API-Key", "API-Key"},
+					Query:  []string{"apikey", "api_key"},
+				},
+				ClientSecret: "api-key-secret",
+			},
+			msg: "headers and query parameters",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateAPIKey(test.apiKey, field.NewPath("apiKey"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateAPIKey() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateAPIKeyInvalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		apiKey *v1.APIKey
+		msg    string
+	}{
+		{
+			apiKey: &v1.APIKey{
+				ClientSecret: "api-key-secret",
+			},
+			msg: "missing suppliedIn",
+		},
+		{
+			apiKey: &v1.APIKey{
+				SuppliedIn:   &v1.SuppliedIn{},
+				ClientSecret: "api-key-secret",
+			},
+			msg: "empty suppliedIn",
+		},
+		{
+			apiKey: &v1.APIKey{
+				SuppliedIn: &v1.SuppliedIn{
+					Header: []string{"X-API-Key"},
+				},
+			},
+			msg: "missing clientSecret",
+		},
+		{
+			apiKey: &v1.APIKey{
+				SuppliedIn: &v1.SuppliedIn{
+					Header: []string{"X-API-Key"},
+				},
+				ClientSecret: "api_key_secret",
+			},
+			msg: "invalid clientSecret",
+		},
+		{
+			apiKey: &v1.APIKey{
+				SuppliedIn: &v1.SuppliedIn{
+					Header: []string{"X API Key"},
+				},
+				ClientSecret: "api-key-secret",
+			},
+			msg: "invalid header",
+		},
+		{
+			apiKey: &v1.APIKey{
+				SuppliedIn: &v1.SuppliedIn{
+					Query: []string{"api,key"},
+				},
+				ClientSecret: "api-key-secret",
+			},
+			msg: "invalid query parameter",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateAPIKey(test.apiKey, field.NewPath("apiKey"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateAPIKey() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
+
+func TestValidateCache(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		cache  *v1.Cache
+		isPlus bool
+		msg    string
+	}{
+		{
+			cache: &v1.Cache{
+				ZoneSize: "10m",
+			},
+			isPlus: false,
+			msg:    "zone size only",
+		},
+		{
+			cache: &v1.Cache{
+				ZoneSize: "10m",
+				MaxSize:  "1g",
+				Valid: []v1.CacheValid{
+					{
+						Codes: []int{200, 301},
+						Time:  "10m",
+					},
+					{
+						Time: "1m",
+					},
+				},
+				Key:     "${scheme}${host}${request_uri}${cookie_user}",
+				Bypass:  []string{"${cookie_nocache}", "${arg_nocache}"},
+				NoCache: []string{"${http_pragma}"},
+				Methods: []string{"GET", "HEAD", "POST"},
+			},
+			isPlus: false,
+			msg:    "all fields without purge",
+		},
+		{
+			cache: &v1.Cache{
+				ZoneSize: "10m",
+				Purge: &v1.CachePurge{
+					Allow: []string{"127.0.0.1", "10.0.0.0/8"},
+				},
+			},
+			isPlus: true,
+			msg:    "purge in NGINX Plus",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateCache(test.cache, field.NewPath("cache"), test.isPlus)
+		if len(allErrs) != 0 {
+			t.Errorf("validateCache() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateCacheInvalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		cache  *v1.Cache
+		isPlus bool
+		msg    string
+	}{
+		{
+			cache:  &v1.Cache{},
+			isPlus: false,
+			msg:    "missing zoneSize",
+		},
+		{
+			cache: &v1.Cache{
+				ZoneSize: "10 MB",
+			},
+			isPlus: false,
+			msg:    "invalid zoneSize",
+		},
+		{
+			cache: &v1.Cache{
+				ZoneSize: "10m",
+				MaxSize:  "1t",
+			},
+			isPlus: false,
+			msg:    "invalid maxSize",
+		},
+		{
+			cache: &v1.Cache{
+				ZoneSize: "10m",
+				Valid: []v1.CacheValid{
+					{
+						Codes: []int{200},
+					},
+				},
+			},
+			isPlus: false,
+			msg:    "missing valid time",
+		},
+		{
+			cache: &v1.Cache{
+				ZoneSize: "10m",
+				Valid: []v1.CacheValid{
+					{
+						Codes: []int{99},
+						Time:  "10m",
+					},
+				},
+			},
+			isPlus: false,
+			msg:    "invalid valid code",
+		},
+		{
+			cache: &v1.Cache{
+				ZoneSize: "10m",
+				Key:      "${request_body}",
+			},
+			isPlus: false,
+			msg:    "invalid variable in key",
+		},
+		{
+			cache: &v1.Cache{
+				ZoneSize: "10m",
+				Bypass:   []string{"$cookie_nocache"},
+			},
+			isPlus: false,
+			msg:    "variable without curly braces in bypass",
+		},
+		{
+			cache: &v1.Cache{
+				ZoneSize: "10m",
+				NoCache:  []string{"${remote_addr}"},
+			},
+			isPlus: false,
+			msg:    "invalid variable in noCache",
+		},
+		{
+			cache: &v1.Cache{
+				ZoneSize: "10m",
+				Methods:  []string{"PUT"},
+			},
+			isPlus: false,
+			msg:    "invalid method",
+		},
+		{
+			cache: &v1.Cache{
+				ZoneSize: "10m",
+				Purge: &v1.CachePurge{
+					Allow: []string{"127.0.0.1"},
+				},
+			},
+			isPlus: false,
+			msg:    "purge in NGINX",
+		},
+		{
+			cache: &v1.Cache{
+				ZoneSize: "10m",
+				Purge:    &v1.CachePurge{},
+			},
+			isPlus: true,
+			msg:    "missing purge allow",
+		},
+		{
+			cache: &v1.Cache{
+				ZoneSize: "10m",
+				Purge: &v1.CachePurge{
+					Allow: []string{"localhost"},
+				},
+			},
+			isPlus: true,
+			msg:    "invalid purge allow",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateCache(test.cache, field.NewPath("cache"), test.isPlus)
+		if len(allErrs) == 0 {
+			t.Errorf("validateCache() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
+
+func TestValidateResilience(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		resilience *v1.Resilience
+		isPlus     bool
+		msg        string
+	}{
+		{
+			resilience: &v1.Resilience{
+				Retry: &v1.Retry{},
+			},
+			isPlus: false,
+			msg:    "empty retry",
+		},
+		{
+			resilience: &v1.Resilience{
+				Retry: &v1.Retry{
+					Conditions:    []string{"error", "timeout", "http_503"},
+					NonIdempotent: true,
+					MaxAttempts:   createPointerFromInt(3),
+					Timeout:       "10s",
+					PerTryTimeout: "2s",
+				},
+				CircuitBreaker: &v1.CircuitBreaker{
+					MaxFails:    createPointerFromInt(5),
+					FailTimeout: "30s",
+				},
+			},
+			isPlus: false,
+			msg:    "retry and circuit breaker",
+		},
+		{
+			resilience: &v1.Resilience{
+				CircuitBreaker: &v1.CircuitBreaker{
+					MaxFails:  createPointerFromInt(0),
+					SlowStart: "1m",
+				},
+			},
+			isPlus: true,
+			msg:    "circuit breaker with slow start in NGINX Plus",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateResilience(test.resilience, field.NewPath("resilience"), test.isPlus)
+		if len(allErrs) != 0 {
+			t.Errorf("validateResilience() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateResilienceInvalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		resilience *v1.Resilience
+		isPlus     bool
+		msg        string
+	}{
+		{
+			resilience: &v1.Resilience{},
+			isPlus:     false,
+			msg:        "missing retry and circuit breaker",
+		},
+		{
+			resilience: &v1.Resilience{
+				Retry: &v1.Retry{
+					Conditions: []string{"non_idempotent"},
+				},
+			},
+			isPlus: false,
+			msg:    "invalid condition",
+		},
+		{
+			resilience: &v1.Resilience{
+				Retry: &v1.Retry{
+					Conditions: []string{"error", "error"},
+				},
+			},
+			isPlus: false,
+			msg:    "duplicate condition",
+		},
+		{
+			resilience: &v1.Resilience{
+				Retry: &v1.Retry{
+					MaxAttempts: createPointerFromInt(-1),
+				},
+			},
+			isPlus: false,
+			msg:    "negative maxAttempts",
+		},
+		{
+			resilience: &v1.Resilience{
+				Retry: &v1.Retry{
+					PerTryTimeout: "2 seconds",
+				},
+			},
+			isPlus: false,
+			msg:    "invalid perTryTimeout",
+		},
+		{
+			resilience: &v1.Resilience{
+				CircuitBreaker: &v1.CircuitBreaker{
+					FailTimeout: "-1s",
+				},
+			},
+			isPlus: false,
+			msg:    "invalid failTimeout",
+		},
+		{
+			resilience: &v1.Resilience{
+				CircuitBreaker: &v1.CircuitBreaker{
+					SlowStart: "1m",
+				},
+			},
+			isPlus: false,
+			msg:    "slow start in NGINX",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateResilience(test.resilience, field.NewPath("resilience"), test.isPlus)
+		if len(allErrs) == 0 {
+			t.Errorf("validateResilience() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
+
+func TestValidateGeoAccess(t *testing.T) {
+	t.Parallel()
+	dryRun := true
+
+	tests := []struct {
+		geoAccess *v1.GeoAccess
+		msg       string
+	}{
+		{
+			geoAccess: &v1.GeoAccess{
+				CountryDatabase: "/etc/nginx/geoip/GeoLite2-Country.mmdb",
+				Allow: &v1.GeoRules{
+					Countries: []string{"US", "CA"},
+				},
+			},
+			msg: "allow countries",
+		},
+		{
+			geoAccess: &v1.GeoAccess{
+				ASNDatabase: "/etc/nginx/geoip/GeoLite2-ASN.mmdb",
+				Deny: &v1.GeoRules{
+					ASNs: []int{64496},
+				},
+				RejectCode: createPointerFromInt(451),
+			},
+			msg: "deny asns with reject code",
+		},
+		{
+			geoAccess: &v1.GeoAccess{
+				CountryDatabase: "/etc/nginx/geoip/GeoLite2-Country.mmdb",
+				ASNDatabase:     "/etc/nginx/geoip/GeoLite2-ASN.mmdb",
+				Deny: &v1.GeoRules{
+					Countries: []string{"RU"},
+					ASNs:      []int{64496, 64497},
+				},
+				DryRun: &dryRun,
+			},
+			msg: "deny countries and asns in dry run",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateGeoAccess(test.geoAccess, field.NewPath("geoAccess"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateGeoAccess() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateGeoAccessInvalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		geoAccess *v1.GeoAccess
+		msg       string
+	}{
+		{
+			geoAccess: &v1.GeoAccess{
+				Allow: &v1.GeoRules{
+					Countries: []string{"US"},
+				},
+			},
+			msg: "no database",
+		},
+		{
+			geoAccess: &v1.GeoAccess{
+				CountryDatabase: "/etc/nginx/geoip/GeoLite2-Country.mmdb",
+			},
+			msg: "no allow or deny",
+		},
+		{
+			geoAccess: &v1.GeoAccess{
+				CountryDatabase: "/etc/nginx/geoip/GeoLite2-Country.mmdb",
+				Allow: &v1.GeoRules{
+					Countries: []string{"US"},
+				},
+				Deny: &v1.GeoRules{
+					Countries: []string{"CA"},
+				},
+			},
+			msg: "both allow and deny",
+		},
+		{
+			geoAccess: &v1.GeoAccess{
+				CountryDatabase: "GeoLite2-Country.mmdb",
+				Allow: &v1.GeoRules{
+					Countries: []string{"US"},
+				},
+			},
+			msg: "relative database path",
+		},
+		{
+			geoAccess: &v1.GeoAccess{
+				CountryDatabase: "/etc/nginx/geoip/country.mmdb; return 200",
+				Allow: &v1.GeoRules{
+					Countries: []string{"US"},
+				},
+			},
+			msg: "invalid database path",
+		},
+		{
+			geoAccess: &v1.GeoAccess{
+				CountryDatabase: "/etc/nginx/geoip/GeoLite2-Country.mmdb",
+				Allow:           &v1.GeoRules{},
+			},
+			msg: "empty allow",
+		},
+		{
+			geoAccess: &v1.GeoAccess{
+				CountryDatabase: "/etc/nginx/geoip/GeoLite2-Country.mmdb",
+				Allow: &v1.GeoRules{
+					Countries: []string{"us"},
+				},
+			},
+			msg: "lower case country",
+		},
+		{
+			geoAccess: &v1.GeoAccess{
+				ASNDatabase: "/etc/nginx/geoip/GeoLite2-ASN.mmdb",
+				Deny: &v1.GeoRules{
+					Countries: []string{"US"},
+				},
+			},
+			msg: "countries without countryDatabase",
+		},
+		{
+			geoAccess: &v1.GeoAccess{
+				CountryDatabase: "/etc/nginx/geoip/GeoLite2-Country.mmdb",
+				Deny: &v1.GeoRules{
+					ASNs: []int{64496},
+				},
+			},
+			msg: "asns without asnDatabase",
+		},
+		{
+			geoAccess: &v1.GeoAccess{
+				ASNDatabase: "/etc/nginx/geoip/GeoLite2-ASN.mmdb",
+				Deny: &v1.GeoRules{
+					ASNs: []int{0},
+				},
+			},
+			msg: "zero asn",
+		},
+		{
+			geoAccess: &v1.GeoAccess{
+				CountryDatabase: "/etc/nginx/geoip/GeoLite2-Country.mmdb",
+				Allow: &v1.GeoRules{
+					Countries: []string{"US"},
+				},
+				RejectCode: createPointerFromInt(200),
+			},
+			msg: "invalid reject code",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateGeoAccess(test.geoAccess, field.NewPath("geoAccess"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateGeoAccess() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
+
+func TestValidateRateLimitCondition(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		condition *v1.RateLimitCondition
+		isPlus    bool
+		msg       string
+	}{
+		{
+			condition: &v1.RateLimitCondition{
+				JWTClaim: "plan",
+				Value:    "gold",
+			},
+			isPlus: true,
+			msg:    "jwt claim",
+		},
+		{
+			condition: &v1.RateLimitCondition{
+				Header: "X-Plan",
+				Value:  "premium plan",
+			},
+			isPlus: false,
+			msg:    "header",
+		},
+		{
+			condition: &v1.RateLimitCondition{
+				Variable: "${host}:${arg_plan}",
+				Value:    "cafe.example.com:gold",
+			},
+			isPlus: false,
+			msg:    "variable",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateRateLimitCondition(test.condition, field.NewPath("condition"), test.isPlus)
+		if len(allErrs) > 0 {
+			t.Errorf("validateRateLimitCondition() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateRateLimitConditionFails(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		condition *v1.RateLimitCondition
+		isPlus    bool
+		msg       string
+	}{
+		{
+			condition: &v1.RateLimitCondition{
+				JWTClaim: "plan",
+				Value:    "gold",
+			},
+			isPlus: false,
+			msg:    "jwt claim in NGINX",
+		},
+		{
+			condition: &v1.RateLimitCondition{
+				Value: "gold",
+			},
+			isPlus: false,
+			msg:    "no source",
+		},
+		{
+			condition: &v1.RateLimitCondition{
+				Header:   "X-Plan",
+				Variable: "${cookie_plan}",
+				Value:    "gold",
+			},
+			isPlus: false,
+			msg:    "header and variable",
+		},
+		{
+			condition: &v1.RateLimitCondition{
+				Header: "X Plan",
+				Value:  "gold",
+			},
+			isPlus: false,
+			msg:    "invalid header",
+		},
+		{
+			condition: &v1.RateLimitCondition{
+				Variable: "$cookie_plan",
+				Value:    "gold",
+			},
+			isPlus: false,
+			msg:    "variable without curly braces",
+		},
+		{
+			condition: &v1.RateLimitCondition{
+				Variable: "${request_uri}",
+				Value:    "gold",
+			},
+			isPlus: false,
+			msg:    "not allowed variable",
+		},
+		{
+			condition: &v1.RateLimitCondition{
+				Header: "X-Plan",
+			},
+			isPlus: false,
+			msg:    "missing value",
+		},
+		{
+			condition: &v1.RateLimitCondition{
+				Header: "X-Plan",
+				Value:  "~^gold",
+			},
+			isPlus: false,
+			msg:    "regex value",
+		},
+		{
+			condition: &v1.RateLimitCondition{
+				Header: "X-Plan",
+				Value:  "${host}",
+			},
+			isPlus: false,
+			msg:    "value with a variable",
+		},
+		{
+			condition: &v1.RateLimitCondition{
+				Header: "X-Plan",
+				Value:  "default",
+			},
+			isPlus: false,
+			msg:    "reserved value",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateRateLimitCondition(test.condition, field.NewPath("condition"), test.isPlus)
+		if len(allErrs) == 0 {
+			t.Errorf("validateRateLimitCondition() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
+
+func TestValidateHeadersPolicy(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		headers *v1.Headers
+		msg     string
+	}{
+		{
+			headers: &v1.Headers{
+				Request: &v1.RequestHeadersTransform{
+					Set: []v1.Header{
+						{Name: "X-Env", Value: "prod"},
+						{Name: "X-Client-Cert", Value: "${ssl_client_escaped_cert}"},
+					},
+					Remove: []string{"X-Debug"},
+					Rename: []v1.HeaderRename{
+						{From: "X-User", To: "X-User-ID"},
+					},
+				},
+			},
+			msg: "request headers",
+		},
+		{
+			headers: &v1.Headers{
+				Response: &v1.ResponseHeadersTransform{
+					Add: []v1.AddHeader{
+						{Header: v1.Header{Name: "X-Served-By", Value: "${server_name}"}, Always: true},
+					},
+					Remove: []string{"Server"},
+					Rename: []v1.HeaderRename{
+						{From: "X-Internal-ID", To: "X-Request-ID"},
+					},
+					RewriteLocation: []v1.HeaderRewrite{
+						{From: "http://tea.internal/", To: "https://cafe.example.com/"},
+					},
+					RewriteCookieDomain: []v1.HeaderRewrite{
+						{From: "tea.internal", To: "cafe.example.com"},
+					},
+				},
+			},
+			msg: "response headers",
+		},
+		{
+			headers: &v1.Headers{
+				SecurityHeaders: &v1.SecurityHeaders{
+					ContentSecurityPolicy: "default-src 'self'",
+					FrameOptions:          "SAMEORIGIN",
+					ReferrerPolicy:        "strict-origin-when-cross-origin",
+				},
+			},
+			msg: "security headers",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateHeadersPolicy(test.headers, field.NewPath("headers"), false)
+		if len(allErrs) > 0 {
+			t.Errorf("validateHeadersPolicy() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateHeadersPolicyInvalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		headers *v1.Headers
+		msg     string
+	}{
+		{
+			headers: &v1.Headers{},
+			msg:     "empty policy",
+		},
+		{
+			headers: &v1.Headers{
+				Request: &v1.RequestHeadersTransform{
+					Set: []v1.Header{
+						{Name: "X Env", Value: "prod"},
+					},
+				},
+			},
+			msg: "invalid request header name",
+		},
+		{
+			headers: &v1.Headers{
+				Request: &v1.RequestHeadersTransform{
+					Set: []v1.Header{
+						{Name: "X-Env", Value: "${hostname}"},
+					},
+				},
+			},
+			msg: "invalid request header variable",
+		},
+		{
+			headers: &v1.Headers{
+				Request: &v1.RequestHeadersTransform{
+					Remove: []string{"Host"},
+				},
+			},
+			msg: "reserved request header",
+		},
+		{
+			headers: &v1.Headers{
+				Request: &v1.RequestHeadersTransform{
+					Set: []v1.Header{
+						{Name: "X-User", Value: "anonymous"},
+					},
+					Rename: []v1.HeaderRename{
+						{From: "x-user", To: "X-User-ID"},
+					},
+				},
+			},
+			msg: "duplicate request header",
+		},
+		{
+			headers: &v1.Headers{
+				Response: &v1.ResponseHeadersTransform{
+					Rename: []v1.HeaderRename{
+						{From: "X-Internal-ID"},
+					},
+				},
+			},
+			msg: "missing rename to",
+		},
+		{
+			headers: &v1.Headers{
+				Response: &v1.ResponseHeadersTransform{
+					RewriteLocation: []v1.HeaderRewrite{
+						{From: "~^http://(.*)$", To: "https://$1"},
+					},
+				},
+			},
+			msg: "regex location rewrite",
+		},
+		{
+			headers: &v1.Headers{
+				Response: &v1.ResponseHeadersTransform{
+					RewriteCookieDomain: []v1.HeaderRewrite{
+						{To: "cafe.example.com"},
+					},
+				},
+			},
+			msg: "missing cookie domain rewrite from",
+		},
+		{
+			headers: &v1.Headers{
+				SecurityHeaders: &v1.SecurityHeaders{},
+			},
+			msg: "empty security headers",
+		},
+		{
+			headers: &v1.Headers{
+				SecurityHeaders: &v1.SecurityHeaders{
+					FrameOptions: "ALLOW-FROM https://example.com",
+				},
+			},
+			msg: "invalid frame options",
+		},
+		{
+			headers: &v1.Headers{
+				SecurityHeaders: &v1.SecurityHeaders{
+					ReferrerPolicy: "never",
+				},
+			},
+			msg: "invalid referrer policy",
+		},
+		{
+			headers: &v1.Headers{
+				SecurityHeaders: &v1.SecurityHeaders{
+					ContentSecurityPolicy: "script-src 'nonce-$request_id'",
+				},
+			},
+			msg: "content security policy with a variable",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateHeadersPolicy(test.headers, field.NewPath("headers"), false)
+		if len(allErrs) == 0 {
+			t.Errorf("validateHeadersPolicy() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
+
+func TestValidateCompression(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		compression *v1.Compression
+		msg         string
+	}{
+		{
+			compression: &v1.Compression{
+				Algorithms: []string{"gzip"},
+			},
+			msg: "gzip only",
+		},
+		{
+			compression: &v1.Compression{
+				Algorithms:  []string{"gzip", "brotli"},
+				GzipLevel:   createPointerFromInt(9),
+				BrotliLevel: createPointerFromInt(0),
+				MinLength:   createPointerFromInt(0),
+				Types:       []string{"application/json", "image/svg+xml", "application/vnd.api+json"},
+				Proxied:     true,
+			},
+			msg: "all fields",
+		},
+		{
+			compression: &v1.Compression{
+				Algorithms: []string{"brotli"},
+				Types:      []string{"*"},
+			},
+			msg: "all types",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateCompression(test.compression, field.NewPath("compression"), true)
+		if len(allErrs) > 0 {
+			t.Errorf("validateCompression() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateCompressionInvalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		compression *v1.Compression
+		msg         string
+	}{
+		{
+			compression: &v1.Compression{},
+			msg:         "no algorithms",
+		},
+		{
+			compression: &v1.Compression{
+				Algorithms: []string{"deflate"},
+			},
+			msg: "unsupported algorithm",
+		},
+		{
+			compression: &v1.Compression{
+				Algorithms: []string{"gzip", "gzip"},
+			},
+			msg: "duplicate algorithm",
+		},
+		{
+			compression: &v1.Compression{
+				Algorithms: []string{"gzip"},
+				GzipLevel:  createPointerFromInt(10),
+			},
+			msg: "gzip level out of range",
+		},
+		{
+			compression: &v1.Compression{
+				Algorithms:  []string{"brotli"},
+				BrotliLevel: createPointerFromInt(12),
+			},
+			msg: "brotli level out of range",
+		},
+		{
+			compression: &v1.Compression{
+				Algorithms:  []string{"gzip"},
+				BrotliLevel: createPointerFromInt(5),
+			},
+			msg: "brotli level without brotli",
+		},
+		{
+			compression: &v1.Compression{
+				Algorithms: []string{"brotli"},
+				GzipLevel:  createPointerFromInt(5),
+			},
+			msg: "gzip level without gzip",
+		},
+		{
+			compression: &v1.Compression{
+				Algorithms: []string{"gzip"},
+				MinLength:  createPointerFromInt(-1),
+			},
+			msg: "negative min length",
+		},
+		{
+			compression: &v1.Compression{
+				Algorithms: []string{"gzip"},
+				Types:      []string{"application/json;charset=utf-8"},
+			},
+			msg: "invalid type",
+		},
+		{
+			compression: &v1.Compression{
+				Algorithms: []string{"gzip"},
+				Types:      []string{"text/html"},
+			},
+			msg: "text/html type",
+		},
+		{
+			compression: &v1.Compression{
+				Algorithms: []string{"gzip"},
+				Types:      []string{"text/css", "text/css"},
+			},
+			msg: "duplicate type",
+		},
+		{
+			compression: &v1.Compression{
+				Algorithms: []string{"gzip"},
+				Types:      []string{"*", "text/css"},
+			},
+			msg: "all types with other types",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateCompression(test.compression, field.NewPath("compression"), true)
+		if len(allErrs) == 0 {
+			t.Errorf("validateCompression() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
+
+func TestValidateConnectionLimit(t *testing.T) {
+	t.Parallel()
+	dryRun := true
+	tests := []struct {
+		connectionLimit *v1.ConnectionLimit
+		msg             string
+	}{
+		{
+			connectionLimit: &v1.ConnectionLimit{
+				Key:            "${binary_remote_addr}",
+				MaxConnections: 10,
+				ZoneSize:       "10M",
+			},
+			msg: "required fields",
+		},
+		{
+			connectionLimit: &v1.ConnectionLimit{
+				Key:            "${server_port}_${remote_addr}",
+				MaxConnections: 1,
+				ZoneSize:       "64k",
+				DryRun:         &dryRun,
+				LogLevel:       "info",
+			},
+			msg: "all fields",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateConnectionLimit(test.connectionLimit, field.NewPath("connectionLimit"), false)
+		if len(allErrs) > 0 {
+			t.Errorf("validateConnectionLimit() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateConnectionLimitInvalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		connectionLimit *v1.ConnectionLimit
+		msg             string
+	}{
+		{
+			connectionLimit: &v1.ConnectionLimit{
+				MaxConnections: 10,
+				ZoneSize:       "10M",
+			},
+			msg: "missing key",
+		},
+		{
+			connectionLimit: &v1.ConnectionLimit{
+				Key:      "${binary_remote_addr}",
+				ZoneSize: "10M",
+			},
+			msg: "missing maxConnections",
+		},
+		{
+			connectionLimit: &v1.ConnectionLimit{
+				Key:            "${binary_remote_addr}",
+				MaxConnections: 10,
+			},
+			msg: "missing zoneSize",
+		},
+		{
+			connectionLimit: &v1.ConnectionLimit{
+				Key:            "${request_uri}",
+				MaxConnections: 10,
+				ZoneSize:       "10M",
+			},
+			msg: "http variable in key",
+		},
+		{
+			connectionLimit: &v1.ConnectionLimit{
+				Key:            "${binary_remote_addr}",
+				MaxConnections: 10,
+				ZoneSize:       "10M",
+				LogLevel:       "debug",
+			},
+			msg: "invalid logLevel",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateConnectionLimit(test.connectionLimit, field.NewPath("connectionLimit"), false)
+		if len(allErrs) == 0 {
+			t.Errorf("validateConnectionLimit() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
+
+func TestValidateBandwidth(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		bandwidth *v1.Bandwidth
+		msg       string
+	}{
+		{
+			bandwidth: &v1.Bandwidth{
+				UploadRate: "512k",
+			},
+			msg: "upload rate",
+		},
+		{
+			bandwidth: &v1.Bandwidth{
+				UploadRate:   "512k",
+				DownloadRate: "1M",
+			},
+			msg: "upload and download rates",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateBandwidth(test.bandwidth, field.NewPath("bandwidth"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateBandwidth() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateBandwidthInvalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		bandwidth *v1.Bandwidth
+		msg       string
+	}{
+		{
+			bandwidth: &v1.Bandwidth{},
+			msg:       "no rates",
+		},
+		{
+			bandwidth: &v1.Bandwidth{
+				DownloadRate: "1G",
+			},
+			msg: "invalid download rate",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateBandwidth(test.bandwidth, field.NewPath("bandwidth"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateBandwidth() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
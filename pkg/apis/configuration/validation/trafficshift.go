@@ -0,0 +1,82 @@
+package validation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// minTrafficShiftInterval is the minimum interval between the steps of a TrafficShift.
+// Shorter intervals don't leave enough time for the upstream to receive responses to judge the step.
+const minTrafficShiftInterval = 10 * time.Second
+
+// ValidateTrafficShift validates a TrafficShift.
+func ValidateTrafficShift(ts *v1alpha1.TrafficShift) error {
+	allErrs := validateTrafficShiftSpec(&ts.Spec, field.NewPath("spec"))
+	return allErrs.ToAggregate()
+}
+
+func validateTrafficShiftSpec(spec *v1alpha1.TrafficShiftSpec, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spec.VirtualServer == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("virtualServer"), ""))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(spec.VirtualServer) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("virtualServer"), spec.VirtualServer, msg))
+		}
+	}
+
+	if spec.Route == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("route"), ""))
+	}
+
+	allErrs = append(allErrs, validateUpstreamName(spec.Upstream, fieldPath.Child("upstream"))...)
+
+	for _, msg := range validation.IsInRange(spec.StepWeight, 1, 100) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("stepWeight"), spec.StepWeight, msg))
+	}
+
+	allErrs = append(allErrs, validateTrafficShiftInterval(spec.Interval, fieldPath.Child("interval"))...)
+
+	if spec.MaxWeight != nil {
+		for _, msg := range validation.IsInRange(*spec.MaxWeight, 1, 100) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("maxWeight"), *spec.MaxWeight, msg))
+		}
+	}
+
+	for _, msg := range validation.IsInRange(spec.ErrorThreshold, 1, 100) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("errorThreshold"), spec.ErrorThreshold, msg))
+	}
+
+	switch spec.OnFailure {
+	case "", v1alpha1.TrafficShiftOnFailurePause, v1alpha1.TrafficShiftOnFailureRollback:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fieldPath.Child("onFailure"), spec.OnFailure,
+			[]string{v1alpha1.TrafficShiftOnFailurePause, v1alpha1.TrafficShiftOnFailureRollback}))
+	}
+
+	return allErrs
+}
+
+func validateTrafficShiftInterval(interval string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if interval == "" {
+		return append(allErrs, field.Required(fieldPath, ""))
+	}
+
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		return append(allErrs, field.Invalid(fieldPath, interval, "must be a duration like 30s or 5m"))
+	}
+
+	if d < minTrafficShiftInterval {
+		return append(allErrs, field.Invalid(fieldPath, interval, fmt.Sprintf("must be at least %v", minTrafficShiftInterval)))
+	}
+
+	return allErrs
+}
@@ -0,0 +1,148 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+)
+
+func createTrafficShiftSpec() v1alpha1.TrafficShiftSpec {
+	return v1alpha1.TrafficShiftSpec{
+		VirtualServer:  "cafe",
+		Route:          "/tea",
+		Upstream:       "tea-v2",
+		StepWeight:     10,
+		Interval:       "1m",
+		ErrorThreshold: 5,
+	}
+}
+
+func TestValidateTrafficShift(t *testing.T) {
+	t.Parallel()
+	maxWeight := 50
+
+	tests := []struct {
+		modify func(spec *v1alpha1.TrafficShiftSpec)
+		msg    string
+	}{
+		{
+			modify: func(_ *v1alpha1.TrafficShiftSpec) {},
+			msg:    "required fields",
+		},
+		{
+			modify: func(spec *v1alpha1.TrafficShiftSpec) {
+				spec.MaxWeight = &maxWeight
+				spec.OnFailure = v1alpha1.TrafficShiftOnFailureRollback
+			},
+			msg: "max weight and rollback",
+		},
+		{
+			modify: func(spec *v1alpha1.TrafficShiftSpec) {
+				spec.StepWeight = 100
+				spec.OnFailure = v1alpha1.TrafficShiftOnFailurePause
+			},
+			msg: "single step and pause",
+		},
+	}
+
+	for _, test := range tests {
+		ts := &v1alpha1.TrafficShift{
+			Spec: createTrafficShiftSpec(),
+		}
+		test.modify(&ts.Spec)
+
+		err := ValidateTrafficShift(ts)
+		if err != nil {
+			t.Errorf("ValidateTrafficShift() returned error %v for valid input for the case of %s", err, test.msg)
+		}
+	}
+}
+
+func TestValidateTrafficShiftFails(t *testing.T) {
+	t.Parallel()
+	zero := 0
+
+	tests := []struct {
+		modify func(spec *v1alpha1.TrafficShiftSpec)
+		msg    string
+	}{
+		{
+			modify: func(spec *v1alpha1.TrafficShiftSpec) {
+				spec.VirtualServer = ""
+			},
+			msg: "missing virtualServer",
+		},
+		{
+			modify: func(spec *v1alpha1.TrafficShiftSpec) {
+				spec.Route = ""
+			},
+			msg: "missing route",
+		},
+		{
+			modify: func(spec *v1alpha1.TrafficShiftSpec) {
+				spec.Upstream = "tea_v2"
+			},
+			msg: "invalid upstream",
+		},
+		{
+			modify: func(spec *v1alpha1.TrafficShiftSpec) {
+				spec.StepWeight = 0
+			},
+			msg: "missing stepWeight",
+		},
+		{
+			modify: func(spec *v1alpha1.TrafficShiftSpec) {
+				spec.StepWeight = 101
+			},
+			msg: "too big stepWeight",
+		},
+		{
+			modify: func(spec *v1alpha1.TrafficShiftSpec) {
+				spec.Interval = ""
+			},
+			msg: "missing interval",
+		},
+		{
+			modify: func(spec *v1alpha1.TrafficShiftSpec) {
+				spec.Interval = "1 minute"
+			},
+			msg: "invalid interval",
+		},
+		{
+			modify: func(spec *v1alpha1.TrafficShiftSpec) {
+				spec.Interval = "1s"
+			},
+			msg: "too short interval",
+		},
+		{
+			modify: func(spec *v1alpha1.TrafficShiftSpec) {
+				spec.MaxWeight = &zero
+			},
+			msg: "zero maxWeight",
+		},
+		{
+			modify: func(spec *v1alpha1.TrafficShiftSpec) {
+				spec.ErrorThreshold = 0
+			},
+			msg: "missing errorThreshold",
+		},
+		{
+			modify: func(spec *v1alpha1.TrafficShiftSpec) {
+				spec.OnFailure = "abort"
+			},
+			msg: "invalid onFailure",
+		},
+	}
+
+	for _, test := range tests {
+		ts := &v1alpha1.TrafficShift{
+			Spec: createTrafficShiftSpec(),
+		}
+		test.modify(&ts.Spec)
+
+		err := ValidateTrafficShift(ts)
+		if err == nil {
+			t.Errorf("ValidateTrafficShift() returned no error for invalid input for the case of %s", test.msg)
+		}
+	}
+}
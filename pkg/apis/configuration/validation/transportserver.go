@@ -4,8 +4,10 @@ import (
 	"encoding/hex"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
+	v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
 	"github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -30,21 +32,27 @@ func NewTransportServerValidator(tlsPassthrough bool, snippetsEnabled bool, isPl
 
 // ValidateTransportServer validates a TransportServer.
 func (tsv *TransportServerValidator) ValidateTransportServer(transportServer *v1alpha1.TransportServer) error {
-	allErrs := tsv.validateTransportServerSpec(&transportServer.Spec, field.NewPath("spec"))
+	allErrs := tsv.validateTransportServerSpec(&transportServer.Spec, field.NewPath("spec"), transportServer.Namespace)
 	return allErrs.ToAggregate()
 }
 
-func (tsv *TransportServerValidator) validateTransportServerSpec(spec *v1alpha1.TransportServerSpec, fieldPath *field.Path) field.ErrorList {
+func (tsv *TransportServerValidator) validateTransportServerSpec(spec *v1alpha1.TransportServerSpec, fieldPath *field.Path, namespace string) field.ErrorList {
 	allErrs := field.ErrorList{}
 
 	allErrs = append(allErrs, tsv.validateTransportListener(&spec.Listener, fieldPath.Child("listener"))...)
 
 	isTLSPassthroughListener := isPotentialTLSPassthroughListener(&spec.Listener)
-	allErrs = append(allErrs, validateTransportServerHost(spec.Host, fieldPath.Child("host"), isTLSPassthroughListener)...)
+	allErrs = append(allErrs, validateTransportServerHost(spec.Host, fieldPath.Child("host"), isTLSPassthroughListener, spec.TLS != nil)...)
+
+	allErrs = append(allErrs, validateTransportServerTLS(spec.TLS, fieldPath.Child("tls"), isTLSPassthroughListener, spec.Listener.Protocol)...)
+
+	allErrs = append(allErrs, validateTransportServerPolicies(spec.Policies, fieldPath.Child("policies"), namespace)...)
 
 	upstreamErrs, upstreamNames := validateTransportServerUpstreams(spec.Upstreams, fieldPath.Child("upstreams"), tsv.isPlus)
 	allErrs = append(allErrs, upstreamErrs...)
 
+	allErrs = append(allErrs, validateTransportServerUpstreamsTLS(spec.Upstreams, fieldPath.Child("upstreams"), isTLSPassthroughListener, spec.Listener.Protocol)...)
+
 	allErrs = append(allErrs, validateTransportServerUpstreamParameters(spec.UpstreamParameters, fieldPath.Child("upstreamParameters"), spec.Listener.Protocol)...)
 
 	allErrs = append(allErrs, validateSessionParameters(spec.SessionParameters, fieldPath.Child("sessionParameters"))...)
@@ -71,19 +79,74 @@ func validateSnippets(serverSnippet string, fieldPath *field.Path, snippetsEnabl
 	return allErrs
 }
 
-func validateTransportServerHost(host string, fieldPath *field.Path, isTLSPassthroughListener bool) field.ErrorList {
+func validateTransportServerHost(host string, fieldPath *field.Path, isTLSPassthroughListener bool, isTLSTerminated bool) field.ErrorList {
 	allErrs := field.ErrorList{}
 
 	if !isTLSPassthroughListener {
-		if host != "" {
-			return append(allErrs, field.Forbidden(fieldPath, "host field is allowed only for TLS Passthrough TransportServers"))
+		if host == "" {
+			return allErrs
+		}
+		// TransportServers with TLS termination share a listener and are distinguished by SNI
+		if !isTLSTerminated {
+			return append(allErrs, field.Forbidden(fieldPath, "host field is allowed only for TLS Passthrough TransportServers and TransportServers with TLS termination"))
 		}
-		return allErrs
 	}
 
 	return validateHost(host, fieldPath)
 }
 
+func validateTransportServerTLS(tls *v1alpha1.TransportServerTLS, fieldPath *field.Path, isTLSPassthroughListener bool, protocol string) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if tls == nil {
+		return allErrs
+	}
+
+	if isTLSPassthroughListener {
+		return append(allErrs, field.Forbidden(fieldPath, "tls field is not allowed for TLS Passthrough TransportServers"))
+	}
+
+	if protocol == "UDP" {
+		return append(allErrs, field.Forbidden(fieldPath, "tls field is allowed only for TCP listeners"))
+	}
+
+	if tls.Secret == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("secret"), ""))
+	}
+	allErrs = append(allErrs, validateSecretName(tls.Secret, fieldPath.Child("secret"))...)
+
+	if tls.ClientCertSecret == "" {
+		if tls.VerifyClient != "" {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("verifyClient"), "can only be used with clientCertSecret"))
+		}
+		if tls.VerifyDepth != nil {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("verifyDepth"), "can only be used with clientCertSecret"))
+		}
+		return allErrs
+	}
+
+	allErrs = append(allErrs, validateSecretName(tls.ClientCertSecret, fieldPath.Child("clientCertSecret"))...)
+	allErrs = append(allErrs, validateIngressMTLSVerifyClient(tls.VerifyClient, fieldPath.Child("verifyClient"))...)
+
+	if tls.VerifyDepth != nil {
+		allErrs = append(allErrs, validatePositiveIntOrZero(*tls.VerifyDepth, fieldPath.Child("verifyDepth"))...)
+	}
+
+	return allErrs
+}
+
+func validateTransportServerPolicies(policies []v1alpha1.PolicyReference, fieldPath *field.Path, namespace string) field.ErrorList {
+	refs := make([]v1.PolicyReference, 0, len(policies))
+	for _, p := range policies {
+		refs = append(refs, v1.PolicyReference{
+			Name:      p.Name,
+			Namespace: p.Namespace,
+		})
+	}
+
+	return validatePolicies(refs, fieldPath, namespace)
+}
+
 func (tsv *TransportServerValidator) validateTransportListener(listener *v1alpha1.TransportServerListener, fieldPath *field.Path) field.ErrorList {
 	if isPotentialTLSPassthroughListener(listener) {
 		return tsv.validateTLSPassthroughListener(listener, fieldPath)
@@ -179,11 +242,75 @@ func validateTransportServerUpstreams(upstreams []v1alpha1.Upstream, fieldPath *
 		allErrs = append(allErrs, validateTSUpstreamHealthChecks(u.HealthCheck, idxPath.Child("healthChecks"))...)
 
 		allErrs = append(allErrs, validateLoadBalancingMethod(u.LoadBalancingMethod, idxPath.Child("loadBalancingMethod"), isPlus)...)
+
+		if u.LoadBalancing != nil {
+			if u.LoadBalancingMethod != "" {
+				allErrs = append(allErrs, field.Forbidden(idxPath.Child("loadBalancing"), "cannot be used with loadBalancingMethod"))
+			} else {
+				allErrs = append(allErrs, validateLoadBalancing(u.LoadBalancing, idxPath.Child("loadBalancing"), isPlus)...)
+			}
+		}
 	}
 
 	return allErrs, upstreamNames
 }
 
+func validateTransportServerUpstreamsTLS(upstreams []v1alpha1.Upstream, fieldPath *field.Path, isTLSPassthroughListener bool, protocol string) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, u := range upstreams {
+		if u.TLS == nil {
+			continue
+		}
+
+		tlsPath := fieldPath.Index(i).Child("tls")
+
+		if isTLSPassthroughListener {
+			allErrs = append(allErrs, field.Forbidden(tlsPath, "tls field is not allowed for the upstreams of TLS Passthrough TransportServers"))
+			continue
+		}
+
+		if protocol == "UDP" {
+			allErrs = append(allErrs, field.Forbidden(tlsPath, "tls field is allowed only for the upstreams of TCP listeners"))
+			continue
+		}
+
+		allErrs = append(allErrs, validateUpstreamTLS(u.TLS, tlsPath)...)
+	}
+
+	return allErrs
+}
+
+func validateUpstreamTLS(tls *v1alpha1.UpstreamTLS, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !tls.Enable {
+		if tls.TLSSecret != "" || tls.TrustedCertSecret != "" || tls.VerifyServer || tls.VerifyDepth != nil || tls.ServerName || tls.SSLName != "" {
+			allErrs = append(allErrs, field.Forbidden(fieldPath, "tls fields can only be used when enable is true"))
+		}
+		return allErrs
+	}
+
+	allErrs = append(allErrs, validateSecretName(tls.TLSSecret, fieldPath.Child("tlsSecret"))...)
+
+	if tls.VerifyServer && tls.TrustedCertSecret == "" {
+		return append(allErrs, field.Required(fieldPath.Child("trustedCertSecret"), "must be set when verifyServer is 'true'"))
+	}
+	allErrs = append(allErrs, validateSecretName(tls.TrustedCertSecret, fieldPath.Child("trustedCertSecret"))...)
+
+	if tls.VerifyDepth != nil {
+		if !tls.VerifyServer {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("verifyDepth"), "can only be used when verifyServer is 'true'"))
+		} else {
+			allErrs = append(allErrs, validatePositiveIntOrZero(*tls.VerifyDepth, fieldPath.Child("verifyDepth"))...)
+		}
+	}
+
+	allErrs = append(allErrs, validateSSLName(tls.SSLName, fieldPath.Child("sslName"))...)
+
+	return allErrs
+}
+
 func validateLoadBalancingMethod(method string, fieldPath *field.Path, isPlus bool) field.ErrorList {
 	allErrs := field.ErrorList{}
 	if method == "" {
@@ -257,6 +384,188 @@ func validateHashLoadBalancingMethod(method string, fieldPath *field.Path, isPlu
 	return allErrs
 }
 
+var loadBalancingMethods = map[string]bool{
+	"round_robin": true,
+	"least_conn":  true,
+	"hash":        true,
+	"random":      true,
+}
+
+var plusLoadBalancingMethods = map[string]bool{
+	"round_robin": true,
+	"least_conn":  true,
+	"hash":        true,
+	"random":      true,
+	"least_time":  true,
+}
+
+// hashKeyVariables includes NGINX stream variables allowed to be used in the key of the hash load balancing method.
+var hashKeyVariables = map[string]bool{
+	"binary_remote_addr": true,
+	"remote_addr":        true,
+	"remote_port":        true,
+	"server_addr":        true,
+	"server_port":        true,
+	"ssl_server_name":    true,
+}
+
+var randomLoadBalancingMethods = map[string]bool{
+	"least_conn": true,
+}
+
+var plusRandomLoadBalancingMethods = map[string]bool{
+	"least_conn":            true,
+	"least_time=connect":    true,
+	"least_time=first_byte": true,
+	"least_time=last_byte":  true,
+}
+
+var leastTimeMeasures = map[string]bool{
+	"connect":    true,
+	"first_byte": true,
+	"last_byte":  true,
+}
+
+func validateLoadBalancing(lb *v1alpha1.LoadBalancing, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	methods := loadBalancingMethods
+	if isPlus {
+		methods = plusLoadBalancingMethods
+	}
+
+	if lb.Persistence != "" {
+		return validateLoadBalancingPersistence(lb, fieldPath)
+	}
+
+	if lb.Method == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("method"), ""))
+	} else if !methods[lb.Method] {
+		allErrs = append(allErrs, field.NotSupported(fieldPath.Child("method"), lb.Method, getSortedKeys(methods)))
+	}
+
+	if lb.Hash != nil && lb.Method != "hash" {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("hash"), "can only be used with the hash method"))
+	}
+	if lb.Random != nil && lb.Method != "random" {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("random"), "can only be used with the random method"))
+	}
+	if lb.LeastTime != nil && lb.Method != "least_time" {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("leastTime"), "can only be used with the least_time method"))
+	}
+
+	switch lb.Method {
+	case "hash":
+		if lb.Hash == nil {
+			allErrs = append(allErrs, field.Required(fieldPath.Child("hash"), "must be set for the hash method"))
+		} else {
+			allErrs = append(allErrs, validateHashKey(lb.Hash.Key, fieldPath.Child("hash", "key"), isPlus)...)
+		}
+	case "random":
+		if lb.Random != nil {
+			allErrs = append(allErrs, validateRandomLoadBalancing(lb.Random, fieldPath.Child("random"), isPlus)...)
+		}
+	case "least_time":
+		if lb.LeastTime != nil {
+			allErrs = append(allErrs, validateLeastTimeLoadBalancing(lb.LeastTime, fieldPath.Child("leastTime"))...)
+		}
+	}
+
+	return allErrs
+}
+
+var loadBalancingPersistenceTypes = map[string]bool{
+	"clientIP": true,
+}
+
+// validateLoadBalancingPersistence validates the persistence shorthand, which replaces the method and its configuration.
+func validateLoadBalancingPersistence(lb *v1alpha1.LoadBalancing, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !loadBalancingPersistenceTypes[lb.Persistence] {
+		allErrs = append(allErrs, field.NotSupported(fieldPath.Child("persistence"), lb.Persistence, getSortedKeys(loadBalancingPersistenceTypes)))
+	}
+
+	if lb.Method != "" {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("method"), "cannot be used with persistence"))
+	}
+	if lb.Hash != nil {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("hash"), "cannot be used with persistence"))
+	}
+	if lb.Random != nil {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("random"), "cannot be used with persistence"))
+	}
+	if lb.LeastTime != nil {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("leastTime"), "cannot be used with persistence"))
+	}
+
+	return allErrs
+}
+
+func validateHashKey(key string, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if key == "" {
+		return append(allErrs, field.Required(fieldPath, ""))
+	}
+
+	if strings.ContainsAny(key, " \t\n") {
+		return append(allErrs, field.Invalid(fieldPath, key, "must not contain whitespace characters"))
+	}
+
+	if err := ValidateEscapedString(key, `${remote_addr}`, `${remote_addr}${server_port}`); err != nil {
+		return append(allErrs, field.Invalid(fieldPath, key, err.Error()))
+	}
+
+	return append(allErrs, validateStringWithVariables(key, fieldPath, nil, hashKeyVariables, isPlus)...)
+}
+
+func validateRandomLoadBalancing(random *v1alpha1.RandomLoadBalancing, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if random.Method == "" {
+		return allErrs
+	}
+
+	if !random.Two {
+		return append(allErrs, field.Forbidden(fieldPath.Child("method"), "can only be used when two is 'true'"))
+	}
+
+	methods := randomLoadBalancingMethods
+	if isPlus {
+		methods = plusRandomLoadBalancingMethods
+	}
+
+	if !methods[random.Method] {
+		allErrs = append(allErrs, field.NotSupported(fieldPath.Child("method"), random.Method, getSortedKeys(methods)))
+	}
+
+	return allErrs
+}
+
+func validateLeastTimeLoadBalancing(leastTime *v1alpha1.LeastTimeLoadBalancing, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if leastTime.Measure != "" && !leastTimeMeasures[leastTime.Measure] {
+		allErrs = append(allErrs, field.NotSupported(fieldPath.Child("measure"), leastTime.Measure, getSortedKeys(leastTimeMeasures)))
+	}
+
+	if leastTime.Inflight && (leastTime.Measure == "" || leastTime.Measure == "connect") {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("inflight"), "can only be used with the first_byte or last_byte measure"))
+	}
+
+	return allErrs
+}
+
+func getSortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func validateTSUpstreamHealthChecks(hc *v1alpha1.HealthCheck, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -172,6 +172,23 @@ func TestValidateTransportServerUpstreamsFails(t *testing.T) {
 			},
 			msg: "duplicated upstreams",
 		},
+		{
+			upstreams: []v1alpha1.Upstream{
+				{
+					Name:                "upstream1",
+					Service:             "test-1",
+					Port:                80,
+					LoadBalancingMethod: "least_conn",
+					LoadBalancing: &v1alpha1.LoadBalancing{
+						Method: "least_conn",
+					},
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "both loadBalancingMethod and loadBalancing",
+		},
 	}
 
 	for _, test := range tests {
@@ -185,10 +202,157 @@ func TestValidateTransportServerUpstreamsFails(t *testing.T) {
 	}
 }
 
+func TestValidateTransportServerUpstreamsTLS(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		tls *v1alpha1.UpstreamTLS
+		msg string
+	}{
+		{
+			tls: nil,
+			msg: "no tls",
+		},
+		{
+			tls: &v1alpha1.UpstreamTLS{
+				Enable: false,
+			},
+			msg: "tls disabled",
+		},
+		{
+			tls: &v1alpha1.UpstreamTLS{
+				Enable: true,
+			},
+			msg: "tls enabled",
+		},
+		{
+			tls: &v1alpha1.UpstreamTLS{
+				Enable:            true,
+				TLSSecret:         "db-client-secret",
+				TrustedCertSecret: "db-ca-secret",
+				VerifyServer:      true,
+				VerifyDepth:       createPointerFromInt(2),
+				ServerName:        true,
+				SSLName:           "db.example.com",
+			},
+			msg: "all fields",
+		},
+	}
+
+	for _, test := range tests {
+		upstreams := []v1alpha1.Upstream{
+			{
+				Name:    "upstream1",
+				Service: "test-1",
+				Port:    80,
+				TLS:     test.tls,
+			},
+		}
+
+		allErrs := validateTransportServerUpstreamsTLS(upstreams, field.NewPath("upstreams"), false, "TCP")
+		if len(allErrs) > 0 {
+			t.Errorf("validateTransportServerUpstreamsTLS() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateTransportServerUpstreamsTLSFails(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		tls                      *v1alpha1.UpstreamTLS
+		isTLSPassthroughListener bool
+		protocol                 string
+		msg                      string
+	}{
+		{
+			tls: &v1alpha1.UpstreamTLS{
+				Enable: true,
+			},
+			isTLSPassthroughListener: true,
+			protocol:                 "TLS_PASSTHROUGH",
+			msg:                      "tls passthrough listener",
+		},
+		{
+			tls: &v1alpha1.UpstreamTLS{
+				Enable: true,
+			},
+			protocol: "UDP",
+			msg:      "udp listener",
+		},
+		{
+			tls: &v1alpha1.UpstreamTLS{
+				Enable:     false,
+				ServerName: true,
+			},
+			protocol: "TCP",
+			msg:      "fields with tls disabled",
+		},
+		{
+			tls: &v1alpha1.UpstreamTLS{
+				Enable:    true,
+				TLSSecret: "db_client_secret",
+			},
+			protocol: "TCP",
+			msg:      "invalid tls secret name",
+		},
+		{
+			tls: &v1alpha1.UpstreamTLS{
+				Enable:       true,
+				VerifyServer: true,
+			},
+			protocol: "TCP",
+			msg:      "verifyServer without trustedCertSecret",
+		},
+		{
+			tls: &v1alpha1.UpstreamTLS{
+				Enable:            true,
+				TrustedCertSecret: "db-ca-secret",
+				VerifyDepth:       createPointerFromInt(1),
+			},
+			protocol: "TCP",
+			msg:      "verifyDepth without verifyServer",
+		},
+		{
+			tls: &v1alpha1.UpstreamTLS{
+				Enable:            true,
+				TrustedCertSecret: "db-ca-secret",
+				VerifyServer:      true,
+				VerifyDepth:       createPointerFromInt(-1),
+			},
+			protocol: "TCP",
+			msg:      "negative verifyDepth",
+		},
+		{
+			tls: &v1alpha1.UpstreamTLS{
+				Enable:  true,
+				SSLName: "db_example.com",
+			},
+			protocol: "TCP",
+			msg:      "invalid sslName",
+		},
+	}
+
+	for _, test := range tests {
+		upstreams := []v1alpha1.Upstream{
+			{
+				Name:    "upstream1",
+				Service: "test-1",
+				Port:    80,
+				TLS:     test.tls,
+			},
+		}
+
+		allErrs := validateTransportServerUpstreamsTLS(upstreams, field.NewPath("upstreams"), test.isTLSPassthroughListener, test.protocol)
+		if len(allErrs) == 0 {
+			t.Errorf("validateTransportServerUpstreamsTLS() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
 func TestValidateTransportServerHost(t *testing.T) {
 	tests := []struct {
 		host                     string
 		isTLSPassthroughListener bool
+		isTLSTerminated          bool
 	}{
 		{
 			host:                     "",
@@ -198,10 +362,15 @@ func TestValidateTransportServerHost(t *testing.T) {
 			host:                     "nginx.org",
 			isTLSPassthroughListener: true,
 		},
+		{
+			host:                     "nginx.org",
+			isTLSPassthroughListener: false,
+			isTLSTerminated:          true,
+		},
 	}
 
 	for _, test := range tests {
-		allErrs := validateTransportServerHost(test.host, field.NewPath("host"), test.isTLSPassthroughListener)
+		allErrs := validateTransportServerHost(test.host, field.NewPath("host"), test.isTLSPassthroughListener, test.isTLSTerminated)
 		if len(allErrs) > 0 {
 			t.Errorf("validateTransportServerHost(%q, %v) returned errors %v for valid input", test.host, test.isTLSPassthroughListener, allErrs)
 		}
@@ -363,6 +532,7 @@ func TestValidateTransportServerHostFails(t *testing.T) {
 	tests := []struct {
 		host                     string
 		isTLSPassthroughListener bool
+		isTLSTerminated          bool
 	}{
 		{
 			host:                     "nginx.org",
@@ -372,16 +542,198 @@ func TestValidateTransportServerHostFails(t *testing.T) {
 			host:                     "",
 			isTLSPassthroughListener: true,
 		},
+		{
+			host:                     "*.nginx.org",
+			isTLSPassthroughListener: false,
+			isTLSTerminated:          true,
+		},
 	}
 
 	for _, test := range tests {
-		allErrs := validateTransportServerHost(test.host, field.NewPath("host"), test.isTLSPassthroughListener)
+		allErrs := validateTransportServerHost(test.host, field.NewPath("host"), test.isTLSPassthroughListener, test.isTLSTerminated)
 		if len(allErrs) == 0 {
 			t.Errorf("validateTransportServerHost(%q, %v) returned no errors for invalid input", test.host, test.isTLSPassthroughListener)
 		}
 	}
 }
 
+func TestValidateTransportServerTLS(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		tls *v1alpha1.TransportServerTLS
+		msg string
+	}{
+		{
+			tls: nil,
+			msg: "no tls",
+		},
+		{
+			tls: &v1alpha1.TransportServerTLS{
+				Secret: "db-secret",
+			},
+			msg: "tls secret",
+		},
+		{
+			tls: &v1alpha1.TransportServerTLS{
+				Secret:           "db-secret",
+				ClientCertSecret: "db-ca-secret",
+				VerifyClient:     "optional",
+				VerifyDepth:      createPointerFromInt(2),
+			},
+			msg: "client certificate verification",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateTransportServerTLS(test.tls, field.NewPath("tls"), false, "TCP")
+		if len(allErrs) > 0 {
+			t.Errorf("validateTransportServerTLS() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateTransportServerTLSFails(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		tls                      *v1alpha1.TransportServerTLS
+		isTLSPassthroughListener bool
+		protocol                 string
+		msg                      string
+	}{
+		{
+			tls: &v1alpha1.TransportServerTLS{
+				Secret: "db-secret",
+			},
+			isTLSPassthroughListener: true,
+			protocol:                 "TLS_PASSTHROUGH",
+			msg:                      "tls passthrough listener",
+		},
+		{
+			tls: &v1alpha1.TransportServerTLS{
+				Secret: "db-secret",
+			},
+			protocol: "UDP",
+			msg:      "udp listener",
+		},
+		{
+			tls:      &v1alpha1.TransportServerTLS{},
+			protocol: "TCP",
+			msg:      "missing secret",
+		},
+		{
+			tls: &v1alpha1.TransportServerTLS{
+				Secret: "db_secret",
+			},
+			protocol: "TCP",
+			msg:      "invalid secret name",
+		},
+		{
+			tls: &v1alpha1.TransportServerTLS{
+				Secret:       "db-secret",
+				VerifyClient: "on",
+			},
+			protocol: "TCP",
+			msg:      "verifyClient without clientCertSecret",
+		},
+		{
+			tls: &v1alpha1.TransportServerTLS{
+				Secret:      "db-secret",
+				VerifyDepth: createPointerFromInt(1),
+			},
+			protocol: "TCP",
+			msg:      "verifyDepth without clientCertSecret",
+		},
+		{
+			tls: &v1alpha1.TransportServerTLS{
+				Secret:           "db-secret",
+				ClientCertSecret: "db-ca-secret",
+				VerifyClient:     "always",
+			},
+			protocol: "TCP",
+			msg:      "invalid verifyClient",
+		},
+		{
+			tls: &v1alpha1.TransportServerTLS{
+				Secret:           "db-secret",
+				ClientCertSecret: "db-ca-secret",
+				VerifyDepth:      createPointerFromInt(-1),
+			},
+			protocol: "TCP",
+			msg:      "negative verifyDepth",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateTransportServerTLS(test.tls, field.NewPath("tls"), test.isTLSPassthroughListener, test.protocol)
+		if len(allErrs) == 0 {
+			t.Errorf("validateTransportServerTLS() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateTransportServerPolicies(t *testing.T) {
+	t.Parallel()
+	policies := []v1alpha1.PolicyReference{
+		{
+			Name: "allow-internal",
+		},
+		{
+			Name:      "conn-limit",
+			Namespace: "policies",
+		},
+	}
+
+	allErrs := validateTransportServerPolicies(policies, field.NewPath("policies"), "default")
+	if len(allErrs) > 0 {
+		t.Errorf("validateTransportServerPolicies() returned errors %v for valid input", allErrs)
+	}
+}
+
+func TestValidateTransportServerPoliciesFails(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		policies []v1alpha1.PolicyReference
+		msg      string
+	}{
+		{
+			policies: []v1alpha1.PolicyReference{
+				{
+					Name: "",
+				},
+			},
+			msg: "missing name",
+		},
+		{
+			policies: []v1alpha1.PolicyReference{
+				{
+					Name: "conn-limit",
+				},
+				{
+					Name:      "conn-limit",
+					Namespace: "default",
+				},
+			},
+			msg: "duplicated policies",
+		},
+		{
+			policies: []v1alpha1.PolicyReference{
+				{
+					Name:      "conn-limit",
+					Namespace: "default_ns",
+				},
+			},
+			msg: "invalid namespace",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateTransportServerPolicies(test.policies, field.NewPath("policies"), "default")
+		if len(allErrs) == 0 {
+			t.Errorf("validateTransportServerPolicies() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
 func TestValidateTransportListener(t *testing.T) {
 	tests := []struct {
 		listener       *v1alpha1.TransportServerListener
@@ -540,6 +892,220 @@ func TestValidateListenerProtocol(t *testing.T) {
 	}
 }
 
+func TestValidateLoadBalancing(t *testing.T) {
+	tests := []struct {
+		lb     *v1alpha1.LoadBalancing
+		isPlus bool
+		msg    string
+	}{
+		{
+			lb:  &v1alpha1.LoadBalancing{Method: "round_robin"},
+			msg: "round robin",
+		},
+		{
+			lb:  &v1alpha1.LoadBalancing{Method: "least_conn"},
+			msg: "least conn",
+		},
+		{
+			lb: &v1alpha1.LoadBalancing{
+				Method: "hash",
+				Hash: &v1alpha1.HashLoadBalancing{
+					Key:        "${remote_addr}",
+					Consistent: true,
+				},
+			},
+			msg: "consistent hash by client IP",
+		},
+		{
+			lb: &v1alpha1.LoadBalancing{
+				Method: "hash",
+				Hash: &v1alpha1.HashLoadBalancing{
+					Key: "${binary_remote_addr}${server_port}",
+				},
+			},
+			msg: "hash with several variables",
+		},
+		{
+			lb:  &v1alpha1.LoadBalancing{Method: "random"},
+			msg: "random",
+		},
+		{
+			lb: &v1alpha1.LoadBalancing{
+				Method: "random",
+				Random: &v1alpha1.RandomLoadBalancing{
+					Two:    true,
+					Method: "least_conn",
+				},
+			},
+			msg: "random two least conn",
+		},
+		{
+			lb: &v1alpha1.LoadBalancing{
+				Method: "random",
+				Random: &v1alpha1.RandomLoadBalancing{
+					Two:    true,
+					Method: "least_time=last_byte",
+				},
+			},
+			isPlus: true,
+			msg:    "random two least time in NGINX Plus",
+		},
+		{
+			lb:     &v1alpha1.LoadBalancing{Method: "least_time"},
+			isPlus: true,
+			msg:    "least time with default measure in NGINX Plus",
+		},
+		{
+			lb: &v1alpha1.LoadBalancing{
+				Method: "least_time",
+				LeastTime: &v1alpha1.LeastTimeLoadBalancing{
+					Measure:  "first_byte",
+					Inflight: true,
+				},
+			},
+			isPlus: true,
+			msg:    "least time first byte inflight in NGINX Plus",
+		},
+		{
+			lb:  &v1alpha1.LoadBalancing{Persistence: "clientIP"},
+			msg: "client IP persistence",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateLoadBalancing(test.lb, field.NewPath("loadBalancing"), test.isPlus)
+		if len(allErrs) > 0 {
+			t.Errorf("validateLoadBalancing() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateLoadBalancingFails(t *testing.T) {
+	tests := []struct {
+		lb     *v1alpha1.LoadBalancing
+		isPlus bool
+		msg    string
+	}{
+		{
+			lb:  &v1alpha1.LoadBalancing{},
+			msg: "missing method",
+		},
+		{
+			lb:  &v1alpha1.LoadBalancing{Method: "invalid"},
+			msg: "invalid method",
+		},
+		{
+			lb:  &v1alpha1.LoadBalancing{Method: "least_time"},
+			msg: "least time in NGINX",
+		},
+		{
+			lb:  &v1alpha1.LoadBalancing{Method: "hash"},
+			msg: "hash without hash block",
+		},
+		{
+			lb: &v1alpha1.LoadBalancing{
+				Method: "hash",
+				Hash:   &v1alpha1.HashLoadBalancing{},
+			},
+			msg: "hash without key",
+		},
+		{
+			lb: &v1alpha1.LoadBalancing{
+				Method: "hash",
+				Hash:   &v1alpha1.HashLoadBalancing{Key: "${remote_addr} consistent"},
+			},
+			msg: "hash key with whitespace",
+		},
+		{
+			lb: &v1alpha1.LoadBalancing{
+				Method: "hash",
+				Hash:   &v1alpha1.HashLoadBalancing{Key: "${invalid_var}"},
+			},
+			msg: "hash key with invalid variable",
+		},
+		{
+			lb: &v1alpha1.LoadBalancing{
+				Method: "hash",
+				Hash:   &v1alpha1.HashLoadBalancing{Key: `${remote_addr}"`},
+			},
+			msg: "hash key with unescaped quote",
+		},
+		{
+			lb: &v1alpha1.LoadBalancing{
+				Method: "least_conn",
+				Hash:   &v1alpha1.HashLoadBalancing{Key: "${remote_addr}"},
+			},
+			msg: "hash block with another method",
+		},
+		{
+			lb: &v1alpha1.LoadBalancing{
+				Method: "random",
+				Random: &v1alpha1.RandomLoadBalancing{Method: "least_conn"},
+			},
+			msg: "random method without two",
+		},
+		{
+			lb: &v1alpha1.LoadBalancing{
+				Method: "random",
+				Random: &v1alpha1.RandomLoadBalancing{
+					Two:    true,
+					Method: "least_time=connect",
+				},
+			},
+			msg: "random two least time in NGINX",
+		},
+		{
+			lb: &v1alpha1.LoadBalancing{
+				Method:    "least_time",
+				LeastTime: &v1alpha1.LeastTimeLoadBalancing{Measure: "invalid"},
+			},
+			isPlus: true,
+			msg:    "invalid least time measure",
+		},
+		{
+			lb: &v1alpha1.LoadBalancing{
+				Method:    "least_time",
+				LeastTime: &v1alpha1.LeastTimeLoadBalancing{Inflight: true},
+			},
+			isPlus: true,
+			msg:    "least time inflight with connect measure",
+		},
+		{
+			lb: &v1alpha1.LoadBalancing{
+				Method:    "round_robin",
+				LeastTime: &v1alpha1.LeastTimeLoadBalancing{Measure: "connect"},
+			},
+			isPlus: true,
+			msg:    "least time block with another method",
+		},
+		{
+			lb:  &v1alpha1.LoadBalancing{Persistence: "cookie"},
+			msg: "invalid persistence",
+		},
+		{
+			lb: &v1alpha1.LoadBalancing{
+				Method:      "least_conn",
+				Persistence: "clientIP",
+			},
+			msg: "persistence with method",
+		},
+		{
+			lb: &v1alpha1.LoadBalancing{
+				Persistence: "clientIP",
+				Hash:        &v1alpha1.HashLoadBalancing{Key: "${remote_addr}"},
+			},
+			msg: "persistence with hash block",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateLoadBalancing(test.lb, field.NewPath("loadBalancing"), test.isPlus)
+		if len(allErrs) == 0 {
+			t.Errorf("validateLoadBalancing() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
 func TestValidateTSUpstreamHealthChecks(t *testing.T) {
 	tests := []struct {
 		healthCheck *v1alpha1.HealthCheck
@@ -712,8 +712,11 @@ func (vsv *VirtualServerValidator) validateRoute(route v1.Route, fieldPath *fiel
 
 	fieldCount := 0
 
+	// with a canary, the action of the route is generated as an internal location, like the actions of matches
+	isInternalAction := route.Canary != nil
+
 	if route.Action != nil {
-		allErrs = append(allErrs, vsv.validateAction(route.Action, fieldPath.Child("action"), upstreamNames, route.Path, false)...)
+		allErrs = append(allErrs, vsv.validateAction(route.Action, fieldPath.Child("action"), upstreamNames, route.Path, isInternalAction)...)
 		fieldCount++
 	}
 
@@ -729,6 +732,18 @@ func (vsv *VirtualServerValidator) validateRoute(route v1.Route, fieldPath *fiel
 		}
 	}
 
+	if route.Canary != nil {
+		allErrs = append(allErrs, vsv.validateCanary(route.Canary, fieldPath.Child("canary"), upstreamNames, route.Path)...)
+
+		if route.Action == nil {
+			allErrs = append(allErrs, field.Required(fieldPath.Child("action"), "must specify `action` when `canary` is specified"))
+		}
+
+		if len(route.Matches) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("matches"), "is not allowed when `canary` is specified"))
+		}
+	}
+
 	for i, e := range route.ErrorPages {
 		allErrs = append(allErrs, vsv.validateErrorPage(e, fieldPath.Child("errorPages").Index(i))...)
 	}
@@ -1064,6 +1079,7 @@ func (vsv *VirtualServerValidator) validateActionProxy(p *v1.ActionProxy, fieldP
 	allErrs = append(allErrs, validateReferencedUpstream(p.Upstream, fieldPath.Child("upstream"), upstreamNames)...)
 	allErrs = append(allErrs, vsv.validateActionProxyRequestHeaders(p.RequestHeaders, fieldPath.Child("requestHeaders"))...)
 	allErrs = append(allErrs, vsv.validateActionProxyResponseHeaders(p.ResponseHeaders, fieldPath.Child("responseHeaders"))...)
+	allErrs = append(allErrs, validateActionProxyMirror(p.Mirror, fieldPath.Child("mirror"), upstreamNames)...)
 
 	if strings.HasPrefix(path, "~") || internal {
 		allErrs = append(allErrs, validateActionProxyRewritePathForRegexp(p.RewritePath, fieldPath.Child("rewritePath"))...)
@@ -1074,6 +1090,24 @@ func (vsv *VirtualServerValidator) validateActionProxy(p *v1.ActionProxy, fieldP
 	return allErrs
 }
 
+func validateActionProxyMirror(m *v1.ActionProxyMirror, fieldPath *field.Path, upstreamNames sets.String) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if m == nil {
+		return allErrs
+	}
+
+	allErrs = append(allErrs, validateReferencedUpstream(m.Upstream, fieldPath.Child("upstream"), upstreamNames)...)
+
+	if m.Percentage != nil {
+		for _, msg := range validation.IsInRange(*m.Percentage, 1, 100) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("percentage"), *m.Percentage, msg))
+		}
+	}
+
+	return allErrs
+}
+
 func validateStringNoVariables(s string, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -1395,6 +1429,38 @@ func (vsv *VirtualServerValidator) validateMatch(match v1.Match, fieldPath *fiel
 	return allErrs
 }
 
+func (vsv *VirtualServerValidator) validateCanary(canary *v1.Canary, fieldPath *field.Path, upstreamNames sets.String, path string) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if canary.Header != "" {
+		for _, msg := range validation.IsHTTPHeaderName(canary.Header) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("header"), canary.Header, msg))
+		}
+	}
+
+	if canary.Cookie != "" {
+		for _, msg := range isCookieName(canary.Cookie) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("cookie"), canary.Cookie, msg))
+		}
+	}
+
+	for _, msg := range validation.IsInRange(canary.Weight, 0, 100) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("weight"), canary.Weight, msg))
+	}
+
+	if canary.Header == "" && canary.Cookie == "" && canary.Weight == 0 {
+		allErrs = append(allErrs, field.Required(fieldPath, "must specify at least one of `header`, `cookie` or `weight`"))
+	}
+
+	if canary.Action == nil {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("action"), ""))
+	} else {
+		allErrs = append(allErrs, vsv.validateAction(canary.Action, fieldPath.Child("action"), upstreamNames, path, true)...)
+	}
+
+	return allErrs
+}
+
 func validateCondition(condition v1.Condition, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
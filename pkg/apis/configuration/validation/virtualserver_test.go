@@ -948,6 +948,28 @@ func TestValidateRoute(t *testing.T) {
 			isRouteFieldForbidden: false,
 			msg:                   "valid route with route",
 		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Action: &v1.Action{
+					Pass: "test-1",
+				},
+				Canary: &v1.Canary{
+					Header: "x-canary",
+					Cookie: "canary",
+					Weight: 10,
+					Action: &v1.Action{
+						Pass: "test-2",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "valid action with canary",
+		},
 	}
 
 	vsv := &VirtualServerValidator{isPlus: false}
@@ -1082,6 +1104,152 @@ func TestValidateRouteFails(t *testing.T) {
 			isRouteFieldForbidden: true,
 			msg:                   "route field exists but is forbidden",
 		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Canary: &v1.Canary{
+					Header: "x-canary",
+					Action: &v1.Action{
+						Pass: "test-2",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "canary without action of route",
+		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Action: &v1.Action{
+					Pass: "test-1",
+				},
+				Canary: &v1.Canary{
+					Header: "x-canary",
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "canary without action",
+		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Action: &v1.Action{
+					Pass: "test-1",
+				},
+				Canary: &v1.Canary{
+					Action: &v1.Action{
+						Pass: "test-2",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "canary without header, cookie and weight",
+		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Action: &v1.Action{
+					Pass: "test-1",
+				},
+				Canary: &v1.Canary{
+					Weight: 101,
+					Action: &v1.Action{
+						Pass: "test-2",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "canary with invalid weight",
+		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Action: &v1.Action{
+					Pass: "test-1",
+				},
+				Canary: &v1.Canary{
+					Header: "x canary",
+					Action: &v1.Action{
+						Pass: "test-2",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "canary with invalid header",
+		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Action: &v1.Action{
+					Pass: "test-1",
+				},
+				Canary: &v1.Canary{
+					Cookie: "canary",
+					Action: &v1.Action{
+						Pass: "test-3",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "canary with non-existing upstream",
+		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Action: &v1.Action{
+					Pass: "test-1",
+				},
+				Matches: []v1.Match{
+					{
+						Conditions: []v1.Condition{
+							{
+								Header: "x-version",
+								Value:  "test-1",
+							},
+						},
+						Action: &v1.Action{
+							Pass: "test-1",
+						},
+					},
+				},
+				Canary: &v1.Canary{
+					Weight: 10,
+					Action: &v1.Action{
+						Pass: "test-2",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "both canary and matches exist",
+		},
 	}
 
 	vsv := &VirtualServerValidator{isPlus: false}
@@ -3307,6 +3475,81 @@ func TestValidateActionProxyFails(t *testing.T) {
 	}
 }
 
+func TestValidateActionProxyMirror(t *testing.T) {
+	t.Parallel()
+	upstreamNames := map[string]sets.Empty{
+		"upstream1": {},
+		"upstream2": {},
+	}
+	percentage := 10
+	requestBody := false
+
+	tests := []*v1.ActionProxyMirror{
+		nil,
+		{
+			Upstream: "upstream2",
+		},
+		{
+			Upstream:    "upstream2",
+			Percentage:  &percentage,
+			RequestBody: &requestBody,
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateActionProxyMirror(test, field.NewPath("mirror"), upstreamNames)
+		if len(allErrs) != 0 {
+			t.Errorf("validateActionProxyMirror(%+v) returned errors for valid input: %v", test, allErrs)
+		}
+	}
+}
+
+func TestValidateActionProxyMirrorFails(t *testing.T) {
+	t.Parallel()
+	upstreamNames := map[string]sets.Empty{
+		"upstream1": {},
+	}
+	zero := 0
+	tooBig := 101
+
+	tests := []struct {
+		mirror *v1.ActionProxyMirror
+		msg    string
+	}{
+		{
+			mirror: &v1.ActionProxyMirror{},
+			msg:    "missing upstream",
+		},
+		{
+			mirror: &v1.ActionProxyMirror{
+				Upstream: "upstream2",
+			},
+			msg: "upstream not in the resource",
+		},
+		{
+			mirror: &v1.ActionProxyMirror{
+				Upstream:   "upstream1",
+				Percentage: &zero,
+			},
+			msg: "zero percentage",
+		},
+		{
+			mirror: &v1.ActionProxyMirror{
+				Upstream:   "upstream1",
+				Percentage: &tooBig,
+			},
+			msg: "too big percentage",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateActionProxyMirror(test.mirror, field.NewPath("mirror"), upstreamNames)
+		if len(allErrs) == 0 {
+			t.Errorf("validateActionProxyMirror() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
 func TestValidateActionProxyRewritePath(t *testing.T) {
 	t.Parallel()
 	tests := []string{"/rewrite", "/rewrite", `/$2`}
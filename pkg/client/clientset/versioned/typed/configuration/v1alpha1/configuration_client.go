@@ -14,6 +14,7 @@ type K8sV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	GlobalConfigurationsGetter
 	PoliciesGetter
+	TrafficShiftsGetter
 	TransportServersGetter
 }
 
@@ -30,6 +31,10 @@ func (c *K8sV1alpha1Client) Policies(namespace string) PolicyInterface {
 	return newPolicies(c, namespace)
 }
 
+func (c *K8sV1alpha1Client) TrafficShifts(namespace string) TrafficShiftInterface {
+	return newTrafficShifts(c, namespace)
+}
+
 func (c *K8sV1alpha1Client) TransportServers(namespace string) TransportServerInterface {
 	return newTransportServers(c, namespace)
 }
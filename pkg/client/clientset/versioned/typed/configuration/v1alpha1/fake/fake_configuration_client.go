@@ -20,6 +20,10 @@ func (c *FakeK8sV1alpha1) Policies(namespace string) v1alpha1.PolicyInterface {
 	return &FakePolicies{c, namespace}
 }
 
+func (c *FakeK8sV1alpha1) TrafficShifts(namespace string) v1alpha1.TrafficShiftInterface {
+	return &FakeTrafficShifts{c, namespace}
+}
+
 func (c *FakeK8sV1alpha1) TransportServers(namespace string) v1alpha1.TransportServerInterface {
 	return &FakeTransportServers{c, namespace}
 }
@@ -12,6 +12,8 @@ type Interface interface {
 	GlobalConfigurations() GlobalConfigurationInformer
 	// Policies returns a PolicyInformer.
 	Policies() PolicyInformer
+	// TrafficShifts returns a TrafficShiftInformer.
+	TrafficShifts() TrafficShiftInformer
 	// TransportServers returns a TransportServerInformer.
 	TransportServers() TransportServerInformer
 }
@@ -37,6 +39,11 @@ func (v *version) Policies() PolicyInformer {
 	return &policyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// TrafficShifts returns a TrafficShiftInformer.
+func (v *version) TrafficShifts() TrafficShiftInformer {
+	return &trafficShiftInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // TransportServers returns a TransportServerInformer.
 func (v *version) TransportServers() TransportServerInformer {
 	return &transportServerInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
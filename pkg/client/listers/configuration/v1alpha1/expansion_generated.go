@@ -18,6 +18,14 @@ type PolicyListerExpansion interface{}
 // PolicyNamespaceLister.
 type PolicyNamespaceListerExpansion interface{}
 
+// TrafficShiftListerExpansion allows custom methods to be added to
+// TrafficShiftLister.
+type TrafficShiftListerExpansion interface{}
+
+// TrafficShiftNamespaceListerExpansion allows custom methods to be added to
+// TrafficShiftNamespaceLister.
+type TrafficShiftNamespaceListerExpansion interface{}
+
 // TransportServerListerExpansion allows custom methods to be added to
 // TransportServerLister.
 type TransportServerListerExpansion interface{}